@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"leaderboard-service/internal/config"
+)
+
+const usage = `usage: server [flags] [command]
+
+Without a command the server starts serving.
+
+commands:
+  migrate up          apply all pending migrations
+  migrate down [N]    roll back the last N applied migrations (default 1)
+  migrate status      list migrations and when they were applied
+  config print        print the effective configuration with secrets redacted
+
+`
+
+// runCommand runs a one-off command instead of the server and returns the
+// exit code.
+func runCommand(cfg *config.Config, args []string, stdout, stderr io.Writer) int {
+	switch args[0] {
+	case "migrate":
+		return runMigrate(cfg, args[1:], stdout, stderr)
+	case "config":
+		return runConfig(cfg, args[1:], stdout, stderr)
+	default:
+		fmt.Fprintf(stderr, "unknown command %q\n\n%s%s", args[0], usage, config.Usage())
+		return 2
+	}
+}
+
+func runConfig(cfg *config.Config, args []string, stdout, stderr io.Writer) int {
+	if len(args) != 1 || args[0] != "print" {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+	if err := cfg.Print(stdout); err != nil {
+		fmt.Fprintf(stderr, "config print: %v\n", err)
+		return 1
+	}
+	return 0
+}
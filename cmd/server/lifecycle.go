@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// lifecycle owns the process's long-running components. It serves until ctx
+// is cancelled or a server fails, then shuts everything down in dependency
+// order: fail readiness and keep serving for drainDelay, stop accepting and
+// drain HTTP and gRPC requests, let the matchmaking and purge workers finish
+// their current pass, and finally close the database pool.
+type lifecycle struct {
+	httpServer   *http.Server
+	httpListener net.Listener
+	grpcServer   *grpc.Server
+	grpcListener net.Listener
+
+	stopWorker context.CancelFunc
+	workerDone <-chan struct{}
+	closeDB    func()
+
+	// markUnready makes /readyz fail. drainDelay is how long to keep serving
+	// afterwards so load balancers can take the instance out of rotation.
+	markUnready func()
+	drainDelay  time.Duration
+
+	shutdownTimeout time.Duration
+}
+
+func (l *lifecycle) run(ctx context.Context) error {
+	errc := make(chan error, 2)
+	go func() {
+		slog.Info("HTTP server started", "addr", l.httpListener.Addr().String())
+		if err := l.httpServer.Serve(l.httpListener); err != nil && err != http.ErrServerClosed {
+			errc <- err
+		}
+	}()
+	go func() {
+		slog.Info("gRPC server started", "addr", l.grpcListener.Addr().String())
+		if err := l.grpcServer.Serve(l.grpcListener); err != nil {
+			errc <- err
+		}
+	}()
+
+	var runErr error
+	select {
+	case <-ctx.Done():
+		slog.Info("shutting down")
+	case runErr = <-errc:
+		slog.Error("server failed, shutting down", "error", runErr)
+	}
+	l.shutdown()
+	return runErr
+}
+
+func (l *lifecycle) shutdown() {
+	// 0. Ask load balancers to stop sending traffic while we still serve it.
+	if l.markUnready != nil {
+		l.markUnready()
+	}
+	if l.drainDelay > 0 {
+		slog.Info("readiness failing, waiting before closing listeners", "drain_delay", l.drainDelay)
+		time.Sleep(l.drainDelay)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), l.shutdownTimeout)
+	defer cancel()
+
+	// 1. Stop accepting connections and drain in-flight HTTP requests.
+	if err := l.httpServer.Shutdown(ctx); err != nil {
+		slog.Warn("HTTP drain did not finish, closing remaining connections", "error", err)
+		l.httpServer.Close()
+	}
+
+	// 2. Same for gRPC, forcing a stop once the deadline passes.
+	stopped := make(chan struct{})
+	go func() {
+		l.grpcServer.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		slog.Warn("gRPC drain did not finish, stopping")
+		l.grpcServer.Stop()
+	}
+
+	// 3. Let the matchmaking and purge workers finish what they are doing.
+	l.stopWorker()
+	select {
+	case <-l.workerDone:
+	case <-ctx.Done():
+		slog.Warn("timed out waiting for the workers")
+	}
+
+	// 4. Nothing uses the database any more.
+	l.closeDB()
+	slog.Info("shutdown complete")
+}
+
+// allDone returns a channel that is closed once all of chans are.
+func allDone(chans ...<-chan struct{}) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for _, c := range chans {
+			<-c
+		}
+	}()
+	return done
+}
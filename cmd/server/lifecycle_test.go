@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+func newTestLifecycle(t *testing.T, handler http.Handler, workerDone <-chan struct{}, events chan<- string) *lifecycle {
+	t.Helper()
+	httpListener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	grpcListener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	return &lifecycle{
+		httpServer:      &http.Server{Handler: handler},
+		httpListener:    httpListener,
+		grpcServer:      grpc.NewServer(),
+		grpcListener:    grpcListener,
+		stopWorker:      func() { events <- "stop worker" },
+		workerDone:      workerDone,
+		closeDB:         func() { events <- "close db" },
+		shutdownTimeout: 2 * time.Second,
+	}
+}
+
+func TestLifecycle_DrainsInFlightRequests(t *testing.T) {
+	started := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		time.Sleep(200 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	})
+	workerDone := make(chan struct{})
+	close(workerDone)
+	events := make(chan string, 4)
+	lc := newTestLifecycle(t, handler, workerDone, events)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	runErr := make(chan error, 1)
+	go func() { runErr <- lc.run(ctx) }()
+
+	respc := make(chan *http.Response, 1)
+	go func() {
+		resp, err := http.Get("http://" + lc.httpListener.Addr().String())
+		if err != nil {
+			t.Errorf("in-flight request failed: %v", err)
+			respc <- nil
+			return
+		}
+		respc <- resp
+	}()
+	<-started
+	cancel()
+
+	if resp := <-respc; resp != nil && resp.StatusCode != http.StatusOK {
+		t.Errorf("expected in-flight request to complete with 200, got %d", resp.StatusCode)
+	}
+	if err := <-runErr; err != nil {
+		t.Errorf("expected clean shutdown, got %v", err)
+	}
+	if _, err := net.DialTimeout("tcp", lc.httpListener.Addr().String(), 100*time.Millisecond); err == nil {
+		t.Errorf("expected listener to be closed after shutdown")
+	}
+}
+
+func TestLifecycle_ClosesDBAfterWorkerFinishes(t *testing.T) {
+	workerDone := make(chan struct{})
+	events := make(chan string, 4)
+	lc := newTestLifecycle(t, http.NotFoundHandler(), workerDone, events)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	finished := make(chan struct{})
+	go func() {
+		lc.run(ctx)
+		close(finished)
+	}()
+
+	if ev := <-events; ev != "stop worker" {
+		t.Fatalf("expected worker to be stopped first, got %q", ev)
+	}
+	select {
+	case ev := <-events:
+		t.Fatalf("expected shutdown to wait for the worker's tick, got %q", ev)
+	case <-time.After(100 * time.Millisecond):
+	}
+	close(workerDone)
+	if ev := <-events; ev != "close db" {
+		t.Errorf("expected db to be closed after the worker, got %q", ev)
+	}
+	<-finished
+}
+
+func TestLifecycle_FailsReadinessBeforeClosingListeners(t *testing.T) {
+	workerDone := make(chan struct{})
+	close(workerDone)
+	events := make(chan string, 4)
+	lc := newTestLifecycle(t, http.NotFoundHandler(), workerDone, events)
+	unready := make(chan struct{})
+	lc.markUnready = func() { close(unready) }
+	lc.drainDelay = 200 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	runErr := make(chan error, 1)
+	go func() { runErr <- lc.run(ctx) }()
+	cancel()
+
+	<-unready
+	resp, err := http.Get("http://" + lc.httpListener.Addr().String())
+	if err != nil {
+		t.Fatalf("expected requests to be served during the drain delay: %v", err)
+	}
+	resp.Body.Close()
+	if err := <-runErr; err != nil {
+		t.Errorf("expected clean shutdown, got %v", err)
+	}
+}
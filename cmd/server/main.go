@@ -2,63 +2,171 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"leaderboard-service/internal/api"
+	"leaderboard-service/internal/config"
 	"leaderboard-service/internal/db"
+	"leaderboard-service/internal/grpcapi"
+	"leaderboard-service/internal/health"
+	"leaderboard-service/internal/logging"
+	"leaderboard-service/internal/metrics"
+	"leaderboard-service/internal/migrate"
+	"leaderboard-service/internal/model"
 	"leaderboard-service/internal/repository"
 	"leaderboard-service/internal/service"
-	"log"
+	"leaderboard-service/internal/tracing"
+	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gorilla/mux/otelmux"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 )
 
-func getenvDuration(key string, fallback time.Duration) time.Duration {
-	if val := os.Getenv(key); val != "" {
-		d, err := time.ParseDuration(val)
-		if err == nil {
-			return d
+func main() {
+	cfg, args, err := config.Load(os.Args[1:], os.LookupEnv)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if _, err := logging.Setup(os.Stdout, cfg.Log.Format, cfg.Log.Level); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid logging configuration: %v\n", err)
+		os.Exit(1)
+	}
+	if len(args) > 0 {
+		os.Exit(runCommand(cfg, args, os.Stdout, os.Stderr))
+	}
+
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing.Exporter)
+	if err != nil {
+		slog.Error("failed to set up tracing", "error", err)
+		os.Exit(1)
+	}
+
+	database := db.Open(cfg.DB)
+
+	migrator := migrate.New(database)
+	if cfg.Migrate.OnStart {
+		n, err := migrator.Up(context.Background())
+		if err != nil {
+			slog.Error("failed to apply migrations", "error", err)
+			os.Exit(1)
 		}
+		slog.Info("database schema is up to date", "applied", n, "version", migrator.Latest())
 	}
-	return fallback
-}
 
-func main() {
-	database := db.Open()
-	defer db.Close(database)
+	registry := metrics.NewRegistry(database)
+	m := metrics.New(registry)
 
-	// Set connection pool settings
-	database.SetMaxOpenConns(20)
-	database.SetMaxIdleConns(10)
-	database.SetConnMaxLifetime(30 * time.Second)
+	baseRepo := repository.NewRepository(database)
+	meteredRepo := metrics.NewRepository(baseRepo, m)
+	// Scrapes read the gauges through the untraced repository so they don't
+	// produce a trace each time.
+	registry.MustRegister(metrics.NewGaugeCollector(meteredRepo))
+	repo := tracing.NewRepository(meteredRepo)
 
-	repo := repository.NewRepository(database)
+	svc := service.NewService(repo, service.Config{
+		MatchmakingInterval: cfg.Matchmaking.Interval,
+		CompetitionDuration: cfg.Matchmaking.CompetitionDuration,
+		MinGroupSize:        cfg.Matchmaking.MinGroupSize,
+		MaxGroupSize:        cfg.Matchmaking.MaxGroupSize,
+		PromotePercent:      cfg.Matchmaking.PromotePercent,
+		RelegatePercent:     cfg.Matchmaking.RelegatePercent,
+		PartyLevel:          model.PartyLevel(cfg.Matchmaking.PartyLevel),
+	})
+	workerMonitor := health.NewWorkerMonitor(func() time.Duration {
+		current, _ := svc.GetConfig(context.Background())
+		return current.MatchmakingInterval
+	})
+	checker := health.NewChecker(database, migrator, workerMonitor, meteredRepo)
 
-	config := service.Config{
-		MatchmakingInterval: getenvDuration("MATCHMAKING_INTERVAL", 15*time.Second),
-		CompetitionDuration: getenvDuration("COMPETITION_DURATION", 30*time.Second),
+	svc.UseTickMiddleware(workerMonitor.TickMiddleware(), m.TickMiddleware(), tracing.TickMiddleware())
+	if len(cfg.Profiles.BlockedWords) > 0 {
+		svc.UseNameFilters(service.BlockedWords(cfg.Profiles.BlockedWords...))
 	}
+	instrumented := tracing.NewService(metrics.NewService(svc, m))
+	handler := api.NewHandler(instrumented, api.WithAdminTokens(cfg.Admin.Tokens))
 
-	svc := service.NewService(repo, config)
-	handler := api.NewHandler(svc)
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler(registry))
+	checker.Register(mux)
+	mux.Handle("/", api.NewRouter(handler,
+		otelmux.Middleware(tracing.ServiceName),
+		tracing.LogMiddleware,
+		m.Middleware,
+	))
 
-	router := api.NewRouter(handler)
+	httpAddr := fmt.Sprintf(":%d", cfg.HTTP.Port)
+	httpListener, err := net.Listen("tcp", httpAddr)
+	if err != nil {
+		slog.Error("failed to listen", "addr", httpAddr, "error", err)
+		os.Exit(1)
+	}
+	httpServer := &http.Server{
+		Handler:           logging.Middleware(mux),
+		ReadTimeout:       cfg.HTTP.ReadTimeout,
+		ReadHeaderTimeout: cfg.HTTP.ReadHeaderTimeout,
+		WriteTimeout:      cfg.HTTP.WriteTimeout,
+		IdleTimeout:       cfg.HTTP.IdleTimeout,
+	}
+	// Live streams never finish on their own; end them as soon as shutdown
+	// starts so they don't hold up draining.
+	httpServer.RegisterOnShutdown(svc.Hub().Close)
 
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	grpcAddr := fmt.Sprintf(":%d", cfg.GRPC.Port)
+	grpcListener, err := net.Listen("tcp", grpcAddr)
+	if err != nil {
+		slog.Error("failed to listen", "addr", grpcAddr, "error", err)
+		os.Exit(1)
+	}
+
+	workerCtx, stopWorker := context.WithCancel(context.Background())
+	workerDone := allDone(
+		svc.StartMatchmakingWorker(workerCtx),
+		svc.StartPurgeWorker(workerCtx, cfg.Privacy.PurgeInterval, cfg.Privacy.Retention),
+	)
 
-	svc.StartMatchmakingWorker(ctx)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	reloadOnSIGHUP(ctx, instrumented, func() (*config.Config, error) {
+		cfg, _, err := config.Load(os.Args[1:], os.LookupEnv)
+		return cfg, err
+	})
 
-	// Graceful shutdown
-	go func() {
-		c := make(chan os.Signal, 1)
-		signal.Notify(c, os.Interrupt, syscall.SIGTERM)
-		<-c
-		log.Println("Shutting down...")
-		cancel()
-	}()
+	grpcServer := grpcapi.NewGRPCServer(instrumented, grpc.StatsHandler(otelgrpc.NewServerHandler()))
+	grpcHealth := grpchealth.NewServer()
+	healthpb.RegisterHealthServer(grpcServer, grpcHealth)
 
-	log.Println("Server started on :8080")
-	log.Fatal(http.ListenAndServe(":8080", router))
+	lc := &lifecycle{
+		httpServer:   httpServer,
+		httpListener: httpListener,
+		grpcServer:   grpcServer,
+		grpcListener: grpcListener,
+		stopWorker:   stopWorker,
+		workerDone:   workerDone,
+		closeDB:      func() { db.Close(database) },
+		markUnready: func() {
+			checker.SetShuttingDown()
+			grpcHealth.Shutdown()
+		},
+		drainDelay:      cfg.Shutdown.DrainDelay,
+		shutdownTimeout: cfg.Shutdown.Timeout,
+	}
+	runErr := lc.run(ctx)
+
+	flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := shutdownTracing(flushCtx); err != nil {
+		slog.Warn("failed to flush traces", "error", err)
+	}
+	if runErr != nil {
+		os.Exit(1)
+	}
 }
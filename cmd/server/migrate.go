@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"leaderboard-service/internal/config"
+	"leaderboard-service/internal/db"
+	"leaderboard-service/internal/migrate"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+// runMigrate implements the migrate subcommand and returns the exit code.
+func runMigrate(cfg *config.Config, args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+	steps := 1
+	switch args[0] {
+	case "up", "status":
+		if len(args) != 1 {
+			fmt.Fprint(stderr, usage)
+			return 2
+		}
+	case "down":
+		if len(args) > 2 {
+			fmt.Fprint(stderr, usage)
+			return 2
+		}
+		if len(args) == 2 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				fmt.Fprintf(stderr, "invalid step count %q\n", args[1])
+				return 2
+			}
+			steps = n
+		}
+	default:
+		fmt.Fprintf(stderr, "unknown migrate command %q\n\n%s", args[0], usage)
+		return 2
+	}
+
+	database := db.Open(cfg.DB)
+	defer db.Close(database)
+	m := migrate.New(database)
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		n, err := m.Up(ctx)
+		if err != nil {
+			fmt.Fprintf(stderr, "migrate up: %v\n", err)
+			return 1
+		}
+		fmt.Fprintf(stdout, "applied %d migration(s), schema is at version %d\n", n, m.Latest())
+	case "down":
+		n, err := m.Down(ctx, steps)
+		if err != nil {
+			fmt.Fprintf(stderr, "migrate down: %v\n", err)
+			return 1
+		}
+		fmt.Fprintf(stdout, "rolled back %d migration(s)\n", n)
+	case "status":
+		statuses, err := m.Status(ctx)
+		if err != nil {
+			fmt.Fprintf(stderr, "migrate status: %v\n", err)
+			return 1
+		}
+		printStatus(stdout, statuses)
+	}
+	return 0
+}
+
+func printStatus(w io.Writer, statuses []migrate.Status) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "VERSION\tNAME\tAPPLIED AT")
+	for _, st := range statuses {
+		name, applied := st.Name, "pending"
+		if name == "" {
+			name = "(unknown to this build)"
+		}
+		if st.AppliedAt != nil {
+			applied = st.AppliedAt.UTC().Format(time.RFC3339)
+		}
+		fmt.Fprintf(tw, "%04d\t%s\t%s\n", st.Version, name, applied)
+	}
+	tw.Flush()
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"bytes"
+	"leaderboard-service/internal/config"
+	"leaderboard-service/internal/migrate"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunMigrate_RejectsBadArguments(t *testing.T) {
+	for _, args := range [][]string{nil, {"sideways"}, {"down", "0"}, {"down", "x"}, {"up", "extra"}} {
+		var stdout, stderr bytes.Buffer
+		if code := runMigrate(&config.Config{}, args, &stdout, &stderr); code != 2 {
+			t.Errorf("%v: expected exit code 2, got %d", args, code)
+		}
+		if stderr.Len() == 0 {
+			t.Errorf("%v: expected usage on stderr", args)
+		}
+	}
+}
+
+func TestPrintStatus(t *testing.T) {
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	var out bytes.Buffer
+	printStatus(&out, []migrate.Status{
+		{Version: 1, Name: "initial_schema", AppliedAt: &at},
+		{Version: 2, Name: "add_tiers"},
+		{Version: 3, AppliedAt: &at},
+	})
+	got := out.String()
+	for _, want := range []string{"0001", "initial_schema", "2024-05-01T12:00:00Z", "add_tiers", "pending", "(unknown to this build)"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in status output:\n%s", want, got)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"leaderboard-service/internal/auth"
+	"leaderboard-service/internal/config"
+	"leaderboard-service/internal/model"
+	"leaderboard-service/internal/service"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// reloadActor is recorded in the audit log for changes made by SIGHUP.
+const reloadActor = "system:sighup"
+
+// reloadOnSIGHUP reapplies the matchmaking settings from the configuration
+// sources every time the process receives SIGHUP, until ctx is done. Other
+// settings need a restart.
+func reloadOnSIGHUP(ctx context.Context, svc service.ServiceInterface, load func() (*config.Config, error)) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		defer signal.Stop(hup)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-hup:
+				reload(ctx, svc, load)
+			}
+		}
+	}()
+}
+
+func reload(ctx context.Context, svc service.ServiceInterface, load func() (*config.Config, error)) {
+	cfg, err := load()
+	if err != nil {
+		slog.Error("config reload failed, keeping current settings", "error", err)
+		return
+	}
+	m := cfg.Matchmaking
+	partyLevel := model.PartyLevel(m.PartyLevel)
+	update := service.ConfigUpdate{
+		MatchmakingInterval: &m.Interval,
+		CompetitionDuration: &m.CompetitionDuration,
+		MinGroupSize:        &m.MinGroupSize,
+		MaxGroupSize:        &m.MaxGroupSize,
+		PromotePercent:      &m.PromotePercent,
+		RelegatePercent:     &m.RelegatePercent,
+		PartyLevel:          &partyLevel,
+		Reason:              "SIGHUP config reload",
+	}
+	if _, err := svc.UpdateConfig(auth.WithActor(ctx, reloadActor), update); err != nil {
+		slog.Error("config reload failed, keeping current settings", "error", err)
+		return
+	}
+	slog.Info("config reloaded")
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"leaderboard-service/internal/auth"
+	"leaderboard-service/internal/config"
+	"leaderboard-service/internal/service"
+	"testing"
+	"time"
+)
+
+type reloadService struct {
+	service.ServiceInterface
+	actor  string
+	update *service.ConfigUpdate
+}
+
+func (s *reloadService) UpdateConfig(ctx context.Context, update service.ConfigUpdate) (service.Config, error) {
+	s.actor, s.update = auth.Actor(ctx), &update
+	return service.Config{}, nil
+}
+
+func TestReload_AppliesMatchmakingSettings(t *testing.T) {
+	svc := &reloadService{}
+	cfg := config.Default()
+	cfg.Matchmaking.Interval = 42 * time.Second
+	reload(context.Background(), svc, func() (*config.Config, error) { return &cfg, nil })
+
+	if svc.update == nil || *svc.update.MatchmakingInterval != 42*time.Second || *svc.update.MaxGroupSize != cfg.Matchmaking.MaxGroupSize {
+		t.Fatalf("unexpected update: %+v", svc.update)
+	}
+	if svc.actor != reloadActor {
+		t.Errorf("expected actor %q, got %q", reloadActor, svc.actor)
+	}
+}
+
+func TestReload_KeepsSettingsOnInvalidConfig(t *testing.T) {
+	svc := &reloadService{}
+	reload(context.Background(), svc, func() (*config.Config, error) { return nil, errors.New("invalid configuration") })
+	if svc.update != nil {
+		t.Errorf("expected no update, got %+v", svc.update)
+	}
+}
@@ -0,0 +1,150 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"leaderboard-service/internal/auth"
+	"leaderboard-service/internal/logging"
+	"leaderboard-service/internal/model"
+	"leaderboard-service/internal/service"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultAuditLimit = 50
+	maxAuditLimit     = 500
+)
+
+// requireAdmin authenticates admin requests with a bearer token and records
+// the matching actor in the request context. With no tokens configured the
+// admin API is disabled.
+func (h *Handler) requireAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(h.adminTokens) == 0 {
+			writeError(w, http.StatusForbidden, "admin API is disabled")
+			return
+		}
+		actor, ok := auth.Authenticate(h.adminTokens, r.Header.Get("Authorization"))
+		if !ok {
+			logger(r.Context()).Warn("admin authentication failed", "path", r.URL.Path)
+			w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+			writeError(w, http.StatusUnauthorized, "invalid or missing admin token")
+			return
+		}
+		ctx := logging.With(auth.WithActor(r.Context(), actor), "actor", actor)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func (h *Handler) GetSettingsHandler(w http.ResponseWriter, r *http.Request) {
+	config, err := h.service.GetConfig(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, config)
+}
+
+func (h *Handler) UpdateSettingsHandler(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		MatchmakingInterval *string           `json:"matchmaking_interval"`
+		CompetitionDuration *string           `json:"competition_duration"`
+		MinGroupSize        *int              `json:"min_group_size"`
+		MaxGroupSize        *int              `json:"max_group_size"`
+		PromotePercent      *int              `json:"promote_percent"`
+		RelegatePercent     *int              `json:"relegate_percent"`
+		PartyLevel          *model.PartyLevel `json:"party_level"`
+		Reason              string            `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	update := service.ConfigUpdate{
+		MinGroupSize:    req.MinGroupSize,
+		MaxGroupSize:    req.MaxGroupSize,
+		PromotePercent:  req.PromotePercent,
+		RelegatePercent: req.RelegatePercent,
+		PartyLevel:      req.PartyLevel,
+		Reason:          req.Reason,
+	}
+	for _, d := range []struct {
+		name string
+		raw  *string
+		dst  **time.Duration
+	}{
+		{"matchmaking_interval", req.MatchmakingInterval, &update.MatchmakingInterval},
+		{"competition_duration", req.CompetitionDuration, &update.CompetitionDuration},
+	} {
+		if d.raw == nil {
+			continue
+		}
+		v, err := time.ParseDuration(*d.raw)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, d.name+" must be a duration such as 15s or 1m")
+			return
+		}
+		*d.dst = &v
+	}
+
+	config, err := h.service.UpdateConfig(r.Context(), update)
+	if err != nil {
+		writeAdminError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, config)
+}
+
+func (h *Handler) AuditLogHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter := model.AuditFilter{
+		Actor:  q.Get("actor"),
+		Action: q.Get("action"),
+		Target: q.Get("target"),
+		Limit:  defaultAuditLimit,
+	}
+	if raw := q.Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > maxAuditLimit {
+			writeError(w, http.StatusBadRequest, "limit must be between 1 and 500")
+			return
+		}
+		filter.Limit = n
+	}
+	entries, err := h.service.ListAuditEntries(r.Context(), filter)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"entries": entries})
+}
+
+// writeAdminError maps the service's admin error kinds to status codes.
+func writeAdminError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidArgument):
+		writeError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrForbidden):
+		writeError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, service.ErrNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrConflict):
+		writeError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, service.ErrPreconditionFailed):
+		writeError(w, http.StatusPreconditionFailed, err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, code int, msg string) {
+	writeJSON(w, code, map[string]string{"error": msg})
+}
@@ -0,0 +1,507 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"leaderboard-service/internal/auth"
+	"leaderboard-service/internal/model"
+	"leaderboard-service/internal/service"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testAdminTokens = map[string]string{"alice": "alice-token-0123456789"}
+
+func adminRequest(method, path, body, token string) *http.Request {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return req
+}
+
+func TestAdmin_RequiresToken(t *testing.T) {
+	svc := &mockService{GetConfigFunc: func(ctx context.Context) (service.Config, error) {
+		return service.Config{}, nil
+	}}
+	cases := []struct {
+		name   string
+		opts   []HandlerOption
+		token  string
+		status int
+	}{
+		{"disabled", nil, "alice-token-0123456789", http.StatusForbidden},
+		{"missing token", []HandlerOption{WithAdminTokens(testAdminTokens)}, "", http.StatusUnauthorized},
+		{"wrong token", []HandlerOption{WithAdminTokens(testAdminTokens)}, "nope", http.StatusUnauthorized},
+		{"valid token", []HandlerOption{WithAdminTokens(testAdminTokens)}, "alice-token-0123456789", http.StatusOK},
+	}
+	for _, tc := range cases {
+		router := NewRouter(NewHandler(svc, tc.opts...))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, adminRequest("GET", "/v1/admin/settings", "", tc.token))
+		if rr.Code != tc.status {
+			t.Errorf("%s: expected %d, got %d: %s", tc.name, tc.status, rr.Code, rr.Body.String())
+		}
+	}
+}
+
+func TestUpdateSettingsHandler(t *testing.T) {
+	var gotActor string
+	var got service.ConfigUpdate
+	svc := &mockService{UpdateConfigFunc: func(ctx context.Context, update service.ConfigUpdate) (service.Config, error) {
+		gotActor, got = auth.Actor(ctx), update
+		return service.Config{MatchmakingInterval: *update.MatchmakingInterval, CompetitionDuration: time.Minute, MinGroupSize: 2}, nil
+	}}
+	router := NewRouter(NewHandler(svc, WithAdminTokens(testAdminTokens)))
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, adminRequest("PATCH", "/v1/admin/settings",
+		`{"matchmaking_interval": "5s", "reason": "load test"}`, "alice-token-0123456789"))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if gotActor != "alice" {
+		t.Errorf("expected actor alice, got %q", gotActor)
+	}
+	if got.MatchmakingInterval == nil || *got.MatchmakingInterval != 5*time.Second || got.CompetitionDuration != nil || got.Reason != "load test" {
+		t.Errorf("unexpected update: %+v", got)
+	}
+	var body map[string]interface{}
+	json.Unmarshal(rr.Body.Bytes(), &body)
+	if body["matchmaking_interval"] != "5s" || body["competition_duration"] != "1m0s" {
+		t.Errorf("unexpected response: %v", body)
+	}
+}
+
+func TestUpdateSettingsHandler_Errors(t *testing.T) {
+	svc := &mockService{UpdateConfigFunc: func(ctx context.Context, update service.ConfigUpdate) (service.Config, error) {
+		if update.MinGroupSize != nil {
+			return service.Config{}, fmt.Errorf("%w: max_group_size must be 0 or at least min_group_size", service.ErrInvalidArgument)
+		}
+		return service.Config{}, errors.New("db error")
+	}}
+	router := NewRouter(NewHandler(svc, WithAdminTokens(testAdminTokens)))
+	cases := map[string]int{
+		`{"matchmaking_interval": "soon"}`: http.StatusBadRequest,
+		`{"min_group_size": 9}`:            http.StatusBadRequest,
+		`{"unknown": 1}`:                   http.StatusBadRequest,
+		`{"reason": "x"}`:                  http.StatusInternalServerError,
+	}
+	for body, status := range cases {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, adminRequest("PATCH", "/v1/admin/settings", body, "alice-token-0123456789"))
+		if rr.Code != status {
+			t.Errorf("%s: expected %d, got %d: %s", body, status, rr.Code, rr.Body.String())
+		}
+	}
+}
+
+func TestAuditLogHandler(t *testing.T) {
+	var got model.AuditFilter
+	svc := &mockService{ListAuditEntriesFunc: func(ctx context.Context, filter model.AuditFilter) ([]model.AuditEntry, error) {
+		got = filter
+		return []model.AuditEntry{{ID: 1, Actor: "alice", Action: service.AuditActionSettingsUpdate, Target: "matchmaking"}}, nil
+	}}
+	router := NewRouter(NewHandler(svc, WithAdminTokens(testAdminTokens)))
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, adminRequest("GET", "/v1/admin/audit?actor=alice", "", "alice-token-0123456789"))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if got.Actor != "alice" || got.Limit != defaultAuditLimit {
+		t.Errorf("unexpected filter: %+v", got)
+	}
+	if !strings.Contains(rr.Body.String(), `"action":"settings.update"`) {
+		t.Errorf("unexpected body: %s", rr.Body.String())
+	}
+}
+
+func TestListCompetitionsHandler(t *testing.T) {
+	var got model.CompetitionFilter
+	svc := &mockService{ListCompetitionsFunc: func(ctx context.Context, filter model.CompetitionFilter) ([]model.Competition, error) {
+		got = filter
+		return []model.Competition{{Level: 3, Status: model.CompetitionActive}}, nil
+	}}
+	router := NewRouter(NewHandler(svc, WithAdminTokens(testAdminTokens)))
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, adminRequest("GET", "/v1/admin/competitions?status=ACTIVE&level=3&from=2024-05-01T00:00:00Z", "", "alice-token-0123456789"))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if got.Status != model.CompetitionActive || got.Level == nil || *got.Level != 3 ||
+		!got.From.Equal(time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)) || !got.To.IsZero() || got.Limit != defaultCompetitionLimit {
+		t.Errorf("unexpected filter: %+v", got)
+	}
+	if !strings.Contains(rr.Body.String(), `"status":"ACTIVE"`) {
+		t.Errorf("unexpected body: %s", rr.Body.String())
+	}
+
+	for _, query := range []string{"status=DONE", "from=yesterday", "limit=0"} {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, adminRequest("GET", "/v1/admin/competitions?"+query, "", "alice-token-0123456789"))
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", query, rr.Code)
+		}
+	}
+}
+
+func TestCompetitionActionHandlers(t *testing.T) {
+	svc := &mockService{
+		CancelCompetitionFunc: func(ctx context.Context, competitionID, reason string) (*model.Competition, error) {
+			switch competitionID {
+			case "gone":
+				return nil, fmt.Errorf("%w: competition not found", service.ErrNotFound)
+			case "done":
+				return nil, fmt.Errorf("%w: competition is COMPLETED", service.ErrConflict)
+			}
+			return &model.Competition{Status: model.CompetitionCancelled}, nil
+		},
+		SetCompetitionEndsAtFunc: func(ctx context.Context, competitionID string, endsAt time.Time, reason string) (*model.Competition, error) {
+			return &model.Competition{EndsAt: endsAt}, nil
+		},
+		ExcludePlayerFunc: func(ctx context.Context, competitionID, playerID string, status model.PlayerStatus, reason string) error {
+			if competitionID != "c1" || playerID != "p1" || status != model.StatusDisqualified || reason != "cheating" {
+				t.Errorf("unexpected exclusion: %s %s %s %q", competitionID, playerID, status, reason)
+			}
+			return nil
+		},
+	}
+	router := NewRouter(NewHandler(svc, WithAdminTokens(testAdminTokens)))
+	cases := []struct {
+		method, path, body string
+		status             int
+	}{
+		{"POST", "/v1/admin/competitions/c1/cancel", "", http.StatusOK},
+		{"POST", "/v1/admin/competitions/c1/cancel", `{"reason": "outage"}`, http.StatusOK},
+		{"POST", "/v1/admin/competitions/gone/cancel", "", http.StatusNotFound},
+		{"POST", "/v1/admin/competitions/done/cancel", "", http.StatusConflict},
+		{"PATCH", "/v1/admin/competitions/c1", `{"ends_at": "2030-01-01T00:00:00Z"}`, http.StatusOK},
+		{"PATCH", "/v1/admin/competitions/c1", `{"reason": "x"}`, http.StatusBadRequest},
+		{"POST", "/v1/admin/competitions/c1/players/p1/disqualify", `{"reason": "cheating"}`, http.StatusOK},
+	}
+	for _, tc := range cases {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, adminRequest(tc.method, tc.path, tc.body, "alice-token-0123456789"))
+		if rr.Code != tc.status {
+			t.Errorf("%s %s %s: expected %d, got %d: %s", tc.method, tc.path, tc.body, tc.status, rr.Code, rr.Body.String())
+		}
+	}
+}
+
+func TestAdjustScoreHandler(t *testing.T) {
+	var got service.ScoreAdjustment
+	svc := &mockService{AdjustScoreFunc: func(ctx context.Context, competitionID, playerID string, adjustment service.ScoreAdjustment) (*service.ScoreChange, error) {
+		got = adjustment
+		if adjustment.Score != nil && adjustment.Delta != nil {
+			return nil, fmt.Errorf("%w: exactly one of score and delta is required", service.ErrInvalidArgument)
+		}
+		return &service.ScoreChange{LeaderboardID: competitionID, PlayerID: playerID, PreviousScore: 10, Score: *adjustment.Score, Rank: 1}, nil
+	}}
+	router := NewRouter(NewHandler(svc, WithAdminTokens(testAdminTokens)))
+	path := "/v1/admin/competitions/c1/players/p1/score"
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, adminRequest("POST", path, `{"score": 25, "reason": "bug #12"}`, "alice-token-0123456789"))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if got.Score == nil || *got.Score != 25 || got.Delta != nil || got.Reason != "bug #12" {
+		t.Errorf("unexpected adjustment: %+v", got)
+	}
+	if !strings.Contains(rr.Body.String(), `"previous_score":10`) {
+		t.Errorf("unexpected body: %s", rr.Body.String())
+	}
+
+	for body, status := range map[string]int{
+		`{"score": 25}`: http.StatusBadRequest,
+		`{"score": 1, "delta": 2, "reason": "x"}`: http.StatusBadRequest,
+	} {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, adminRequest("POST", path, body, "alice-token-0123456789"))
+		if rr.Code != status {
+			t.Errorf("%s: expected %d, got %d", body, status, rr.Code)
+		}
+	}
+}
+
+func TestScheduleCompetitionHandler(t *testing.T) {
+	var got service.CompetitionSchedule
+	svc := &mockService{ScheduleCompetitionFunc: func(ctx context.Context, schedule service.CompetitionSchedule) (*model.Competition, error) {
+		got = schedule
+		if schedule.Name == "" {
+			return nil, fmt.Errorf("%w: name is required", service.ErrInvalidArgument)
+		}
+		return &model.Competition{Name: schedule.Name, Kind: model.CompetitionKindScheduled, Status: model.CompetitionScheduled,
+			StartedAt: schedule.StartsAt, EndsAt: schedule.EndsAt, RegistrationOpensAt: &schedule.RegistrationOpensAt}, nil
+	}}
+	router := NewRouter(NewHandler(svc, WithAdminTokens(testAdminTokens)))
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, adminRequest("POST", "/v1/admin/competitions", `{"name": "Weekend Cup",
+		"registration_opens_at": "2030-01-03T00:00:00Z", "starts_at": "2030-01-04T00:00:00Z",
+		"ends_at": "2030-01-06T00:00:00Z", "max_players": 64}`, "alice-token-0123456789"))
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if got.Name != "Weekend Cup" || got.MaxPlayers != 64 || got.RegistrationOpensAt.Day() != 3 || got.StartsAt.Day() != 4 {
+		t.Errorf("unexpected schedule: %+v", got)
+	}
+	if !strings.Contains(rr.Body.String(), `"kind":"SCHEDULED"`) {
+		t.Errorf("unexpected body: %s", rr.Body.String())
+	}
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, adminRequest("POST", "/v1/admin/competitions", `{"name": "",
+		"starts_at": "2030-01-04T00:00:00Z", "ends_at": "2030-01-06T00:00:00Z"}`, "alice-token-0123456789"))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestTemplateHandlers(t *testing.T) {
+	var got service.TemplateDefinition
+	svc := &mockService{
+		CreateTemplateFunc: func(ctx context.Context, def service.TemplateDefinition) (*model.CompetitionTemplate, error) {
+			got = def
+			return &model.CompetitionTemplate{Name: def.Name, Schedule: def.Schedule, Duration: def.Duration, ScoringMode: def.ScoringMode, Enabled: def.Enabled}, nil
+		},
+		PreviewTemplateFunc: func(ctx context.Context, templateID string, count int) ([]service.Occurrence, error) {
+			if count > service.MaxPreviewOccurrences {
+				return nil, fmt.Errorf("%w: count must be between 1 and 100", service.ErrInvalidArgument)
+			}
+			start := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+			return []service.Occurrence{{StartsAt: start, EndsAt: start.Add(24 * time.Hour)}}, nil
+		},
+	}
+	router := NewRouter(NewHandler(svc, WithAdminTokens(testAdminTokens)))
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, adminRequest("POST", "/v1/admin/templates", `{"name": "Daily Sprint", "schedule": "@daily", "duration": "24h",
+		"eligibility": {"min_level": 5, "countries": ["DE"]}, "rewards": [{"from_rank": 1, "to_rank": 3, "reward": "gold"}]}`, "alice-token-0123456789"))
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if got.Duration != 24*time.Hour || !got.Enabled || got.ScoringMode != model.ScoringSum || got.Eligibility.MinLevel != 5 || len(got.Rewards) != 1 {
+		t.Errorf("unexpected definition: %+v", got)
+	}
+	if !strings.Contains(rr.Body.String(), `"duration":"24h0m0s"`) {
+		t.Errorf("unexpected body: %s", rr.Body.String())
+	}
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, adminRequest("POST", "/v1/admin/templates", `{"name": "x", "schedule": "@daily", "duration": "a day"}`, "alice-token-0123456789"))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for a bad duration, got %d", rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, adminRequest("GET", "/v1/admin/templates/t1/occurrences?count=3", "", "alice-token-0123456789"))
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), `"starts_at":"2030-01-01T00:00:00Z"`) {
+		t.Errorf("unexpected preview: %d %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestTournamentHandlers(t *testing.T) {
+	var got service.TournamentDefinition
+	svc := &mockService{
+		CreateTournamentFunc: func(ctx context.Context, def service.TournamentDefinition) (*model.Tournament, error) {
+			got = def
+			return &model.Tournament{Name: def.Name, Format: def.Format, Seeding: def.Seeding, Seeds: def.PlayerIDs, GroupSize: def.GroupSize,
+				Advance: def.Advance, RoundDuration: def.RoundDuration, Round: 1, Rounds: 2, Status: model.TournamentActive}, nil
+		},
+		GetTournamentFunc: func(ctx context.Context, tournamentID string) (*service.Bracket, error) {
+			if tournamentID != "t1" {
+				return nil, fmt.Errorf("%w: tournament not found", service.ErrNotFound)
+			}
+			return &service.Bracket{Tournament: model.Tournament{Name: "Cup"}, Rounds: []service.BracketRound{
+				{Round: 1, Status: service.RoundPending, Matches: []service.BracketMatch{{Slot: 0, Players: []string{"p1", "p2"}}}},
+			}}, nil
+		},
+	}
+	router := NewRouter(NewHandler(svc, WithAdminTokens(testAdminTokens)))
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, adminRequest("POST", "/v1/admin/tournaments", `{"name": "Cup", "format": "SINGLE_ELIMINATION",
+		"player_ids": ["p1", "p2", "p3", "p4"], "group_size": 2, "advance": 1, "round_duration": "1h"}`, "alice-token-0123456789"))
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if got.Seeding != model.SeedByLevel || got.RoundDuration != time.Hour || len(got.PlayerIDs) != 4 {
+		t.Errorf("unexpected definition: %+v", got)
+	}
+	if !strings.Contains(rr.Body.String(), `"round_duration":"1h0m0s"`) {
+		t.Errorf("unexpected body: %s", rr.Body.String())
+	}
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, adminRequest("POST", "/v1/admin/tournaments", `{"name": "Cup", "format": "SINGLE_ELIMINATION",
+		"player_ids": ["p1", "p2"], "group_size": 2, "round_duration": "an hour"}`, "alice-token-0123456789"))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for a bad round_duration, got %d", rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest("GET", "/v1/tournaments/t1", nil))
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), `"players":["p1","p2"]`) {
+		t.Errorf("unexpected bracket: %d %s", rr.Code, rr.Body.String())
+	}
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest("GET", "/v1/tournaments/t2", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", rr.Code)
+	}
+}
+
+func TestPartyHandlers(t *testing.T) {
+	party := &model.Party{LeaderID: "lead", Members: []string{"lead"}, Invites: []string{}}
+	var invited string
+	svc := &mockService{
+		CreatePartyFunc: func(ctx context.Context, playerID string) (*model.Party, error) {
+			if playerID != "lead" {
+				return nil, fmt.Errorf("%w: player already in a party", service.ErrConflict)
+			}
+			return party, nil
+		},
+		InviteToPartyFunc: func(ctx context.Context, partyID, playerID, inviteeID string) error {
+			if playerID != "lead" {
+				return fmt.Errorf("%w: only the party leader can invite players", service.ErrForbidden)
+			}
+			invited = inviteeID
+			return nil
+		},
+		QueuePartyFunc: func(ctx context.Context, partyID, playerID string) error {
+			return nil
+		},
+	}
+	router := NewRouter(NewHandler(svc))
+	id := party.PartyID.String()
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest("POST", "/v1/party?player_id=lead", nil))
+	if rr.Code != http.StatusCreated || !strings.Contains(rr.Body.String(), `"leader_id":"lead"`) {
+		t.Errorf("unexpected create: %d %s", rr.Code, rr.Body.String())
+	}
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest("POST", "/v1/party?player_id=p2", nil))
+	if rr.Code != http.StatusConflict {
+		t.Errorf("expected 409, got %d", rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest("POST", "/v1/party/"+id+"/invite?player_id=p2&invitee_id=p3", nil))
+	if rr.Code != http.StatusForbidden {
+		t.Errorf("expected 403 for a member, got %d", rr.Code)
+	}
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest("POST", "/v1/party/"+id+"/invite?player_id=lead&invitee_id=p3", nil))
+	if rr.Code != http.StatusOK || invited != "p3" {
+		t.Errorf("unexpected invite: %d %q", rr.Code, invited)
+	}
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest("POST", "/v1/party/"+id+"/queue?player_id=lead", nil))
+	if rr.Code != http.StatusAccepted {
+		t.Errorf("expected 202, got %d", rr.Code)
+	}
+}
+
+func TestRegionHandlers(t *testing.T) {
+	var got service.RegionDefinition
+	svc := &mockService{
+		PutRegionFunc: func(ctx context.Context, regionCode string, def service.RegionDefinition) (*model.Region, error) {
+			if len(def.Countries) == 1 && def.Countries[0] == "XX" {
+				return nil, fmt.Errorf("%w: country_code \"XX\" is not an ISO 3166-1 alpha-2 code", service.ErrInvalidArgument)
+			}
+			got = def
+			return &model.Region{RegionCode: regionCode, Name: def.Name, Countries: def.Countries}, nil
+		},
+		DeleteRegionFunc: func(ctx context.Context, regionCode, reason string) error {
+			return fmt.Errorf("%w: region not found", service.ErrNotFound)
+		},
+	}
+	router := NewRouter(NewHandler(svc, WithAdminTokens(testAdminTokens)))
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, adminRequest("PUT", "/v1/admin/regions/DACH", `{"name": "DACH", "countries": ["DE", "AT", "CH"], "reason": "launch"}`, "alice-token-0123456789"))
+	if rr.Code != http.StatusOK || got.Reason != "launch" || len(got.Countries) != 3 {
+		t.Errorf("unexpected region update: %d %+v", rr.Code, got)
+	}
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, adminRequest("PUT", "/v1/admin/regions/DACH", `{"name": "DACH", "countries": ["XX"]}`, "alice-token-0123456789"))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an invalid country, got %d", rr.Code)
+	}
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, adminRequest("PUT", "/v1/admin/regions/DACH", `{"name": "DACH", "countries": ["DE"]}`, ""))
+	if rr.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 without a token, got %d", rr.Code)
+	}
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, adminRequest("DELETE", "/v1/admin/regions/ZZ", `{"reason": "cleanup"}`, "alice-token-0123456789"))
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown region, got %d", rr.Code)
+	}
+}
+
+func TestModerationHandlers(t *testing.T) {
+	type call struct {
+		sanction model.Sanction
+		until    time.Time
+		reason   string
+		lift     bool
+	}
+	var calls []call
+	svc := &mockService{
+		GetModerationFunc: func(ctx context.Context, playerID string) (*model.Moderation, error) {
+			return nil, fmt.Errorf("%w: player not found", service.ErrNotFound)
+		},
+		ApplySanctionFunc: func(ctx context.Context, playerID string, sanction model.Sanction, until time.Time, reason string) (*model.Moderation, error) {
+			calls = append(calls, call{sanction, until, reason, false})
+			return &model.Moderation{PlayerID: playerID}, nil
+		},
+		LiftSanctionFunc: func(ctx context.Context, playerID string, sanction model.Sanction, reason string) (*model.Moderation, error) {
+			calls = append(calls, call{sanction: sanction, reason: reason, lift: true})
+			return nil, fmt.Errorf("%w: player not banned", service.ErrConflict)
+		},
+	}
+	router := NewRouter(NewHandler(svc, WithAdminTokens(testAdminTokens)))
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, adminRequest("PUT", "/v1/admin/players/p1/shadow-ban", `{"reason": "bot"}`, "alice-token-0123456789"))
+	if rr.Code != http.StatusOK || len(calls) != 1 || calls[0] != (call{sanction: model.SanctionShadowBan, reason: "bot"}) {
+		t.Errorf("unexpected shadow-ban: %d %+v", rr.Code, calls)
+	}
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, adminRequest("PUT", "/v1/admin/players/p1/suspension", `{"until": "2030-01-01T00:00:00Z"}`, "alice-token-0123456789"))
+	if rr.Code != http.StatusOK || len(calls) != 2 || calls[1].sanction != model.SanctionSuspension || calls[1].until.Year() != 2030 {
+		t.Errorf("unexpected suspension: %d %+v", rr.Code, calls)
+	}
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, adminRequest("PUT", "/v1/admin/players/p1/suspension", `{"reason": "spam"}`, "alice-token-0123456789"))
+	if rr.Code != http.StatusBadRequest || len(calls) != 2 {
+		t.Errorf("expected 400 for a suspension without until, got %d", rr.Code)
+	}
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, adminRequest("DELETE", "/v1/admin/players/p1/ban", `{"reason": "appeal"}`, "alice-token-0123456789"))
+	if rr.Code != http.StatusConflict || len(calls) != 3 || calls[2] != (call{sanction: model.SanctionBan, reason: "appeal", lift: true}) {
+		t.Errorf("unexpected ban lift: %d %+v", rr.Code, calls)
+	}
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, adminRequest("GET", "/v1/admin/players/ghost/sanctions", "", "alice-token-0123456789"))
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown player, got %d", rr.Code)
+	}
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, adminRequest("PUT", "/v1/admin/players/p1/ban", "", ""))
+	if rr.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 without a token, got %d", rr.Code)
+	}
+}
@@ -0,0 +1,194 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"leaderboard-service/internal/model"
+	"leaderboard-service/internal/service"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	defaultCompetitionLimit = 50
+	maxCompetitionLimit     = 500
+)
+
+func (h *Handler) ListCompetitionsHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter := model.CompetitionFilter{
+		Status:      model.CompetitionStatus(q.Get("status")),
+		CountryCode: q.Get("country_code"),
+		Limit:       defaultCompetitionLimit,
+	}
+	if raw := q.Get("level"); raw != "" {
+		level, err := strconv.Atoi(raw)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "level must be an integer")
+			return
+		}
+		filter.Level = &level
+	}
+	for _, t := range []struct {
+		name string
+		dst  *time.Time
+	}{{"from", &filter.From}, {"to", &filter.To}} {
+		raw := q.Get(t.name)
+		if raw == "" {
+			continue
+		}
+		v, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, t.name+" must be an RFC 3339 timestamp")
+			return
+		}
+		*t.dst = v
+	}
+	if raw := q.Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > maxCompetitionLimit {
+			writeError(w, http.StatusBadRequest, "limit must be between 1 and 500")
+			return
+		}
+		filter.Limit = n
+	}
+	comps, err := h.service.ListCompetitions(r.Context(), filter)
+	if err != nil {
+		writeAdminError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"competitions": comps})
+}
+
+func (h *Handler) ScheduleCompetitionHandler(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Name                string     `json:"name"`
+		RegistrationOpensAt *time.Time `json:"registration_opens_at"`
+		StartsAt            time.Time  `json:"starts_at"`
+		EndsAt              time.Time  `json:"ends_at"`
+		MaxPlayers          int        `json:"max_players"`
+		Reason              string     `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	schedule := service.CompetitionSchedule{
+		Name:       req.Name,
+		StartsAt:   req.StartsAt,
+		EndsAt:     req.EndsAt,
+		MaxPlayers: req.MaxPlayers,
+		Reason:     req.Reason,
+	}
+	if req.RegistrationOpensAt != nil {
+		schedule.RegistrationOpensAt = *req.RegistrationOpensAt
+	}
+	comp, err := h.service.ScheduleCompetition(r.Context(), schedule)
+	if err != nil {
+		writeAdminError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, comp)
+}
+
+func (h *Handler) UpdateCompetitionHandler(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		EndsAt *time.Time `json:"ends_at"`
+		Reason string     `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.EndsAt == nil {
+		writeError(w, http.StatusBadRequest, "ends_at is required")
+		return
+	}
+	comp, err := h.service.SetCompetitionEndsAt(r.Context(), mux.Vars(r)["competition_id"], *req.EndsAt, req.Reason)
+	if err != nil {
+		writeAdminError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, comp)
+}
+
+func (h *Handler) CancelCompetitionHandler(w http.ResponseWriter, r *http.Request) {
+	reason, ok := decodeReason(w, r)
+	if !ok {
+		return
+	}
+	comp, err := h.service.CancelCompetition(r.Context(), mux.Vars(r)["competition_id"], reason)
+	if err != nil {
+		writeAdminError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, comp)
+}
+
+func (h *Handler) CompleteCompetitionHandler(w http.ResponseWriter, r *http.Request) {
+	reason, ok := decodeReason(w, r)
+	if !ok {
+		return
+	}
+	comp, err := h.service.CompleteCompetition(r.Context(), mux.Vars(r)["competition_id"], reason)
+	if err != nil {
+		writeAdminError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, comp)
+}
+
+// ExcludePlayerHandler returns the handler that takes a player out of a
+// competition with status.
+func (h *Handler) ExcludePlayerHandler(status model.PlayerStatus) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reason, ok := decodeReason(w, r)
+		if !ok {
+			return
+		}
+		vars := mux.Vars(r)
+		if err := h.service.ExcludePlayer(r.Context(), vars["competition_id"], vars["player_id"], status, reason); err != nil {
+			writeAdminError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"message": "Player " + strings.ToLower(string(status))})
+	}
+}
+
+func (h *Handler) AdjustScoreHandler(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Score  *int   `json:"score"`
+		Delta  *int   `json:"delta"`
+		Reason string `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	vars := mux.Vars(r)
+	change, err := h.service.AdjustScore(r.Context(), vars["competition_id"], vars["player_id"],
+		service.ScoreAdjustment{Score: req.Score, Delta: req.Delta, Reason: req.Reason})
+	if err != nil {
+		writeAdminError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, change)
+}
+
+// decodeReason reads the optional {"reason": "..."} body of an admin action,
+// writing a 400 response if it is malformed.
+func decodeReason(w http.ResponseWriter, r *http.Request) (string, bool) {
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return "", false
+	}
+	return req.Reason, true
+}
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+func (h *Handler) ListFriendsHandler(w http.ResponseWriter, r *http.Request) {
+	friends, err := h.service.ListFriends(r.Context(), mux.Vars(r)["player_id"])
+	if err != nil {
+		writeAdminError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, friends)
+}
+
+func (h *Handler) AddFriendHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	if err := h.service.AddFriend(r.Context(), vars["player_id"], vars["friend_id"]); err != nil {
+		writeAdminError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"message": "Friend added"})
+}
+
+// ImportFriendsHandler follows every player in a friend list, e.g. one synced
+// from the platform identity service.
+func (h *Handler) ImportFriendsHandler(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		FriendIDs []string `json:"friend_ids"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	result, err := h.service.ImportFriends(r.Context(), mux.Vars(r)["player_id"], req.FriendIDs)
+	if err != nil {
+		writeAdminError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (h *Handler) RemoveFriendHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	if err := h.service.RemoveFriend(r.Context(), vars["player_id"], vars["friend_id"]); err != nil {
+		writeAdminError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"message": "Friend removed"})
+}
+
+// FriendsLeaderboardHandler ranks the player_id in the query against their
+// friends in one competition.
+func (h *Handler) FriendsLeaderboardHandler(w http.ResponseWriter, r *http.Request) {
+	board, err := h.service.GetFriendsLeaderboard(r.Context(), mux.Vars(r)["leaderboardID"], r.URL.Query().Get("player_id"))
+	if err != nil {
+		writeAdminError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, board)
+}
+
+// FriendsStatsHandler ranks a player against their friends by their stats
+// over all completed competitions.
+func (h *Handler) FriendsStatsHandler(w http.ResponseWriter, r *http.Request) {
+	stats, err := h.service.GetFriendsStats(r.Context(), mux.Vars(r)["player_id"])
+	if err != nil {
+		writeAdminError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, stats)
+}
@@ -1,20 +1,47 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"leaderboard-service/internal/logging"
+	"leaderboard-service/internal/model"
 	"leaderboard-service/internal/service"
-	"log"
+	"log/slog"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 )
 
 type Handler struct {
-	service service.ServiceInterface
+	service           service.ServiceInterface
+	heartbeatInterval time.Duration
+	adminTokens       map[string]string
 }
 
-func NewHandler(svc service.ServiceInterface) *Handler {
-	return &Handler{service: svc}
+// HandlerOption configures a Handler.
+type HandlerOption func(*Handler)
+
+// WithAdminTokens enables the admin API for the actors in tokens, which
+// maps actor names to bearer tokens.
+func WithAdminTokens(tokens map[string]string) HandlerOption {
+	return func(h *Handler) { h.adminTokens = tokens }
+}
+
+func NewHandler(svc service.ServiceInterface, opts ...HandlerOption) *Handler {
+	h := &Handler{service: svc, heartbeatInterval: defaultHeartbeatInterval}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// logger returns the request-scoped logger for handler messages.
+func logger(ctx context.Context) *slog.Logger {
+	return logging.FromContext(ctx).With("component", "api")
 }
 
 func (h *Handler) HelloHandler(w http.ResponseWriter, r *http.Request) {
@@ -35,10 +62,14 @@ func (h *Handler) JoinHandler(w http.ResponseWriter, r *http.Request) {
 			w.WriteHeader(http.StatusConflict)
 			json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
 			return
-		case "player already in waiting queue":
+		case "player already in waiting queue", "player in a party":
 			w.WriteHeader(http.StatusConflict)
 			json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
 			return
+		case "player banned", "player suspended":
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+			return
 		default:
 			w.WriteHeader(http.StatusInternalServerError)
 			json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
@@ -50,6 +81,71 @@ func (h *Handler) JoinHandler(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(map[string]string{"message": "Player added to matchmaking queue", "leaderboard_id": leaderboardID})
 }
 
+func (h *Handler) LeaveHandler(w http.ResponseWriter, r *http.Request) {
+	playerID := r.URL.Query().Get("player_id")
+	ctx := r.Context()
+	err := h.service.Leave(ctx, playerID)
+	if err != nil {
+		switch err.Error() {
+		case "player not found":
+			w.WriteHeader(http.StatusNotFound)
+		case "player not in waiting queue":
+			w.WriteHeader(http.StatusConflict)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Player removed from matchmaking queue"})
+}
+
+func (h *Handler) RegisterHandler(w http.ResponseWriter, r *http.Request) {
+	competitionID := mux.Vars(r)["leaderboardID"]
+	playerID := r.URL.Query().Get("player_id")
+	err := h.service.RegisterForCompetition(r.Context(), competitionID, playerID)
+	if err != nil {
+		switch err.Error() {
+		case "player not found", "leaderboard not found":
+			w.WriteHeader(http.StatusNotFound)
+		case "registration is not open", "competition is full", "player already registered", "player already in active competition":
+			w.WriteHeader(http.StatusConflict)
+		case "player not eligible", "player excluded", "player banned", "player suspended":
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Player registered for competition", "leaderboard_id": competitionID})
+}
+
+func (h *Handler) UnregisterHandler(w http.ResponseWriter, r *http.Request) {
+	competitionID := mux.Vars(r)["leaderboardID"]
+	playerID := r.URL.Query().Get("player_id")
+	err := h.service.UnregisterFromCompetition(r.Context(), competitionID, playerID)
+	if err != nil {
+		switch err.Error() {
+		case "player not found", "leaderboard not found":
+			w.WriteHeader(http.StatusNotFound)
+		case "player not registered":
+			w.WriteHeader(http.StatusConflict)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Player unregistered from competition"})
+}
+
 func (h *Handler) PlayerLeaderboardHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	playerID := vars["player_id"]
@@ -72,7 +168,17 @@ func (h *Handler) LeaderboardHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	leaderboardID := vars["leaderboardID"]
 	ctx := r.Context()
-	log.Printf("[Handler] /leaderboard/%s called", leaderboardID)
+	logger(ctx).Debug("leaderboard requested", "competition_id", leaderboardID)
+	q := r.URL.Query()
+	if filter := (model.GeoFilter{CountryCode: q.Get("country_code"), RegionCode: q.Get("region")}); filter != (model.GeoFilter{}) {
+		board, err := h.service.GetGeoLeaderboard(ctx, leaderboardID, filter)
+		if err != nil {
+			writeAdminError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, board)
+		return
+	}
 	resp, err := h.service.GetLeaderboard(ctx, leaderboardID)
 	if err != nil {
 		w.WriteHeader(http.StatusNotFound)
@@ -94,7 +200,7 @@ func (h *Handler) ScoreHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	ctx := r.Context()
-	log.Printf("[Handler] /leaderboard/score called for player %s", req.PlayerID)
+	logger(ctx).Debug("score submitted", "player_id", req.PlayerID)
 	err := h.service.SubmitScore(ctx, req.PlayerID, req.Score)
 	if err != nil {
 		if err.Error() == "player not found" {
@@ -107,6 +213,11 @@ func (h *Handler) ScoreHandler(w http.ResponseWriter, r *http.Request) {
 			json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
 			return
 		}
+		if err.Error() == "player banned" || err.Error() == "player suspended" {
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+			return
+		}
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
 		return
@@ -115,11 +226,51 @@ func (h *Handler) ScoreHandler(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
 
+// profileRequest is the body of POST /player and PUT /player/{player_id}.
+type profileRequest struct {
+	Level       *int            `json:"level"`
+	CountryCode *string         `json:"country_code"`
+	DisplayName *string         `json:"display_name"`
+	AvatarURL   *string         `json:"avatar_url"`
+	Metadata    json.RawMessage `json:"metadata"`
+}
+
+func (req profileRequest) profile() service.PlayerProfile {
+	return service.PlayerProfile{
+		Level:       req.Level,
+		CountryCode: req.CountryCode,
+		DisplayName: req.DisplayName,
+		AvatarURL:   req.AvatarURL,
+		Metadata:    req.Metadata,
+	}
+}
+
+// playerETag is the entity tag of a version of a player.
+func playerETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// ifMatchVersion returns the player version an If-Match header asks for: 0
+// if there is none or it is "*", and -1 if it names no player version.
+func ifMatchVersion(r *http.Request) int {
+	tag := strings.TrimSpace(r.Header.Get("If-Match"))
+	if tag == "" || tag == "*" {
+		return 0
+	}
+	if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+		return -1
+	}
+	version, err := strconv.Atoi(tag[1 : len(tag)-1])
+	if err != nil || version < 1 {
+		return -1
+	}
+	return version
+}
+
 func (h *Handler) CreatePlayerHandler(w http.ResponseWriter, r *http.Request) {
 	var req struct {
-		PlayerID    string `json:"player_id"`
-		Level       int    `json:"level"`
-		CountryCode string `json:"country_code"`
+		PlayerID string `json:"player_id"`
+		profileRequest
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		w.WriteHeader(http.StatusBadRequest)
@@ -127,12 +278,21 @@ func (h *Handler) CreatePlayerHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	ctx := r.Context()
-	err := h.service.CreatePlayer(ctx, req.PlayerID, req.Level, req.CountryCode)
+	player, err := h.service.CreatePlayer(ctx, req.PlayerID, req.profile())
+	if errors.Is(err, service.ErrInvalidArgument) {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if errors.Is(err, service.ErrConflict) {
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	}
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
 		return
 	}
+	w.Header().Set("ETag", playerETag(player.Version))
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(map[string]string{"message": "Player created"})
 }
@@ -148,28 +308,48 @@ func (h *Handler) GetPlayerHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", playerETag(player.Version))
 	json.NewEncoder(w).Encode(player)
 }
 
+// UpdatePlayerHandler updates a player's profile. With an If-Match header
+// carrying the ETag of GET /player/{player_id} the update only applies if
+// the player has not changed since; otherwise it fails with 412.
 func (h *Handler) UpdatePlayerHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	playerID := vars["player_id"]
-	var req struct {
-		Level       int    `json:"level"`
-		CountryCode string `json:"country_code"`
-	}
+	var req profileRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		w.WriteHeader(http.StatusBadRequest)
 		json.NewEncoder(w).Encode(map[string]string{"error": "invalid request body"})
 		return
 	}
+	profile := req.profile()
+	if profile.Version = ifMatchVersion(r); profile.Version < 0 {
+		writeError(w, http.StatusPreconditionFailed, "If-Match does not match a player version")
+		return
+	}
 	ctx := r.Context()
-	err := h.service.UpdatePlayer(ctx, playerID, req.Level, req.CountryCode)
+	player, err := h.service.UpdatePlayer(ctx, playerID, profile)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		writeAdminError(w, err)
 		return
 	}
+	w.Header().Set("ETag", playerETag(player.Version))
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(map[string]string{"message": "Player updated"})
 }
+
+func (h *Handler) LeagueHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	playerID := mux.Vars(r)["player_id"]
+	moves, err := h.service.GetLeagueHistory(r.Context(), playerID)
+	if err != nil {
+		code := http.StatusInternalServerError
+		if err.Error() == "player not found" {
+			code = http.StatusNotFound
+		}
+		writeError(w, code, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, moves)
+}
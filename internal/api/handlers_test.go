@@ -5,31 +5,204 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"leaderboard-service/internal/model"
+	"leaderboard-service/internal/service"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gorilla/mux"
 )
 
 type mockService struct {
-	CreatePlayerFunc         func(ctx context.Context, playerID string, level int, countryCode string) error
-	JoinFunc                 func(ctx context.Context, playerID string) (string, error)
-	GetPlayerLeaderboardFunc func(ctx context.Context, playerID string) (interface{}, error)
-	GetLeaderboardFunc       func(ctx context.Context, leaderboardID string) (interface{}, error)
-	SubmitScoreFunc          func(ctx context.Context, playerID string, score int) error
-	GetPlayerFunc            func(ctx context.Context, playerID string) (*model.Player, error)
-	UpdatePlayerFunc         func(ctx context.Context, playerID string, level int, countryCode string) error
+	service.ServiceInterface
+	CreatePlayerFunc          func(ctx context.Context, playerID string, profile service.PlayerProfile) (*model.Player, error)
+	JoinFunc                  func(ctx context.Context, playerID string) (string, error)
+	LeaveFunc                 func(ctx context.Context, playerID string) error
+	GetPlayerLeaderboardFunc  func(ctx context.Context, playerID string) (interface{}, error)
+	GetLeaderboardFunc        func(ctx context.Context, leaderboardID string) (interface{}, error)
+	SubmitScoreFunc           func(ctx context.Context, playerID string, score int) error
+	GetPlayerFunc             func(ctx context.Context, playerID string) (*model.Player, error)
+	UpdatePlayerFunc          func(ctx context.Context, playerID string, profile service.PlayerProfile) (*model.Player, error)
+	SubscribeLeaderboardFunc  func(ctx context.Context, leaderboardID string, lastEventID uint64) (*service.Subscription, error)
+	SubscribePlayerFunc       func(ctx context.Context, playerID string) (*service.Subscription, error)
+	GetConfigFunc             func(ctx context.Context) (service.Config, error)
+	UpdateConfigFunc          func(ctx context.Context, update service.ConfigUpdate) (service.Config, error)
+	ListAuditEntriesFunc      func(ctx context.Context, filter model.AuditFilter) ([]model.AuditEntry, error)
+	ListCompetitionsFunc      func(ctx context.Context, filter model.CompetitionFilter) ([]model.Competition, error)
+	CancelCompetitionFunc     func(ctx context.Context, competitionID, reason string) (*model.Competition, error)
+	SetCompetitionEndsAtFunc  func(ctx context.Context, competitionID string, endsAt time.Time, reason string) (*model.Competition, error)
+	ExcludePlayerFunc         func(ctx context.Context, competitionID, playerID string, status model.PlayerStatus, reason string) error
+	AdjustScoreFunc           func(ctx context.Context, competitionID, playerID string, adjustment service.ScoreAdjustment) (*service.ScoreChange, error)
+	ScheduleCompetitionFunc   func(ctx context.Context, schedule service.CompetitionSchedule) (*model.Competition, error)
+	RegisterFunc              func(ctx context.Context, competitionID, playerID string) error
+	UnregisterFunc            func(ctx context.Context, competitionID, playerID string) error
+	CreateTemplateFunc        func(ctx context.Context, def service.TemplateDefinition) (*model.CompetitionTemplate, error)
+	PreviewTemplateFunc       func(ctx context.Context, templateID string, count int) ([]service.Occurrence, error)
+	GetLeagueHistoryFunc      func(ctx context.Context, playerID string) ([]model.TierMovement, error)
+	CreateTournamentFunc      func(ctx context.Context, def service.TournamentDefinition) (*model.Tournament, error)
+	GetTournamentFunc         func(ctx context.Context, tournamentID string) (*service.Bracket, error)
+	CreatePartyFunc           func(ctx context.Context, playerID string) (*model.Party, error)
+	GetPartyFunc              func(ctx context.Context, partyID string) (*model.Party, error)
+	InviteToPartyFunc         func(ctx context.Context, partyID, playerID, inviteeID string) error
+	AcceptPartyInviteFunc     func(ctx context.Context, partyID, playerID string) (*model.Party, error)
+	LeavePartyFunc            func(ctx context.Context, partyID, playerID string) error
+	QueuePartyFunc            func(ctx context.Context, partyID, playerID string) error
+	ListFriendsFunc           func(ctx context.Context, playerID string) ([]string, error)
+	AddFriendFunc             func(ctx context.Context, playerID, friendID string) error
+	ImportFriendsFunc         func(ctx context.Context, playerID string, friendIDs []string) (*service.FriendImport, error)
+	RemoveFriendFunc          func(ctx context.Context, playerID, friendID string) error
+	GetFriendsLeaderboardFunc func(ctx context.Context, leaderboardID, playerID string) (*service.FriendsLeaderboard, error)
+	GetFriendsStatsFunc       func(ctx context.Context, playerID string) (*service.FriendsStats, error)
+	ListRegionsFunc           func(ctx context.Context) ([]model.Region, error)
+	PutRegionFunc             func(ctx context.Context, regionCode string, def service.RegionDefinition) (*model.Region, error)
+	DeleteRegionFunc          func(ctx context.Context, regionCode, reason string) error
+	GetGeoLeaderboardFunc     func(ctx context.Context, leaderboardID string, filter model.GeoFilter) (*service.GeoLeaderboard, error)
+	GetTopPlayersFunc         func(ctx context.Context, filter model.GeoFilter, limit int) (*service.TopPlayers, error)
+	GetPlayerHistoryFunc      func(ctx context.Context, playerID string, filter model.PlayerHistoryFilter) (*service.PlayerHistory, error)
+	DeletePlayerFunc          func(ctx context.Context, playerID string) error
+	ExportPlayerDataFunc      func(ctx context.Context, playerID string) (*service.PlayerExport, error)
+	GetModerationFunc         func(ctx context.Context, playerID string) (*model.Moderation, error)
+	ApplySanctionFunc         func(ctx context.Context, playerID string, sanction model.Sanction, until time.Time, reason string) (*model.Moderation, error)
+	LiftSanctionFunc          func(ctx context.Context, playerID string, sanction model.Sanction, reason string) (*model.Moderation, error)
 }
 
-func (m *mockService) CreatePlayer(ctx context.Context, playerID string, level int, countryCode string) error {
-	return m.CreatePlayerFunc(ctx, playerID, level, countryCode)
+func (m *mockService) GetConfig(ctx context.Context) (service.Config, error) {
+	return m.GetConfigFunc(ctx)
+}
+func (m *mockService) UpdateConfig(ctx context.Context, update service.ConfigUpdate) (service.Config, error) {
+	return m.UpdateConfigFunc(ctx, update)
+}
+func (m *mockService) ListAuditEntries(ctx context.Context, filter model.AuditFilter) ([]model.AuditEntry, error) {
+	return m.ListAuditEntriesFunc(ctx, filter)
+}
+func (m *mockService) ListCompetitions(ctx context.Context, filter model.CompetitionFilter) ([]model.Competition, error) {
+	return m.ListCompetitionsFunc(ctx, filter)
+}
+func (m *mockService) CancelCompetition(ctx context.Context, competitionID, reason string) (*model.Competition, error) {
+	return m.CancelCompetitionFunc(ctx, competitionID, reason)
+}
+func (m *mockService) SetCompetitionEndsAt(ctx context.Context, competitionID string, endsAt time.Time, reason string) (*model.Competition, error) {
+	return m.SetCompetitionEndsAtFunc(ctx, competitionID, endsAt, reason)
+}
+func (m *mockService) ExcludePlayer(ctx context.Context, competitionID, playerID string, status model.PlayerStatus, reason string) error {
+	return m.ExcludePlayerFunc(ctx, competitionID, playerID, status, reason)
+}
+func (m *mockService) AdjustScore(ctx context.Context, competitionID, playerID string, adjustment service.ScoreAdjustment) (*service.ScoreChange, error) {
+	return m.AdjustScoreFunc(ctx, competitionID, playerID, adjustment)
+}
+func (m *mockService) ScheduleCompetition(ctx context.Context, schedule service.CompetitionSchedule) (*model.Competition, error) {
+	return m.ScheduleCompetitionFunc(ctx, schedule)
+}
+func (m *mockService) RegisterForCompetition(ctx context.Context, competitionID, playerID string) error {
+	return m.RegisterFunc(ctx, competitionID, playerID)
+}
+func (m *mockService) UnregisterFromCompetition(ctx context.Context, competitionID, playerID string) error {
+	return m.UnregisterFunc(ctx, competitionID, playerID)
+}
+func (m *mockService) CreateTemplate(ctx context.Context, def service.TemplateDefinition) (*model.CompetitionTemplate, error) {
+	return m.CreateTemplateFunc(ctx, def)
+}
+func (m *mockService) PreviewTemplate(ctx context.Context, templateID string, count int) ([]service.Occurrence, error) {
+	return m.PreviewTemplateFunc(ctx, templateID, count)
+}
+func (m *mockService) GetLeagueHistory(ctx context.Context, playerID string) ([]model.TierMovement, error) {
+	return m.GetLeagueHistoryFunc(ctx, playerID)
+}
+func (m *mockService) CreateTournament(ctx context.Context, def service.TournamentDefinition) (*model.Tournament, error) {
+	return m.CreateTournamentFunc(ctx, def)
+}
+func (m *mockService) GetTournament(ctx context.Context, tournamentID string) (*service.Bracket, error) {
+	return m.GetTournamentFunc(ctx, tournamentID)
+}
+func (m *mockService) CreateParty(ctx context.Context, playerID string) (*model.Party, error) {
+	return m.CreatePartyFunc(ctx, playerID)
+}
+func (m *mockService) GetParty(ctx context.Context, partyID string) (*model.Party, error) {
+	return m.GetPartyFunc(ctx, partyID)
+}
+func (m *mockService) InviteToParty(ctx context.Context, partyID, playerID, inviteeID string) error {
+	return m.InviteToPartyFunc(ctx, partyID, playerID, inviteeID)
+}
+func (m *mockService) AcceptPartyInvite(ctx context.Context, partyID, playerID string) (*model.Party, error) {
+	return m.AcceptPartyInviteFunc(ctx, partyID, playerID)
+}
+func (m *mockService) LeaveParty(ctx context.Context, partyID, playerID string) error {
+	return m.LeavePartyFunc(ctx, partyID, playerID)
+}
+func (m *mockService) QueueParty(ctx context.Context, partyID, playerID string) error {
+	return m.QueuePartyFunc(ctx, partyID, playerID)
+}
+func (m *mockService) ListFriends(ctx context.Context, playerID string) ([]string, error) {
+	return m.ListFriendsFunc(ctx, playerID)
+}
+func (m *mockService) AddFriend(ctx context.Context, playerID, friendID string) error {
+	return m.AddFriendFunc(ctx, playerID, friendID)
+}
+func (m *mockService) ImportFriends(ctx context.Context, playerID string, friendIDs []string) (*service.FriendImport, error) {
+	return m.ImportFriendsFunc(ctx, playerID, friendIDs)
+}
+func (m *mockService) RemoveFriend(ctx context.Context, playerID, friendID string) error {
+	return m.RemoveFriendFunc(ctx, playerID, friendID)
+}
+func (m *mockService) GetFriendsLeaderboard(ctx context.Context, leaderboardID, playerID string) (*service.FriendsLeaderboard, error) {
+	return m.GetFriendsLeaderboardFunc(ctx, leaderboardID, playerID)
+}
+func (m *mockService) GetFriendsStats(ctx context.Context, playerID string) (*service.FriendsStats, error) {
+	return m.GetFriendsStatsFunc(ctx, playerID)
+}
+func (m *mockService) ListRegions(ctx context.Context) ([]model.Region, error) {
+	return m.ListRegionsFunc(ctx)
+}
+func (m *mockService) PutRegion(ctx context.Context, regionCode string, def service.RegionDefinition) (*model.Region, error) {
+	return m.PutRegionFunc(ctx, regionCode, def)
+}
+func (m *mockService) DeleteRegion(ctx context.Context, regionCode, reason string) error {
+	return m.DeleteRegionFunc(ctx, regionCode, reason)
+}
+func (m *mockService) GetGeoLeaderboard(ctx context.Context, leaderboardID string, filter model.GeoFilter) (*service.GeoLeaderboard, error) {
+	return m.GetGeoLeaderboardFunc(ctx, leaderboardID, filter)
+}
+func (m *mockService) GetTopPlayers(ctx context.Context, filter model.GeoFilter, limit int) (*service.TopPlayers, error) {
+	return m.GetTopPlayersFunc(ctx, filter, limit)
+}
+
+func (m *mockService) GetPlayerHistory(ctx context.Context, playerID string, filter model.PlayerHistoryFilter) (*service.PlayerHistory, error) {
+	return m.GetPlayerHistoryFunc(ctx, playerID, filter)
+}
+
+func (m *mockService) DeletePlayer(ctx context.Context, playerID string) error {
+	return m.DeletePlayerFunc(ctx, playerID)
+}
+
+func (m *mockService) ExportPlayerData(ctx context.Context, playerID string) (*service.PlayerExport, error) {
+	return m.ExportPlayerDataFunc(ctx, playerID)
+}
+func (m *mockService) GetModeration(ctx context.Context, playerID string) (*model.Moderation, error) {
+	return m.GetModerationFunc(ctx, playerID)
+}
+func (m *mockService) ApplySanction(ctx context.Context, playerID string, sanction model.Sanction, until time.Time, reason string) (*model.Moderation, error) {
+	return m.ApplySanctionFunc(ctx, playerID, sanction, until, reason)
+}
+func (m *mockService) LiftSanction(ctx context.Context, playerID string, sanction model.Sanction, reason string) (*model.Moderation, error) {
+	return m.LiftSanctionFunc(ctx, playerID, sanction, reason)
+}
+
+func (m *mockService) CreatePlayer(ctx context.Context, playerID string, profile service.PlayerProfile) (*model.Player, error) {
+	return m.CreatePlayerFunc(ctx, playerID, profile)
 }
 func (m *mockService) Join(ctx context.Context, playerID string) (string, error) {
 	return m.JoinFunc(ctx, playerID)
 }
+func (m *mockService) Leave(ctx context.Context, playerID string) error {
+	if m.LeaveFunc != nil {
+		return m.LeaveFunc(ctx, playerID)
+	}
+	return nil
+}
 func (m *mockService) GetPlayerLeaderboard(ctx context.Context, playerID string) (interface{}, error) {
 	if m.GetPlayerLeaderboardFunc != nil {
 		return m.GetPlayerLeaderboardFunc(ctx, playerID)
@@ -54,20 +227,32 @@ func (m *mockService) GetPlayer(ctx context.Context, playerID string) (*model.Pl
 	}
 	return nil, nil
 }
-func (m *mockService) UpdatePlayer(ctx context.Context, playerID string, level int, countryCode string) error {
+func (m *mockService) UpdatePlayer(ctx context.Context, playerID string, profile service.PlayerProfile) (*model.Player, error) {
 	if m.UpdatePlayerFunc != nil {
-		return m.UpdatePlayerFunc(ctx, playerID, level, countryCode)
+		return m.UpdatePlayerFunc(ctx, playerID, profile)
 	}
-	return nil
+	return &model.Player{PlayerID: playerID, Version: 2}, nil
+}
+func (m *mockService) SubscribeLeaderboard(ctx context.Context, leaderboardID string, lastEventID uint64) (*service.Subscription, error) {
+	if m.SubscribeLeaderboardFunc != nil {
+		return m.SubscribeLeaderboardFunc(ctx, leaderboardID, lastEventID)
+	}
+	return nil, errors.New("leaderboard not found")
+}
+func (m *mockService) SubscribePlayer(ctx context.Context, playerID string) (*service.Subscription, error) {
+	if m.SubscribePlayerFunc != nil {
+		return m.SubscribePlayerFunc(ctx, playerID)
+	}
+	return nil, errors.New("player not found")
 }
 
 func TestCreatePlayerHandler_Success(t *testing.T) {
 	svc := &mockService{
-		CreatePlayerFunc: func(ctx context.Context, playerID string, level int, countryCode string) error {
-			if playerID != "p1" || level != 2 || countryCode != "US" {
-				t.Errorf("unexpected args: %s, %d, %s", playerID, level, countryCode)
+		CreatePlayerFunc: func(ctx context.Context, playerID string, profile service.PlayerProfile) (*model.Player, error) {
+			if playerID != "p1" || *profile.Level != 2 || *profile.CountryCode != "US" {
+				t.Errorf("unexpected args: %s, %+v", playerID, profile)
 			}
-			return nil
+			return &model.Player{PlayerID: playerID, Version: 1}, nil
 		},
 	}
 	h := NewHandler(svc)
@@ -89,8 +274,8 @@ func TestCreatePlayerHandler_Success(t *testing.T) {
 
 func TestCreatePlayerHandler_Error(t *testing.T) {
 	svc := &mockService{
-		CreatePlayerFunc: func(ctx context.Context, playerID string, level int, countryCode string) error {
-			return errors.New("fail")
+		CreatePlayerFunc: func(ctx context.Context, playerID string, profile service.PlayerProfile) (*model.Player, error) {
+			return nil, errors.New("fail")
 		},
 	}
 	h := NewHandler(svc)
@@ -331,11 +516,11 @@ func TestGetPlayerHandler_NotFound(t *testing.T) {
 
 func TestUpdatePlayerHandler_Success(t *testing.T) {
 	svc := &mockService{
-		UpdatePlayerFunc: func(ctx context.Context, playerID string, level int, countryCode string) error {
-			if playerID != "p1" || level != 2 || countryCode != "GB" {
-				t.Errorf("unexpected update: %s, %d, %s", playerID, level, countryCode)
+		UpdatePlayerFunc: func(ctx context.Context, playerID string, profile service.PlayerProfile) (*model.Player, error) {
+			if playerID != "p1" || *profile.Level != 2 || *profile.CountryCode != "GB" {
+				t.Errorf("unexpected update: %s, %+v", playerID, profile)
 			}
-			return nil
+			return &model.Player{PlayerID: playerID, Version: 2}, nil
 		},
 	}
 	h := NewHandler(svc)
@@ -364,8 +549,8 @@ func TestUpdatePlayerHandler_BadRequest(t *testing.T) {
 
 func TestUpdatePlayerHandler_Error(t *testing.T) {
 	svc := &mockService{
-		UpdatePlayerFunc: func(ctx context.Context, playerID string, level int, countryCode string) error {
-			return errors.New("fail")
+		UpdatePlayerFunc: func(ctx context.Context, playerID string, profile service.PlayerProfile) (*model.Player, error) {
+			return nil, errors.New("fail")
 		},
 	}
 	h := NewHandler(svc)
@@ -414,6 +599,23 @@ func TestJoinHandler_AlreadyInWaitingQueue(t *testing.T) {
 	}
 }
 
+func TestJoinHandler_InParty(t *testing.T) {
+	svc := &mockService{
+		JoinFunc: func(ctx context.Context, playerID string) (string, error) {
+			return "", errors.New("player in a party")
+		},
+	}
+	h := NewHandler(svc)
+	req := httptest.NewRequest("POST", "/leaderboard/join?player_id=p7", nil)
+	rec := httptest.NewRecorder()
+
+	h.JoinHandler(rec, req)
+	resp := rec.Result()
+	if resp.StatusCode != http.StatusConflict {
+		t.Errorf("expected 409, got %d", resp.StatusCode)
+	}
+}
+
 func TestJoinHandler_InternalError(t *testing.T) {
 	svc := &mockService{
 		JoinFunc: func(ctx context.Context, playerID string) (string, error) {
@@ -443,6 +645,22 @@ func TestCreatePlayerHandler_BadRequest(t *testing.T) {
 	}
 }
 
+func TestCreatePlayerHandler_InvalidCountry(t *testing.T) {
+	svc := &mockService{
+		CreatePlayerFunc: func(ctx context.Context, playerID string, profile service.PlayerProfile) (*model.Player, error) {
+			return nil, fmt.Errorf("%w: country_code %q is not an ISO 3166-1 alpha-2 code", service.ErrInvalidArgument, *profile.CountryCode)
+		},
+	}
+	h := NewHandler(svc)
+	req := httptest.NewRequest("POST", "/player", bytes.NewReader([]byte(`{"player_id": "p1", "country_code": "XX"}`)))
+	rec := httptest.NewRecorder()
+
+	h.CreatePlayerHandler(rec, req)
+	if rec.Code != http.StatusBadRequest || !bytes.Contains(rec.Body.Bytes(), []byte("ISO 3166-1")) {
+		t.Errorf("expected 400 for an invalid country, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
 func TestScoreHandler_PlayerNotInActiveCompetition(t *testing.T) {
 	svc := &mockService{
 		SubmitScoreFunc: func(ctx context.Context, playerID string, score int) error {
@@ -481,8 +699,8 @@ func TestScoreHandler_InternalError(t *testing.T) {
 
 func TestUpdatePlayerHandler_InternalError(t *testing.T) {
 	svc := &mockService{
-		UpdatePlayerFunc: func(ctx context.Context, playerID string, level int, countryCode string) error {
-			return errors.New("db error")
+		UpdatePlayerFunc: func(ctx context.Context, playerID string, profile service.PlayerProfile) (*model.Player, error) {
+			return nil, errors.New("db error")
 		},
 	}
 	h := NewHandler(svc)
@@ -534,3 +752,422 @@ func TestLeaderboardHandler_InternalError(t *testing.T) {
 		t.Errorf("expected 404, got %d", resp.StatusCode)
 	}
 }
+
+func TestLeaveHandler_Success(t *testing.T) {
+	svc := &mockService{
+		LeaveFunc: func(ctx context.Context, playerID string) error {
+			if playerID != "p1" {
+				t.Errorf("unexpected playerID: %s", playerID)
+			}
+			return nil
+		},
+	}
+	h := NewHandler(svc)
+	req := httptest.NewRequest("POST", "/leaderboard/leave?player_id=p1", nil)
+	rec := httptest.NewRecorder()
+
+	h.LeaveHandler(rec, req)
+	resp := rec.Result()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected 200, got %d", resp.StatusCode)
+	}
+}
+
+func TestLeaveHandler_NotInQueue(t *testing.T) {
+	svc := &mockService{
+		LeaveFunc: func(ctx context.Context, playerID string) error {
+			return errors.New("player not in waiting queue")
+		},
+	}
+	h := NewHandler(svc)
+	req := httptest.NewRequest("POST", "/leaderboard/leave?player_id=p1", nil)
+	rec := httptest.NewRecorder()
+
+	h.LeaveHandler(rec, req)
+	resp := rec.Result()
+	if resp.StatusCode != http.StatusConflict {
+		t.Errorf("expected 409, got %d", resp.StatusCode)
+	}
+}
+
+func TestRegisterHandler(t *testing.T) {
+	var gotCompetition, gotPlayer string
+	svc := &mockService{
+		RegisterFunc: func(ctx context.Context, competitionID, playerID string) error {
+			gotCompetition, gotPlayer = competitionID, playerID
+			switch playerID {
+			case "full":
+				return errors.New("competition is full")
+			case "ghost":
+				return errors.New("player not found")
+			case "novice":
+				return errors.New("player not eligible")
+			case "cheat":
+				return errors.New("player excluded")
+			}
+			return nil
+		},
+	}
+	router := NewRouter(NewHandler(svc))
+	for playerID, status := range map[string]int{
+		"p1":     http.StatusOK,
+		"full":   http.StatusConflict,
+		"ghost":  http.StatusNotFound,
+		"novice": http.StatusForbidden,
+		"cheat":  http.StatusForbidden,
+	} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest("POST", "/v1/leaderboard/c1/join?player_id="+playerID, nil))
+		if rec.Code != status {
+			t.Errorf("%s: expected %d, got %d", playerID, status, rec.Code)
+		}
+		if gotCompetition != "c1" || gotPlayer != playerID {
+			t.Errorf("unexpected registration %s/%s", gotCompetition, gotPlayer)
+		}
+	}
+}
+
+func TestUnregisterHandler_NotRegistered(t *testing.T) {
+	svc := &mockService{
+		UnregisterFunc: func(ctx context.Context, competitionID, playerID string) error {
+			return errors.New("player not registered")
+		},
+	}
+	rec := httptest.NewRecorder()
+	NewRouter(NewHandler(svc)).ServeHTTP(rec, httptest.NewRequest("POST", "/v1/leaderboard/c1/leave?player_id=p1", nil))
+	if rec.Code != http.StatusConflict {
+		t.Errorf("expected 409, got %d", rec.Code)
+	}
+}
+
+func TestLeagueHistoryHandler(t *testing.T) {
+	svc := &mockService{
+		GetLeagueHistoryFunc: func(ctx context.Context, playerID string) ([]model.TierMovement, error) {
+			if playerID != "p1" {
+				return nil, errors.New("player not found")
+			}
+			return []model.TierMovement{{PlayerID: playerID, FromTier: model.TierBronze, ToTier: model.TierSilver, Rank: 1}}, nil
+		},
+	}
+	h := NewHandler(svc)
+	for playerID, want := range map[string]int{"p1": http.StatusOK, "ghost": http.StatusNotFound} {
+		req := httptest.NewRequest("GET", "/v1/player/"+playerID+"/league-history", nil)
+		req = mux.SetURLVars(req, map[string]string{"player_id": playerID})
+		rec := httptest.NewRecorder()
+		h.LeagueHistoryHandler(rec, req)
+		if rec.Code != want {
+			t.Errorf("%s: expected %d, got %d", playerID, want, rec.Code)
+		}
+	}
+}
+
+func TestFriendHandlers(t *testing.T) {
+	var imported []string
+	svc := &mockService{
+		ImportFriendsFunc: func(ctx context.Context, playerID string, friendIDs []string) (*service.FriendImport, error) {
+			imported = friendIDs
+			return &service.FriendImport{Added: friendIDs[:1], Ignored: friendIDs[1:]}, nil
+		},
+		AddFriendFunc: func(ctx context.Context, playerID, friendID string) error {
+			if friendID == playerID {
+				return fmt.Errorf("%w: a player cannot befriend themselves", service.ErrInvalidArgument)
+			}
+			return nil
+		},
+		RemoveFriendFunc: func(ctx context.Context, playerID, friendID string) error {
+			return fmt.Errorf("%w: friend not found", service.ErrNotFound)
+		},
+		GetFriendsLeaderboardFunc: func(ctx context.Context, leaderboardID, playerID string) (*service.FriendsLeaderboard, error) {
+			return &service.FriendsLeaderboard{LeaderboardID: leaderboardID, PlayerID: playerID,
+				Leaderboard: []service.FriendRank{{Rank: 1, PlayerID: playerID, Score: 10}}}, nil
+		},
+		GetFriendsStatsFunc: func(ctx context.Context, playerID string) (*service.FriendsStats, error) {
+			return &service.FriendsStats{PlayerID: playerID, Leaderboard: []service.StatsRank{
+				{Rank: 1, PlayerStats: model.PlayerStats{PlayerID: playerID, Competitions: 2, Wins: 1, TotalScore: 30, BestScore: 20}},
+			}}, nil
+		},
+	}
+	router := NewRouter(NewHandler(svc))
+	importRequest := func(body string) *http.Request {
+		req := httptest.NewRequest("POST", "/v1/player/p1/friends", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		return req
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, importRequest(`{"friend_ids": ["p2", "ghost"]}`))
+	var result service.FriendImport
+	json.NewDecoder(rec.Body).Decode(&result)
+	if rec.Code != http.StatusOK || len(imported) != 2 || len(result.Added) != 1 || result.Ignored[0] != "ghost" {
+		t.Errorf("unexpected import: %d %+v", rec.Code, result)
+	}
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, importRequest(`{"friend_ids": []}`))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an empty import, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("PUT", "/v1/player/p1/friends/p1", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for befriending oneself, got %d", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("DELETE", "/v1/player/p1/friends/p2", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for a player not followed, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/v1/leaderboard/c1/friends?player_id=p1", nil))
+	var board service.FriendsLeaderboard
+	json.NewDecoder(rec.Body).Decode(&board)
+	if rec.Code != http.StatusOK || board.LeaderboardID != "c1" || len(board.Leaderboard) != 1 {
+		t.Errorf("unexpected friends leaderboard: %d %+v", rec.Code, board)
+	}
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/v1/player/p1/friends/leaderboard", nil))
+	if rec.Code != http.StatusOK || !bytes.Contains(rec.Body.Bytes(), []byte(`{"rank":1,"player_id":"p1","competitions":2,"wins":1,"total_score":30,"best_score":20}`)) {
+		t.Errorf("unexpected friends stats: %d %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestGeoHandlers(t *testing.T) {
+	var filters []model.GeoFilter
+	var limit int
+	svc := &mockService{
+		GetGeoLeaderboardFunc: func(ctx context.Context, leaderboardID string, filter model.GeoFilter) (*service.GeoLeaderboard, error) {
+			filters = append(filters, filter)
+			if filter.CountryCode != "" && filter.RegionCode != "" {
+				return nil, fmt.Errorf("%w: filter by country_code or region, not both", service.ErrInvalidArgument)
+			}
+			return &service.GeoLeaderboard{LeaderboardID: leaderboardID, RegionCode: filter.RegionCode,
+				Leaderboard: []service.GeoRank{{Rank: 1, PlayerID: "p1", CountryCode: "DE", Score: 10}}}, nil
+		},
+		GetTopPlayersFunc: func(ctx context.Context, filter model.GeoFilter, n int) (*service.TopPlayers, error) {
+			filters = append(filters, filter)
+			limit = n
+			return &service.TopPlayers{CountryCode: filter.CountryCode, Leaderboard: []service.StatsRank{}}, nil
+		},
+		ListRegionsFunc: func(ctx context.Context) ([]model.Region, error) {
+			return []model.Region{{RegionCode: "NA", Name: "North America", Countries: []string{"CA", "MX", "US"}}}, nil
+		},
+	}
+	router := NewRouter(NewHandler(svc))
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/v1/leaderboard/c1?region=EU", nil))
+	if rec.Code != http.StatusOK || !bytes.Contains(rec.Body.Bytes(), []byte(`"region_code":"EU"`)) {
+		t.Errorf("unexpected regional leaderboard: %d %s", rec.Code, rec.Body.String())
+	}
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/v1/leaderboard/c1?region=EU&country_code=de", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for both filters, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/v1/countries/de/top-players?limit=10", nil))
+	if rec.Code != http.StatusOK || limit != 10 || filters[len(filters)-1].CountryCode != "de" {
+		t.Errorf("unexpected country top players: %d %v %d", rec.Code, filters, limit)
+	}
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/v1/top-players", nil))
+	if rec.Code != http.StatusOK || limit != service.DefaultTopPlayers || filters[len(filters)-1] != (model.GeoFilter{}) {
+		t.Errorf("unexpected global top players: %d %v %d", rec.Code, filters, limit)
+	}
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/v1/top-players?limit=5000", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for a limit over the maximum, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/v1/regions", nil))
+	if rec.Code != http.StatusOK || !bytes.Contains(rec.Body.Bytes(), []byte(`"countries":["CA","MX","US"]`)) {
+		t.Errorf("unexpected regions: %d %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestPlayerHistoryHandler(t *testing.T) {
+	var got model.PlayerHistoryFilter
+	svc := &mockService{
+		GetPlayerHistoryFunc: func(ctx context.Context, playerID string, filter model.PlayerHistoryFilter) (*service.PlayerHistory, error) {
+			if playerID != "p1" {
+				return nil, fmt.Errorf("%w: player not found", service.ErrNotFound)
+			}
+			got = filter
+			return &service.PlayerHistory{PlayerID: playerID, Total: 1, Limit: filter.Limit, Offset: filter.Offset,
+				Competitions: []model.CompetitionResult{{Status: model.CompetitionCompleted, Score: 40, Rank: 2, Participants: 5}},
+				Summary:      model.PlayerHistorySummary{Competitions: 1, AverageRank: 2}}, nil
+		},
+	}
+	router := NewRouter(NewHandler(svc))
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/v1/player/p1/competitions?status=COMPLETED&from=2030-01-01T00:00:00Z&limit=10&offset=20", nil))
+	if rec.Code != http.StatusOK || !bytes.Contains(rec.Body.Bytes(), []byte(`"rank":2,"participants":5`)) ||
+		!bytes.Contains(rec.Body.Bytes(), []byte(`"summary":{"competitions":1,"wins":0,"average_rank":2}`)) {
+		t.Errorf("unexpected history: %d %s", rec.Code, rec.Body.String())
+	}
+	if got.Status != model.CompetitionCompleted || got.From.Year() != 2030 || !got.To.IsZero() || got.Limit != 10 || got.Offset != 20 {
+		t.Errorf("unexpected filter: %+v", got)
+	}
+
+	for _, path := range []string{"/v1/player/p1/competitions?status=WAITING", "/v1/player/p1/competitions?limit=500", "/v1/player/p1/competitions?from=yesterday"} {
+		rec = httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", path, rec.Code)
+		}
+	}
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/v1/player/ghost/competitions", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown player, got %d", rec.Code)
+	}
+}
+
+func TestPlayerPrivacyHandlers(t *testing.T) {
+	var deleted string
+	notFound := fmt.Errorf("%w: player not found", service.ErrNotFound)
+	svc := &mockService{
+		DeletePlayerFunc: func(ctx context.Context, playerID string) error {
+			if playerID != "p1" {
+				return notFound
+			}
+			deleted = playerID
+			return nil
+		},
+		ExportPlayerDataFunc: func(ctx context.Context, playerID string) (*service.PlayerExport, error) {
+			if playerID != "p1" {
+				return nil, notFound
+			}
+			return &service.PlayerExport{Player: &model.Player{PlayerID: playerID}, Friends: []string{"p2"}}, nil
+		},
+	}
+	router := NewRouter(NewHandler(svc, WithAdminTokens(testAdminTokens)))
+	token := testAdminTokens["alice"]
+
+	for _, req := range []*http.Request{adminRequest("DELETE", "/v1/player/p1", "", ""), adminRequest("GET", "/v1/player/p1/export", "", "nope")} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != http.StatusUnauthorized || deleted != "" {
+			t.Errorf("%s %s: expected 401 without an admin token, got %d", req.Method, req.URL.Path, rec.Code)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, adminRequest("DELETE", "/v1/player/p1", "", token))
+	if rec.Code != http.StatusOK || deleted != "p1" {
+		t.Errorf("unexpected delete: %d %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, adminRequest("GET", "/v1/player/p1/export", "", token))
+	if rec.Code != http.StatusOK || !bytes.Contains(rec.Body.Bytes(), []byte(`"friends":["p2"]`)) {
+		t.Errorf("unexpected export: %d %s", rec.Code, rec.Body.String())
+	}
+	if got := rec.Header().Get("Content-Disposition"); got != `attachment; filename="player-data.json"` {
+		t.Errorf("expected an attachment, got %q", got)
+	}
+
+	for _, req := range []*http.Request{adminRequest("DELETE", "/v1/player/ghost", "", token), adminRequest("GET", "/v1/player/ghost/export", "", token)} {
+		rec = httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("%s %s: expected 404, got %d", req.Method, req.URL.Path, rec.Code)
+		}
+	}
+}
+
+func TestPlayerProfileHandlers(t *testing.T) {
+	var updated service.PlayerProfile
+	svc := &mockService{
+		CreatePlayerFunc: func(ctx context.Context, playerID string, profile service.PlayerProfile) (*model.Player, error) {
+			if *profile.DisplayName == "Taken" {
+				return nil, fmt.Errorf("%w: display_name already taken", service.ErrConflict)
+			}
+			return &model.Player{PlayerID: playerID, DisplayName: *profile.DisplayName, Metadata: profile.Metadata, Version: 1}, nil
+		},
+		GetPlayerFunc: func(ctx context.Context, playerID string) (*model.Player, error) {
+			return &model.Player{PlayerID: playerID, DisplayName: "Ada", Version: 3}, nil
+		},
+		UpdatePlayerFunc: func(ctx context.Context, playerID string, profile service.PlayerProfile) (*model.Player, error) {
+			updated = profile
+			if profile.Version != 0 && profile.Version != 3 {
+				return nil, fmt.Errorf("%w: player is at version 3", service.ErrPreconditionFailed)
+			}
+			return &model.Player{PlayerID: playerID, Version: 4}, nil
+		},
+	}
+	router := NewRouter(NewHandler(svc))
+	request := func(method, path, body string, header map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		if body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := request("POST", "/v1/player", `{"player_id": "p1", "display_name": "Ada", "metadata": {"clan": "x"}}`, nil)
+	if rec.Code != http.StatusCreated || rec.Header().Get("ETag") != `"1"` {
+		t.Errorf("unexpected create: %d %q %s", rec.Code, rec.Header().Get("ETag"), rec.Body.String())
+	}
+	if rec := request("POST", "/v1/player", `{"player_id": "p2", "display_name": "Taken"}`, nil); rec.Code != http.StatusConflict {
+		t.Errorf("expected 409 for a taken name, got %d", rec.Code)
+	}
+
+	rec = request("GET", "/v1/player/p1", "", nil)
+	if rec.Code != http.StatusOK || rec.Header().Get("ETag") != `"3"` || !bytes.Contains(rec.Body.Bytes(), []byte(`"DisplayName":"Ada"`)) {
+		t.Errorf("unexpected get: %d %q %s", rec.Code, rec.Header().Get("ETag"), rec.Body.String())
+	}
+
+	rec = request("PUT", "/v1/player/p1", `{"level": 2, "display_name": "Ada L", "metadata": null}`, map[string]string{"If-Match": `"3"`})
+	if rec.Code != http.StatusOK || rec.Header().Get("ETag") != `"4"` {
+		t.Errorf("unexpected update: %d %q %s", rec.Code, rec.Header().Get("ETag"), rec.Body.String())
+	}
+	// The country is left out, so it stays as it is.
+	if updated.Version != 3 || *updated.Level != 2 || updated.CountryCode != nil || *updated.DisplayName != "Ada L" ||
+		string(updated.Metadata) != "null" || updated.AvatarURL != nil {
+		t.Errorf("unexpected profile passed on: %+v", updated)
+	}
+	if rec := request("PUT", "/v1/player/p1", `{"level": 2}`, map[string]string{"If-Match": "*"}); rec.Code != http.StatusOK || updated.Version != 0 {
+		t.Errorf("expected an unconditional update, got %d with version %d", rec.Code, updated.Version)
+	}
+	for _, tag := range []string{`"2"`, `W/"3"`, "3"} {
+		if rec := request("PUT", "/v1/player/p1", `{"level": 2}`, map[string]string{"If-Match": tag}); rec.Code != http.StatusPreconditionFailed {
+			t.Errorf("If-Match %s: expected 412, got %d", tag, rec.Code)
+		}
+	}
+}
+
+func TestSanctionedPlayerHandlers(t *testing.T) {
+	svc := &mockService{
+		JoinFunc: func(ctx context.Context, playerID string) (string, error) {
+			return "", errors.New("player banned")
+		},
+		SubmitScoreFunc: func(ctx context.Context, playerID string, score int) error {
+			return errors.New("player suspended")
+		},
+	}
+	router := NewRouter(NewHandler(svc))
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("POST", "/v1/leaderboard/join?player_id=p1", nil))
+	if rec.Code != http.StatusForbidden || !bytes.Contains(rec.Body.Bytes(), []byte("player banned")) {
+		t.Errorf("unexpected join: %d %s", rec.Code, rec.Body.String())
+	}
+	req := httptest.NewRequest("POST", "/v1/leaderboard/score", bytes.NewBufferString(`{"player_id": "p1", "score": 10}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusForbidden || !bytes.Contains(rec.Body.Bytes(), []byte("player suspended")) {
+		t.Errorf("unexpected score: %d %s", rec.Code, rec.Body.String())
+	}
+}
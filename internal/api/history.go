@@ -0,0 +1,53 @@
+package api
+
+import (
+	"leaderboard-service/internal/model"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// PlayerHistoryHandler pages through the competitions a player took part
+// in, filtered by the status, from, to, limit and offset query parameters.
+func (h *Handler) PlayerHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter := model.PlayerHistoryFilter{Status: model.CompetitionStatus(q.Get("status"))}
+	for _, t := range []struct {
+		name string
+		dst  *time.Time
+	}{{"from", &filter.From}, {"to", &filter.To}} {
+		raw := q.Get(t.name)
+		if raw == "" {
+			continue
+		}
+		v, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, t.name+" must be an RFC 3339 timestamp")
+			return
+		}
+		*t.dst = v
+	}
+	for _, n := range []struct {
+		name string
+		dst  *int
+	}{{"limit", &filter.Limit}, {"offset", &filter.Offset}} {
+		raw := q.Get(n.name)
+		if raw == "" {
+			continue
+		}
+		v, err := strconv.Atoi(raw)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, n.name+" must be an integer")
+			return
+		}
+		*n.dst = v
+	}
+	history, err := h.service.GetPlayerHistory(r.Context(), mux.Vars(r)["player_id"], filter)
+	if err != nil {
+		writeAdminError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, history)
+}
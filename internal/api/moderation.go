@@ -0,0 +1,66 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"leaderboard-service/internal/model"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+func (h *Handler) GetModerationHandler(w http.ResponseWriter, r *http.Request) {
+	m, err := h.service.GetModeration(r.Context(), mux.Vars(r)["player_id"])
+	if err != nil {
+		writeAdminError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, m)
+}
+
+// ApplySanctionHandler returns the handler that puts sanction on a player.
+// A suspension needs an until time in the body.
+func (h *Handler) ApplySanctionHandler(sanction model.Sanction) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Until  *time.Time `json:"until"`
+			Reason string     `json:"reason"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+			writeError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+		var until time.Time
+		if sanction == model.SanctionSuspension {
+			if req.Until == nil {
+				writeError(w, http.StatusBadRequest, "until is required")
+				return
+			}
+			until = *req.Until
+		}
+		m, err := h.service.ApplySanction(r.Context(), mux.Vars(r)["player_id"], sanction, until, req.Reason)
+		if err != nil {
+			writeAdminError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, m)
+	}
+}
+
+// LiftSanctionHandler returns the handler that lifts sanction from a player.
+func (h *Handler) LiftSanctionHandler(sanction model.Sanction) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reason, ok := decodeReason(w, r)
+		if !ok {
+			return
+		}
+		m, err := h.service.LiftSanction(r.Context(), mux.Vars(r)["player_id"], sanction, reason)
+		if err != nil {
+			writeAdminError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, m)
+	}
+}
@@ -0,0 +1,69 @@
+package api
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+)
+
+//go:embed openapi.yaml
+var openAPISpec []byte
+
+// LoadOpenAPISpec parses and validates the embedded OpenAPI document.
+func LoadOpenAPISpec() (*openapi3.T, error) {
+	doc, err := openapi3.NewLoader().LoadFromData(openAPISpec)
+	if err != nil {
+		return nil, err
+	}
+	if err := doc.Validate(openapi3.NewLoader().Context); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+func (h *Handler) OpenAPIHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/yaml")
+	w.Write(openAPISpec)
+}
+
+// openAPIValidator rejects requests whose parameters or body do not match the
+// OpenAPI document with 400 Bad Request. Requests for routes that are not in
+// the document are passed through unchanged.
+func openAPIValidator(doc *openapi3.T) func(http.Handler) http.Handler {
+	router, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		panic(fmt.Sprintf("failed to build OpenAPI router: %v", err))
+	}
+	options := &openapi3filter.Options{AuthenticationFunc: openapi3filter.NoopAuthenticationFunc}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route, pathParams, err := router.FindRoute(r)
+			if err != nil {
+				if err != routers.ErrPathNotFound && err != routers.ErrMethodNotAllowed {
+					logger(r.Context()).Warn("OpenAPI route lookup failed", "method", r.Method, "path", r.URL.Path, "error", err)
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+			input := &openapi3filter.RequestValidationInput{
+				Request:    r,
+				PathParams: pathParams,
+				Route:      route,
+				Options:    options,
+			}
+			if err := openapi3filter.ValidateRequest(r.Context(), input); err != nil {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestOpenAPISpec_Valid(t *testing.T) {
+	if _, err := LoadOpenAPISpec(); err != nil {
+		t.Fatalf("OpenAPI spec is invalid: %v", err)
+	}
+}
+
+func TestOpenAPISpec_CoversEveryRoute(t *testing.T) {
+	doc, err := LoadOpenAPISpec()
+	if err != nil {
+		t.Fatalf("OpenAPI spec is invalid: %v", err)
+	}
+	router := NewRouter(NewHandler(&mockService{})).(*mux.Router)
+	count := 0
+	err = router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		tpl, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			// Prefix-only routes such as the version subrouter have no methods.
+			return nil
+		}
+		item := doc.Paths.Find(tpl)
+		for _, method := range methods {
+			count++
+			if item == nil || item.GetOperation(method) == nil {
+				t.Errorf("route %s %s is not documented in openapi.yaml", method, tpl)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("walking routes failed: %v", err)
+	}
+	if count == 0 {
+		t.Fatalf("no routes found")
+	}
+}
+
+func TestRouter_RoutesAreVersioned(t *testing.T) {
+	router := NewRouter(NewHandler(&mockService{}))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/hello", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected unversioned route to 404, got %d", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/v1/hello", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected 200, got %d", rec.Code)
+	}
+}
+
+func TestRouter_ValidatesRequestBody(t *testing.T) {
+	called := false
+	svc := &mockService{
+		SubmitScoreFunc: func(ctx context.Context, playerID string, score int) error {
+			called = true
+			return nil
+		},
+	}
+	router := NewRouter(NewHandler(svc))
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/v1/leaderboard/score", bytes.NewReader([]byte(`{"player_id":"p1"}`)))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for missing score, got %d", rec.Code)
+	}
+	if called {
+		t.Errorf("handler should not run for an invalid request")
+	}
+
+	rec = httptest.NewRecorder()
+	req = httptest.NewRequest("POST", "/v1/leaderboard/score", bytes.NewReader([]byte(`{"player_id":"p1","score":5}`)))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || !called {
+		t.Errorf("expected valid request to reach handler, got %d", rec.Code)
+	}
+}
+
+func TestRouter_ValidatesRequiredQuery(t *testing.T) {
+	router := NewRouter(NewHandler(&mockService{}))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("POST", "/v1/leaderboard/join", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for missing player_id, got %d", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), "player_id") {
+		t.Errorf("expected error to mention player_id, got %s", rec.Body.String())
+	}
+}
+
+func TestOpenAPIHandler(t *testing.T) {
+	router := NewRouter(NewHandler(&mockService{}))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/v1/openapi.yaml", nil))
+	if rec.Code != http.StatusOK || !strings.HasPrefix(rec.Body.String(), "openapi: 3") {
+		t.Errorf("expected OpenAPI document, got %d", rec.Code)
+	}
+}
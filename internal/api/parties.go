@@ -0,0 +1,63 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// CreatePartyHandler creates a party led by the player_id in the query.
+func (h *Handler) CreatePartyHandler(w http.ResponseWriter, r *http.Request) {
+	party, err := h.service.CreateParty(r.Context(), r.URL.Query().Get("player_id"))
+	if err != nil {
+		writeAdminError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, party)
+}
+
+func (h *Handler) GetPartyHandler(w http.ResponseWriter, r *http.Request) {
+	party, err := h.service.GetParty(r.Context(), mux.Vars(r)["party_id"])
+	if err != nil {
+		writeAdminError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, party)
+}
+
+func (h *Handler) InviteToPartyHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	err := h.service.InviteToParty(r.Context(), mux.Vars(r)["party_id"], query.Get("player_id"), query.Get("invitee_id"))
+	if err != nil {
+		writeAdminError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"message": "Player invited to party"})
+}
+
+func (h *Handler) AcceptPartyInviteHandler(w http.ResponseWriter, r *http.Request) {
+	party, err := h.service.AcceptPartyInvite(r.Context(), mux.Vars(r)["party_id"], r.URL.Query().Get("player_id"))
+	if err != nil {
+		writeAdminError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, party)
+}
+
+func (h *Handler) LeavePartyHandler(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.LeaveParty(r.Context(), mux.Vars(r)["party_id"], r.URL.Query().Get("player_id")); err != nil {
+		writeAdminError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"message": "Player left party"})
+}
+
+// QueuePartyHandler puts the whole party in the matchmaking queue; only its
+// leader may.
+func (h *Handler) QueuePartyHandler(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.QueueParty(r.Context(), mux.Vars(r)["party_id"], r.URL.Query().Get("player_id")); err != nil {
+		writeAdminError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, map[string]string{"message": "Party added to matchmaking queue"})
+}
@@ -0,0 +1,30 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// DeletePlayerHandler deletes a player, keeping their scores on leaderboards
+// under a pseudonym.
+func (h *Handler) DeletePlayerHandler(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeletePlayer(r.Context(), mux.Vars(r)["player_id"]); err != nil {
+		writeAdminError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"message": "Player deleted"})
+}
+
+// ExportPlayerHandler returns all the data held about a player as a JSON
+// attachment.
+func (h *Handler) ExportPlayerHandler(w http.ResponseWriter, r *http.Request) {
+	playerID := mux.Vars(r)["player_id"]
+	export, err := h.service.ExportPlayerData(r.Context(), playerID)
+	if err != nil {
+		writeAdminError(w, err)
+		return
+	}
+	w.Header().Set("Content-Disposition", `attachment; filename="player-data.json"`)
+	writeJSON(w, http.StatusOK, export)
+}
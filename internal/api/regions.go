@@ -0,0 +1,82 @@
+package api
+
+import (
+	"encoding/json"
+	"leaderboard-service/internal/model"
+	"leaderboard-service/internal/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+func (h *Handler) ListRegionsHandler(w http.ResponseWriter, r *http.Request) {
+	regions, err := h.service.ListRegions(r.Context())
+	if err != nil {
+		writeAdminError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, regions)
+}
+
+// PutRegionHandler creates a region or replaces its name and countries.
+func (h *Handler) PutRegionHandler(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Name      string   `json:"name"`
+		Countries []string `json:"countries"`
+		Reason    string   `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	def := service.RegionDefinition{Name: req.Name, Countries: req.Countries, Reason: req.Reason}
+	region, err := h.service.PutRegion(r.Context(), mux.Vars(r)["region_code"], def)
+	if err != nil {
+		writeAdminError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, region)
+}
+
+func (h *Handler) DeleteRegionHandler(w http.ResponseWriter, r *http.Request) {
+	reason, ok := decodeReason(w, r)
+	if !ok {
+		return
+	}
+	if err := h.service.DeleteRegion(r.Context(), mux.Vars(r)["region_code"], reason); err != nil {
+		writeAdminError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"message": "Region deleted"})
+}
+
+// TopPlayersHandler ranks players by their stats over all completed
+// competitions, optionally within a country_code or region from the query.
+func (h *Handler) TopPlayersHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	h.writeTopPlayers(w, r, model.GeoFilter{CountryCode: q.Get("country_code"), RegionCode: q.Get("region")})
+}
+
+// CountryTopPlayersHandler ranks the players of one country.
+func (h *Handler) CountryTopPlayersHandler(w http.ResponseWriter, r *http.Request) {
+	h.writeTopPlayers(w, r, model.GeoFilter{CountryCode: mux.Vars(r)["country_code"]})
+}
+
+func (h *Handler) writeTopPlayers(w http.ResponseWriter, r *http.Request, filter model.GeoFilter) {
+	limit := 0
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "limit must be an integer")
+			return
+		}
+		limit = n
+	}
+	top, err := h.service.GetTopPlayers(r.Context(), filter, limit)
+	if err != nil {
+		writeAdminError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, top)
+}
@@ -1,23 +1,104 @@
 package api
 
 import (
+	"fmt"
+	"leaderboard-service/internal/model"
 	"net/http"
 
 	"github.com/gorilla/mux"
 )
 
-func NewRouter(handler *Handler) http.Handler {
+// APIPrefix is the path prefix of the current API version.
+const APIPrefix = "/v1"
+
+// NewRouter builds the API router. Middleware in mw runs for every matched
+// route, before request validation.
+func NewRouter(handler *Handler, mw ...mux.MiddlewareFunc) http.Handler {
+	doc, err := LoadOpenAPISpec()
+	if err != nil {
+		panic(fmt.Sprintf("invalid OpenAPI spec: %v", err))
+	}
+
 	r := mux.NewRouter()
-	r.HandleFunc("/hello", handler.HelloHandler).Methods("GET")
-	r.HandleFunc("/leaderboard/join", handler.JoinHandler).Methods("POST")
-	r.HandleFunc("/leaderboard/player/{player_id}", handler.PlayerLeaderboardHandler).Methods("GET")
-	r.HandleFunc("/leaderboard/{leaderboardID}", handler.LeaderboardHandler).Methods("GET")
-	r.HandleFunc("/leaderboard/score", handler.ScoreHandler).Methods("POST")
+	r.Use(mw...)
+	v1 := r.PathPrefix(APIPrefix).Subrouter()
+	v1.Use(openAPIValidator(doc))
+
+	v1.HandleFunc("/hello", handler.HelloHandler).Methods("GET")
+	v1.HandleFunc("/openapi.yaml", handler.OpenAPIHandler).Methods("GET")
+	v1.HandleFunc("/leaderboard/join", handler.JoinHandler).Methods("POST")
+	v1.HandleFunc("/leaderboard/leave", handler.LeaveHandler).Methods("POST")
+	v1.HandleFunc("/leaderboard/player/{player_id}", handler.PlayerLeaderboardHandler).Methods("GET")
+	v1.HandleFunc("/leaderboard/{leaderboardID}", handler.LeaderboardHandler).Methods("GET")
+	v1.HandleFunc("/leaderboard/{leaderboardID}/stream", handler.LeaderboardStreamHandler).Methods("GET")
+	v1.HandleFunc("/leaderboard/{leaderboardID}/join", handler.RegisterHandler).Methods("POST")
+	v1.HandleFunc("/leaderboard/{leaderboardID}/leave", handler.UnregisterHandler).Methods("POST")
+	v1.HandleFunc("/leaderboard/{leaderboardID}/friends", handler.FriendsLeaderboardHandler).Methods("GET")
+	v1.HandleFunc("/leaderboard/score", handler.ScoreHandler).Methods("POST")
+	v1.HandleFunc("/ws", handler.WebSocketHandler).Methods("GET")
+	v1.HandleFunc("/tournaments/{tournament_id}", handler.GetTournamentHandler).Methods("GET")
 
 	// Player CRUD
-	r.HandleFunc("/player", handler.CreatePlayerHandler).Methods("POST")
-	r.HandleFunc("/player/{player_id}", handler.GetPlayerHandler).Methods("GET")
-	r.HandleFunc("/player/{player_id}", handler.UpdatePlayerHandler).Methods("PUT")
+	v1.HandleFunc("/player", handler.CreatePlayerHandler).Methods("POST")
+	v1.HandleFunc("/player/{player_id}", handler.GetPlayerHandler).Methods("GET")
+	v1.HandleFunc("/player/{player_id}", handler.UpdatePlayerHandler).Methods("PUT")
+	// Deleting a player and exporting their data are irreversible or expose
+	// personal data, so they take an admin token.
+	v1.Handle("/player/{player_id}", handler.requireAdmin(http.HandlerFunc(handler.DeletePlayerHandler))).Methods("DELETE")
+	v1.Handle("/player/{player_id}/export", handler.requireAdmin(http.HandlerFunc(handler.ExportPlayerHandler))).Methods("GET")
+	v1.HandleFunc("/player/{player_id}/league-history", handler.LeagueHistoryHandler).Methods("GET")
+	v1.HandleFunc("/player/{player_id}/competitions", handler.PlayerHistoryHandler).Methods("GET")
+
+	// Friends
+	v1.HandleFunc("/player/{player_id}/friends", handler.ListFriendsHandler).Methods("GET")
+	v1.HandleFunc("/player/{player_id}/friends", handler.ImportFriendsHandler).Methods("POST")
+	v1.HandleFunc("/player/{player_id}/friends/leaderboard", handler.FriendsStatsHandler).Methods("GET")
+	v1.HandleFunc("/player/{player_id}/friends/{friend_id}", handler.AddFriendHandler).Methods("PUT")
+	v1.HandleFunc("/player/{player_id}/friends/{friend_id}", handler.RemoveFriendHandler).Methods("DELETE")
+
+	// Parties
+	v1.HandleFunc("/party", handler.CreatePartyHandler).Methods("POST")
+	v1.HandleFunc("/party/{party_id}", handler.GetPartyHandler).Methods("GET")
+	v1.HandleFunc("/party/{party_id}/invite", handler.InviteToPartyHandler).Methods("POST")
+	v1.HandleFunc("/party/{party_id}/accept", handler.AcceptPartyInviteHandler).Methods("POST")
+	v1.HandleFunc("/party/{party_id}/leave", handler.LeavePartyHandler).Methods("POST")
+	v1.HandleFunc("/party/{party_id}/queue", handler.QueuePartyHandler).Methods("POST")
+
+	// Countries & regions
+	v1.HandleFunc("/regions", handler.ListRegionsHandler).Methods("GET")
+	v1.HandleFunc("/top-players", handler.TopPlayersHandler).Methods("GET")
+	v1.HandleFunc("/countries/{country_code}/top-players", handler.CountryTopPlayersHandler).Methods("GET")
+
+	// Admin
+	admin := v1.PathPrefix("/admin").Subrouter()
+	admin.Use(handler.requireAdmin)
+	admin.HandleFunc("/settings", handler.GetSettingsHandler).Methods("GET")
+	admin.HandleFunc("/settings", handler.UpdateSettingsHandler).Methods("PATCH")
+	admin.HandleFunc("/audit", handler.AuditLogHandler).Methods("GET")
+	admin.HandleFunc("/competitions", handler.ListCompetitionsHandler).Methods("GET")
+	admin.HandleFunc("/competitions", handler.ScheduleCompetitionHandler).Methods("POST")
+	admin.HandleFunc("/competitions/{competition_id}", handler.UpdateCompetitionHandler).Methods("PATCH")
+	admin.HandleFunc("/competitions/{competition_id}/cancel", handler.CancelCompetitionHandler).Methods("POST")
+	admin.HandleFunc("/competitions/{competition_id}/complete", handler.CompleteCompetitionHandler).Methods("POST")
+	admin.Handle("/competitions/{competition_id}/players/{player_id}/remove", handler.ExcludePlayerHandler(model.StatusRemoved)).Methods("POST")
+	admin.Handle("/competitions/{competition_id}/players/{player_id}/disqualify", handler.ExcludePlayerHandler(model.StatusDisqualified)).Methods("POST")
+	admin.HandleFunc("/competitions/{competition_id}/players/{player_id}/score", handler.AdjustScoreHandler).Methods("POST")
+	admin.HandleFunc("/templates", handler.ListTemplatesHandler).Methods("GET")
+	admin.HandleFunc("/templates", handler.CreateTemplateHandler).Methods("POST")
+	admin.HandleFunc("/templates/{template_id}", handler.GetTemplateHandler).Methods("GET")
+	admin.HandleFunc("/templates/{template_id}", handler.UpdateTemplateHandler).Methods("PUT")
+	admin.HandleFunc("/templates/{template_id}", handler.DeleteTemplateHandler).Methods("DELETE")
+	admin.HandleFunc("/templates/{template_id}/occurrences", handler.PreviewTemplateHandler).Methods("GET")
+	admin.HandleFunc("/tournaments", handler.CreateTournamentHandler).Methods("POST")
+	admin.HandleFunc("/regions/{region_code}", handler.PutRegionHandler).Methods("PUT")
+	admin.HandleFunc("/regions/{region_code}", handler.DeleteRegionHandler).Methods("DELETE")
+	admin.HandleFunc("/players/{player_id}/sanctions", handler.GetModerationHandler).Methods("GET")
+	admin.Handle("/players/{player_id}/ban", handler.ApplySanctionHandler(model.SanctionBan)).Methods("PUT")
+	admin.Handle("/players/{player_id}/ban", handler.LiftSanctionHandler(model.SanctionBan)).Methods("DELETE")
+	admin.Handle("/players/{player_id}/suspension", handler.ApplySanctionHandler(model.SanctionSuspension)).Methods("PUT")
+	admin.Handle("/players/{player_id}/suspension", handler.LiftSanctionHandler(model.SanctionSuspension)).Methods("DELETE")
+	admin.Handle("/players/{player_id}/shadow-ban", handler.ApplySanctionHandler(model.SanctionShadowBan)).Methods("PUT")
+	admin.Handle("/players/{player_id}/shadow-ban", handler.LiftSanctionHandler(model.SanctionShadowBan)).Methods("DELETE")
 
 	return r
 }
@@ -0,0 +1,112 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"leaderboard-service/internal/service"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// defaultHeartbeatInterval keeps idle streams alive through proxies that close
+// quiet connections.
+const defaultHeartbeatInterval = 15 * time.Second
+
+// LeaderboardStreamHandler streams live leaderboard updates for a competition
+// as Server-Sent Events. Clients reconnecting with Last-Event-ID resume from
+// the next retained event, otherwise they first receive a snapshot.
+func (h *Handler) LeaderboardStreamHandler(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "streaming unsupported"})
+		return
+	}
+	vars := mux.Vars(r)
+	leaderboardID := vars["leaderboardID"]
+	ctx := r.Context()
+	lastEventID := parseLastEventID(r)
+	logger(ctx).Debug("leaderboard stream requested", "competition_id", leaderboardID, "last_event_id", lastEventID)
+
+	sub, err := h.service.SubscribeLeaderboard(ctx, leaderboardID, lastEventID)
+	if err != nil {
+		if err.Error() == "leaderboard not found" {
+			w.WriteHeader(http.StatusNotFound)
+		} else {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+	defer sub.Close()
+
+	// Streams outlive the server's WriteTimeout; lift the deadline for this
+	// response. Writers that do not support deadlines are fine to ignore.
+	http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	if !sub.Resumed {
+		if snapshot, err := h.service.GetLeaderboard(ctx, leaderboardID); err == nil {
+			writeSSE(w, service.Event{Type: service.EventSnapshot, Data: snapshot})
+		}
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(h.heartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+			flusher.Flush()
+		case ev, ok := <-sub.C:
+			if !ok {
+				return
+			}
+			if err := writeSSE(w, ev); err != nil {
+				logger(ctx).Info("error writing stream event", "competition_id", leaderboardID, "error", err)
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// parseLastEventID reads the resume position from the Last-Event-ID header,
+// falling back to a last_event_id query parameter for clients that cannot set
+// headers.
+func parseLastEventID(r *http.Request) uint64 {
+	raw := r.Header.Get("Last-Event-ID")
+	if raw == "" {
+		raw = r.URL.Query().Get("last_event_id")
+	}
+	id, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return id
+}
+
+func writeSSE(w http.ResponseWriter, ev service.Event) error {
+	data, err := json.Marshal(ev.Data)
+	if err != nil {
+		return err
+	}
+	if ev.ID > 0 {
+		if _, err := fmt.Fprintf(w, "id: %d\n", ev.ID); err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", ev.Type, data)
+	return err
+}
@@ -0,0 +1,163 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"leaderboard-service/internal/service"
+
+	"github.com/gorilla/mux"
+)
+
+func TestLeaderboardStreamHandler_NotFound(t *testing.T) {
+	h := NewHandler(&mockService{})
+	req := httptest.NewRequest("GET", "/v1/leaderboard/lid/stream", nil)
+	rec := httptest.NewRecorder()
+	req = mux.SetURLVars(req, map[string]string{"leaderboardID": "lid"})
+	h.LeaderboardStreamHandler(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", rec.Code)
+	}
+}
+
+func TestLeaderboardStreamHandler_InternalError(t *testing.T) {
+	svc := &mockService{
+		SubscribeLeaderboardFunc: func(ctx context.Context, leaderboardID string, lastEventID uint64) (*service.Subscription, error) {
+			return nil, errors.New("db error")
+		},
+	}
+	h := NewHandler(svc)
+	req := httptest.NewRequest("GET", "/v1/leaderboard/lid/stream", nil)
+	rec := httptest.NewRecorder()
+	req = mux.SetURLVars(req, map[string]string{"leaderboardID": "lid"})
+	h.LeaderboardStreamHandler(rec, req)
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500, got %d", rec.Code)
+	}
+}
+
+func TestLeaderboardStreamHandler_SnapshotEventsAndCompletion(t *testing.T) {
+	hub := service.NewHub()
+	svc := &mockService{
+		SubscribeLeaderboardFunc: func(ctx context.Context, leaderboardID string, lastEventID uint64) (*service.Subscription, error) {
+			return hub.Subscribe("lid", lastEventID), nil
+		},
+		GetLeaderboardFunc: func(ctx context.Context, leaderboardID string) (interface{}, error) {
+			return map[string]interface{}{"leaderboard_id": leaderboardID}, nil
+		},
+	}
+	h := NewHandler(svc)
+	srv := httptest.NewServer(NewRouter(h))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/v1/leaderboard/lid/stream")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("expected text/event-stream, got %s", ct)
+	}
+
+	reader := bufio.NewReader(resp.Body)
+	snapshot := readSSEBlock(t, reader)
+	if !strings.Contains(snapshot, "event: snapshot") {
+		t.Errorf("expected snapshot event first, got %q", snapshot)
+	}
+
+	hub.Publish("lid", service.EventScore, map[string]interface{}{"player_id": "p1", "score": 10})
+	hub.Publish("lid", service.EventCompleted, map[string]interface{}{"leaderboard_id": "lid"})
+	hub.CloseTopic("lid")
+
+	score := readSSEBlock(t, reader)
+	if !strings.Contains(score, "id: 1") || !strings.Contains(score, "event: score") {
+		t.Errorf("unexpected score event: %q", score)
+	}
+	completed := readSSEBlock(t, reader)
+	if !strings.Contains(completed, "id: 2") || !strings.Contains(completed, "event: completed") {
+		t.Errorf("unexpected completed event: %q", completed)
+	}
+}
+
+func TestLeaderboardStreamHandler_ResumeSkipsSnapshot(t *testing.T) {
+	hub := service.NewHub()
+	hub.Publish("lid", service.EventScore, map[string]interface{}{"score": 1})
+	hub.Publish("lid", service.EventScore, map[string]interface{}{"score": 2})
+	var gotLastID uint64
+	svc := &mockService{
+		SubscribeLeaderboardFunc: func(ctx context.Context, leaderboardID string, lastEventID uint64) (*service.Subscription, error) {
+			gotLastID = lastEventID
+			return hub.Subscribe("lid", lastEventID), nil
+		},
+		GetLeaderboardFunc: func(ctx context.Context, leaderboardID string) (interface{}, error) {
+			t.Errorf("snapshot should not be fetched on resume")
+			return nil, nil
+		},
+	}
+	h := NewHandler(svc)
+	srv := httptest.NewServer(NewRouter(h))
+	defer srv.Close()
+
+	req, _ := http.NewRequest("GET", srv.URL+"/v1/leaderboard/lid/stream", nil)
+	req.Header.Set("Last-Event-ID", "1")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if gotLastID != 1 {
+		t.Errorf("expected last event id 1, got %d", gotLastID)
+	}
+	block := readSSEBlock(t, bufio.NewReader(resp.Body))
+	if !strings.Contains(block, "id: 2") {
+		t.Errorf("expected replay of event 2, got %q", block)
+	}
+}
+
+func TestLeaderboardStreamHandler_Heartbeat(t *testing.T) {
+	hub := service.NewHub()
+	svc := &mockService{
+		SubscribeLeaderboardFunc: func(ctx context.Context, leaderboardID string, lastEventID uint64) (*service.Subscription, error) {
+			return hub.Subscribe("lid", lastEventID), nil
+		},
+		GetLeaderboardFunc: func(ctx context.Context, leaderboardID string) (interface{}, error) {
+			return nil, errors.New("leaderboard not found")
+		},
+	}
+	h := NewHandler(svc)
+	h.heartbeatInterval = 10 * time.Millisecond
+	srv := httptest.NewServer(NewRouter(h))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/v1/leaderboard/lid/stream")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	block := readSSEBlock(t, bufio.NewReader(resp.Body))
+	if !strings.Contains(block, ": heartbeat") {
+		t.Errorf("expected heartbeat comment, got %q", block)
+	}
+}
+
+// readSSEBlock reads one blank-line terminated SSE block.
+func readSSEBlock(t *testing.T, r *bufio.Reader) string {
+	t.Helper()
+	var b strings.Builder
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("reading stream: %v (read so far %q)", err, b.String())
+		}
+		if line == "\n" {
+			return b.String()
+		}
+		b.WriteString(line)
+	}
+}
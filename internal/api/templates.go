@@ -0,0 +1,129 @@
+package api
+
+import (
+	"encoding/json"
+	"leaderboard-service/internal/model"
+	"leaderboard-service/internal/service"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+const defaultPreviewCount = 5
+
+func (h *Handler) ListTemplatesHandler(w http.ResponseWriter, r *http.Request) {
+	tpls, err := h.service.ListTemplates(r.Context())
+	if err != nil {
+		writeAdminError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"templates": tpls})
+}
+
+func (h *Handler) GetTemplateHandler(w http.ResponseWriter, r *http.Request) {
+	tpl, err := h.service.GetTemplate(r.Context(), mux.Vars(r)["template_id"])
+	if err != nil {
+		writeAdminError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, tpl)
+}
+
+func (h *Handler) CreateTemplateHandler(w http.ResponseWriter, r *http.Request) {
+	def, ok := decodeTemplateDefinition(w, r)
+	if !ok {
+		return
+	}
+	tpl, err := h.service.CreateTemplate(r.Context(), def)
+	if err != nil {
+		writeAdminError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, tpl)
+}
+
+func (h *Handler) UpdateTemplateHandler(w http.ResponseWriter, r *http.Request) {
+	def, ok := decodeTemplateDefinition(w, r)
+	if !ok {
+		return
+	}
+	tpl, err := h.service.UpdateTemplate(r.Context(), mux.Vars(r)["template_id"], def)
+	if err != nil {
+		writeAdminError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, tpl)
+}
+
+func (h *Handler) DeleteTemplateHandler(w http.ResponseWriter, r *http.Request) {
+	reason, ok := decodeReason(w, r)
+	if !ok {
+		return
+	}
+	if err := h.service.DeleteTemplate(r.Context(), mux.Vars(r)["template_id"], reason); err != nil {
+		writeAdminError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"message": "Template deleted"})
+}
+
+func (h *Handler) PreviewTemplateHandler(w http.ResponseWriter, r *http.Request) {
+	count := defaultPreviewCount
+	if raw := r.URL.Query().Get("count"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "count must be an integer")
+			return
+		}
+		count = n
+	}
+	occurrences, err := h.service.PreviewTemplate(r.Context(), mux.Vars(r)["template_id"], count)
+	if err != nil {
+		writeAdminError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"occurrences": occurrences})
+}
+
+// decodeTemplateDefinition reads a template definition from the request
+// body, writing a 400 response if it is malformed. Templates are enabled
+// and sum scores unless the body says otherwise.
+func decodeTemplateDefinition(w http.ResponseWriter, r *http.Request) (service.TemplateDefinition, bool) {
+	var req struct {
+		Name        string            `json:"name"`
+		Schedule    string            `json:"schedule"`
+		Duration    string            `json:"duration"`
+		ScoringMode model.ScoringMode `json:"scoring_mode"`
+		Eligibility model.Eligibility `json:"eligibility"`
+		Rewards     []model.Reward    `json:"rewards"`
+		MaxPlayers  int               `json:"max_players"`
+		Enabled     *bool             `json:"enabled"`
+		Reason      string            `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return service.TemplateDefinition{}, false
+	}
+	duration, err := time.ParseDuration(req.Duration)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "duration must be a duration such as 24h or 90m")
+		return service.TemplateDefinition{}, false
+	}
+	def := service.TemplateDefinition{
+		Name:        req.Name,
+		Schedule:    req.Schedule,
+		Duration:    duration,
+		ScoringMode: req.ScoringMode,
+		Eligibility: req.Eligibility,
+		Rewards:     req.Rewards,
+		MaxPlayers:  req.MaxPlayers,
+		Enabled:     req.Enabled == nil || *req.Enabled,
+		Reason:      req.Reason,
+	}
+	if def.ScoringMode == "" {
+		def.ScoringMode = model.ScoringSum
+	}
+	return def, true
+}
@@ -0,0 +1,64 @@
+package api
+
+import (
+	"encoding/json"
+	"leaderboard-service/internal/model"
+	"leaderboard-service/internal/service"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// GetTournamentHandler returns a tournament's bracket.
+func (h *Handler) GetTournamentHandler(w http.ResponseWriter, r *http.Request) {
+	bracket, err := h.service.GetTournament(r.Context(), mux.Vars(r)["tournament_id"])
+	if err != nil {
+		writeAdminError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, bracket)
+}
+
+// CreateTournamentHandler creates a tournament and starts its first round.
+// Players are seeded by level unless the body says otherwise.
+func (h *Handler) CreateTournamentHandler(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Name          string                 `json:"name"`
+		Format        model.TournamentFormat `json:"format"`
+		Seeding       model.Seeding          `json:"seeding"`
+		PlayerIDs     []string               `json:"player_ids"`
+		GroupSize     int                    `json:"group_size"`
+		Advance       int                    `json:"advance"`
+		RoundDuration string                 `json:"round_duration"`
+		Reason        string                 `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	roundDuration, err := time.ParseDuration(req.RoundDuration)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "round_duration must be a duration such as 1h or 30m")
+		return
+	}
+	def := service.TournamentDefinition{
+		Name:          req.Name,
+		Format:        req.Format,
+		Seeding:       req.Seeding,
+		PlayerIDs:     req.PlayerIDs,
+		GroupSize:     req.GroupSize,
+		Advance:       req.Advance,
+		RoundDuration: roundDuration,
+		Reason:        req.Reason,
+	}
+	if def.Seeding == "" {
+		def.Seeding = model.SeedByLevel
+	}
+	t, err := h.service.CreateTournament(r.Context(), def)
+	if err != nil {
+		writeAdminError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, t)
+}
@@ -0,0 +1,216 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"leaderboard-service/internal/service"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	wsWriteWait      = 10 * time.Second
+	wsPongWait       = 60 * time.Second
+	wsPingPeriod     = (wsPongWait * 9) / 10
+	wsMaxMessageSize = 4096
+	wsOutboxSize     = 16
+)
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+}
+
+// wsMessage is the envelope for every message exchanged over the player
+// WebSocket. Server pushes carry the hub event ID (when there is one) and the
+// event payload; client requests may set request_id to correlate the reply.
+type wsMessage struct {
+	Type      string      `json:"type"`
+	ID        uint64      `json:"id,omitempty"`
+	RequestID string      `json:"request_id,omitempty"`
+	Score     int         `json:"score,omitempty"`
+	Data      interface{} `json:"data,omitempty"`
+	Error     string      `json:"error,omitempty"`
+}
+
+// WebSocketHandler upgrades to a per-player WebSocket that pushes match,
+// leaderboard and competition-end notifications, and accepts join and score
+// submission requests over the same connection.
+func (h *Handler) WebSocketHandler(w http.ResponseWriter, r *http.Request) {
+	playerID := r.URL.Query().Get("player_id")
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	sub, err := h.service.SubscribePlayer(ctx, playerID)
+	if err != nil {
+		if err.Error() == "player not found" {
+			w.WriteHeader(http.StatusNotFound)
+		} else {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+	defer sub.Close()
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		logger(ctx).Info("WebSocket upgrade failed", "player_id", playerID, "error", err)
+		return
+	}
+	defer conn.Close()
+	logger(ctx).Info("WebSocket connected", "player_id", playerID)
+
+	outbox := make(chan wsMessage, wsOutboxSize)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		h.wsWriteLoop(ctx, conn, playerID, sub, outbox)
+		cancel()
+		// Unblock the reader, which only notices a closed connection.
+		conn.Close()
+	}()
+	h.wsReadLoop(ctx, conn, playerID, outbox)
+	cancel()
+	<-done
+	logger(ctx).Info("WebSocket disconnected", "player_id", playerID)
+}
+
+// wsReadLoop handles client requests until the connection fails or ctx ends.
+func (h *Handler) wsReadLoop(ctx context.Context, conn *websocket.Conn, playerID string, outbox chan<- wsMessage) {
+	conn.SetReadLimit(wsMaxMessageSize)
+	conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+	for {
+		var msg wsMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+				logger(ctx).Info("WebSocket read error", "player_id", playerID, "error", err)
+			}
+			return
+		}
+		reply := wsMessage{Type: "ack", RequestID: msg.RequestID}
+		switch msg.Type {
+		case "submit_score":
+			if err := h.service.SubmitScore(ctx, playerID, msg.Score); err != nil {
+				reply = wsMessage{Type: "error", RequestID: msg.RequestID, Error: err.Error()}
+			}
+		case "join":
+			if _, err := h.service.Join(ctx, playerID); err != nil {
+				reply = wsMessage{Type: "error", RequestID: msg.RequestID, Error: err.Error()}
+			}
+		default:
+			reply = wsMessage{Type: "error", RequestID: msg.RequestID, Error: "unknown message type"}
+		}
+		select {
+		case outbox <- reply:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// wsWriteLoop is the connection's only writer. It relays player events, keeps
+// a leaderboard subscription for the player's current competition and sends
+// keepalive pings.
+func (h *Handler) wsWriteLoop(ctx context.Context, conn *websocket.Conn, playerID string, playerSub *service.Subscription, outbox <-chan wsMessage) {
+	ping := time.NewTicker(wsPingPeriod)
+	defer ping.Stop()
+
+	var compSub *service.Subscription
+	var compID string
+	var lastCompEventID uint64
+	defer func() {
+		if compSub != nil {
+			compSub.Close()
+		}
+	}()
+
+	subscribe := func(id string, lastEventID uint64) {
+		if compSub != nil {
+			compSub.Close()
+			compSub = nil
+		}
+		sub, err := h.service.SubscribeLeaderboard(ctx, id, lastEventID)
+		if err != nil {
+			logger(ctx).Warn("WebSocket could not subscribe to competition", "player_id", playerID, "competition_id", id, "error", err)
+			return
+		}
+		compSub, compID, lastCompEventID = sub, id, lastEventID
+	}
+
+	write := func(msg wsMessage) bool {
+		conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+		if err := conn.WriteJSON(msg); err != nil {
+			logger(ctx).Info("WebSocket write error", "player_id", playerID, "error", err)
+			return false
+		}
+		return true
+	}
+
+	for {
+		var compC <-chan service.Event
+		if compSub != nil {
+			compC = compSub.C
+		}
+		select {
+		case <-ctx.Done():
+			conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+			return
+		case msg := <-outbox:
+			if !write(msg) {
+				return
+			}
+		case ev, ok := <-playerSub.C:
+			if !ok {
+				return
+			}
+			if ev.Type == service.EventMatched {
+				if data, ok := ev.Data.(map[string]interface{}); ok {
+					if id, ok := data["leaderboard_id"].(string); ok && id != compID {
+						subscribe(id, 0)
+					}
+				}
+			}
+			if !write(wsMessage{Type: ev.Type, Data: ev.Data}) {
+				return
+			}
+		case ev, ok := <-compC:
+			if !ok {
+				// Dropped for falling behind; resume from the last event seen,
+				// or start over from a snapshot if none had an ID yet.
+				id, last := compID, lastCompEventID
+				compSub, compID = nil, ""
+				subscribe(id, last)
+				if compSub != nil && !compSub.Resumed {
+					if snapshot, err := h.service.GetLeaderboard(ctx, id); err == nil {
+						if !write(wsMessage{Type: service.EventSnapshot, Data: snapshot}) {
+							return
+						}
+					}
+				}
+				continue
+			}
+			if ev.ID > 0 {
+				lastCompEventID = ev.ID
+			}
+			if !write(wsMessage{Type: ev.Type, ID: ev.ID, Data: ev.Data}) {
+				return
+			}
+			if ev.Type == service.EventCompleted {
+				compSub.Close()
+				compSub, compID, lastCompEventID = nil, "", 0
+			}
+		case <-ping.C:
+			conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
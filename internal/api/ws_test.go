@@ -0,0 +1,160 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"leaderboard-service/internal/service"
+
+	"github.com/gorilla/websocket"
+)
+
+func dialPlayerWS(t *testing.T, srv *httptest.Server, playerID string) *websocket.Conn {
+	t.Helper()
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/v1/ws?player_id=" + playerID
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	return conn
+}
+
+func TestWebSocketHandler_PlayerNotFound(t *testing.T) {
+	h := NewHandler(&mockService{})
+	req := httptest.NewRequest("GET", "/ws?player_id=p1", nil)
+	rec := httptest.NewRecorder()
+	h.WebSocketHandler(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", rec.Code)
+	}
+}
+
+func TestWebSocketHandler_MatchedThenLeaderboardUpdates(t *testing.T) {
+	hub := service.NewHub()
+	subscribed := make(chan string, 1)
+	svc := &mockService{
+		SubscribePlayerFunc: func(ctx context.Context, playerID string) (*service.Subscription, error) {
+			return hub.Subscribe("player:"+playerID, 0), nil
+		},
+		SubscribeLeaderboardFunc: func(ctx context.Context, leaderboardID string, lastEventID uint64) (*service.Subscription, error) {
+			sub := hub.Subscribe("competition:"+leaderboardID, lastEventID)
+			subscribed <- leaderboardID
+			return sub, nil
+		},
+	}
+	srv := httptest.NewServer(NewRouter(NewHandler(svc)))
+	defer srv.Close()
+	conn := dialPlayerWS(t, srv, "p1")
+	defer conn.Close()
+
+	hub.Publish("player:p1", service.EventMatched, map[string]interface{}{"leaderboard_id": "c1"})
+	var msg wsMessage
+	if err := conn.ReadJSON(&msg); err != nil || msg.Type != service.EventMatched {
+		t.Fatalf("expected matched message, got %+v (%v)", msg, err)
+	}
+	select {
+	case id := <-subscribed:
+		if id != "c1" {
+			t.Errorf("expected subscription to c1, got %s", id)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("gateway did not subscribe to the matched competition")
+	}
+
+	hub.Publish("competition:c1", service.EventScore, map[string]interface{}{"player_id": "p2", "score": 5})
+	hub.Publish("competition:c1", service.EventCompleted, map[string]interface{}{"leaderboard_id": "c1"})
+	if err := conn.ReadJSON(&msg); err != nil || msg.Type != service.EventScore || msg.ID != 1 {
+		t.Errorf("expected score message, got %+v (%v)", msg, err)
+	}
+	if err := conn.ReadJSON(&msg); err != nil || msg.Type != service.EventCompleted {
+		t.Errorf("expected completed message, got %+v (%v)", msg, err)
+	}
+}
+
+func TestWebSocketHandler_ResubscribesWhenDroppedBeforeAnyEvent(t *testing.T) {
+	hub := service.NewHub()
+	// The first subscription ends at once, as if the hub dropped it before
+	// any event arrived.
+	hub.CloseTopic("dropped")
+	subscriptions := make(chan uint64, 2)
+	svc := &mockService{
+		SubscribePlayerFunc: func(ctx context.Context, playerID string) (*service.Subscription, error) {
+			return hub.Subscribe("player:"+playerID, 0), nil
+		},
+		SubscribeLeaderboardFunc: func(ctx context.Context, leaderboardID string, lastEventID uint64) (*service.Subscription, error) {
+			topic := "competition:" + leaderboardID
+			if len(subscriptions) == 0 {
+				topic = "dropped"
+			}
+			subscriptions <- lastEventID
+			return hub.Subscribe(topic, lastEventID), nil
+		},
+		GetLeaderboardFunc: func(ctx context.Context, leaderboardID string) (interface{}, error) {
+			return map[string]interface{}{"leaderboard_id": leaderboardID}, nil
+		},
+	}
+	srv := httptest.NewServer(NewRouter(NewHandler(svc)))
+	defer srv.Close()
+	conn := dialPlayerWS(t, srv, "p1")
+	defer conn.Close()
+
+	hub.Publish("player:p1", service.EventMatched, map[string]interface{}{"leaderboard_id": "c1"})
+	var msg wsMessage
+	if err := conn.ReadJSON(&msg); err != nil || msg.Type != service.EventMatched {
+		t.Fatalf("expected matched message, got %+v (%v)", msg, err)
+	}
+	if err := conn.ReadJSON(&msg); err != nil || msg.Type != service.EventSnapshot {
+		t.Fatalf("expected a snapshot after resubscribing, got %+v (%v)", msg, err)
+	}
+	if len(subscriptions) != 2 {
+		t.Fatalf("expected the gateway to subscribe again, got %d subscriptions", len(subscriptions))
+	}
+	hub.Publish("competition:c1", service.EventScore, map[string]interface{}{"player_id": "p2", "score": 5})
+	if err := conn.ReadJSON(&msg); err != nil || msg.Type != service.EventScore {
+		t.Errorf("expected live updates after resubscribing, got %+v (%v)", msg, err)
+	}
+}
+
+func TestWebSocketHandler_SubmitScore(t *testing.T) {
+	hub := service.NewHub()
+	svc := &mockService{
+		SubscribePlayerFunc: func(ctx context.Context, playerID string) (*service.Subscription, error) {
+			return hub.Subscribe("player:"+playerID, 0), nil
+		},
+		SubmitScoreFunc: func(ctx context.Context, playerID string, score int) error {
+			if playerID != "p1" {
+				t.Errorf("unexpected player: %s", playerID)
+			}
+			if score < 0 {
+				return errors.New("player not in active competition")
+			}
+			return nil
+		},
+	}
+	srv := httptest.NewServer(NewRouter(NewHandler(svc)))
+	defer srv.Close()
+	conn := dialPlayerWS(t, srv, "p1")
+	defer conn.Close()
+
+	conn.WriteJSON(wsMessage{Type: "submit_score", RequestID: "r1", Score: 10})
+	var msg wsMessage
+	if err := conn.ReadJSON(&msg); err != nil || msg.Type != "ack" || msg.RequestID != "r1" {
+		t.Errorf("expected ack for r1, got %+v (%v)", msg, err)
+	}
+
+	conn.WriteJSON(wsMessage{Type: "submit_score", RequestID: "r2", Score: -1})
+	if err := conn.ReadJSON(&msg); err != nil || msg.Type != "error" || msg.Error != "player not in active competition" {
+		t.Errorf("expected error for r2, got %+v (%v)", msg, err)
+	}
+
+	conn.WriteJSON(wsMessage{Type: "bogus", RequestID: "r3"})
+	if err := conn.ReadJSON(&msg); err != nil || msg.Type != "error" || msg.RequestID != "r3" {
+		t.Errorf("expected unknown type error, got %+v (%v)", msg, err)
+	}
+}
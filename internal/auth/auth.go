@@ -0,0 +1,40 @@
+// Package auth identifies the operator behind administrative requests so
+// their changes can be attributed in the audit log.
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"strings"
+)
+
+type actorKey struct{}
+
+// WithActor returns a context carrying the name of whoever is acting.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// Actor returns the actor carried by ctx, or "" if there is none.
+func Actor(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
+
+// Authenticate resolves an "Authorization: Bearer <token>" header against
+// tokens, which maps actor names to their tokens.
+func Authenticate(tokens map[string]string, header string) (string, bool) {
+	token, ok := strings.CutPrefix(header, "Bearer ")
+	if !ok || token == "" {
+		return "", false
+	}
+	matched := ""
+	for actor, want := range tokens {
+		// Compare against every token so timing doesn't reveal which
+		// actors exist.
+		if subtle.ConstantTimeCompare([]byte(token), []byte(want)) == 1 {
+			matched = actor
+		}
+	}
+	return matched, matched != ""
+}
@@ -0,0 +1,436 @@
+// Package config loads the service configuration from defaults, an optional
+// YAML or JSON file, environment variables and command-line flags, in that
+// order of increasing precedence, and validates the result.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"leaderboard-service/internal/logging"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the complete service configuration.
+type Config struct {
+	HTTP        HTTPConfig        `yaml:"http"`
+	GRPC        GRPCConfig        `yaml:"grpc"`
+	DB          DBConfig          `yaml:"db"`
+	Matchmaking MatchmakingConfig `yaml:"matchmaking"`
+	Log         LogConfig         `yaml:"log"`
+	Tracing     TracingConfig     `yaml:"tracing"`
+	Shutdown    ShutdownConfig    `yaml:"shutdown"`
+	Migrate     MigrateConfig     `yaml:"migrate"`
+	Admin       AdminConfig       `yaml:"admin"`
+	Privacy     PrivacyConfig     `yaml:"privacy"`
+	Profiles    ProfilesConfig    `yaml:"profiles"`
+}
+
+type HTTPConfig struct {
+	Port              int           `yaml:"port"`
+	ReadTimeout       time.Duration `yaml:"read_timeout"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout"`
+	WriteTimeout      time.Duration `yaml:"write_timeout"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout"`
+}
+
+type GRPCConfig struct {
+	Port int `yaml:"port"`
+}
+
+type DBConfig struct {
+	Host            string        `yaml:"host"`
+	Port            int           `yaml:"port"`
+	User            string        `yaml:"user"`
+	Password        string        `yaml:"password"`
+	Name            string        `yaml:"name"`
+	SSLMode         string        `yaml:"sslmode"`
+	MaxOpenConns    int           `yaml:"max_open_conns"`
+	MaxIdleConns    int           `yaml:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime"`
+}
+
+// DSN returns the lib/pq connection string.
+func (c DBConfig) DSN() string {
+	return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+		c.Host, c.Port, c.User, c.Password, c.Name, c.SSLMode)
+}
+
+type MatchmakingConfig struct {
+	Interval            time.Duration `yaml:"interval"`
+	CompetitionDuration time.Duration `yaml:"competition_duration"`
+	MinGroupSize        int           `yaml:"min_group_size"`
+	MaxGroupSize        int           `yaml:"max_group_size"`
+	// PromotePercent and RelegatePercent are the shares of a competition's
+	// players who move up or down a league tier when it completes.
+	PromotePercent  int `yaml:"promote_percent"`
+	RelegatePercent int `yaml:"relegate_percent"`
+	// PartyLevel rates a party by its members' MAX or AVERAGE level.
+	PartyLevel string `yaml:"party_level"`
+}
+
+type LogConfig struct {
+	Format string `yaml:"format"`
+	Level  string `yaml:"level"`
+}
+
+type TracingConfig struct {
+	Exporter string `yaml:"exporter"`
+}
+
+type ShutdownConfig struct {
+	Timeout    time.Duration `yaml:"timeout"`
+	DrainDelay time.Duration `yaml:"drain_delay"`
+}
+
+type MigrateConfig struct {
+	OnStart bool `yaml:"on_start"`
+}
+
+type AdminConfig struct {
+	// Tokens maps operator names to the bearer tokens that authenticate
+	// them on the admin API. The admin API is disabled when it is empty.
+	Tokens map[string]string `yaml:"tokens"`
+}
+
+type PrivacyConfig struct {
+	// Retention is how long what is left of a deleted player is kept
+	// before the purge job strips it; PurgeInterval is how often it runs.
+	Retention     time.Duration `yaml:"retention"`
+	PurgeInterval time.Duration `yaml:"purge_interval"`
+}
+
+type ProfilesConfig struct {
+	// BlockedWords are words display names may not contain, ignoring case.
+	BlockedWords []string `yaml:"blocked_words"`
+}
+
+// minTokenLength keeps admin tokens from being guessable.
+const minTokenLength = 16
+
+// Default returns the configuration used when nothing overrides it.
+func Default() Config {
+	return Config{
+		HTTP: HTTPConfig{
+			Port:              8080,
+			ReadTimeout:       10 * time.Second,
+			ReadHeaderTimeout: 5 * time.Second,
+			WriteTimeout:      15 * time.Second,
+			IdleTimeout:       60 * time.Second,
+		},
+		GRPC: GRPCConfig{Port: 9090},
+		DB: DBConfig{
+			Host:            "localhost",
+			Port:            5432,
+			SSLMode:         "disable",
+			MaxOpenConns:    20,
+			MaxIdleConns:    10,
+			ConnMaxLifetime: 30 * time.Second,
+		},
+		Matchmaking: MatchmakingConfig{
+			Interval:            15 * time.Second,
+			CompetitionDuration: 30 * time.Second,
+			MinGroupSize:        2,
+			MaxGroupSize:        10,
+			PromotePercent:      20,
+			RelegatePercent:     20,
+			PartyLevel:          "MAX",
+		},
+		Log:      LogConfig{Format: "json", Level: "info"},
+		Tracing:  TracingConfig{Exporter: "none"},
+		Shutdown: ShutdownConfig{Timeout: 20 * time.Second, DrainDelay: 5 * time.Second},
+		Migrate:  MigrateConfig{OnStart: true},
+		Privacy:  PrivacyConfig{Retention: 30 * 24 * time.Hour, PurgeInterval: time.Hour},
+	}
+}
+
+// setting binds one configuration value to its environment variable and
+// flag.
+type setting struct {
+	env   string
+	flag  string
+	usage string
+	field func(c *Config) interface{}
+}
+
+var settings = []setting{
+	{"HTTP_PORT", "http-port", "HTTP listen port", func(c *Config) interface{} { return &c.HTTP.Port }},
+	{"HTTP_READ_TIMEOUT", "http-read-timeout", "HTTP read timeout", func(c *Config) interface{} { return &c.HTTP.ReadTimeout }},
+	{"HTTP_READ_HEADER_TIMEOUT", "http-read-header-timeout", "HTTP read header timeout", func(c *Config) interface{} { return &c.HTTP.ReadHeaderTimeout }},
+	{"HTTP_WRITE_TIMEOUT", "http-write-timeout", "HTTP write timeout", func(c *Config) interface{} { return &c.HTTP.WriteTimeout }},
+	{"HTTP_IDLE_TIMEOUT", "http-idle-timeout", "HTTP keep-alive idle timeout", func(c *Config) interface{} { return &c.HTTP.IdleTimeout }},
+	{"GRPC_PORT", "grpc-port", "gRPC listen port", func(c *Config) interface{} { return &c.GRPC.Port }},
+	{"DB_HOST", "db-host", "Postgres host", func(c *Config) interface{} { return &c.DB.Host }},
+	{"DB_PORT", "db-port", "Postgres port", func(c *Config) interface{} { return &c.DB.Port }},
+	{"DB_USER", "db-user", "Postgres user", func(c *Config) interface{} { return &c.DB.User }},
+	{"DB_PASSWORD", "db-password", "Postgres password", func(c *Config) interface{} { return &c.DB.Password }},
+	{"DB_NAME", "db-name", "Postgres database name", func(c *Config) interface{} { return &c.DB.Name }},
+	{"DB_SSLMODE", "db-sslmode", "Postgres sslmode", func(c *Config) interface{} { return &c.DB.SSLMode }},
+	{"DB_MAX_OPEN_CONNS", "db-max-open-conns", "maximum open database connections", func(c *Config) interface{} { return &c.DB.MaxOpenConns }},
+	{"DB_MAX_IDLE_CONNS", "db-max-idle-conns", "maximum idle database connections", func(c *Config) interface{} { return &c.DB.MaxIdleConns }},
+	{"DB_CONN_MAX_LIFETIME", "db-conn-max-lifetime", "maximum lifetime of a database connection (0 = unlimited)", func(c *Config) interface{} { return &c.DB.ConnMaxLifetime }},
+	{"MATCHMAKING_INTERVAL", "matchmaking-interval", "how often the matchmaking worker runs", func(c *Config) interface{} { return &c.Matchmaking.Interval }},
+	{"COMPETITION_DURATION", "competition-duration", "how long a competition lasts", func(c *Config) interface{} { return &c.Matchmaking.CompetitionDuration }},
+	{"MATCHMAKING_MIN_GROUP_SIZE", "matchmaking-min-group-size", "fewest players that start a competition", func(c *Config) interface{} { return &c.Matchmaking.MinGroupSize }},
+	{"MATCHMAKING_MAX_GROUP_SIZE", "matchmaking-max-group-size", "most players in one competition, 0 for no limit", func(c *Config) interface{} { return &c.Matchmaking.MaxGroupSize }},
+	{"MATCHMAKING_PROMOTE_PERCENT", "matchmaking-promote-percent", "percentage of a competition promoted a tier", func(c *Config) interface{} { return &c.Matchmaking.PromotePercent }},
+	{"MATCHMAKING_RELEGATE_PERCENT", "matchmaking-relegate-percent", "percentage of a competition relegated a tier", func(c *Config) interface{} { return &c.Matchmaking.RelegatePercent }},
+	{"MATCHMAKING_PARTY_LEVEL", "matchmaking-party-level", "level a party is matched at: MAX or AVERAGE of its members'", func(c *Config) interface{} { return &c.Matchmaking.PartyLevel }},
+	{"LOG_FORMAT", "log-format", "log format: json or text", func(c *Config) interface{} { return &c.Log.Format }},
+	{"LOG_LEVEL", "log-level", "log level: debug, info, warn or error", func(c *Config) interface{} { return &c.Log.Level }},
+	{"OTEL_TRACES_EXPORTER", "traces-exporter", "trace exporter: otlp, stdout or none", func(c *Config) interface{} { return &c.Tracing.Exporter }},
+	{"SHUTDOWN_TIMEOUT", "shutdown-timeout", "how long to wait for in-flight work on shutdown", func(c *Config) interface{} { return &c.Shutdown.Timeout }},
+	{"SHUTDOWN_DRAIN_DELAY", "shutdown-drain-delay", "how long to fail readiness before closing listeners", func(c *Config) interface{} { return &c.Shutdown.DrainDelay }},
+	{"MIGRATE_ON_START", "migrate-on-start", "apply pending migrations before serving", func(c *Config) interface{} { return &c.Migrate.OnStart }},
+	{"PRIVACY_RETENTION", "privacy-retention", "how long deleted players' data is kept before it is purged", func(c *Config) interface{} { return &c.Privacy.Retention }},
+	{"PRIVACY_PURGE_INTERVAL", "privacy-purge-interval", "how often the purge job runs", func(c *Config) interface{} { return &c.Privacy.PurgeInterval }},
+	{"PROFILE_BLOCKED_WORDS", "profile-blocked-words", "comma-separated words display names may not contain", func(c *Config) interface{} { return &c.Profiles.BlockedWords }},
+	{"ADMIN_TOKENS", "admin-tokens", "admin API tokens as actor=token,actor=token", func(c *Config) interface{} { return &c.Admin.Tokens }},
+}
+
+// FileEnv names the environment variable that points at a config file when
+// -config is not given.
+const FileEnv = "CONFIG_FILE"
+
+// Load builds the configuration from defaults, the config file, the
+// environment (read through lookupEnv) and args, then validates it. It
+// returns the arguments left after the flags, i.e. the command to run.
+func Load(args []string, lookupEnv func(string) (string, bool)) (*Config, []string, error) {
+	fs := flag.NewFlagSet("server", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	path := fs.String("config", "", "path to a YAML or JSON config file (env "+FileEnv+")")
+	flagValues := map[string]*string{}
+	for _, s := range settings {
+		flagValues[s.flag] = fs.String(s.flag, "", s.usage+" (env "+s.env+")")
+	}
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil, nil, fmt.Errorf("%w\n\n%s", err, Usage())
+		}
+		return nil, nil, err
+	}
+
+	cfg := Default()
+	if *path == "" {
+		*path, _ = lookupEnv(FileEnv)
+	}
+	if *path != "" {
+		if err := loadFile(&cfg, *path); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var errs []error
+	for _, s := range settings {
+		if raw, ok := lookupEnv(s.env); ok && raw != "" {
+			if err := set(s.field(&cfg), raw); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", s.env, err))
+			}
+		}
+	}
+	fs.Visit(func(f *flag.Flag) {
+		for _, s := range settings {
+			if s.flag == f.Name {
+				if err := set(s.field(&cfg), *flagValues[s.flag]); err != nil {
+					errs = append(errs, fmt.Errorf("-%s: %w", s.flag, err))
+				}
+			}
+		}
+	})
+	if len(errs) > 0 {
+		return nil, nil, invalid(errs)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, nil, err
+	}
+	return &cfg, fs.Args(), nil
+}
+
+// Usage describes the flags Load accepts.
+func Usage() string {
+	var b strings.Builder
+	b.WriteString("flags:\n  -config string\n        path to a YAML or JSON config file (env " + FileEnv + ")\n")
+	for _, s := range settings {
+		fmt.Fprintf(&b, "  -%s value\n        %s (env %s)\n", s.flag, s.usage, s.env)
+	}
+	return b.String()
+}
+
+// loadFile decodes a YAML or JSON file (JSON being a subset of YAML) over
+// cfg, rejecting keys the config doesn't have.
+func loadFile(cfg *Config, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading config file: %w", err)
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("parsing config file %s: %w", path, err)
+	}
+	return nil
+}
+
+func set(field interface{}, raw string) error {
+	switch p := field.(type) {
+	case *string:
+		*p = raw
+	case *int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("%q is not an integer", raw)
+		}
+		*p = n
+	case *bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("%q is not a boolean", raw)
+		}
+		*p = b
+	case *map[string]string:
+		m := map[string]string{}
+		for _, pair := range strings.Split(raw, ",") {
+			name, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+			if !ok {
+				return fmt.Errorf("expected name=value pairs separated by commas")
+			}
+			m[name] = value
+		}
+		*p = m
+	case *[]string:
+		var values []string
+		for _, v := range strings.Split(raw, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+		*p = values
+	case *time.Duration:
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("%q is not a duration (e.g. 15s, 1m)", raw)
+		}
+		*p = d
+	default:
+		panic(fmt.Sprintf("config: unsupported field type %T", field))
+	}
+	return nil
+}
+
+// Validate reports every invalid value at once.
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+	port := func(name string, p int) {
+		check(p > 0 && p <= 65535, "%s must be between 1 and 65535, got %d", name, p)
+	}
+	positive := func(name string, d time.Duration) {
+		check(d > 0, "%s must be positive, got %s", name, d)
+	}
+
+	port("http.port", c.HTTP.Port)
+	port("grpc.port", c.GRPC.Port)
+	check(c.HTTP.Port != c.GRPC.Port, "http.port and grpc.port must differ, both are %d", c.HTTP.Port)
+	positive("http.read_timeout", c.HTTP.ReadTimeout)
+	positive("http.read_header_timeout", c.HTTP.ReadHeaderTimeout)
+	positive("http.write_timeout", c.HTTP.WriteTimeout)
+	positive("http.idle_timeout", c.HTTP.IdleTimeout)
+
+	check(c.DB.Host != "", "db.host is required")
+	port("db.port", c.DB.Port)
+	check(c.DB.User != "", "db.user is required")
+	check(c.DB.Name != "", "db.name is required")
+	check(c.DB.MaxOpenConns > 0, "db.max_open_conns must be positive, got %d", c.DB.MaxOpenConns)
+	check(c.DB.MaxIdleConns >= 0 && c.DB.MaxIdleConns <= c.DB.MaxOpenConns,
+		"db.max_idle_conns must be between 0 and db.max_open_conns (%d), got %d", c.DB.MaxOpenConns, c.DB.MaxIdleConns)
+	check(c.DB.ConnMaxLifetime >= 0, "db.conn_max_lifetime must not be negative, got %s", c.DB.ConnMaxLifetime)
+
+	positive("matchmaking.interval", c.Matchmaking.Interval)
+	positive("matchmaking.competition_duration", c.Matchmaking.CompetitionDuration)
+	check(c.Matchmaking.MinGroupSize >= 2, "matchmaking.min_group_size must be at least 2, got %d", c.Matchmaking.MinGroupSize)
+	check(c.Matchmaking.MaxGroupSize == 0 || c.Matchmaking.MaxGroupSize >= c.Matchmaking.MinGroupSize,
+		"matchmaking.max_group_size (%d) must be 0 (no limit) or at least matchmaking.min_group_size (%d)", c.Matchmaking.MaxGroupSize, c.Matchmaking.MinGroupSize)
+	check(c.Matchmaking.PromotePercent >= 0 && c.Matchmaking.RelegatePercent >= 0 && c.Matchmaking.PromotePercent+c.Matchmaking.RelegatePercent <= 100,
+		"matchmaking.promote_percent (%d) and matchmaking.relegate_percent (%d) must not be negative or add up to more than 100",
+		c.Matchmaking.PromotePercent, c.Matchmaking.RelegatePercent)
+	check(c.Matchmaking.PartyLevel == "MAX" || c.Matchmaking.PartyLevel == "AVERAGE",
+		"matchmaking.party_level must be MAX or AVERAGE, got %q", c.Matchmaking.PartyLevel)
+
+	check(c.Log.Format == "json" || c.Log.Format == "text", "log.format must be json or text, got %q", c.Log.Format)
+	if _, err := logging.ParseLevel(c.Log.Level); err != nil {
+		errs = append(errs, fmt.Errorf("log.level must be debug, info, warn or error, got %q", c.Log.Level))
+	}
+	switch c.Tracing.Exporter {
+	case "none", "otlp", "stdout", "console":
+	default:
+		errs = append(errs, fmt.Errorf("tracing.exporter must be otlp, stdout or none, got %q", c.Tracing.Exporter))
+	}
+
+	seen := map[string]string{}
+	for actor, token := range c.Admin.Tokens {
+		check(actor != "", "admin.tokens has an empty actor name")
+		check(len(token) >= minTokenLength, "admin.tokens[%s] must be at least %d characters", actor, minTokenLength)
+		if other, dup := seen[token]; dup {
+			errs = append(errs, fmt.Errorf("admin.tokens[%s] and admin.tokens[%s] share a token", other, actor))
+		}
+		seen[token] = actor
+	}
+
+	positive("privacy.retention", c.Privacy.Retention)
+	positive("privacy.purge_interval", c.Privacy.PurgeInterval)
+
+	positive("shutdown.timeout", c.Shutdown.Timeout)
+	check(c.Shutdown.DrainDelay >= 0, "shutdown.drain_delay must not be negative, got %s", c.Shutdown.DrainDelay)
+
+	if len(errs) > 0 {
+		return invalid(errs)
+	}
+	return nil
+}
+
+func invalid(errs []error) error {
+	msgs := make([]string, len(errs))
+	for i, err := range errs {
+		msgs[i] = "  - " + err.Error()
+	}
+	return fmt.Errorf("invalid configuration:\n%s", strings.Join(msgs, "\n"))
+}
+
+// redacted replaces secrets in printed configuration.
+const redacted = "REDACTED"
+
+// Redacted returns a copy of c with secrets replaced.
+func (c Config) Redacted() Config {
+	if c.DB.Password != "" {
+		c.DB.Password = redacted
+	}
+	if len(c.Admin.Tokens) > 0 {
+		tokens := make(map[string]string, len(c.Admin.Tokens))
+		for actor := range c.Admin.Tokens {
+			tokens[actor] = redacted
+		}
+		c.Admin.Tokens = tokens
+	}
+	return c
+}
+
+// Print writes the effective configuration as YAML with secrets redacted.
+func (c Config) Print(w io.Writer) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(c.Redacted()); err != nil {
+		return err
+	}
+	return enc.Close()
+}
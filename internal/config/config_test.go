@@ -0,0 +1,192 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// env returns a lookup function over vars, with the required DB settings
+// filled in unless overridden.
+func env(vars map[string]string) func(string) (string, bool) {
+	all := map[string]string{"DB_USER": "leaderboard", "DB_NAME": "leaderboard"}
+	for k, v := range vars {
+		all[k] = v
+	}
+	return func(key string) (string, bool) {
+		v, ok := all[key]
+		return v, ok
+	}
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write config file: %v", err)
+	}
+	return path
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	cfg, args, err := Load(nil, env(nil))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(args) != 0 {
+		t.Errorf("expected no command, got %v", args)
+	}
+	if cfg.Matchmaking.Interval != 15*time.Second || cfg.HTTP.Port != 8080 || cfg.DB.MaxOpenConns != 20 || cfg.Privacy.Retention != 30*24*time.Hour {
+		t.Errorf("unexpected defaults: %+v", cfg)
+	}
+}
+
+func TestLoad_Precedence(t *testing.T) {
+	path := writeFile(t, "config.yaml", `
+http:
+  port: 8081
+grpc:
+  port: 9091
+matchmaking:
+  interval: 1m
+log:
+  level: warn
+`)
+	cfg, args, err := Load(
+		[]string{"-config", path, "-log-level", "debug", "migrate", "status"},
+		env(map[string]string{"GRPC_PORT": "9092", "LOG_LEVEL": "error"}),
+	)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.HTTP.Port != 8081 {
+		t.Errorf("expected file to override default port, got %d", cfg.HTTP.Port)
+	}
+	if cfg.Matchmaking.Interval != time.Minute {
+		t.Errorf("expected file duration 1m, got %s", cfg.Matchmaking.Interval)
+	}
+	if cfg.GRPC.Port != 9092 {
+		t.Errorf("expected env to override file, got %d", cfg.GRPC.Port)
+	}
+	if cfg.Log.Level != "debug" {
+		t.Errorf("expected flag to override env, got %q", cfg.Log.Level)
+	}
+	if strings.Join(args, " ") != "migrate status" {
+		t.Errorf("expected remaining command, got %v", args)
+	}
+}
+
+func TestLoad_JSONFileFromEnv(t *testing.T) {
+	path := writeFile(t, "config.json", `{"matchmaking": {"min_group_size": 4, "max_group_size": 8}}`)
+	cfg, _, err := Load(nil, env(map[string]string{FileEnv: path}))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Matchmaking.MinGroupSize != 4 || cfg.Matchmaking.MaxGroupSize != 8 {
+		t.Errorf("unexpected group sizes: %+v", cfg.Matchmaking)
+	}
+}
+
+func TestLoad_RejectsUnknownFileKeys(t *testing.T) {
+	path := writeFile(t, "config.yaml", "matchmaking:\n  intervall: 1m\n")
+	if _, _, err := Load([]string{"-config", path}, env(nil)); err == nil || !strings.Contains(err.Error(), "intervall") {
+		t.Errorf("expected unknown key error, got %v", err)
+	}
+}
+
+func TestLoad_ReportsEveryInvalidValue(t *testing.T) {
+	_, _, err := Load(nil, env(map[string]string{
+		"MATCHMAKING_INTERVAL":        "0s",
+		"MATCHMAKING_MIN_GROUP_SIZE":  "5",
+		"MATCHMAKING_MAX_GROUP_SIZE":  "3",
+		"MATCHMAKING_PROMOTE_PERCENT": "-1",
+		"MATCHMAKING_PARTY_LEVEL":     "MEDIAN",
+		"PRIVACY_RETENTION":           "0s",
+		"LOG_FORMAT":                  "xml",
+		"DB_USER":                     "",
+	}))
+	if err == nil {
+		t.Fatal("expected validation to fail")
+	}
+	for _, want := range []string{"matchmaking.interval", "matchmaking.max_group_size", "matchmaking.promote_percent", "matchmaking.party_level", "privacy.retention", "log.format", "db.user"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in error:\n%v", want, err)
+		}
+	}
+}
+
+func TestLoad_AcceptsUnlimitedGroupSize(t *testing.T) {
+	cfg, _, err := Load(nil, env(map[string]string{"MATCHMAKING_MAX_GROUP_SIZE": "0"}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Matchmaking.MaxGroupSize != 0 {
+		t.Errorf("expected max group size 0, got %d", cfg.Matchmaking.MaxGroupSize)
+	}
+}
+
+func TestLoad_UnparsableValues(t *testing.T) {
+	_, _, err := Load([]string{"-http-port", "eighty"}, env(map[string]string{"COMPETITION_DURATION": "30"}))
+	if err == nil {
+		t.Fatal("expected parse errors")
+	}
+	for _, want := range []string{"COMPETITION_DURATION", "-http-port"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in error:\n%v", want, err)
+		}
+	}
+}
+
+func TestPrint_RedactsSecrets(t *testing.T) {
+	cfg, _, err := Load(nil, env(map[string]string{"DB_PASSWORD": "hunter2"}))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	var out bytes.Buffer
+	if err := cfg.Print(&out); err != nil {
+		t.Fatalf("Print failed: %v", err)
+	}
+	if strings.Contains(out.String(), "hunter2") || !strings.Contains(out.String(), redacted) {
+		t.Errorf("expected password to be redacted:\n%s", out.String())
+	}
+	if !strings.Contains(out.String(), "interval: 15s") {
+		t.Errorf("expected durations printed as strings:\n%s", out.String())
+	}
+	if cfg.DB.Password != "hunter2" {
+		t.Error("Print must not modify the config")
+	}
+}
+
+func TestLoad_BlockedWords(t *testing.T) {
+	cfg, _, err := Load([]string{"-profile-blocked-words", "darn, heck,,"}, env(nil))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if got := cfg.Profiles.BlockedWords; len(got) != 2 || got[0] != "darn" || got[1] != "heck" {
+		t.Errorf("unexpected blocked words: %q", got)
+	}
+}
+
+func TestLoad_AdminTokens(t *testing.T) {
+	cfg, _, err := Load(nil, env(map[string]string{"ADMIN_TOKENS": "alice=aaaaaaaaaaaaaaaa, bob=bbbbbbbbbbbbbbbb"}))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Admin.Tokens["alice"] != "aaaaaaaaaaaaaaaa" || cfg.Admin.Tokens["bob"] != "bbbbbbbbbbbbbbbb" {
+		t.Errorf("unexpected tokens: %v", cfg.Admin.Tokens)
+	}
+	var out bytes.Buffer
+	cfg.Print(&out)
+	if strings.Contains(out.String(), "aaaaaaaaaaaaaaaa") {
+		t.Errorf("expected tokens to be redacted:\n%s", out.String())
+	}
+
+	for _, raw := range []string{"alice", "alice=short", "alice=aaaaaaaaaaaaaaaa,bob=aaaaaaaaaaaaaaaa"} {
+		if _, _, err := Load(nil, env(map[string]string{"ADMIN_TOKENS": raw})); err == nil {
+			t.Errorf("%q: expected an error", raw)
+		}
+	}
+}
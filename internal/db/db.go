@@ -2,40 +2,40 @@ package db
 
 import (
 	"database/sql"
-	"fmt"
-	"log"
+	"leaderboard-service/internal/config"
+	"log/slog"
 	"os"
 	"time"
 
 	_ "github.com/lib/pq"
 )
 
-func Open() *sql.DB {
-	dbHost := os.Getenv("DB_HOST")
-	dbPort := os.Getenv("DB_PORT")
-	dbUser := os.Getenv("DB_USER")
-	dbPassword := os.Getenv("DB_PASSWORD")
-	dbName := os.Getenv("DB_NAME")
-
-	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable", dbHost, dbPort, dbUser, dbPassword, dbName)
+// Open connects to Postgres, retrying while the database starts up, and
+// applies the pool settings from cfg.
+func Open(cfg config.DBConfig) *sql.DB {
+	dsn := cfg.DSN()
 
 	var db *sql.DB
 	var err error
 	for i := 0; i < 10; i++ {
 		db, err = sql.Open("postgres", dsn)
 		if err == nil && db.Ping() == nil {
-			log.Println("Connected to Postgres database!")
+			slog.Info("connected to Postgres database", "host", cfg.Host, "name", cfg.Name)
+			db.SetMaxOpenConns(cfg.MaxOpenConns)
+			db.SetMaxIdleConns(cfg.MaxIdleConns)
+			db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
 			return db
 		}
-		log.Printf("Waiting for database to be ready... (%d/10)", i+1)
+		slog.Info("waiting for database to be ready", "attempt", i+1, "max_attempts", 10)
 		time.Sleep(2 * time.Second)
 	}
-	log.Fatalf("failed to connect to db after retries: %v", err)
+	slog.Error("failed to connect to db after retries", "error", err)
+	os.Exit(1)
 	return nil
 }
 
 func Close(db *sql.DB) {
 	if err := db.Close(); err != nil {
-		log.Printf("failed to close db: %v", err)
+		slog.Error("failed to close db", "error", err)
 	}
 }
@@ -0,0 +1,51 @@
+package grpcapi
+
+import (
+	"context"
+	"leaderboard-service/internal/logging"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// requestIDKey is the metadata key for the request ID, the gRPC counterpart of
+// the X-Request-ID HTTP header.
+var requestIDKey = strings.ToLower(logging.RequestIDHeader)
+
+// unaryRequestID assigns each call a request ID, taken from the incoming
+// metadata when present, and returns it in the response header.
+func unaryRequestID(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	id := incomingRequestID(ctx)
+	grpc.SetHeader(ctx, metadata.Pairs(requestIDKey, id))
+	ctx = logging.With(logging.WithRequestID(ctx, id), "rpc", info.FullMethod)
+	return handler(ctx, req)
+}
+
+// streamRequestID is the streaming counterpart of unaryRequestID.
+func streamRequestID(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	id := incomingRequestID(ss.Context())
+	ss.SetHeader(metadata.Pairs(requestIDKey, id))
+	ctx := logging.With(logging.WithRequestID(ss.Context(), id), "rpc", info.FullMethod)
+	return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+}
+
+func incomingRequestID(ctx context.Context) string {
+	var id string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(requestIDKey); len(values) > 0 {
+			id = values[0]
+		}
+	}
+	return logging.NewRequestID(id)
+}
+
+// contextStream overrides the context of a server stream.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,265 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"io"
+	"leaderboard-service/internal/grpcapi/leaderboardv1"
+	"leaderboard-service/internal/logging"
+	"leaderboard-service/internal/model"
+	"leaderboard-service/internal/service"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Server adapts service.ServiceInterface to the generated gRPC
+// LeaderboardService. It holds no business logic of its own.
+type Server struct {
+	leaderboardv1.UnimplementedLeaderboardServiceServer
+	service service.ServiceInterface
+}
+
+func NewServer(svc service.ServiceInterface) *Server {
+	return &Server{service: svc}
+}
+
+// NewGRPCServer returns a grpc.Server with the leaderboard service registered.
+// Every call is assigned a request ID for log correlation.
+func NewGRPCServer(svc service.ServiceInterface, opts ...grpc.ServerOption) *grpc.Server {
+	opts = append([]grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(unaryRequestID),
+		grpc.ChainStreamInterceptor(streamRequestID),
+	}, opts...)
+	s := grpc.NewServer(opts...)
+	leaderboardv1.RegisterLeaderboardServiceServer(s, NewServer(svc))
+	return s
+}
+
+func (s *Server) CreatePlayer(ctx context.Context, req *leaderboardv1.CreatePlayerRequest) (*leaderboardv1.Player, error) {
+	level, countryCode := int(req.GetLevel()), req.GetCountryCode()
+	profile := service.PlayerProfile{Level: &level, CountryCode: &countryCode}
+	player, err := s.service.CreatePlayer(ctx, req.GetPlayerId(), profile)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toPlayer(player), nil
+}
+
+func (s *Server) GetPlayer(ctx context.Context, req *leaderboardv1.GetPlayerRequest) (*leaderboardv1.Player, error) {
+	player, err := s.service.GetPlayer(ctx, req.GetPlayerId())
+	if err != nil {
+		// Mirrors the REST handler, which reports any lookup failure as 404.
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	return toPlayer(player), nil
+}
+
+func (s *Server) UpdatePlayer(ctx context.Context, req *leaderboardv1.UpdatePlayerRequest) (*leaderboardv1.Player, error) {
+	// Proto3 cannot tell an omitted level or country from a zero one, so
+	// an update always sets both.
+	level, countryCode := int(req.GetLevel()), req.GetCountryCode()
+	profile := service.PlayerProfile{Level: &level, CountryCode: &countryCode}
+	player, err := s.service.UpdatePlayer(ctx, req.GetPlayerId(), profile)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toPlayer(player), nil
+}
+
+// toPlayer converts a stored player, whose country code is normalized, for
+// the wire.
+func toPlayer(player *model.Player) *leaderboardv1.Player {
+	return &leaderboardv1.Player{PlayerId: player.PlayerID, Level: int32(player.Level), CountryCode: player.CountryCode}
+}
+
+func (s *Server) JoinQueue(ctx context.Context, req *leaderboardv1.JoinQueueRequest) (*leaderboardv1.JoinQueueResponse, error) {
+	leaderboardID, err := s.service.Join(ctx, req.GetPlayerId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &leaderboardv1.JoinQueueResponse{LeaderboardId: leaderboardID}, nil
+}
+
+func (s *Server) LeaveQueue(ctx context.Context, req *leaderboardv1.LeaveQueueRequest) (*leaderboardv1.LeaveQueueResponse, error) {
+	if err := s.service.Leave(ctx, req.GetPlayerId()); err != nil {
+		return nil, toStatus(err)
+	}
+	return &leaderboardv1.LeaveQueueResponse{}, nil
+}
+
+func (s *Server) SubmitScore(ctx context.Context, req *leaderboardv1.SubmitScoreRequest) (*leaderboardv1.SubmitScoreResponse, error) {
+	if err := s.service.SubmitScore(ctx, req.GetPlayerId(), int(req.GetScore())); err != nil {
+		return nil, toStatus(err)
+	}
+	return &leaderboardv1.SubmitScoreResponse{}, nil
+}
+
+func (s *Server) SubmitScores(stream leaderboardv1.LeaderboardService_SubmitScoresServer) error {
+	ctx := stream.Context()
+	resp := &leaderboardv1.SubmitScoresResponse{}
+	for index := int32(0); ; index++ {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			logging.FromContext(ctx).Info("SubmitScores batch done", "component", "grpc", "accepted", resp.Accepted, "rejected", resp.Rejected)
+			return stream.SendAndClose(resp)
+		}
+		if err != nil {
+			return err
+		}
+		if err := s.service.SubmitScore(ctx, req.GetPlayerId(), int(req.GetScore())); err != nil {
+			resp.Rejected++
+			resp.Errors = append(resp.Errors, &leaderboardv1.SubmitScoreError{
+				Index:    index,
+				PlayerId: req.GetPlayerId(),
+				Error:    err.Error(),
+			})
+			continue
+		}
+		resp.Accepted++
+	}
+}
+
+func (s *Server) GetLeaderboard(ctx context.Context, req *leaderboardv1.GetLeaderboardRequest) (*leaderboardv1.Leaderboard, error) {
+	resp, err := s.service.GetLeaderboard(ctx, req.GetLeaderboardId())
+	if err != nil {
+		// Mirrors the REST handler, which reports any lookup failure as 404.
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	return toLeaderboard(resp), nil
+}
+
+func (s *Server) GetPlayerLeaderboard(ctx context.Context, req *leaderboardv1.GetPlayerLeaderboardRequest) (*leaderboardv1.Leaderboard, error) {
+	resp, err := s.service.GetPlayerLeaderboard(ctx, req.GetPlayerId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toLeaderboard(resp), nil
+}
+
+func (s *Server) WatchLeaderboard(req *leaderboardv1.WatchLeaderboardRequest, stream leaderboardv1.LeaderboardService_WatchLeaderboardServer) error {
+	ctx := stream.Context()
+	sub, err := s.service.SubscribeLeaderboard(ctx, req.GetLeaderboardId(), req.GetLastEventId())
+	if err != nil {
+		return toStatus(err)
+	}
+	defer sub.Close()
+
+	if !sub.Resumed {
+		if snapshot, err := s.service.GetLeaderboard(ctx, req.GetLeaderboardId()); err == nil {
+			if err := stream.Send(toEvent(service.Event{Type: service.EventSnapshot, Data: snapshot})); err != nil {
+				return err
+			}
+		}
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case ev, ok := <-sub.C:
+			if !ok {
+				return nil
+			}
+			if err := stream.Send(toEvent(ev)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// toStatus maps the service's error messages onto gRPC status codes, matching
+// the HTTP status codes used by the REST handlers.
+func toStatus(err error) error {
+	switch err.Error() {
+	case "player not found", "leaderboard not found":
+		return status.Error(codes.NotFound, err.Error())
+	case "player already in active competition", "player already in waiting queue", "player in a party":
+		return status.Error(codes.AlreadyExists, err.Error())
+	case "player not in active competition", "player not in waiting queue":
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case "player banned", "player suspended":
+		return status.Error(codes.PermissionDenied, err.Error())
+	}
+	if errors.Is(err, service.ErrInvalidArgument) {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	if errors.Is(err, service.ErrNotFound) {
+		return status.Error(codes.NotFound, err.Error())
+	}
+	if errors.Is(err, service.ErrConflict) {
+		return status.Error(codes.AlreadyExists, err.Error())
+	}
+	if errors.Is(err, service.ErrForbidden) {
+		return status.Error(codes.PermissionDenied, err.Error())
+	}
+	if errors.Is(err, service.ErrPreconditionFailed) {
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+	if errors.Is(err, context.Canceled) {
+		return status.Error(codes.Canceled, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+func toEvent(ev service.Event) *leaderboardv1.LeaderboardEvent {
+	out := &leaderboardv1.LeaderboardEvent{Id: ev.ID, Type: ev.Type}
+	data, _ := ev.Data.(map[string]interface{})
+	if ev.Type == service.EventScore {
+		out.Payload = &leaderboardv1.LeaderboardEvent_Score{Score: &leaderboardv1.ScoreUpdate{
+			PlayerId:     stringValue(data["player_id"]),
+			Score:        intValue(data["score"]),
+			Delta:        intValue(data["delta"]),
+			Rank:         intValue(data["rank"]),
+			PreviousRank: intValue(data["previous_rank"]),
+		}}
+		return out
+	}
+	out.Payload = &leaderboardv1.LeaderboardEvent_Leaderboard{Leaderboard: toLeaderboard(ev.Data)}
+	return out
+}
+
+// toLeaderboard converts the map responses returned by the service layer.
+func toLeaderboard(v interface{}) *leaderboardv1.Leaderboard {
+	out := &leaderboardv1.Leaderboard{}
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return out
+	}
+	out.LeaderboardId = stringValue(m["leaderboard_id"])
+	if endsAt, ok := m["ends_at"].(int64); ok {
+		out.EndsAt = endsAt
+	}
+	entries, _ := m["leaderboard"].([]map[string]interface{})
+	for i, entry := range entries {
+		rank := intValue(entry["rank"])
+		if rank == 0 {
+			rank = int32(i + 1)
+		}
+		out.Entries = append(out.Entries, &leaderboardv1.LeaderboardEntry{
+			PlayerId: stringValue(entry["player_id"]),
+			Score:    intValue(entry["score"]),
+			Rank:     rank,
+		})
+	}
+	return out
+}
+
+func stringValue(v interface{}) string {
+	s, _ := v.(string)
+	return s
+}
+
+func intValue(v interface{}) int32 {
+	switch n := v.(type) {
+	case int:
+		return int32(n)
+	case int32:
+		return n
+	case int64:
+		return int32(n)
+	case float64:
+		return int32(n)
+	}
+	return 0
+}
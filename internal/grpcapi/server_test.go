@@ -0,0 +1,275 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"leaderboard-service/internal/grpcapi/leaderboardv1"
+	"leaderboard-service/internal/logging"
+	"leaderboard-service/internal/model"
+	"leaderboard-service/internal/service"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type mockService struct {
+	service.ServiceInterface
+	JoinFunc                 func(ctx context.Context, playerID string) (string, error)
+	SubmitScoreFunc          func(ctx context.Context, playerID string, score int) error
+	GetLeaderboardFunc       func(ctx context.Context, leaderboardID string) (interface{}, error)
+	GetPlayerFunc            func(ctx context.Context, playerID string) (*model.Player, error)
+	CreatePlayerFunc         func(ctx context.Context, playerID string, profile service.PlayerProfile) (*model.Player, error)
+	UpdatePlayerFunc         func(ctx context.Context, playerID string, profile service.PlayerProfile) (*model.Player, error)
+	SubscribeLeaderboardFunc func(ctx context.Context, leaderboardID string, lastEventID uint64) (*service.Subscription, error)
+}
+
+func (m *mockService) Join(ctx context.Context, playerID string) (string, error) {
+	return m.JoinFunc(ctx, playerID)
+}
+func (m *mockService) SubmitScore(ctx context.Context, playerID string, score int) error {
+	return m.SubmitScoreFunc(ctx, playerID, score)
+}
+func (m *mockService) GetLeaderboard(ctx context.Context, leaderboardID string) (interface{}, error) {
+	return m.GetLeaderboardFunc(ctx, leaderboardID)
+}
+func (m *mockService) GetPlayer(ctx context.Context, playerID string) (*model.Player, error) {
+	return m.GetPlayerFunc(ctx, playerID)
+}
+func (m *mockService) CreatePlayer(ctx context.Context, playerID string, profile service.PlayerProfile) (*model.Player, error) {
+	return m.CreatePlayerFunc(ctx, playerID, profile)
+}
+func (m *mockService) UpdatePlayer(ctx context.Context, playerID string, profile service.PlayerProfile) (*model.Player, error) {
+	return m.UpdatePlayerFunc(ctx, playerID, profile)
+}
+func (m *mockService) SubscribeLeaderboard(ctx context.Context, leaderboardID string, lastEventID uint64) (*service.Subscription, error) {
+	return m.SubscribeLeaderboardFunc(ctx, leaderboardID, lastEventID)
+}
+
+// newTestClient serves svc over an in-process bufconn listener.
+func newTestClient(t *testing.T, svc service.ServiceInterface) leaderboardv1.LeaderboardServiceClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv := NewGRPCServer(svc)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("failed to dial bufconn: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return leaderboardv1.NewLeaderboardServiceClient(conn)
+}
+
+func TestGetPlayer_Success(t *testing.T) {
+	client := newTestClient(t, &mockService{
+		GetPlayerFunc: func(ctx context.Context, playerID string) (*model.Player, error) {
+			return &model.Player{PlayerID: playerID, Level: 3, CountryCode: "US"}, nil
+		},
+	})
+	p, err := client.GetPlayer(context.Background(), &leaderboardv1.GetPlayerRequest{PlayerId: "p1"})
+	if err != nil {
+		t.Fatalf("GetPlayer failed: %v", err)
+	}
+	if p.GetPlayerId() != "p1" || p.GetLevel() != 3 || p.GetCountryCode() != "US" {
+		t.Errorf("unexpected player: %+v", p)
+	}
+}
+
+func TestCreatePlayer_ReturnsStoredPlayer(t *testing.T) {
+	client := newTestClient(t, &mockService{
+		CreatePlayerFunc: func(ctx context.Context, playerID string, profile service.PlayerProfile) (*model.Player, error) {
+			return &model.Player{PlayerID: playerID, Level: *profile.Level, CountryCode: "US"}, nil
+		},
+	})
+	p, err := client.CreatePlayer(context.Background(), &leaderboardv1.CreatePlayerRequest{PlayerId: "p1", Level: 3, CountryCode: " us"})
+	if err != nil {
+		t.Fatalf("CreatePlayer failed: %v", err)
+	}
+	if p.GetPlayerId() != "p1" || p.GetLevel() != 3 || p.GetCountryCode() != "US" {
+		t.Errorf("expected the stored player, got %+v", p)
+	}
+}
+
+func TestUpdatePlayer_ErrorCodes(t *testing.T) {
+	cases := map[error]codes.Code{
+		fmt.Errorf("%w: player is at version 2", service.ErrPreconditionFailed):  codes.FailedPrecondition,
+		fmt.Errorf("%w: not allowed", service.ErrForbidden):                      codes.PermissionDenied,
+		fmt.Errorf("%w: level must not be negative", service.ErrInvalidArgument): codes.InvalidArgument,
+	}
+	for err, want := range cases {
+		client := newTestClient(t, &mockService{
+			UpdatePlayerFunc: func(ctx context.Context, playerID string, profile service.PlayerProfile) (*model.Player, error) {
+				return nil, err
+			},
+		})
+		_, got := client.UpdatePlayer(context.Background(), &leaderboardv1.UpdatePlayerRequest{PlayerId: "p1", Level: 1})
+		if status.Code(got) != want {
+			t.Errorf("%v: expected %v, got %v", err, want, got)
+		}
+	}
+}
+
+func TestGetPlayer_NotFound(t *testing.T) {
+	client := newTestClient(t, &mockService{
+		GetPlayerFunc: func(ctx context.Context, playerID string) (*model.Player, error) {
+			return nil, errors.New("sql: no rows in result set")
+		},
+	})
+	_, err := client.GetPlayer(context.Background(), &leaderboardv1.GetPlayerRequest{PlayerId: "p1"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound, got %v", err)
+	}
+}
+
+func TestJoinQueue_ErrorCodes(t *testing.T) {
+	cases := map[string]codes.Code{
+		"player not found":                     codes.NotFound,
+		"player already in active competition": codes.AlreadyExists,
+		"player already in waiting queue":      codes.AlreadyExists,
+		"player in a party":                    codes.AlreadyExists,
+		"db error":                             codes.Internal,
+	}
+	for msg, want := range cases {
+		client := newTestClient(t, &mockService{
+			JoinFunc: func(ctx context.Context, playerID string) (string, error) {
+				return "", errors.New(msg)
+			},
+		})
+		_, err := client.JoinQueue(context.Background(), &leaderboardv1.JoinQueueRequest{PlayerId: "p1"})
+		if status.Code(err) != want {
+			t.Errorf("%q: expected %v, got %v", msg, want, err)
+		}
+	}
+}
+
+func TestSubmitScores_Batch(t *testing.T) {
+	client := newTestClient(t, &mockService{
+		SubmitScoreFunc: func(ctx context.Context, playerID string, score int) error {
+			if playerID == "bad" {
+				return errors.New("player not in active competition")
+			}
+			return nil
+		},
+	})
+	stream, err := client.SubmitScores(context.Background())
+	if err != nil {
+		t.Fatalf("SubmitScores failed: %v", err)
+	}
+	for _, id := range []string{"p1", "bad", "p2"} {
+		if err := stream.Send(&leaderboardv1.SubmitScoreRequest{PlayerId: id, Score: 5}); err != nil {
+			t.Fatalf("Send failed: %v", err)
+		}
+	}
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		t.Fatalf("CloseAndRecv failed: %v", err)
+	}
+	if resp.GetAccepted() != 2 || resp.GetRejected() != 1 {
+		t.Errorf("unexpected counts: %+v", resp)
+	}
+	if len(resp.GetErrors()) != 1 || resp.GetErrors()[0].GetIndex() != 1 || resp.GetErrors()[0].GetPlayerId() != "bad" {
+		t.Errorf("unexpected errors: %+v", resp.GetErrors())
+	}
+}
+
+func TestGetLeaderboard_ConvertsEntries(t *testing.T) {
+	client := newTestClient(t, &mockService{
+		GetLeaderboardFunc: func(ctx context.Context, leaderboardID string) (interface{}, error) {
+			return map[string]interface{}{
+				"leaderboard_id": leaderboardID,
+				"leaderboard": []map[string]interface{}{
+					{"player_id": "p1", "score": 20},
+					{"player_id": "p2", "score": 10},
+				},
+			}, nil
+		},
+	})
+	lb, err := client.GetLeaderboard(context.Background(), &leaderboardv1.GetLeaderboardRequest{LeaderboardId: "lid"})
+	if err != nil {
+		t.Fatalf("GetLeaderboard failed: %v", err)
+	}
+	if lb.GetLeaderboardId() != "lid" || len(lb.GetEntries()) != 2 {
+		t.Fatalf("unexpected leaderboard: %+v", lb)
+	}
+	if e := lb.GetEntries()[1]; e.GetPlayerId() != "p2" || e.GetScore() != 10 || e.GetRank() != 2 {
+		t.Errorf("unexpected entry: %+v", e)
+	}
+}
+
+func TestWatchLeaderboard_StreamsUntilCompleted(t *testing.T) {
+	hub := service.NewHub()
+	subscribed := make(chan struct{})
+	client := newTestClient(t, &mockService{
+		SubscribeLeaderboardFunc: func(ctx context.Context, leaderboardID string, lastEventID uint64) (*service.Subscription, error) {
+			defer close(subscribed)
+			return hub.Subscribe(leaderboardID, lastEventID), nil
+		},
+		GetLeaderboardFunc: func(ctx context.Context, leaderboardID string) (interface{}, error) {
+			return map[string]interface{}{"leaderboard_id": leaderboardID}, nil
+		},
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	stream, err := client.WatchLeaderboard(ctx, &leaderboardv1.WatchLeaderboardRequest{LeaderboardId: "lid"})
+	if err != nil {
+		t.Fatalf("WatchLeaderboard failed: %v", err)
+	}
+	ev, err := stream.Recv()
+	if err != nil || ev.GetType() != service.EventSnapshot {
+		t.Fatalf("expected snapshot, got %+v (%v)", ev, err)
+	}
+	<-subscribed
+
+	hub.Publish("lid", service.EventScore, map[string]interface{}{"player_id": "p1", "score": 7, "delta": 7, "rank": 1, "previous_rank": 2})
+	hub.Publish("lid", service.EventCompleted, map[string]interface{}{"leaderboard_id": "lid"})
+	hub.CloseTopic("lid")
+
+	ev, err = stream.Recv()
+	if err != nil || ev.GetId() != 1 || ev.GetScore().GetScore() != 7 || ev.GetScore().GetPreviousRank() != 2 {
+		t.Errorf("unexpected score event: %+v (%v)", ev, err)
+	}
+	ev, err = stream.Recv()
+	if err != nil || ev.GetType() != service.EventCompleted {
+		t.Errorf("unexpected completed event: %+v (%v)", ev, err)
+	}
+	if _, err := stream.Recv(); err != io.EOF {
+		t.Errorf("expected end of stream, got %v", err)
+	}
+}
+
+func TestRequestID_PropagatedToService(t *testing.T) {
+	var seen string
+	client := newTestClient(t, &mockService{
+		GetPlayerFunc: func(ctx context.Context, playerID string) (*model.Player, error) {
+			seen = logging.RequestID(ctx)
+			return &model.Player{PlayerID: playerID}, nil
+		},
+	})
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-request-id", "req-42")
+	var header metadata.MD
+	if _, err := client.GetPlayer(ctx, &leaderboardv1.GetPlayerRequest{PlayerId: "p1"}, grpc.Header(&header)); err != nil {
+		t.Fatalf("GetPlayer failed: %v", err)
+	}
+	if seen != "req-42" {
+		t.Errorf("expected request ID req-42 in service context, got %q", seen)
+	}
+	if got := header.Get("x-request-id"); len(got) != 1 || got[0] != "req-42" {
+		t.Errorf("expected request ID echoed in header, got %v", got)
+	}
+}
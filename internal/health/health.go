@@ -0,0 +1,177 @@
+// Package health serves the liveness (/healthz), readiness (/readyz) and
+// status (/status) endpoints used by orchestrators and operators.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"leaderboard-service/internal/logging"
+	"net/http"
+	"runtime/debug"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// Version is the release version, set at build time with
+// -ldflags "-X leaderboard-service/internal/health.Version=...".
+var Version = "dev"
+
+// checkTimeout bounds each dependency check.
+const checkTimeout = 2 * time.Second
+
+// Pinger is satisfied by *sql.DB.
+type Pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// SchemaChecker reports whether the database schema is the one this build
+// expects.
+type SchemaChecker interface {
+	CheckSchema(ctx context.Context) error
+}
+
+// QueueCounter reports the number of waiting players per level.
+type QueueCounter interface {
+	CountWaitingPlayersByLevel(ctx context.Context) (map[int]int, error)
+}
+
+type Checker struct {
+	db      Pinger
+	schema  SchemaChecker
+	worker  *WorkerMonitor
+	queue   QueueCounter
+	started time.Time
+
+	shuttingDown atomic.Bool
+}
+
+func NewChecker(db Pinger, schema SchemaChecker, worker *WorkerMonitor, queue QueueCounter) *Checker {
+	return &Checker{db: db, schema: schema, worker: worker, queue: queue, started: time.Now()}
+}
+
+// SetShuttingDown makes readiness fail from now on so load balancers stop
+// routing new traffic while in-flight requests drain.
+func (c *Checker) SetShuttingDown() {
+	c.shuttingDown.Store(true)
+}
+
+// Register adds the endpoints to mux.
+func (c *Checker) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET /healthz", c.HealthzHandler)
+	mux.HandleFunc("GET /readyz", c.ReadyzHandler)
+	mux.HandleFunc("GET /status", c.StatusHandler)
+}
+
+// HealthzHandler reports that the process is alive and serving.
+func (c *Checker) HealthzHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// ReadyzHandler reports whether this instance should receive traffic: the
+// database is reachable, its schema is current, the matchmaking worker is
+// ticking and the process is not shutting down.
+func (c *Checker) ReadyzHandler(w http.ResponseWriter, r *http.Request) {
+	checks := c.Check(r.Context())
+	status, code := "ready", http.StatusOK
+	for _, result := range checks {
+		if result != "ok" {
+			status, code = "not ready", http.StatusServiceUnavailable
+			break
+		}
+	}
+	if code != http.StatusOK {
+		logging.FromContext(r.Context()).Warn("readiness check failed", "component", "health", "checks", checks)
+	}
+	writeJSON(w, code, map[string]interface{}{"status": status, "checks": checks})
+}
+
+// Check runs every readiness check and returns "ok" or a failure reason for
+// each.
+func (c *Checker) Check(ctx context.Context) map[string]string {
+	checks := map[string]string{"shutdown": "ok", "database": "ok", "schema": "ok", "worker": "ok"}
+	if c.shuttingDown.Load() {
+		checks["shutdown"] = "shutting down"
+	}
+
+	pingCtx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+	if err := c.db.PingContext(pingCtx); err != nil {
+		checks["database"] = err.Error()
+		checks["schema"] = "skipped: database unreachable"
+	} else if err := c.schema.CheckSchema(pingCtx); err != nil {
+		checks["schema"] = err.Error()
+	}
+
+	if c.worker.Stalled(time.Now()) {
+		checks["worker"] = "stalled: no matchmaking tick completed recently"
+	}
+	return checks
+}
+
+type buildInfo struct {
+	Version   string `json:"version"`
+	Revision  string `json:"revision,omitempty"`
+	BuildTime string `json:"build_time,omitempty"`
+	Modified  bool   `json:"modified,omitempty"`
+	GoVersion string `json:"go_version"`
+}
+
+type queueStatus struct {
+	Depth   int            `json:"depth"`
+	ByLevel map[string]int `json:"by_level"`
+	Error   string         `json:"error,omitempty"`
+}
+
+// StatusHandler reports build information, uptime, the worker's last tick
+// and the matchmaking queue depth.
+func (c *Checker) StatusHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), checkTimeout)
+	defer cancel()
+
+	queue := queueStatus{ByLevel: map[string]int{}}
+	if counts, err := c.queue.CountWaitingPlayersByLevel(ctx); err != nil {
+		queue.Error = err.Error()
+	} else {
+		for level, n := range counts {
+			queue.ByLevel[strconv.Itoa(level)] = n
+			queue.Depth += n
+		}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"build":          readBuildInfo(),
+		"started_at":     c.started.UTC(),
+		"uptime_seconds": int64(time.Since(c.started).Seconds()),
+		"shutting_down":  c.shuttingDown.Load(),
+		"worker":         c.worker.Status(),
+		"queue":          queue,
+	})
+}
+
+func readBuildInfo() buildInfo {
+	info := buildInfo{Version: Version}
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	info.GoVersion = bi.GoVersion
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			info.Revision = s.Value
+		case "vcs.time":
+			info.BuildTime = s.Value
+		case "vcs.modified":
+			info.Modified = s.Value == "true"
+		}
+	}
+	return info
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
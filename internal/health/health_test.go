@@ -0,0 +1,127 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type fakeDB struct{ err error }
+
+func (f fakeDB) PingContext(ctx context.Context) error { return f.err }
+
+type fakeSchema struct{ err error }
+
+func (f fakeSchema) CheckSchema(ctx context.Context) error { return f.err }
+
+type fakeQueue struct{ counts map[int]int }
+
+func (f fakeQueue) CountWaitingPlayersByLevel(ctx context.Context) (map[int]int, error) {
+	return f.counts, nil
+}
+
+func every(d time.Duration) func() time.Duration {
+	return func() time.Duration { return d }
+}
+
+// tickedMonitor returns a monitor that has just completed a tick.
+func tickedMonitor(err error) *WorkerMonitor {
+	m := NewWorkerMonitor(every(time.Minute))
+	m.TickMiddleware()(func(ctx context.Context) error { return err })(context.Background())
+	return m
+}
+
+func serve(handler http.HandlerFunc) (*httptest.ResponseRecorder, map[string]interface{}) {
+	rr := httptest.NewRecorder()
+	handler(rr, httptest.NewRequest("GET", "/", nil))
+	var body map[string]interface{}
+	json.Unmarshal(rr.Body.Bytes(), &body)
+	return rr, body
+}
+
+func TestHealthz(t *testing.T) {
+	c := NewChecker(fakeDB{err: errors.New("down")}, fakeSchema{}, tickedMonitor(nil), fakeQueue{})
+	if rr, _ := serve(c.HealthzHandler); rr.Code != http.StatusOK {
+		t.Errorf("expected liveness to ignore dependencies, got %d", rr.Code)
+	}
+}
+
+func TestReadyz_Ready(t *testing.T) {
+	c := NewChecker(fakeDB{}, fakeSchema{}, tickedMonitor(nil), fakeQueue{})
+	rr, body := serve(c.ReadyzHandler)
+	if rr.Code != http.StatusOK || body["status"] != "ready" {
+		t.Errorf("expected ready, got %d %v", rr.Code, body)
+	}
+}
+
+func TestReadyz_FailingChecks(t *testing.T) {
+	cases := map[string]*Checker{
+		"database": NewChecker(fakeDB{err: errors.New("connection refused")}, fakeSchema{}, tickedMonitor(nil), fakeQueue{}),
+		"schema":   NewChecker(fakeDB{}, fakeSchema{err: errors.New("table players is missing")}, tickedMonitor(nil), fakeQueue{}),
+		"worker":   NewChecker(fakeDB{}, fakeSchema{}, NewWorkerMonitor(every(-time.Second)), fakeQueue{}),
+	}
+	for check, c := range cases {
+		rr, body := serve(c.ReadyzHandler)
+		if rr.Code != http.StatusServiceUnavailable {
+			t.Errorf("%s: expected 503, got %d", check, rr.Code)
+		}
+		checks, _ := body["checks"].(map[string]interface{})
+		if checks[check] == "ok" {
+			t.Errorf("%s: expected failing %s check, got %v", check, check, checks)
+		}
+	}
+}
+
+func TestReadyz_FailsDuringShutdown(t *testing.T) {
+	c := NewChecker(fakeDB{}, fakeSchema{}, tickedMonitor(nil), fakeQueue{})
+	c.SetShuttingDown()
+	rr, body := serve(c.ReadyzHandler)
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503 while shutting down, got %d", rr.Code)
+	}
+	if checks, _ := body["checks"].(map[string]interface{}); checks["shutdown"] == "ok" {
+		t.Errorf("expected shutdown check to fail, got %v", checks)
+	}
+}
+
+func TestWorkerMonitor_Stalled(t *testing.T) {
+	m := NewWorkerMonitor(every(time.Second))
+	now := time.Now()
+	if m.Stalled(now) {
+		t.Error("expected a fresh worker not to be stalled")
+	}
+	if !m.Stalled(now.Add(stallFactor*time.Second + time.Millisecond)) {
+		t.Error("expected worker without ticks to be stalled after the grace period")
+	}
+	m.TickMiddleware()(func(ctx context.Context) error { return nil })(context.Background())
+	if m.Stalled(time.Now()) {
+		t.Error("expected worker not to be stalled right after a tick")
+	}
+}
+
+func TestStatus_ReportsWorkerAndQueue(t *testing.T) {
+	c := NewChecker(fakeDB{}, fakeSchema{}, tickedMonitor(errors.New("db error")), fakeQueue{counts: map[int]int{1: 2, 3: 1}})
+	rr, body := serve(c.StatusHandler)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+	worker, _ := body["worker"].(map[string]interface{})
+	if worker["last_result"] != "error" || worker["last_error"] != "db error" || worker["last_tick_at"] == nil {
+		t.Errorf("unexpected worker status: %v", worker)
+	}
+	queue, _ := body["queue"].(map[string]interface{})
+	if queue["depth"] != float64(3) {
+		t.Errorf("expected queue depth 3, got %v", queue)
+	}
+	build, _ := body["build"].(map[string]interface{})
+	if build["version"] != Version {
+		t.Errorf("unexpected build info: %v", build)
+	}
+	if _, ok := body["uptime_seconds"]; !ok {
+		t.Error("expected uptime_seconds")
+	}
+}
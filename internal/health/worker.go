@@ -0,0 +1,86 @@
+package health
+
+import (
+	"context"
+	"leaderboard-service/internal/service"
+	"sync"
+	"time"
+)
+
+// stallFactor is how many matchmaking intervals may pass without a completed
+// tick before the worker is considered stalled.
+const stallFactor = 3
+
+// WorkerMonitor records the outcome of every matchmaking tick.
+type WorkerMonitor struct {
+	interval func() time.Duration
+	started  time.Time
+
+	mu           sync.Mutex
+	ticks        uint64
+	lastTickAt   time.Time
+	lastDuration time.Duration
+	lastErr      error
+}
+
+// WorkerStatus is a snapshot of the worker's progress.
+type WorkerStatus struct {
+	Ticks          uint64     `json:"ticks"`
+	LastTickAt     *time.Time `json:"last_tick_at,omitempty"`
+	LastDurationMS int64      `json:"last_tick_duration_ms"`
+	LastResult     string     `json:"last_result,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+}
+
+// NewWorkerMonitor returns a monitor for a worker that ticks at the interval
+// returned by interval, which may change at runtime.
+func NewWorkerMonitor(interval func() time.Duration) *WorkerMonitor {
+	return &WorkerMonitor{interval: interval, started: time.Now()}
+}
+
+// TickMiddleware records each tick's completion time and result.
+func (m *WorkerMonitor) TickMiddleware() service.TickMiddleware {
+	return func(next service.TickFunc) service.TickFunc {
+		return func(ctx context.Context) error {
+			start := time.Now()
+			err := next(ctx)
+			m.mu.Lock()
+			m.ticks++
+			m.lastTickAt = time.Now()
+			m.lastDuration = m.lastTickAt.Sub(start)
+			m.lastErr = err
+			m.mu.Unlock()
+			return err
+		}
+	}
+}
+
+// Status returns the latest tick information.
+func (m *WorkerMonitor) Status() WorkerStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	st := WorkerStatus{Ticks: m.ticks, LastDurationMS: m.lastDuration.Milliseconds()}
+	if m.ticks == 0 {
+		return st
+	}
+	last := m.lastTickAt
+	st.LastTickAt = &last
+	st.LastResult = "ok"
+	if m.lastErr != nil {
+		st.LastResult = "error"
+		st.LastError = m.lastErr.Error()
+	}
+	return st
+}
+
+// Stalled reports whether no tick has completed within stallFactor intervals,
+// counting from startup until the first tick.
+func (m *WorkerMonitor) Stalled(now time.Time) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	since := m.started
+	if m.ticks > 0 {
+		since = m.lastTickAt
+	}
+	return now.Sub(since) > stallFactor*m.interval()
+}
@@ -0,0 +1,25 @@
+package iso3166
+
+import "testing"
+
+func TestValid(t *testing.T) {
+	for _, code := range []string{"DE", "US", "JP", "AX", "ZW"} {
+		if !Valid(code) {
+			t.Errorf("expected %s to be valid", code)
+		}
+	}
+	for _, code := range []string{"", "de", "ZZ", "UK", "EU", "USA", "D"} {
+		if Valid(code) {
+			t.Errorf("expected %q to be invalid", code)
+		}
+	}
+	if len(alpha2) != 249 {
+		t.Errorf("expected 249 codes, got %d", len(alpha2))
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	if got := Normalize(" de "); got != "DE" || !Valid(got) {
+		t.Errorf("expected DE, got %q", got)
+	}
+}
@@ -0,0 +1,90 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSetup_JSONWithLevel(t *testing.T) {
+	defer slog.SetDefault(slog.Default())
+	var buf bytes.Buffer
+	logger, err := Setup(&buf, "json", "warn")
+	if err != nil {
+		t.Fatalf("Setup failed: %v", err)
+	}
+	logger.Info("dropped")
+	logger.Warn("kept", "player_id", "p1")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("expected 1 line at warn level, got %d: %q", len(lines), buf.String())
+	}
+	var entry map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatalf("output is not JSON: %v", err)
+	}
+	if entry["msg"] != "kept" || entry["player_id"] != "p1" {
+		t.Errorf("unexpected entry: %v", entry)
+	}
+}
+
+func TestSetup_RejectsUnknownValues(t *testing.T) {
+	if _, err := Setup(&bytes.Buffer{}, "xml", "info"); err == nil {
+		t.Error("expected error for unknown format")
+	}
+	if _, err := Setup(&bytes.Buffer{}, "json", "loud"); err == nil {
+		t.Error("expected error for unknown level")
+	}
+}
+
+func TestWith_AddsAttributesToContextLogger(t *testing.T) {
+	var buf bytes.Buffer
+	ctx := NewContext(context.Background(), slog.New(slog.NewTextHandler(&buf, nil)))
+	ctx = With(ctx, "tick_id", 7)
+	FromContext(ctx).Info("tick")
+	if !strings.Contains(buf.String(), "tick_id=7") {
+		t.Errorf("expected tick_id attribute, got %q", buf.String())
+	}
+}
+
+func TestMiddleware_PropagatesRequestID(t *testing.T) {
+	var buf bytes.Buffer
+	var seen string
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = RequestID(r.Context())
+		FromContext(r.Context()).Info("handled")
+	}))
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, nil)))
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set(RequestIDHeader, "abc-123")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if seen != "abc-123" || rr.Header().Get(RequestIDHeader) != "abc-123" {
+		t.Errorf("expected client request ID to be used, got ctx=%q header=%q", seen, rr.Header().Get(RequestIDHeader))
+	}
+	if !strings.Contains(buf.String(), "request_id=abc-123") {
+		t.Errorf("expected request_id in log line, got %q", buf.String())
+	}
+}
+
+func TestMiddleware_GeneratesRequestID(t *testing.T) {
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set(RequestIDHeader, strings.Repeat("x", maxRequestIDLength+1))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	id := rr.Header().Get(RequestIDHeader)
+	if id == "" || len(id) > maxRequestIDLength {
+		t.Errorf("expected a generated request ID, got %q", id)
+	}
+}
@@ -0,0 +1,50 @@
+package logging
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader carries the request ID on HTTP requests and responses. The
+// gRPC server reads the lower-case form from incoming metadata.
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds client-supplied IDs before they reach the logs.
+const maxRequestIDLength = 128
+
+type requestIDKey struct{}
+
+// WithRequestID returns a copy of ctx carrying id, with a logger tagged with
+// request_id.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	ctx = context.WithValue(ctx, requestIDKey{}, id)
+	return With(ctx, "request_id", id)
+}
+
+// RequestID returns the request ID stored in ctx, if any.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// NewRequestID returns id if it is usable as a request ID, or a fresh one.
+func NewRequestID(id string) string {
+	if id == "" || len(id) > maxRequestIDLength {
+		return uuid.NewString()
+	}
+	return id
+}
+
+// Middleware assigns every request an ID, taken from the X-Request-ID header
+// when the client sent one, echoes it in the response and stores it in the
+// request context along with a logger tagged with it.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := NewRequestID(r.Header.Get(RequestIDHeader))
+		w.Header().Set(RequestIDHeader, id)
+		ctx := WithRequestID(r.Context(), id)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -0,0 +1,204 @@
+// Package metrics exposes Prometheus instrumentation for the service. The
+// HTTP middleware, the ServiceInterface and RepositoryInterface decorators and
+// the matchmaking tick middleware all record into a single Metrics value, so
+// the business logic itself carries no instrumentation code.
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"leaderboard-service/internal/service"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "leaderboard"
+
+// collectTimeout bounds the queries run at scrape time.
+const collectTimeout = 2 * time.Second
+
+type Metrics struct {
+	httpRequests *prometheus.CounterVec
+	httpDuration *prometheus.HistogramVec
+
+	serviceCalls    *prometheus.CounterVec
+	serviceDuration *prometheus.HistogramVec
+	dbDuration      *prometheus.HistogramVec
+	dbErrors        *prometheus.CounterVec
+
+	scoreSubmissions *prometheus.CounterVec
+	scoreRejections  *prometheus.CounterVec
+
+	tickDuration   *prometheus.HistogramVec
+	groupsFormed   prometheus.Counter
+	playersMatched prometheus.Counter
+	timeInQueue    prometheus.Histogram
+}
+
+// New creates the service's collectors and registers them with reg.
+func New(reg prometheus.Registerer) *Metrics {
+	m := &Metrics{
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Subsystem: "http", Name: "requests_total",
+			Help: "HTTP requests by route template, method and status code.",
+		}, []string{"route", "method", "code"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace, Subsystem: "http", Name: "request_duration_seconds",
+			Help:    "HTTP request latency by route template and method.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"route", "method"}),
+
+		serviceCalls: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Subsystem: "service", Name: "calls_total",
+			Help: "Service calls by method and result.",
+		}, []string{"method", "result"}),
+		serviceDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace, Subsystem: "service", Name: "call_duration_seconds",
+			Help:    "Service call latency by method.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method"}),
+		dbDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace, Subsystem: "db", Name: "query_duration_seconds",
+			Help:    "Repository call latency by operation.",
+			Buckets: []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+		}, []string{"operation"}),
+		dbErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Subsystem: "db", Name: "errors_total",
+			Help: "Repository calls that returned an error, by operation.",
+		}, []string{"operation"}),
+
+		scoreSubmissions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Subsystem: "scores", Name: "submissions_total",
+			Help: "Score submissions by result (accepted or rejected).",
+		}, []string{"result"}),
+		scoreRejections: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Subsystem: "scores", Name: "rejections_total",
+			Help: "Rejected score submissions by reason.",
+		}, []string{"reason"}),
+
+		tickDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace, Subsystem: "matchmaking", Name: "tick_duration_seconds",
+			Help:    "Duration of matchmaking passes by result.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"result"}),
+		groupsFormed: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace, Subsystem: "matchmaking", Name: "groups_formed_total",
+			Help: "Groups of players matched into a competition.",
+		}),
+		playersMatched: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace, Subsystem: "matchmaking", Name: "players_matched_total",
+			Help: "Players moved from the waiting queue into a competition.",
+		}),
+		timeInQueue: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace, Subsystem: "matchmaking", Name: "time_in_queue_seconds",
+			Help:    "Time matched players spent in the waiting queue.",
+			Buckets: []float64{1, 5, 10, 15, 30, 60, 120, 300, 600, 1800},
+		}),
+	}
+	reg.MustRegister(
+		m.httpRequests, m.httpDuration,
+		m.serviceCalls, m.serviceDuration, m.dbDuration, m.dbErrors,
+		m.scoreSubmissions, m.scoreRejections,
+		m.tickDuration, m.groupsFormed, m.playersMatched, m.timeInQueue,
+	)
+	return m
+}
+
+// NewRegistry returns a registry with the Go runtime and process collectors
+// plus the connection pool statistics of db.
+func NewRegistry(db *sql.DB) *prometheus.Registry {
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		collectors.NewDBStatsCollector(db, namespace),
+	)
+	return reg
+}
+
+// Handler serves the metrics gathered by reg.
+func Handler(reg *prometheus.Registry) http.Handler {
+	return promhttp.HandlerFor(reg, promhttp.HandlerOpts{Registry: reg})
+}
+
+// TickMiddleware times each matchmaking pass.
+func (m *Metrics) TickMiddleware() service.TickMiddleware {
+	return func(next service.TickFunc) service.TickFunc {
+		return func(ctx context.Context) error {
+			start := time.Now()
+			err := next(ctx)
+			m.tickDuration.WithLabelValues(result(err)).Observe(time.Since(start).Seconds())
+			return err
+		}
+	}
+}
+
+// GaugeSource provides the values behind the gauges that are read from the
+// database at scrape time.
+type GaugeSource interface {
+	CountWaitingPlayersByLevel(ctx context.Context) (map[int]int, error)
+	CountActiveCompetitions(ctx context.Context) (int, error)
+}
+
+var (
+	queueDepthDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "matchmaking", "queue_depth"),
+		"Players waiting in the matchmaking queue by level bracket.",
+		[]string{"bracket"}, nil,
+	)
+	activeCompetitionsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "active_competitions"),
+		"Competitions currently in progress.",
+		nil, nil,
+	)
+)
+
+// gaugeCollector queries the database on every scrape, so the gauges reflect
+// changes made by any replica.
+type gaugeCollector struct {
+	source GaugeSource
+}
+
+// NewGaugeCollector returns a collector for the queue depth and active
+// competition gauges.
+func NewGaugeCollector(source GaugeSource) prometheus.Collector {
+	return &gaugeCollector{source: source}
+}
+
+func (c *gaugeCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- queueDepthDesc
+	ch <- activeCompetitionsDesc
+}
+
+func (c *gaugeCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), collectTimeout)
+	defer cancel()
+
+	if counts, err := c.source.CountWaitingPlayersByLevel(ctx); err != nil {
+		slog.Error("error collecting queue depth", "component", "metrics", "error", err)
+		ch <- prometheus.NewInvalidMetric(queueDepthDesc, err)
+	} else {
+		for level, count := range counts {
+			ch <- prometheus.MustNewConstMetric(queueDepthDesc, prometheus.GaugeValue, float64(count), strconv.Itoa(level))
+		}
+	}
+	if count, err := c.source.CountActiveCompetitions(ctx); err != nil {
+		slog.Error("error collecting active competitions", "component", "metrics", "error", err)
+		ch <- prometheus.NewInvalidMetric(activeCompetitionsDesc, err)
+	} else {
+		ch <- prometheus.MustNewConstMetric(activeCompetitionsDesc, prometheus.GaugeValue, float64(count))
+	}
+}
+
+func result(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "ok"
+}
@@ -0,0 +1,221 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"leaderboard-service/internal/model"
+	"leaderboard-service/internal/repository"
+	"leaderboard-service/internal/service"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+type mockService struct {
+	service.ServiceInterface
+	SubmitScoreFunc func(ctx context.Context, playerID string, score int) error
+}
+
+func (m *mockService) SubmitScore(ctx context.Context, playerID string, score int) error {
+	return m.SubmitScoreFunc(ctx, playerID, score)
+}
+
+type mockRepo struct {
+	repository.RepositoryInterface
+	waiting   []model.PlayerCompetition
+	levels    map[int]int
+	active    int
+	countErr  error
+	updateErr error
+}
+
+func (m *mockRepo) GetWaitingPlayers(ctx context.Context, limit int) ([]model.PlayerCompetition, error) {
+	return m.waiting, nil
+}
+func (m *mockRepo) UpdatePlayerCompetitionsToActive(ctx context.Context, playerIDs []string, competitionID uuid.UUID, endsAt time.Time) error {
+	return m.updateErr
+}
+func (m *mockRepo) CountWaitingPlayersByLevel(ctx context.Context) (map[int]int, error) {
+	return m.levels, m.countErr
+}
+func (m *mockRepo) CountActiveCompetitions(ctx context.Context) (int, error) {
+	return m.active, nil
+}
+
+func TestMiddleware_LabelsByRouteTemplate(t *testing.T) {
+	m := New(prometheus.NewRegistry())
+	r := mux.NewRouter()
+	r.Use(m.Middleware)
+	r.HandleFunc("/v1/player/{player_id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	r.HandleFunc("/v1/stream", func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := w.(http.Flusher); !ok {
+			t.Error("wrapped writer does not implement http.Flusher")
+		}
+	})
+
+	for _, path := range []string{"/v1/player/p1", "/v1/player/p2", "/v1/stream"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+
+	if got := testutil.ToFloat64(m.httpRequests.WithLabelValues("/v1/player/{player_id}", "GET", "404")); got != 2 {
+		t.Errorf("player requests = %v, want 2", got)
+	}
+	if got := testutil.ToFloat64(m.httpRequests.WithLabelValues("/v1/stream", "GET", "200")); got != 1 {
+		t.Errorf("stream requests = %v, want 1", got)
+	}
+	if got := testutil.CollectAndCount(m.httpDuration); got != 2 {
+		t.Errorf("latency series = %d, want 2", got)
+	}
+}
+
+func TestService_CountsScoreSubmissions(t *testing.T) {
+	m := New(prometheus.NewRegistry())
+	svc := NewService(&mockService{
+		SubmitScoreFunc: func(ctx context.Context, playerID string, score int) error {
+			switch playerID {
+			case "idle":
+				return errors.New("player not in active competition")
+			case "banned":
+				return errors.New("player banned")
+			case "broken":
+				return errors.New("pq: connection refused")
+			}
+			return nil
+		},
+	}, m)
+
+	for _, id := range []string{"p1", "p2", "idle", "banned", "broken"} {
+		svc.SubmitScore(context.Background(), id, 5)
+	}
+
+	if got := testutil.ToFloat64(m.scoreSubmissions.WithLabelValues("accepted")); got != 2 {
+		t.Errorf("accepted = %v, want 2", got)
+	}
+	if got := testutil.ToFloat64(m.scoreSubmissions.WithLabelValues("rejected")); got != 3 {
+		t.Errorf("rejected = %v, want 3", got)
+	}
+	if got := testutil.ToFloat64(m.scoreRejections.WithLabelValues("player_not_in_active_competition")); got != 1 {
+		t.Errorf("not-in-competition rejections = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(m.scoreRejections.WithLabelValues("player_banned")); got != 1 {
+		t.Errorf("banned rejections = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(m.scoreRejections.WithLabelValues("error")); got != 1 {
+		t.Errorf("error rejections = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(m.serviceCalls.WithLabelValues("SubmitScore", "error")); got != 3 {
+		t.Errorf("failed SubmitScore calls = %v, want 3", got)
+	}
+}
+
+func TestRepository_RecordsMatchedGroups(t *testing.T) {
+	m := New(prometheus.NewRegistry())
+	joined := time.Now().Add(-30 * time.Second)
+	repo := NewRepository(&mockRepo{waiting: []model.PlayerCompetition{
+		{PlayerID: "p1", JoinedAt: joined},
+		{PlayerID: "p2", JoinedAt: joined},
+		{PlayerID: "p3", JoinedAt: joined},
+	}}, m)
+
+	ctx := context.Background()
+	if _, err := repo.GetWaitingPlayers(ctx, 0); err != nil {
+		t.Fatalf("GetWaitingPlayers failed: %v", err)
+	}
+	if err := repo.UpdatePlayerCompetitionsToActive(ctx, []string{"p1", "p2"}, uuid.New(), time.Now()); err != nil {
+		t.Fatalf("UpdatePlayerCompetitionsToActive failed: %v", err)
+	}
+
+	if got := testutil.ToFloat64(m.groupsFormed); got != 1 {
+		t.Errorf("groups formed = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(m.playersMatched); got != 2 {
+		t.Errorf("players matched = %v, want 2", got)
+	}
+	expected := `
+# HELP leaderboard_matchmaking_time_in_queue_seconds Time matched players spent in the waiting queue.
+# TYPE leaderboard_matchmaking_time_in_queue_seconds histogram
+leaderboard_matchmaking_time_in_queue_seconds_bucket{le="1"} 0
+leaderboard_matchmaking_time_in_queue_seconds_bucket{le="5"} 0
+leaderboard_matchmaking_time_in_queue_seconds_bucket{le="10"} 0
+leaderboard_matchmaking_time_in_queue_seconds_bucket{le="15"} 0
+leaderboard_matchmaking_time_in_queue_seconds_bucket{le="30"} 0
+leaderboard_matchmaking_time_in_queue_seconds_bucket{le="60"} 2
+leaderboard_matchmaking_time_in_queue_seconds_bucket{le="120"} 2
+leaderboard_matchmaking_time_in_queue_seconds_bucket{le="300"} 2
+leaderboard_matchmaking_time_in_queue_seconds_bucket{le="600"} 2
+leaderboard_matchmaking_time_in_queue_seconds_bucket{le="1800"} 2
+leaderboard_matchmaking_time_in_queue_seconds_bucket{le="+Inf"} 2
+`
+	if err := testutil.CollectAndCompare(m.timeInQueue, strings.NewReader(expected), "leaderboard_matchmaking_time_in_queue_seconds_bucket"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRepository_FailedUpdateRecordsNoGroup(t *testing.T) {
+	m := New(prometheus.NewRegistry())
+	repo := NewRepository(&mockRepo{updateErr: errors.New("db error")}, m)
+
+	repo.UpdatePlayerCompetitionsToActive(context.Background(), []string{"p1", "p2"}, uuid.New(), time.Now())
+
+	if got := testutil.ToFloat64(m.groupsFormed); got != 0 {
+		t.Errorf("groups formed = %v, want 0", got)
+	}
+	if got := testutil.ToFloat64(m.dbErrors.WithLabelValues("UpdatePlayerCompetitionsToActive")); got != 1 {
+		t.Errorf("db errors = %v, want 1", got)
+	}
+}
+
+func TestTickMiddleware_ObservesResult(t *testing.T) {
+	m := New(prometheus.NewRegistry())
+	tick := m.TickMiddleware()(func(ctx context.Context) error {
+		return errors.New("db error")
+	})
+	if err := tick(context.Background()); err == nil {
+		t.Fatal("expected the tick error to be returned")
+	}
+	if got := testutil.CollectAndCount(m.tickDuration, "leaderboard_matchmaking_tick_duration_seconds"); got != 1 {
+		t.Errorf("tick series = %d, want 1", got)
+	}
+}
+
+func TestGaugeCollector(t *testing.T) {
+	c := NewGaugeCollector(&mockRepo{levels: map[int]int{1: 3, 5: 1}, active: 2})
+	expected := `
+# HELP leaderboard_active_competitions Competitions currently in progress.
+# TYPE leaderboard_active_competitions gauge
+leaderboard_active_competitions 2
+# HELP leaderboard_matchmaking_queue_depth Players waiting in the matchmaking queue by level bracket.
+# TYPE leaderboard_matchmaking_queue_depth gauge
+leaderboard_matchmaking_queue_depth{bracket="1"} 3
+leaderboard_matchmaking_queue_depth{bracket="5"} 1
+`
+	if err := testutil.CollectAndCompare(c, strings.NewReader(expected)); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestHandler_ServesRegisteredMetrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m := New(reg)
+	m.groupsFormed.Inc()
+
+	rec := httptest.NewRecorder()
+	Handler(reg).ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), "leaderboard_matchmaking_groups_formed_total 1") {
+		t.Errorf("metrics output missing groups counter:\n%s", rec.Body.String())
+	}
+}
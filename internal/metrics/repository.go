@@ -0,0 +1,574 @@
+package metrics
+
+import (
+	"context"
+	"leaderboard-service/internal/model"
+	"leaderboard-service/internal/repository"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// instrumentedRepository records latency and errors for every repository
+// call. It also derives the matchmaking group metrics from the worker's
+// GetWaitingPlayers / UpdatePlayerCompetitionsToActive sequence.
+type instrumentedRepository struct {
+	next    repository.RepositoryInterface
+	metrics *Metrics
+
+	mu       sync.Mutex
+	joinedAt map[string]time.Time // from the latest GetWaitingPlayers call
+}
+
+// NewRepository wraps next so that every call is recorded in m.
+func NewRepository(next repository.RepositoryInterface, m *Metrics) repository.RepositoryInterface {
+	return &instrumentedRepository{next: next, metrics: m, joinedAt: make(map[string]time.Time)}
+}
+
+func (r *instrumentedRepository) observe(operation string, start time.Time, err error) {
+	r.metrics.dbDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+	if err != nil {
+		r.metrics.dbErrors.WithLabelValues(operation).Inc()
+	}
+}
+
+func (r *instrumentedRepository) CreatePlayer(ctx context.Context, player *model.Player) error {
+	start := time.Now()
+	err := r.next.CreatePlayer(ctx, player)
+	r.observe("CreatePlayer", start, err)
+	return err
+}
+
+func (r *instrumentedRepository) GetPlayerByID(ctx context.Context, playerID string) (*model.Player, error) {
+	start := time.Now()
+	player, err := r.next.GetPlayerByID(ctx, playerID)
+	r.observe("GetPlayerByID", start, err)
+	return player, err
+}
+
+func (r *instrumentedRepository) UpdatePlayer(ctx context.Context, player *model.Player) error {
+	start := time.Now()
+	err := r.next.UpdatePlayer(ctx, player)
+	r.observe("UpdatePlayer", start, err)
+	return err
+}
+
+func (r *instrumentedRepository) CreateCompetition(ctx context.Context, comp *model.Competition) error {
+	start := time.Now()
+	err := r.next.CreateCompetition(ctx, comp)
+	r.observe("CreateCompetition", start, err)
+	return err
+}
+
+func (r *instrumentedRepository) GetCompetitionByID(ctx context.Context, competitionID string) (*model.Competition, error) {
+	start := time.Now()
+	comp, err := r.next.GetCompetitionByID(ctx, competitionID)
+	r.observe("GetCompetitionByID", start, err)
+	return comp, err
+}
+
+func (r *instrumentedRepository) UpdateCompetition(ctx context.Context, comp *model.Competition) error {
+	start := time.Now()
+	err := r.next.UpdateCompetition(ctx, comp)
+	r.observe("UpdateCompetition", start, err)
+	return err
+}
+
+func (r *instrumentedRepository) GetActiveCompetition(ctx context.Context) (*model.Competition, error) {
+	start := time.Now()
+	comp, err := r.next.GetActiveCompetition(ctx)
+	r.observe("GetActiveCompetition", start, err)
+	return comp, err
+}
+
+func (r *instrumentedRepository) CreatePlayerCompetition(ctx context.Context, pc *model.PlayerCompetition) error {
+	start := time.Now()
+	err := r.next.CreatePlayerCompetition(ctx, pc)
+	r.observe("CreatePlayerCompetition", start, err)
+	return err
+}
+
+func (r *instrumentedRepository) GetPlayerCompetitionByID(ctx context.Context, id int) (*model.PlayerCompetition, error) {
+	start := time.Now()
+	pc, err := r.next.GetPlayerCompetitionByID(ctx, id)
+	r.observe("GetPlayerCompetitionByID", start, err)
+	return pc, err
+}
+
+func (r *instrumentedRepository) UpdatePlayerCompetition(ctx context.Context, pc *model.PlayerCompetition) error {
+	start := time.Now()
+	err := r.next.UpdatePlayerCompetition(ctx, pc)
+	r.observe("UpdatePlayerCompetition", start, err)
+	return err
+}
+
+func (r *instrumentedRepository) GetLatestPlayerCompetition(ctx context.Context, playerID string) (*model.PlayerCompetition, error) {
+	start := time.Now()
+	pc, err := r.next.GetLatestPlayerCompetition(ctx, playerID)
+	r.observe("GetLatestPlayerCompetition", start, err)
+	return pc, err
+}
+
+func (r *instrumentedRepository) GetLeaderboardByCompetitionID(ctx context.Context, competitionID string, viewer model.Viewer) ([]model.PlayerCompetition, error) {
+	start := time.Now()
+	pcs, err := r.next.GetLeaderboardByCompetitionID(ctx, competitionID, viewer)
+	r.observe("GetLeaderboardByCompetitionID", start, err)
+	return pcs, err
+}
+
+func (r *instrumentedRepository) GetActivePlayerCompetition(ctx context.Context, playerID string) (*model.PlayerCompetition, error) {
+	start := time.Now()
+	pc, err := r.next.GetActivePlayerCompetition(ctx, playerID)
+	r.observe("GetActivePlayerCompetition", start, err)
+	return pc, err
+}
+
+func (r *instrumentedRepository) GetWaitingPlayers(ctx context.Context, limit int) ([]model.PlayerCompetition, error) {
+	start := time.Now()
+	pcs, err := r.next.GetWaitingPlayers(ctx, limit)
+	r.observe("GetWaitingPlayers", start, err)
+	if err == nil {
+		joinedAt := make(map[string]time.Time, len(pcs))
+		for _, pc := range pcs {
+			joinedAt[pc.PlayerID] = pc.JoinedAt
+		}
+		r.mu.Lock()
+		r.joinedAt = joinedAt
+		r.mu.Unlock()
+	}
+	return pcs, err
+}
+
+func (r *instrumentedRepository) UpdatePlayerCompetitionsToActive(ctx context.Context, playerIDs []string, competitionID uuid.UUID, endsAt time.Time) error {
+	start := time.Now()
+	err := r.next.UpdatePlayerCompetitionsToActive(ctx, playerIDs, competitionID, endsAt)
+	r.observe("UpdatePlayerCompetitionsToActive", start, err)
+	if err != nil || len(playerIDs) == 0 {
+		return err
+	}
+	r.metrics.groupsFormed.Inc()
+	r.metrics.playersMatched.Add(float64(len(playerIDs)))
+	r.mu.Lock()
+	for _, id := range playerIDs {
+		if joined, ok := r.joinedAt[id]; ok {
+			r.metrics.timeInQueue.Observe(start.Sub(joined).Seconds())
+			delete(r.joinedAt, id)
+		}
+	}
+	r.mu.Unlock()
+	return nil
+}
+
+func (r *instrumentedRepository) AddScoreToPlayer(ctx context.Context, playerID string, score int) error {
+	start := time.Now()
+	err := r.next.AddScoreToPlayer(ctx, playerID, score)
+	r.observe("AddScoreToPlayer", start, err)
+	return err
+}
+
+func (r *instrumentedRepository) CompleteFinishedCompetitions(ctx context.Context) ([]uuid.UUID, error) {
+	start := time.Now()
+	ids, err := r.next.CompleteFinishedCompetitions(ctx)
+	r.observe("CompleteFinishedCompetitions", start, err)
+	return ids, err
+}
+
+func (r *instrumentedRepository) IsPlayerInWaitingQueue(ctx context.Context, playerID string) (bool, error) {
+	start := time.Now()
+	inQueue, err := r.next.IsPlayerInWaitingQueue(ctx, playerID)
+	r.observe("IsPlayerInWaitingQueue", start, err)
+	return inQueue, err
+}
+
+func (r *instrumentedRepository) CancelWaitingPlayerCompetition(ctx context.Context, playerID string) (bool, error) {
+	start := time.Now()
+	removed, err := r.next.CancelWaitingPlayerCompetition(ctx, playerID)
+	r.observe("CancelWaitingPlayerCompetition", start, err)
+	return removed, err
+}
+
+func (r *instrumentedRepository) CountWaitingPlayersByLevel(ctx context.Context) (map[int]int, error) {
+	start := time.Now()
+	counts, err := r.next.CountWaitingPlayersByLevel(ctx)
+	r.observe("CountWaitingPlayersByLevel", start, err)
+	return counts, err
+}
+
+func (r *instrumentedRepository) CountActiveCompetitions(ctx context.Context) (int, error) {
+	start := time.Now()
+	count, err := r.next.CountActiveCompetitions(ctx)
+	r.observe("CountActiveCompetitions", start, err)
+	return count, err
+}
+
+func (r *instrumentedRepository) CreateAuditEntry(ctx context.Context, entry *model.AuditEntry) error {
+	start := time.Now()
+	err := r.next.CreateAuditEntry(ctx, entry)
+	r.observe("CreateAuditEntry", start, err)
+	return err
+}
+
+func (r *instrumentedRepository) ListAuditEntries(ctx context.Context, filter model.AuditFilter) ([]model.AuditEntry, error) {
+	start := time.Now()
+	entries, err := r.next.ListAuditEntries(ctx, filter)
+	r.observe("ListAuditEntries", start, err)
+	return entries, err
+}
+
+func (r *instrumentedRepository) ListCompetitions(ctx context.Context, filter model.CompetitionFilter) ([]model.Competition, error) {
+	start := time.Now()
+	comps, err := r.next.ListCompetitions(ctx, filter)
+	r.observe("ListCompetitions", start, err)
+	return comps, err
+}
+
+func (r *instrumentedRepository) GetCompetitionPlayer(ctx context.Context, competitionID uuid.UUID, playerID string) (*model.PlayerCompetition, error) {
+	start := time.Now()
+	pc, err := r.next.GetCompetitionPlayer(ctx, competitionID, playerID)
+	r.observe("GetCompetitionPlayer", start, err)
+	return pc, err
+}
+
+func (r *instrumentedRepository) CancelCompetition(ctx context.Context, competitionID uuid.UUID, audit *model.AuditEntry) (bool, error) {
+	start := time.Now()
+	ok, err := r.next.CancelCompetition(ctx, competitionID, audit)
+	r.observe("CancelCompetition", start, err)
+	return ok, err
+}
+
+func (r *instrumentedRepository) CompleteCompetition(ctx context.Context, competitionID uuid.UUID, audit *model.AuditEntry) (bool, error) {
+	start := time.Now()
+	ok, err := r.next.CompleteCompetition(ctx, competitionID, audit)
+	r.observe("CompleteCompetition", start, err)
+	return ok, err
+}
+
+func (r *instrumentedRepository) SetCompetitionEndsAt(ctx context.Context, competitionID uuid.UUID, endsAt time.Time, audit *model.AuditEntry) (bool, error) {
+	start := time.Now()
+	ok, err := r.next.SetCompetitionEndsAt(ctx, competitionID, endsAt, audit)
+	r.observe("SetCompetitionEndsAt", start, err)
+	return ok, err
+}
+
+func (r *instrumentedRepository) ExcludeCompetitionPlayer(ctx context.Context, competitionID uuid.UUID, playerID string, status model.PlayerStatus, audit *model.AuditEntry) (bool, error) {
+	start := time.Now()
+	ok, err := r.next.ExcludeCompetitionPlayer(ctx, competitionID, playerID, status, audit)
+	r.observe("ExcludeCompetitionPlayer", start, err)
+	return ok, err
+}
+
+func (r *instrumentedRepository) SetCompetitionScore(ctx context.Context, competitionID uuid.UUID, playerID string, from, to int, audit *model.AuditEntry) (bool, error) {
+	start := time.Now()
+	ok, err := r.next.SetCompetitionScore(ctx, competitionID, playerID, from, to, audit)
+	r.observe("SetCompetitionScore", start, err)
+	return ok, err
+}
+
+func (r *instrumentedRepository) CreateScheduledCompetition(ctx context.Context, comp *model.Competition, audit *model.AuditEntry) error {
+	start := time.Now()
+	err := r.next.CreateScheduledCompetition(ctx, comp, audit)
+	r.observe("CreateScheduledCompetition", start, err)
+	return err
+}
+
+func (r *instrumentedRepository) OpenScheduledCompetitions(ctx context.Context) ([]uuid.UUID, error) {
+	start := time.Now()
+	opened, err := r.next.OpenScheduledCompetitions(ctx)
+	r.observe("OpenScheduledCompetitions", start, err)
+	return opened, err
+}
+
+func (r *instrumentedRepository) StartScheduledCompetitions(ctx context.Context) ([]model.Competition, error) {
+	start := time.Now()
+	started, err := r.next.StartScheduledCompetitions(ctx)
+	r.observe("StartScheduledCompetitions", start, err)
+	return started, err
+}
+
+func (r *instrumentedRepository) RegisterCompetitionPlayer(ctx context.Context, competitionID uuid.UUID, player *model.Player) (bool, error) {
+	start := time.Now()
+	ok, err := r.next.RegisterCompetitionPlayer(ctx, competitionID, player)
+	r.observe("RegisterCompetitionPlayer", start, err)
+	return ok, err
+}
+
+func (r *instrumentedRepository) CancelCompetitionRegistration(ctx context.Context, competitionID uuid.UUID, playerID string) (bool, error) {
+	start := time.Now()
+	ok, err := r.next.CancelCompetitionRegistration(ctx, competitionID, playerID)
+	r.observe("CancelCompetitionRegistration", start, err)
+	return ok, err
+}
+
+func (r *instrumentedRepository) ListCompetitionTemplates(ctx context.Context) ([]model.CompetitionTemplate, error) {
+	start := time.Now()
+	res, err := r.next.ListCompetitionTemplates(ctx)
+	r.observe("ListCompetitionTemplates", start, err)
+	return res, err
+}
+
+func (r *instrumentedRepository) GetCompetitionTemplate(ctx context.Context, templateID uuid.UUID) (*model.CompetitionTemplate, error) {
+	start := time.Now()
+	res, err := r.next.GetCompetitionTemplate(ctx, templateID)
+	r.observe("GetCompetitionTemplate", start, err)
+	return res, err
+}
+
+func (r *instrumentedRepository) CreateCompetitionTemplate(ctx context.Context, tpl *model.CompetitionTemplate, audit *model.AuditEntry) error {
+	start := time.Now()
+	err := r.next.CreateCompetitionTemplate(ctx, tpl, audit)
+	r.observe("CreateCompetitionTemplate", start, err)
+	return err
+}
+
+func (r *instrumentedRepository) UpdateCompetitionTemplate(ctx context.Context, tpl *model.CompetitionTemplate, lastUpdated time.Time, audit *model.AuditEntry) (bool, error) {
+	start := time.Now()
+	ok, err := r.next.UpdateCompetitionTemplate(ctx, tpl, lastUpdated, audit)
+	r.observe("UpdateCompetitionTemplate", start, err)
+	return ok, err
+}
+
+func (r *instrumentedRepository) DeleteCompetitionTemplate(ctx context.Context, templateID uuid.UUID, audit *model.AuditEntry) (bool, error) {
+	start := time.Now()
+	ok, err := r.next.DeleteCompetitionTemplate(ctx, templateID, audit)
+	r.observe("DeleteCompetitionTemplate", start, err)
+	return ok, err
+}
+
+func (r *instrumentedRepository) DueCompetitionTemplates(ctx context.Context) ([]model.CompetitionTemplate, error) {
+	start := time.Now()
+	res, err := r.next.DueCompetitionTemplates(ctx)
+	r.observe("DueCompetitionTemplates", start, err)
+	return res, err
+}
+
+func (r *instrumentedRepository) StartTemplateOccurrence(ctx context.Context, templateID uuid.UUID, due time.Time, next *time.Time, comp *model.Competition) ([]uuid.UUID, bool, error) {
+	start := time.Now()
+	closed, ok, err := r.next.StartTemplateOccurrence(ctx, templateID, due, next, comp)
+	r.observe("StartTemplateOccurrence", start, err)
+	return closed, ok, err
+}
+
+func (r *instrumentedRepository) UnsettledLeagueCompetitions(ctx context.Context) ([]model.Competition, error) {
+	start := time.Now()
+	comps, err := r.next.UnsettledLeagueCompetitions(ctx)
+	r.observe("UnsettledLeagueCompetitions", start, err)
+	return comps, err
+}
+
+func (r *instrumentedRepository) ApplyTierMovements(ctx context.Context, competitionID uuid.UUID, moves []model.TierMovement) ([]model.TierMovement, error) {
+	start := time.Now()
+	res, err := r.next.ApplyTierMovements(ctx, competitionID, moves)
+	r.observe("ApplyTierMovements", start, err)
+	return res, err
+}
+
+func (r *instrumentedRepository) ListTierHistory(ctx context.Context, playerID string, limit int) ([]model.TierMovement, error) {
+	start := time.Now()
+	res, err := r.next.ListTierHistory(ctx, playerID, limit)
+	r.observe("ListTierHistory", start, err)
+	return res, err
+}
+
+func (r *instrumentedRepository) CreateTournament(ctx context.Context, t *model.Tournament, matches []model.TournamentMatch, comps []model.Competition, audit *model.AuditEntry) error {
+	start := time.Now()
+	err := r.next.CreateTournament(ctx, t, matches, comps, audit)
+	r.observe("CreateTournament", start, err)
+	return err
+}
+
+func (r *instrumentedRepository) GetTournament(ctx context.Context, tournamentID uuid.UUID) (*model.Tournament, error) {
+	start := time.Now()
+	res, err := r.next.GetTournament(ctx, tournamentID)
+	r.observe("GetTournament", start, err)
+	return res, err
+}
+
+func (r *instrumentedRepository) ActiveTournaments(ctx context.Context) ([]model.Tournament, error) {
+	start := time.Now()
+	res, err := r.next.ActiveTournaments(ctx)
+	r.observe("ActiveTournaments", start, err)
+	return res, err
+}
+
+func (r *instrumentedRepository) ListTournamentMatches(ctx context.Context, tournamentID uuid.UUID) ([]model.TournamentMatch, error) {
+	start := time.Now()
+	res, err := r.next.ListTournamentMatches(ctx, tournamentID)
+	r.observe("ListTournamentMatches", start, err)
+	return res, err
+}
+
+func (r *instrumentedRepository) StartTournamentRound(ctx context.Context, tournamentID uuid.UUID, round int, matches []model.TournamentMatch, comps []model.Competition) (bool, error) {
+	start := time.Now()
+	ok, err := r.next.StartTournamentRound(ctx, tournamentID, round, matches, comps)
+	r.observe("StartTournamentRound", start, err)
+	return ok, err
+}
+
+func (r *instrumentedRepository) CompleteTournament(ctx context.Context, tournamentID uuid.UUID, round int, winners []string) (bool, error) {
+	start := time.Now()
+	ok, err := r.next.CompleteTournament(ctx, tournamentID, round, winners)
+	r.observe("CompleteTournament", start, err)
+	return ok, err
+}
+
+func (r *instrumentedRepository) CreateParty(ctx context.Context, party *model.Party) (bool, error) {
+	start := time.Now()
+	ok, err := r.next.CreateParty(ctx, party)
+	r.observe("CreateParty", start, err)
+	return ok, err
+}
+
+func (r *instrumentedRepository) GetParty(ctx context.Context, partyID uuid.UUID) (*model.Party, error) {
+	start := time.Now()
+	res, err := r.next.GetParty(ctx, partyID)
+	r.observe("GetParty", start, err)
+	return res, err
+}
+
+func (r *instrumentedRepository) GetPlayerParty(ctx context.Context, playerID string) (*model.Party, error) {
+	start := time.Now()
+	res, err := r.next.GetPlayerParty(ctx, playerID)
+	r.observe("GetPlayerParty", start, err)
+	return res, err
+}
+
+func (r *instrumentedRepository) InvitePartyPlayer(ctx context.Context, partyID uuid.UUID, playerID string) (bool, error) {
+	start := time.Now()
+	ok, err := r.next.InvitePartyPlayer(ctx, partyID, playerID)
+	r.observe("InvitePartyPlayer", start, err)
+	return ok, err
+}
+
+func (r *instrumentedRepository) AcceptPartyInvite(ctx context.Context, partyID uuid.UUID, playerID string, maxSize int) (bool, error) {
+	start := time.Now()
+	ok, err := r.next.AcceptPartyInvite(ctx, partyID, playerID, maxSize)
+	r.observe("AcceptPartyInvite", start, err)
+	return ok, err
+}
+
+func (r *instrumentedRepository) LeaveParty(ctx context.Context, partyID uuid.UUID, playerID string) (bool, error) {
+	start := time.Now()
+	ok, err := r.next.LeaveParty(ctx, partyID, playerID)
+	r.observe("LeaveParty", start, err)
+	return ok, err
+}
+
+func (r *instrumentedRepository) QueueParty(ctx context.Context, partyID uuid.UUID, entries []model.PlayerCompetition) (bool, error) {
+	start := time.Now()
+	ok, err := r.next.QueueParty(ctx, partyID, entries)
+	r.observe("QueueParty", start, err)
+	return ok, err
+}
+
+func (r *instrumentedRepository) AddFriends(ctx context.Context, playerID string, friendIDs []string) ([]string, error) {
+	start := time.Now()
+	added, err := r.next.AddFriends(ctx, playerID, friendIDs)
+	r.observe("AddFriends", start, err)
+	return added, err
+}
+
+func (r *instrumentedRepository) RemoveFriend(ctx context.Context, playerID, friendID string) (bool, error) {
+	start := time.Now()
+	ok, err := r.next.RemoveFriend(ctx, playerID, friendID)
+	r.observe("RemoveFriend", start, err)
+	return ok, err
+}
+
+func (r *instrumentedRepository) ListFriends(ctx context.Context, playerID string) ([]string, error) {
+	start := time.Now()
+	friends, err := r.next.ListFriends(ctx, playerID)
+	r.observe("ListFriends", start, err)
+	return friends, err
+}
+
+func (r *instrumentedRepository) GetPlayerStats(ctx context.Context, playerIDs []string, viewer model.Viewer) ([]model.PlayerStats, error) {
+	start := time.Now()
+	stats, err := r.next.GetPlayerStats(ctx, playerIDs, viewer)
+	r.observe("GetPlayerStats", start, err)
+	return stats, err
+}
+
+func (r *instrumentedRepository) ListRegions(ctx context.Context) ([]model.Region, error) {
+	start := time.Now()
+	regions, err := r.next.ListRegions(ctx)
+	r.observe("ListRegions", start, err)
+	return regions, err
+}
+
+func (r *instrumentedRepository) GetRegion(ctx context.Context, regionCode string) (*model.Region, error) {
+	start := time.Now()
+	region, err := r.next.GetRegion(ctx, regionCode)
+	r.observe("GetRegion", start, err)
+	return region, err
+}
+
+func (r *instrumentedRepository) PutRegion(ctx context.Context, region *model.Region, audit *model.AuditEntry) error {
+	start := time.Now()
+	err := r.next.PutRegion(ctx, region, audit)
+	r.observe("PutRegion", start, err)
+	return err
+}
+
+func (r *instrumentedRepository) DeleteRegion(ctx context.Context, regionCode string, audit *model.AuditEntry) (bool, error) {
+	start := time.Now()
+	ok, err := r.next.DeleteRegion(ctx, regionCode, audit)
+	r.observe("DeleteRegion", start, err)
+	return ok, err
+}
+
+func (r *instrumentedRepository) ListTopPlayers(ctx context.Context, filter model.GeoFilter, limit int) ([]model.PlayerStats, error) {
+	start := time.Now()
+	stats, err := r.next.ListTopPlayers(ctx, filter, limit)
+	r.observe("ListTopPlayers", start, err)
+	return stats, err
+}
+
+func (r *instrumentedRepository) ListPlayerHistory(ctx context.Context, playerID string, filter model.PlayerHistoryFilter) ([]model.CompetitionResult, error) {
+	start := time.Now()
+	results, err := r.next.ListPlayerHistory(ctx, playerID, filter)
+	r.observe("ListPlayerHistory", start, err)
+	return results, err
+}
+
+func (r *instrumentedRepository) GetPlayerHistorySummary(ctx context.Context, playerID string, filter model.PlayerHistoryFilter) (*model.PlayerHistorySummary, error) {
+	start := time.Now()
+	sum, err := r.next.GetPlayerHistorySummary(ctx, playerID, filter)
+	r.observe("GetPlayerHistorySummary", start, err)
+	return sum, err
+}
+
+func (r *instrumentedRepository) AnonymizePlayer(ctx context.Context, playerID, alias string) (bool, error) {
+	start := time.Now()
+	ok, err := r.next.AnonymizePlayer(ctx, playerID, alias)
+	r.observe("AnonymizePlayer", start, err)
+	return ok, err
+}
+
+func (r *instrumentedRepository) PurgeAnonymizedPlayers(ctx context.Context, cutoff time.Time) (int, error) {
+	start := time.Now()
+	n, err := r.next.PurgeAnonymizedPlayers(ctx, cutoff)
+	r.observe("PurgeAnonymizedPlayers", start, err)
+	return n, err
+}
+
+func (r *instrumentedRepository) ListPlayerEntries(ctx context.Context, playerID string) ([]model.PlayerCompetition, error) {
+	start := time.Now()
+	entries, err := r.next.ListPlayerEntries(ctx, playerID)
+	r.observe("ListPlayerEntries", start, err)
+	return entries, err
+}
+
+func (r *instrumentedRepository) GetPlayerModeration(ctx context.Context, playerID string) (*model.Moderation, error) {
+	start := time.Now()
+	m, err := r.next.GetPlayerModeration(ctx, playerID)
+	r.observe("GetPlayerModeration", start, err)
+	return m, err
+}
+
+func (r *instrumentedRepository) SetPlayerSanction(ctx context.Context, playerID string, sanction model.Sanction, value *time.Time, audit *model.AuditEntry) (bool, error) {
+	start := time.Now()
+	ok, err := r.next.SetPlayerSanction(ctx, playerID, sanction, value, audit)
+	r.observe("SetPlayerSanction", start, err)
+	return ok, err
+}
@@ -0,0 +1,419 @@
+package metrics
+
+import (
+	"context"
+	"leaderboard-service/internal/model"
+	"leaderboard-service/internal/service"
+	"strings"
+	"time"
+)
+
+// instrumentedService records call counts and latency for every
+// ServiceInterface method, plus score submission outcomes.
+type instrumentedService struct {
+	next    service.ServiceInterface
+	metrics *Metrics
+}
+
+// NewService wraps next so that every call is recorded in m.
+func NewService(next service.ServiceInterface, m *Metrics) service.ServiceInterface {
+	return &instrumentedService{next: next, metrics: m}
+}
+
+func (s *instrumentedService) observe(method string, start time.Time, err error) {
+	s.metrics.serviceDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+	s.metrics.serviceCalls.WithLabelValues(method, result(err)).Inc()
+}
+
+func (s *instrumentedService) Join(ctx context.Context, playerID string) (string, error) {
+	start := time.Now()
+	id, err := s.next.Join(ctx, playerID)
+	s.observe("Join", start, err)
+	return id, err
+}
+
+func (s *instrumentedService) Leave(ctx context.Context, playerID string) error {
+	start := time.Now()
+	err := s.next.Leave(ctx, playerID)
+	s.observe("Leave", start, err)
+	return err
+}
+
+func (s *instrumentedService) SubmitScore(ctx context.Context, playerID string, score int) error {
+	start := time.Now()
+	err := s.next.SubmitScore(ctx, playerID, score)
+	s.observe("SubmitScore", start, err)
+	if err != nil {
+		s.metrics.scoreSubmissions.WithLabelValues("rejected").Inc()
+		s.metrics.scoreRejections.WithLabelValues(rejectionReason(err)).Inc()
+	} else {
+		s.metrics.scoreSubmissions.WithLabelValues("accepted").Inc()
+	}
+	return err
+}
+
+func (s *instrumentedService) GetPlayerLeaderboard(ctx context.Context, playerID string) (interface{}, error) {
+	start := time.Now()
+	resp, err := s.next.GetPlayerLeaderboard(ctx, playerID)
+	s.observe("GetPlayerLeaderboard", start, err)
+	return resp, err
+}
+
+func (s *instrumentedService) GetLeaderboard(ctx context.Context, leaderboardID string) (interface{}, error) {
+	start := time.Now()
+	resp, err := s.next.GetLeaderboard(ctx, leaderboardID)
+	s.observe("GetLeaderboard", start, err)
+	return resp, err
+}
+
+func (s *instrumentedService) CreatePlayer(ctx context.Context, playerID string, profile service.PlayerProfile) (*model.Player, error) {
+	start := time.Now()
+	player, err := s.next.CreatePlayer(ctx, playerID, profile)
+	s.observe("CreatePlayer", start, err)
+	return player, err
+}
+
+func (s *instrumentedService) GetPlayer(ctx context.Context, playerID string) (*model.Player, error) {
+	start := time.Now()
+	player, err := s.next.GetPlayer(ctx, playerID)
+	s.observe("GetPlayer", start, err)
+	return player, err
+}
+
+func (s *instrumentedService) UpdatePlayer(ctx context.Context, playerID string, profile service.PlayerProfile) (*model.Player, error) {
+	start := time.Now()
+	player, err := s.next.UpdatePlayer(ctx, playerID, profile)
+	s.observe("UpdatePlayer", start, err)
+	return player, err
+}
+
+func (s *instrumentedService) SubscribeLeaderboard(ctx context.Context, leaderboardID string, lastEventID uint64) (*service.Subscription, error) {
+	start := time.Now()
+	sub, err := s.next.SubscribeLeaderboard(ctx, leaderboardID, lastEventID)
+	s.observe("SubscribeLeaderboard", start, err)
+	return sub, err
+}
+
+func (s *instrumentedService) SubscribePlayer(ctx context.Context, playerID string) (*service.Subscription, error) {
+	start := time.Now()
+	sub, err := s.next.SubscribePlayer(ctx, playerID)
+	s.observe("SubscribePlayer", start, err)
+	return sub, err
+}
+
+// rejectionReason turns the service's error messages into a bounded label.
+func rejectionReason(err error) string {
+	switch msg := err.Error(); msg {
+	case "player not found", "player not in active competition", "player banned", "player suspended":
+		return strings.ReplaceAll(msg, " ", "_")
+	}
+	return "error"
+}
+
+func (s *instrumentedService) GetConfig(ctx context.Context) (service.Config, error) {
+	start := time.Now()
+	config, err := s.next.GetConfig(ctx)
+	s.observe("GetConfig", start, err)
+	return config, err
+}
+
+func (s *instrumentedService) UpdateConfig(ctx context.Context, update service.ConfigUpdate) (service.Config, error) {
+	start := time.Now()
+	config, err := s.next.UpdateConfig(ctx, update)
+	s.observe("UpdateConfig", start, err)
+	return config, err
+}
+
+func (s *instrumentedService) ListAuditEntries(ctx context.Context, filter model.AuditFilter) ([]model.AuditEntry, error) {
+	start := time.Now()
+	entries, err := s.next.ListAuditEntries(ctx, filter)
+	s.observe("ListAuditEntries", start, err)
+	return entries, err
+}
+
+func (s *instrumentedService) ListCompetitions(ctx context.Context, filter model.CompetitionFilter) ([]model.Competition, error) {
+	start := time.Now()
+	comps, err := s.next.ListCompetitions(ctx, filter)
+	s.observe("ListCompetitions", start, err)
+	return comps, err
+}
+
+func (s *instrumentedService) CancelCompetition(ctx context.Context, competitionID, reason string) (*model.Competition, error) {
+	start := time.Now()
+	comp, err := s.next.CancelCompetition(ctx, competitionID, reason)
+	s.observe("CancelCompetition", start, err)
+	return comp, err
+}
+
+func (s *instrumentedService) CompleteCompetition(ctx context.Context, competitionID, reason string) (*model.Competition, error) {
+	start := time.Now()
+	comp, err := s.next.CompleteCompetition(ctx, competitionID, reason)
+	s.observe("CompleteCompetition", start, err)
+	return comp, err
+}
+
+func (s *instrumentedService) SetCompetitionEndsAt(ctx context.Context, competitionID string, endsAt time.Time, reason string) (*model.Competition, error) {
+	start := time.Now()
+	comp, err := s.next.SetCompetitionEndsAt(ctx, competitionID, endsAt, reason)
+	s.observe("SetCompetitionEndsAt", start, err)
+	return comp, err
+}
+
+func (s *instrumentedService) ExcludePlayer(ctx context.Context, competitionID, playerID string, status model.PlayerStatus, reason string) error {
+	start := time.Now()
+	err := s.next.ExcludePlayer(ctx, competitionID, playerID, status, reason)
+	s.observe("ExcludePlayer", start, err)
+	return err
+}
+
+func (s *instrumentedService) AdjustScore(ctx context.Context, competitionID, playerID string, adjustment service.ScoreAdjustment) (*service.ScoreChange, error) {
+	start := time.Now()
+	change, err := s.next.AdjustScore(ctx, competitionID, playerID, adjustment)
+	s.observe("AdjustScore", start, err)
+	return change, err
+}
+
+func (s *instrumentedService) ScheduleCompetition(ctx context.Context, schedule service.CompetitionSchedule) (*model.Competition, error) {
+	start := time.Now()
+	comp, err := s.next.ScheduleCompetition(ctx, schedule)
+	s.observe("ScheduleCompetition", start, err)
+	return comp, err
+}
+
+func (s *instrumentedService) RegisterForCompetition(ctx context.Context, competitionID, playerID string) error {
+	start := time.Now()
+	err := s.next.RegisterForCompetition(ctx, competitionID, playerID)
+	s.observe("RegisterForCompetition", start, err)
+	return err
+}
+
+func (s *instrumentedService) UnregisterFromCompetition(ctx context.Context, competitionID, playerID string) error {
+	start := time.Now()
+	err := s.next.UnregisterFromCompetition(ctx, competitionID, playerID)
+	s.observe("UnregisterFromCompetition", start, err)
+	return err
+}
+
+func (s *instrumentedService) ListTemplates(ctx context.Context) ([]model.CompetitionTemplate, error) {
+	start := time.Now()
+	res, err := s.next.ListTemplates(ctx)
+	s.observe("ListTemplates", start, err)
+	return res, err
+}
+
+func (s *instrumentedService) GetTemplate(ctx context.Context, templateID string) (*model.CompetitionTemplate, error) {
+	start := time.Now()
+	res, err := s.next.GetTemplate(ctx, templateID)
+	s.observe("GetTemplate", start, err)
+	return res, err
+}
+
+func (s *instrumentedService) CreateTemplate(ctx context.Context, def service.TemplateDefinition) (*model.CompetitionTemplate, error) {
+	start := time.Now()
+	res, err := s.next.CreateTemplate(ctx, def)
+	s.observe("CreateTemplate", start, err)
+	return res, err
+}
+
+func (s *instrumentedService) UpdateTemplate(ctx context.Context, templateID string, def service.TemplateDefinition) (*model.CompetitionTemplate, error) {
+	start := time.Now()
+	res, err := s.next.UpdateTemplate(ctx, templateID, def)
+	s.observe("UpdateTemplate", start, err)
+	return res, err
+}
+
+func (s *instrumentedService) DeleteTemplate(ctx context.Context, templateID, reason string) error {
+	start := time.Now()
+	err := s.next.DeleteTemplate(ctx, templateID, reason)
+	s.observe("DeleteTemplate", start, err)
+	return err
+}
+
+func (s *instrumentedService) PreviewTemplate(ctx context.Context, templateID string, count int) ([]service.Occurrence, error) {
+	start := time.Now()
+	res, err := s.next.PreviewTemplate(ctx, templateID, count)
+	s.observe("PreviewTemplate", start, err)
+	return res, err
+}
+
+func (s *instrumentedService) GetLeagueHistory(ctx context.Context, playerID string) ([]model.TierMovement, error) {
+	start := time.Now()
+	res, err := s.next.GetLeagueHistory(ctx, playerID)
+	s.observe("GetLeagueHistory", start, err)
+	return res, err
+}
+
+func (s *instrumentedService) CreateTournament(ctx context.Context, def service.TournamentDefinition) (*model.Tournament, error) {
+	start := time.Now()
+	res, err := s.next.CreateTournament(ctx, def)
+	s.observe("CreateTournament", start, err)
+	return res, err
+}
+
+func (s *instrumentedService) GetTournament(ctx context.Context, tournamentID string) (*service.Bracket, error) {
+	start := time.Now()
+	res, err := s.next.GetTournament(ctx, tournamentID)
+	s.observe("GetTournament", start, err)
+	return res, err
+}
+
+func (s *instrumentedService) CreateParty(ctx context.Context, playerID string) (*model.Party, error) {
+	start := time.Now()
+	res, err := s.next.CreateParty(ctx, playerID)
+	s.observe("CreateParty", start, err)
+	return res, err
+}
+
+func (s *instrumentedService) GetParty(ctx context.Context, partyID string) (*model.Party, error) {
+	start := time.Now()
+	res, err := s.next.GetParty(ctx, partyID)
+	s.observe("GetParty", start, err)
+	return res, err
+}
+
+func (s *instrumentedService) InviteToParty(ctx context.Context, partyID, playerID, inviteeID string) error {
+	start := time.Now()
+	err := s.next.InviteToParty(ctx, partyID, playerID, inviteeID)
+	s.observe("InviteToParty", start, err)
+	return err
+}
+
+func (s *instrumentedService) AcceptPartyInvite(ctx context.Context, partyID, playerID string) (*model.Party, error) {
+	start := time.Now()
+	res, err := s.next.AcceptPartyInvite(ctx, partyID, playerID)
+	s.observe("AcceptPartyInvite", start, err)
+	return res, err
+}
+
+func (s *instrumentedService) LeaveParty(ctx context.Context, partyID, playerID string) error {
+	start := time.Now()
+	err := s.next.LeaveParty(ctx, partyID, playerID)
+	s.observe("LeaveParty", start, err)
+	return err
+}
+
+func (s *instrumentedService) QueueParty(ctx context.Context, partyID, playerID string) error {
+	start := time.Now()
+	err := s.next.QueueParty(ctx, partyID, playerID)
+	s.observe("QueueParty", start, err)
+	return err
+}
+
+func (s *instrumentedService) ListFriends(ctx context.Context, playerID string) ([]string, error) {
+	start := time.Now()
+	friends, err := s.next.ListFriends(ctx, playerID)
+	s.observe("ListFriends", start, err)
+	return friends, err
+}
+
+func (s *instrumentedService) AddFriend(ctx context.Context, playerID, friendID string) error {
+	start := time.Now()
+	err := s.next.AddFriend(ctx, playerID, friendID)
+	s.observe("AddFriend", start, err)
+	return err
+}
+
+func (s *instrumentedService) ImportFriends(ctx context.Context, playerID string, friendIDs []string) (*service.FriendImport, error) {
+	start := time.Now()
+	res, err := s.next.ImportFriends(ctx, playerID, friendIDs)
+	s.observe("ImportFriends", start, err)
+	return res, err
+}
+
+func (s *instrumentedService) RemoveFriend(ctx context.Context, playerID, friendID string) error {
+	start := time.Now()
+	err := s.next.RemoveFriend(ctx, playerID, friendID)
+	s.observe("RemoveFriend", start, err)
+	return err
+}
+
+func (s *instrumentedService) GetFriendsLeaderboard(ctx context.Context, leaderboardID, playerID string) (*service.FriendsLeaderboard, error) {
+	start := time.Now()
+	res, err := s.next.GetFriendsLeaderboard(ctx, leaderboardID, playerID)
+	s.observe("GetFriendsLeaderboard", start, err)
+	return res, err
+}
+
+func (s *instrumentedService) GetFriendsStats(ctx context.Context, playerID string) (*service.FriendsStats, error) {
+	start := time.Now()
+	res, err := s.next.GetFriendsStats(ctx, playerID)
+	s.observe("GetFriendsStats", start, err)
+	return res, err
+}
+
+func (s *instrumentedService) ListRegions(ctx context.Context) ([]model.Region, error) {
+	start := time.Now()
+	regions, err := s.next.ListRegions(ctx)
+	s.observe("ListRegions", start, err)
+	return regions, err
+}
+
+func (s *instrumentedService) PutRegion(ctx context.Context, regionCode string, def service.RegionDefinition) (*model.Region, error) {
+	start := time.Now()
+	region, err := s.next.PutRegion(ctx, regionCode, def)
+	s.observe("PutRegion", start, err)
+	return region, err
+}
+
+func (s *instrumentedService) DeleteRegion(ctx context.Context, regionCode, reason string) error {
+	start := time.Now()
+	err := s.next.DeleteRegion(ctx, regionCode, reason)
+	s.observe("DeleteRegion", start, err)
+	return err
+}
+
+func (s *instrumentedService) GetGeoLeaderboard(ctx context.Context, leaderboardID string, filter model.GeoFilter) (*service.GeoLeaderboard, error) {
+	start := time.Now()
+	res, err := s.next.GetGeoLeaderboard(ctx, leaderboardID, filter)
+	s.observe("GetGeoLeaderboard", start, err)
+	return res, err
+}
+
+func (s *instrumentedService) GetTopPlayers(ctx context.Context, filter model.GeoFilter, limit int) (*service.TopPlayers, error) {
+	start := time.Now()
+	res, err := s.next.GetTopPlayers(ctx, filter, limit)
+	s.observe("GetTopPlayers", start, err)
+	return res, err
+}
+
+func (s *instrumentedService) GetPlayerHistory(ctx context.Context, playerID string, filter model.PlayerHistoryFilter) (*service.PlayerHistory, error) {
+	start := time.Now()
+	res, err := s.next.GetPlayerHistory(ctx, playerID, filter)
+	s.observe("GetPlayerHistory", start, err)
+	return res, err
+}
+
+func (s *instrumentedService) DeletePlayer(ctx context.Context, playerID string) error {
+	start := time.Now()
+	err := s.next.DeletePlayer(ctx, playerID)
+	s.observe("DeletePlayer", start, err)
+	return err
+}
+
+func (s *instrumentedService) ExportPlayerData(ctx context.Context, playerID string) (*service.PlayerExport, error) {
+	start := time.Now()
+	res, err := s.next.ExportPlayerData(ctx, playerID)
+	s.observe("ExportPlayerData", start, err)
+	return res, err
+}
+
+func (s *instrumentedService) GetModeration(ctx context.Context, playerID string) (*model.Moderation, error) {
+	start := time.Now()
+	m, err := s.next.GetModeration(ctx, playerID)
+	s.observe("GetModeration", start, err)
+	return m, err
+}
+
+func (s *instrumentedService) ApplySanction(ctx context.Context, playerID string, sanction model.Sanction, until time.Time, reason string) (*model.Moderation, error) {
+	start := time.Now()
+	m, err := s.next.ApplySanction(ctx, playerID, sanction, until, reason)
+	s.observe("ApplySanction", start, err)
+	return m, err
+}
+
+func (s *instrumentedService) LiftSanction(ctx context.Context, playerID string, sanction model.Sanction, reason string) (*model.Moderation, error) {
+	start := time.Now()
+	m, err := s.next.LiftSanction(ctx, playerID, sanction, reason)
+	s.observe("LiftSanction", start, err)
+	return m, err
+}
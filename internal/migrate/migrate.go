@@ -0,0 +1,321 @@
+// Package migrate applies the versioned SQL migrations embedded in the
+// binary and records them in the schema_migrations table.
+//
+// Migrations live in migrations/ as NNNN_name.up.sql and NNNN_name.down.sql.
+// Each one runs in its own transaction, and every run holds a Postgres
+// advisory lock so replicas starting at the same time don't race.
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"leaderboard-service/internal/logging"
+	"log/slog"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+//go:embed migrations/*.sql
+var embedded embed.FS
+
+// lockID identifies the advisory lock held while migrating.
+const lockID int64 = 0x6c6272646d6967 // "lbrdmig"
+
+var fileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Migration is one schema version.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status reports whether a migration has been applied.
+type Status struct {
+	Version   int
+	Name      string
+	AppliedAt *time.Time
+}
+
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+// New returns a migrator for the migrations embedded in the binary.
+func New(db *sql.DB) *Migrator {
+	migrations, err := Load(embedded)
+	if err != nil {
+		panic(fmt.Sprintf("invalid embedded migrations: %v", err))
+	}
+	return &Migrator{db: db, migrations: migrations}
+}
+
+// Load reads the migrations under migrations/ in fsys, sorted by version.
+// Every version needs both an up and a down file.
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	byVersion := map[int]*Migration{}
+	for _, entry := range entries {
+		match := fileName.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			return nil, fmt.Errorf("unexpected file %s", entry.Name())
+		}
+		version, _ := strconv.Atoi(match[1])
+		if version == 0 {
+			return nil, fmt.Errorf("%s: versions start at 1", entry.Name())
+		}
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("version %d is used by both %s and %s", version, m.Name, match[2])
+		}
+		body, err := fs.ReadFile(fsys, path.Join("migrations", entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		if match[3] == "up" {
+			m.Up = string(body)
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s needs both an up and a down file", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Latest returns the newest version this build knows about.
+func (m *Migrator) Latest() int {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+func logger(ctx context.Context) *slog.Logger {
+	return logging.FromContext(ctx).With("component", "migrate")
+}
+
+// Up applies every pending migration in order and returns how many ran.
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	applied := 0
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		done, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, mig := range m.migrations {
+			if _, ok := done[mig.Version]; ok {
+				continue
+			}
+			if err := apply(ctx, conn, mig.Up,
+				`INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`, mig.Version, mig.Name); err != nil {
+				return fmt.Errorf("migration %d_%s: %w", mig.Version, mig.Name, err)
+			}
+			logger(ctx).Info("applied migration", "version", mig.Version, "name", mig.Name)
+			applied++
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down rolls back the newest steps applied migrations and returns how many
+// were rolled back.
+func (m *Migrator) Down(ctx context.Context, steps int) (int, error) {
+	rolledBack := 0
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		done, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		known := map[int]Migration{}
+		for _, mig := range m.migrations {
+			known[mig.Version] = mig
+		}
+		versions := make([]int, 0, len(done))
+		for v := range done {
+			versions = append(versions, v)
+		}
+		sort.Sort(sort.Reverse(sort.IntSlice(versions)))
+
+		for _, v := range versions {
+			if rolledBack == steps {
+				break
+			}
+			mig, ok := known[v]
+			if !ok {
+				return fmt.Errorf("version %d was applied by a newer build and cannot be rolled back by this one", v)
+			}
+			if err := apply(ctx, conn, mig.Down,
+				`DELETE FROM schema_migrations WHERE version = $1`, mig.Version); err != nil {
+				return fmt.Errorf("rollback %d_%s: %w", mig.Version, mig.Name, err)
+			}
+			logger(ctx).Info("rolled back migration", "version", mig.Version, "name", mig.Name)
+			rolledBack++
+		}
+		return nil
+	})
+	return rolledBack, err
+}
+
+// Status lists every known migration and when it was applied. Versions
+// applied by a newer build are included without a name.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	done, err := appliedVersions(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, mig := range m.migrations {
+		st := Status{Version: mig.Version, Name: mig.Name}
+		if at, ok := done[mig.Version]; ok {
+			st.AppliedAt = &at
+			delete(done, mig.Version)
+		}
+		statuses = append(statuses, st)
+	}
+	for v, at := range done {
+		at := at
+		statuses = append(statuses, Status{Version: v, AppliedAt: &at})
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+	return statuses, nil
+}
+
+// Version returns the newest applied version, or 0 if none has been applied.
+func (m *Migrator) Version(ctx context.Context) (int, error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+	done, err := appliedVersions(ctx, conn)
+	if err != nil {
+		return 0, err
+	}
+	current := 0
+	for v := range done {
+		if v > current {
+			current = v
+		}
+	}
+	return current, nil
+}
+
+// CheckSchema reports an error if the database is behind this build. A
+// database ahead of it is accepted so older replicas stay ready during a
+// rolling deploy.
+func (m *Migrator) CheckSchema(ctx context.Context) error {
+	current, err := m.Version(ctx)
+	if err != nil {
+		return err
+	}
+	if current < m.Latest() {
+		return fmt.Errorf("schema version %d is behind %d: migrations pending", current, m.Latest())
+	}
+	return nil
+}
+
+// apply runs body and the bookkeeping statement in one transaction.
+func apply(ctx context.Context, conn *sql.Conn, body, record string, args ...interface{}) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, body); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, record, args...); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// withLock runs fn on a single connection holding the migration advisory
+// lock, creating the tracking table first if needed.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	start := time.Now()
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, lockID); err != nil {
+		return fmt.Errorf("acquire migration lock: %w", err)
+	}
+	logger(ctx).Debug("acquired migration lock", "waited", time.Since(start))
+	defer func() {
+		// Unlock even if ctx was cancelled; the session would otherwise keep
+		// the lock until the pooled connection is closed.
+		if _, err := conn.ExecContext(context.WithoutCancel(ctx), `SELECT pg_advisory_unlock($1)`, lockID); err != nil {
+			logger(ctx).Error("failed to release migration lock", "error", err)
+		}
+	}()
+
+	if _, err := conn.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version    BIGINT PRIMARY KEY,
+			name       TEXT NOT NULL,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		)
+	`); err != nil {
+		return fmt.Errorf("create schema_migrations: %w", err)
+	}
+	return fn(conn)
+}
+
+// appliedVersions returns the applied versions and when they were applied.
+// A missing tracking table means nothing has been applied yet.
+func appliedVersions(ctx context.Context, conn *sql.Conn) (map[int]time.Time, error) {
+	var exists bool
+	if err := conn.QueryRowContext(ctx, `SELECT to_regclass('schema_migrations') IS NOT NULL`).Scan(&exists); err != nil {
+		return nil, err
+	}
+	done := map[int]time.Time{}
+	if !exists {
+		return done, nil
+	}
+	rows, err := conn.QueryContext(ctx, `SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var v int
+		var at time.Time
+		if err := rows.Scan(&v, &at); err != nil {
+			return nil, err
+		}
+		done[v] = at
+	}
+	return done, rows.Err()
+}
@@ -0,0 +1,132 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	_ "github.com/lib/pq"
+)
+
+var testDSN = "host=localhost port=5432 user=testuser password=testpass dbname=testdb sslmode=disable"
+
+func setupTestDB(t *testing.T) *sql.DB {
+	db, err := sql.Open("postgres", testDSN)
+	if err != nil {
+		t.Fatalf("failed to open test db: %v", err)
+	}
+	if err := db.Ping(); err != nil {
+		t.Fatalf("failed to connect to test db: %v", err)
+	}
+	return db
+}
+
+func TestLoad_Embedded(t *testing.T) {
+	migrations, err := Load(embedded)
+	if err != nil {
+		t.Fatalf("embedded migrations are invalid: %v", err)
+	}
+	for i, m := range migrations {
+		if m.Version != i+1 {
+			t.Errorf("expected contiguous versions, got %d at position %d", m.Version, i)
+		}
+	}
+}
+
+func TestLoad_SortsByVersion(t *testing.T) {
+	fsys := fstest.MapFS{
+		"migrations/0002_second.up.sql":   {Data: []byte("B")},
+		"migrations/0002_second.down.sql": {Data: []byte("b")},
+		"migrations/0001_first.up.sql":    {Data: []byte("A")},
+		"migrations/0001_first.down.sql":  {Data: []byte("a")},
+	}
+	migrations, err := Load(fsys)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(migrations) != 2 || migrations[0].Name != "first" || migrations[1].Up != "B" || migrations[1].Down != "b" {
+		t.Errorf("unexpected migrations: %+v", migrations)
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	cases := map[string]fstest.MapFS{
+		"missing down": {
+			"migrations/0001_first.up.sql": {Data: []byte("A")},
+		},
+		"duplicate version": {
+			"migrations/0001_first.up.sql":   {Data: []byte("A")},
+			"migrations/0001_first.down.sql": {Data: []byte("a")},
+			"migrations/0001_other.up.sql":   {Data: []byte("B")},
+			"migrations/0001_other.down.sql": {Data: []byte("b")},
+		},
+		"bad name": {
+			"migrations/first.sql": {Data: []byte("A")},
+		},
+		"version zero": {
+			"migrations/0000_zero.up.sql":   {Data: []byte("A")},
+			"migrations/0000_zero.down.sql": {Data: []byte("a")},
+		},
+	}
+	for name, fsys := range cases {
+		if _, err := Load(fsys); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestUp_IsIdempotentAndConcurrencySafe(t *testing.T) {
+	db := setupTestDB(t)
+	m := New(db)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 3)
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := m.Up(context.Background())
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("concurrent Up failed: %v", err)
+		}
+	}
+
+	if n, err := m.Up(context.Background()); err != nil || n != 0 {
+		t.Errorf("expected nothing left to apply, got %d, %v", n, err)
+	}
+	if err := m.CheckSchema(context.Background()); err != nil {
+		t.Errorf("CheckSchema failed after Up: %v", err)
+	}
+	statuses, err := m.Status(context.Background())
+	if err != nil {
+		t.Fatalf("Status failed: %v", err)
+	}
+	for _, st := range statuses {
+		if st.AppliedAt == nil {
+			t.Errorf("expected migration %d to be applied", st.Version)
+		}
+	}
+}
+
+func TestCheckSchema_Behind(t *testing.T) {
+	db := setupTestDB(t)
+	m := New(db)
+	if _, err := m.Up(context.Background()); err != nil {
+		t.Fatalf("Up failed: %v", err)
+	}
+	// A build that knows one more migration than the database has.
+	m.migrations = append(m.migrations, Migration{Version: m.Latest() + 1, Name: "future", Up: "SELECT 1", Down: "SELECT 1"})
+	err := m.CheckSchema(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "behind") {
+		t.Errorf("expected schema to be reported behind, got %v", err)
+	}
+}
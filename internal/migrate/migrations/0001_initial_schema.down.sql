@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS player_competitions;
+DROP TABLE IF EXISTS competitions;
+DROP TABLE IF EXISTS players;
+DROP TYPE IF EXISTS player_status;
@@ -1,14 +1,19 @@
--- Enum for player status
-CREATE TYPE player_status AS ENUM ('WAITING', 'ACTIVE', 'COMPLETED', 'CANCELLED');
+-- Baseline schema. Written to be idempotent so databases that were created
+-- from the old initdb/schema.sql can adopt migrations without changes.
+DO $$
+BEGIN
+    CREATE TYPE player_status AS ENUM ('WAITING', 'ACTIVE', 'COMPLETED', 'CANCELLED');
+EXCEPTION
+    WHEN duplicate_object THEN NULL;
+END
+$$;
 
--- Players table
 CREATE TABLE IF NOT EXISTS players (
     player_id      TEXT PRIMARY KEY,
     level          INT NOT NULL,
     country_code   TEXT
 );
 
--- Competitions table
 CREATE TABLE IF NOT EXISTS competitions (
     competition_id UUID PRIMARY KEY,
     started_at     TIMESTAMP NOT NULL,
@@ -18,7 +23,6 @@ CREATE TABLE IF NOT EXISTS competitions (
     status         TEXT NOT NULL DEFAULT 'ACTIVE'
 );
 
--- Player competitions table
 CREATE TABLE IF NOT EXISTS player_competitions (
     id             SERIAL PRIMARY KEY,
     player_id      TEXT REFERENCES players(player_id),
@@ -33,4 +37,4 @@ CREATE TABLE IF NOT EXISTS player_competitions (
 
 CREATE INDEX IF NOT EXISTS idx_player_competitions_status ON player_competitions(status);
 CREATE INDEX IF NOT EXISTS idx_player_competitions_competition_id ON player_competitions(competition_id);
-CREATE INDEX IF NOT EXISTS idx_player_competitions_player_id ON player_competitions(player_id); 
\ No newline at end of file
+CREATE INDEX IF NOT EXISTS idx_player_competitions_player_id ON player_competitions(player_id);
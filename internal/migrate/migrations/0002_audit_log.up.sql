@@ -0,0 +1,24 @@
+-- Append-only record of administrative changes.
+CREATE TABLE audit_log (
+    id          BIGSERIAL PRIMARY KEY,
+    occurred_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    actor       TEXT NOT NULL,
+    action      TEXT NOT NULL,
+    target      TEXT NOT NULL,
+    reason      TEXT NOT NULL DEFAULT '',
+    before      JSONB,
+    after       JSONB
+);
+
+CREATE INDEX idx_audit_log_action ON audit_log(action, occurred_at DESC);
+CREATE INDEX idx_audit_log_target ON audit_log(target, occurred_at DESC);
+
+CREATE FUNCTION audit_log_immutable() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_log_immutable
+    BEFORE UPDATE OR DELETE ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION audit_log_immutable();
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_competitions_started_at;
+
+-- Enum values cannot be dropped, so rebuild the type without them.
+UPDATE player_competitions SET status = 'CANCELLED' WHERE status IN ('REMOVED', 'DISQUALIFIED');
+ALTER TYPE player_status RENAME TO player_status_old;
+CREATE TYPE player_status AS ENUM ('WAITING', 'ACTIVE', 'COMPLETED', 'CANCELLED');
+ALTER TABLE player_competitions ALTER COLUMN status TYPE player_status USING status::text::player_status;
+DROP TYPE player_status_old;
@@ -0,0 +1,6 @@
+-- Players taken out of a competition by an admin keep their row for the
+-- record but no longer count towards its leaderboard.
+ALTER TYPE player_status ADD VALUE IF NOT EXISTS 'REMOVED';
+ALTER TYPE player_status ADD VALUE IF NOT EXISTS 'DISQUALIFIED';
+
+CREATE INDEX idx_competitions_started_at ON competitions(started_at DESC);
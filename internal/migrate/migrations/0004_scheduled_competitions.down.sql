@@ -0,0 +1,15 @@
+DROP INDEX IF EXISTS idx_competitions_status;
+
+UPDATE competitions SET status = 'CANCELLED' WHERE status IN ('SCHEDULED', 'OPEN');
+ALTER TABLE competitions
+    DROP COLUMN IF EXISTS name,
+    DROP COLUMN IF EXISTS kind,
+    DROP COLUMN IF EXISTS registration_opens_at,
+    DROP COLUMN IF EXISTS max_players;
+
+-- Enum values cannot be dropped, so rebuild the type without REGISTERED.
+UPDATE player_competitions SET status = 'CANCELLED' WHERE status = 'REGISTERED';
+ALTER TYPE player_status RENAME TO player_status_old;
+CREATE TYPE player_status AS ENUM ('WAITING', 'ACTIVE', 'COMPLETED', 'CANCELLED', 'REMOVED', 'DISQUALIFIED');
+ALTER TABLE player_competitions ALTER COLUMN status TYPE player_status USING status::text::player_status;
+DROP TYPE player_status_old;
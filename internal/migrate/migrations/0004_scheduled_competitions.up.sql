@@ -0,0 +1,11 @@
+-- Scheduled competitions are created by an admin with fixed times; players
+-- register for them explicitly and are REGISTERED until they start.
+ALTER TYPE player_status ADD VALUE IF NOT EXISTS 'REGISTERED';
+
+ALTER TABLE competitions
+    ADD COLUMN name                  TEXT NOT NULL DEFAULT '',
+    ADD COLUMN kind                  TEXT NOT NULL DEFAULT 'MATCHMAKING',
+    ADD COLUMN registration_opens_at TIMESTAMP,
+    ADD COLUMN max_players           INT NOT NULL DEFAULT 0;
+
+CREATE INDEX idx_competitions_status ON competitions(status);
@@ -0,0 +1,12 @@
+DROP INDEX IF EXISTS idx_competitions_template_id;
+
+UPDATE competitions SET kind = 'SCHEDULED' WHERE kind = 'RECURRING';
+ALTER TABLE competitions
+    DROP COLUMN IF EXISTS template_id,
+    DROP COLUMN IF EXISTS scoring_mode,
+    DROP COLUMN IF EXISTS min_level,
+    DROP COLUMN IF EXISTS max_level,
+    DROP COLUMN IF EXISTS countries,
+    DROP COLUMN IF EXISTS rewards;
+
+DROP TABLE IF EXISTS competition_templates;
@@ -0,0 +1,32 @@
+-- Templates describe recurring competitions; the worker starts a competition
+-- from a template at each occurrence of its schedule.
+CREATE TABLE competition_templates (
+    template_id      UUID PRIMARY KEY,
+    name             TEXT NOT NULL,
+    schedule         TEXT NOT NULL,
+    duration_seconds BIGINT NOT NULL,
+    scoring_mode     TEXT NOT NULL DEFAULT 'SUM',
+    min_level        INT NOT NULL DEFAULT 0,
+    max_level        INT NOT NULL DEFAULT 0,
+    countries        TEXT[] NOT NULL DEFAULT '{}',
+    rewards          JSONB NOT NULL DEFAULT '[]',
+    max_players      INT NOT NULL DEFAULT 0,
+    enabled          BOOLEAN NOT NULL DEFAULT TRUE,
+    next_run_at      TIMESTAMP,
+    created_at       TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at       TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_competition_templates_next_run_at ON competition_templates(next_run_at) WHERE enabled;
+
+-- Competitions carry their own copy of the template's rules so that editing
+-- a template does not change competitions already started from it.
+ALTER TABLE competitions
+    ADD COLUMN template_id  UUID REFERENCES competition_templates(template_id) ON DELETE SET NULL,
+    ADD COLUMN scoring_mode TEXT NOT NULL DEFAULT 'SUM',
+    ADD COLUMN min_level    INT NOT NULL DEFAULT 0,
+    ADD COLUMN max_level    INT NOT NULL DEFAULT 0,
+    ADD COLUMN countries    TEXT[] NOT NULL DEFAULT '{}',
+    ADD COLUMN rewards      JSONB NOT NULL DEFAULT '[]';
+
+CREATE INDEX idx_competitions_template_id ON competitions(template_id, status);
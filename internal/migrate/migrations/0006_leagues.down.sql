@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS player_tier_history;
+
+ALTER TABLE competitions DROP COLUMN IF EXISTS tier;
+ALTER TABLE player_competitions DROP COLUMN IF EXISTS tier;
+ALTER TABLE players DROP COLUMN IF EXISTS tier;
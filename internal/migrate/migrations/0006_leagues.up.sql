@@ -0,0 +1,26 @@
+-- Every player belongs to a league tier. Matchmaking groups players of the
+-- same tier, and a competition's results promote or relegate its players.
+ALTER TABLE players ADD COLUMN tier TEXT NOT NULL DEFAULT 'BRONZE';
+
+-- Like level and country_code, a waiting entry records the player's tier
+-- when they joined.
+ALTER TABLE player_competitions ADD COLUMN tier TEXT NOT NULL DEFAULT '';
+UPDATE player_competitions SET tier = 'BRONZE' WHERE status = 'WAITING';
+
+-- The tier a matchmaking competition was formed in; empty for competitions
+-- that do not move players between tiers.
+ALTER TABLE competitions ADD COLUMN tier TEXT NOT NULL DEFAULT '';
+
+CREATE TABLE player_tier_history (
+    id             SERIAL PRIMARY KEY,
+    player_id      TEXT NOT NULL REFERENCES players(player_id),
+    competition_id UUID NOT NULL REFERENCES competitions(competition_id),
+    from_tier      TEXT NOT NULL,
+    to_tier        TEXT NOT NULL,
+    rank           INT NOT NULL,
+    occurred_at    TIMESTAMP NOT NULL DEFAULT NOW(),
+    -- A competition moves each of its players at most once.
+    UNIQUE (player_id, competition_id)
+);
+
+CREATE INDEX idx_player_tier_history_player_id ON player_tier_history(player_id, occurred_at DESC);
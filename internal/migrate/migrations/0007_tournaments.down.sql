@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS tournament_matches;
+DROP TABLE IF EXISTS tournaments;
+
+UPDATE competitions SET kind = 'SCHEDULED' WHERE kind = 'TOURNAMENT';
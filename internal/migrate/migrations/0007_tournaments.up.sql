@@ -0,0 +1,30 @@
+-- Tournaments are played as a series of competitions, their matches, in
+-- rounds; the worker starts each round once the previous one is over.
+CREATE TABLE tournaments (
+    tournament_id          UUID PRIMARY KEY,
+    name                   TEXT NOT NULL,
+    format                 TEXT NOT NULL,
+    seeding                TEXT NOT NULL,
+    seeds                  TEXT[] NOT NULL,
+    group_size             INT NOT NULL,
+    advance                INT NOT NULL DEFAULT 0,
+    round_duration_seconds BIGINT NOT NULL,
+    round                  INT NOT NULL DEFAULT 1,
+    rounds                 INT NOT NULL,
+    status                 TEXT NOT NULL DEFAULT 'ACTIVE',
+    winners                TEXT[] NOT NULL DEFAULT '{}',
+    created_at             TIMESTAMP NOT NULL DEFAULT NOW(),
+    completed_at           TIMESTAMP
+);
+
+CREATE INDEX idx_tournaments_status ON tournaments(status);
+
+CREATE TABLE tournament_matches (
+    tournament_id  UUID NOT NULL REFERENCES tournaments(tournament_id) ON DELETE CASCADE,
+    round          INT NOT NULL,
+    slot           INT NOT NULL,
+    group_index    INT NOT NULL DEFAULT 0,
+    competition_id UUID NOT NULL UNIQUE REFERENCES competitions(competition_id),
+    players        TEXT[] NOT NULL,
+    PRIMARY KEY (tournament_id, round, slot)
+);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_player_competitions_party_id;
+ALTER TABLE player_competitions DROP COLUMN IF EXISTS party_id;
+
+DROP TABLE IF EXISTS party_invites;
+DROP TABLE IF EXISTS party_members;
+DROP TABLE IF EXISTS parties;
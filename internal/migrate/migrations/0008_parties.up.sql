@@ -0,0 +1,28 @@
+-- Players form parties to queue for matchmaking together.
+CREATE TABLE parties (
+    party_id   UUID PRIMARY KEY,
+    leader_id  TEXT NOT NULL REFERENCES players(player_id),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- A player is in at most one party.
+CREATE TABLE party_members (
+    player_id TEXT PRIMARY KEY REFERENCES players(player_id),
+    party_id  UUID NOT NULL REFERENCES parties(party_id) ON DELETE CASCADE,
+    joined_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_party_members_party_id ON party_members(party_id, joined_at);
+
+CREATE TABLE party_invites (
+    party_id   UUID NOT NULL REFERENCES parties(party_id) ON DELETE CASCADE,
+    player_id  TEXT NOT NULL REFERENCES players(player_id),
+    invited_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (party_id, player_id)
+);
+
+-- The waiting entries of a queued party carry its ID, so matchmaking can
+-- keep its members together. Not a foreign key: entries outlive parties.
+ALTER TABLE player_competitions ADD COLUMN party_id UUID;
+
+CREATE INDEX idx_player_competitions_party_id ON player_competitions(party_id) WHERE status = 'WAITING';
@@ -0,0 +1,12 @@
+-- A player follows their friends. Friendship is one-way, so a player can
+-- follow someone who does not follow them back.
+CREATE TABLE player_friends (
+    player_id  TEXT NOT NULL REFERENCES players(player_id) ON DELETE CASCADE,
+    friend_id  TEXT NOT NULL REFERENCES players(player_id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (player_id, friend_id),
+    CHECK (player_id <> friend_id)
+);
+
+CREATE INDEX idx_player_friends_friend_id ON player_friends(friend_id);
+
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_players_country_code;
+DROP TABLE IF EXISTS region_countries;
+DROP TABLE IF EXISTS regions;
@@ -0,0 +1,34 @@
+-- Regions group countries for regional leaderboards. A country may belong
+-- to several regions.
+CREATE TABLE regions (
+    region_code TEXT PRIMARY KEY,
+    name        TEXT NOT NULL,
+    updated_at  TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE region_countries (
+    region_code  TEXT NOT NULL REFERENCES regions(region_code) ON DELETE CASCADE,
+    country_code TEXT NOT NULL,
+    PRIMARY KEY (region_code, country_code)
+);
+
+CREATE INDEX idx_region_countries_country_code ON region_countries(country_code);
+
+INSERT INTO regions (region_code, name) VALUES
+    ('EU', 'European Union'),
+    ('NA', 'North America'),
+    ('APAC', 'Asia-Pacific');
+
+INSERT INTO region_countries (region_code, country_code)
+SELECT 'EU', unnest(ARRAY['AT', 'BE', 'BG', 'CY', 'CZ', 'DE', 'DK', 'EE', 'ES', 'FI', 'FR', 'GR', 'HR', 'HU', 'IE', 'IT',
+    'LT', 'LU', 'LV', 'MT', 'NL', 'PL', 'PT', 'RO', 'SE', 'SI', 'SK'])
+UNION ALL
+SELECT 'NA', unnest(ARRAY['CA', 'MX', 'US'])
+UNION ALL
+SELECT 'APAC', unnest(ARRAY['AU', 'BD', 'CN', 'HK', 'ID', 'IN', 'JP', 'KH', 'KR', 'LK', 'MM', 'MN', 'MO', 'MY', 'NP', 'NZ',
+    'PH', 'PK', 'SG', 'TH', 'TW', 'VN']);
+
+-- Country codes are stored upper-case; leaderboards filter on them.
+UPDATE players SET country_code = UPPER(TRIM(country_code)) WHERE country_code <> UPPER(TRIM(country_code));
+
+CREATE INDEX idx_players_country_code ON players(country_code);
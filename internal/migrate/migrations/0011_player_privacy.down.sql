@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_players_anonymized_at;
+
+ALTER TABLE players
+    DROP COLUMN IF EXISTS purged_at,
+    DROP COLUMN IF EXISTS anonymized_at;
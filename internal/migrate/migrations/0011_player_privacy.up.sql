@@ -0,0 +1,9 @@
+-- A deleted player lives on under a random pseudonym, so that their scores
+-- still count on other players' leaderboards. The purge job strips what is
+-- left once the retention period has passed.
+ALTER TABLE players
+    ADD COLUMN anonymized_at TIMESTAMP,
+    ADD COLUMN purged_at     TIMESTAMP;
+
+CREATE INDEX idx_players_anonymized_at ON players(anonymized_at)
+    WHERE anonymized_at IS NOT NULL AND purged_at IS NULL;
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_players_shadow_banned;
+
+ALTER TABLE players
+    DROP COLUMN IF EXISTS shadow_banned_at,
+    DROP COLUMN IF EXISTS suspended_until,
+    DROP COLUMN IF EXISTS banned_at;
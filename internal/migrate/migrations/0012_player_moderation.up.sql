@@ -0,0 +1,9 @@
+-- Sanctions an admin has put on a player. The reasons are kept in the
+-- audit log with the admin action that set them.
+ALTER TABLE players
+    ADD COLUMN banned_at        TIMESTAMP,
+    ADD COLUMN suspended_until  TIMESTAMP,
+    ADD COLUMN shadow_banned_at TIMESTAMP;
+
+CREATE INDEX idx_players_shadow_banned ON players(player_id)
+    WHERE shadow_banned_at IS NOT NULL;
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_players_display_name;
+
+ALTER TABLE players
+    DROP COLUMN IF EXISTS version,
+    DROP COLUMN IF EXISTS updated_at,
+    DROP COLUMN IF EXISTS created_at,
+    DROP COLUMN IF EXISTS metadata,
+    DROP COLUMN IF EXISTS avatar_url,
+    DROP COLUMN IF EXISTS display_name;
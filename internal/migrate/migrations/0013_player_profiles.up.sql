@@ -0,0 +1,15 @@
+-- What a player shows on leaderboards besides their ID, and free-form
+-- metadata the game keeps about them. version is bumped on every profile
+-- update, so that clients can update against the version they last read.
+-- Players created before this migration get its run time as created_at.
+ALTER TABLE players
+    ADD COLUMN display_name TEXT,
+    ADD COLUMN avatar_url   TEXT,
+    ADD COLUMN metadata     JSONB,
+    ADD COLUMN created_at   TIMESTAMP NOT NULL DEFAULT NOW(),
+    ADD COLUMN updated_at   TIMESTAMP NOT NULL DEFAULT NOW(),
+    ADD COLUMN version      INT NOT NULL DEFAULT 1;
+
+-- Display names are unique regardless of case.
+CREATE UNIQUE INDEX idx_players_display_name ON players(LOWER(display_name))
+    WHERE display_name IS NOT NULL;
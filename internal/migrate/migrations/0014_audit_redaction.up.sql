@@ -0,0 +1,13 @@
+-- The audit log stays append-only, except that deleting a player redacts
+-- their ID from it: a transaction that sets audit_log.redact may rewrite
+-- the target, reason and before and after of an entry, nothing else.
+CREATE OR REPLACE FUNCTION audit_log_immutable() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'UPDATE' AND current_setting('audit_log.redact', true) = 'on'
+        AND NEW.id = OLD.id AND NEW.occurred_at = OLD.occurred_at
+        AND NEW.actor = OLD.actor AND NEW.action = OLD.action THEN
+        RETURN NEW;
+    END IF;
+    RAISE EXCEPTION 'audit_log is append-only';
+END
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_competitions_unsettled;
+
+ALTER TABLE competitions DROP COLUMN IF EXISTS settled_at;
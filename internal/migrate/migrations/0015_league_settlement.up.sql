@@ -0,0 +1,9 @@
+-- When a league competition's promotions and relegations were applied. The
+-- worker settles every completed league competition still without one, so
+-- a settlement that fails or is interrupted is retried on the next pass.
+ALTER TABLE competitions ADD COLUMN settled_at TIMESTAMP;
+
+-- League competitions completed before now were settled as they completed.
+UPDATE competitions SET settled_at = ends_at WHERE tier <> '' AND status = 'COMPLETED';
+
+CREATE INDEX idx_competitions_unsettled ON competitions(ends_at) WHERE tier <> '' AND status = 'COMPLETED' AND settled_at IS NULL;
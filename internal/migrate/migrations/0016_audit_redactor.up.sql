@@ -0,0 +1,66 @@
+-- Redacting a deleted player from the audit log no longer trusts a session
+-- setting any client could set. The update is done by redact_audit_log,
+-- which runs as the audit_redactor role, and the append-only trigger only
+-- lets that role through.
+DO $$
+BEGIN
+    CREATE ROLE audit_redactor NOLOGIN;
+EXCEPTION
+    WHEN duplicate_object THEN NULL;
+END
+$$;
+
+GRANT SELECT, UPDATE ON audit_log TO audit_redactor;
+
+-- redact_audit_log replaces player ID $1 with alias $2 in the audit log: in
+-- the targets of the entries about the player, where their reasons mention
+-- the ID as a whole token, and in the JSON strings of every entry's before
+-- and after. Other entries' free-text reasons are left alone.
+CREATE OR REPLACE FUNCTION redact_audit_log(TEXT, TEXT) RETURNS void AS $$
+    WITH id AS (
+        -- The ID as a regular expression that only matches it whole.
+        SELECT '(?<![[:alnum:]_-])' || regexp_replace($1, '([\\.^$|?*+()\[\]{}-])', '\\\1', 'g') || '(?![[:alnum:]_-])' AS pattern
+    )
+    UPDATE audit_log SET
+        target = CASE
+            WHEN target = 'player/' || $1 THEN 'player/' || $2
+            WHEN right(target, length($1) + 8) = '/player/' || $1 THEN left(target, -length($1)) || $2
+            ELSE target END,
+        reason = CASE
+            WHEN target = 'player/' || $1 OR right(target, length($1) + 8) = '/player/' || $1
+            THEN regexp_replace(reason, (SELECT pattern FROM id), $2, 'g')
+            ELSE reason END,
+        before = replace(before::text, to_jsonb($1)::text, to_jsonb($2)::text)::jsonb,
+        after = replace(after::text, to_jsonb($1)::text, to_jsonb($2)::text)::jsonb
+    WHERE target = 'player/' || $1
+       OR right(target, length($1) + 8) = '/player/' || $1
+       OR strpos(before::text, to_jsonb($1)::text) > 0
+       OR strpos(after::text, to_jsonb($1)::text) > 0
+$$ LANGUAGE sql SECURITY DEFINER SET search_path FROM CURRENT;
+
+-- Only the service may redact, and the function runs as audit_redactor.
+-- Handing the function over takes membership of the role, which is dropped
+-- again so that no client can act as audit_redactor directly.
+REVOKE ALL ON FUNCTION redact_audit_log(TEXT, TEXT) FROM PUBLIC;
+DO $$
+BEGIN
+    EXECUTE format('GRANT EXECUTE ON FUNCTION redact_audit_log(TEXT, TEXT) TO %I', current_user);
+    EXECUTE format('GRANT audit_redactor TO %I', current_user);
+    ALTER FUNCTION redact_audit_log(TEXT, TEXT) OWNER TO audit_redactor;
+    EXECUTE format('REVOKE audit_redactor FROM %I', current_user);
+END
+$$;
+
+-- The audit log stays append-only, except that redact_audit_log may
+-- rewrite the target, reason and before and after of an entry, nothing
+-- else.
+CREATE OR REPLACE FUNCTION audit_log_immutable() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'UPDATE' AND current_user = 'audit_redactor'
+        AND NEW.id = OLD.id AND NEW.occurred_at = OLD.occurred_at
+        AND NEW.actor = OLD.actor AND NEW.action = OLD.action THEN
+        RETURN NEW;
+    END IF;
+    RAISE EXCEPTION 'audit_log is append-only';
+END
+$$ LANGUAGE plpgsql;
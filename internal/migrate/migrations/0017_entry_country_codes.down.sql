@@ -0,0 +1 @@
+-- Normalized and cleared country codes are not restored.
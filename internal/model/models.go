@@ -1,6 +1,7 @@
 package model
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
@@ -10,6 +11,71 @@ type Player struct {
 	PlayerID    string `db:"player_id"`
 	Level       int    `db:"level"`
 	CountryCode string `db:"country_code"`
+	Tier        Tier   `db:"tier"`
+	// DisplayName is the name shown on leaderboards, unique regardless of
+	// case; empty if the player has not chosen one.
+	DisplayName string `db:"display_name"`
+	AvatarURL   string `db:"avatar_url"`
+	// Metadata is a JSON object the game keeps about the player; the
+	// service does not look inside it.
+	Metadata  json.RawMessage `db:"metadata"`
+	CreatedAt time.Time       `db:"created_at"`
+	UpdatedAt time.Time       `db:"updated_at"`
+	// Version is bumped by every update of the player's profile.
+	Version int `db:"version"`
+}
+
+// Tier is a player's league. Matchmaking only groups players of the same
+// tier, and competition results move players between tiers.
+type Tier string
+
+const (
+	TierBronze Tier = "BRONZE"
+	TierSilver Tier = "SILVER"
+	TierGold   Tier = "GOLD"
+)
+
+// Tiers lists the tiers from lowest to highest. New players start in the
+// lowest.
+var Tiers = []Tier{TierBronze, TierSilver, TierGold}
+
+func (t Tier) index() int {
+	for i, tier := range Tiers {
+		if tier == t {
+			return i
+		}
+	}
+	return -1
+}
+
+// Above returns the tier players are promoted to from t, or "" if t is the
+// highest or not a tier.
+func (t Tier) Above() Tier {
+	if i := t.index(); i >= 0 && i < len(Tiers)-1 {
+		return Tiers[i+1]
+	}
+	return ""
+}
+
+// Below returns the tier players are relegated to from t, or "" if t is
+// the lowest or not a tier.
+func (t Tier) Below() Tier {
+	if i := t.index(); i > 0 {
+		return Tiers[i-1]
+	}
+	return ""
+}
+
+// TierMovement records a player's promotion or relegation after a
+// competition.
+type TierMovement struct {
+	PlayerID      string    `db:"player_id" json:"player_id"`
+	CompetitionID uuid.UUID `db:"competition_id" json:"competition_id"`
+	FromTier      Tier      `db:"from_tier" json:"from_tier"`
+	ToTier        Tier      `db:"to_tier" json:"to_tier"`
+	// Rank is the player's final position in the competition.
+	Rank       int       `db:"rank" json:"rank"`
+	OccurredAt time.Time `db:"occurred_at" json:"occurred_at"`
 }
 
 type CompetitionStatus string
@@ -18,15 +84,283 @@ const (
 	CompetitionActive    CompetitionStatus = "ACTIVE"
 	CompetitionCompleted CompetitionStatus = "COMPLETED"
 	CompetitionCancelled CompetitionStatus = "CANCELLED"
+	// CompetitionScheduled and CompetitionOpen are the states of a scheduled
+	// competition before it starts: before and after registration opens.
+	CompetitionScheduled CompetitionStatus = "SCHEDULED"
+	CompetitionOpen      CompetitionStatus = "OPEN"
+)
+
+// CompetitionKind tells how a competition was created.
+type CompetitionKind string
+
+const (
+	CompetitionKindMatchmaking CompetitionKind = "MATCHMAKING"
+	CompetitionKindScheduled   CompetitionKind = "SCHEDULED"
+	CompetitionKindRecurring   CompetitionKind = "RECURRING"
+	// CompetitionKindTournament is one match of a tournament.
+	CompetitionKindTournament CompetitionKind = "TOURNAMENT"
 )
 
+// ScoringMode tells how a player's submissions make up their score.
+type ScoringMode string
+
+const (
+	// ScoringSum adds every submission to the score.
+	ScoringSum ScoringMode = "SUM"
+	// ScoringBest keeps the best single submission.
+	ScoringBest ScoringMode = "BEST"
+)
+
+// Eligibility restricts who can enter a competition. Zero fields allow
+// everyone.
+type Eligibility struct {
+	MinLevel  int      `json:"min_level,omitempty"`
+	MaxLevel  int      `json:"max_level,omitempty"`
+	Countries []string `json:"countries,omitempty"`
+}
+
+// Allows reports whether player meets e.
+func (e Eligibility) Allows(player *Player) bool {
+	if player.Level < e.MinLevel || (e.MaxLevel > 0 && player.Level > e.MaxLevel) {
+		return false
+	}
+	if len(e.Countries) == 0 {
+		return true
+	}
+	for _, c := range e.Countries {
+		if c == player.CountryCode {
+			return true
+		}
+	}
+	return false
+}
+
+// Reward is what players finishing between FromRank and ToRank, inclusive,
+// receive.
+type Reward struct {
+	FromRank int    `json:"from_rank"`
+	ToRank   int    `json:"to_rank"`
+	Reward   string `json:"reward"`
+}
+
 type Competition struct {
-	CompetitionID uuid.UUID         `db:"competition_id"`
-	StartedAt     time.Time         `db:"started_at"`
-	EndsAt        time.Time         `db:"ends_at"`
-	Level         int               `db:"level"`
-	CountryCode   string            `db:"country_code"`
-	Status        CompetitionStatus `db:"status"`
+	CompetitionID uuid.UUID         `db:"competition_id" json:"competition_id"`
+	Name          string            `db:"name" json:"name,omitempty"`
+	Kind          CompetitionKind   `db:"kind" json:"kind"`
+	StartedAt     time.Time         `db:"started_at" json:"started_at"`
+	EndsAt        time.Time         `db:"ends_at" json:"ends_at"`
+	Level         int               `db:"level" json:"level"`
+	CountryCode   string            `db:"country_code" json:"country_code"`
+	Status        CompetitionStatus `db:"status" json:"status"`
+	// RegistrationOpensAt is when players can start registering for a
+	// scheduled competition; nil for matchmaking competitions.
+	RegistrationOpensAt *time.Time `db:"registration_opens_at" json:"registration_opens_at,omitempty"`
+	// MaxPlayers caps registrations for a scheduled competition; 0 means no
+	// limit.
+	MaxPlayers int `db:"max_players" json:"max_players,omitempty"`
+	// TemplateID is the template a recurring competition was created from;
+	// nil for other kinds.
+	TemplateID  *uuid.UUID  `db:"template_id" json:"template_id,omitempty"`
+	ScoringMode ScoringMode `db:"scoring_mode" json:"scoring_mode"`
+	Eligibility Eligibility `db:"-" json:"eligibility"`
+	Rewards     []Reward    `db:"rewards" json:"rewards,omitempty"`
+	// Tier is the league of a matchmaking competition's players; its results
+	// promote and relegate them. Empty for other competitions.
+	Tier Tier `db:"tier" json:"tier,omitempty"`
+}
+
+// CompetitionTemplate describes a recurring competition. The worker starts
+// a competition from it at every occurrence of Schedule, completing the
+// previous one if it is still running.
+type CompetitionTemplate struct {
+	TemplateID uuid.UUID `db:"template_id"`
+	Name       string    `db:"name"`
+	// Schedule is a cron expression or "@every <duration>"; see package
+	// recurrence.
+	Schedule    string        `db:"schedule"`
+	Duration    time.Duration `db:"duration_seconds"`
+	ScoringMode ScoringMode   `db:"scoring_mode"`
+	Eligibility Eligibility   `db:"-"`
+	Rewards     []Reward      `db:"rewards"`
+	MaxPlayers  int           `db:"max_players"`
+	Enabled     bool          `db:"enabled"`
+	// NextRunAt is the next occurrence the worker will start; nil when the
+	// template is disabled or its schedule has no further occurrences.
+	NextRunAt *time.Time `db:"next_run_at"`
+	CreatedAt time.Time  `db:"created_at"`
+	UpdatedAt time.Time  `db:"updated_at"`
+}
+
+// competitionTemplateJSON is the wire form of CompetitionTemplate, with the
+// duration as a Go duration string.
+type competitionTemplateJSON struct {
+	TemplateID  uuid.UUID   `json:"template_id"`
+	Name        string      `json:"name"`
+	Schedule    string      `json:"schedule"`
+	Duration    string      `json:"duration"`
+	ScoringMode ScoringMode `json:"scoring_mode"`
+	Eligibility Eligibility `json:"eligibility"`
+	Rewards     []Reward    `json:"rewards,omitempty"`
+	MaxPlayers  int         `json:"max_players,omitempty"`
+	Enabled     bool        `json:"enabled"`
+	NextRunAt   *time.Time  `json:"next_run_at,omitempty"`
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+}
+
+func (t CompetitionTemplate) MarshalJSON() ([]byte, error) {
+	return json.Marshal(competitionTemplateJSON{
+		TemplateID:  t.TemplateID,
+		Name:        t.Name,
+		Schedule:    t.Schedule,
+		Duration:    t.Duration.String(),
+		ScoringMode: t.ScoringMode,
+		Eligibility: t.Eligibility,
+		Rewards:     t.Rewards,
+		MaxPlayers:  t.MaxPlayers,
+		Enabled:     t.Enabled,
+		NextRunAt:   t.NextRunAt,
+		CreatedAt:   t.CreatedAt,
+		UpdatedAt:   t.UpdatedAt,
+	})
+}
+
+// TournamentFormat tells how a tournament's matches are drawn up.
+type TournamentFormat string
+
+const (
+	// TournamentSingleElimination plays rounds of matches in which the top
+	// Advance players of each match go through to the next round, until one
+	// final decides the winner.
+	TournamentSingleElimination TournamentFormat = "SINGLE_ELIMINATION"
+	// TournamentRoundRobin splits the players into groups in which everyone
+	// plays everyone else head to head, one match per round.
+	TournamentRoundRobin TournamentFormat = "ROUND_ROBIN"
+)
+
+// Seeding tells how a tournament ranks its players before the first round.
+type Seeding string
+
+const (
+	// SeedByLevel ranks players by level, highest first.
+	SeedByLevel Seeding = "LEVEL"
+	// SeedByTier ranks players by league tier, then level.
+	SeedByTier Seeding = "TIER"
+	// SeedManual keeps the order the players were given in.
+	SeedManual Seeding = "MANUAL"
+)
+
+type TournamentStatus string
+
+const (
+	TournamentActive    TournamentStatus = "ACTIVE"
+	TournamentCompleted TournamentStatus = "COMPLETED"
+)
+
+// Tournament is a series of competitions, its matches, played in rounds.
+// The matchmaking worker starts each round once the previous one is over.
+type Tournament struct {
+	TournamentID uuid.UUID        `db:"tournament_id"`
+	Name         string           `db:"name"`
+	Format       TournamentFormat `db:"format"`
+	Seeding      Seeding          `db:"seeding"`
+	// Seeds lists the players, best seed first.
+	Seeds []string `db:"seeds"`
+	// GroupSize is the most players in a single-elimination match or a
+	// round-robin group.
+	GroupSize int `db:"group_size"`
+	// Advance is how many players of each single-elimination match go
+	// through to the next round; 0 for round robin.
+	Advance       int              `db:"advance"`
+	RoundDuration time.Duration    `db:"round_duration_seconds"`
+	Round         int              `db:"round"`
+	Rounds        int              `db:"rounds"`
+	Status        TournamentStatus `db:"status"`
+	// Winners holds the final's winner, or each round-robin group's leader,
+	// once the tournament has completed.
+	Winners     []string   `db:"winners"`
+	CreatedAt   time.Time  `db:"created_at"`
+	CompletedAt *time.Time `db:"completed_at"`
+}
+
+// tournamentJSON is the wire form of Tournament, with the round duration as
+// a Go duration string.
+type tournamentJSON struct {
+	TournamentID  uuid.UUID        `json:"tournament_id"`
+	Name          string           `json:"name"`
+	Format        TournamentFormat `json:"format"`
+	Seeding       Seeding          `json:"seeding"`
+	Seeds         []string         `json:"seeds"`
+	GroupSize     int              `json:"group_size"`
+	Advance       int              `json:"advance,omitempty"`
+	RoundDuration string           `json:"round_duration"`
+	Round         int              `json:"round"`
+	Rounds        int              `json:"rounds"`
+	Status        TournamentStatus `json:"status"`
+	Winners       []string         `json:"winners,omitempty"`
+	CreatedAt     time.Time        `json:"created_at"`
+	CompletedAt   *time.Time       `json:"completed_at,omitempty"`
+}
+
+func (t Tournament) MarshalJSON() ([]byte, error) {
+	return json.Marshal(tournamentJSON{
+		TournamentID:  t.TournamentID,
+		Name:          t.Name,
+		Format:        t.Format,
+		Seeding:       t.Seeding,
+		Seeds:         t.Seeds,
+		GroupSize:     t.GroupSize,
+		Advance:       t.Advance,
+		RoundDuration: t.RoundDuration.String(),
+		Round:         t.Round,
+		Rounds:        t.Rounds,
+		Status:        t.Status,
+		Winners:       t.Winners,
+		CreatedAt:     t.CreatedAt,
+		CompletedAt:   t.CompletedAt,
+	})
+}
+
+// TournamentMatch is the competition played by some of a tournament's
+// players in one round.
+type TournamentMatch struct {
+	TournamentID uuid.UUID `db:"tournament_id"`
+	Round        int       `db:"round"`
+	// Slot numbers the matches of a round from 0. The winners of
+	// single-elimination match s play on in slot s / (GroupSize / Advance)
+	// of the next round.
+	Slot int `db:"slot"`
+	// Group is the round-robin group the match belongs to.
+	Group         int       `db:"group_index"`
+	CompetitionID uuid.UUID `db:"competition_id"`
+	// Players lists the match's players, best seed first.
+	Players []string `db:"players"`
+	// Status and EndsAt are the competition's.
+	Status CompetitionStatus `db:"status"`
+	EndsAt time.Time         `db:"ends_at"`
+}
+
+// CompetitionFilter narrows a competition listing. Zero fields match
+// everything; From and To select competitions running at any time in
+// [From, To).
+type CompetitionFilter struct {
+	Status      CompetitionStatus
+	Level       *int
+	CountryCode string
+	From        time.Time
+	To          time.Time
+	Limit       int
+}
+
+// PlayerHistoryFilter narrows a player's competition history. Zero fields
+// match everything; From and To select competitions running at any time in
+// [From, To). Limit and Offset page through the history, newest first.
+type PlayerHistoryFilter struct {
+	Status CompetitionStatus
+	From   time.Time
+	To     time.Time
+	Limit  int
+	Offset int
 }
 
 type PlayerStatus string
@@ -36,6 +370,13 @@ const (
 	StatusActive    PlayerStatus = "ACTIVE"
 	StatusCompleted PlayerStatus = "COMPLETED"
 	StatusCancelled PlayerStatus = "CANCELLED"
+	// StatusRegistered marks a player signed up for a scheduled competition
+	// that has not started yet.
+	StatusRegistered PlayerStatus = "REGISTERED"
+	// StatusRemoved and StatusDisqualified mark players taken out of a
+	// competition by an admin; their scores are left out of its leaderboard.
+	StatusRemoved      PlayerStatus = "REMOVED"
+	StatusDisqualified PlayerStatus = "DISQUALIFIED"
 )
 
 type PlayerCompetition struct {
@@ -48,4 +389,153 @@ type PlayerCompetition struct {
 	UpdatedAt     time.Time    `db:"updated_at"`
 	Level         int          `db:"level"`
 	CountryCode   string       `db:"country_code"`
+	Tier          Tier         `db:"tier"`
+	// PartyID is set on the waiting entries of a party that queued
+	// together; matchmaking keeps them in the same competition.
+	PartyID *uuid.UUID `db:"party_id"`
+	// DisplayName and AvatarURL are the player's, filled in on leaderboard
+	// reads.
+	DisplayName string `db:"display_name"`
+	AvatarURL   string `db:"avatar_url"`
+}
+
+// Party is a group of players who queue for matchmaking as a unit.
+type Party struct {
+	PartyID uuid.UUID `db:"party_id" json:"party_id"`
+	// LeaderID is the member who invites players and queues the party.
+	LeaderID string `db:"leader_id" json:"leader_id"`
+	// Members lists the players in the order they joined.
+	Members []string `json:"members"`
+	// Invites lists the players invited who have not accepted yet.
+	Invites []string `json:"invites"`
+	// Queued reports whether the party is in the matchmaking queue.
+	Queued    bool      `json:"queued"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// PartyLevel is how matchmaking rates a party's level from its members'.
+type PartyLevel string
+
+const (
+	PartyLevelMax     PartyLevel = "MAX"
+	PartyLevelAverage PartyLevel = "AVERAGE"
+)
+
+// PlayerStats are a player's totals over the competitions they completed.
+type PlayerStats struct {
+	PlayerID     string `db:"player_id" json:"player_id"`
+	DisplayName  string `db:"display_name" json:"display_name,omitempty"`
+	AvatarURL    string `db:"avatar_url" json:"avatar_url,omitempty"`
+	CountryCode  string `db:"country_code" json:"country_code,omitempty"`
+	Competitions int    `db:"competitions" json:"competitions"`
+	// Wins counts the competitions the player finished first in.
+	Wins       int   `db:"wins" json:"wins"`
+	TotalScore int64 `db:"total_score" json:"total_score"`
+	BestScore  int   `db:"best_score" json:"best_score"`
+}
+
+// CompetitionResult is how a player did in one competition they took part
+// in.
+type CompetitionResult struct {
+	CompetitionID uuid.UUID         `db:"competition_id" json:"competition_id"`
+	Name          string            `db:"name" json:"name,omitempty"`
+	Kind          CompetitionKind   `db:"kind" json:"kind"`
+	Status        CompetitionStatus `db:"status" json:"status"`
+	// EntryStatus is the player's, e.g. DISQUALIFIED.
+	EntryStatus PlayerStatus `db:"entry_status" json:"entry_status"`
+	Score       int          `db:"score" json:"score"`
+	// Rank is the player's place on the leaderboard, final once the
+	// competition is completed; 0 if they were taken off it.
+	Rank         int       `db:"rank" json:"rank,omitempty"`
+	Participants int       `db:"participants" json:"participants"`
+	StartedAt    time.Time `db:"started_at" json:"started_at"`
+	EndsAt       time.Time `db:"ends_at" json:"ends_at"`
+}
+
+// PlayerHistorySummary totals a player's competition history.
+type PlayerHistorySummary struct {
+	Competitions int `db:"competitions" json:"competitions"`
+	// Wins counts the completed competitions the player finished first in.
+	Wins int `db:"wins" json:"wins"`
+	// AverageRank is over the completed competitions the player was ranked
+	// in; 0 if there are none.
+	AverageRank float64 `db:"average_rank" json:"average_rank"`
+}
+
+// Region groups countries, e.g. EU, NA or APAC, for regional leaderboards.
+type Region struct {
+	RegionCode string `db:"region_code" json:"region_code"`
+	Name       string `db:"name" json:"name"`
+	// Countries are ISO 3166-1 alpha-2 codes, in code order.
+	Countries []string  `json:"countries"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// GeoFilter narrows a leaderboard to the players of one country or one
+// region. The zero value matches everyone.
+type GeoFilter struct {
+	CountryCode string
+	RegionCode  string
+}
+
+// Sanction is a restriction an admin puts on a player.
+type Sanction string
+
+const (
+	// SanctionBan stops a player joining competitions and submitting
+	// scores until it is lifted.
+	SanctionBan Sanction = "BAN"
+	// SanctionSuspension is a ban that ends at a set time.
+	SanctionSuspension Sanction = "SUSPENSION"
+	// SanctionShadowBan lets a player play on but hides them from every
+	// other player's leaderboards.
+	SanctionShadowBan Sanction = "SHADOW_BAN"
+)
+
+// Moderation is the sanctions on a player; nil fields are not in force.
+type Moderation struct {
+	PlayerID       string     `db:"player_id" json:"player_id"`
+	BannedAt       *time.Time `db:"banned_at" json:"banned_at,omitempty"`
+	SuspendedUntil *time.Time `db:"suspended_until" json:"suspended_until,omitempty"`
+	ShadowBannedAt *time.Time `db:"shadow_banned_at" json:"shadow_banned_at,omitempty"`
+}
+
+// Suspended reports whether the player is suspended at now.
+func (m *Moderation) Suspended(now time.Time) bool {
+	return m.SuspendedUntil != nil && now.Before(*m.SuspendedUntil)
+}
+
+// Viewer is who a competition leaderboard is read for. Shadow-banned
+// players only see themselves; the zero value, an anonymous viewer, sees
+// none of them.
+type Viewer struct {
+	PlayerID string
+	// All includes every shadow-banned player, for the service's own
+	// bookkeeping rather than for display.
+	All bool
+}
+
+// AuditEntry records one administrative change. Entries are never updated
+// or deleted.
+type AuditEntry struct {
+	ID         int64           `db:"id" json:"id"`
+	OccurredAt time.Time       `db:"occurred_at" json:"occurred_at"`
+	Actor      string          `db:"actor" json:"actor"`
+	Action     string          `db:"action" json:"action"`
+	Target     string          `db:"target" json:"target"`
+	Reason     string          `db:"reason" json:"reason,omitempty"`
+	Before     json.RawMessage `db:"before" json:"before,omitempty"`
+	After      json.RawMessage `db:"after" json:"after,omitempty"`
+}
+
+// AuditFilter narrows an audit log listing. Empty fields match everything.
+type AuditFilter struct {
+	Actor  string
+	Action string
+	Target string
+	// Player selects the entries about one player: those on the player
+	// themselves, e.g. sanctions, and on their entries in competitions, e.g.
+	// score adjustments.
+	Player string
+	Limit  int
 }
@@ -0,0 +1,58 @@
+package recurrence
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse_Next(t *testing.T) {
+	// A Wednesday.
+	from := time.Date(2030, 1, 2, 10, 30, 0, 0, time.UTC)
+	cases := []struct {
+		spec string
+		want []string
+	}{
+		{"@daily", []string{"2030-01-03T00:00:00Z", "2030-01-04T00:00:00Z"}},
+		{"@weekly", []string{"2030-01-06T00:00:00Z", "2030-01-13T00:00:00Z"}},
+		{"@monthly", []string{"2030-02-01T00:00:00Z", "2030-03-01T00:00:00Z"}},
+		{"*/20 * * * *", []string{"2030-01-02T10:40:00Z", "2030-01-02T11:00:00Z"}},
+		{"0 18 * * 1-5", []string{"2030-01-02T18:00:00Z", "2030-01-03T18:00:00Z", "2030-01-04T18:00:00Z", "2030-01-07T18:00:00Z"}},
+		{"0 9 * * 7", []string{"2030-01-06T09:00:00Z"}},
+		{"30 10 2,15 * *", []string{"2030-01-15T10:30:00Z", "2030-02-02T10:30:00Z"}},
+		// Both day fields restricted: either one matches.
+		{"0 0 1 * 5", []string{"2030-01-04T00:00:00Z", "2030-01-11T00:00:00Z"}},
+		{"@every 12h", []string{"2030-01-02T12:00:00Z", "2030-01-03T00:00:00Z"}},
+	}
+	for _, tc := range cases {
+		s, err := Parse(tc.spec)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tc.spec, err)
+		}
+		next := from
+		for _, w := range tc.want {
+			next = s.Next(next)
+			if got := next.Format(time.RFC3339); got != w {
+				t.Errorf("%s: expected %s, got %s", tc.spec, w, got)
+				break
+			}
+		}
+	}
+}
+
+func TestParse_NoMatch(t *testing.T) {
+	s, err := Parse("0 0 30 2 *")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if next := s.Next(time.Now()); !next.IsZero() {
+		t.Errorf("expected no occurrence of February 30th, got %v", next)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "0 0 0 * *", "* * * 13 *", "5-1 * * * *", "*/0 * * * *", "@every 10s", "@every soon", "@fortnightly"} {
+		if _, err := Parse(spec); err == nil {
+			t.Errorf("expected %q to be rejected", spec)
+		}
+	}
+}
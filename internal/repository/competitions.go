@@ -0,0 +1,451 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"leaderboard-service/internal/model"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// queryer is satisfied by both *sql.DB and *sql.Tx.
+type queryer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// inTx runs fn in a transaction, committing it if fn returns nil and rolling
+// it back otherwise.
+func (r *Repository) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// errNoChange rolls back an admin change whose guarded update matched no
+// rows; it never leaves the repository.
+var errNoChange = errors.New("no rows changed")
+
+// adminChange runs update and, if it changed any rows, records audit in the
+// same transaction. It reports whether the change was applied.
+func (r *Repository) adminChange(ctx context.Context, audit *model.AuditEntry, update func(tx *sql.Tx) (int64, error)) (bool, error) {
+	err := r.inTx(ctx, func(tx *sql.Tx) error {
+		n, err := update(tx)
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return errNoChange
+		}
+		return insertAuditEntry(ctx, tx, audit)
+	})
+	if err == errNoChange {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// ListCompetitions returns matching competitions, most recently started
+// first.
+func (r *Repository) ListCompetitions(ctx context.Context, filter model.CompetitionFilter) ([]model.Competition, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+competitionColumns+`
+		FROM competitions
+		WHERE ($1 = '' OR status = $1)
+		  AND ($2::int IS NULL OR level = $2)
+		  AND ($3 = '' OR country_code = $3)
+		  AND ($4::timestamp IS NULL OR ends_at > $4)
+		  AND ($5::timestamp IS NULL OR started_at < $5)
+		ORDER BY started_at DESC, competition_id
+		LIMIT NULLIF($6, 0)
+	`, string(filter.Status), filter.Level, filter.CountryCode, nullTime(filter.From), nullTime(filter.To), filter.Limit)
+	if err != nil {
+		logger(ctx).Error("error listing competitions", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	comps := []model.Competition{}
+	for rows.Next() {
+		comp, err := scanCompetition(rows)
+		if err != nil {
+			logger(ctx).Error("error scanning competition", "error", err)
+			return nil, err
+		}
+		comps = append(comps, *comp)
+	}
+	return comps, rows.Err()
+}
+
+// GetCompetitionPlayer returns playerID's row in a competition, whatever its
+// status.
+func (r *Repository) GetCompetitionPlayer(ctx context.Context, competitionID uuid.UUID, playerID string) (*model.PlayerCompetition, error) {
+	var pc model.PlayerCompetition
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, player_id, competition_id, status, score, joined_at, updated_at, level, country_code, tier
+		FROM player_competitions
+		WHERE competition_id = $1 AND player_id = $2
+		ORDER BY id DESC
+		LIMIT 1
+	`, competitionID, playerID).Scan(&pc.ID, &pc.PlayerID, &pc.CompetitionID, &pc.Status, &pc.Score, &pc.JoinedAt, &pc.UpdatedAt, &pc.Level, &pc.CountryCode, &pc.Tier)
+	if err != nil {
+		return nil, err
+	}
+	return &pc, nil
+}
+
+// CancelCompetition cancels a competition that has not finished, and its
+// players' active entries and registrations. It reports false if the
+// competition has already finished.
+func (r *Repository) CancelCompetition(ctx context.Context, competitionID uuid.UUID, audit *model.AuditEntry) (bool, error) {
+	ok, err := r.adminChange(ctx, audit, func(tx *sql.Tx) (int64, error) {
+		res, err := tx.ExecContext(ctx, `
+			UPDATE competitions SET status = 'CANCELLED'
+			WHERE competition_id = $1 AND status IN ('SCHEDULED', 'OPEN', 'ACTIVE')
+		`, competitionID)
+		if err != nil {
+			return 0, err
+		}
+		n, _ := res.RowsAffected()
+		if n == 0 {
+			return 0, nil
+		}
+		_, err = tx.ExecContext(ctx, `
+			UPDATE player_competitions SET status = 'CANCELLED', updated_at = NOW()
+			WHERE competition_id = $1 AND status IN ('REGISTERED', 'ACTIVE')
+		`, competitionID)
+		return n, err
+	})
+	if err != nil {
+		logger(ctx).Error("error cancelling competition", "competition_id", competitionID, "error", err)
+	}
+	return ok, err
+}
+
+// CompleteCompetition completes an active competition now, even if its end
+// time has not been reached. It reports false if the competition is not
+// active.
+func (r *Repository) CompleteCompetition(ctx context.Context, competitionID uuid.UUID, audit *model.AuditEntry) (bool, error) {
+	ok, err := r.adminChange(ctx, audit, func(tx *sql.Tx) (int64, error) {
+		res, err := tx.ExecContext(ctx, `
+			UPDATE competitions SET status = 'COMPLETED', ends_at = LEAST(ends_at, NOW())
+			WHERE competition_id = $1 AND status = 'ACTIVE'
+		`, competitionID)
+		if err != nil {
+			return 0, err
+		}
+		n, _ := res.RowsAffected()
+		if n == 0 {
+			return 0, nil
+		}
+		_, err = tx.ExecContext(ctx, `
+			UPDATE player_competitions SET status = 'COMPLETED', updated_at = NOW()
+			WHERE competition_id = $1 AND status = 'ACTIVE'
+		`, competitionID)
+		return n, err
+	})
+	if err != nil {
+		logger(ctx).Error("error completing competition", "competition_id", competitionID, "error", err)
+	}
+	return ok, err
+}
+
+// SetCompetitionEndsAt moves the end time of an active competition. It
+// reports false if the competition is not active.
+func (r *Repository) SetCompetitionEndsAt(ctx context.Context, competitionID uuid.UUID, endsAt time.Time, audit *model.AuditEntry) (bool, error) {
+	ok, err := r.adminChange(ctx, audit, func(tx *sql.Tx) (int64, error) {
+		res, err := tx.ExecContext(ctx, `
+			UPDATE competitions SET ends_at = $2 WHERE competition_id = $1 AND status = 'ACTIVE'
+		`, competitionID, endsAt)
+		if err != nil {
+			return 0, err
+		}
+		return res.RowsAffected()
+	})
+	if err != nil {
+		logger(ctx).Error("error updating competition end time", "competition_id", competitionID, "error", err)
+	}
+	return ok, err
+}
+
+// ExcludeCompetitionPlayer sets playerID's entry in a competition to status,
+// one of REMOVED or DISQUALIFIED, so that it no longer counts. Only active
+// and completed entries can be excluded; it reports false otherwise.
+func (r *Repository) ExcludeCompetitionPlayer(ctx context.Context, competitionID uuid.UUID, playerID string, status model.PlayerStatus, audit *model.AuditEntry) (bool, error) {
+	ok, err := r.adminChange(ctx, audit, func(tx *sql.Tx) (int64, error) {
+		res, err := tx.ExecContext(ctx, `
+			UPDATE player_competitions SET status = $3, updated_at = NOW()
+			WHERE competition_id = $1 AND player_id = $2 AND status IN ('ACTIVE', 'COMPLETED')
+		`, competitionID, playerID, string(status))
+		if err != nil {
+			return 0, err
+		}
+		return res.RowsAffected()
+	})
+	if err != nil {
+		logger(ctx).Error("error excluding player from competition", "competition_id", competitionID, "player_id", playerID, "error", err)
+	}
+	return ok, err
+}
+
+// SetCompetitionScore changes playerID's score in a competition from from to
+// to, for an active or completed entry. It reports false if the entry is
+// not counted or its score is no longer from. updated_at is left alone so a
+// correction does not make an old competition the player's latest.
+func (r *Repository) SetCompetitionScore(ctx context.Context, competitionID uuid.UUID, playerID string, from, to int, audit *model.AuditEntry) (bool, error) {
+	ok, err := r.adminChange(ctx, audit, func(tx *sql.Tx) (int64, error) {
+		res, err := tx.ExecContext(ctx, `
+			UPDATE player_competitions SET score = $4
+			WHERE competition_id = $1 AND player_id = $2 AND score = $3 AND status IN ('ACTIVE', 'COMPLETED')
+		`, competitionID, playerID, from, to)
+		if err != nil {
+			return 0, err
+		}
+		return res.RowsAffected()
+	})
+	if err != nil {
+		logger(ctx).Error("error setting competition score", "competition_id", competitionID, "player_id", playerID, "error", err)
+	}
+	return ok, err
+}
+
+// CreateScheduledCompetition stores a competition created by an admin,
+// recording audit in the same transaction.
+func (r *Repository) CreateScheduledCompetition(ctx context.Context, comp *model.Competition, audit *model.AuditEntry) error {
+	err := r.inTx(ctx, func(tx *sql.Tx) error {
+		if err := insertCompetition(ctx, tx, comp); err != nil {
+			return err
+		}
+		return insertAuditEntry(ctx, tx, audit)
+	})
+	if err != nil {
+		logger(ctx).Error("error creating scheduled competition", "competition_id", comp.CompetitionID, "error", err)
+	}
+	return err
+}
+
+// OpenScheduledCompetitions opens registration for scheduled competitions
+// whose registration time has come and returns their IDs.
+func (r *Repository) OpenScheduledCompetitions(ctx context.Context) ([]uuid.UUID, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		UPDATE competitions SET status = 'OPEN'
+		WHERE status = 'SCHEDULED' AND registration_opens_at <= NOW()
+		RETURNING competition_id
+	`)
+	if err != nil {
+		logger(ctx).Error("error opening scheduled competitions", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var opened []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			logger(ctx).Error("error scanning opened competition", "error", err)
+			return nil, err
+		}
+		opened = append(opened, id)
+	}
+	return opened, rows.Err()
+}
+
+// StartScheduledCompetitions starts scheduled competitions whose start time
+// has come: registrations become active entries, and the registered players
+// leave the matchmaking queue with any party they queued with. A player
+// plays one competition at a time, so a registration is cancelled instead
+// if the player is still playing another competition, or has an earlier
+// registration for one starting at the same time. It returns the started
+// competitions.
+func (r *Repository) StartScheduledCompetitions(ctx context.Context) ([]model.Competition, error) {
+	var started []model.Competition
+	err := r.inTx(ctx, func(tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, `
+			UPDATE competitions SET status = 'ACTIVE'
+			WHERE kind = 'SCHEDULED' AND status IN ('SCHEDULED', 'OPEN') AND started_at <= NOW()
+			RETURNING `+competitionColumns)
+		if err != nil {
+			return err
+		}
+		var ids []string
+		for rows.Next() {
+			comp, err := scanCompetition(rows)
+			if err != nil {
+				rows.Close()
+				return err
+			}
+			started = append(started, *comp)
+			ids = append(ids, comp.CompetitionID.String())
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil || len(ids) == 0 {
+			return err
+		}
+		for _, stmt := range []string{
+			`UPDATE player_competitions pc SET status = 'ACTIVE', updated_at = NOW()
+			 WHERE pc.competition_id = ANY($1::uuid[]) AND pc.status = 'REGISTERED'
+			 AND pc.id = (
+				SELECT MIN(r.id) FROM player_competitions r
+				WHERE r.player_id = pc.player_id AND r.competition_id = ANY($1::uuid[]) AND r.status = 'REGISTERED'
+			 )
+			 AND NOT EXISTS (
+				SELECT 1 FROM player_competitions a JOIN competitions c ON a.competition_id = c.competition_id
+				WHERE a.player_id = pc.player_id AND a.status = 'ACTIVE' AND c.ends_at > NOW()
+			 )`,
+			`UPDATE player_competitions SET status = 'CANCELLED', updated_at = NOW()
+			 WHERE competition_id = ANY($1::uuid[]) AND status = 'REGISTERED'`,
+		} {
+			if _, err := tx.ExecContext(ctx, stmt, pq.Array(ids)); err != nil {
+				return err
+			}
+		}
+		_, err = tx.ExecContext(ctx, `
+			WITH entered AS (
+				SELECT player_id FROM player_competitions WHERE competition_id = ANY($1::uuid[]) AND status = 'ACTIVE'
+			)
+			UPDATE player_competitions SET status = 'CANCELLED', updated_at = NOW()
+			WHERE status = 'WAITING' AND (player_id IN (SELECT player_id FROM entered) OR party_id IN (
+				SELECT party_id FROM player_competitions
+				WHERE status = 'WAITING' AND party_id IS NOT NULL AND player_id IN (SELECT player_id FROM entered)
+			))
+		`, pq.Array(ids))
+		return err
+	})
+	if err != nil {
+		logger(ctx).Error("error starting scheduled competitions", "error", err)
+		return nil, err
+	}
+	return started, nil
+}
+
+// RegisterCompetitionPlayer registers player for a scheduled competition
+// whose registration is open, or enters them straight into a running
+// recurring competition, leaving the matchmaking queue with any party they
+// queued with. It reports false if the competition takes no entries, is
+// full, already has the player or has removed or disqualified them, and
+// for a running competition if the player is playing another one.
+func (r *Repository) RegisterCompetitionPlayer(ctx context.Context, competitionID uuid.UUID, player *model.Player) (bool, error) {
+	err := r.inTx(ctx, func(tx *sql.Tx) error {
+		// Lock the competition so concurrent registrations respect max_players.
+		var status, kind string
+		var maxPlayers, registered int
+		var running bool
+		err := tx.QueryRowContext(ctx, `
+			SELECT status, kind, max_players, ends_at > NOW() FROM competitions WHERE competition_id = $1 FOR UPDATE
+		`, competitionID).Scan(&status, &kind, &maxPlayers, &running)
+		if err != nil {
+			return err
+		}
+		var entryStatus model.PlayerStatus
+		switch {
+		case status == string(model.CompetitionOpen):
+			entryStatus = model.StatusRegistered
+		case status == string(model.CompetitionActive) && kind == string(model.CompetitionKindRecurring) && running:
+			entryStatus = model.StatusActive
+		default:
+			return errNoChange
+		}
+		if entryStatus == model.StatusActive {
+			var playing bool
+			err = tx.QueryRowContext(ctx, `
+				SELECT EXISTS (
+					SELECT 1 FROM player_competitions pc JOIN competitions c ON pc.competition_id = c.competition_id
+					WHERE pc.player_id = $1 AND pc.status = 'ACTIVE' AND c.ends_at > NOW() AND pc.competition_id <> $2
+				)
+			`, player.PlayerID, competitionID).Scan(&playing)
+			if err != nil {
+				return err
+			}
+			if playing {
+				return errNoChange
+			}
+		}
+		// An excluded player stays out: entering again would start them
+		// afresh next to the entry they were excluded with.
+		var already bool
+		err = tx.QueryRowContext(ctx, `
+			SELECT COUNT(1) FILTER (WHERE status IN ('REGISTERED', 'ACTIVE')),
+				COALESCE(BOOL_OR(player_id = $2 AND status IN ('REGISTERED', 'ACTIVE', 'REMOVED', 'DISQUALIFIED')), FALSE)
+			FROM player_competitions WHERE competition_id = $1
+		`, competitionID, player.PlayerID).Scan(&registered, &already)
+		if err != nil {
+			return err
+		}
+		if already || (maxPlayers > 0 && registered >= maxPlayers) {
+			return errNoChange
+		}
+		// A player who withdrew and comes back gets their old row back, so
+		// they never appear twice in the competition.
+		res, err := tx.ExecContext(ctx, `
+			UPDATE player_competitions
+			SET status = $3, score = 0, joined_at = NOW(), updated_at = NOW(), level = $4, country_code = $5, tier = $6
+			WHERE id = (
+				SELECT id FROM player_competitions
+				WHERE competition_id = $1 AND player_id = $2 AND status = 'CANCELLED'
+				ORDER BY id DESC
+				LIMIT 1
+			)
+		`, competitionID, player.PlayerID, string(entryStatus), player.Level, player.CountryCode, player.Tier)
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			_, err = tx.ExecContext(ctx, `
+				INSERT INTO player_competitions (player_id, competition_id, status, score, joined_at, updated_at, level, country_code, tier)
+				VALUES ($1, $2, $3, 0, NOW(), NOW(), $4, $5, $6)
+			`, player.PlayerID, competitionID, string(entryStatus), player.Level, player.CountryCode, player.Tier)
+		}
+		if err != nil || entryStatus != model.StatusActive {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, `
+			UPDATE player_competitions SET status = 'CANCELLED', updated_at = NOW()
+			WHERE status = 'WAITING' AND (player_id = $1 OR party_id IN (
+				SELECT party_id FROM player_competitions WHERE player_id = $1 AND status = 'WAITING' AND party_id IS NOT NULL
+			))
+		`, player.PlayerID)
+		return err
+	})
+	if err == errNoChange {
+		return false, nil
+	}
+	if err != nil {
+		logger(ctx).Error("error registering player", "competition_id", competitionID, "player_id", player.PlayerID, "error", err)
+		return false, err
+	}
+	logger(ctx).Debug("registered player", "competition_id", competitionID, "player_id", player.PlayerID)
+	return true, nil
+}
+
+// CancelCompetitionRegistration withdraws playerID's registration for a
+// scheduled competition that has not started. It reports false if there was
+// none.
+func (r *Repository) CancelCompetitionRegistration(ctx context.Context, competitionID uuid.UUID, playerID string) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE player_competitions SET status = 'CANCELLED', updated_at = NOW()
+		WHERE competition_id = $1 AND player_id = $2 AND status = 'REGISTERED'
+	`, competitionID, playerID)
+	if err != nil {
+		logger(ctx).Error("error cancelling registration", "competition_id", competitionID, "player_id", playerID, "error", err)
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// nullTime stores the zero time as SQL NULL.
+func nullTime(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+	return t
+}
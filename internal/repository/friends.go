@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"leaderboard-service/internal/model"
+
+	"github.com/lib/pq"
+)
+
+// AddFriends makes playerID follow each of friendIDs that is a player. It
+// skips unknown players, playerID itself and players already followed, and
+// returns the IDs it added.
+func (r *Repository) AddFriends(ctx context.Context, playerID string, friendIDs []string) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		INSERT INTO player_friends (player_id, friend_id)
+		SELECT $1, p.player_id FROM players p
+		WHERE p.player_id = ANY($2) AND p.player_id <> $1
+		ON CONFLICT DO NOTHING
+		RETURNING friend_id
+	`, playerID, pq.Array(friendIDs))
+	if err != nil {
+		logger(ctx).Error("error adding friends", "player_id", playerID, "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	added := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		added = append(added, id)
+	}
+	return added, rows.Err()
+}
+
+// RemoveFriend stops playerID following friendID. It reports false if they
+// did not.
+func (r *Repository) RemoveFriend(ctx context.Context, playerID, friendID string) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM player_friends WHERE player_id = $1 AND friend_id = $2`, playerID, friendID)
+	if err != nil {
+		logger(ctx).Error("error removing friend", "player_id", playerID, "friend_id", friendID, "error", err)
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// ListFriends returns the IDs of the players playerID follows, in ID order.
+func (r *Repository) ListFriends(ctx context.Context, playerID string) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT friend_id FROM player_friends WHERE player_id = $1 ORDER BY friend_id
+	`, playerID)
+	if err != nil {
+		logger(ctx).Error("error listing friends", "player_id", playerID, "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	friends := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		friends = append(friends, id)
+	}
+	return friends, rows.Err()
+}
+
+// GetPlayerStats returns the stats of each of playerIDs that is a player,
+// over their completed entries; players who completed none get zeros. A
+// player wins a competition by topping its leaderboard, ties going to the
+// lower player ID as on the leaderboard itself. Shadow-banned players are
+// left out, and do not take wins from others, unless viewer may see them.
+func (r *Repository) GetPlayerStats(ctx context.Context, playerIDs []string, viewer model.Viewer) ([]model.PlayerStats, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		WITH ranked AS (
+			SELECT pc.player_id, pc.score,
+				ROW_NUMBER() OVER (PARTITION BY pc.competition_id ORDER BY pc.score DESC, pc.player_id ASC) AS rank
+			FROM player_competitions pc
+			LEFT JOIN players sp ON sp.player_id = pc.player_id
+			WHERE pc.status = 'COMPLETED' AND ($2 OR pc.player_id = $3 OR sp.shadow_banned_at IS NULL) AND pc.competition_id IN (
+				SELECT competition_id FROM player_competitions
+				WHERE player_id = ANY($1) AND status = 'COMPLETED'
+			)
+		)
+		SELECT p.player_id, COALESCE(p.display_name, ''), COALESCE(p.avatar_url, ''), COALESCE(p.country_code, ''), COUNT(r.player_id), COUNT(r.player_id) FILTER (WHERE r.rank = 1),
+			COALESCE(SUM(r.score), 0), COALESCE(MAX(r.score), 0)
+		FROM players p
+		LEFT JOIN ranked r ON r.player_id = p.player_id
+		WHERE p.player_id = ANY($1) AND ($2 OR p.player_id = $3 OR p.shadow_banned_at IS NULL)
+		GROUP BY p.player_id
+		ORDER BY p.player_id
+	`, pq.Array(playerIDs), viewer.All, viewer.PlayerID)
+	if err != nil {
+		logger(ctx).Error("error fetching player stats", "players", len(playerIDs), "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := []model.PlayerStats{}
+	for rows.Next() {
+		var s model.PlayerStats
+		if err := rows.Scan(&s.PlayerID, &s.DisplayName, &s.AvatarURL, &s.CountryCode, &s.Competitions, &s.Wins, &s.TotalScore, &s.BestScore); err != nil {
+			return nil, err
+		}
+		stats = append(stats, s)
+	}
+	return stats, rows.Err()
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"leaderboard-service/internal/model"
+)
+
+// playerHistoryCTE selects the competitions a player took part in and their
+// leaderboards. $1 is the player ID and $2 to $4 are the filter's status,
+// from and to. A player took part in a competition once it started with
+// them in it; registrations withdrawn or cancelled before the start do not
+// count, for the player or anyone they are ranked against. Ranks follow the
+// leaderboard the player sees, shadow-banned players other than them left
+// out and ties going to the lower player ID.
+const playerHistoryCTE = `
+	WITH entries AS (
+		SELECT DISTINCT ON (pc.competition_id) pc.competition_id, pc.status, pc.score
+		FROM player_competitions pc
+		JOIN competitions c ON c.competition_id = pc.competition_id
+		WHERE pc.player_id = $1 AND pc.status <> 'REGISTERED'
+		  AND c.status IN ('ACTIVE', 'COMPLETED', 'CANCELLED')
+		  AND (pc.status <> 'CANCELLED' OR pc.updated_at >= c.started_at)
+		  AND ($2 = '' OR c.status = $2)
+		  AND ($3::timestamp IS NULL OR c.ends_at > $3)
+		  AND ($4::timestamp IS NULL OR c.started_at < $4)
+		ORDER BY pc.competition_id, pc.id DESC
+	), ranked AS (
+		SELECT pc.competition_id, pc.player_id,
+			ROW_NUMBER() OVER (PARTITION BY pc.competition_id ORDER BY pc.score DESC, pc.player_id ASC) AS rank,
+			COUNT(*) OVER (PARTITION BY pc.competition_id) AS participants
+		FROM player_competitions pc
+		JOIN competitions c ON c.competition_id = pc.competition_id
+		LEFT JOIN players p ON p.player_id = pc.player_id
+		WHERE pc.competition_id IN (SELECT competition_id FROM entries)
+		  AND pc.status NOT IN ('REGISTERED', 'REMOVED', 'DISQUALIFIED')
+		  AND (pc.status <> 'CANCELLED' OR pc.updated_at >= c.started_at)
+		  AND (pc.player_id = $1 OR p.shadow_banned_at IS NULL)
+	)`
+
+// ListPlayerHistory returns a page of the competitions playerID took part
+// in, most recently started first.
+func (r *Repository) ListPlayerHistory(ctx context.Context, playerID string, filter model.PlayerHistoryFilter) ([]model.CompetitionResult, error) {
+	rows, err := r.db.QueryContext(ctx, playerHistoryCTE+`
+		SELECT c.competition_id, c.name, c.kind, c.status, e.status, e.score, COALESCE(pr.rank, 0),
+			COALESCE((SELECT MAX(participants) FROM ranked WHERE competition_id = e.competition_id), 0),
+			c.started_at, c.ends_at
+		FROM entries e
+		JOIN competitions c ON c.competition_id = e.competition_id
+		LEFT JOIN ranked pr ON pr.competition_id = e.competition_id AND pr.player_id = $1
+		ORDER BY c.started_at DESC, c.competition_id
+		LIMIT NULLIF($5, 0) OFFSET $6
+	`, playerID, string(filter.Status), nullTime(filter.From), nullTime(filter.To), filter.Limit, filter.Offset)
+	if err != nil {
+		logger(ctx).Error("error listing player history", "player_id", playerID, "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := []model.CompetitionResult{}
+	for rows.Next() {
+		var res model.CompetitionResult
+		if err := rows.Scan(&res.CompetitionID, &res.Name, &res.Kind, &res.Status, &res.EntryStatus, &res.Score, &res.Rank,
+			&res.Participants, &res.StartedAt, &res.EndsAt); err != nil {
+			logger(ctx).Error("error scanning player history", "player_id", playerID, "error", err)
+			return nil, err
+		}
+		results = append(results, res)
+	}
+	return results, rows.Err()
+}
+
+// GetPlayerHistorySummary totals the competitions ListPlayerHistory would
+// return for filter, ignoring its Limit and Offset.
+func (r *Repository) GetPlayerHistorySummary(ctx context.Context, playerID string, filter model.PlayerHistoryFilter) (*model.PlayerHistorySummary, error) {
+	var sum model.PlayerHistorySummary
+	err := r.db.QueryRowContext(ctx, playerHistoryCTE+`
+		SELECT COUNT(*), COUNT(*) FILTER (WHERE c.status = 'COMPLETED' AND pr.rank = 1),
+			COALESCE(ROUND(AVG(pr.rank) FILTER (WHERE c.status = 'COMPLETED'), 2), 0)
+		FROM entries e
+		JOIN competitions c ON c.competition_id = e.competition_id
+		LEFT JOIN ranked pr ON pr.competition_id = e.competition_id AND pr.player_id = $1
+	`, playerID, string(filter.Status), nullTime(filter.From), nullTime(filter.To)).Scan(&sum.Competitions, &sum.Wins, &sum.AverageRank)
+	if err != nil {
+		logger(ctx).Error("error summarising player history", "player_id", playerID, "error", err)
+		return nil, err
+	}
+	return &sum, nil
+}
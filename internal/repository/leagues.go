@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"leaderboard-service/internal/model"
+
+	"github.com/google/uuid"
+)
+
+// UnsettledLeagueCompetitions returns the completed league competitions
+// whose tier movements have not been applied yet, oldest first.
+func (r *Repository) UnsettledLeagueCompetitions(ctx context.Context) ([]model.Competition, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+competitionColumns+`
+		FROM competitions
+		WHERE tier <> '' AND status = 'COMPLETED' AND settled_at IS NULL
+		ORDER BY ends_at, competition_id
+	`)
+	if err != nil {
+		logger(ctx).Error("error listing unsettled league competitions", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var comps []model.Competition
+	for rows.Next() {
+		comp, err := scanCompetition(rows)
+		if err != nil {
+			logger(ctx).Error("error scanning unsettled league competition", "error", err)
+			return nil, err
+		}
+		comps = append(comps, *comp)
+	}
+	return comps, rows.Err()
+}
+
+// ApplyTierMovements moves players between tiers after competitionID has
+// completed, records each move in their tier history and marks the
+// competition settled, in one transaction. A move is skipped if the
+// competition already moved the player or the player is no longer in its
+// FromTier. It returns the moves applied, with OccurredAt set.
+func (r *Repository) ApplyTierMovements(ctx context.Context, competitionID uuid.UUID, moves []model.TierMovement) ([]model.TierMovement, error) {
+	var applied []model.TierMovement
+	err := r.inTx(ctx, func(tx *sql.Tx) error {
+		applied = nil
+		for _, m := range moves {
+			err := tx.QueryRowContext(ctx, `
+				WITH moved AS (
+					UPDATE players SET tier = $4
+					WHERE player_id = $1 AND tier = $3
+					  AND NOT EXISTS (SELECT 1 FROM player_tier_history WHERE player_id = $1 AND competition_id = $2)
+					RETURNING player_id
+				)
+				INSERT INTO player_tier_history (player_id, competition_id, from_tier, to_tier, rank)
+				SELECT player_id, $2, $3, $4, $5 FROM moved
+				ON CONFLICT (player_id, competition_id) DO NOTHING
+				RETURNING occurred_at
+			`, m.PlayerID, competitionID, m.FromTier, m.ToTier, m.Rank).Scan(&m.OccurredAt)
+			if err == sql.ErrNoRows {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			m.CompetitionID = competitionID
+			applied = append(applied, m)
+		}
+		_, err := tx.ExecContext(ctx, `
+			UPDATE competitions SET settled_at = NOW() WHERE competition_id = $1 AND settled_at IS NULL
+		`, competitionID)
+		return err
+	})
+	if err != nil {
+		logger(ctx).Error("error applying tier movements", "competition_id", competitionID, "error", err)
+		return nil, err
+	}
+	return applied, nil
+}
+
+// ListTierHistory returns up to limit of a player's tier movements, most
+// recent first. A limit of 0 returns them all.
+func (r *Repository) ListTierHistory(ctx context.Context, playerID string, limit int) ([]model.TierMovement, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT player_id, competition_id, from_tier, to_tier, rank, occurred_at
+		FROM player_tier_history
+		WHERE player_id = $1
+		ORDER BY occurred_at DESC, id DESC
+		LIMIT NULLIF($2, 0)
+	`, playerID, limit)
+	if err != nil {
+		logger(ctx).Error("error listing tier history", "player_id", playerID, "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	moves := []model.TierMovement{}
+	for rows.Next() {
+		var m model.TierMovement
+		if err := rows.Scan(&m.PlayerID, &m.CompetitionID, &m.FromTier, &m.ToTier, &m.Rank, &m.OccurredAt); err != nil {
+			return nil, err
+		}
+		moves = append(moves, m)
+	}
+	return moves, rows.Err()
+}
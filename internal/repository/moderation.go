@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"leaderboard-service/internal/model"
+	"time"
+)
+
+// sanctionColumns are the players columns that hold each sanction.
+var sanctionColumns = map[model.Sanction]string{
+	model.SanctionBan:        "banned_at",
+	model.SanctionSuspension: "suspended_until",
+	model.SanctionShadowBan:  "shadow_banned_at",
+}
+
+// GetPlayerModeration returns the sanctions on playerID, or sql.ErrNoRows
+// if there is no such player.
+func (r *Repository) GetPlayerModeration(ctx context.Context, playerID string) (*model.Moderation, error) {
+	m := model.Moderation{PlayerID: playerID}
+	err := r.db.QueryRowContext(ctx, `
+		SELECT banned_at, suspended_until, shadow_banned_at FROM players WHERE player_id = $1
+	`, playerID).Scan(&m.BannedAt, &m.SuspendedUntil, &m.ShadowBannedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// SetPlayerSanction sets sanction on playerID to value, or lifts it if value
+// is nil, recording audit in the same transaction. A ban or suspension also
+// takes the player out of the queue, with their party, and cancels their
+// registrations. It reports false if there is no such player.
+func (r *Repository) SetPlayerSanction(ctx context.Context, playerID string, sanction model.Sanction, value *time.Time, audit *model.AuditEntry) (bool, error) {
+	ok, err := r.adminChange(ctx, audit, func(tx *sql.Tx) (int64, error) {
+		res, err := tx.ExecContext(ctx, `UPDATE players SET `+sanctionColumns[sanction]+` = $2 WHERE player_id = $1`, playerID, value)
+		if err != nil {
+			return 0, err
+		}
+		n, err := res.RowsAffected()
+		if err != nil || n == 0 || value == nil || sanction == model.SanctionShadowBan {
+			return n, err
+		}
+		_, err = tx.ExecContext(ctx, `
+			UPDATE player_competitions
+			SET status = 'CANCELLED', updated_at = NOW()
+			WHERE (player_id = $1 AND status IN ('WAITING', 'REGISTERED')) OR (status = 'WAITING' AND party_id IN (
+				SELECT party_id FROM player_competitions WHERE player_id = $1 AND status = 'WAITING' AND party_id IS NOT NULL
+			))
+		`, playerID)
+		return n, err
+	})
+	if err != nil {
+		logger(ctx).Error("error setting player sanction", "player_id", playerID, "sanction", sanction, "error", err)
+	}
+	return ok, err
+}
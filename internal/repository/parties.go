@@ -0,0 +1,237 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"leaderboard-service/internal/model"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// partyColumns are the columns read by scanParty, from parties p.
+const partyColumns = `p.party_id, p.leader_id, p.created_at,
+	ARRAY(SELECT player_id FROM party_members m WHERE m.party_id = p.party_id ORDER BY m.joined_at, m.player_id),
+	ARRAY(SELECT player_id FROM party_invites i WHERE i.party_id = p.party_id ORDER BY i.invited_at, i.player_id),
+	EXISTS(SELECT 1 FROM player_competitions pc WHERE pc.party_id = p.party_id AND pc.status = 'WAITING')`
+
+func scanParty(row rowScanner) (*model.Party, error) {
+	var party model.Party
+	err := row.Scan(&party.PartyID, &party.LeaderID, &party.CreatedAt, pq.Array(&party.Members), pq.Array(&party.Invites), &party.Queued)
+	if err != nil {
+		return nil, err
+	}
+	return &party, nil
+}
+
+// lockParty locks a party's row for the rest of tx, so that changes to its
+// members, invites and queue entries are serialized. It returns errNoChange
+// if the party does not exist.
+func lockParty(ctx context.Context, tx *sql.Tx, partyID uuid.UUID) (leaderID string, err error) {
+	err = tx.QueryRowContext(ctx, `SELECT leader_id FROM parties WHERE party_id = $1 FOR UPDATE`, partyID).Scan(&leaderID)
+	if err == sql.ErrNoRows {
+		return "", errNoChange
+	}
+	return leaderID, err
+}
+
+// CreateParty stores party with its leader as the only member. It reports
+// false, storing nothing, if the leader is already in a party. CreatedAt is
+// set from the database.
+func (r *Repository) CreateParty(ctx context.Context, party *model.Party) (bool, error) {
+	err := r.inTx(ctx, func(tx *sql.Tx) error {
+		err := tx.QueryRowContext(ctx, `
+			INSERT INTO parties (party_id, leader_id) VALUES ($1, $2)
+			RETURNING created_at
+		`, party.PartyID, party.LeaderID).Scan(&party.CreatedAt)
+		if err != nil {
+			return err
+		}
+		res, err := tx.ExecContext(ctx, `
+			INSERT INTO party_members (player_id, party_id) VALUES ($1, $2)
+			ON CONFLICT (player_id) DO NOTHING
+		`, party.LeaderID, party.PartyID)
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return errNoChange
+		}
+		return nil
+	})
+	if err == errNoChange {
+		return false, nil
+	}
+	if err != nil {
+		logger(ctx).Error("error creating party", "party_id", party.PartyID, "error", err)
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *Repository) GetParty(ctx context.Context, partyID uuid.UUID) (*model.Party, error) {
+	return scanParty(r.db.QueryRowContext(ctx,
+		`SELECT `+partyColumns+` FROM parties p WHERE p.party_id = $1`,
+		partyID,
+	))
+}
+
+// GetPlayerParty returns the party playerID is a member of, or
+// sql.ErrNoRows if there is none.
+func (r *Repository) GetPlayerParty(ctx context.Context, playerID string) (*model.Party, error) {
+	return scanParty(r.db.QueryRowContext(ctx, `
+		SELECT `+partyColumns+`
+		FROM parties p
+		JOIN party_members m ON m.party_id = p.party_id
+		WHERE m.player_id = $1
+	`, playerID))
+}
+
+// InvitePartyPlayer invites playerID to a party. It reports false if they
+// are already invited.
+func (r *Repository) InvitePartyPlayer(ctx context.Context, partyID uuid.UUID, playerID string) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+		INSERT INTO party_invites (party_id, player_id) VALUES ($1, $2)
+		ON CONFLICT DO NOTHING
+	`, partyID, playerID)
+	if err != nil {
+		logger(ctx).Error("error inviting party player", "party_id", partyID, "player_id", playerID, "error", err)
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// AcceptPartyInvite makes an invited player a member. It reports false,
+// changing nothing, unless the player is invited and in no other party, and
+// the party is not queued and has fewer than maxSize members.
+func (r *Repository) AcceptPartyInvite(ctx context.Context, partyID uuid.UUID, playerID string, maxSize int) (bool, error) {
+	err := r.inTx(ctx, func(tx *sql.Tx) error {
+		if _, err := lockParty(ctx, tx, partyID); err != nil {
+			return err
+		}
+		res, err := tx.ExecContext(ctx, `DELETE FROM party_invites WHERE party_id = $1 AND player_id = $2`, partyID, playerID)
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return errNoChange
+		}
+		res, err = tx.ExecContext(ctx, `
+			INSERT INTO party_members (player_id, party_id)
+			SELECT $2, $1
+			WHERE (SELECT COUNT(1) FROM party_members WHERE party_id = $1) < $3
+				AND NOT EXISTS (SELECT 1 FROM player_competitions WHERE party_id = $1 AND status = 'WAITING')
+			ON CONFLICT (player_id) DO NOTHING
+		`, partyID, playerID, maxSize)
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return errNoChange
+		}
+		return nil
+	})
+	if err == errNoChange {
+		return false, nil
+	}
+	if err != nil {
+		logger(ctx).Error("error accepting party invite", "party_id", partyID, "player_id", playerID, "error", err)
+		return false, err
+	}
+	return true, nil
+}
+
+// LeaveParty removes playerID from a party and takes the party out of the
+// matchmaking queue. A leader who leaves hands over to the longest-standing
+// member, and the last member to leave disbands the party. It reports
+// false if the player is not a member.
+func (r *Repository) LeaveParty(ctx context.Context, partyID uuid.UUID, playerID string) (bool, error) {
+	err := r.inTx(ctx, func(tx *sql.Tx) error {
+		leaderID, err := lockParty(ctx, tx, partyID)
+		if err != nil {
+			return err
+		}
+		res, err := tx.ExecContext(ctx, `DELETE FROM party_members WHERE party_id = $1 AND player_id = $2`, partyID, playerID)
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return errNoChange
+		}
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE player_competitions SET status = 'CANCELLED', updated_at = NOW()
+			WHERE party_id = $1 AND status = 'WAITING'
+		`, partyID); err != nil {
+			return err
+		}
+		if leaderID == playerID {
+			if _, err := tx.ExecContext(ctx, `
+				UPDATE parties SET leader_id = m.player_id
+				FROM (SELECT player_id FROM party_members WHERE party_id = $1 ORDER BY joined_at, player_id LIMIT 1) m
+				WHERE parties.party_id = $1
+			`, partyID); err != nil {
+				return err
+			}
+		}
+		_, err = tx.ExecContext(ctx, `
+			DELETE FROM parties WHERE party_id = $1 AND NOT EXISTS (SELECT 1 FROM party_members WHERE party_id = $1)
+		`, partyID)
+		return err
+	})
+	if err == errNoChange {
+		return false, nil
+	}
+	if err != nil {
+		logger(ctx).Error("error leaving party", "party_id", partyID, "player_id", playerID, "error", err)
+		return false, err
+	}
+	return true, nil
+}
+
+// QueueParty puts a party in the matchmaking queue with one waiting entry
+// per member, all joined at the same time. It reports false, queueing
+// nobody, if any member is already waiting or entries no longer match the
+// party's members.
+func (r *Repository) QueueParty(ctx context.Context, partyID uuid.UUID, entries []model.PlayerCompetition) (bool, error) {
+	err := r.inTx(ctx, func(tx *sql.Tx) error {
+		if _, err := lockParty(ctx, tx, partyID); err != nil {
+			return err
+		}
+		var members int
+		var waiting bool
+		if err := tx.QueryRowContext(ctx, `
+			SELECT COUNT(1), COALESCE(BOOL_OR(EXISTS(
+				SELECT 1 FROM player_competitions pc WHERE pc.player_id = m.player_id AND pc.status = 'WAITING'
+			)), FALSE)
+			FROM party_members m WHERE m.party_id = $1
+		`, partyID).Scan(&members, &waiting); err != nil {
+			return err
+		}
+		if waiting || members != len(entries) {
+			return errNoChange
+		}
+		for _, pc := range entries {
+			res, err := tx.ExecContext(ctx, `
+				INSERT INTO player_competitions (player_id, status, score, joined_at, updated_at, level, country_code, tier, party_id)
+				SELECT $1, 'WAITING', 0, $2, $2, $3, $4, $5, $6
+				WHERE EXISTS (SELECT 1 FROM party_members WHERE party_id = $6 AND player_id = $1)
+			`, pc.PlayerID, pc.JoinedAt, pc.Level, pc.CountryCode, pc.Tier, partyID)
+			if err != nil {
+				return err
+			}
+			if n, _ := res.RowsAffected(); n == 0 {
+				return errNoChange
+			}
+		}
+		return nil
+	})
+	if err == errNoChange {
+		return false, nil
+	}
+	if err != nil {
+		logger(ctx).Error("error queueing party", "party_id", partyID, "error", err)
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"leaderboard-service/internal/model"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// AnonymizePlayer replaces playerID with alias wherever it is kept, the
+// audit log included, so that the player's entries still count on
+// leaderboards, and forgets their country and level, on their entries too,
+// profile, friends, invites, queue entry and registrations. A shadow-ban
+// carries over to the alias. It reports false if playerID is not a player.
+// The player must have left their party.
+func (r *Repository) AnonymizePlayer(ctx context.Context, playerID, alias string) (bool, error) {
+	err := r.inTx(ctx, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, `
+			INSERT INTO players (player_id, level, country_code, tier, shadow_banned_at, anonymized_at)
+			SELECT $2, 0, '', tier, shadow_banned_at, NOW() FROM players WHERE player_id = $1
+		`, playerID, alias)
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return errNoChange
+		}
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE player_competitions SET status = 'CANCELLED', updated_at = NOW()
+			WHERE player_id = $1 AND status IN ('WAITING', 'REGISTERED')
+		`, playerID); err != nil {
+			return err
+		}
+		for _, stmt := range []string{
+			`UPDATE player_competitions SET player_id = $2, level = 0, country_code = '' WHERE player_id = $1`,
+			`UPDATE player_tier_history SET player_id = $2 WHERE player_id = $1`,
+			`UPDATE tournaments SET seeds = array_replace(seeds, $1, $2), winners = array_replace(winners, $1, $2)
+			 WHERE $1 = ANY(seeds)`,
+			`UPDATE tournament_matches SET players = array_replace(players, $1, $2) WHERE $1 = ANY(players)`,
+			// The audit log is append-only to everyone but this function.
+			`SELECT redact_audit_log($1, $2)`,
+		} {
+			if _, err := tx.ExecContext(ctx, stmt, playerID, alias); err != nil {
+				return err
+			}
+		}
+		for _, stmt := range []string{
+			`DELETE FROM party_invites WHERE player_id = $1`,
+			// Friendships go with the player row.
+			`DELETE FROM players WHERE player_id = $1`,
+		} {
+			if _, err := tx.ExecContext(ctx, stmt, playerID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err == errNoChange {
+		return false, nil
+	}
+	if err != nil {
+		logger(ctx).Error("error anonymizing player", "player_id", playerID, "error", err)
+		return false, err
+	}
+	return true, nil
+}
+
+// PurgeAnonymizedPlayers strips the players anonymized before cutoff down
+// to the entries that rank on a leaderboard: their league history and their
+// queue, registration and removed entries are deleted, and so are players
+// left with no entries. It returns how many players it purged.
+func (r *Repository) PurgeAnonymizedPlayers(ctx context.Context, cutoff time.Time) (int, error) {
+	var n int
+	err := r.inTx(ctx, func(tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, `
+			UPDATE players SET purged_at = NOW()
+			WHERE anonymized_at < $1 AND purged_at IS NULL
+			RETURNING player_id
+		`, cutoff)
+		if err != nil {
+			return err
+		}
+		var ids []string
+		for rows.Next() {
+			var id string
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return err
+			}
+			ids = append(ids, id)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		n = len(ids)
+		if n == 0 {
+			return nil
+		}
+		for _, stmt := range []string{
+			`DELETE FROM player_tier_history WHERE player_id = ANY($1)`,
+			`DELETE FROM player_competitions
+			 WHERE player_id = ANY($1) AND (competition_id IS NULL OR status IN ('REGISTERED', 'REMOVED', 'DISQUALIFIED'))`,
+			`DELETE FROM players p WHERE p.player_id = ANY($1)
+			 AND NOT EXISTS (SELECT 1 FROM player_competitions pc WHERE pc.player_id = p.player_id)
+			 AND NOT EXISTS (SELECT 1 FROM tournaments t WHERE p.player_id = ANY(t.seeds))`,
+		} {
+			if _, err := tx.ExecContext(ctx, stmt, pq.Array(ids)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		logger(ctx).Error("error purging anonymized players", "error", err)
+		return 0, err
+	}
+	return n, nil
+}
+
+// ListPlayerEntries returns every entry playerID has, queue entries
+// included, oldest first.
+func (r *Repository) ListPlayerEntries(ctx context.Context, playerID string) ([]model.PlayerCompetition, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, player_id, competition_id, status, score, joined_at, updated_at, level, country_code, tier, party_id
+		FROM player_competitions
+		WHERE player_id = $1
+		ORDER BY joined_at, id
+	`, playerID)
+	if err != nil {
+		logger(ctx).Error("error listing player entries", "player_id", playerID, "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []model.PlayerCompetition{}
+	for rows.Next() {
+		var pc model.PlayerCompetition
+		if err := rows.Scan(&pc.ID, &pc.PlayerID, &pc.CompetitionID, &pc.Status, &pc.Score, &pc.JoinedAt, &pc.UpdatedAt,
+			&pc.Level, &pc.CountryCode, &pc.Tier, &pc.PartyID); err != nil {
+			logger(ctx).Error("error scanning player entry", "player_id", playerID, "error", err)
+			return nil, err
+		}
+		entries = append(entries, pc)
+	}
+	return entries, rows.Err()
+}
@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"leaderboard-service/internal/model"
+
+	"github.com/lib/pq"
+)
+
+// regionColumns are the columns read by scanRegion, from regions r.
+const regionColumns = `r.region_code, r.name, r.updated_at,
+	ARRAY(SELECT country_code FROM region_countries c WHERE c.region_code = r.region_code ORDER BY country_code)`
+
+func scanRegion(row rowScanner) (*model.Region, error) {
+	var region model.Region
+	if err := row.Scan(&region.RegionCode, &region.Name, &region.UpdatedAt, pq.Array(&region.Countries)); err != nil {
+		return nil, err
+	}
+	return &region, nil
+}
+
+// ListRegions returns every region with its countries, by code.
+func (r *Repository) ListRegions(ctx context.Context) ([]model.Region, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+regionColumns+` FROM regions r ORDER BY r.region_code`)
+	if err != nil {
+		logger(ctx).Error("error listing regions", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	regions := []model.Region{}
+	for rows.Next() {
+		region, err := scanRegion(rows)
+		if err != nil {
+			return nil, err
+		}
+		regions = append(regions, *region)
+	}
+	return regions, rows.Err()
+}
+
+func (r *Repository) GetRegion(ctx context.Context, regionCode string) (*model.Region, error) {
+	return scanRegion(r.db.QueryRowContext(ctx,
+		`SELECT `+regionColumns+` FROM regions r WHERE r.region_code = $1`,
+		regionCode,
+	))
+}
+
+// PutRegion creates region or replaces its name and countries, recording
+// audit in the same transaction. UpdatedAt is set from the database.
+func (r *Repository) PutRegion(ctx context.Context, region *model.Region, audit *model.AuditEntry) error {
+	_, err := r.adminChange(ctx, audit, func(tx *sql.Tx) (int64, error) {
+		err := tx.QueryRowContext(ctx, `
+			INSERT INTO regions (region_code, name) VALUES ($1, $2)
+			ON CONFLICT (region_code) DO UPDATE SET name = EXCLUDED.name, updated_at = NOW()
+			RETURNING updated_at
+		`, region.RegionCode, region.Name).Scan(&region.UpdatedAt)
+		if err != nil {
+			return 0, err
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM region_countries WHERE region_code = $1`, region.RegionCode); err != nil {
+			return 0, err
+		}
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO region_countries (region_code, country_code)
+			SELECT $1, unnest($2::text[])
+		`, region.RegionCode, pq.Array(region.Countries))
+		return 1, err
+	})
+	if err != nil {
+		logger(ctx).Error("error saving region", "region_code", region.RegionCode, "error", err)
+	}
+	return err
+}
+
+// DeleteRegion deletes a region, recording audit in the same transaction.
+// It reports false if there was no such region.
+func (r *Repository) DeleteRegion(ctx context.Context, regionCode string, audit *model.AuditEntry) (bool, error) {
+	ok, err := r.adminChange(ctx, audit, func(tx *sql.Tx) (int64, error) {
+		res, err := tx.ExecContext(ctx, `DELETE FROM regions WHERE region_code = $1`, regionCode)
+		if err != nil {
+			return 0, err
+		}
+		return res.RowsAffected()
+	})
+	if err != nil {
+		logger(ctx).Error("error deleting region", "region_code", regionCode, "error", err)
+	}
+	return ok, err
+}
+
+// ListTopPlayers returns up to limit players matching filter by their stats
+// over the competitions they completed: highest total score first, then
+// most wins. Players who completed none are left out, and so are
+// shadow-banned players, who do not count towards others' ranks either. A
+// player's current country decides which country and regions they count
+// towards.
+func (r *Repository) ListTopPlayers(ctx context.Context, filter model.GeoFilter, limit int) ([]model.PlayerStats, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		WITH ranked AS (
+			SELECT pc.player_id, pc.score,
+				ROW_NUMBER() OVER (PARTITION BY pc.competition_id ORDER BY pc.score DESC, pc.player_id ASC) AS rank
+			FROM player_competitions pc
+			WHERE pc.status = 'COMPLETED' AND NOT EXISTS (
+				SELECT 1 FROM players p WHERE p.player_id = pc.player_id AND p.shadow_banned_at IS NOT NULL
+			)
+		)
+		SELECT p.player_id, COALESCE(p.display_name, ''), COALESCE(p.avatar_url, ''), COALESCE(p.country_code, ''), COUNT(1), COUNT(1) FILTER (WHERE r.rank = 1),
+			SUM(r.score) AS total_score, MAX(r.score)
+		FROM players p
+		JOIN ranked r ON r.player_id = p.player_id
+		WHERE ($1 = '' OR p.country_code = $1)
+		  AND ($2 = '' OR p.country_code IN (SELECT country_code FROM region_countries WHERE region_code = $2))
+		GROUP BY p.player_id
+		ORDER BY total_score DESC, COUNT(1) FILTER (WHERE r.rank = 1) DESC, p.player_id
+		LIMIT NULLIF($3, 0)
+	`, filter.CountryCode, filter.RegionCode, limit)
+	if err != nil {
+		logger(ctx).Error("error listing top players", "country_code", filter.CountryCode, "region_code", filter.RegionCode, "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := []model.PlayerStats{}
+	for rows.Next() {
+		var s model.PlayerStats
+		if err := rows.Scan(&s.PlayerID, &s.DisplayName, &s.AvatarURL, &s.CountryCode, &s.Competitions, &s.Wins, &s.TotalScore, &s.BestScore); err != nil {
+			return nil, err
+		}
+		stats = append(stats, s)
+	}
+	return stats, rows.Err()
+}
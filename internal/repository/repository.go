@@ -3,13 +3,17 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
+	"leaderboard-service/internal/logging"
 	"leaderboard-service/internal/model"
-	"log"
+	"log/slog"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 type Repository struct {
@@ -20,80 +24,171 @@ func NewRepository(db *sql.DB) *Repository {
 	return &Repository{db: db}
 }
 
+// logger returns the request-scoped logger for repository messages.
+func logger(ctx context.Context) *slog.Logger {
+	return logging.FromContext(ctx).With("component", "repository")
+}
+
+// ErrDisplayNameTaken is returned when saving a player whose display name
+// another player already has, regardless of case.
+var ErrDisplayNameTaken = errors.New("display name taken")
+
+// displayNameTaken translates a violation of the display name index.
+func displayNameTaken(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" && pqErr.Constraint == "idx_players_display_name" {
+		return ErrDisplayNameTaken
+	}
+	return err
+}
+
+// CreatePlayer stores player and fills in its timestamps and version.
 func (r *Repository) CreatePlayer(ctx context.Context, player *model.Player) error {
-	_, err := r.db.ExecContext(ctx,
-		`INSERT INTO players (player_id, level, country_code) VALUES ($1, $2, $3)`,
-		player.PlayerID, player.Level, player.CountryCode,
-	)
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO players (player_id, level, country_code, tier, display_name, avatar_url, metadata)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, ''), $7)
+		RETURNING created_at, updated_at, version
+	`, player.PlayerID, player.Level, player.CountryCode, player.Tier, player.DisplayName, player.AvatarURL, nullJSON(player.Metadata),
+	).Scan(&player.CreatedAt, &player.UpdatedAt, &player.Version)
 	if err != nil {
-		log.Printf("[Repository] Error creating player %s: %v", player.PlayerID, err)
-		return err
+		logger(ctx).Error("error creating player", "player_id", player.PlayerID, "error", err)
+		return displayNameTaken(err)
 	}
-	log.Printf("[Repository] Successfully created player %s", player.PlayerID)
+	logger(ctx).Debug("created player", "player_id", player.PlayerID)
 	return nil
 }
 
 func (r *Repository) GetPlayerByID(ctx context.Context, playerID string) (*model.Player, error) {
 	var player model.Player
-	err := r.db.QueryRowContext(ctx,
-		`SELECT player_id, level, country_code FROM players WHERE player_id = $1`,
-		playerID,
-	).Scan(&player.PlayerID, &player.Level, &player.CountryCode)
+	var metadata []byte
+	err := r.db.QueryRowContext(ctx, `
+		SELECT player_id, level, country_code, tier, COALESCE(display_name, ''), COALESCE(avatar_url, ''), metadata, created_at, updated_at, version
+		FROM players WHERE player_id = $1
+	`, playerID).Scan(&player.PlayerID, &player.Level, &player.CountryCode, &player.Tier, &player.DisplayName, &player.AvatarURL, &metadata,
+		&player.CreatedAt, &player.UpdatedAt, &player.Version)
 	if err != nil {
-		log.Printf("[Repository] Error fetching player %s: %v", playerID, err)
+		logger(ctx).Warn("error fetching player", "player_id", playerID, "error", err)
 		return nil, err
 	}
-	log.Printf("[Repository] Successfully fetched player %s", playerID)
+	if metadata != nil {
+		player.Metadata = metadata
+	}
+	logger(ctx).Debug("fetched player", "player_id", playerID)
 	return &player, nil
 }
 
+// UpdatePlayer saves player's level, country and profile, bumping its
+// version and filling in the new version and update time. If
+// player.Version is not 0 the update only applies to that version of the
+// player; sql.ErrNoRows is returned if there is no such player or it has
+// moved on to another version.
 func (r *Repository) UpdatePlayer(ctx context.Context, player *model.Player) error {
-	_, err := r.db.ExecContext(ctx,
-		`UPDATE players SET level = $2, country_code = $3 WHERE player_id = $1`,
-		player.PlayerID, player.Level, player.CountryCode,
-	)
+	err := r.db.QueryRowContext(ctx, `
+		UPDATE players
+		SET level = $2, country_code = $3, display_name = NULLIF($4, ''), avatar_url = NULLIF($5, ''), metadata = $6,
+			version = version + 1, updated_at = NOW()
+		WHERE player_id = $1 AND ($7 = 0 OR version = $7)
+		RETURNING version, updated_at
+	`, player.PlayerID, player.Level, player.CountryCode, player.DisplayName, player.AvatarURL, nullJSON(player.Metadata), player.Version,
+	).Scan(&player.Version, &player.UpdatedAt)
 	if err != nil {
-		log.Printf("[Repository] Error updating player %s: %v", player.PlayerID, err)
-		return err
+		logger(ctx).Error("error updating player", "player_id", player.PlayerID, "error", err)
+		return displayNameTaken(err)
 	}
-	log.Printf("[Repository] Successfully updated player %s", player.PlayerID)
+	logger(ctx).Debug("updated player", "player_id", player.PlayerID, "version", player.Version)
 	return nil
 }
 
 // Competition methods
-func (r *Repository) GetActiveCompetition(ctx context.Context) (*model.Competition, error) {
+
+// competitionColumns are the columns read by scanCompetition.
+const competitionColumns = `competition_id, name, kind, started_at, ends_at, level, country_code, status, registration_opens_at, max_players,
+	template_id, scoring_mode, min_level, max_level, countries, rewards, tier`
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanCompetition(row rowScanner) (*model.Competition, error) {
 	var comp model.Competition
-	err := r.db.QueryRowContext(ctx,
-		`SELECT competition_id, started_at, ends_at, level, country_code, status FROM competitions WHERE status = 'ACTIVE' LIMIT 1`,
-	).Scan(&comp.CompetitionID, &comp.StartedAt, &comp.EndsAt, &comp.Level, &comp.CountryCode, &comp.Status)
+	var opensAt sql.NullTime
+	var rewards []byte
+	err := row.Scan(&comp.CompetitionID, &comp.Name, &comp.Kind, &comp.StartedAt, &comp.EndsAt, &comp.Level, &comp.CountryCode, &comp.Status, &opensAt, &comp.MaxPlayers,
+		&comp.TemplateID, &comp.ScoringMode, &comp.Eligibility.MinLevel, &comp.Eligibility.MaxLevel, pq.Array(&comp.Eligibility.Countries), &rewards, &comp.Tier)
 	if err != nil {
 		return nil, err
 	}
+	if opensAt.Valid {
+		comp.RegistrationOpensAt = &opensAt.Time
+	}
+	if err := json.Unmarshal(rewards, &comp.Rewards); err != nil {
+		return nil, err
+	}
 	return &comp, nil
 }
 
+// insertCompetition stores comp, defaulting its kind to MATCHMAKING and its
+// scoring mode to SUM.
+func insertCompetition(ctx context.Context, q queryer, comp *model.Competition) error {
+	if comp.Kind == "" {
+		comp.Kind = model.CompetitionKindMatchmaking
+	}
+	if comp.ScoringMode == "" {
+		comp.ScoringMode = model.ScoringSum
+	}
+	rewards, err := marshalRewards(comp.Rewards)
+	if err != nil {
+		return err
+	}
+	_, err = q.ExecContext(ctx, `
+		INSERT INTO competitions (competition_id, name, kind, started_at, ends_at, level, country_code, status, registration_opens_at, max_players,
+			template_id, scoring_mode, min_level, max_level, countries, rewards, tier)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+	`, comp.CompetitionID, comp.Name, comp.Kind, comp.StartedAt, comp.EndsAt, comp.Level, comp.CountryCode, comp.Status, comp.RegistrationOpensAt, comp.MaxPlayers,
+		comp.TemplateID, comp.ScoringMode, comp.Eligibility.MinLevel, comp.Eligibility.MaxLevel, pq.Array(nonNil(comp.Eligibility.Countries)), rewards, comp.Tier)
+	return err
+}
+
+// marshalRewards encodes a reward table for a JSONB column, storing nil as
+// an empty table.
+func marshalRewards(rewards []model.Reward) ([]byte, error) {
+	if rewards == nil {
+		rewards = []model.Reward{}
+	}
+	return json.Marshal(rewards)
+}
+
+// nonNil stores a nil list as an empty array rather than NULL.
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
+// GetActiveCompetition returns an active matchmaking competition, if any.
+// Scheduled competitions run alongside matchmaking and are not considered.
+func (r *Repository) GetActiveCompetition(ctx context.Context) (*model.Competition, error) {
+	return scanCompetition(r.db.QueryRowContext(ctx,
+		`SELECT `+competitionColumns+` FROM competitions WHERE status = 'ACTIVE' AND kind = 'MATCHMAKING' LIMIT 1`,
+	))
+}
+
 func (r *Repository) CreateCompetition(ctx context.Context, comp *model.Competition) error {
-	log.Printf("[Repository] Creating competition %s", comp.CompetitionID.String())
-	_, err := r.db.ExecContext(ctx, `
-		INSERT INTO competitions (competition_id, started_at, ends_at, level, country_code, status)
-		VALUES ($1, $2, $3, $4, $5, $6)
-	`, comp.CompetitionID, comp.StartedAt, comp.EndsAt, comp.Level, comp.CountryCode, comp.Status)
+	logger(ctx).Info("creating competition", "competition_id", comp.CompetitionID)
+	err := insertCompetition(ctx, r.db, comp)
 	if err != nil {
-		log.Printf("[Repository] Error creating competition: %v", err)
+		logger(ctx).Error("error creating competition", "competition_id", comp.CompetitionID, "error", err)
 	}
 	return err
 }
 
 func (r *Repository) GetCompetitionByID(ctx context.Context, competitionID string) (*model.Competition, error) {
-	var comp model.Competition
-	err := r.db.QueryRowContext(ctx,
-		`SELECT competition_id, started_at, ends_at, level, country_code, status FROM competitions WHERE competition_id = $1`,
+	return scanCompetition(r.db.QueryRowContext(ctx,
+		`SELECT `+competitionColumns+` FROM competitions WHERE competition_id = $1`,
 		competitionID,
-	).Scan(&comp.CompetitionID, &comp.StartedAt, &comp.EndsAt, &comp.Level, &comp.CountryCode, &comp.Status)
-	if err != nil {
-		return nil, err
-	}
-	return &comp, nil
+	))
 }
 
 func (r *Repository) UpdateCompetition(ctx context.Context, comp *model.Competition) error {
@@ -107,70 +202,77 @@ func (r *Repository) UpdateCompetition(ctx context.Context, comp *model.Competit
 // PlayerCompetition methods
 func (r *Repository) CreatePlayerCompetition(ctx context.Context, pc *model.PlayerCompetition) error {
 	_, err := r.db.ExecContext(ctx,
-		`INSERT INTO player_competitions (player_id, competition_id, status, score, joined_at, updated_at, level, country_code) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
-		pc.PlayerID, pc.CompetitionID, pc.Status, pc.Score, pc.JoinedAt, pc.UpdatedAt, pc.Level, pc.CountryCode,
+		`INSERT INTO player_competitions (player_id, competition_id, status, score, joined_at, updated_at, level, country_code, tier) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		pc.PlayerID, pc.CompetitionID, pc.Status, pc.Score, pc.JoinedAt, pc.UpdatedAt, pc.Level, pc.CountryCode, pc.Tier,
 	)
 	if err != nil {
-		log.Printf("[Repository] Error creating player_competition for player %s: %v", pc.PlayerID, err)
+		logger(ctx).Error("error creating player_competition", "player_id", pc.PlayerID, "error", err)
 		return err
 	}
-	log.Printf("[Repository] Successfully created player_competition for player %s", pc.PlayerID)
+	logger(ctx).Debug("created player_competition", "player_id", pc.PlayerID)
 	return nil
 }
 
 func (r *Repository) GetPlayerCompetitionByID(ctx context.Context, id int) (*model.PlayerCompetition, error) {
 	var pc model.PlayerCompetition
 	err := r.db.QueryRowContext(ctx,
-		`SELECT id, player_id, competition_id, status, score, joined_at, updated_at, level, country_code FROM player_competitions WHERE id = $1`,
+		`SELECT id, player_id, competition_id, status, score, joined_at, updated_at, level, country_code, tier FROM player_competitions WHERE id = $1`,
 		id,
-	).Scan(&pc.ID, &pc.PlayerID, &pc.CompetitionID, &pc.Status, &pc.Score, &pc.JoinedAt, &pc.UpdatedAt, &pc.Level, &pc.CountryCode)
+	).Scan(&pc.ID, &pc.PlayerID, &pc.CompetitionID, &pc.Status, &pc.Score, &pc.JoinedAt, &pc.UpdatedAt, &pc.Level, &pc.CountryCode, &pc.Tier)
 	if err != nil {
-		log.Printf("[Repository] Error fetching player_competition %d: %v", id, err)
+		logger(ctx).Warn("error fetching player_competition", "player_competition_id", id, "error", err)
 		return nil, err
 	}
-	log.Printf("[Repository] Successfully fetched player_competition %d", id)
+	logger(ctx).Debug("fetched player_competition", "player_competition_id", id)
 	return &pc, nil
 }
 
 func (r *Repository) UpdatePlayerCompetition(ctx context.Context, pc *model.PlayerCompetition) error {
 	_, err := r.db.ExecContext(ctx,
-		`UPDATE player_competitions SET player_id = $2, competition_id = $3, status = $4, score = $5, joined_at = $6, updated_at = $7, level = $8, country_code = $9 WHERE id = $1`,
-		pc.ID, pc.PlayerID, pc.CompetitionID, pc.Status, pc.Score, pc.JoinedAt, pc.UpdatedAt, pc.Level, pc.CountryCode,
+		`UPDATE player_competitions SET player_id = $2, competition_id = $3, status = $4, score = $5, joined_at = $6, updated_at = $7, level = $8, country_code = $9, tier = $10 WHERE id = $1`,
+		pc.ID, pc.PlayerID, pc.CompetitionID, pc.Status, pc.Score, pc.JoinedAt, pc.UpdatedAt, pc.Level, pc.CountryCode, pc.Tier,
 	)
 	if err != nil {
-		log.Printf("[Repository] Error updating player_competition %d: %v", pc.ID, err)
+		logger(ctx).Error("error updating player_competition", "player_competition_id", pc.ID, "error", err)
 		return err
 	}
-	log.Printf("[Repository] Successfully updated player_competition %d", pc.ID)
+	logger(ctx).Debug("updated player_competition", "player_competition_id", pc.ID)
 	return nil
 }
 
 func (r *Repository) GetLatestPlayerCompetition(ctx context.Context, playerID string) (*model.PlayerCompetition, error) {
 	var pc model.PlayerCompetition
 	err := r.db.QueryRowContext(ctx, `
-		SELECT id, player_id, competition_id, status, score, joined_at, updated_at, level, country_code
+		SELECT id, player_id, competition_id, status, score, joined_at, updated_at, level, country_code, tier
 		FROM player_competitions
 		WHERE player_id = $1
 		ORDER BY updated_at DESC
 		LIMIT 1
-	`, playerID).Scan(&pc.ID, &pc.PlayerID, &pc.CompetitionID, &pc.Status, &pc.Score, &pc.JoinedAt, &pc.UpdatedAt, &pc.Level, &pc.CountryCode)
+	`, playerID).Scan(&pc.ID, &pc.PlayerID, &pc.CompetitionID, &pc.Status, &pc.Score, &pc.JoinedAt, &pc.UpdatedAt, &pc.Level, &pc.CountryCode, &pc.Tier)
 	if err != nil {
-		log.Printf("[Repository] Error fetching latest player_competition for player %s: %v", playerID, err)
+		logger(ctx).Debug("no latest player_competition", "player_id", playerID, "error", err)
 		return nil, err
 	}
-	log.Printf("[Repository] Successfully fetched latest player_competition for player %s", playerID)
+	logger(ctx).Debug("fetched latest player_competition", "player_id", playerID)
 	return &pc, nil
 }
 
-func (r *Repository) GetLeaderboardByCompetitionID(ctx context.Context, competitionID string) ([]model.PlayerCompetition, error) {
+// GetLeaderboardByCompetitionID returns a competition's standings as viewer
+// sees them: only active and completed entries count, and shadow-banned
+// players other than the viewer are left out. Each entry carries the
+// player's display name and avatar.
+func (r *Repository) GetLeaderboardByCompetitionID(ctx context.Context, competitionID string, viewer model.Viewer) ([]model.PlayerCompetition, error) {
 	rows, err := r.db.QueryContext(ctx, `
-		SELECT id, player_id, competition_id, status, score, joined_at, updated_at, level, country_code
-		FROM player_competitions
-		WHERE competition_id = $1
-		ORDER BY score DESC, player_id ASC
-	`, competitionID)
+		SELECT pc.id, pc.player_id, pc.competition_id, pc.status, pc.score, pc.joined_at, pc.updated_at, pc.level, pc.country_code, pc.tier,
+			COALESCE(p.display_name, ''), COALESCE(p.avatar_url, '')
+		FROM player_competitions pc
+		LEFT JOIN players p ON p.player_id = pc.player_id
+		WHERE pc.competition_id = $1 AND pc.status IN ('ACTIVE', 'COMPLETED')
+		  AND ($2 OR pc.player_id = $3 OR p.shadow_banned_at IS NULL)
+		ORDER BY pc.score DESC, pc.player_id ASC
+	`, competitionID, viewer.All, viewer.PlayerID)
 	if err != nil {
-		log.Printf("[Repository] Error fetching leaderboard for competition %s: %v", competitionID, err)
+		logger(ctx).Error("error fetching leaderboard", "competition_id", competitionID, "error", err)
 		return nil, err
 	}
 	defer rows.Close()
@@ -178,42 +280,70 @@ func (r *Repository) GetLeaderboardByCompetitionID(ctx context.Context, competit
 	var pcs []model.PlayerCompetition
 	for rows.Next() {
 		var pc model.PlayerCompetition
-		if err := rows.Scan(&pc.ID, &pc.PlayerID, &pc.CompetitionID, &pc.Status, &pc.Score, &pc.JoinedAt, &pc.UpdatedAt, &pc.Level, &pc.CountryCode); err != nil {
-			log.Printf("[Repository] Error scanning leaderboard entry for competition %s: %v", competitionID, err)
+		if err := rows.Scan(&pc.ID, &pc.PlayerID, &pc.CompetitionID, &pc.Status, &pc.Score, &pc.JoinedAt, &pc.UpdatedAt, &pc.Level, &pc.CountryCode, &pc.Tier,
+			&pc.DisplayName, &pc.AvatarURL); err != nil {
+			logger(ctx).Error("error scanning leaderboard entry", "competition_id", competitionID, "error", err)
 			return nil, err
 		}
 		pcs = append(pcs, pc)
 	}
-	log.Printf("[Repository] Successfully fetched leaderboard for competition %s", competitionID)
+	logger(ctx).Debug("fetched leaderboard", "competition_id", competitionID, "entries", len(pcs))
 	return pcs, nil
 }
 
 func (r *Repository) GetActivePlayerCompetition(ctx context.Context, playerID string) (*model.PlayerCompetition, error) {
 	var pc model.PlayerCompetition
 	err := r.db.QueryRowContext(ctx, `
-		SELECT pc.id, pc.player_id, pc.competition_id, pc.status, pc.score, pc.joined_at, pc.updated_at, pc.level, pc.country_code
+		SELECT pc.id, pc.player_id, pc.competition_id, pc.status, pc.score, pc.joined_at, pc.updated_at, pc.level, pc.country_code, pc.tier
 		FROM player_competitions pc
 		JOIN competitions c ON pc.competition_id = c.competition_id
 		WHERE pc.player_id = $1 AND pc.status = 'ACTIVE' AND c.ends_at > NOW()
+		ORDER BY c.started_at DESC, pc.id DESC
 		LIMIT 1
-	`, playerID).Scan(&pc.ID, &pc.PlayerID, &pc.CompetitionID, &pc.Status, &pc.Score, &pc.JoinedAt, &pc.UpdatedAt, &pc.Level, &pc.CountryCode)
+	`, playerID).Scan(&pc.ID, &pc.PlayerID, &pc.CompetitionID, &pc.Status, &pc.Score, &pc.JoinedAt, &pc.UpdatedAt, &pc.Level, &pc.CountryCode, &pc.Tier)
 	if err != nil {
 		return nil, err
 	}
 	return &pc, nil
 }
 
-func (r *Repository) GetWaitingPlayers(ctx context.Context) ([]model.PlayerCompetition, error) {
-	log.Println("[Repository] Fetching waiting players")
+// GetWaitingPlayers returns up to limit waiting players of each league
+// tier, longest-waiting first, plus the rest of any party among them, so
+// that a party is never split and a crowded tier does not keep the others
+// out. A party counts in the highest tier among its members, the tier it
+// is matched in. A limit of 0 returns them all.
+func (r *Repository) GetWaitingPlayers(ctx context.Context, limit int) ([]model.PlayerCompetition, error) {
+	logger(ctx).Debug("fetching waiting players", "limit", limit)
+	tiers := make([]string, len(model.Tiers))
+	for i, t := range model.Tiers {
+		tiers[i] = string(t)
+	}
 	rows, err := r.db.QueryContext(ctx, `
-		SELECT id, player_id, competition_id, status, score, joined_at, updated_at, level, country_code
+		WITH waiting AS (
+			SELECT id, party_id, joined_at, CASE WHEN party_id IS NULL THEN tier ELSE (
+				SELECT m.tier FROM player_competitions m
+				WHERE m.status = 'WAITING' AND m.party_id = pc.party_id
+				ORDER BY array_position($2::text[], m.tier) DESC NULLS LAST
+				LIMIT 1
+			) END AS unit_tier
+			FROM player_competitions pc
+			WHERE status = 'WAITING'
+		), head AS (
+			SELECT id, party_id FROM (
+				SELECT id, party_id, ROW_NUMBER() OVER (PARTITION BY unit_tier ORDER BY joined_at, id) AS n
+				FROM waiting
+			) w
+			WHERE $1 = 0 OR n <= $1
+		)
+		SELECT id, player_id, competition_id, status, score, joined_at, updated_at, level, country_code, tier, party_id
 		FROM player_competitions
-		WHERE status = 'WAITING'
-		ORDER BY joined_at
-		LIMIT 10
-	`)
+		WHERE status = 'WAITING' AND (
+			id IN (SELECT id FROM head) OR party_id IN (SELECT party_id FROM head WHERE party_id IS NOT NULL)
+		)
+		ORDER BY joined_at, id
+	`, limit, pq.Array(tiers))
 	if err != nil {
-		log.Printf("[Repository] Error fetching waiting players: %v", err)
+		logger(ctx).Error("error fetching waiting players", "error", err)
 		return nil, err
 	}
 	defer rows.Close()
@@ -221,8 +351,8 @@ func (r *Repository) GetWaitingPlayers(ctx context.Context) ([]model.PlayerCompe
 	var pcs []model.PlayerCompetition
 	for rows.Next() {
 		var pc model.PlayerCompetition
-		if err := rows.Scan(&pc.ID, &pc.PlayerID, &pc.CompetitionID, &pc.Status, &pc.Score, &pc.JoinedAt, &pc.UpdatedAt, &pc.Level, &pc.CountryCode); err != nil {
-			log.Printf("[Repository] Error scanning waiting player: %v", err)
+		if err := rows.Scan(&pc.ID, &pc.PlayerID, &pc.CompetitionID, &pc.Status, &pc.Score, &pc.JoinedAt, &pc.UpdatedAt, &pc.Level, &pc.CountryCode, &pc.Tier, &pc.PartyID); err != nil {
+			logger(ctx).Error("error scanning waiting player", "error", err)
 			return nil, err
 		}
 		pcs = append(pcs, pc)
@@ -231,7 +361,7 @@ func (r *Repository) GetWaitingPlayers(ctx context.Context) ([]model.PlayerCompe
 }
 
 func (r *Repository) UpdatePlayerCompetitionsToActive(ctx context.Context, playerIDs []string, competitionID uuid.UUID, endsAt time.Time) error {
-	log.Printf("[Repository] Updating %d players to ACTIVE for competition %s", len(playerIDs), competitionID.String())
+	logger(ctx).Info("updating players to ACTIVE", "competition_id", competitionID, "players", len(playerIDs))
 	if len(playerIDs) == 0 {
 		return nil
 	}
@@ -253,41 +383,65 @@ func (r *Repository) UpdatePlayerCompetitionsToActive(ctx context.Context, playe
 	`
 	_, err := r.db.ExecContext(ctx, query, args...)
 	if err != nil {
-		log.Printf("[Repository] Error updating player competitions: %v", err)
+		logger(ctx).Error("error updating player competitions", "competition_id", competitionID, "error", err)
 	}
 	return err
 }
 
+// AddScoreToPlayer adds score to the player's active entry, or keeps the
+// better of the two in a BEST competition. A player only ever enters one
+// running competition at a time; should they still have two, they score in
+// the one that started last, the same entry GetActivePlayerCompetition
+// returns.
 func (r *Repository) AddScoreToPlayer(ctx context.Context, playerID string, score int) error {
-	log.Printf("[Repository] Adding score %d to player %s", score, playerID)
+	logger(ctx).Debug("adding score", "player_id", playerID, "score", score)
 	_, err := r.db.ExecContext(ctx, `
 		UPDATE player_competitions pc
-		SET score = score + $1, updated_at = NOW()
+		SET score = CASE c.scoring_mode WHEN 'BEST' THEN GREATEST(pc.score, $1) ELSE pc.score + $1 END, updated_at = NOW()
 		FROM competitions c
-		WHERE pc.player_id = $2
-		  AND pc.status = 'ACTIVE'
-		  AND pc.competition_id = c.competition_id
-		  AND c.ends_at > NOW()
+		WHERE c.competition_id = pc.competition_id AND pc.id = (
+			SELECT pc.id
+			FROM player_competitions pc
+			JOIN competitions c ON pc.competition_id = c.competition_id
+			WHERE pc.player_id = $2 AND pc.status = 'ACTIVE' AND c.ends_at > NOW()
+			ORDER BY c.started_at DESC, pc.id DESC
+			LIMIT 1
+		)
 	`, score, playerID)
 	if err != nil {
-		log.Printf("[Repository] Error adding score: %v", err)
+		logger(ctx).Error("error adding score", "player_id", playerID, "error", err)
 	}
 	return err
 }
 
-func (r *Repository) CompleteFinishedCompetitions(ctx context.Context) error {
+func (r *Repository) CompleteFinishedCompetitions(ctx context.Context) ([]uuid.UUID, error) {
 	// 1. Mark competitions as COMPLETED
-	res, err := r.db.ExecContext(ctx, `
+	rows, err := r.db.QueryContext(ctx, `
 		UPDATE competitions
 		SET status = 'COMPLETED'
 		WHERE ends_at <= NOW() AND status = 'ACTIVE'
+		RETURNING competition_id
 	`)
 	if err != nil {
-		log.Printf("[Repository] Error completing finished competitions: %v", err)
-		return err
+		logger(ctx).Error("error completing finished competitions", "error", err)
+		return nil, err
 	}
-	count, _ := res.RowsAffected()
-	log.Printf("[Repository] Marked %d competitions as COMPLETED", count)
+	defer rows.Close()
+
+	var completed []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			logger(ctx).Error("error scanning completed competition", "error", err)
+			return nil, err
+		}
+		completed = append(completed, id)
+	}
+	if err := rows.Err(); err != nil {
+		logger(ctx).Error("error completing finished competitions", "error", err)
+		return nil, err
+	}
+	logger(ctx).Debug("marked competitions as COMPLETED", "count", len(completed))
 
 	// 2. Mark related player_competitions as COMPLETED
 	_, err = r.db.ExecContext(ctx, `
@@ -298,10 +452,10 @@ func (r *Repository) CompleteFinishedCompetitions(ctx context.Context) error {
 		) AND status = 'ACTIVE'
 	`)
 	if err != nil {
-		log.Printf("[Repository] Error completing player_competitions: %v", err)
-		return err
+		logger(ctx).Error("error completing player_competitions", "error", err)
+		return nil, err
 	}
-	return nil
+	return completed, nil
 }
 
 func (r *Repository) IsPlayerInWaitingQueue(ctx context.Context, playerID string) (bool, error) {
@@ -310,12 +464,128 @@ func (r *Repository) IsPlayerInWaitingQueue(ctx context.Context, playerID string
 		SELECT COUNT(1) FROM player_competitions WHERE player_id = $1 AND status = 'WAITING'
 	`, playerID).Scan(&count)
 	if err != nil {
-		log.Printf("[Repository] Error checking waiting queue for player %s: %v", playerID, err)
+		logger(ctx).Error("error checking waiting queue", "player_id", playerID, "error", err)
 		return false, err
 	}
 	return count > 0, nil
 }
 
+// CancelWaitingPlayerCompetition takes playerID out of the matchmaking
+// queue. A party queues as a unit, so the rest of their party leaves it with
+// them.
+func (r *Repository) CancelWaitingPlayerCompetition(ctx context.Context, playerID string) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE player_competitions
+		SET status = 'CANCELLED', updated_at = NOW()
+		WHERE status = 'WAITING' AND (player_id = $1 OR party_id IN (
+			SELECT party_id FROM player_competitions WHERE player_id = $1 AND status = 'WAITING' AND party_id IS NOT NULL
+		))
+	`, playerID)
+	if err != nil {
+		logger(ctx).Error("error removing player from waiting queue", "player_id", playerID, "error", err)
+		return false, err
+	}
+	count, _ := res.RowsAffected()
+	logger(ctx).Debug("removed player from waiting queue", "player_id", playerID, "rows", count)
+	return count > 0, nil
+}
+
+// CountWaitingPlayersByLevel returns the number of queued players per level.
+func (r *Repository) CountWaitingPlayersByLevel(ctx context.Context) (map[int]int, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT level, COUNT(1) FROM player_competitions WHERE status = 'WAITING' GROUP BY level
+	`)
+	if err != nil {
+		logger(ctx).Error("error counting waiting players", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[int]int)
+	for rows.Next() {
+		var level, count int
+		if err := rows.Scan(&level, &count); err != nil {
+			logger(ctx).Error("error scanning waiting player count", "error", err)
+			return nil, err
+		}
+		counts[level] = count
+	}
+	return counts, rows.Err()
+}
+
+func (r *Repository) CountActiveCompetitions(ctx context.Context) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(1) FROM competitions WHERE status = 'ACTIVE'
+	`).Scan(&count)
+	if err != nil {
+		logger(ctx).Error("error counting active competitions", "error", err)
+		return 0, err
+	}
+	return count, nil
+}
+
+// CreateAuditEntry appends entry to the audit log, filling in its ID and
+// time.
+func (r *Repository) CreateAuditEntry(ctx context.Context, entry *model.AuditEntry) error {
+	return insertAuditEntry(ctx, r.db, entry)
+}
+
+// insertAuditEntry writes entry through q, so that admin changes can record
+// it in their own transaction.
+func insertAuditEntry(ctx context.Context, q queryer, entry *model.AuditEntry) error {
+	err := q.QueryRowContext(ctx, `
+		INSERT INTO audit_log (actor, action, target, reason, before, after)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, occurred_at
+	`, entry.Actor, entry.Action, entry.Target, entry.Reason, nullJSON(entry.Before), nullJSON(entry.After)).Scan(&entry.ID, &entry.OccurredAt)
+	if err != nil {
+		logger(ctx).Error("error writing audit entry", "action", entry.Action, "target", entry.Target, "error", err)
+		return err
+	}
+	return nil
+}
+
+// ListAuditEntries returns matching audit entries, newest first.
+func (r *Repository) ListAuditEntries(ctx context.Context, filter model.AuditFilter) ([]model.AuditEntry, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, occurred_at, actor, action, target, reason, before, after
+		FROM audit_log
+		WHERE ($1 = '' OR actor = $1)
+		  AND ($2 = '' OR action = $2)
+		  AND ($3 = '' OR target = $3)
+		  AND ($5 = '' OR target = 'player/' || $5 OR right(target, length($5) + 8) = '/player/' || $5)
+		ORDER BY occurred_at DESC, id DESC
+		LIMIT NULLIF($4, 0)
+	`, filter.Actor, filter.Action, filter.Target, filter.Limit, filter.Player)
+	if err != nil {
+		logger(ctx).Error("error listing audit entries", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []model.AuditEntry{}
+	for rows.Next() {
+		var e model.AuditEntry
+		var before, after []byte
+		if err := rows.Scan(&e.ID, &e.OccurredAt, &e.Actor, &e.Action, &e.Target, &e.Reason, &before, &after); err != nil {
+			logger(ctx).Error("error scanning audit entry", "error", err)
+			return nil, err
+		}
+		e.Before, e.After = before, after
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// nullJSON stores an empty document as SQL NULL.
+func nullJSON(doc []byte) interface{} {
+	if len(doc) == 0 {
+		return nil
+	}
+	return string(doc)
+}
+
 // Repository interface for dependency injection
 // (should match the one in service)
 type RepositoryInterface interface {
@@ -334,15 +604,79 @@ type RepositoryInterface interface {
 	UpdatePlayerCompetition(ctx context.Context, pc *model.PlayerCompetition) error
 
 	GetLatestPlayerCompetition(ctx context.Context, playerID string) (*model.PlayerCompetition, error)
-	GetLeaderboardByCompetitionID(ctx context.Context, competitionID string) ([]model.PlayerCompetition, error)
+	GetLeaderboardByCompetitionID(ctx context.Context, competitionID string, viewer model.Viewer) ([]model.PlayerCompetition, error)
 	GetActivePlayerCompetition(ctx context.Context, playerID string) (*model.PlayerCompetition, error)
 
-	GetWaitingPlayers(ctx context.Context) ([]model.PlayerCompetition, error)
+	GetWaitingPlayers(ctx context.Context, limit int) ([]model.PlayerCompetition, error)
 	UpdatePlayerCompetitionsToActive(ctx context.Context, playerIDs []string, competitionID uuid.UUID, endsAt time.Time) error
 
 	AddScoreToPlayer(ctx context.Context, playerID string, score int) error
 
-	CompleteFinishedCompetitions(ctx context.Context) error
+	CompleteFinishedCompetitions(ctx context.Context) ([]uuid.UUID, error)
 
 	IsPlayerInWaitingQueue(ctx context.Context, playerID string) (bool, error)
+	CancelWaitingPlayerCompetition(ctx context.Context, playerID string) (bool, error)
+
+	CountWaitingPlayersByLevel(ctx context.Context) (map[int]int, error)
+	CountActiveCompetitions(ctx context.Context) (int, error)
+
+	CreateAuditEntry(ctx context.Context, entry *model.AuditEntry) error
+	ListAuditEntries(ctx context.Context, filter model.AuditFilter) ([]model.AuditEntry, error)
+
+	ListCompetitions(ctx context.Context, filter model.CompetitionFilter) ([]model.Competition, error)
+	GetCompetitionPlayer(ctx context.Context, competitionID uuid.UUID, playerID string) (*model.PlayerCompetition, error)
+	CancelCompetition(ctx context.Context, competitionID uuid.UUID, audit *model.AuditEntry) (bool, error)
+	CompleteCompetition(ctx context.Context, competitionID uuid.UUID, audit *model.AuditEntry) (bool, error)
+	SetCompetitionEndsAt(ctx context.Context, competitionID uuid.UUID, endsAt time.Time, audit *model.AuditEntry) (bool, error)
+	ExcludeCompetitionPlayer(ctx context.Context, competitionID uuid.UUID, playerID string, status model.PlayerStatus, audit *model.AuditEntry) (bool, error)
+	SetCompetitionScore(ctx context.Context, competitionID uuid.UUID, playerID string, from, to int, audit *model.AuditEntry) (bool, error)
+
+	CreateScheduledCompetition(ctx context.Context, comp *model.Competition, audit *model.AuditEntry) error
+	OpenScheduledCompetitions(ctx context.Context) ([]uuid.UUID, error)
+	StartScheduledCompetitions(ctx context.Context) ([]model.Competition, error)
+	RegisterCompetitionPlayer(ctx context.Context, competitionID uuid.UUID, player *model.Player) (bool, error)
+	CancelCompetitionRegistration(ctx context.Context, competitionID uuid.UUID, playerID string) (bool, error)
+
+	ListCompetitionTemplates(ctx context.Context) ([]model.CompetitionTemplate, error)
+	GetCompetitionTemplate(ctx context.Context, templateID uuid.UUID) (*model.CompetitionTemplate, error)
+	CreateCompetitionTemplate(ctx context.Context, tpl *model.CompetitionTemplate, audit *model.AuditEntry) error
+	UpdateCompetitionTemplate(ctx context.Context, tpl *model.CompetitionTemplate, lastUpdated time.Time, audit *model.AuditEntry) (bool, error)
+	DeleteCompetitionTemplate(ctx context.Context, templateID uuid.UUID, audit *model.AuditEntry) (bool, error)
+	DueCompetitionTemplates(ctx context.Context) ([]model.CompetitionTemplate, error)
+	StartTemplateOccurrence(ctx context.Context, templateID uuid.UUID, due time.Time, next *time.Time, comp *model.Competition) ([]uuid.UUID, bool, error)
+
+	UnsettledLeagueCompetitions(ctx context.Context) ([]model.Competition, error)
+	ApplyTierMovements(ctx context.Context, competitionID uuid.UUID, moves []model.TierMovement) ([]model.TierMovement, error)
+	ListTierHistory(ctx context.Context, playerID string, limit int) ([]model.TierMovement, error)
+
+	CreateTournament(ctx context.Context, t *model.Tournament, matches []model.TournamentMatch, comps []model.Competition, audit *model.AuditEntry) error
+	GetTournament(ctx context.Context, tournamentID uuid.UUID) (*model.Tournament, error)
+	ActiveTournaments(ctx context.Context) ([]model.Tournament, error)
+	ListTournamentMatches(ctx context.Context, tournamentID uuid.UUID) ([]model.TournamentMatch, error)
+	StartTournamentRound(ctx context.Context, tournamentID uuid.UUID, round int, matches []model.TournamentMatch, comps []model.Competition) (bool, error)
+	CompleteTournament(ctx context.Context, tournamentID uuid.UUID, round int, winners []string) (bool, error)
+
+	CreateParty(ctx context.Context, party *model.Party) (bool, error)
+	GetParty(ctx context.Context, partyID uuid.UUID) (*model.Party, error)
+	GetPlayerParty(ctx context.Context, playerID string) (*model.Party, error)
+	InvitePartyPlayer(ctx context.Context, partyID uuid.UUID, playerID string) (bool, error)
+	AcceptPartyInvite(ctx context.Context, partyID uuid.UUID, playerID string, maxSize int) (bool, error)
+	LeaveParty(ctx context.Context, partyID uuid.UUID, playerID string) (bool, error)
+	QueueParty(ctx context.Context, partyID uuid.UUID, entries []model.PlayerCompetition) (bool, error)
+	AddFriends(ctx context.Context, playerID string, friendIDs []string) ([]string, error)
+	RemoveFriend(ctx context.Context, playerID, friendID string) (bool, error)
+	ListFriends(ctx context.Context, playerID string) ([]string, error)
+	GetPlayerStats(ctx context.Context, playerIDs []string, viewer model.Viewer) ([]model.PlayerStats, error)
+	ListRegions(ctx context.Context) ([]model.Region, error)
+	GetRegion(ctx context.Context, regionCode string) (*model.Region, error)
+	PutRegion(ctx context.Context, region *model.Region, audit *model.AuditEntry) error
+	DeleteRegion(ctx context.Context, regionCode string, audit *model.AuditEntry) (bool, error)
+	ListTopPlayers(ctx context.Context, filter model.GeoFilter, limit int) ([]model.PlayerStats, error)
+	ListPlayerHistory(ctx context.Context, playerID string, filter model.PlayerHistoryFilter) ([]model.CompetitionResult, error)
+	GetPlayerHistorySummary(ctx context.Context, playerID string, filter model.PlayerHistoryFilter) (*model.PlayerHistorySummary, error)
+	AnonymizePlayer(ctx context.Context, playerID, alias string) (bool, error)
+	PurgeAnonymizedPlayers(ctx context.Context, cutoff time.Time) (int, error)
+	ListPlayerEntries(ctx context.Context, playerID string) ([]model.PlayerCompetition, error)
+	GetPlayerModeration(ctx context.Context, playerID string) (*model.Moderation, error)
+	SetPlayerSanction(ctx context.Context, playerID string, sanction model.Sanction, value *time.Time, audit *model.AuditEntry) (bool, error)
 }
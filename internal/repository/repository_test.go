@@ -349,7 +349,7 @@ func TestGetLeaderboardByCompetitionID(t *testing.T) {
 	if err != nil {
 		t.Fatalf("CreatePlayerCompetition failed: %v", err)
 	}
-	entries, err := repo.GetLeaderboardByCompetitionID(context.Background(), compID.String())
+	entries, err := repo.GetLeaderboardByCompetitionID(context.Background(), compID.String(), model.Viewer{})
 	if err != nil {
 		t.Fatalf("GetLeaderboardByCompetitionID failed: %v", err)
 	}
@@ -412,7 +412,7 @@ func TestGetWaitingPlayers(t *testing.T) {
 	if err != nil {
 		t.Fatalf("CreatePlayerCompetition failed: %v", err)
 	}
-	waiting, err := repo.GetWaitingPlayers(context.Background())
+	waiting, err := repo.GetWaitingPlayers(context.Background(), 0)
 	if err != nil {
 		t.Fatalf("GetWaitingPlayers failed: %v", err)
 	}
@@ -428,6 +428,43 @@ func TestGetWaitingPlayers(t *testing.T) {
 	}
 }
 
+func TestGetWaitingPlayers_LimitsEachTier(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewRepository(db)
+	ctx := context.Background()
+	// Three SILVER players queued ahead of a GOLD one, long enough ago to
+	// head their tiers' queues.
+	queued := []struct {
+		id   string
+		tier model.Tier
+	}{{"testwaittier1", model.TierSilver}, {"testwaittier2", model.TierSilver}, {"testwaittier3", model.TierSilver}, {"testwaittier4", model.TierGold}}
+	start := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, q := range queued {
+		if err := repo.CreatePlayer(ctx, &model.Player{PlayerID: q.id, Level: 1, CountryCode: "ZZ", Tier: q.tier}); err != nil {
+			t.Fatalf("CreatePlayer failed: %v", err)
+		}
+		defer cleanupPlayer(t, db, q.id)
+		defer cleanupPlayerCompetitionByPlayerID(t, db, q.id)
+		joined := start.Add(time.Duration(i) * time.Minute)
+		pc := &model.PlayerCompetition{PlayerID: q.id, Status: model.StatusWaiting, JoinedAt: joined, UpdatedAt: joined, Level: 1, CountryCode: "ZZ", Tier: q.tier}
+		if err := repo.CreatePlayerCompetition(ctx, pc); err != nil {
+			t.Fatalf("CreatePlayerCompetition failed: %v", err)
+		}
+	}
+
+	waiting, err := repo.GetWaitingPlayers(ctx, 2)
+	if err != nil {
+		t.Fatalf("GetWaitingPlayers failed: %v", err)
+	}
+	got := make(map[string]bool)
+	for _, pc := range waiting {
+		got[pc.PlayerID] = true
+	}
+	if !got["testwaittier1"] || !got["testwaittier2"] || got["testwaittier3"] || !got["testwaittier4"] {
+		t.Errorf("expected the first two SILVER players and the GOLD one, got %+v", waiting)
+	}
+}
+
 func TestUpdatePlayerCompetitionsToActive(t *testing.T) {
 	db := setupTestDB(t)
 	repo := NewRepository(db)
@@ -537,10 +574,20 @@ func TestCompleteFinishedCompetitions(t *testing.T) {
 	if err != nil {
 		t.Fatalf("CreatePlayerCompetition failed: %v", err)
 	}
-	err = repo.CompleteFinishedCompetitions(context.Background())
+	completed, err := repo.CompleteFinishedCompetitions(context.Background())
 	if err != nil {
 		t.Fatalf("CompleteFinishedCompetitions failed: %v", err)
 	}
+	found := false
+	for _, id := range completed {
+		if id == compID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		t.Errorf("CompleteFinishedCompetitions did not return competition %s", compID)
+	}
 	updatedComp, err := repo.GetCompetitionByID(context.Background(), compID.String())
 	if err != nil {
 		t.Fatalf("GetCompetitionByID failed: %v", err)
@@ -579,3 +626,1168 @@ func TestIsPlayerInWaitingQueue(t *testing.T) {
 		t.Errorf("IsPlayerInWaitingQueue returned false, want true")
 	}
 }
+
+func TestCancelWaitingPlayerCompetition(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewRepository(db)
+	playerID := "testplayer13"
+	pc := &model.PlayerCompetition{
+		PlayerID:      playerID,
+		CompetitionID: nil,
+		Status:        "WAITING",
+		Score:         0,
+		JoinedAt:      time.Now(),
+		UpdatedAt:     time.Now(),
+		Level:         1,
+		CountryCode:   "US",
+	}
+	_, _ = db.Exec("INSERT INTO players (player_id, level, country_code) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING", playerID, 1, "US")
+	defer cleanupPlayer(t, db, playerID)
+	defer cleanupPlayerCompetitionByPlayerID(t, db, playerID)
+	err := repo.CreatePlayerCompetition(context.Background(), pc)
+	if err != nil {
+		t.Fatalf("CreatePlayerCompetition failed: %v", err)
+	}
+	removed, err := repo.CancelWaitingPlayerCompetition(context.Background(), playerID)
+	if err != nil {
+		t.Fatalf("CancelWaitingPlayerCompetition failed: %v", err)
+	}
+	if !removed {
+		t.Errorf("CancelWaitingPlayerCompetition returned false, want true")
+	}
+	inQueue, err := repo.IsPlayerInWaitingQueue(context.Background(), playerID)
+	if err != nil {
+		t.Fatalf("IsPlayerInWaitingQueue failed: %v", err)
+	}
+	if inQueue {
+		t.Errorf("player still in waiting queue after cancel")
+	}
+}
+
+func TestCountWaitingPlayersByLevel(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewRepository(db)
+	playerID := "testplayer14"
+	level := 987
+	pc := &model.PlayerCompetition{
+		PlayerID:    playerID,
+		Status:      "WAITING",
+		JoinedAt:    time.Now(),
+		UpdatedAt:   time.Now(),
+		Level:       level,
+		CountryCode: "US",
+	}
+	_, _ = db.Exec("INSERT INTO players (player_id, level, country_code) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING", playerID, level, "US")
+	defer cleanupPlayer(t, db, playerID)
+	defer cleanupPlayerCompetitionByPlayerID(t, db, playerID)
+	if err := repo.CreatePlayerCompetition(context.Background(), pc); err != nil {
+		t.Fatalf("CreatePlayerCompetition failed: %v", err)
+	}
+	counts, err := repo.CountWaitingPlayersByLevel(context.Background())
+	if err != nil {
+		t.Fatalf("CountWaitingPlayersByLevel failed: %v", err)
+	}
+	if counts[level] != 1 {
+		t.Errorf("waiting players at level %d = %d, want 1", level, counts[level])
+	}
+}
+
+func TestAuditEntries(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewRepository(db)
+	ctx := context.Background()
+	target := "test-" + uuid.NewString()
+
+	for _, after := range []string{`{"v": 1}`, `{"v": 2}`} {
+		entry := &model.AuditEntry{Actor: "tester", Action: "test.update", Target: target, After: []byte(after)}
+		if err := repo.CreateAuditEntry(ctx, entry); err != nil {
+			t.Fatalf("CreateAuditEntry failed: %v", err)
+		}
+		if entry.ID == 0 || entry.OccurredAt.IsZero() {
+			t.Errorf("expected ID and time to be filled in, got %+v", entry)
+		}
+	}
+
+	entries, err := repo.ListAuditEntries(ctx, model.AuditFilter{Target: target, Limit: 1})
+	if err != nil {
+		t.Fatalf("ListAuditEntries failed: %v", err)
+	}
+	if len(entries) != 1 || string(entries[0].After) != `{"v": 2}` || entries[0].Before != nil {
+		t.Errorf("expected only the newest entry, got %+v", entries)
+	}
+
+	if _, err := db.Exec("UPDATE audit_log SET actor = 'someone' WHERE target = $1", target); err == nil {
+		t.Error("expected audit_log to reject updates")
+	}
+	// No session setting opens the log to a client; only redact_audit_log
+	// may rewrite it.
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("Begin failed: %v", err)
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec("SELECT set_config('audit_log.redact', 'on', true)"); err != nil {
+		t.Fatalf("set_config failed: %v", err)
+	}
+	if _, err := tx.Exec("UPDATE audit_log SET reason = 'rewritten' WHERE target = $1", target); err == nil {
+		t.Error("expected audit_log to reject updates from a client that sets audit_log.redact")
+	}
+}
+
+func TestCompetitionAdmin(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewRepository(db)
+	ctx := context.Background()
+	compID := uuid.New()
+	players := []string{"testadmin1", "testadmin2"}
+	_, _ = db.Exec("INSERT INTO competitions (competition_id, started_at, ends_at, level, country_code, status) VALUES ($1, $2, $3, $4, $5, $6)", compID, time.Now(), time.Now().Add(time.Hour), 7, "ZZ", "ACTIVE")
+	defer cleanupCompetition(t, db, compID.String())
+	defer cleanupPlayerCompetitionByCompetitionID(t, db, compID.String())
+	for i, playerID := range players {
+		_, _ = db.Exec("INSERT INTO players (player_id, level, country_code) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING", playerID, 7, "ZZ")
+		defer cleanupPlayer(t, db, playerID)
+		pc := &model.PlayerCompetition{PlayerID: playerID, CompetitionID: &compID, Status: model.StatusActive, Score: 100 * (i + 1),
+			JoinedAt: time.Now(), UpdatedAt: time.Now(), Level: 7, CountryCode: "ZZ"}
+		if err := repo.CreatePlayerCompetition(ctx, pc); err != nil {
+			t.Fatalf("CreatePlayerCompetition failed: %v", err)
+		}
+	}
+	level := 7
+	comps, err := repo.ListCompetitions(ctx, model.CompetitionFilter{Status: model.CompetitionActive, Level: &level, CountryCode: "ZZ"})
+	if err != nil {
+		t.Fatalf("ListCompetitions failed: %v", err)
+	}
+	if len(comps) != 1 || comps[0].CompetitionID != compID {
+		t.Errorf("expected only the test competition, got %+v", comps)
+	}
+
+	audit := &model.AuditEntry{Actor: "tester", Action: "test.disqualify", Target: "competition/" + compID.String()}
+	if ok, err := repo.ExcludeCompetitionPlayer(ctx, compID, players[1], model.StatusDisqualified, audit); err != nil || !ok {
+		t.Fatalf("ExcludeCompetitionPlayer = %v, %v", ok, err)
+	}
+	if audit.ID == 0 {
+		t.Error("expected the audit entry to be written")
+	}
+	entries, err := repo.GetLeaderboardByCompetitionID(ctx, compID.String(), model.Viewer{})
+	if err != nil {
+		t.Fatalf("GetLeaderboardByCompetitionID failed: %v", err)
+	}
+	if len(entries) != 1 || entries[0].PlayerID != players[0] {
+		t.Errorf("expected the disqualified player to be left out, got %+v", entries)
+	}
+
+	if ok, err := repo.CancelCompetition(ctx, compID, &model.AuditEntry{Actor: "tester", Action: "test.cancel", Target: "competition/" + compID.String()}); err != nil || !ok {
+		t.Fatalf("CancelCompetition = %v, %v", ok, err)
+	}
+	pc, err := repo.GetCompetitionPlayer(ctx, compID, players[0])
+	if err != nil || pc.Status != model.StatusCancelled {
+		t.Errorf("expected the player entry to be cancelled, got %+v, %v", pc, err)
+	}
+	audit = &model.AuditEntry{Actor: "tester", Action: "test.complete", Target: "competition/" + compID.String()}
+	if ok, err := repo.CompleteCompetition(ctx, compID, audit); err != nil || ok {
+		t.Errorf("expected completing a cancelled competition to be refused, got %v, %v", ok, err)
+	}
+	if audit.ID != 0 {
+		t.Error("expected no audit entry for a refused change")
+	}
+}
+
+func TestSetCompetitionScore(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewRepository(db)
+	ctx := context.Background()
+	compID := uuid.New()
+	playerID := "testadjust1"
+	_, _ = db.Exec("INSERT INTO players (player_id, level, country_code) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING", playerID, 1, "US")
+	_, _ = db.Exec("INSERT INTO competitions (competition_id, started_at, ends_at, level, country_code, status) VALUES ($1, $2, $3, $4, $5, $6)", compID, time.Now().Add(-time.Hour), time.Now().Add(-time.Minute), 1, "US", "COMPLETED")
+	defer cleanupPlayer(t, db, playerID)
+	defer cleanupCompetition(t, db, compID.String())
+	defer cleanupPlayerCompetitionByCompetitionID(t, db, compID.String())
+	pc := &model.PlayerCompetition{PlayerID: playerID, CompetitionID: &compID, Status: model.StatusCompleted, Score: 40,
+		JoinedAt: time.Now(), UpdatedAt: time.Now(), Level: 1, CountryCode: "US"}
+	if err := repo.CreatePlayerCompetition(ctx, pc); err != nil {
+		t.Fatalf("CreatePlayerCompetition failed: %v", err)
+	}
+
+	audit := func() *model.AuditEntry {
+		return &model.AuditEntry{Actor: "tester", Action: "test.adjust_score", Target: "competition/" + compID.String()}
+	}
+	if ok, err := repo.SetCompetitionScore(ctx, compID, playerID, 39, 60, audit()); err != nil || ok {
+		t.Errorf("expected a stale score to be refused, got %v, %v", ok, err)
+	}
+	before, err := repo.GetCompetitionPlayer(ctx, compID, playerID)
+	if err != nil {
+		t.Fatalf("GetCompetitionPlayer failed: %v", err)
+	}
+	if ok, err := repo.SetCompetitionScore(ctx, compID, playerID, 40, 60, audit()); err != nil || !ok {
+		t.Fatalf("SetCompetitionScore = %v, %v", ok, err)
+	}
+	got, err := repo.GetCompetitionPlayer(ctx, compID, playerID)
+	if err != nil || got.Score != 60 || !got.UpdatedAt.Equal(before.UpdatedAt) {
+		t.Errorf("expected score 60 with updated_at unchanged, got %+v, %v", got, err)
+	}
+}
+
+func TestScheduledCompetitionLifecycle(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewRepository(db)
+	ctx := context.Background()
+	opensAt := time.Now().Add(-time.Minute)
+	comp := &model.Competition{CompetitionID: uuid.New(), Name: "Test Cup", Kind: model.CompetitionKindScheduled,
+		StartedAt: time.Now().Add(time.Hour), EndsAt: time.Now().Add(2 * time.Hour), Status: model.CompetitionScheduled,
+		RegistrationOpensAt: &opensAt, MaxPlayers: 1}
+	audit := &model.AuditEntry{Actor: "tester", Action: "test.schedule", Target: "competition/" + comp.CompetitionID.String()}
+	if err := repo.CreateScheduledCompetition(ctx, comp, audit); err != nil {
+		t.Fatalf("CreateScheduledCompetition failed: %v", err)
+	}
+	defer cleanupCompetition(t, db, comp.CompetitionID.String())
+	defer cleanupPlayerCompetitionByCompetitionID(t, db, comp.CompetitionID.String())
+	players := []*model.Player{{PlayerID: "testsched1", Level: 4, CountryCode: "ZZ"}, {PlayerID: "testsched2", Level: 4, CountryCode: "ZZ"}}
+	for _, p := range players {
+		_, _ = db.Exec("INSERT INTO players (player_id, level, country_code) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING", p.PlayerID, p.Level, p.CountryCode)
+		defer cleanupPlayer(t, db, p.PlayerID)
+	}
+
+	if ok, err := repo.RegisterCompetitionPlayer(ctx, comp.CompetitionID, players[0]); err != nil || ok {
+		t.Errorf("expected registration to be refused before it opens, got %v, %v", ok, err)
+	}
+	opened, err := repo.OpenScheduledCompetitions(ctx)
+	if err != nil {
+		t.Fatalf("OpenScheduledCompetitions failed: %v", err)
+	}
+	found := false
+	for _, id := range opened {
+		found = found || id == comp.CompetitionID
+	}
+	if !found {
+		t.Fatalf("expected the test competition to open, got %v", opened)
+	}
+	if ok, err := repo.RegisterCompetitionPlayer(ctx, comp.CompetitionID, players[0]); err != nil || !ok {
+		t.Fatalf("RegisterCompetitionPlayer = %v, %v", ok, err)
+	}
+	if ok, err := repo.RegisterCompetitionPlayer(ctx, comp.CompetitionID, players[1]); err != nil || ok {
+		t.Errorf("expected a full competition to refuse registration, got %v, %v", ok, err)
+	}
+
+	// Matchmaking must not pick up a scheduled competition.
+	_, _ = db.Exec("UPDATE competitions SET started_at = NOW() - INTERVAL '1 second' WHERE competition_id = $1", comp.CompetitionID)
+	started, err := repo.StartScheduledCompetitions(ctx)
+	if err != nil {
+		t.Fatalf("StartScheduledCompetitions failed: %v", err)
+	}
+	found = false
+	for _, c := range started {
+		found = found || (c.CompetitionID == comp.CompetitionID && c.Status == model.CompetitionActive && c.Name == "Test Cup")
+	}
+	if !found {
+		t.Fatalf("expected the test competition to start, got %+v", started)
+	}
+	if active, err := repo.GetActiveCompetition(ctx); err == nil && active.CompetitionID == comp.CompetitionID {
+		t.Error("expected GetActiveCompetition to skip scheduled competitions")
+	}
+	pc, err := repo.GetCompetitionPlayer(ctx, comp.CompetitionID, players[0].PlayerID)
+	if err != nil || pc.Status != model.StatusActive {
+		t.Errorf("expected the registration to become an active entry, got %+v, %v", pc, err)
+	}
+}
+
+func TestRecurringCompetitionExcludedPlayerStaysOut(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewRepository(db)
+	ctx := context.Background()
+	now := time.Now()
+	comp := &model.Competition{CompetitionID: uuid.New(), Name: "Test Daily", Kind: model.CompetitionKindRecurring,
+		StartedAt: now, EndsAt: now.Add(time.Hour), Status: model.CompetitionActive}
+	if err := repo.CreateCompetition(ctx, comp); err != nil {
+		t.Fatalf("CreateCompetition failed: %v", err)
+	}
+	defer cleanupCompetition(t, db, comp.CompetitionID.String())
+	defer cleanupPlayerCompetitionByCompetitionID(t, db, comp.CompetitionID.String())
+	player := &model.Player{PlayerID: "testexcluded1", Level: 4, CountryCode: "ZZ"}
+	_, _ = db.Exec("INSERT INTO players (player_id, level, country_code) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING", player.PlayerID, player.Level, player.CountryCode)
+	defer cleanupPlayer(t, db, player.PlayerID)
+	defer cleanupPlayerCompetitionByPlayerID(t, db, player.PlayerID)
+
+	if ok, err := repo.RegisterCompetitionPlayer(ctx, comp.CompetitionID, player); err != nil || !ok {
+		t.Fatalf("RegisterCompetitionPlayer = %v, %v", ok, err)
+	}
+	audit := &model.AuditEntry{Actor: "tester", Action: "test.disqualify", Target: "competition/" + comp.CompetitionID.String()}
+	if ok, err := repo.ExcludeCompetitionPlayer(ctx, comp.CompetitionID, player.PlayerID, model.StatusDisqualified, audit); err != nil || !ok {
+		t.Fatalf("ExcludeCompetitionPlayer = %v, %v", ok, err)
+	}
+	if ok, err := repo.RegisterCompetitionPlayer(ctx, comp.CompetitionID, player); err != nil || ok {
+		t.Errorf("expected a disqualified player to be refused, got %v, %v", ok, err)
+	}
+	var rows int
+	if err := db.QueryRow("SELECT COUNT(1) FROM player_competitions WHERE competition_id = $1 AND player_id = $2",
+		comp.CompetitionID, player.PlayerID).Scan(&rows); err != nil || rows != 1 {
+		t.Errorf("expected only the disqualified entry, got %d rows, %v", rows, err)
+	}
+}
+
+func TestPlayerEntersOneRunningCompetition(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewRepository(db)
+	ctx := context.Background()
+	now := time.Now()
+	player := &model.Player{PlayerID: "testbusy1", Level: 4, CountryCode: "ZZ"}
+	_, _ = db.Exec("INSERT INTO players (player_id, level, country_code) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING", player.PlayerID, player.Level, player.CountryCode)
+	defer cleanupPlayer(t, db, player.PlayerID)
+	defer cleanupPlayerCompetitionByPlayerID(t, db, player.PlayerID)
+
+	matched := &model.Competition{CompetitionID: uuid.New(), StartedAt: now, EndsAt: now.Add(time.Hour), Status: model.CompetitionActive}
+	recurring := &model.Competition{CompetitionID: uuid.New(), Name: "Test Daily", Kind: model.CompetitionKindRecurring,
+		StartedAt: now, EndsAt: now.Add(time.Hour), Status: model.CompetitionActive}
+	opensAt := now.Add(-time.Minute)
+	scheduled := &model.Competition{CompetitionID: uuid.New(), Name: "Test Cup", Kind: model.CompetitionKindScheduled,
+		StartedAt: now.Add(time.Hour), EndsAt: now.Add(2 * time.Hour), Status: model.CompetitionOpen, RegistrationOpensAt: &opensAt}
+	for _, comp := range []*model.Competition{matched, recurring} {
+		if err := repo.CreateCompetition(ctx, comp); err != nil {
+			t.Fatalf("CreateCompetition failed: %v", err)
+		}
+		defer cleanupCompetition(t, db, comp.CompetitionID.String())
+		defer cleanupPlayerCompetitionByCompetitionID(t, db, comp.CompetitionID.String())
+	}
+	audit := &model.AuditEntry{Actor: "tester", Action: "test.schedule", Target: "competition/" + scheduled.CompetitionID.String()}
+	if err := repo.CreateScheduledCompetition(ctx, scheduled, audit); err != nil {
+		t.Fatalf("CreateScheduledCompetition failed: %v", err)
+	}
+	defer cleanupCompetition(t, db, scheduled.CompetitionID.String())
+	defer cleanupPlayerCompetitionByCompetitionID(t, db, scheduled.CompetitionID.String())
+
+	// A registration for later is fine while playing.
+	if ok, err := repo.RegisterCompetitionPlayer(ctx, scheduled.CompetitionID, player); err != nil || !ok {
+		t.Fatalf("RegisterCompetitionPlayer = %v, %v", ok, err)
+	}
+	pc := &model.PlayerCompetition{PlayerID: player.PlayerID, CompetitionID: &matched.CompetitionID, Status: model.StatusActive, JoinedAt: now, UpdatedAt: now}
+	if err := repo.CreatePlayerCompetition(ctx, pc); err != nil {
+		t.Fatalf("CreatePlayerCompetition failed: %v", err)
+	}
+	if ok, err := repo.RegisterCompetitionPlayer(ctx, recurring.CompetitionID, player); err != nil || ok {
+		t.Errorf("expected entering a second running competition to be refused, got %v, %v", ok, err)
+	}
+
+	_, _ = db.Exec("UPDATE competitions SET started_at = NOW() - INTERVAL '1 second' WHERE competition_id = $1", scheduled.CompetitionID)
+	if _, err := repo.StartScheduledCompetitions(ctx); err != nil {
+		t.Fatalf("StartScheduledCompetitions failed: %v", err)
+	}
+	if got, err := repo.GetCompetitionPlayer(ctx, scheduled.CompetitionID, player.PlayerID); err != nil || got.Status != model.StatusCancelled {
+		t.Errorf("expected the registration of a playing player to be cancelled, got %+v, %v", got, err)
+	}
+	if active, err := repo.GetActivePlayerCompetition(ctx, player.PlayerID); err != nil || *active.CompetitionID != matched.CompetitionID {
+		t.Errorf("expected the player to keep playing the matched competition, got %+v, %v", active, err)
+	}
+}
+
+func TestScheduledCompetitionReregistration(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewRepository(db)
+	ctx := context.Background()
+	opensAt := time.Now().Add(-time.Minute)
+	comp := &model.Competition{CompetitionID: uuid.New(), Name: "Rematch Cup", Kind: model.CompetitionKindScheduled,
+		StartedAt: time.Now().Add(time.Hour), EndsAt: time.Now().Add(2 * time.Hour), Status: model.CompetitionOpen,
+		RegistrationOpensAt: &opensAt}
+	audit := &model.AuditEntry{Actor: "tester", Action: "test.schedule", Target: "competition/" + comp.CompetitionID.String()}
+	if err := repo.CreateScheduledCompetition(ctx, comp, audit); err != nil {
+		t.Fatalf("CreateScheduledCompetition failed: %v", err)
+	}
+	defer cleanupCompetition(t, db, comp.CompetitionID.String())
+	defer cleanupPlayerCompetitionByCompetitionID(t, db, comp.CompetitionID.String())
+	players := []*model.Player{{PlayerID: "testrereg1", Level: 4, CountryCode: "ZZ"}, {PlayerID: "testrereg2", Level: 4, CountryCode: "ZZ"}}
+	for _, p := range players {
+		_, _ = db.Exec("INSERT INTO players (player_id, level, country_code) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING", p.PlayerID, p.Level, p.CountryCode)
+		defer cleanupPlayer(t, db, p.PlayerID)
+		defer cleanupPlayerCompetitionByPlayerID(t, db, p.PlayerID)
+		if ok, err := repo.RegisterCompetitionPlayer(ctx, comp.CompetitionID, p); err != nil || !ok {
+			t.Fatalf("RegisterCompetitionPlayer(%s) = %v, %v", p.PlayerID, ok, err)
+		}
+	}
+
+	// The first player withdraws and comes back; the second withdraws for good.
+	for _, p := range players {
+		if ok, err := repo.CancelCompetitionRegistration(ctx, comp.CompetitionID, p.PlayerID); err != nil || !ok {
+			t.Fatalf("CancelCompetitionRegistration(%s) = %v, %v", p.PlayerID, ok, err)
+		}
+	}
+	if ok, err := repo.RegisterCompetitionPlayer(ctx, comp.CompetitionID, players[0]); err != nil || !ok {
+		t.Fatalf("re-registering = %v, %v", ok, err)
+	}
+	var rows int
+	if err := db.QueryRow("SELECT COUNT(1) FROM player_competitions WHERE competition_id = $1 AND player_id = $2",
+		comp.CompetitionID, players[0].PlayerID).Scan(&rows); err != nil || rows != 1 {
+		t.Errorf("expected re-registering to reuse the player's row, got %d rows, %v", rows, err)
+	}
+	if entries, err := repo.GetLeaderboardByCompetitionID(ctx, comp.CompetitionID.String(), model.Viewer{All: true}); err != nil || len(entries) != 0 {
+		t.Errorf("expected no leaderboard before the start, got %+v, %v", entries, err)
+	}
+
+	_, _ = db.Exec("UPDATE competitions SET started_at = NOW() - INTERVAL '1 second' WHERE competition_id = $1", comp.CompetitionID)
+	if _, err := repo.StartScheduledCompetitions(ctx); err != nil {
+		t.Fatalf("StartScheduledCompetitions failed: %v", err)
+	}
+	entries, err := repo.GetLeaderboardByCompetitionID(ctx, comp.CompetitionID.String(), model.Viewer{All: true})
+	if err != nil {
+		t.Fatalf("GetLeaderboardByCompetitionID failed: %v", err)
+	}
+	if len(entries) != 1 || entries[0].PlayerID != players[0].PlayerID || entries[0].Status != model.StatusActive {
+		t.Errorf("expected only the re-registered player on the leaderboard, got %+v", entries)
+	}
+}
+
+func TestCompetitionTemplates(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewRepository(db)
+	ctx := context.Background()
+	due := time.Now().Add(-time.Minute).UTC().Truncate(time.Second)
+	tpl := &model.CompetitionTemplate{TemplateID: uuid.New(), Name: "Test Daily", Schedule: "@daily", Duration: time.Hour,
+		ScoringMode: model.ScoringBest, Eligibility: model.Eligibility{MinLevel: 3, Countries: []string{"ZZ"}},
+		Rewards: []model.Reward{{FromRank: 1, ToRank: 1, Reward: "gold"}}, Enabled: true, NextRunAt: &due}
+	audit := &model.AuditEntry{Actor: "tester", Action: "test.template", Target: "template/" + tpl.TemplateID.String()}
+	if err := repo.CreateCompetitionTemplate(ctx, tpl, audit); err != nil {
+		t.Fatalf("CreateCompetitionTemplate failed: %v", err)
+	}
+	defer db.Exec("DELETE FROM competition_templates WHERE template_id = $1", tpl.TemplateID)
+
+	got, err := repo.GetCompetitionTemplate(ctx, tpl.TemplateID)
+	if err != nil || got.Duration != time.Hour || got.Eligibility.Countries[0] != "ZZ" || got.Rewards[0].Reward != "gold" {
+		t.Fatalf("unexpected template: %+v, %v", got, err)
+	}
+	dueTemplates, err := repo.DueCompetitionTemplates(ctx)
+	if err != nil {
+		t.Fatalf("DueCompetitionTemplates failed: %v", err)
+	}
+	found := false
+	for _, d := range dueTemplates {
+		found = found || d.TemplateID == tpl.TemplateID
+	}
+	if !found {
+		t.Fatal("expected the test template to be due")
+	}
+
+	next := due.Add(24 * time.Hour)
+	comp := &model.Competition{CompetitionID: uuid.New(), Name: tpl.Name, Kind: model.CompetitionKindRecurring, StartedAt: due,
+		EndsAt: due.Add(time.Hour), Status: model.CompetitionActive, TemplateID: &tpl.TemplateID, ScoringMode: model.ScoringBest,
+		Eligibility: tpl.Eligibility, Rewards: tpl.Rewards}
+	defer cleanupCompetition(t, db, comp.CompetitionID.String())
+	defer cleanupPlayerCompetitionByCompetitionID(t, db, comp.CompetitionID.String())
+	if _, ok, err := repo.StartTemplateOccurrence(ctx, tpl.TemplateID, *got.NextRunAt, &next, comp); err != nil || !ok {
+		t.Fatalf("StartTemplateOccurrence = %v, %v", ok, err)
+	}
+	if _, ok, err := repo.StartTemplateOccurrence(ctx, tpl.TemplateID, *got.NextRunAt, &next, nil); err != nil || ok {
+		t.Errorf("expected a second start of the same occurrence to be refused, got %v, %v", ok, err)
+	}
+
+	player := &model.Player{PlayerID: "testrecurring1", Level: 3, CountryCode: "ZZ"}
+	_, _ = db.Exec("INSERT INTO players (player_id, level, country_code) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING", player.PlayerID, player.Level, player.CountryCode)
+	defer cleanupPlayer(t, db, player.PlayerID)
+	if ok, err := repo.RegisterCompetitionPlayer(ctx, comp.CompetitionID, player); err != nil || !ok {
+		t.Fatalf("RegisterCompetitionPlayer = %v, %v", ok, err)
+	}
+	for _, score := range []int{40, 25} {
+		if err := repo.AddScoreToPlayer(ctx, player.PlayerID, score); err != nil {
+			t.Fatalf("AddScoreToPlayer failed: %v", err)
+		}
+	}
+	pc, err := repo.GetCompetitionPlayer(ctx, comp.CompetitionID, player.PlayerID)
+	if err != nil || pc.Status != model.StatusActive || pc.Score != 40 {
+		t.Errorf("expected an active entry keeping the best score 40, got %+v, %v", pc, err)
+	}
+
+	stored, err := repo.GetCompetitionByID(ctx, comp.CompetitionID.String())
+	if err != nil || stored.TemplateID == nil || *stored.TemplateID != tpl.TemplateID || stored.Rewards[0].Reward != "gold" {
+		t.Errorf("unexpected competition: %+v, %v", stored, err)
+	}
+	if ok, err := repo.DeleteCompetitionTemplate(ctx, tpl.TemplateID, &model.AuditEntry{Actor: "tester", Action: "test.delete", Target: "template/x"}); err != nil || !ok {
+		t.Fatalf("DeleteCompetitionTemplate = %v, %v", ok, err)
+	}
+	if stored, err := repo.GetCompetitionByID(ctx, comp.CompetitionID.String()); err != nil || stored.TemplateID != nil {
+		t.Errorf("expected the competition to outlive its template, got %+v, %v", stored, err)
+	}
+}
+
+func TestTierMovements(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewRepository(db)
+	ctx := context.Background()
+	player := &model.Player{PlayerID: "testtier1", Level: 1, CountryCode: "ZZ", Tier: model.TierSilver}
+	if err := repo.CreatePlayer(ctx, player); err != nil {
+		t.Fatalf("CreatePlayer failed: %v", err)
+	}
+	defer cleanupPlayer(t, db, player.PlayerID)
+	comp := &model.Competition{CompetitionID: uuid.New(), StartedAt: time.Now(), EndsAt: time.Now(), Status: model.CompetitionCompleted, Tier: model.TierSilver}
+	if err := repo.CreateCompetition(ctx, comp); err != nil {
+		t.Fatalf("CreateCompetition failed: %v", err)
+	}
+	defer cleanupCompetition(t, db, comp.CompetitionID.String())
+	defer db.Exec("DELETE FROM player_tier_history WHERE player_id = $1", player.PlayerID)
+
+	if stored, err := repo.GetCompetitionByID(ctx, comp.CompetitionID.String()); err != nil || stored.Tier != model.TierSilver {
+		t.Fatalf("expected a SILVER competition, got %+v, %v", stored, err)
+	}
+	unsettled := func() bool {
+		comps, err := repo.UnsettledLeagueCompetitions(ctx)
+		if err != nil {
+			t.Fatalf("UnsettledLeagueCompetitions failed: %v", err)
+		}
+		for _, c := range comps {
+			if c.CompetitionID == comp.CompetitionID {
+				return true
+			}
+		}
+		return false
+	}
+	if !unsettled() {
+		t.Error("expected the completed competition to await settlement")
+	}
+	moves := []model.TierMovement{
+		{PlayerID: player.PlayerID, FromTier: model.TierSilver, ToTier: model.TierGold, Rank: 1},
+		// Not in BRONZE, so skipped.
+		{PlayerID: player.PlayerID, FromTier: model.TierBronze, ToTier: model.TierSilver, Rank: 1},
+	}
+	applied, err := repo.ApplyTierMovements(ctx, comp.CompetitionID, moves)
+	if err != nil || len(applied) != 1 || applied[0].OccurredAt.IsZero() {
+		t.Fatalf("ApplyTierMovements = %+v, %v", applied, err)
+	}
+	if got, err := repo.GetPlayerByID(ctx, player.PlayerID); err != nil || got.Tier != model.TierGold {
+		t.Errorf("expected the player to be promoted to GOLD, got %+v, %v", got, err)
+	}
+	if unsettled() {
+		t.Error("expected the competition to be settled")
+	}
+	// Applying the same competition again moves nobody.
+	if applied, err := repo.ApplyTierMovements(ctx, comp.CompetitionID, []model.TierMovement{
+		{PlayerID: player.PlayerID, FromTier: model.TierGold, ToTier: model.TierSilver, Rank: 1},
+	}); err != nil || len(applied) != 0 {
+		t.Errorf("expected a repeated settlement to be skipped, got %+v, %v", applied, err)
+	}
+
+	history, err := repo.ListTierHistory(ctx, player.PlayerID, 10)
+	if err != nil || len(history) != 1 || history[0].ToTier != model.TierGold || history[0].CompetitionID != comp.CompetitionID {
+		t.Errorf("unexpected history: %+v, %v", history, err)
+	}
+}
+
+func TestTournaments(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewRepository(db)
+	ctx := context.Background()
+	players := []string{"testtournament1", "testtournament2"}
+	for _, id := range players {
+		if err := repo.CreatePlayer(ctx, &model.Player{PlayerID: id, Level: 1, CountryCode: "ZZ", Tier: model.TierBronze}); err != nil {
+			t.Fatalf("CreatePlayer failed: %v", err)
+		}
+		defer cleanupPlayer(t, db, id)
+		defer cleanupPlayerCompetitionByPlayerID(t, db, id)
+	}
+	waiting := &model.PlayerCompetition{PlayerID: players[0], Status: model.StatusWaiting, JoinedAt: time.Now(), UpdatedAt: time.Now()}
+	if err := repo.CreatePlayerCompetition(ctx, waiting); err != nil {
+		t.Fatalf("CreatePlayerCompetition failed: %v", err)
+	}
+
+	tournament := &model.Tournament{TournamentID: uuid.New(), Name: "Test Cup", Format: model.TournamentSingleElimination,
+		Seeding: model.SeedManual, Seeds: players, GroupSize: 2, Advance: 1, RoundDuration: time.Hour, Round: 1, Rounds: 1,
+		Status: model.TournamentActive}
+	comp := model.Competition{CompetitionID: uuid.New(), Name: "Test Cup: round 1, match 1", Kind: model.CompetitionKindTournament,
+		StartedAt: time.Now(), EndsAt: time.Now().Add(time.Hour), Status: model.CompetitionActive, ScoringMode: model.ScoringSum}
+	match := model.TournamentMatch{TournamentID: tournament.TournamentID, Round: 1, CompetitionID: comp.CompetitionID, Players: players}
+	audit := &model.AuditEntry{Actor: "tester", Action: "test.tournament", Target: "tournament/" + tournament.TournamentID.String()}
+	if err := repo.CreateTournament(ctx, tournament, []model.TournamentMatch{match}, []model.Competition{comp}, audit); err != nil {
+		t.Fatalf("CreateTournament failed: %v", err)
+	}
+	defer cleanupCompetition(t, db, comp.CompetitionID.String())
+	defer cleanupPlayerCompetitionByCompetitionID(t, db, comp.CompetitionID.String())
+	defer db.Exec("DELETE FROM tournaments WHERE tournament_id = $1", tournament.TournamentID)
+
+	if queued, err := repo.IsPlayerInWaitingQueue(ctx, players[0]); err != nil || queued {
+		t.Errorf("expected the player to leave the queue, got %v, %v", queued, err)
+	}
+	pc, err := repo.GetCompetitionPlayer(ctx, comp.CompetitionID, players[1])
+	if err != nil || pc.Status != model.StatusActive || pc.Tier != model.TierBronze {
+		t.Errorf("expected an active entry, got %+v, %v", pc, err)
+	}
+	got, err := repo.GetTournament(ctx, tournament.TournamentID)
+	if err != nil || got.RoundDuration != time.Hour || len(got.Seeds) != 2 || got.CreatedAt.IsZero() {
+		t.Fatalf("unexpected tournament: %+v, %v", got, err)
+	}
+	matches, err := repo.ListTournamentMatches(ctx, tournament.TournamentID)
+	if err != nil || len(matches) != 1 || matches[0].Status != model.CompetitionActive || len(matches[0].Players) != 2 {
+		t.Errorf("unexpected matches: %+v, %v", matches, err)
+	}
+
+	if ok, err := repo.StartTournamentRound(ctx, tournament.TournamentID, 3, nil, nil); err != nil || ok {
+		t.Errorf("expected round 3 not to follow round 1, got %v, %v", ok, err)
+	}
+	if ok, err := repo.CompleteTournament(ctx, tournament.TournamentID, 1, players[:1]); err != nil || !ok {
+		t.Fatalf("CompleteTournament = %v, %v", ok, err)
+	}
+	if got, err := repo.GetTournament(ctx, tournament.TournamentID); err != nil || got.Status != model.TournamentCompleted ||
+		got.CompletedAt == nil || got.Winners[0] != players[0] {
+		t.Errorf("unexpected completed tournament: %+v, %v", got, err)
+	}
+}
+
+func TestParties(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewRepository(db)
+	ctx := context.Background()
+	players := []string{"testparty1", "testparty2", "testparty3"}
+	for _, id := range players {
+		if err := repo.CreatePlayer(ctx, &model.Player{PlayerID: id, Level: 1, CountryCode: "ZZ", Tier: model.TierBronze}); err != nil {
+			t.Fatalf("CreatePlayer failed: %v", err)
+		}
+		defer cleanupPlayer(t, db, id)
+		defer cleanupPlayerCompetitionByPlayerID(t, db, id)
+	}
+
+	party := &model.Party{PartyID: uuid.New(), LeaderID: players[0]}
+	if ok, err := repo.CreateParty(ctx, party); err != nil || !ok {
+		t.Fatalf("CreateParty failed: %v, %v", ok, err)
+	}
+	defer db.Exec("DELETE FROM parties WHERE party_id = $1", party.PartyID)
+	if ok, err := repo.CreateParty(ctx, &model.Party{PartyID: uuid.New(), LeaderID: players[0]}); err != nil || ok {
+		t.Errorf("expected a second party for the leader to be refused, got %v, %v", ok, err)
+	}
+
+	if ok, err := repo.InvitePartyPlayer(ctx, party.PartyID, players[1]); err != nil || !ok {
+		t.Fatalf("InvitePartyPlayer failed: %v, %v", ok, err)
+	}
+	if ok, err := repo.InvitePartyPlayer(ctx, party.PartyID, players[1]); err != nil || ok {
+		t.Errorf("expected a repeated invite to be refused, got %v, %v", ok, err)
+	}
+	if ok, err := repo.AcceptPartyInvite(ctx, party.PartyID, players[2], 5); err != nil || ok {
+		t.Errorf("expected an uninvited player to be refused, got %v, %v", ok, err)
+	}
+	if ok, err := repo.AcceptPartyInvite(ctx, party.PartyID, players[1], 5); err != nil || !ok {
+		t.Fatalf("AcceptPartyInvite failed: %v, %v", ok, err)
+	}
+	got, err := repo.GetPlayerParty(ctx, players[1])
+	if err != nil || got.PartyID != party.PartyID || len(got.Members) != 2 || len(got.Invites) != 0 || got.Queued {
+		t.Fatalf("unexpected party: %+v, %v", got, err)
+	}
+
+	now := time.Now()
+	var entries []model.PlayerCompetition
+	for _, id := range got.Members {
+		entries = append(entries, model.PlayerCompetition{PlayerID: id, JoinedAt: now, Level: 1, CountryCode: "ZZ", Tier: model.TierBronze})
+	}
+	if ok, err := repo.QueueParty(ctx, party.PartyID, entries[:1]); err != nil || ok {
+		t.Errorf("expected a partial queue to be refused, got %v, %v", ok, err)
+	}
+	if ok, err := repo.QueueParty(ctx, party.PartyID, entries); err != nil || !ok {
+		t.Fatalf("QueueParty failed: %v, %v", ok, err)
+	}
+	solo := &model.PlayerCompetition{PlayerID: players[2], Status: model.StatusWaiting, JoinedAt: now.Add(-time.Minute), UpdatedAt: now}
+	if err := repo.CreatePlayerCompetition(ctx, solo); err != nil {
+		t.Fatalf("CreatePlayerCompetition failed: %v", err)
+	}
+
+	// A limit cutting through the party still returns all of it.
+	waiting, err := repo.GetWaitingPlayers(ctx, 2)
+	if err != nil {
+		t.Fatalf("GetWaitingPlayers failed: %v", err)
+	}
+	var members int
+	for _, pc := range waiting {
+		if pc.PartyID != nil && *pc.PartyID == party.PartyID {
+			members++
+		}
+	}
+	if members != 0 && members != 2 {
+		t.Errorf("expected the party to be returned whole, got %+v", waiting)
+	}
+
+	if ok, err := repo.CancelWaitingPlayerCompetition(ctx, players[1]); err != nil || !ok {
+		t.Fatalf("CancelWaitingPlayerCompetition failed: %v, %v", ok, err)
+	}
+	if queued, err := repo.IsPlayerInWaitingQueue(ctx, players[0]); err != nil || queued {
+		t.Errorf("expected the leader to leave the queue with the party, got %v, %v", queued, err)
+	}
+
+	// Entering a running recurring competition takes the whole party out of
+	// the queue, not just the player who entered.
+	if ok, err := repo.QueueParty(ctx, party.PartyID, entries); err != nil || !ok {
+		t.Fatalf("QueueParty failed: %v, %v", ok, err)
+	}
+	recurring := &model.Competition{CompetitionID: uuid.New(), Name: "Test Daily", Kind: model.CompetitionKindRecurring,
+		StartedAt: now, EndsAt: now.Add(time.Hour), Status: model.CompetitionActive}
+	if err := repo.CreateCompetition(ctx, recurring); err != nil {
+		t.Fatalf("CreateCompetition failed: %v", err)
+	}
+	defer cleanupCompetition(t, db, recurring.CompetitionID.String())
+	defer cleanupPlayerCompetitionByCompetitionID(t, db, recurring.CompetitionID.String())
+	if ok, err := repo.RegisterCompetitionPlayer(ctx, recurring.CompetitionID, &model.Player{PlayerID: players[0], Level: 1, CountryCode: "ZZ"}); err != nil || !ok {
+		t.Fatalf("RegisterCompetitionPlayer = %v, %v", ok, err)
+	}
+	if queued, err := repo.IsPlayerInWaitingQueue(ctx, players[1]); err != nil || queued {
+		t.Errorf("expected the party to leave the queue with the player, got %v, %v", queued, err)
+	}
+
+	if ok, err := repo.LeaveParty(ctx, party.PartyID, players[0]); err != nil || !ok {
+		t.Fatalf("LeaveParty failed: %v, %v", ok, err)
+	}
+	if got, err := repo.GetParty(ctx, party.PartyID); err != nil || got.LeaderID != players[1] || len(got.Members) != 1 {
+		t.Errorf("expected leadership to pass on, got %+v, %v", got, err)
+	}
+	if ok, err := repo.LeaveParty(ctx, party.PartyID, players[1]); err != nil || !ok {
+		t.Fatalf("LeaveParty failed: %v, %v", ok, err)
+	}
+	if _, err := repo.GetParty(ctx, party.PartyID); err != sql.ErrNoRows {
+		t.Errorf("expected the empty party to be disbanded, got %v", err)
+	}
+}
+
+func TestFriends(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewRepository(db)
+	ctx := context.Background()
+	players := []string{"testfriend1", "testfriend2", "testfriend3"}
+	for _, id := range players {
+		if err := repo.CreatePlayer(ctx, &model.Player{PlayerID: id, Level: 1, CountryCode: "ZZ", Tier: model.TierBronze}); err != nil {
+			t.Fatalf("CreatePlayer failed: %v", err)
+		}
+		defer cleanupPlayer(t, db, id)
+		defer cleanupPlayerCompetitionByPlayerID(t, db, id)
+	}
+	defer db.Exec("DELETE FROM player_friends WHERE player_id LIKE 'testfriend%'")
+
+	added, err := repo.AddFriends(ctx, players[0], []string{players[1], players[2], players[0], "testfriendghost"})
+	if err != nil || len(added) != 2 {
+		t.Fatalf("expected 2 friends added, got %v, %v", added, err)
+	}
+	if added, err := repo.AddFriends(ctx, players[0], players[1:2]); err != nil || len(added) != 0 {
+		t.Errorf("expected a followed player to be skipped, got %v, %v", added, err)
+	}
+	if friends, err := repo.ListFriends(ctx, players[1]); err != nil || len(friends) != 0 {
+		t.Errorf("expected friendship to be one-way, got %v, %v", friends, err)
+	}
+	if ok, err := repo.RemoveFriend(ctx, players[0], players[2]); err != nil || !ok {
+		t.Errorf("RemoveFriend failed: %v, %v", ok, err)
+	}
+	if friends, err := repo.ListFriends(ctx, players[0]); err != nil || len(friends) != 1 || friends[0] != players[1] {
+		t.Errorf("unexpected friends: %v, %v", friends, err)
+	}
+
+	comp := &model.Competition{CompetitionID: uuid.New(), StartedAt: time.Now().Add(-2 * time.Hour), EndsAt: time.Now().Add(-time.Hour), Status: model.CompetitionCompleted}
+	if err := repo.CreateCompetition(ctx, comp); err != nil {
+		t.Fatalf("CreateCompetition failed: %v", err)
+	}
+	defer cleanupCompetition(t, db, comp.CompetitionID.String())
+	defer cleanupPlayerCompetitionByCompetitionID(t, db, comp.CompetitionID.String())
+	for i, score := range []int{30, 50} {
+		pc := &model.PlayerCompetition{PlayerID: players[i], CompetitionID: &comp.CompetitionID, Status: model.StatusCompleted, Score: score, JoinedAt: time.Now(), UpdatedAt: time.Now()}
+		if err := repo.CreatePlayerCompetition(ctx, pc); err != nil {
+			t.Fatalf("CreatePlayerCompetition failed: %v", err)
+		}
+	}
+
+	stats, err := repo.GetPlayerStats(ctx, players, model.Viewer{})
+	if err != nil || len(stats) != 3 {
+		t.Fatalf("expected stats for every player, got %+v, %v", stats, err)
+	}
+	want := []model.PlayerStats{
+		{PlayerID: players[0], CountryCode: "ZZ", Competitions: 1, TotalScore: 30, BestScore: 30},
+		{PlayerID: players[1], CountryCode: "ZZ", Competitions: 1, Wins: 1, TotalScore: 50, BestScore: 50},
+		{PlayerID: players[2], CountryCode: "ZZ"},
+	}
+	for i := range want {
+		if stats[i] != want[i] {
+			t.Errorf("expected %+v, got %+v", want[i], stats[i])
+		}
+	}
+
+	// A shadow-banned winner only counts for themselves.
+	now := time.Now()
+	if ok, err := repo.SetPlayerSanction(ctx, players[1], model.SanctionShadowBan, &now,
+		&model.AuditEntry{Actor: "tester", Action: "player.shadow_ban", Target: "player/" + players[1]}); err != nil || !ok {
+		t.Fatalf("SetPlayerSanction failed: %v, %v", ok, err)
+	}
+	stats, err = repo.GetPlayerStats(ctx, players, model.Viewer{PlayerID: players[0]})
+	if err != nil || len(stats) != 2 || stats[0].PlayerID != players[0] || stats[0].Wins != 1 || stats[1].PlayerID != players[2] {
+		t.Errorf("expected the shadow-banned player left out, got %+v, %v", stats, err)
+	}
+	stats, err = repo.GetPlayerStats(ctx, players, model.Viewer{PlayerID: players[1]})
+	if err != nil || len(stats) != 3 || stats[1] != want[1] {
+		t.Errorf("expected the shadow-banned player to see their own stats, got %+v, %v", stats, err)
+	}
+}
+
+func TestRegions(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewRepository(db)
+	ctx := context.Background()
+	region := &model.Region{RegionCode: "TESTR", Name: "Test Region", Countries: []string{"XA", "XB"}}
+	if err := repo.PutRegion(ctx, region, &model.AuditEntry{Actor: "tester", Action: "test.region", Target: "region/TESTR"}); err != nil {
+		t.Fatalf("PutRegion failed: %v", err)
+	}
+	defer db.Exec("DELETE FROM regions WHERE region_code = 'TESTR'")
+	if got, err := repo.GetRegion(ctx, "TESTR"); err != nil || got.Name != "Test Region" || len(got.Countries) != 2 || got.UpdatedAt.IsZero() {
+		t.Fatalf("unexpected region: %+v, %v", got, err)
+	}
+	if got, err := repo.GetRegion(ctx, "EU"); err != nil || len(got.Countries) != 27 {
+		t.Errorf("expected the seeded EU region, got %+v, %v", got, err)
+	}
+
+	players := map[string]string{"testregion1": "XA", "testregion2": "XB", "testregion3": "XC"}
+	comp := &model.Competition{CompetitionID: uuid.New(), StartedAt: time.Now().Add(-2 * time.Hour), EndsAt: time.Now().Add(-time.Hour), Status: model.CompetitionCompleted}
+	if err := repo.CreateCompetition(ctx, comp); err != nil {
+		t.Fatalf("CreateCompetition failed: %v", err)
+	}
+	defer cleanupCompetition(t, db, comp.CompetitionID.String())
+	defer cleanupPlayerCompetitionByCompetitionID(t, db, comp.CompetitionID.String())
+	scores := map[string]int{"testregion1": 30, "testregion2": 50, "testregion3": 70}
+	for id, country := range players {
+		if err := repo.CreatePlayer(ctx, &model.Player{PlayerID: id, Level: 1, CountryCode: country, Tier: model.TierBronze}); err != nil {
+			t.Fatalf("CreatePlayer failed: %v", err)
+		}
+		defer cleanupPlayer(t, db, id)
+		pc := &model.PlayerCompetition{PlayerID: id, CompetitionID: &comp.CompetitionID, Status: model.StatusCompleted, Score: scores[id],
+			CountryCode: country, JoinedAt: time.Now(), UpdatedAt: time.Now()}
+		if err := repo.CreatePlayerCompetition(ctx, pc); err != nil {
+			t.Fatalf("CreatePlayerCompetition failed: %v", err)
+		}
+	}
+
+	top, err := repo.ListTopPlayers(ctx, model.GeoFilter{RegionCode: "TESTR"}, 10)
+	if err != nil || len(top) != 2 || top[0].PlayerID != "testregion2" || top[1].PlayerID != "testregion1" {
+		t.Errorf("unexpected regional top players: %+v, %v", top, err)
+	}
+	if top, err := repo.ListTopPlayers(ctx, model.GeoFilter{CountryCode: "XC"}, 10); err != nil || len(top) != 1 || top[0].Wins != 1 {
+		t.Errorf("unexpected country top players: %+v, %v", top, err)
+	}
+
+	region.Countries = []string{"XC"}
+	if err := repo.PutRegion(ctx, region, &model.AuditEntry{Actor: "tester", Action: "test.region", Target: "region/TESTR"}); err != nil {
+		t.Fatalf("PutRegion failed: %v", err)
+	}
+	if top, err := repo.ListTopPlayers(ctx, model.GeoFilter{RegionCode: "TESTR"}, 10); err != nil || len(top) != 1 || top[0].PlayerID != "testregion3" {
+		t.Errorf("expected the region's countries to be replaced, got %+v, %v", top, err)
+	}
+	if ok, err := repo.DeleteRegion(ctx, "TESTR", &model.AuditEntry{Actor: "tester", Action: "test.region", Target: "region/TESTR"}); err != nil || !ok {
+		t.Errorf("DeleteRegion failed: %v, %v", ok, err)
+	}
+	if _, err := repo.GetRegion(ctx, "TESTR"); err != sql.ErrNoRows {
+		t.Errorf("expected sql.ErrNoRows after delete, got %v", err)
+	}
+}
+
+func TestPlayerHistory(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewRepository(db)
+	ctx := context.Background()
+	players := []string{"testhistory1", "testhistory2"}
+	for _, id := range players {
+		if err := repo.CreatePlayer(ctx, &model.Player{PlayerID: id, Level: 1, CountryCode: "ZZ", Tier: model.TierBronze}); err != nil {
+			t.Fatalf("CreatePlayer failed: %v", err)
+		}
+		defer cleanupPlayer(t, db, id)
+		defer cleanupPlayerCompetitionByPlayerID(t, db, id)
+	}
+
+	base := time.Now().Add(-10 * time.Hour)
+	comps := []*model.Competition{
+		{CompetitionID: uuid.New(), StartedAt: base, EndsAt: base.Add(time.Hour), Status: model.CompetitionCompleted},
+		{CompetitionID: uuid.New(), StartedAt: base.Add(2 * time.Hour), EndsAt: base.Add(3 * time.Hour), Status: model.CompetitionCompleted},
+		{CompetitionID: uuid.New(), StartedAt: base.Add(4 * time.Hour), EndsAt: time.Now().Add(time.Hour), Status: model.CompetitionActive},
+		// A scheduled competition the player only registered for.
+		{CompetitionID: uuid.New(), StartedAt: time.Now().Add(time.Hour), EndsAt: time.Now().Add(2 * time.Hour), Status: model.CompetitionScheduled},
+	}
+	// Scores of testhistory1 and testhistory2 in each competition.
+	scores := [][2]int{{50, 30}, {10, 20}, {5, 0}, {0, 0}}
+	for i, comp := range comps {
+		if err := repo.CreateCompetition(ctx, comp); err != nil {
+			t.Fatalf("CreateCompetition failed: %v", err)
+		}
+		defer cleanupCompetition(t, db, comp.CompetitionID.String())
+		defer cleanupPlayerCompetitionByCompetitionID(t, db, comp.CompetitionID.String())
+		status := map[model.CompetitionStatus]model.PlayerStatus{model.CompetitionCompleted: model.StatusCompleted,
+			model.CompetitionActive: model.StatusActive, model.CompetitionScheduled: model.StatusRegistered}[comp.Status]
+		for j, id := range players {
+			pc := &model.PlayerCompetition{PlayerID: id, CompetitionID: &comp.CompetitionID, Status: status, Score: scores[i][j], JoinedAt: base, UpdatedAt: base}
+			if err := repo.CreatePlayerCompetition(ctx, pc); err != nil {
+				t.Fatalf("CreatePlayerCompetition failed: %v", err)
+			}
+		}
+	}
+
+	results, err := repo.ListPlayerHistory(ctx, players[0], model.PlayerHistoryFilter{Limit: 2})
+	if err != nil || len(results) != 2 {
+		t.Fatalf("expected a page of 2, got %+v, %v", results, err)
+	}
+	if results[0].CompetitionID != comps[2].CompetitionID || results[0].Rank != 1 || results[0].Participants != 2 ||
+		results[1].CompetitionID != comps[1].CompetitionID || results[1].Rank != 2 || results[1].Score != 10 {
+		t.Errorf("unexpected history: %+v", results)
+	}
+	if results, err := repo.ListPlayerHistory(ctx, players[0], model.PlayerHistoryFilter{Limit: 2, Offset: 2}); err != nil || len(results) != 1 || results[0].CompetitionID != comps[0].CompetitionID {
+		t.Errorf("unexpected second page: %+v, %v", results, err)
+	}
+
+	filter := model.PlayerHistoryFilter{Status: model.CompetitionCompleted}
+	sum, err := repo.GetPlayerHistorySummary(ctx, players[0], filter)
+	if err != nil || *sum != (model.PlayerHistorySummary{Competitions: 2, Wins: 1, AverageRank: 1.5}) {
+		t.Errorf("unexpected summary: %+v, %v", sum, err)
+	}
+	filter = model.PlayerHistoryFilter{From: base.Add(90 * time.Minute), To: base.Add(150 * time.Minute)}
+	if results, err := repo.ListPlayerHistory(ctx, players[1], filter); err != nil || len(results) != 1 || results[0].CompetitionID != comps[1].CompetitionID {
+		t.Errorf("unexpected history in range: %+v, %v", results, err)
+	}
+
+	// A registration withdrawn before the start and a shadow-banned player
+	// change neither the participants nor the player's rank.
+	withdrawn, banned := "testhistory3", "testhistory4"
+	for _, id := range []string{withdrawn, banned} {
+		if err := repo.CreatePlayer(ctx, &model.Player{PlayerID: id, Level: 1, CountryCode: "ZZ", Tier: model.TierBronze}); err != nil {
+			t.Fatalf("CreatePlayer failed: %v", err)
+		}
+		defer cleanupPlayer(t, db, id)
+		defer cleanupPlayerCompetitionByPlayerID(t, db, id)
+	}
+	if ok, err := repo.SetPlayerSanction(ctx, banned, model.SanctionShadowBan, &base,
+		&model.AuditEntry{Actor: "tester", Action: "player.shadow_ban", Target: "player/" + banned}); err != nil || !ok {
+		t.Fatalf("SetPlayerSanction failed: %v, %v", ok, err)
+	}
+	for _, pc := range []*model.PlayerCompetition{
+		{PlayerID: withdrawn, CompetitionID: &comps[1].CompetitionID, Status: model.StatusCancelled, JoinedAt: base, UpdatedAt: base},
+		{PlayerID: banned, CompetitionID: &comps[1].CompetitionID, Status: model.StatusCompleted, Score: 100, JoinedAt: base, UpdatedAt: base},
+	} {
+		if err := repo.CreatePlayerCompetition(ctx, pc); err != nil {
+			t.Fatalf("CreatePlayerCompetition failed: %v", err)
+		}
+	}
+	filter = model.PlayerHistoryFilter{From: base.Add(90 * time.Minute), To: base.Add(150 * time.Minute)}
+	if results, err := repo.ListPlayerHistory(ctx, players[0], filter); err != nil || len(results) != 1 ||
+		results[0].Rank != 2 || results[0].Participants != 2 {
+		t.Errorf("expected the withdrawn and shadow-banned players left out, got %+v, %v", results, err)
+	}
+	if results, err := repo.ListPlayerHistory(ctx, banned, filter); err != nil || len(results) != 1 ||
+		results[0].Rank != 1 || results[0].Participants != 3 {
+		t.Errorf("expected the shadow-banned player to rank themselves, got %+v, %v", results, err)
+	}
+}
+
+func TestPlayerPrivacy(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewRepository(db)
+	ctx := context.Background()
+	players := []string{"testprivacy1", "testprivacy2"}
+	alias := "deleted-testprivacy"
+	defer cleanupPlayer(t, db, alias)
+	for _, id := range players {
+		if err := repo.CreatePlayer(ctx, &model.Player{PlayerID: id, Level: 3, CountryCode: "ZZ", Tier: model.TierSilver}); err != nil {
+			t.Fatalf("CreatePlayer failed: %v", err)
+		}
+		defer cleanupPlayer(t, db, id)
+	}
+	if _, err := repo.AddFriends(ctx, players[1], []string{players[0]}); err != nil {
+		t.Fatalf("AddFriends failed: %v", err)
+	}
+
+	now := time.Now()
+	comp := &model.Competition{CompetitionID: uuid.New(), StartedAt: now.Add(-2 * time.Hour), EndsAt: now.Add(-time.Hour), Status: model.CompetitionCompleted}
+	if err := repo.CreateCompetition(ctx, comp); err != nil {
+		t.Fatalf("CreateCompetition failed: %v", err)
+	}
+	defer cleanupCompetition(t, db, comp.CompetitionID.String())
+	defer cleanupPlayerCompetitionByCompetitionID(t, db, comp.CompetitionID.String())
+	for i, id := range players {
+		pc := &model.PlayerCompetition{PlayerID: id, CompetitionID: &comp.CompetitionID, Status: model.StatusCompleted, Score: 10 * (i + 1),
+			JoinedAt: now, UpdatedAt: now, Level: 3, CountryCode: "ZZ"}
+		if err := repo.CreatePlayerCompetition(ctx, pc); err != nil {
+			t.Fatalf("CreatePlayerCompetition failed: %v", err)
+		}
+	}
+
+	for _, entry := range []*model.AuditEntry{
+		{Actor: "alice", Action: "player.ban", Target: "player/" + players[0], Reason: "cheating as " + players[0]},
+		{Actor: "alice", Action: "competition.adjust_score", Target: "competition/" + comp.CompetitionID.String() + "/player/" + players[0],
+			Before: []byte(`{"PlayerID": "` + players[0] + `", "Score": 5}`), After: []byte(`{"PlayerID": "` + players[0] + `", "Score": 10}`)},
+		{Actor: "alice", Action: "tournament.create", Target: "tournament/" + uuid.NewString(),
+			After: []byte(`{"Seeds": ["` + players[1] + `", "` + players[0] + `"]}`)},
+	} {
+		if err := repo.CreateAuditEntry(ctx, entry); err != nil {
+			t.Fatalf("CreateAuditEntry failed: %v", err)
+		}
+	}
+
+	// Another player's reason naming a longer ID that starts with the
+	// deleted player's is not theirs to redact.
+	lookalike := &model.AuditEntry{Actor: "alice", Action: "player.ban", Target: "player/" + players[1], Reason: "smurf of " + players[0] + "0"}
+	if err := repo.CreateAuditEntry(ctx, lookalike); err != nil {
+		t.Fatalf("CreateAuditEntry failed: %v", err)
+	}
+
+	// The export finds the entries on the player and on their competition
+	// entries, not those that merely mention them.
+	if entries, err := repo.ListAuditEntries(ctx, model.AuditFilter{Player: players[0]}); err != nil || len(entries) != 2 ||
+		entries[0].Action != "competition.adjust_score" || entries[1].Action != "player.ban" {
+		t.Errorf("expected the score adjustment and the ban, got %+v, %v", entries, err)
+	}
+
+	if ok, err := repo.AnonymizePlayer(ctx, players[0], alias); err != nil || !ok {
+		t.Fatalf("AnonymizePlayer failed: %v, %v", ok, err)
+	}
+	var mentions int
+	if err := db.QueryRow(`
+		SELECT COUNT(*) FROM audit_log
+		WHERE id <> $2 AND (strpos(target, $1) > 0 OR strpos(reason, $1) > 0 OR strpos(COALESCE(before::text, ''), $1) > 0 OR strpos(COALESCE(after::text, ''), $1) > 0)
+	`, players[0], lookalike.ID).Scan(&mentions); err != nil || mentions != 0 {
+		t.Errorf("expected no audit entry to name the deleted player, got %d, %v", mentions, err)
+	}
+	if entries, err := repo.ListAuditEntries(ctx, model.AuditFilter{Target: "player/" + players[1], Limit: 1}); err != nil || len(entries) != 1 ||
+		entries[0].ID != lookalike.ID || entries[0].Reason != lookalike.Reason {
+		t.Errorf("expected the other player's reason to be left alone, got %+v, %v", entries, err)
+	}
+	if entries, err := repo.ListAuditEntries(ctx, model.AuditFilter{Target: "player/" + alias}); err != nil || len(entries) != 1 || entries[0].Reason != "cheating as "+alias {
+		t.Errorf("expected the ban to name the pseudonym, got %+v, %v", entries, err)
+	}
+	if _, err := repo.GetPlayerByID(ctx, players[0]); err != sql.ErrNoRows {
+		t.Errorf("expected the player to be gone, got %v", err)
+	}
+	board, err := repo.GetLeaderboardByCompetitionID(ctx, comp.CompetitionID.String(), model.Viewer{})
+	if err != nil || len(board) != 2 {
+		t.Fatalf("expected both entries to stay on the leaderboard, got %+v, %v", board, err)
+	}
+	for _, pc := range board {
+		if pc.PlayerID == players[0] || (pc.PlayerID == alias && (pc.Score != 10 || pc.Level != 0 || pc.CountryCode != "")) {
+			t.Errorf("unexpected leaderboard entry: %+v", pc)
+		}
+	}
+	if friends, err := repo.ListFriends(ctx, players[1]); err != nil || len(friends) != 0 {
+		t.Errorf("expected the friendship to be gone, got %v, %v", friends, err)
+	}
+	if ok, err := repo.AnonymizePlayer(ctx, players[0], alias+"2"); err != nil || ok {
+		t.Errorf("expected no change for a deleted player, got %v, %v", ok, err)
+	}
+
+	if n, err := repo.PurgeAnonymizedPlayers(ctx, now.Add(-time.Hour)); err != nil || n != 0 {
+		t.Errorf("expected nothing to purge within retention, got %d, %v", n, err)
+	}
+	if n, err := repo.PurgeAnonymizedPlayers(ctx, time.Now().Add(time.Hour)); err != nil || n != 1 {
+		t.Errorf("expected one player purged, got %d, %v", n, err)
+	}
+	if entries, err := repo.ListPlayerEntries(ctx, alias); err != nil || len(entries) != 1 || entries[0].Score != 10 {
+		t.Errorf("expected the ranked entry to survive the purge, got %+v, %v", entries, err)
+	}
+}
+
+func TestPlayerModeration(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewRepository(db)
+	ctx := context.Background()
+	players := []string{"testmoderation1", "testmoderation2"}
+	for _, id := range players {
+		if err := repo.CreatePlayer(ctx, &model.Player{PlayerID: id, Level: 1, CountryCode: "ZZ", Tier: model.TierBronze}); err != nil {
+			t.Fatalf("CreatePlayer failed: %v", err)
+		}
+		defer cleanupPlayer(t, db, id)
+		defer cleanupPlayerCompetitionByPlayerID(t, db, id)
+	}
+
+	now := time.Now()
+	comp := &model.Competition{CompetitionID: uuid.New(), StartedAt: now, EndsAt: now.Add(time.Hour), Status: model.CompetitionActive}
+	if err := repo.CreateCompetition(ctx, comp); err != nil {
+		t.Fatalf("CreateCompetition failed: %v", err)
+	}
+	defer cleanupCompetition(t, db, comp.CompetitionID.String())
+	for i, id := range players {
+		pc := &model.PlayerCompetition{PlayerID: id, CompetitionID: &comp.CompetitionID, Status: model.StatusActive, Score: 10 * (i + 1), JoinedAt: now, UpdatedAt: now}
+		if err := repo.CreatePlayerCompetition(ctx, pc); err != nil {
+			t.Fatalf("CreatePlayerCompetition failed: %v", err)
+		}
+	}
+
+	audit := &model.AuditEntry{Actor: "alice", Action: "player.shadow_ban", Target: "player/" + players[0]}
+	if ok, err := repo.SetPlayerSanction(ctx, players[0], model.SanctionShadowBan, &now, audit); err != nil || !ok || audit.ID == 0 {
+		t.Fatalf("SetPlayerSanction failed: %v, %v (audit %d)", ok, err, audit.ID)
+	}
+	for _, tc := range []struct {
+		viewer model.Viewer
+		want   int
+	}{{model.Viewer{}, 1}, {model.Viewer{PlayerID: players[1]}, 1}, {model.Viewer{PlayerID: players[0]}, 2}, {model.Viewer{All: true}, 2}} {
+		board, err := repo.GetLeaderboardByCompetitionID(ctx, comp.CompetitionID.String(), tc.viewer)
+		if err != nil || len(board) != tc.want {
+			t.Errorf("viewer %+v: expected %d entries, got %+v, %v", tc.viewer, tc.want, board, err)
+		}
+	}
+
+	// A ban takes the player out of the queue.
+	if err := repo.CreatePlayerCompetition(ctx, &model.PlayerCompetition{PlayerID: players[1], Status: model.StatusWaiting, JoinedAt: now, UpdatedAt: now}); err != nil {
+		t.Fatalf("CreatePlayerCompetition failed: %v", err)
+	}
+	audit = &model.AuditEntry{Actor: "alice", Action: "player.ban", Target: "player/" + players[1]}
+	if ok, err := repo.SetPlayerSanction(ctx, players[1], model.SanctionBan, &now, audit); err != nil || !ok {
+		t.Fatalf("SetPlayerSanction failed: %v, %v", ok, err)
+	}
+	if waiting, err := repo.IsPlayerInWaitingQueue(ctx, players[1]); err != nil || waiting {
+		t.Errorf("expected the banned player out of the queue, got %v, %v", waiting, err)
+	}
+	m, err := repo.GetPlayerModeration(ctx, players[1])
+	if err != nil || m.BannedAt == nil || m.ShadowBannedAt != nil || m.SuspendedUntil != nil {
+		t.Errorf("unexpected moderation: %+v, %v", m, err)
+	}
+
+	audit = &model.AuditEntry{Actor: "alice", Action: "player.unban", Target: "player/" + players[1]}
+	if ok, err := repo.SetPlayerSanction(ctx, players[1], model.SanctionBan, nil, audit); err != nil || !ok {
+		t.Fatalf("SetPlayerSanction failed: %v, %v", ok, err)
+	}
+	if m, err := repo.GetPlayerModeration(ctx, players[1]); err != nil || m.BannedAt != nil {
+		t.Errorf("expected the ban lifted, got %+v, %v", m, err)
+	}
+	if ok, err := repo.SetPlayerSanction(ctx, "testmoderation-ghost", model.SanctionBan, &now, audit); err != nil || ok {
+		t.Errorf("expected no change for an unknown player, got %v, %v", ok, err)
+	}
+	if _, err := repo.GetPlayerModeration(ctx, "testmoderation-ghost"); err != sql.ErrNoRows {
+		t.Errorf("expected sql.ErrNoRows, got %v", err)
+	}
+}
+
+func TestPlayerProfiles(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewRepository(db)
+	ctx := context.Background()
+	player := &model.Player{PlayerID: "testprofile1", Level: 1, CountryCode: "ZZ", Tier: model.TierBronze,
+		DisplayName: "Test Profile", AvatarURL: "https://example.com/a.png", Metadata: []byte(`{"clan": "x"}`)}
+	if err := repo.CreatePlayer(ctx, player); err != nil {
+		t.Fatalf("CreatePlayer failed: %v", err)
+	}
+	defer cleanupPlayer(t, db, player.PlayerID)
+	defer cleanupPlayer(t, db, "testprofile2")
+	if player.Version != 1 || player.CreatedAt.IsZero() {
+		t.Errorf("expected the version and timestamps filled in, got %+v", player)
+	}
+	got, err := repo.GetPlayerByID(ctx, player.PlayerID)
+	if err != nil || got.DisplayName != "Test Profile" || got.AvatarURL != "https://example.com/a.png" || string(got.Metadata) != `{"clan": "x"}` {
+		t.Errorf("unexpected player: %+v, %v", got, err)
+	}
+	if err := repo.CreatePlayer(ctx, &model.Player{PlayerID: "testprofile2", Tier: model.TierBronze, DisplayName: "TEST PROFILE"}); err != ErrDisplayNameTaken {
+		t.Errorf("expected ErrDisplayNameTaken, got %v", err)
+	}
+
+	got.Level = 2
+	got.Metadata = nil
+	if err := repo.UpdatePlayer(ctx, got); err != nil || got.Version != 2 {
+		t.Fatalf("UpdatePlayer failed: %v (version %d)", err, got.Version)
+	}
+	stale := *player
+	if err := repo.UpdatePlayer(ctx, &stale); err != sql.ErrNoRows {
+		t.Errorf("expected sql.ErrNoRows for a stale version, got %v", err)
+	}
+	if got, err := repo.GetPlayerByID(ctx, player.PlayerID); err != nil || got.Level != 2 || got.Metadata != nil || got.Version != 2 {
+		t.Errorf("unexpected player after update: %+v, %v", got, err)
+	}
+
+	now := time.Now()
+	comp := &model.Competition{CompetitionID: uuid.New(), StartedAt: now, EndsAt: now.Add(time.Hour), Status: model.CompetitionActive}
+	if err := repo.CreateCompetition(ctx, comp); err != nil {
+		t.Fatalf("CreateCompetition failed: %v", err)
+	}
+	defer cleanupCompetition(t, db, comp.CompetitionID.String())
+	defer cleanupPlayerCompetitionByCompetitionID(t, db, comp.CompetitionID.String())
+	pc := &model.PlayerCompetition{PlayerID: player.PlayerID, CompetitionID: &comp.CompetitionID, Status: model.StatusActive, JoinedAt: now, UpdatedAt: now}
+	if err := repo.CreatePlayerCompetition(ctx, pc); err != nil {
+		t.Fatalf("CreatePlayerCompetition failed: %v", err)
+	}
+	board, err := repo.GetLeaderboardByCompetitionID(ctx, comp.CompetitionID.String(), model.Viewer{})
+	if err != nil || len(board) != 1 || board[0].DisplayName != "Test Profile" || board[0].AvatarURL != "https://example.com/a.png" {
+		t.Errorf("expected display info on the leaderboard, got %+v, %v", board, err)
+	}
+}
@@ -0,0 +1,225 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"leaderboard-service/internal/model"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// templateColumns are the columns read by scanTemplate.
+const templateColumns = `template_id, name, schedule, duration_seconds, scoring_mode, min_level, max_level, countries, rewards,
+	max_players, enabled, next_run_at, created_at, updated_at`
+
+func scanTemplate(row rowScanner) (*model.CompetitionTemplate, error) {
+	var tpl model.CompetitionTemplate
+	var seconds int64
+	var rewards []byte
+	var nextRunAt sql.NullTime
+	err := row.Scan(&tpl.TemplateID, &tpl.Name, &tpl.Schedule, &seconds, &tpl.ScoringMode, &tpl.Eligibility.MinLevel, &tpl.Eligibility.MaxLevel,
+		pq.Array(&tpl.Eligibility.Countries), &rewards, &tpl.MaxPlayers, &tpl.Enabled, &nextRunAt, &tpl.CreatedAt, &tpl.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	tpl.Duration = time.Duration(seconds) * time.Second
+	if nextRunAt.Valid {
+		tpl.NextRunAt = &nextRunAt.Time
+	}
+	if err := json.Unmarshal(rewards, &tpl.Rewards); err != nil {
+		return nil, err
+	}
+	return &tpl, nil
+}
+
+func (r *Repository) queryTemplates(ctx context.Context, query string, args ...interface{}) ([]model.CompetitionTemplate, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tpls := []model.CompetitionTemplate{}
+	for rows.Next() {
+		tpl, err := scanTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+		tpls = append(tpls, *tpl)
+	}
+	return tpls, rows.Err()
+}
+
+// ListCompetitionTemplates returns all templates by name.
+func (r *Repository) ListCompetitionTemplates(ctx context.Context) ([]model.CompetitionTemplate, error) {
+	tpls, err := r.queryTemplates(ctx, `SELECT `+templateColumns+` FROM competition_templates ORDER BY name, template_id`)
+	if err != nil {
+		logger(ctx).Error("error listing competition templates", "error", err)
+	}
+	return tpls, err
+}
+
+func (r *Repository) GetCompetitionTemplate(ctx context.Context, templateID uuid.UUID) (*model.CompetitionTemplate, error) {
+	return scanTemplate(r.db.QueryRowContext(ctx,
+		`SELECT `+templateColumns+` FROM competition_templates WHERE template_id = $1`,
+		templateID,
+	))
+}
+
+// CreateCompetitionTemplate stores tpl, recording audit in the same
+// transaction. CreatedAt and UpdatedAt are set from the database.
+func (r *Repository) CreateCompetitionTemplate(ctx context.Context, tpl *model.CompetitionTemplate, audit *model.AuditEntry) error {
+	rewards, err := marshalRewards(tpl.Rewards)
+	if err != nil {
+		return err
+	}
+	err = r.inTx(ctx, func(tx *sql.Tx) error {
+		err := tx.QueryRowContext(ctx, `
+			INSERT INTO competition_templates (template_id, name, schedule, duration_seconds, scoring_mode, min_level, max_level, countries, rewards,
+				max_players, enabled, next_run_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+			RETURNING created_at, updated_at
+		`, tpl.TemplateID, tpl.Name, tpl.Schedule, int64(tpl.Duration/time.Second), tpl.ScoringMode, tpl.Eligibility.MinLevel, tpl.Eligibility.MaxLevel,
+			pq.Array(nonNil(tpl.Eligibility.Countries)), rewards, tpl.MaxPlayers, tpl.Enabled, tpl.NextRunAt,
+		).Scan(&tpl.CreatedAt, &tpl.UpdatedAt)
+		if err != nil {
+			return err
+		}
+		return insertAuditEntry(ctx, tx, audit)
+	})
+	if err != nil {
+		logger(ctx).Error("error creating competition template", "template_id", tpl.TemplateID, "error", err)
+	}
+	return err
+}
+
+// UpdateCompetitionTemplate replaces the definition of a template last
+// updated at lastUpdated, recording audit in the same transaction. It
+// reports false if the template has been changed or deleted since.
+func (r *Repository) UpdateCompetitionTemplate(ctx context.Context, tpl *model.CompetitionTemplate, lastUpdated time.Time, audit *model.AuditEntry) (bool, error) {
+	rewards, err := marshalRewards(tpl.Rewards)
+	if err != nil {
+		return false, err
+	}
+	ok, err := r.adminChange(ctx, audit, func(tx *sql.Tx) (int64, error) {
+		err := tx.QueryRowContext(ctx, `
+			UPDATE competition_templates
+			SET name = $3, schedule = $4, duration_seconds = $5, scoring_mode = $6, min_level = $7, max_level = $8, countries = $9,
+				rewards = $10, max_players = $11, enabled = $12, next_run_at = $13, updated_at = NOW()
+			WHERE template_id = $1 AND updated_at = $2
+			RETURNING updated_at
+		`, tpl.TemplateID, lastUpdated, tpl.Name, tpl.Schedule, int64(tpl.Duration/time.Second), tpl.ScoringMode, tpl.Eligibility.MinLevel,
+			tpl.Eligibility.MaxLevel, pq.Array(nonNil(tpl.Eligibility.Countries)), rewards, tpl.MaxPlayers, tpl.Enabled, tpl.NextRunAt,
+		).Scan(&tpl.UpdatedAt)
+		if err == sql.ErrNoRows {
+			return 0, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		return 1, nil
+	})
+	if err != nil {
+		logger(ctx).Error("error updating competition template", "template_id", tpl.TemplateID, "error", err)
+	}
+	return ok, err
+}
+
+// DeleteCompetitionTemplate deletes a template, recording audit in the same
+// transaction. Competitions started from it are kept. It reports false if
+// there was no such template.
+func (r *Repository) DeleteCompetitionTemplate(ctx context.Context, templateID uuid.UUID, audit *model.AuditEntry) (bool, error) {
+	ok, err := r.adminChange(ctx, audit, func(tx *sql.Tx) (int64, error) {
+		res, err := tx.ExecContext(ctx, `DELETE FROM competition_templates WHERE template_id = $1`, templateID)
+		if err != nil {
+			return 0, err
+		}
+		return res.RowsAffected()
+	})
+	if err != nil {
+		logger(ctx).Error("error deleting competition template", "template_id", templateID, "error", err)
+	}
+	return ok, err
+}
+
+// DueCompetitionTemplates returns the enabled templates whose next
+// occurrence has come.
+func (r *Repository) DueCompetitionTemplates(ctx context.Context) ([]model.CompetitionTemplate, error) {
+	tpls, err := r.queryTemplates(ctx, `
+		SELECT `+templateColumns+` FROM competition_templates
+		WHERE enabled AND next_run_at <= NOW()
+		ORDER BY next_run_at
+	`)
+	if err != nil {
+		logger(ctx).Error("error fetching due competition templates", "error", err)
+	}
+	return tpls, err
+}
+
+// StartTemplateOccurrence moves a template's next occurrence from due to
+// next, completes the competitions still running from it and stores comp,
+// if not nil, as the new one. It returns the completed competitions, and
+// reports false, changing nothing, if the template is no longer due at due,
+// e.g. because another worker got there first.
+func (r *Repository) StartTemplateOccurrence(ctx context.Context, templateID uuid.UUID, due time.Time, next *time.Time, comp *model.Competition) ([]uuid.UUID, bool, error) {
+	var closed []uuid.UUID
+	err := r.inTx(ctx, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, `
+			UPDATE competition_templates SET next_run_at = $3
+			WHERE template_id = $1 AND enabled AND next_run_at = $2
+		`, templateID, due, next)
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return errNoChange
+		}
+		rows, err := tx.QueryContext(ctx, `
+			UPDATE competitions SET status = 'COMPLETED', ends_at = LEAST(ends_at, NOW())
+			WHERE template_id = $1 AND status = 'ACTIVE'
+			RETURNING competition_id
+		`, templateID)
+		if err != nil {
+			return err
+		}
+		for rows.Next() {
+			var id uuid.UUID
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return err
+			}
+			closed = append(closed, id)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		if len(closed) > 0 {
+			ids := make([]string, len(closed))
+			for i, id := range closed {
+				ids[i] = id.String()
+			}
+			if _, err := tx.ExecContext(ctx, `
+				UPDATE player_competitions SET status = 'COMPLETED', updated_at = NOW()
+				WHERE competition_id = ANY($1::uuid[]) AND status = 'ACTIVE'
+			`, pq.Array(ids)); err != nil {
+				return err
+			}
+		}
+		if comp == nil {
+			return nil
+		}
+		return insertCompetition(ctx, tx, comp)
+	})
+	if err == errNoChange {
+		return nil, false, nil
+	}
+	if err != nil {
+		logger(ctx).Error("error starting template occurrence", "template_id", templateID, "error", err)
+		return nil, false, err
+	}
+	return closed, true, nil
+}
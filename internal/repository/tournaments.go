@@ -0,0 +1,188 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"leaderboard-service/internal/model"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// tournamentColumns are the columns read by scanTournament.
+const tournamentColumns = `tournament_id, name, format, seeding, seeds, group_size, advance, round_duration_seconds, round, rounds, status,
+	winners, created_at, completed_at`
+
+func scanTournament(row rowScanner) (*model.Tournament, error) {
+	var t model.Tournament
+	var seconds int64
+	var completedAt sql.NullTime
+	err := row.Scan(&t.TournamentID, &t.Name, &t.Format, &t.Seeding, pq.Array(&t.Seeds), &t.GroupSize, &t.Advance, &seconds, &t.Round, &t.Rounds,
+		&t.Status, pq.Array(&t.Winners), &t.CreatedAt, &completedAt)
+	if err != nil {
+		return nil, err
+	}
+	t.RoundDuration = time.Duration(seconds) * time.Second
+	if completedAt.Valid {
+		t.CompletedAt = &completedAt.Time
+	}
+	return &t, nil
+}
+
+// startTournamentRound stores the competitions of a round's matches and
+// enters their players, taking them, and any party they queued with, out of
+// the matchmaking queue.
+func startTournamentRound(ctx context.Context, tx *sql.Tx, matches []model.TournamentMatch, comps []model.Competition) error {
+	for i := range comps {
+		if err := insertCompetition(ctx, tx, &comps[i]); err != nil {
+			return err
+		}
+	}
+	var players []string
+	for _, m := range matches {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO player_competitions (player_id, competition_id, status, score, joined_at, updated_at, level, country_code, tier)
+			SELECT player_id, $2, 'ACTIVE', 0, NOW(), NOW(), level, country_code, tier FROM players WHERE player_id = ANY($1)
+		`, pq.Array(nonNil(m.Players)), m.CompetitionID); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO tournament_matches (tournament_id, round, slot, group_index, competition_id, players)
+			VALUES ($1, $2, $3, $4, $5, $6)
+		`, m.TournamentID, m.Round, m.Slot, m.Group, m.CompetitionID, pq.Array(nonNil(m.Players))); err != nil {
+			return err
+		}
+		players = append(players, m.Players...)
+	}
+	_, err := tx.ExecContext(ctx, `
+		UPDATE player_competitions SET status = 'CANCELLED', updated_at = NOW()
+		WHERE status = 'WAITING' AND (player_id = ANY($1) OR party_id IN (
+			SELECT party_id FROM player_competitions WHERE status = 'WAITING' AND party_id IS NOT NULL AND player_id = ANY($1)
+		))
+	`, pq.Array(nonNil(players)))
+	return err
+}
+
+// CreateTournament stores t and starts its first round, recording audit in
+// the same transaction. CreatedAt is set from the database.
+func (r *Repository) CreateTournament(ctx context.Context, t *model.Tournament, matches []model.TournamentMatch, comps []model.Competition, audit *model.AuditEntry) error {
+	err := r.inTx(ctx, func(tx *sql.Tx) error {
+		err := tx.QueryRowContext(ctx, `
+			INSERT INTO tournaments (tournament_id, name, format, seeding, seeds, group_size, advance, round_duration_seconds, round, rounds, status)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+			RETURNING created_at
+		`, t.TournamentID, t.Name, t.Format, t.Seeding, pq.Array(t.Seeds), t.GroupSize, t.Advance, int64(t.RoundDuration/time.Second), t.Round, t.Rounds,
+			t.Status,
+		).Scan(&t.CreatedAt)
+		if err != nil {
+			return err
+		}
+		if err := startTournamentRound(ctx, tx, matches, comps); err != nil {
+			return err
+		}
+		return insertAuditEntry(ctx, tx, audit)
+	})
+	if err != nil {
+		logger(ctx).Error("error creating tournament", "tournament_id", t.TournamentID, "error", err)
+	}
+	return err
+}
+
+func (r *Repository) GetTournament(ctx context.Context, tournamentID uuid.UUID) (*model.Tournament, error) {
+	return scanTournament(r.db.QueryRowContext(ctx,
+		`SELECT `+tournamentColumns+` FROM tournaments WHERE tournament_id = $1`,
+		tournamentID,
+	))
+}
+
+// ActiveTournaments returns the tournaments still being played, oldest
+// first.
+func (r *Repository) ActiveTournaments(ctx context.Context) ([]model.Tournament, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+tournamentColumns+` FROM tournaments WHERE status = 'ACTIVE' ORDER BY created_at`)
+	if err != nil {
+		logger(ctx).Error("error fetching active tournaments", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tournaments []model.Tournament
+	for rows.Next() {
+		t, err := scanTournament(rows)
+		if err != nil {
+			return nil, err
+		}
+		tournaments = append(tournaments, *t)
+	}
+	return tournaments, rows.Err()
+}
+
+// ListTournamentMatches returns a tournament's matches by round and slot,
+// with the status and end time of their competitions.
+func (r *Repository) ListTournamentMatches(ctx context.Context, tournamentID uuid.UUID) ([]model.TournamentMatch, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT m.tournament_id, m.round, m.slot, m.group_index, m.competition_id, m.players, c.status, c.ends_at
+		FROM tournament_matches m
+		JOIN competitions c ON c.competition_id = m.competition_id
+		WHERE m.tournament_id = $1
+		ORDER BY m.round, m.slot
+	`, tournamentID)
+	if err != nil {
+		logger(ctx).Error("error listing tournament matches", "tournament_id", tournamentID, "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	matches := []model.TournamentMatch{}
+	for rows.Next() {
+		var m model.TournamentMatch
+		if err := rows.Scan(&m.TournamentID, &m.Round, &m.Slot, &m.Group, &m.CompetitionID, pq.Array(&m.Players), &m.Status, &m.EndsAt); err != nil {
+			return nil, err
+		}
+		matches = append(matches, m)
+	}
+	return matches, rows.Err()
+}
+
+// StartTournamentRound moves a tournament on to round and starts its
+// matches. It reports false, changing nothing, if the tournament is no
+// longer active in the round before, e.g. because another worker got there
+// first.
+func (r *Repository) StartTournamentRound(ctx context.Context, tournamentID uuid.UUID, round int, matches []model.TournamentMatch, comps []model.Competition) (bool, error) {
+	err := r.inTx(ctx, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, `
+			UPDATE tournaments SET round = $2
+			WHERE tournament_id = $1 AND round = $2 - 1 AND status = 'ACTIVE'
+		`, tournamentID, round)
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return errNoChange
+		}
+		return startTournamentRound(ctx, tx, matches, comps)
+	})
+	if err == errNoChange {
+		return false, nil
+	}
+	if err != nil {
+		logger(ctx).Error("error starting tournament round", "tournament_id", tournamentID, "round", round, "error", err)
+		return false, err
+	}
+	return true, nil
+}
+
+// CompleteTournament completes a tournament whose last round, round, is
+// over. It reports false if the tournament is not active in that round.
+func (r *Repository) CompleteTournament(ctx context.Context, tournamentID uuid.UUID, round int, winners []string) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE tournaments SET status = 'COMPLETED', winners = $3, completed_at = NOW()
+		WHERE tournament_id = $1 AND round = $2 AND status = 'ACTIVE'
+	`, tournamentID, round, pq.Array(nonNil(winners)))
+	if err != nil {
+		logger(ctx).Error("error completing tournament", "tournament_id", tournamentID, "error", err)
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
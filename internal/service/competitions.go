@@ -0,0 +1,236 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"leaderboard-service/internal/auth"
+	"leaderboard-service/internal/model"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Audit actions recorded by the competition admin operations.
+const (
+	AuditActionCompetitionCancel     = "competition.cancel"
+	AuditActionCompetitionComplete   = "competition.complete"
+	AuditActionCompetitionUpdate     = "competition.update"
+	AuditActionCompetitionRemove     = "competition.remove_player"
+	AuditActionCompetitionDisqualify = "competition.disqualify_player"
+)
+
+// requireActor returns the context's actor, which every admin change is
+// recorded under.
+func requireActor(ctx context.Context) (string, error) {
+	actor := auth.Actor(ctx)
+	if actor == "" {
+		return "", fmt.Errorf("%w: an actor is required for admin changes", ErrInvalidArgument)
+	}
+	return actor, nil
+}
+
+// newAuditEntry builds an audit entry with before and after encoded as JSON;
+// nil values are left empty.
+func newAuditEntry(actor, action, target, reason string, before, after interface{}) *model.AuditEntry {
+	entry := &model.AuditEntry{Actor: actor, Action: action, Target: target, Reason: reason}
+	if before != nil {
+		entry.Before, _ = json.Marshal(before)
+	}
+	if after != nil {
+		entry.After, _ = json.Marshal(after)
+	}
+	return entry
+}
+
+func competitionTarget(competitionID uuid.UUID) string {
+	return "competition/" + competitionID.String()
+}
+
+// ListCompetitions returns competitions matching filter, most recently
+// started first.
+func (s *Service) ListCompetitions(ctx context.Context, filter model.CompetitionFilter) ([]model.Competition, error) {
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		return nil, fmt.Errorf("%w: from must be before to", ErrInvalidArgument)
+	}
+	comps, err := s.repo.ListCompetitions(ctx, filter)
+	if err != nil {
+		logger(ctx).Error("error listing competitions", "error", err)
+		return nil, err
+	}
+	return comps, nil
+}
+
+// activeCompetition loads a competition for an admin change, which only
+// applies while it is active.
+func (s *Service) activeCompetition(ctx context.Context, competitionID string) (*model.Competition, error) {
+	return s.competitionIn(ctx, competitionID, model.CompetitionActive)
+}
+
+// competitionIn loads a competition for an admin change, which only applies
+// in one of statuses.
+func (s *Service) competitionIn(ctx context.Context, competitionID string, statuses ...model.CompetitionStatus) (*model.Competition, error) {
+	comp, err := s.loadCompetition(ctx, competitionID)
+	if err != nil {
+		return nil, err
+	}
+	for _, status := range statuses {
+		if comp.Status == status {
+			return comp, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: competition is %s", ErrConflict, comp.Status)
+}
+
+func (s *Service) loadCompetition(ctx context.Context, competitionID string) (*model.Competition, error) {
+	if _, err := uuid.Parse(competitionID); err != nil {
+		return nil, fmt.Errorf("%w: competition not found", ErrNotFound)
+	}
+	comp, err := s.repo.GetCompetitionByID(ctx, competitionID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: competition not found", ErrNotFound)
+	}
+	if err != nil {
+		logger(ctx).Error("error fetching competition", "competition_id", competitionID, "error", err)
+		return nil, err
+	}
+	return comp, nil
+}
+
+// changed interprets the result of a guarded repository change: false means
+// the competition left the state the change was checked against.
+func changed(ok bool, err error) error {
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("%w: competition changed concurrently, retry", ErrConflict)
+	}
+	return nil
+}
+
+// CancelCompetition cancels a competition that has not finished, with its
+// players' entries and registrations, and closes its stream with a
+// cancelled event.
+func (s *Service) CancelCompetition(ctx context.Context, competitionID, reason string) (*model.Competition, error) {
+	actor, err := requireActor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	before, err := s.competitionIn(ctx, competitionID, model.CompetitionScheduled, model.CompetitionOpen, model.CompetitionActive)
+	if err != nil {
+		return nil, err
+	}
+	after := *before
+	after.Status = model.CompetitionCancelled
+	entry := newAuditEntry(actor, AuditActionCompetitionCancel, competitionTarget(before.CompetitionID), reason, before, after)
+	if err := changed(s.repo.CancelCompetition(ctx, before.CompetitionID, entry)); err != nil {
+		return nil, err
+	}
+	logger(ctx).Info("competition cancelled", "competition_id", competitionID, "actor", actor, "audit_id", entry.ID)
+	s.publishFinished(ctx, competitionID, model.CompetitionCancelled)
+	return &after, nil
+}
+
+// CompleteCompetition completes an active competition before its end time,
+// publishing its final standings.
+func (s *Service) CompleteCompetition(ctx context.Context, competitionID, reason string) (*model.Competition, error) {
+	actor, err := requireActor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	before, err := s.activeCompetition(ctx, competitionID)
+	if err != nil {
+		return nil, err
+	}
+	after := *before
+	after.Status = model.CompetitionCompleted
+	if now := time.Now(); after.EndsAt.After(now) {
+		after.EndsAt = now
+	}
+	entry := newAuditEntry(actor, AuditActionCompetitionComplete, competitionTarget(before.CompetitionID), reason, before, after)
+	if err := changed(s.repo.CompleteCompetition(ctx, before.CompetitionID, entry)); err != nil {
+		return nil, err
+	}
+	logger(ctx).Info("competition completed early", "competition_id", competitionID, "actor", actor, "audit_id", entry.ID)
+	s.publishFinished(ctx, competitionID, model.CompetitionCompleted)
+	if err := s.settleLeagues(ctx); err != nil {
+		logger(ctx).Error("error settling league after early completion", "competition_id", competitionID, "error", err)
+	}
+	return &after, nil
+}
+
+// SetCompetitionEndsAt extends or shortens an active competition. The new
+// end time must be in the future; use CompleteCompetition to end it now.
+func (s *Service) SetCompetitionEndsAt(ctx context.Context, competitionID string, endsAt time.Time, reason string) (*model.Competition, error) {
+	actor, err := requireActor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !endsAt.After(time.Now()) {
+		return nil, fmt.Errorf("%w: ends_at must be in the future", ErrInvalidArgument)
+	}
+	before, err := s.activeCompetition(ctx, competitionID)
+	if err != nil {
+		return nil, err
+	}
+	after := *before
+	after.EndsAt = endsAt
+	entry := newAuditEntry(actor, AuditActionCompetitionUpdate, competitionTarget(before.CompetitionID), reason, before, after)
+	if err := changed(s.repo.SetCompetitionEndsAt(ctx, before.CompetitionID, endsAt, entry)); err != nil {
+		return nil, err
+	}
+	logger(ctx).Info("competition end time changed", "competition_id", competitionID, "actor", actor,
+		"audit_id", entry.ID, "from", before.EndsAt, "to", endsAt)
+	s.publishSnapshot(ctx, competitionID)
+	return &after, nil
+}
+
+// ExcludePlayer takes playerID out of a competition, active or completed,
+// with status REMOVED or DISQUALIFIED. Their entry is kept but their score
+// no longer counts towards the leaderboard.
+func (s *Service) ExcludePlayer(ctx context.Context, competitionID, playerID string, status model.PlayerStatus, reason string) error {
+	actor, err := requireActor(ctx)
+	if err != nil {
+		return err
+	}
+	action := AuditActionCompetitionRemove
+	switch status {
+	case model.StatusRemoved:
+	case model.StatusDisqualified:
+		action = AuditActionCompetitionDisqualify
+	default:
+		return fmt.Errorf("%w: status must be REMOVED or DISQUALIFIED", ErrInvalidArgument)
+	}
+	comp, err := s.loadCompetition(ctx, competitionID)
+	if err != nil {
+		return err
+	}
+	pc, err := s.repo.GetCompetitionPlayer(ctx, comp.CompetitionID, playerID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: player not in competition", ErrNotFound)
+	}
+	if err != nil {
+		logger(ctx).Error("error fetching competition player", "competition_id", competitionID, "player_id", playerID, "error", err)
+		return err
+	}
+	if pc.Status != model.StatusActive && pc.Status != model.StatusCompleted {
+		return fmt.Errorf("%w: player entry is %s", ErrConflict, pc.Status)
+	}
+
+	type entryState struct {
+		Status model.PlayerStatus `json:"status"`
+		Score  int                `json:"score"`
+	}
+	entry := newAuditEntry(actor, action, competitionTarget(comp.CompetitionID)+"/player/"+playerID, reason,
+		entryState{pc.Status, pc.Score}, entryState{status, pc.Score})
+	if err := changed(s.repo.ExcludeCompetitionPlayer(ctx, comp.CompetitionID, playerID, status, entry)); err != nil {
+		return err
+	}
+	logger(ctx).Info("player excluded from competition", "competition_id", competitionID, "player_id", playerID,
+		"status", status, "actor", actor, "audit_id", entry.ID)
+	s.publishSnapshot(ctx, competitionID)
+	return nil
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"leaderboard-service/internal/auth"
+	"leaderboard-service/internal/model"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func activeCompetitionRepo(comp *model.Competition) *mockRepo {
+	return &mockRepo{GetCompetitionByIDFunc: func(ctx context.Context, competitionID string) (*model.Competition, error) {
+		if competitionID != comp.CompetitionID.String() {
+			return nil, sql.ErrNoRows
+		}
+		c := *comp
+		return &c, nil
+	}}
+}
+
+func TestService_CancelCompetition(t *testing.T) {
+	comp := &model.Competition{CompetitionID: uuid.New(), EndsAt: time.Now().Add(time.Minute), Status: model.CompetitionActive}
+	repo := activeCompetitionRepo(comp)
+	var audited *model.AuditEntry
+	repo.CancelCompetitionFunc = func(ctx context.Context, competitionID uuid.UUID, audit *model.AuditEntry) (bool, error) {
+		audited = audit
+		return true, nil
+	}
+	svc := NewService(repo, validConfig())
+	sub := svc.Hub().Subscribe(competitionTopic(comp.CompetitionID.String()), 0)
+	defer sub.Close()
+
+	got, err := svc.CancelCompetition(auth.WithActor(context.Background(), "alice"), comp.CompetitionID.String(), "bad seed")
+	if err != nil {
+		t.Fatalf("CancelCompetition failed: %v", err)
+	}
+	if got.Status != model.CompetitionCancelled {
+		t.Errorf("expected CANCELLED, got %s", got.Status)
+	}
+	if audited == nil || audited.Actor != "alice" || audited.Action != AuditActionCompetitionCancel ||
+		audited.Target != "competition/"+comp.CompetitionID.String() || audited.Reason != "bad seed" {
+		t.Fatalf("unexpected audit entry: %+v", audited)
+	}
+	var before, after model.Competition
+	json.Unmarshal(audited.Before, &before)
+	json.Unmarshal(audited.After, &after)
+	if before.Status != model.CompetitionActive || after.Status != model.CompetitionCancelled {
+		t.Errorf("unexpected before/after: %s -> %s", audited.Before, audited.After)
+	}
+	select {
+	case ev := <-sub.C:
+		if data := ev.Data.(map[string]interface{}); ev.Type != EventCompleted || data["status"] != model.CompetitionCancelled {
+			t.Errorf("unexpected event: %+v", ev)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected a cancelled event")
+	}
+}
+
+func TestService_CompetitionAdmin_Errors(t *testing.T) {
+	active := &model.Competition{CompetitionID: uuid.New(), Status: model.CompetitionActive}
+	alice := auth.WithActor(context.Background(), "alice")
+	cases := []struct {
+		name string
+		ctx  context.Context
+		comp *model.Competition
+		id   string
+		want error
+	}{
+		{"no actor", context.Background(), active, active.CompetitionID.String(), ErrInvalidArgument},
+		{"bad id", alice, active, "nope", ErrNotFound},
+		{"unknown", alice, active, uuid.NewString(), ErrNotFound},
+		{"completed", alice, &model.Competition{CompetitionID: active.CompetitionID, Status: model.CompetitionCompleted}, active.CompetitionID.String(), ErrConflict},
+	}
+	for _, tc := range cases {
+		svc := NewService(activeCompetitionRepo(tc.comp), validConfig())
+		if _, err := svc.CompleteCompetition(tc.ctx, tc.id, ""); !errors.Is(err, tc.want) {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.want, err)
+		}
+	}
+
+	repo := activeCompetitionRepo(active)
+	repo.SetCompetitionEndsAtFunc = func(ctx context.Context, competitionID uuid.UUID, endsAt time.Time, audit *model.AuditEntry) (bool, error) {
+		return false, nil
+	}
+	svc := NewService(repo, validConfig())
+	if _, err := svc.SetCompetitionEndsAt(alice, active.CompetitionID.String(), time.Now().Add(-time.Minute), ""); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("past ends_at: expected ErrInvalidArgument, got %v", err)
+	}
+	if _, err := svc.SetCompetitionEndsAt(alice, active.CompetitionID.String(), time.Now().Add(time.Hour), ""); !errors.Is(err, ErrConflict) {
+		t.Errorf("concurrent change: expected ErrConflict, got %v", err)
+	}
+}
+
+func TestService_ExcludePlayer(t *testing.T) {
+	comp := &model.Competition{CompetitionID: uuid.New(), Status: model.CompetitionCompleted}
+	repo := activeCompetitionRepo(comp)
+	repo.GetCompetitionPlayerFunc = func(ctx context.Context, competitionID uuid.UUID, playerID string) (*model.PlayerCompetition, error) {
+		if playerID != "p1" {
+			return nil, sql.ErrNoRows
+		}
+		return &model.PlayerCompetition{PlayerID: playerID, CompetitionID: &competitionID, Status: model.StatusCompleted, Score: 900}, nil
+	}
+	var gotStatus model.PlayerStatus
+	var audited *model.AuditEntry
+	repo.ExcludeCompetitionPlayerFunc = func(ctx context.Context, competitionID uuid.UUID, playerID string, status model.PlayerStatus, audit *model.AuditEntry) (bool, error) {
+		gotStatus, audited = status, audit
+		return true, nil
+	}
+	svc := NewService(repo, validConfig())
+	ctx := auth.WithActor(context.Background(), "alice")
+	id := comp.CompetitionID.String()
+
+	if err := svc.ExcludePlayer(ctx, id, "p1", model.StatusDisqualified, "speed hack"); err != nil {
+		t.Fatalf("ExcludePlayer failed: %v", err)
+	}
+	if gotStatus != model.StatusDisqualified || audited.Action != AuditActionCompetitionDisqualify || audited.Target != "competition/"+id+"/player/p1" {
+		t.Errorf("unexpected exclusion: %s %+v", gotStatus, audited)
+	}
+	if string(audited.Before) != `{"status":"COMPLETED","score":900}` || string(audited.After) != `{"status":"DISQUALIFIED","score":900}` {
+		t.Errorf("unexpected before/after: %s -> %s", audited.Before, audited.After)
+	}
+
+	if err := svc.ExcludePlayer(ctx, id, "p2", model.StatusRemoved, ""); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for a player not in the competition, got %v", err)
+	}
+	if err := svc.ExcludePlayer(ctx, id, "p1", model.StatusCancelled, ""); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("expected ErrInvalidArgument for status CANCELLED, got %v", err)
+	}
+}
@@ -0,0 +1,20 @@
+package service
+
+import "errors"
+
+var (
+	// ErrInvalidArgument is wrapped by errors caused by a bad request value.
+	ErrInvalidArgument = errors.New("invalid argument")
+	// ErrNotFound is wrapped by errors for a missing competition or player
+	// in the admin API.
+	ErrNotFound = errors.New("not found")
+	// ErrConflict is wrapped by errors for a change that does not apply to
+	// the current state, such as cancelling a completed competition.
+	ErrConflict = errors.New("conflict")
+	// ErrForbidden is wrapped by errors for an action the player may not
+	// take, such as a party member other than the leader inviting others.
+	ErrForbidden = errors.New("forbidden")
+	// ErrPreconditionFailed is wrapped by errors for a change made against
+	// a version of a player that is no longer current.
+	ErrPreconditionFailed = errors.New("precondition failed")
+)
@@ -0,0 +1,177 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"leaderboard-service/internal/model"
+	"slices"
+)
+
+// MaxFriendImport caps how many friend IDs ImportFriends takes at once.
+const MaxFriendImport = 1000
+
+// FriendImport reports the outcome of ImportFriends.
+type FriendImport struct {
+	// Added lists the players now followed.
+	Added []string `json:"added"`
+	// Ignored lists the IDs that are not players, already followed or the
+	// importing player's own.
+	Ignored []string `json:"ignored"`
+}
+
+// FriendRank is a player's place in a competition among a player and their
+// friends.
+type FriendRank struct {
+	Rank        int    `json:"rank"`
+	PlayerID    string `json:"player_id"`
+	DisplayName string `json:"display_name,omitempty"`
+	AvatarURL   string `json:"avatar_url,omitempty"`
+	Score       int    `json:"score"`
+}
+
+// FriendsLeaderboard ranks a player against their friends in one
+// competition. Friends who did not play in it are left out.
+type FriendsLeaderboard struct {
+	LeaderboardID string       `json:"leaderboard_id"`
+	PlayerID      string       `json:"player_id"`
+	Leaderboard   []FriendRank `json:"leaderboard"`
+}
+
+// StatsRank is a player's place in a ranking by their stats over all
+// completed competitions.
+type StatsRank struct {
+	Rank int `json:"rank"`
+	model.PlayerStats
+}
+
+// FriendsStats ranks a player against their friends by total score, then
+// wins.
+type FriendsStats struct {
+	PlayerID    string      `json:"player_id"`
+	Leaderboard []StatsRank `json:"leaderboard"`
+}
+
+// friendGroup returns playerID followed by the players they follow, or
+// ErrNotFound if playerID is not a player.
+func (s *Service) friendGroup(ctx context.Context, playerID string) ([]string, error) {
+	if _, err := s.findPlayer(ctx, playerID); err != nil {
+		return nil, err
+	}
+	friends, err := s.repo.ListFriends(ctx, playerID)
+	if err != nil {
+		return nil, err
+	}
+	return append([]string{playerID}, friends...), nil
+}
+
+// ListFriends returns the IDs of the players playerID follows.
+func (s *Service) ListFriends(ctx context.Context, playerID string) ([]string, error) {
+	group, err := s.friendGroup(ctx, playerID)
+	if err != nil {
+		return nil, err
+	}
+	return group[1:], nil
+}
+
+// AddFriend makes playerID follow friendID. Following a player twice is not
+// an error.
+func (s *Service) AddFriend(ctx context.Context, playerID, friendID string) error {
+	if playerID == friendID {
+		return fmt.Errorf("%w: a player cannot befriend themselves", ErrInvalidArgument)
+	}
+	if _, err := s.findPlayer(ctx, playerID); err != nil {
+		return err
+	}
+	if _, err := s.findPlayer(ctx, friendID); err != nil {
+		return fmt.Errorf("%w: friend not found", ErrNotFound)
+	}
+	added, err := s.repo.AddFriends(ctx, playerID, []string{friendID})
+	if err != nil {
+		return err
+	}
+	if len(added) > 0 {
+		logger(ctx).Info("friend added", "player_id", playerID, "friend_id", friendID)
+	}
+	return nil
+}
+
+// ImportFriends makes playerID follow every player in friendIDs, e.g. a
+// friend list synced from the platform identity service. IDs that are not
+// players are ignored rather than failing the import.
+func (s *Service) ImportFriends(ctx context.Context, playerID string, friendIDs []string) (*FriendImport, error) {
+	if len(friendIDs) > MaxFriendImport {
+		return nil, fmt.Errorf("%w: at most %d friend IDs per import", ErrInvalidArgument, MaxFriendImport)
+	}
+	if _, err := s.findPlayer(ctx, playerID); err != nil {
+		return nil, err
+	}
+	added, err := s.repo.AddFriends(ctx, playerID, friendIDs)
+	if err != nil {
+		return nil, err
+	}
+	result := &FriendImport{Added: added, Ignored: []string{}}
+	for _, id := range friendIDs {
+		if !slices.Contains(added, id) && !slices.Contains(result.Ignored, id) {
+			result.Ignored = append(result.Ignored, id)
+		}
+	}
+	logger(ctx).Info("friends imported", "player_id", playerID, "added", len(added), "ignored", len(result.Ignored))
+	return result, nil
+}
+
+// RemoveFriend stops playerID following friendID.
+func (s *Service) RemoveFriend(ctx context.Context, playerID, friendID string) error {
+	removed, err := s.repo.RemoveFriend(ctx, playerID, friendID)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return fmt.Errorf("%w: friend not found", ErrNotFound)
+	}
+	logger(ctx).Info("friend removed", "player_id", playerID, "friend_id", friendID)
+	return nil
+}
+
+// GetFriendsLeaderboard ranks playerID and their friends by their scores in
+// a competition, keeping the competition's own order.
+func (s *Service) GetFriendsLeaderboard(ctx context.Context, leaderboardID, playerID string) (*FriendsLeaderboard, error) {
+	group, err := s.friendGroup(ctx, playerID)
+	if err != nil {
+		return nil, err
+	}
+	pcs, err := s.repo.GetLeaderboardByCompetitionID(ctx, leaderboardID, model.Viewer{PlayerID: playerID})
+	if err != nil || len(pcs) == 0 {
+		return nil, fmt.Errorf("%w: leaderboard not found", ErrNotFound)
+	}
+	board := &FriendsLeaderboard{LeaderboardID: leaderboardID, PlayerID: playerID, Leaderboard: []FriendRank{}}
+	for _, pc := range pcs {
+		if slices.Contains(group, pc.PlayerID) {
+			board.Leaderboard = append(board.Leaderboard, FriendRank{Rank: len(board.Leaderboard) + 1, PlayerID: pc.PlayerID,
+				DisplayName: pc.DisplayName, AvatarURL: pc.AvatarURL, Score: pc.Score})
+		}
+	}
+	return board, nil
+}
+
+// GetFriendsStats ranks playerID and their friends by their stats over all
+// the competitions they completed. Shadow-banned friends are left out, as on
+// public leaderboards.
+func (s *Service) GetFriendsStats(ctx context.Context, playerID string) (*FriendsStats, error) {
+	group, err := s.friendGroup(ctx, playerID)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := s.repo.GetPlayerStats(ctx, group, model.Viewer{PlayerID: playerID})
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(stats, func(a, b model.PlayerStats) int {
+		return cmp.Or(cmp.Compare(b.TotalScore, a.TotalScore), cmp.Compare(b.Wins, a.Wins), cmp.Compare(a.PlayerID, b.PlayerID))
+	})
+	result := &FriendsStats{PlayerID: playerID, Leaderboard: make([]StatsRank, len(stats))}
+	for i, st := range stats {
+		result.Leaderboard[i] = StatsRank{Rank: i + 1, PlayerStats: st}
+	}
+	return result, nil
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"leaderboard-service/internal/model"
+	"testing"
+)
+
+func friendsRepo(friends map[string][]string) *mockRepo {
+	return &mockRepo{
+		GetPlayerByIDFunc: func(ctx context.Context, playerID string) (*model.Player, error) {
+			if _, ok := friends[playerID]; !ok {
+				return nil, sql.ErrNoRows
+			}
+			return &model.Player{PlayerID: playerID}, nil
+		},
+		ListFriendsFunc: func(ctx context.Context, playerID string) ([]string, error) {
+			return friends[playerID], nil
+		},
+	}
+}
+
+func TestService_AddFriend(t *testing.T) {
+	repo := friendsRepo(map[string][]string{"p1": nil, "p2": nil})
+	var added []string
+	repo.AddFriendsFunc = func(ctx context.Context, playerID string, friendIDs []string) ([]string, error) {
+		added = append(added, friendIDs...)
+		return friendIDs, nil
+	}
+	svc := NewService(repo, validConfig())
+
+	cases := map[string]struct {
+		player, friend string
+		want           error
+	}{
+		"self":           {"p1", "p1", ErrInvalidArgument},
+		"unknown player": {"ghost", "p2", ErrNotFound},
+		"unknown friend": {"p1", "ghost", ErrNotFound},
+	}
+	for name, tc := range cases {
+		if err := svc.AddFriend(context.Background(), tc.player, tc.friend); !errors.Is(err, tc.want) {
+			t.Errorf("%s: expected %v, got %v", name, tc.want, err)
+		}
+	}
+	if err := svc.AddFriend(context.Background(), "p1", "p2"); err != nil || fmt.Sprint(added) != "[p2]" {
+		t.Errorf("expected p2 to be added, got %v (%v)", added, err)
+	}
+}
+
+func TestService_ImportFriends(t *testing.T) {
+	repo := friendsRepo(map[string][]string{"p1": nil})
+	repo.AddFriendsFunc = func(ctx context.Context, playerID string, friendIDs []string) ([]string, error) {
+		return []string{"p2", "p4"}, nil
+	}
+	svc := NewService(repo, validConfig())
+
+	result, err := svc.ImportFriends(context.Background(), "p1", []string{"p2", "ghost", "p4", "p1", "ghost"})
+	if err != nil {
+		t.Fatalf("ImportFriends failed: %v", err)
+	}
+	if fmt.Sprint(result.Added) != "[p2 p4]" || fmt.Sprint(result.Ignored) != "[ghost p1]" {
+		t.Errorf("unexpected import: %+v", result)
+	}
+
+	if _, err := svc.ImportFriends(context.Background(), "p1", make([]string, MaxFriendImport+1)); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("expected ErrInvalidArgument for an oversized import, got %v", err)
+	}
+}
+
+func TestService_RemoveFriend_NotFound(t *testing.T) {
+	repo := &mockRepo{
+		RemoveFriendFunc: func(ctx context.Context, playerID, friendID string) (bool, error) {
+			return false, nil
+		},
+	}
+	if err := NewService(repo, validConfig()).RemoveFriend(context.Background(), "p1", "p2"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestService_GetFriendsLeaderboard(t *testing.T) {
+	repo := friendsRepo(map[string][]string{"p2": {"p1", "p4", "p9"}})
+	repo.GetLeaderboardByCompetitionIDFunc = func(ctx context.Context, competitionID string, viewer model.Viewer) ([]model.PlayerCompetition, error) {
+		if competitionID != "c1" {
+			return nil, nil
+		}
+		return []model.PlayerCompetition{
+			{PlayerID: "p1", Score: 50, DisplayName: "Ada"}, {PlayerID: "p3", Score: 40}, {PlayerID: "p2", Score: 30}, {PlayerID: "p4", Score: 10},
+		}, nil
+	}
+	svc := NewService(repo, validConfig())
+
+	board, err := svc.GetFriendsLeaderboard(context.Background(), "c1", "p2")
+	if err != nil {
+		t.Fatalf("GetFriendsLeaderboard failed: %v", err)
+	}
+	want := []FriendRank{{1, "p1", "Ada", "", 50}, {2, "p2", "", "", 30}, {3, "p4", "", "", 10}}
+	if fmt.Sprint(board.Leaderboard) != fmt.Sprint(want) {
+		t.Errorf("expected %v, got %v", want, board.Leaderboard)
+	}
+
+	if _, err := svc.GetFriendsLeaderboard(context.Background(), "c2", "p2"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for an unknown leaderboard, got %v", err)
+	}
+	if _, err := svc.GetFriendsLeaderboard(context.Background(), "c1", "ghost"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for an unknown player, got %v", err)
+	}
+}
+
+func TestService_GetFriendsStats(t *testing.T) {
+	repo := friendsRepo(map[string][]string{"p1": {"p2", "p3", "p4"}})
+	var asked []string
+	var viewer model.Viewer
+	repo.GetPlayerStatsFunc = func(ctx context.Context, playerIDs []string, v model.Viewer) ([]model.PlayerStats, error) {
+		asked, viewer = playerIDs, v
+		return []model.PlayerStats{
+			{PlayerID: "p1", Competitions: 3, Wins: 1, TotalScore: 100},
+			{PlayerID: "p2", Competitions: 2, Wins: 2, TotalScore: 100},
+			{PlayerID: "p3", Competitions: 5, TotalScore: 250},
+			{PlayerID: "p4"},
+		}, nil
+	}
+	stats, err := NewService(repo, validConfig()).GetFriendsStats(context.Background(), "p1")
+	if err != nil {
+		t.Fatalf("GetFriendsStats failed: %v", err)
+	}
+	if fmt.Sprint(asked) != "[p1 p2 p3 p4]" || viewer != (model.Viewer{PlayerID: "p1"}) {
+		t.Errorf("expected stats for the player and their friends as the player sees them, got %v, %+v", asked, viewer)
+	}
+	var order []string
+	for i, r := range stats.Leaderboard {
+		if r.Rank != i+1 {
+			t.Errorf("expected rank %d, got %d", i+1, r.Rank)
+		}
+		order = append(order, r.PlayerID)
+	}
+	// Ties on total score go to the player with more wins.
+	if fmt.Sprint(order) != "[p3 p2 p1 p4]" {
+		t.Errorf("unexpected order: %v", order)
+	}
+}
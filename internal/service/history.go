@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"leaderboard-service/internal/model"
+)
+
+const (
+	// DefaultHistoryPage is how many competitions GetPlayerHistory returns
+	// unless asked for another number, up to MaxHistoryPage.
+	DefaultHistoryPage = 20
+	MaxHistoryPage     = 100
+)
+
+// PlayerHistory is a page of the competitions a player took part in, with
+// totals over every competition the filter matches.
+type PlayerHistory struct {
+	PlayerID     string                     `json:"player_id"`
+	Total        int                        `json:"total"`
+	Limit        int                        `json:"limit"`
+	Offset       int                        `json:"offset"`
+	Competitions []model.CompetitionResult  `json:"competitions"`
+	Summary      model.PlayerHistorySummary `json:"summary"`
+}
+
+// GetPlayerHistory returns the competitions playerID took part in, most
+// recently started first. A Limit of 0 means DefaultHistoryPage.
+func (s *Service) GetPlayerHistory(ctx context.Context, playerID string, filter model.PlayerHistoryFilter) (*PlayerHistory, error) {
+	switch filter.Status {
+	case "", model.CompetitionActive, model.CompetitionCompleted, model.CompetitionCancelled:
+	default:
+		return nil, fmt.Errorf("%w: status must be ACTIVE, COMPLETED or CANCELLED", ErrInvalidArgument)
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		return nil, fmt.Errorf("%w: from must be before to", ErrInvalidArgument)
+	}
+	if filter.Limit == 0 {
+		filter.Limit = DefaultHistoryPage
+	}
+	if filter.Limit < 1 || filter.Limit > MaxHistoryPage {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidArgument, MaxHistoryPage)
+	}
+	if filter.Offset < 0 {
+		return nil, fmt.Errorf("%w: offset must not be negative", ErrInvalidArgument)
+	}
+	if _, err := s.findPlayer(ctx, playerID); err != nil {
+		return nil, err
+	}
+
+	results, err := s.repo.ListPlayerHistory(ctx, playerID, filter)
+	if err != nil {
+		return nil, err
+	}
+	sum, err := s.repo.GetPlayerHistorySummary(ctx, playerID, filter)
+	if err != nil {
+		return nil, err
+	}
+	return &PlayerHistory{PlayerID: playerID, Total: sum.Competitions, Limit: filter.Limit, Offset: filter.Offset,
+		Competitions: results, Summary: *sum}, nil
+}
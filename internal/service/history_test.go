@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"leaderboard-service/internal/model"
+	"testing"
+	"time"
+)
+
+func TestService_GetPlayerHistory(t *testing.T) {
+	var got model.PlayerHistoryFilter
+	repo := &mockRepo{
+		GetPlayerByIDFunc: func(ctx context.Context, playerID string) (*model.Player, error) {
+			if playerID != "p1" {
+				return nil, sql.ErrNoRows
+			}
+			return &model.Player{PlayerID: playerID}, nil
+		},
+		ListPlayerHistoryFunc: func(ctx context.Context, playerID string, filter model.PlayerHistoryFilter) ([]model.CompetitionResult, error) {
+			got = filter
+			return []model.CompetitionResult{{Status: model.CompetitionCompleted, Rank: 1, Participants: 4}}, nil
+		},
+		GetPlayerHistorySummaryFunc: func(ctx context.Context, playerID string, filter model.PlayerHistoryFilter) (*model.PlayerHistorySummary, error) {
+			return &model.PlayerHistorySummary{Competitions: 7, Wins: 2, AverageRank: 2.5}, nil
+		},
+	}
+	svc := NewService(repo, validConfig())
+
+	history, err := svc.GetPlayerHistory(context.Background(), "p1", model.PlayerHistoryFilter{Status: model.CompetitionCompleted, Offset: 5})
+	if err != nil {
+		t.Fatalf("GetPlayerHistory failed: %v", err)
+	}
+	if got.Limit != DefaultHistoryPage || got.Offset != 5 || got.Status != model.CompetitionCompleted {
+		t.Errorf("unexpected filter: %+v", got)
+	}
+	if history.Total != 7 || history.Limit != DefaultHistoryPage || len(history.Competitions) != 1 || history.Summary.AverageRank != 2.5 {
+		t.Errorf("unexpected history: %+v", history)
+	}
+
+	now := time.Now()
+	cases := map[string]struct {
+		player string
+		filter model.PlayerHistoryFilter
+		want   error
+	}{
+		"unknown player":  {"ghost", model.PlayerHistoryFilter{}, ErrNotFound},
+		"waiting status":  {"p1", model.PlayerHistoryFilter{Status: "WAITING"}, ErrInvalidArgument},
+		"scheduled":       {"p1", model.PlayerHistoryFilter{Status: model.CompetitionScheduled}, ErrInvalidArgument},
+		"inverted range":  {"p1", model.PlayerHistoryFilter{From: now, To: now.Add(-time.Hour)}, ErrInvalidArgument},
+		"limit too large": {"p1", model.PlayerHistoryFilter{Limit: MaxHistoryPage + 1}, ErrInvalidArgument},
+		"negative offset": {"p1", model.PlayerHistoryFilter{Offset: -1}, ErrInvalidArgument},
+	}
+	for name, tc := range cases {
+		if _, err := svc.GetPlayerHistory(context.Background(), tc.player, tc.filter); !errors.Is(err, tc.want) {
+			t.Errorf("%s: expected %v, got %v", name, tc.want, err)
+		}
+	}
+}
@@ -0,0 +1,267 @@
+package service
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// subscriberBufferSize is how many undelivered events a single subscriber may
+	// hold before it is considered too slow and dropped.
+	subscriberBufferSize = 64
+	// topicHistorySize is how many recent events each topic keeps for
+	// Last-Event-ID resume. It must not exceed subscriberBufferSize so a full
+	// replay always fits into a fresh subscription.
+	topicHistorySize = 64
+	// closedTopicRetention is how long a closed topic is remembered, so that
+	// events published late, after the topic was closed, are dropped rather
+	// than starting it afresh.
+	closedTopicRetention = time.Hour
+)
+
+// Event is a single message published on a Hub topic. IDs are assigned by the
+// hub and increase monotonically per topic.
+type Event struct {
+	ID   uint64
+	Type string
+	Data interface{}
+}
+
+// Subscription receives events for one topic. C is closed when the topic is
+// closed, when the subscriber falls too far behind, or after Close is called.
+type Subscription struct {
+	C <-chan Event
+	// Resumed reports whether every event after the requested Last-Event-ID
+	// could be replayed from history. When false nothing is replayed and the
+	// caller should send a fresh snapshot before relaying events.
+	Resumed bool
+
+	ch     chan Event
+	hub    *Hub
+	topic  string
+	closed bool
+}
+
+// Close unsubscribes and releases the subscription. It is safe to call more
+// than once.
+func (s *Subscription) Close() {
+	if s.hub == nil {
+		return
+	}
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	if t, ok := s.hub.topics[s.topic]; ok {
+		delete(t.subs, s)
+		if len(t.subs) == 0 && !t.sequenced {
+			delete(s.hub.topics, s.topic)
+		}
+	}
+	s.closeLocked()
+}
+
+func (s *Subscription) closeLocked() {
+	if !s.closed {
+		s.closed = true
+		close(s.ch)
+	}
+}
+
+type hubTopic struct {
+	nextID  uint64
+	history []Event
+	subs    map[*Subscription]struct{}
+	// sequenced is set once an event ID has been used for resume. The
+	// topic is then kept until CloseTopic, so that IDs never start over.
+	sequenced bool
+}
+
+// Hub is an in-process publish/subscribe hub used to fan out leaderboard
+// updates to streaming clients. Publishing never blocks: subscribers whose
+// buffer is full are dropped and are expected to reconnect with their last
+// seen event ID.
+type Hub struct {
+	mu     sync.Mutex
+	topics map[string]*hubTopic
+	// closed holds when each recently closed topic was closed.
+	closed map[string]time.Time
+}
+
+func NewHub() *Hub {
+	return &Hub{topics: make(map[string]*hubTopic), closed: make(map[string]time.Time)}
+}
+
+func (h *Hub) topicLocked(name string) *hubTopic {
+	t, ok := h.topics[name]
+	if !ok {
+		t = &hubTopic{nextID: 1, subs: make(map[*Subscription]struct{})}
+		h.topics[name] = t
+	}
+	return t
+}
+
+// Subscribe registers a new subscriber on topic. If lastEventID is non-zero
+// and every event after it is still retained, those events are replayed
+// before live events; a partial replay is never sent. Subscribing to a
+// closed topic yields an already closed subscription.
+func (h *Hub) Subscribe(topic string, lastEventID uint64) *Subscription {
+	sub, _ := h.subscribe(topic, lastEventID)
+	return sub
+}
+
+// subscribe is Subscribe with optional initial events queued ahead of any
+// replayed or live events, used to hand new subscribers their current state.
+// It reports false if topic is closed, in which case the subscription only
+// yields the initial events.
+func (h *Hub) subscribe(topic string, lastEventID uint64, initial ...Event) (*Subscription, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.closed[topic]; ok {
+		return closedSubscription(initial...), false
+	}
+	t := h.topicLocked(topic)
+	ch := make(chan Event, subscriberBufferSize+len(initial))
+	sub := &Subscription{C: ch, ch: ch, hub: h, topic: topic}
+	for _, ev := range initial {
+		ch <- ev
+	}
+
+	if lastEventID > 0 && lastEventID < t.nextID {
+		oldest := t.nextID
+		if len(t.history) > 0 {
+			oldest = t.history[0].ID
+		}
+		// Every event after lastEventID is still retained. Otherwise the
+		// caller starts over from a snapshot: replaying what is left would
+		// roll the client back to scores older than the snapshot.
+		sub.Resumed = lastEventID+1 >= oldest
+		for _, ev := range t.history {
+			if sub.Resumed && ev.ID > lastEventID {
+				ch <- ev
+			}
+		}
+	}
+	t.subs[sub] = struct{}{}
+	return sub, true
+}
+
+// Publish assigns the next event ID on topic, records the event in the
+// topic's history and delivers it to every subscriber. Events published on
+// a closed topic are dropped and returned with ID 0.
+func (h *Hub) Publish(topic, eventType string, data interface{}) Event {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.closed[topic]; ok {
+		return Event{Type: eventType, Data: data}
+	}
+	t := h.topicLocked(topic)
+	ev := Event{ID: t.nextID, Type: eventType, Data: data}
+	t.nextID++
+	t.sequenced = true
+	t.history = append(t.history, ev)
+	if len(t.history) > topicHistorySize {
+		t.history = t.history[len(t.history)-topicHistorySize:]
+	}
+	h.deliverLocked(t, ev)
+	return ev
+}
+
+// skipIfIdle reports whether topic has no subscribers, in which case the
+// caller may skip building an event for it. The skipped event still counts:
+// the topic's history is dropped and an event ID used up, so that a client
+// resuming from before it gets a snapshot instead of a replay with a gap.
+func (h *Hub) skipIfIdle(topic string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	t, ok := h.topics[topic]
+	if !ok {
+		return true
+	}
+	if len(t.subs) > 0 {
+		return false
+	}
+	t.history = nil
+	t.nextID++
+	t.sequenced = true
+	return true
+}
+
+// Notify delivers an event to the current subscribers of topic without
+// retaining it for resume. Topics without subscribers are left untouched, so
+// per-player topics do not accumulate for players who are not connected.
+func (h *Hub) Notify(topic, eventType string, data interface{}) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	t, ok := h.topics[topic]
+	if !ok {
+		return
+	}
+	ev := Event{ID: t.nextID, Type: eventType, Data: data}
+	t.nextID++
+	h.deliverLocked(t, ev)
+}
+
+func (h *Hub) deliverLocked(t *hubTopic, ev Event) {
+	for sub := range t.subs {
+		select {
+		case sub.ch <- ev:
+		default:
+			// Slow consumer: drop it rather than block the publisher.
+			delete(t.subs, sub)
+			sub.closeLocked()
+		}
+	}
+}
+
+// CloseTopic ends every subscription on topic and forgets its history.
+// Events already buffered are still delivered before the channel closes.
+// The topic stays closed for closedTopicRetention.
+func (h *Hub) CloseTopic(topic string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	now := time.Now()
+	for name, at := range h.closed {
+		if now.Sub(at) > closedTopicRetention {
+			delete(h.closed, name)
+		}
+	}
+	h.closed[topic] = now
+	t, ok := h.topics[topic]
+	if !ok {
+		return
+	}
+	for sub := range t.subs {
+		sub.closeLocked()
+	}
+	delete(h.topics, topic)
+}
+
+// Close ends every subscription on every topic.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for name, t := range h.topics {
+		for sub := range t.subs {
+			sub.closeLocked()
+		}
+		delete(h.topics, name)
+	}
+}
+
+// closedSubscription returns a subscription that yields the given events and
+// then reports end of stream. It is used for topics that have already ended.
+func closedSubscription(events ...Event) *Subscription {
+	ch := make(chan Event, len(events))
+	for _, ev := range events {
+		ch <- ev
+	}
+	close(ch)
+	return &Subscription{C: ch, ch: ch, closed: true}
+}
+
+func competitionTopic(competitionID string) string {
+	return "competition:" + competitionID
+}
+
+func playerTopic(playerID string) string {
+	return "player:" + playerID
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"testing"
+)
+
+func TestHub_PublishDeliversToSubscribers(t *testing.T) {
+	hub := NewHub()
+	sub := hub.Subscribe("t", 0)
+	defer sub.Close()
+
+	hub.Publish("t", EventScore, "a")
+	ev := <-sub.C
+	if ev.ID != 1 || ev.Type != EventScore || ev.Data != "a" {
+		t.Errorf("unexpected event: %+v", ev)
+	}
+}
+
+func TestHub_ResumeReplaysRetainedEvents(t *testing.T) {
+	hub := NewHub()
+	for i := 0; i < 3; i++ {
+		hub.Publish("t", EventScore, i)
+	}
+	sub := hub.Subscribe("t", 1)
+	defer sub.Close()
+	if !sub.Resumed {
+		t.Errorf("expected subscription to be resumed")
+	}
+	for _, want := range []uint64{2, 3} {
+		if ev := <-sub.C; ev.ID != want {
+			t.Errorf("expected event %d, got %d", want, ev.ID)
+		}
+	}
+}
+
+func TestHub_ResumeGapNotResumed(t *testing.T) {
+	hub := NewHub()
+	for i := 0; i < topicHistorySize+5; i++ {
+		hub.Publish("t", EventScore, i)
+	}
+	sub := hub.Subscribe("t", 1)
+	defer sub.Close()
+	if sub.Resumed {
+		t.Errorf("expected gap to be reported when history is exhausted")
+	}
+	if n := len(sub.C); n != 0 {
+		t.Errorf("expected no partial replay, got %d events", n)
+	}
+
+	stale := hub.Subscribe("other", 42)
+	defer stale.Close()
+	if stale.Resumed {
+		t.Errorf("expected unknown event ID not to resume")
+	}
+}
+
+func TestHub_SkippedEventBreaksResume(t *testing.T) {
+	hub := NewHub()
+	sub := hub.Subscribe("t", 0)
+	hub.Publish("t", EventScore, 1)
+	sub.Close()
+	if !hub.skipIfIdle("t") {
+		t.Fatalf("expected a topic without subscribers to be idle")
+	}
+	resumed := hub.Subscribe("t", 1)
+	defer resumed.Close()
+	if resumed.Resumed {
+		t.Errorf("expected a resume across a skipped event to need a snapshot")
+	}
+	if hub.skipIfIdle("t") {
+		t.Errorf("expected a topic with subscribers not to be idle")
+	}
+}
+
+func TestHub_SlowSubscriberDropped(t *testing.T) {
+	hub := NewHub()
+	slow := hub.Subscribe("t", 0)
+	for i := 0; i < subscriberBufferSize+1; i++ {
+		hub.Publish("t", EventScore, i)
+	}
+	n := 0
+	for range slow.C {
+		n++
+	}
+	if n != subscriberBufferSize {
+		t.Errorf("expected %d buffered events before drop, got %d", subscriberBufferSize, n)
+	}
+	slow.Close()
+}
+
+func TestHub_CloseTopicEndsSubscriptions(t *testing.T) {
+	hub := NewHub()
+	sub := hub.Subscribe("t", 0)
+	hub.Publish("t", EventCompleted, nil)
+	hub.CloseTopic("t")
+	if ev, ok := <-sub.C; !ok || ev.Type != EventCompleted {
+		t.Errorf("expected buffered completed event, got %+v (ok=%v)", ev, ok)
+	}
+	if _, ok := <-sub.C; ok {
+		t.Errorf("expected channel to be closed")
+	}
+	sub.Close()
+}
+
+func TestHub_ClosedTopicStaysClosed(t *testing.T) {
+	hub := NewHub()
+	hub.Publish("t", EventScore, nil)
+	hub.CloseTopic("t")
+	if ev := hub.Publish("t", EventScore, "late"); ev.ID != 0 {
+		t.Errorf("expected a late publish to be dropped, got %+v", ev)
+	}
+	if len(hub.topics) != 0 {
+		t.Errorf("expected a late publish not to re-create the topic")
+	}
+	sub := hub.Subscribe("t", 0)
+	if _, ok := <-sub.C; ok {
+		t.Errorf("expected a subscription to a closed topic to be closed")
+	}
+	sub.Close()
+}
+
+func TestHub_NotifyOnlyReachesLiveSubscribers(t *testing.T) {
+	hub := NewHub()
+	hub.Notify("p", EventMatched, nil)
+	if len(hub.topics) != 0 {
+		t.Errorf("expected notify without subscribers not to create a topic")
+	}
+	sub := hub.Subscribe("p", 0)
+	hub.Notify("p", EventMatched, "x")
+	if ev := <-sub.C; ev.Data != "x" {
+		t.Errorf("unexpected event: %+v", ev)
+	}
+	sub.Close()
+	if len(hub.topics) != 0 {
+		t.Errorf("expected topic to be removed after last subscriber left")
+	}
+}
+
+func TestHub_IdleTopicKeepsItsSequence(t *testing.T) {
+	hub := NewHub()
+	sub := hub.Subscribe("t", 0)
+	hub.Publish("t", EventScore, "a")
+	<-sub.C
+	sub.Close()
+	if !hub.skipIfIdle("t") {
+		t.Fatal("expected topic without subscribers to be idle")
+	}
+	resumed := hub.Subscribe("t", 1)
+	defer resumed.Close()
+	if resumed.Resumed {
+		t.Errorf("expected resume across a skipped event to need a snapshot")
+	}
+	if ev := hub.Publish("t", EventScore, "b"); ev.ID != 3 {
+		t.Errorf("expected event IDs to carry on at 3, got %d", ev.ID)
+	}
+}
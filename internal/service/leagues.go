@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"leaderboard-service/internal/logging"
+	"leaderboard-service/internal/model"
+)
+
+// MaxLeagueHistory caps how many tier movements GetLeagueHistory returns.
+const MaxLeagueHistory = 100
+
+// leagueMovements works out who a completed competition promotes and
+// relegates: the top promote percent of its leaderboard move up a tier and
+// the bottom relegate percent move down, rounding down. Nobody moves above
+// the highest tier or below the lowest.
+func leagueMovements(comp *model.Competition, leaderboard []model.PlayerCompetition, promote, relegate int) []model.TierMovement {
+	n := len(leaderboard)
+	up, down := n*promote/100, n*relegate/100
+	var moves []model.TierMovement
+	for i, pc := range leaderboard {
+		var to model.Tier
+		switch {
+		case i < up:
+			to = comp.Tier.Above()
+		case i >= n-down:
+			to = comp.Tier.Below()
+		}
+		if to == "" {
+			continue
+		}
+		moves = append(moves, model.TierMovement{
+			PlayerID:      pc.PlayerID,
+			CompetitionID: comp.CompetitionID,
+			FromTier:      comp.Tier,
+			ToTier:        to,
+			Rank:          i + 1,
+		})
+	}
+	return moves
+}
+
+// visibleRanks sets the rank of each of moves to the player's place on the
+// leaderboard as they see it: among the players on the public leaderboard
+// and themselves.
+func visibleRanks(moves []model.TierMovement, leaderboard, public []model.PlayerCompetition) {
+	shown := make(map[string]bool, len(public))
+	for _, pc := range public {
+		shown[pc.PlayerID] = true
+	}
+	ahead := make(map[string]int, len(leaderboard))
+	n := 0
+	for _, pc := range leaderboard {
+		ahead[pc.PlayerID] = n
+		if shown[pc.PlayerID] {
+			n++
+		}
+	}
+	for i := range moves {
+		moves[i].Rank = ahead[moves[i].PlayerID] + 1
+	}
+}
+
+// settleLeagues promotes and relegates the players of every completed
+// league competition not yet settled, so that one whose settlement failed
+// or was cut short is retried. It returns the first error, having tried
+// every competition.
+func (s *Service) settleLeagues(ctx context.Context) error {
+	config := s.currentConfig()
+	comps, err := s.repo.UnsettledLeagueCompetitions(ctx)
+	if err != nil {
+		workerLogger(ctx).Error("error fetching unsettled league competitions", "error", err)
+		return err
+	}
+	var passErr error
+	for i := range comps {
+		comp := &comps[i]
+		ctx := logging.With(ctx, "competition_id", comp.CompetitionID)
+		if err := s.applyLeagueMovements(ctx, comp, config); err != nil {
+			workerLogger(ctx).Error("error settling league competition", "error", err)
+			if passErr == nil {
+				passErr = err
+			}
+		}
+	}
+	return passErr
+}
+
+// applyLeagueMovements settles comp, recording it as settled even when
+// nobody moves.
+func (s *Service) applyLeagueMovements(ctx context.Context, comp *model.Competition, config Config) error {
+	if comp.Tier == "" {
+		return nil
+	}
+	var moves []model.TierMovement
+	if config.PromotePercent > 0 || config.RelegatePercent > 0 {
+		// Shadow-banned players play on as usual, so they move like everyone
+		// else; only the ranks kept in league history leave them out.
+		leaderboard, err := s.repo.GetLeaderboardByCompetitionID(ctx, comp.CompetitionID.String(), model.Viewer{All: true})
+		if err != nil {
+			return err
+		}
+		moves = leagueMovements(comp, leaderboard, config.PromotePercent, config.RelegatePercent)
+		if len(moves) > 0 {
+			public, err := s.repo.GetLeaderboardByCompetitionID(ctx, comp.CompetitionID.String(), model.Viewer{})
+			if err != nil {
+				return err
+			}
+			visibleRanks(moves, leaderboard, public)
+		}
+	}
+	applied, err := s.repo.ApplyTierMovements(ctx, comp.CompetitionID, moves)
+	if err != nil {
+		return err
+	}
+	workerLogger(ctx).Info("applied league movements", "tier", comp.Tier, "moved", len(applied))
+	return nil
+}
+
+// GetLeagueHistory returns a player's most recent tier movements, newest
+// first.
+func (s *Service) GetLeagueHistory(ctx context.Context, playerID string) ([]model.TierMovement, error) {
+	if _, err := s.repo.GetPlayerByID(ctx, playerID); err != nil {
+		logger(ctx).Info("player not found", "player_id", playerID)
+		return nil, errors.New("player not found")
+	}
+	moves, err := s.repo.ListTierHistory(ctx, playerID, MaxLeagueHistory)
+	if err != nil {
+		logger(ctx).Error("error fetching league history", "player_id", playerID, "error", err)
+		return nil, err
+	}
+	return moves, nil
+}
@@ -0,0 +1,248 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"leaderboard-service/internal/model"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func leaderboardOf(n int) []model.PlayerCompetition {
+	pcs := make([]model.PlayerCompetition, n)
+	for i := range pcs {
+		pcs[i] = model.PlayerCompetition{PlayerID: fmt.Sprintf("p%d", i+1), Score: 100 - i}
+	}
+	return pcs
+}
+
+func TestLeagueMovements(t *testing.T) {
+	cases := []struct {
+		tier              model.Tier
+		players           int
+		promote, relegate int
+		want              map[string]model.Tier
+	}{
+		{model.TierSilver, 10, 20, 30, map[string]model.Tier{
+			"p1": model.TierGold, "p2": model.TierGold, "p8": model.TierBronze, "p9": model.TierBronze, "p10": model.TierBronze}},
+		// Nobody is promoted out of the top tier or relegated out of the bottom one.
+		{model.TierGold, 5, 20, 20, map[string]model.Tier{"p5": model.TierSilver}},
+		{model.TierBronze, 5, 20, 20, map[string]model.Tier{"p1": model.TierSilver}},
+		// Shares round down.
+		{model.TierSilver, 4, 20, 20, map[string]model.Tier{}},
+	}
+	for _, tc := range cases {
+		comp := &model.Competition{CompetitionID: uuid.New(), Tier: tc.tier}
+		moves := leagueMovements(comp, leaderboardOf(tc.players), tc.promote, tc.relegate)
+		if len(moves) != len(tc.want) {
+			t.Errorf("%s/%d: expected %d moves, got %+v", tc.tier, tc.players, len(tc.want), moves)
+			continue
+		}
+		for _, m := range moves {
+			if m.FromTier != tc.tier || m.ToTier != tc.want[m.PlayerID] || m.CompetitionID != comp.CompetitionID || m.PlayerID != fmt.Sprintf("p%d", m.Rank) {
+				t.Errorf("%s/%d: unexpected move %+v", tc.tier, tc.players, m)
+			}
+		}
+	}
+}
+
+func TestService_RunMatchmaking_GroupsByTier(t *testing.T) {
+	var created *model.Competition
+	var matched []string
+	repo := &mockRepo{
+		GetWaitingPlayersFunc: func(ctx context.Context, limit int) ([]model.PlayerCompetition, error) {
+			return []model.PlayerCompetition{
+				{PlayerID: "p1", Level: 1, Tier: model.TierSilver},
+				{PlayerID: "p2", Level: 1, Tier: model.TierBronze},
+				{PlayerID: "p3", Level: 2, Tier: model.TierGold},
+				{PlayerID: "p4", Level: 3, Tier: model.TierGold},
+			}, nil
+		},
+		CreateCompetitionFunc: func(ctx context.Context, comp *model.Competition) error {
+			created = comp
+			return nil
+		},
+		UpdatePlayerCompetitionsToActiveFunc: func(ctx context.Context, playerIDs []string, competitionID uuid.UUID, endsAt time.Time) error {
+			matched = playerIDs
+			return nil
+		},
+	}
+	svc := NewService(repo, validConfig())
+
+	if err := svc.runMatchmaking(context.Background()); err != nil {
+		t.Fatalf("runMatchmaking failed: %v", err)
+	}
+	if created == nil || created.Tier != model.TierGold {
+		t.Fatalf("expected a GOLD competition, got %+v", created)
+	}
+	if len(matched) != 2 || matched[0] != "p3" || matched[1] != "p4" {
+		t.Errorf("expected only the GOLD players to be matched, got %v", matched)
+	}
+}
+
+func TestService_RunMatchmaking_WaitsForTierToFill(t *testing.T) {
+	repo := &mockRepo{
+		GetWaitingPlayersFunc: func(ctx context.Context, limit int) ([]model.PlayerCompetition, error) {
+			return []model.PlayerCompetition{{PlayerID: "p1", Tier: model.TierSilver}, {PlayerID: "p2", Tier: model.TierBronze}}, nil
+		},
+		CreateCompetitionFunc: func(ctx context.Context, comp *model.Competition) error {
+			t.Error("expected no competition across tiers")
+			return nil
+		},
+	}
+	if err := NewService(repo, validConfig()).runMatchmaking(context.Background()); err != nil {
+		t.Fatalf("runMatchmaking failed: %v", err)
+	}
+}
+
+func TestService_RunMatchmaking_SettlesLeagues(t *testing.T) {
+	league, retried := uuid.New(), uuid.New()
+	applied := make(map[uuid.UUID][]model.TierMovement)
+	repo := &mockRepo{
+		// retried completed in an earlier pass that failed to settle it.
+		CompleteFinishedCompetitionsFunc: func(ctx context.Context) ([]uuid.UUID, error) {
+			return []uuid.UUID{league}, nil
+		},
+		UnsettledLeagueCompetitionsFunc: func(ctx context.Context) ([]model.Competition, error) {
+			return []model.Competition{
+				{CompetitionID: retried, Kind: model.CompetitionKindMatchmaking, Tier: model.TierGold, Status: model.CompetitionCompleted},
+				{CompetitionID: league, Kind: model.CompetitionKindMatchmaking, Tier: model.TierSilver, Status: model.CompetitionCompleted},
+			}, nil
+		},
+		GetLeaderboardByCompetitionIDFunc: func(ctx context.Context, competitionID string, viewer model.Viewer) ([]model.PlayerCompetition, error) {
+			return leaderboardOf(5), nil
+		},
+		ApplyTierMovementsFunc: func(ctx context.Context, competitionID uuid.UUID, moves []model.TierMovement) ([]model.TierMovement, error) {
+			applied[competitionID] = moves
+			return moves, nil
+		},
+		GetActiveCompetitionFunc: func(ctx context.Context) (*model.Competition, error) {
+			return &model.Competition{}, nil
+		},
+	}
+	config := validConfig()
+	config.PromotePercent, config.RelegatePercent = 20, 40
+	svc := NewService(repo, config)
+
+	if err := svc.runMatchmaking(context.Background()); err != nil {
+		t.Fatalf("runMatchmaking failed: %v", err)
+	}
+	want := []model.TierMovement{
+		{PlayerID: "p1", CompetitionID: league, FromTier: model.TierSilver, ToTier: model.TierGold, Rank: 1},
+		{PlayerID: "p4", CompetitionID: league, FromTier: model.TierSilver, ToTier: model.TierBronze, Rank: 4},
+		{PlayerID: "p5", CompetitionID: league, FromTier: model.TierSilver, ToTier: model.TierBronze, Rank: 5},
+	}
+	if fmt.Sprint(applied[league]) != fmt.Sprint(want) {
+		t.Errorf("expected moves %+v, got %+v", want, applied[league])
+	}
+	if moves, ok := applied[retried]; !ok || len(moves) != 2 {
+		t.Errorf("expected the competition left unsettled to be settled, got %+v", moves)
+	}
+}
+
+func TestService_SettleLeagues_RecordsCompetitionsWithoutMoves(t *testing.T) {
+	comp := model.Competition{CompetitionID: uuid.New(), Kind: model.CompetitionKindMatchmaking, Tier: model.TierSilver, Status: model.CompetitionCompleted}
+	settled := false
+	repo := &mockRepo{
+		UnsettledLeagueCompetitionsFunc: func(ctx context.Context) ([]model.Competition, error) {
+			return []model.Competition{comp}, nil
+		},
+		GetLeaderboardByCompetitionIDFunc: func(ctx context.Context, competitionID string, viewer model.Viewer) ([]model.PlayerCompetition, error) {
+			t.Error("expected no leaderboard read with promotion and relegation off")
+			return nil, nil
+		},
+		ApplyTierMovementsFunc: func(ctx context.Context, competitionID uuid.UUID, moves []model.TierMovement) ([]model.TierMovement, error) {
+			settled = competitionID == comp.CompetitionID && len(moves) == 0
+			return nil, nil
+		},
+	}
+	config := validConfig()
+	config.PromotePercent, config.RelegatePercent = 0, 0
+	if err := NewService(repo, config).settleLeagues(context.Background()); err != nil {
+		t.Fatalf("settleLeagues failed: %v", err)
+	}
+	if !settled {
+		t.Error("expected the competition to be recorded as settled")
+	}
+}
+
+func TestService_RunMatchmaking_SettleFailureIsReported(t *testing.T) {
+	repo := &mockRepo{
+		UnsettledLeagueCompetitionsFunc: func(ctx context.Context) ([]model.Competition, error) {
+			return []model.Competition{{CompetitionID: uuid.New(), Tier: model.TierSilver, Status: model.CompetitionCompleted}}, nil
+		},
+		GetLeaderboardByCompetitionIDFunc: func(ctx context.Context, competitionID string, viewer model.Viewer) ([]model.PlayerCompetition, error) {
+			return nil, errors.New("db error")
+		},
+		GetActiveCompetitionFunc: func(ctx context.Context) (*model.Competition, error) {
+			return &model.Competition{}, nil
+		},
+	}
+	config := validConfig()
+	config.PromotePercent = 10
+	if err := NewService(repo, config).runMatchmaking(context.Background()); err == nil {
+		t.Error("expected the settle failure to be returned")
+	}
+}
+
+func TestService_GetLeagueHistory(t *testing.T) {
+	var gotLimit int
+	repo := &mockRepo{
+		GetPlayerByIDFunc: func(ctx context.Context, playerID string) (*model.Player, error) {
+			if playerID != "p1" {
+				return nil, errors.New("not found")
+			}
+			return &model.Player{PlayerID: playerID}, nil
+		},
+		ListTierHistoryFunc: func(ctx context.Context, playerID string, limit int) ([]model.TierMovement, error) {
+			gotLimit = limit
+			return []model.TierMovement{{PlayerID: playerID, FromTier: model.TierBronze, ToTier: model.TierSilver}}, nil
+		},
+	}
+	svc := NewService(repo, validConfig())
+
+	moves, err := svc.GetLeagueHistory(context.Background(), "p1")
+	if err != nil || len(moves) != 1 || gotLimit != MaxLeagueHistory {
+		t.Errorf("unexpected history: %+v, %v (limit %d)", moves, err, gotLimit)
+	}
+	if _, err := svc.GetLeagueHistory(context.Background(), "ghost"); err == nil || err.Error() != "player not found" {
+		t.Errorf("expected player not found, got %v", err)
+	}
+}
+
+func TestService_ApplyLeagueMovements_ShadowBanned(t *testing.T) {
+	comp := &model.Competition{CompetitionID: uuid.New(), Kind: model.CompetitionKindMatchmaking, Tier: model.TierSilver}
+	var applied []model.TierMovement
+	repo := &mockRepo{
+		// p1 is shadow-banned.
+		GetLeaderboardByCompetitionIDFunc: func(ctx context.Context, competitionID string, viewer model.Viewer) ([]model.PlayerCompetition, error) {
+			if viewer.All {
+				return leaderboardOf(5), nil
+			}
+			return leaderboardOf(5)[1:], nil
+		},
+		ApplyTierMovementsFunc: func(ctx context.Context, competitionID uuid.UUID, moves []model.TierMovement) ([]model.TierMovement, error) {
+			applied = moves
+			return moves, nil
+		},
+	}
+	config := validConfig()
+	config.PromotePercent, config.RelegatePercent = 20, 40
+
+	if err := NewService(repo, config).applyLeagueMovements(context.Background(), comp, config); err != nil {
+		t.Fatalf("applyLeagueMovements failed: %v", err)
+	}
+	// p1 still takes the promotion place; the others' ranks are those
+	// they saw.
+	want := []model.TierMovement{
+		{PlayerID: "p1", CompetitionID: comp.CompetitionID, FromTier: model.TierSilver, ToTier: model.TierGold, Rank: 1},
+		{PlayerID: "p4", CompetitionID: comp.CompetitionID, FromTier: model.TierSilver, ToTier: model.TierBronze, Rank: 3},
+		{PlayerID: "p5", CompetitionID: comp.CompetitionID, FromTier: model.TierSilver, ToTier: model.TierBronze, Rank: 4},
+	}
+	if fmt.Sprint(applied) != fmt.Sprint(want) {
+		t.Errorf("expected moves %+v, got %+v", want, applied)
+	}
+}
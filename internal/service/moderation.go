@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"leaderboard-service/internal/model"
+	"time"
+)
+
+// Audit actions recorded by the player moderation operations.
+const (
+	AuditActionPlayerBan           = "player.ban"
+	AuditActionPlayerUnban         = "player.unban"
+	AuditActionPlayerSuspend       = "player.suspend"
+	AuditActionPlayerUnsuspend     = "player.unsuspend"
+	AuditActionPlayerShadowBan     = "player.shadow_ban"
+	AuditActionPlayerLiftShadowBan = "player.lift_shadow_ban"
+)
+
+// sanctionActions are the audit actions that apply and lift each sanction.
+var sanctionActions = map[model.Sanction][2]string{
+	model.SanctionBan:        {AuditActionPlayerBan, AuditActionPlayerUnban},
+	model.SanctionSuspension: {AuditActionPlayerSuspend, AuditActionPlayerUnsuspend},
+	model.SanctionShadowBan:  {AuditActionPlayerShadowBan, AuditActionPlayerLiftShadowBan},
+}
+
+func playerTarget(playerID string) string {
+	return "player/" + playerID
+}
+
+// checkSanctions returns an error if a ban or suspension stops playerID
+// joining competitions or submitting scores. Shadow-banned players play on.
+func (s *Service) checkSanctions(ctx context.Context, playerID string) error {
+	m, err := s.repo.GetPlayerModeration(ctx, playerID)
+	if err != nil {
+		logger(ctx).Error("error fetching player sanctions", "player_id", playerID, "error", err)
+		return err
+	}
+	if m.BannedAt != nil {
+		logger(ctx).Info("player banned", "player_id", playerID)
+		return errors.New("player banned")
+	}
+	if m.Suspended(time.Now()) {
+		logger(ctx).Info("player suspended", "player_id", playerID, "suspended_until", *m.SuspendedUntil)
+		return errors.New("player suspended")
+	}
+	return nil
+}
+
+// GetModeration returns the sanctions on a player. An expired suspension
+// is left out.
+func (s *Service) GetModeration(ctx context.Context, playerID string) (*model.Moderation, error) {
+	m, err := s.repo.GetPlayerModeration(ctx, playerID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: player not found", ErrNotFound)
+	}
+	if err != nil {
+		logger(ctx).Error("error fetching player sanctions", "player_id", playerID, "error", err)
+		return nil, err
+	}
+	if !m.Suspended(time.Now()) {
+		m.SuspendedUntil = nil
+	}
+	return m, nil
+}
+
+// ApplySanction puts sanction on a player. A suspension lasts until until,
+// which is ignored for the other sanctions; suspending a suspended player
+// moves the end of their suspension. A ban or suspension also takes the
+// player out of the matchmaking queue, with their party, and cancels their
+// registrations; entries in running competitions stay, but take no more
+// scores.
+func (s *Service) ApplySanction(ctx context.Context, playerID string, sanction model.Sanction, until time.Time, reason string) (*model.Moderation, error) {
+	actor, err := requireActor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	before, err := s.GetModeration(ctx, playerID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	value := now
+	after := *before
+	switch sanction {
+	case model.SanctionBan:
+		if before.BannedAt != nil {
+			return nil, fmt.Errorf("%w: player already banned", ErrConflict)
+		}
+		after.BannedAt = &value
+	case model.SanctionSuspension:
+		if !until.After(now) {
+			return nil, fmt.Errorf("%w: until must be in the future", ErrInvalidArgument)
+		}
+		value = until
+		after.SuspendedUntil = &value
+	case model.SanctionShadowBan:
+		if before.ShadowBannedAt != nil {
+			return nil, fmt.Errorf("%w: player already shadow-banned", ErrConflict)
+		}
+		after.ShadowBannedAt = &value
+	default:
+		return nil, fmt.Errorf("%w: sanction must be BAN, SUSPENSION or SHADOW_BAN", ErrInvalidArgument)
+	}
+
+	entry := newAuditEntry(actor, sanctionActions[sanction][0], playerTarget(playerID), reason, before, &after)
+	ok, err := s.repo.SetPlayerSanction(ctx, playerID, sanction, &value, entry)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: player not found", ErrNotFound)
+	}
+	logger(ctx).Info("player sanctioned", "player_id", playerID, "sanction", sanction, "actor", actor, "audit_id", entry.ID)
+	return &after, nil
+}
+
+// LiftSanction lifts sanction from a player.
+func (s *Service) LiftSanction(ctx context.Context, playerID string, sanction model.Sanction, reason string) (*model.Moderation, error) {
+	actor, err := requireActor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	before, err := s.GetModeration(ctx, playerID)
+	if err != nil {
+		return nil, err
+	}
+	after := *before
+	switch sanction {
+	case model.SanctionBan:
+		if before.BannedAt == nil {
+			return nil, fmt.Errorf("%w: player not banned", ErrConflict)
+		}
+		after.BannedAt = nil
+	case model.SanctionSuspension:
+		if before.SuspendedUntil == nil {
+			return nil, fmt.Errorf("%w: player not suspended", ErrConflict)
+		}
+		after.SuspendedUntil = nil
+	case model.SanctionShadowBan:
+		if before.ShadowBannedAt == nil {
+			return nil, fmt.Errorf("%w: player not shadow-banned", ErrConflict)
+		}
+		after.ShadowBannedAt = nil
+	default:
+		return nil, fmt.Errorf("%w: sanction must be BAN, SUSPENSION or SHADOW_BAN", ErrInvalidArgument)
+	}
+
+	entry := newAuditEntry(actor, sanctionActions[sanction][1], playerTarget(playerID), reason, before, &after)
+	ok, err := s.repo.SetPlayerSanction(ctx, playerID, sanction, nil, entry)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: player not found", ErrNotFound)
+	}
+	logger(ctx).Info("player sanction lifted", "player_id", playerID, "sanction", sanction, "actor", actor, "audit_id", entry.ID)
+	return &after, nil
+}
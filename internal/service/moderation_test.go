@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"leaderboard-service/internal/auth"
+	"leaderboard-service/internal/model"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestService_ApplySanction(t *testing.T) {
+	moderation := &model.Moderation{PlayerID: "p1"}
+	var value *time.Time
+	var audit *model.AuditEntry
+	repo := &mockRepo{
+		GetPlayerModerationFunc: func(ctx context.Context, playerID string) (*model.Moderation, error) {
+			if playerID != "p1" {
+				return nil, sql.ErrNoRows
+			}
+			m := *moderation
+			return &m, nil
+		},
+		SetPlayerSanctionFunc: func(ctx context.Context, playerID string, sanction model.Sanction, v *time.Time, entry *model.AuditEntry) (bool, error) {
+			value, audit = v, entry
+			return true, nil
+		},
+	}
+	svc := NewService(repo, validConfig())
+	ctx := auth.WithActor(context.Background(), "alice")
+
+	m, err := svc.ApplySanction(ctx, "p1", model.SanctionBan, time.Time{}, "cheating")
+	if err != nil || m.BannedAt == nil || value == nil {
+		t.Fatalf("expected a ban, got %+v, %v", m, err)
+	}
+	if audit.Action != AuditActionPlayerBan || audit.Actor != "alice" || audit.Target != "player/p1" || audit.Reason != "cheating" {
+		t.Errorf("unexpected audit entry: %+v", audit)
+	}
+
+	until := time.Now().Add(time.Hour)
+	if m, err := svc.ApplySanction(ctx, "p1", model.SanctionSuspension, until, ""); err != nil || !m.SuspendedUntil.Equal(until) || !value.Equal(until) {
+		t.Errorf("expected a suspension until %s, got %+v, %v", until, m, err)
+	}
+	if _, err := svc.ApplySanction(ctx, "p1", model.SanctionSuspension, time.Now().Add(-time.Hour), ""); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("expected ErrInvalidArgument for a suspension in the past, got %v", err)
+	}
+	if _, err := svc.ApplySanction(ctx, "p1", "MUTE", time.Time{}, ""); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("expected ErrInvalidArgument for an unknown sanction, got %v", err)
+	}
+	if _, err := svc.ApplySanction(ctx, "ghost", model.SanctionBan, time.Time{}, ""); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for an unknown player, got %v", err)
+	}
+	if _, err := svc.ApplySanction(context.Background(), "p1", model.SanctionBan, time.Time{}, ""); err == nil {
+		t.Errorf("expected an error without an actor")
+	}
+
+	now := time.Now()
+	moderation.BannedAt = &now
+	if _, err := svc.ApplySanction(ctx, "p1", model.SanctionBan, time.Time{}, ""); !errors.Is(err, ErrConflict) {
+		t.Errorf("expected ErrConflict for a banned player, got %v", err)
+	}
+}
+
+func TestService_LiftSanction(t *testing.T) {
+	past := time.Now().Add(-time.Minute)
+	var value *time.Time
+	var audit *model.AuditEntry
+	repo := &mockRepo{
+		GetPlayerModerationFunc: func(ctx context.Context, playerID string) (*model.Moderation, error) {
+			return &model.Moderation{PlayerID: playerID, ShadowBannedAt: &past, SuspendedUntil: &past}, nil
+		},
+		SetPlayerSanctionFunc: func(ctx context.Context, playerID string, sanction model.Sanction, v *time.Time, entry *model.AuditEntry) (bool, error) {
+			value, audit = v, entry
+			return true, nil
+		},
+	}
+	svc := NewService(repo, validConfig())
+	ctx := auth.WithActor(context.Background(), "alice")
+
+	m, err := svc.LiftSanction(ctx, "p1", model.SanctionShadowBan, "appeal upheld")
+	if err != nil || m.ShadowBannedAt != nil || value != nil {
+		t.Fatalf("expected the shadow-ban lifted, got %+v, %v", m, err)
+	}
+	if audit.Action != AuditActionPlayerLiftShadowBan || audit.Reason != "appeal upheld" {
+		t.Errorf("unexpected audit entry: %+v", audit)
+	}
+	if _, err := svc.LiftSanction(ctx, "p1", model.SanctionBan, ""); !errors.Is(err, ErrConflict) {
+		t.Errorf("expected ErrConflict for a player not banned, got %v", err)
+	}
+	// An expired suspension is no longer in force.
+	if _, err := svc.LiftSanction(ctx, "p1", model.SanctionSuspension, ""); !errors.Is(err, ErrConflict) {
+		t.Errorf("expected ErrConflict for an expired suspension, got %v", err)
+	}
+}
+
+func TestService_SanctionsStopPlay(t *testing.T) {
+	now := time.Now()
+	soon, past := now.Add(time.Hour), now.Add(-time.Hour)
+	sanctions := map[string]*model.Moderation{
+		"banned":    {BannedAt: &now},
+		"suspended": {SuspendedUntil: &soon},
+		"served":    {SuspendedUntil: &past},
+		"shadow":    {ShadowBannedAt: &now},
+	}
+	compID := uuid.New()
+	var queued []string
+	repo := &mockRepo{
+		GetPlayerByIDFunc: func(ctx context.Context, playerID string) (*model.Player, error) {
+			return &model.Player{PlayerID: playerID, Level: 1}, nil
+		},
+		GetPlayerModerationFunc: func(ctx context.Context, playerID string) (*model.Moderation, error) {
+			return sanctions[playerID], nil
+		},
+		GetActivePlayerCompetitionFunc: func(ctx context.Context, playerID string) (*model.PlayerCompetition, error) {
+			return &model.PlayerCompetition{PlayerID: playerID, CompetitionID: &compID, Status: model.StatusActive}, nil
+		},
+		IsPlayerInWaitingQueueFunc: func(ctx context.Context, playerID string) (bool, error) {
+			return false, nil
+		},
+		CreatePlayerCompetitionFunc: func(ctx context.Context, pc *model.PlayerCompetition) error {
+			queued = append(queued, pc.PlayerID)
+			return nil
+		},
+	}
+	svc := NewService(repo, validConfig())
+	ctx := context.Background()
+
+	for playerID, want := range map[string]string{"banned": "player banned", "suspended": "player suspended"} {
+		if _, err := svc.Join(ctx, playerID); err == nil || err.Error() != want {
+			t.Errorf("Join(%s): expected %q, got %v", playerID, want, err)
+		}
+		if err := svc.SubmitScore(ctx, playerID, 10); err == nil || err.Error() != want {
+			t.Errorf("SubmitScore(%s): expected %q, got %v", playerID, want, err)
+		}
+	}
+	for _, playerID := range []string{"served", "shadow"} {
+		if err := svc.SubmitScore(ctx, playerID, 10); err != nil {
+			t.Errorf("SubmitScore(%s) failed: %v", playerID, err)
+		}
+	}
+	repo.GetActivePlayerCompetitionFunc = func(ctx context.Context, playerID string) (*model.PlayerCompetition, error) {
+		return nil, sql.ErrNoRows
+	}
+	for _, playerID := range []string{"served", "shadow"} {
+		if _, err := svc.Join(ctx, playerID); err != nil {
+			t.Errorf("Join(%s) failed: %v", playerID, err)
+		}
+	}
+	if len(queued) != 2 {
+		t.Errorf("expected two players queued, got %v", queued)
+	}
+}
+
+func TestService_LeaderboardViewers(t *testing.T) {
+	compID := uuid.New()
+	var viewers []model.Viewer
+	repo := &mockRepo{
+		GetLatestPlayerCompetitionFunc: func(ctx context.Context, playerID string) (*model.PlayerCompetition, error) {
+			return &model.PlayerCompetition{PlayerID: playerID, CompetitionID: &compID}, nil
+		},
+		GetLeaderboardByCompetitionIDFunc: func(ctx context.Context, competitionID string, viewer model.Viewer) ([]model.PlayerCompetition, error) {
+			viewers = append(viewers, viewer)
+			return []model.PlayerCompetition{{PlayerID: "p1", CompetitionID: &compID}}, nil
+		},
+	}
+	svc := NewService(repo, validConfig())
+	if _, err := svc.GetLeaderboard(context.Background(), compID.String()); err != nil {
+		t.Fatalf("GetLeaderboard failed: %v", err)
+	}
+	if _, err := svc.GetPlayerLeaderboard(context.Background(), "p1"); err != nil {
+		t.Fatalf("GetPlayerLeaderboard failed: %v", err)
+	}
+	if len(viewers) != 2 || viewers[0] != (model.Viewer{}) || viewers[1] != (model.Viewer{PlayerID: "p1"}) {
+		t.Errorf("expected an anonymous viewer, then the player, got %+v", viewers)
+	}
+}
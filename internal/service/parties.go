@@ -0,0 +1,284 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"leaderboard-service/internal/model"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxPartySize caps the members of a party. A party must also fit in a
+// competition, so it cannot queue while it has more members than
+// MaxGroupSize.
+const MaxPartySize = 5
+
+// EventPartyInvite tells a player that they have been invited to a party.
+const EventPartyInvite = "party_invite"
+
+// queueUnit is a solo player or a whole party waiting for matchmaking.
+// Matchmaking places units, so a party is never split.
+type queueUnit struct {
+	entries     []model.PlayerCompetition
+	level       int
+	countryCode string
+	tier        model.Tier
+}
+
+// queueUnits groups waiting entries into units, in waiting order. A party
+// plays in the highest tier among its members and at their highest or
+// average level, by mode; it takes its first member's country.
+func queueUnits(waiting []model.PlayerCompetition, mode model.PartyLevel) []queueUnit {
+	var units []queueUnit
+	parties := make(map[uuid.UUID]int)
+	for _, pc := range waiting {
+		if pc.PartyID != nil {
+			if i, ok := parties[*pc.PartyID]; ok {
+				units[i].entries = append(units[i].entries, pc)
+				continue
+			}
+			parties[*pc.PartyID] = len(units)
+		}
+		units = append(units, queueUnit{entries: []model.PlayerCompetition{pc}})
+	}
+	for i := range units {
+		u := &units[i]
+		u.countryCode = u.entries[0].CountryCode
+		u.tier = u.entries[0].Tier
+		sum := 0
+		for _, pc := range u.entries {
+			sum += pc.Level
+			u.level = max(u.level, pc.Level)
+			if tierRank(pc.Tier) > tierRank(u.tier) {
+				u.tier = pc.Tier
+			}
+		}
+		if mode == model.PartyLevelAverage {
+			n := len(u.entries)
+			u.level = (sum + n/2) / n
+		}
+	}
+	return units
+}
+
+// tierRank orders tiers from lowest to highest; unknown tiers rank lowest.
+func tierRank(t model.Tier) int {
+	return slices.Index(model.Tiers, t)
+}
+
+// playerCount returns the number of players in units.
+func playerCount(units []queueUnit) int {
+	n := 0
+	for _, u := range units {
+		n += len(u.entries)
+	}
+	return n
+}
+
+// fitUnits takes units in order while they fit in maxSize players, skipping
+// those too large for the room left. A maxSize of 0 means no limit.
+func fitUnits(units []queueUnit, maxSize int) []queueUnit {
+	if maxSize <= 0 {
+		return units
+	}
+	var fitted []queueUnit
+	room := maxSize
+	for _, u := range units {
+		if len(u.entries) <= room {
+			fitted = append(fitted, u)
+			room -= len(u.entries)
+		}
+	}
+	return fitted
+}
+
+// findParty returns a party by ID, or ErrNotFound.
+func (s *Service) findParty(ctx context.Context, partyID string) (*model.Party, error) {
+	id, err := uuid.Parse(partyID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: party not found", ErrNotFound)
+	}
+	party, err := s.repo.GetParty(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: party not found", ErrNotFound)
+	}
+	if err != nil {
+		logger(ctx).Error("error fetching party", "party_id", partyID, "error", err)
+		return nil, err
+	}
+	return party, nil
+}
+
+// findPlayer returns a player by ID, or ErrNotFound.
+func (s *Service) findPlayer(ctx context.Context, playerID string) (*model.Player, error) {
+	player, err := s.repo.GetPlayerByID(ctx, playerID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: player not found", ErrNotFound)
+	}
+	if err != nil {
+		logger(ctx).Error("error fetching player", "player_id", playerID, "error", err)
+		return nil, err
+	}
+	return player, nil
+}
+
+// CreateParty creates a party led by playerID, its only member until
+// others accept an invite.
+func (s *Service) CreateParty(ctx context.Context, playerID string) (*model.Party, error) {
+	if _, err := s.findPlayer(ctx, playerID); err != nil {
+		return nil, err
+	}
+	party := &model.Party{PartyID: uuid.New(), LeaderID: playerID, Members: []string{playerID}, Invites: []string{}}
+	created, err := s.repo.CreateParty(ctx, party)
+	if err != nil {
+		return nil, err
+	}
+	if !created {
+		return nil, fmt.Errorf("%w: player already in a party", ErrConflict)
+	}
+	logger(ctx).Info("party created", "party_id", party.PartyID, "player_id", playerID)
+	return party, nil
+}
+
+// GetParty returns a party with its members and pending invites.
+func (s *Service) GetParty(ctx context.Context, partyID string) (*model.Party, error) {
+	return s.findParty(ctx, partyID)
+}
+
+// InviteToParty lets the leader of a party invite inviteeID, who is told
+// over their player stream.
+func (s *Service) InviteToParty(ctx context.Context, partyID, playerID, inviteeID string) error {
+	party, err := s.findParty(ctx, partyID)
+	if err != nil {
+		return err
+	}
+	if party.LeaderID != playerID {
+		return fmt.Errorf("%w: only the party leader can invite players", ErrForbidden)
+	}
+	if _, err := s.findPlayer(ctx, inviteeID); err != nil {
+		return err
+	}
+	switch {
+	case slices.Contains(party.Members, inviteeID):
+		return fmt.Errorf("%w: player already in the party", ErrConflict)
+	case len(party.Members) >= MaxPartySize:
+		return fmt.Errorf("%w: party is full", ErrConflict)
+	}
+	invited, err := s.repo.InvitePartyPlayer(ctx, party.PartyID, inviteeID)
+	if err != nil {
+		return err
+	}
+	if !invited {
+		return fmt.Errorf("%w: player already invited", ErrConflict)
+	}
+	s.hub.Notify(playerTopic(inviteeID), EventPartyInvite, map[string]interface{}{
+		"party_id":  party.PartyID.String(),
+		"leader_id": party.LeaderID,
+		"members":   party.Members,
+	})
+	logger(ctx).Info("player invited to party", "party_id", party.PartyID, "player_id", inviteeID)
+	return nil
+}
+
+// AcceptPartyInvite makes an invited player a member of the party. A player
+// is in at most one party, and a queued party takes no new members.
+func (s *Service) AcceptPartyInvite(ctx context.Context, partyID, playerID string) (*model.Party, error) {
+	party, err := s.findParty(ctx, partyID)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(party.Invites, playerID) {
+		return nil, fmt.Errorf("%w: invite not found", ErrNotFound)
+	}
+	if _, err := s.repo.GetPlayerParty(ctx, playerID); err == nil {
+		return nil, fmt.Errorf("%w: player already in a party", ErrConflict)
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		logger(ctx).Error("error fetching player party", "player_id", playerID, "error", err)
+		return nil, err
+	}
+	switch {
+	case party.Queued:
+		return nil, fmt.Errorf("%w: party is in the waiting queue", ErrConflict)
+	case len(party.Members) >= MaxPartySize:
+		return nil, fmt.Errorf("%w: party is full", ErrConflict)
+	}
+	accepted, err := s.repo.AcceptPartyInvite(ctx, party.PartyID, playerID, MaxPartySize)
+	if err != nil {
+		return nil, err
+	}
+	if !accepted {
+		return nil, fmt.Errorf("%w: party changed, try again", ErrConflict)
+	}
+	logger(ctx).Info("player joined party", "party_id", party.PartyID, "player_id", playerID)
+	return s.findParty(ctx, partyID)
+}
+
+// LeaveParty removes a member from a party and takes the party out of the
+// matchmaking queue.
+func (s *Service) LeaveParty(ctx context.Context, partyID, playerID string) error {
+	party, err := s.findParty(ctx, partyID)
+	if err != nil {
+		return err
+	}
+	left, err := s.repo.LeaveParty(ctx, party.PartyID, playerID)
+	if err != nil {
+		return err
+	}
+	if !left {
+		return fmt.Errorf("%w: player not in the party", ErrConflict)
+	}
+	logger(ctx).Info("player left party", "party_id", party.PartyID, "player_id", playerID, "dequeued", party.Queued)
+	return nil
+}
+
+// QueueParty lets the leader put the whole party in the matchmaking queue.
+// Matchmaking places all of its members in the same competition.
+func (s *Service) QueueParty(ctx context.Context, partyID, playerID string) error {
+	party, err := s.findParty(ctx, partyID)
+	if err != nil {
+		return err
+	}
+	if party.LeaderID != playerID {
+		return fmt.Errorf("%w: only the party leader can queue the party", ErrForbidden)
+	}
+	if maxSize := s.currentConfig().MaxGroupSize; maxSize > 0 && len(party.Members) > maxSize {
+		return fmt.Errorf("%w: party is larger than the maximum group size of %d", ErrConflict, maxSize)
+	}
+	now := time.Now()
+	entries := make([]model.PlayerCompetition, len(party.Members))
+	for i, memberID := range party.Members {
+		player, err := s.findPlayer(ctx, memberID)
+		if err != nil {
+			return err
+		}
+		if err := s.checkSanctions(ctx, memberID); err != nil {
+			return fmt.Errorf("%w: party member %s: %v", ErrForbidden, memberID, err)
+		}
+		if _, err := s.repo.GetActivePlayerCompetition(ctx, memberID); err == nil {
+			return fmt.Errorf("%w: party member %s already in active competition", ErrConflict, memberID)
+		}
+		entries[i] = model.PlayerCompetition{
+			PlayerID:    memberID,
+			Status:      model.StatusWaiting,
+			JoinedAt:    now,
+			UpdatedAt:   now,
+			Level:       player.Level,
+			CountryCode: player.CountryCode,
+			Tier:        player.Tier,
+			PartyID:     &party.PartyID,
+		}
+	}
+	queued, err := s.repo.QueueParty(ctx, party.PartyID, entries)
+	if err != nil {
+		return err
+	}
+	if !queued {
+		return fmt.Errorf("%w: party or one of its members already in waiting queue", ErrConflict)
+	}
+	logger(ctx).Info("party added to matchmaking queue", "party_id", party.PartyID, "players", party.Members)
+	return nil
+}
@@ -0,0 +1,243 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"leaderboard-service/internal/model"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func partyEntries(partyID uuid.UUID, levels map[string]int, tier model.Tier, ids ...string) []model.PlayerCompetition {
+	pcs := make([]model.PlayerCompetition, len(ids))
+	for i, id := range ids {
+		pcs[i] = model.PlayerCompetition{PlayerID: id, Level: levels[id], Tier: tier, PartyID: &partyID}
+	}
+	return pcs
+}
+
+func TestQueueUnits(t *testing.T) {
+	party := uuid.New()
+	levels := map[string]int{"p2": 2, "p3": 5}
+	waiting := []model.PlayerCompetition{{PlayerID: "p1", Level: 1, Tier: model.TierBronze}}
+	waiting = append(waiting, partyEntries(party, levels, model.TierBronze, "p2")...)
+	waiting = append(waiting, model.PlayerCompetition{PlayerID: "p4", Level: 7, Tier: model.TierSilver})
+	waiting = append(waiting, partyEntries(party, levels, model.TierGold, "p3")...)
+
+	units := queueUnits(waiting, model.PartyLevelMax)
+	if len(units) != 3 || playerCount(units) != 4 {
+		t.Fatalf("expected 3 units of 4 players, got %+v", units)
+	}
+	if u := units[1]; len(u.entries) != 2 || u.level != 5 || u.tier != model.TierGold {
+		t.Errorf("expected the party at its highest level and tier, got %+v", u)
+	}
+	if u := queueUnits(waiting, model.PartyLevelAverage)[1]; u.level != 4 {
+		t.Errorf("expected the party at its rounded average level 4, got %d", u.level)
+	}
+
+	// A unit that no longer fits is skipped, not split.
+	if fitted := fitUnits(units, 2); playerCount(fitted) != 2 || fitted[0].entries[0].PlayerID != "p1" || fitted[1].entries[0].PlayerID != "p4" {
+		t.Errorf("unexpected fit: %+v", fitted)
+	}
+	if fitted := fitUnits(units, 0); len(fitted) != 3 {
+		t.Errorf("expected no limit with 0, got %+v", fitted)
+	}
+}
+
+func TestService_RunMatchmaking_KeepsPartyTogether(t *testing.T) {
+	party := uuid.New()
+	cases := []struct {
+		name    string
+		max     int
+		waiting []model.PlayerCompetition
+		want    string
+	}{
+		// The party is rated at its highest level, 5, and matched with p4.
+		{"level", 10, append(append([]model.PlayerCompetition{{PlayerID: "p1", Level: 1}},
+			partyEntries(party, map[string]int{"p2": 1, "p3": 5}, "", "p2", "p3")...),
+			model.PlayerCompetition{PlayerID: "p4", Level: 5}), "[p2 p3 p4]"},
+		// The party does not fit next to p1, so p4 takes the second place.
+		{"group size", 2, append(append([]model.PlayerCompetition{{PlayerID: "p1", Level: 1}},
+			partyEntries(party, map[string]int{"p2": 1, "p3": 1}, "", "p2", "p3")...),
+			model.PlayerCompetition{PlayerID: "p4", Level: 1}), "[p1 p4]"},
+	}
+	for _, tc := range cases {
+		var matched []string
+		repo := &mockRepo{
+			GetWaitingPlayersFunc: func(ctx context.Context, limit int) ([]model.PlayerCompetition, error) {
+				return tc.waiting, nil
+			},
+			CreateCompetitionFunc: func(ctx context.Context, comp *model.Competition) error {
+				return nil
+			},
+			UpdatePlayerCompetitionsToActiveFunc: func(ctx context.Context, playerIDs []string, competitionID uuid.UUID, endsAt time.Time) error {
+				matched = playerIDs
+				return nil
+			},
+		}
+		config := validConfig()
+		config.MaxGroupSize = tc.max
+		if err := NewService(repo, config).runMatchmaking(context.Background()); err != nil {
+			t.Fatalf("%s: runMatchmaking failed: %v", tc.name, err)
+		}
+		if fmt.Sprint(matched) != tc.want {
+			t.Errorf("%s: expected %s to be matched, got %v", tc.name, tc.want, matched)
+		}
+	}
+}
+
+func partyRepo(party *model.Party) *mockRepo {
+	return &mockRepo{
+		GetPartyFunc: func(ctx context.Context, partyID uuid.UUID) (*model.Party, error) {
+			if partyID != party.PartyID {
+				return nil, sql.ErrNoRows
+			}
+			return party, nil
+		},
+		GetPlayerByIDFunc: func(ctx context.Context, playerID string) (*model.Player, error) {
+			if playerID == "ghost" {
+				return nil, sql.ErrNoRows
+			}
+			return &model.Player{PlayerID: playerID, Level: len(playerID), CountryCode: "DE", Tier: model.TierSilver}, nil
+		},
+		GetActivePlayerCompetitionFunc: func(ctx context.Context, playerID string) (*model.PlayerCompetition, error) {
+			return nil, sql.ErrNoRows
+		},
+	}
+}
+
+func TestService_InviteToParty(t *testing.T) {
+	party := &model.Party{PartyID: uuid.New(), LeaderID: "lead", Members: []string{"lead", "p2"}}
+	repo := partyRepo(party)
+	repo.InvitePartyPlayerFunc = func(ctx context.Context, partyID uuid.UUID, playerID string) (bool, error) {
+		return playerID != "again", nil
+	}
+	svc := NewService(repo, validConfig())
+	sub := svc.Hub().Subscribe(playerTopic("friend"), 0)
+	defer sub.Close()
+
+	cases := map[string]struct {
+		party, player, invitee string
+		want                   error
+	}{
+		"unknown party":  {uuid.NewString(), "lead", "friend", ErrNotFound},
+		"not the leader": {party.PartyID.String(), "p2", "friend", ErrForbidden},
+		"unknown player": {party.PartyID.String(), "lead", "ghost", ErrNotFound},
+		"member":         {party.PartyID.String(), "lead", "p2", ErrConflict},
+		"invited":        {party.PartyID.String(), "lead", "again", ErrConflict},
+	}
+	for name, tc := range cases {
+		if err := svc.InviteToParty(context.Background(), tc.party, tc.player, tc.invitee); !errors.Is(err, tc.want) {
+			t.Errorf("%s: expected %v, got %v", name, tc.want, err)
+		}
+	}
+
+	if err := svc.InviteToParty(context.Background(), party.PartyID.String(), "lead", "friend"); err != nil {
+		t.Fatalf("InviteToParty failed: %v", err)
+	}
+	select {
+	case ev := <-sub.C:
+		if data := ev.Data.(map[string]interface{}); ev.Type != EventPartyInvite || data["party_id"] != party.PartyID.String() {
+			t.Errorf("unexpected event: %+v", ev)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected a party_invite event")
+	}
+}
+
+func TestService_AcceptPartyInvite(t *testing.T) {
+	party := &model.Party{PartyID: uuid.New(), LeaderID: "lead", Members: []string{"lead"}, Invites: []string{"friend", "busy"}}
+	repo := partyRepo(party)
+	repo.GetPlayerPartyFunc = func(ctx context.Context, playerID string) (*model.Party, error) {
+		if playerID == "busy" {
+			return &model.Party{PartyID: uuid.New()}, nil
+		}
+		return nil, sql.ErrNoRows
+	}
+	var accepted string
+	repo.AcceptPartyInviteFunc = func(ctx context.Context, partyID uuid.UUID, playerID string, maxSize int) (bool, error) {
+		accepted = playerID
+		return maxSize == MaxPartySize, nil
+	}
+	svc := NewService(repo, validConfig())
+	id := party.PartyID.String()
+
+	if _, err := svc.AcceptPartyInvite(context.Background(), id, "stranger"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound without an invite, got %v", err)
+	}
+	if _, err := svc.AcceptPartyInvite(context.Background(), id, "busy"); !errors.Is(err, ErrConflict) {
+		t.Errorf("expected ErrConflict for a player in another party, got %v", err)
+	}
+	party.Queued = true
+	if _, err := svc.AcceptPartyInvite(context.Background(), id, "friend"); !errors.Is(err, ErrConflict) {
+		t.Errorf("expected ErrConflict for a queued party, got %v", err)
+	}
+	party.Queued = false
+	if _, err := svc.AcceptPartyInvite(context.Background(), id, "friend"); err != nil || accepted != "friend" {
+		t.Errorf("expected the invite to be accepted, got %v (%q)", err, accepted)
+	}
+}
+
+func TestService_QueueParty(t *testing.T) {
+	party := &model.Party{PartyID: uuid.New(), LeaderID: "lead", Members: []string{"lead", "p2", "p3"}}
+	repo := partyRepo(party)
+	var queued []model.PlayerCompetition
+	repo.QueuePartyFunc = func(ctx context.Context, partyID uuid.UUID, entries []model.PlayerCompetition) (bool, error) {
+		queued = entries
+		return true, nil
+	}
+	config := validConfig()
+	config.MaxGroupSize = 2
+	svc := NewService(repo, config)
+	id := party.PartyID.String()
+
+	if err := svc.QueueParty(context.Background(), id, "p2"); !errors.Is(err, ErrForbidden) {
+		t.Errorf("expected ErrForbidden for a member, got %v", err)
+	}
+	if err := svc.QueueParty(context.Background(), id, "lead"); !errors.Is(err, ErrConflict) {
+		t.Errorf("expected ErrConflict for a party larger than a group, got %v", err)
+	}
+
+	config.MaxGroupSize = 3
+	svc = NewService(repo, config)
+	if err := svc.QueueParty(context.Background(), id, "lead"); err != nil {
+		t.Fatalf("QueueParty failed: %v", err)
+	}
+	if len(queued) != 3 {
+		t.Fatalf("expected an entry per member, got %+v", queued)
+	}
+	for _, pc := range queued {
+		if pc.Status != model.StatusWaiting || pc.PartyID == nil || *pc.PartyID != party.PartyID || pc.Tier != model.TierSilver ||
+			pc.Level != len(pc.PlayerID) || !pc.JoinedAt.Equal(queued[0].JoinedAt) {
+			t.Errorf("unexpected entry: %+v", pc)
+		}
+	}
+}
+
+func TestService_Join_PartyMember(t *testing.T) {
+	repo := &mockRepo{
+		GetPlayerByIDFunc: func(ctx context.Context, playerID string) (*model.Player, error) {
+			return &model.Player{PlayerID: playerID}, nil
+		},
+		GetActivePlayerCompetitionFunc: func(ctx context.Context, playerID string) (*model.PlayerCompetition, error) {
+			return nil, sql.ErrNoRows
+		},
+		IsPlayerInWaitingQueueFunc: func(ctx context.Context, playerID string) (bool, error) {
+			return false, nil
+		},
+		GetPlayerPartyFunc: func(ctx context.Context, playerID string) (*model.Party, error) {
+			return &model.Party{PartyID: uuid.New(), LeaderID: "lead"}, nil
+		},
+		CreatePlayerCompetitionFunc: func(ctx context.Context, pc *model.PlayerCompetition) error {
+			t.Error("expected a party member not to queue alone")
+			return nil
+		},
+	}
+	if _, err := NewService(repo, validConfig()).Join(context.Background(), "p2"); err == nil || err.Error() != "player in a party" {
+		t.Errorf("expected player in a party, got %v", err)
+	}
+}
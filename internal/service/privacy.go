@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"leaderboard-service/internal/logging"
+	"leaderboard-service/internal/model"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AnonymousPlayerPrefix starts the pseudonym a deleted player's entries are
+// kept under. Players cannot be created with it.
+const AnonymousPlayerPrefix = "deleted-"
+
+// PlayerExport is all the data held about a player.
+type PlayerExport struct {
+	ExportedAt time.Time     `json:"exported_at"`
+	Player     *model.Player `json:"player"`
+	// QueueEntries are the player's matchmaking queue entries, past and
+	// present; Competitions their entries and registrations in competitions.
+	QueueEntries  []model.PlayerCompetition `json:"queue_entries"`
+	Competitions  []model.PlayerCompetition `json:"competitions"`
+	LeagueHistory []model.TierMovement      `json:"league_history"`
+	Friends       []string                  `json:"friends"`
+	Party         *model.Party              `json:"party,omitempty"`
+	// ScoreEvents are the admin actions on the player, their scores and
+	// entries, newest first. Shadow-bans are left out, as the player is not
+	// to know of them.
+	ScoreEvents []model.AuditEntry `json:"score_events"`
+}
+
+// purgeLogger returns the logger for the purge worker.
+func purgeLogger(ctx context.Context) *slog.Logger {
+	return logging.FromContext(ctx).With("component", "purge")
+}
+
+// DeletePlayer deletes a player on their request. Their entries stay on
+// competition leaderboards, so other players keep their ranks, but under a
+// random pseudonym that nothing links back to playerID, the audit log
+// included; their country, level, profile, friends, party and queue place
+// are dropped, from their entries too.
+func (s *Service) DeletePlayer(ctx context.Context, playerID string) error {
+	if _, err := s.findPlayer(ctx, playerID); err != nil {
+		return err
+	}
+	if party, err := s.repo.GetPlayerParty(ctx, playerID); err == nil {
+		if _, err := s.repo.LeaveParty(ctx, party.PartyID, playerID); err != nil {
+			return err
+		}
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		logger(ctx).Error("error fetching player party", "player_id", playerID, "error", err)
+		return err
+	}
+	ok, err := s.repo.AnonymizePlayer(ctx, playerID, AnonymousPlayerPrefix+uuid.NewString())
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("%w: player not found", ErrNotFound)
+	}
+	logger(ctx).Info("player deleted", "player_id", playerID)
+	return nil
+}
+
+// ExportPlayerData returns all the data held about playerID.
+func (s *Service) ExportPlayerData(ctx context.Context, playerID string) (*PlayerExport, error) {
+	player, err := s.findPlayer(ctx, playerID)
+	if err != nil {
+		return nil, err
+	}
+	export := &PlayerExport{ExportedAt: time.Now().UTC(), Player: player,
+		QueueEntries: []model.PlayerCompetition{}, Competitions: []model.PlayerCompetition{}}
+
+	entries, err := s.repo.ListPlayerEntries(ctx, playerID)
+	if err != nil {
+		return nil, err
+	}
+	for _, pc := range entries {
+		if pc.CompetitionID == nil {
+			export.QueueEntries = append(export.QueueEntries, pc)
+		} else {
+			export.Competitions = append(export.Competitions, pc)
+		}
+	}
+	if export.LeagueHistory, err = s.repo.ListTierHistory(ctx, playerID, 0); err != nil {
+		return nil, err
+	}
+	if export.Friends, err = s.repo.ListFriends(ctx, playerID); err != nil {
+		return nil, err
+	}
+	if party, err := s.repo.GetPlayerParty(ctx, playerID); err == nil {
+		export.Party = party
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	events, err := s.repo.ListAuditEntries(ctx, model.AuditFilter{Player: playerID})
+	if err != nil {
+		return nil, err
+	}
+	export.ScoreEvents = []model.AuditEntry{}
+	for _, e := range events {
+		if e.Action != AuditActionPlayerShadowBan && e.Action != AuditActionPlayerLiftShadowBan {
+			export.ScoreEvents = append(export.ScoreEvents, e)
+		}
+	}
+	logger(ctx).Info("player data exported", "player_id", playerID)
+	return export, nil
+}
+
+// purgeAnonymized purges the players deleted more than retention ago.
+func (s *Service) purgeAnonymized(ctx context.Context, retention time.Duration) {
+	n, err := s.repo.PurgeAnonymizedPlayers(ctx, time.Now().Add(-retention))
+	if err != nil {
+		purgeLogger(ctx).Error("error purging deleted players", "error", err)
+		return
+	}
+	if n > 0 {
+		purgeLogger(ctx).Info("purged deleted players", "players", n, "retention", retention)
+	}
+}
+
+// StartPurgeWorker purges what is left of players deleted more than
+// retention ago, at start and then every interval, until ctx is cancelled.
+// The returned channel is closed once the worker has exited.
+func (s *Service) StartPurgeWorker(ctx context.Context, interval, retention time.Duration) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		purgeLogger(ctx).Info("worker started", "interval", interval, "retention", retention)
+		for {
+			s.purgeAnonymized(context.WithoutCancel(ctx), retention)
+			select {
+			case <-ctx.Done():
+				purgeLogger(ctx).Info("worker stopped")
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return done
+}
+
+// checkPlayerID rejects IDs that could pass for a deleted player's.
+func checkPlayerID(playerID string) error {
+	if strings.HasPrefix(playerID, AnonymousPlayerPrefix) {
+		return fmt.Errorf("%w: player IDs must not start with %q", ErrInvalidArgument, AnonymousPlayerPrefix)
+	}
+	return nil
+}
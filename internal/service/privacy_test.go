@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"leaderboard-service/internal/model"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestService_DeletePlayer(t *testing.T) {
+	partyID := uuid.New()
+	var left, alias string
+	repo := &mockRepo{
+		GetPlayerByIDFunc: func(ctx context.Context, playerID string) (*model.Player, error) {
+			if playerID != "p1" {
+				return nil, sql.ErrNoRows
+			}
+			return &model.Player{PlayerID: playerID}, nil
+		},
+		GetPlayerPartyFunc: func(ctx context.Context, playerID string) (*model.Party, error) {
+			return &model.Party{PartyID: partyID, LeaderID: playerID}, nil
+		},
+		LeavePartyFunc: func(ctx context.Context, id uuid.UUID, playerID string) (bool, error) {
+			if id == partyID {
+				left = playerID
+			}
+			return true, nil
+		},
+		AnonymizePlayerFunc: func(ctx context.Context, playerID, a string) (bool, error) {
+			alias = a
+			return true, nil
+		},
+	}
+	svc := NewService(repo, validConfig())
+
+	if err := svc.DeletePlayer(context.Background(), "p1"); err != nil {
+		t.Fatalf("DeletePlayer failed: %v", err)
+	}
+	if left != "p1" {
+		t.Errorf("expected the player to leave their party first")
+	}
+	if !strings.HasPrefix(alias, AnonymousPlayerPrefix) || strings.Contains(alias, "p1") {
+		t.Errorf("unexpected pseudonym %q", alias)
+	}
+	if err := svc.DeletePlayer(context.Background(), "ghost"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for an unknown player, got %v", err)
+	}
+}
+
+func TestService_CreatePlayer_ReservedPrefix(t *testing.T) {
+	svc := NewService(&mockRepo{}, validConfig())
+	if _, err := svc.CreatePlayer(context.Background(), AnonymousPlayerPrefix+"x", PlayerProfile{Level: intPtr(1), CountryCode: strPtr("")}); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("expected ErrInvalidArgument, got %v", err)
+	}
+}
+
+func TestService_ExportPlayerData(t *testing.T) {
+	compID := uuid.New()
+	var auditFilter model.AuditFilter
+	repo := &mockRepo{
+		GetPlayerByIDFunc: func(ctx context.Context, playerID string) (*model.Player, error) {
+			return &model.Player{PlayerID: playerID, CountryCode: "DE"}, nil
+		},
+		ListPlayerEntriesFunc: func(ctx context.Context, playerID string) ([]model.PlayerCompetition, error) {
+			return []model.PlayerCompetition{
+				{ID: 1, PlayerID: playerID, Status: model.StatusCancelled},
+				{ID: 2, PlayerID: playerID, CompetitionID: &compID, Status: model.StatusCompleted, Score: 40},
+			}, nil
+		},
+		ListTierHistoryFunc: func(ctx context.Context, playerID string, limit int) ([]model.TierMovement, error) {
+			if limit != 0 {
+				t.Errorf("expected the whole league history, got limit %d", limit)
+			}
+			return []model.TierMovement{{PlayerID: playerID}}, nil
+		},
+		ListFriendsFunc: func(ctx context.Context, playerID string) ([]string, error) {
+			return []string{"p2"}, nil
+		},
+		ListAuditEntriesFunc: func(ctx context.Context, filter model.AuditFilter) ([]model.AuditEntry, error) {
+			auditFilter = filter
+			return []model.AuditEntry{{Action: AuditActionScoreAdjust}, {Action: AuditActionPlayerShadowBan}, {Action: AuditActionPlayerBan}}, nil
+		},
+	}
+	export, err := NewService(repo, validConfig()).ExportPlayerData(context.Background(), "p1")
+	if err != nil {
+		t.Fatalf("ExportPlayerData failed: %v", err)
+	}
+	if len(export.QueueEntries) != 1 || export.QueueEntries[0].ID != 1 || len(export.Competitions) != 1 || export.Competitions[0].ID != 2 {
+		t.Errorf("unexpected entries: %+v %+v", export.QueueEntries, export.Competitions)
+	}
+	if len(export.LeagueHistory) != 1 || len(export.Friends) != 1 || export.Party != nil {
+		t.Errorf("unexpected export: %+v", export)
+	}
+	// Sanctions are exported, shadow-bans are not.
+	if len(export.ScoreEvents) != 2 || export.ScoreEvents[0].Action != AuditActionScoreAdjust || export.ScoreEvents[1].Action != AuditActionPlayerBan {
+		t.Errorf("unexpected score events: %+v", export.ScoreEvents)
+	}
+	if auditFilter != (model.AuditFilter{Player: "p1"}) {
+		t.Errorf("unexpected audit filter: %+v", auditFilter)
+	}
+}
+
+func TestService_StartPurgeWorker(t *testing.T) {
+	cutoffs := make(chan time.Time, 10)
+	repo := &mockRepo{
+		PurgeAnonymizedPlayersFunc: func(ctx context.Context, cutoff time.Time) (int, error) {
+			cutoffs <- cutoff
+			return 1, nil
+		},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := NewService(repo, validConfig()).StartPurgeWorker(ctx, time.Hour, 24*time.Hour)
+
+	select {
+	case cutoff := <-cutoffs:
+		if age := time.Since(cutoff); age < 24*time.Hour || age > 24*time.Hour+time.Minute {
+			t.Errorf("expected a cutoff a day ago, got %s ago", age)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the worker to purge at start")
+	}
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected the worker to stop")
+	}
+}
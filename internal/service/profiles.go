@@ -0,0 +1,187 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"leaderboard-service/internal/model"
+	"leaderboard-service/internal/repository"
+	"net/url"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// MinDisplayNameLength and MaxDisplayNameLength bound the length of a
+	// display name in characters.
+	MinDisplayNameLength = 3
+	MaxDisplayNameLength = 32
+	// MaxAvatarURLLength and MaxMetadataSize bound, in bytes, the avatar
+	// URL and metadata a player may keep.
+	MaxAvatarURLLength = 2048
+	MaxMetadataSize    = 16 << 10
+)
+
+// PlayerProfile is what a player sets about themselves. A nil field is left
+// as it is on update, and unset on create; an empty country, display name
+// or avatar URL (JSON null for metadata) clears it.
+type PlayerProfile struct {
+	Level       *int
+	CountryCode *string
+	DisplayName *string
+	AvatarURL   *string
+	// Metadata must be a JSON object.
+	Metadata json.RawMessage
+	// Version, when not 0, is the version of the player an update was
+	// made against; the update fails with ErrPreconditionFailed if the
+	// player has changed since.
+	Version int
+}
+
+// NameFilter vets a display name before it is saved, e.g. against a
+// profanity list. It returns an error saying why the name may not be used.
+type NameFilter func(ctx context.Context, name string) error
+
+// UseNameFilters adds filters that every new display name must pass, in
+// order. It must be called before the service is used.
+func (s *Service) UseNameFilters(filters ...NameFilter) {
+	s.nameFilters = append(s.nameFilters, filters...)
+}
+
+// BlockedWords returns a NameFilter that rejects names containing any of
+// words, ignoring case.
+func BlockedWords(words ...string) NameFilter {
+	blocked := make([]string, 0, len(words))
+	for _, w := range words {
+		if w = strings.ToLower(strings.TrimSpace(w)); w != "" {
+			blocked = append(blocked, w)
+		}
+	}
+	return func(ctx context.Context, name string) error {
+		name = strings.ToLower(name)
+		for _, w := range blocked {
+			if strings.Contains(name, w) {
+				return errors.New("contains a blocked word")
+			}
+		}
+		return nil
+	}
+}
+
+// applyProfile validates profile and sets it on player; display names that
+// pass validation are also run through the name filters.
+func (s *Service) applyProfile(ctx context.Context, player *model.Player, profile PlayerProfile) error {
+	if profile.Level != nil {
+		player.Level = *profile.Level
+	}
+	if profile.CountryCode != nil {
+		countryCode, err := normalizeCountry(*profile.CountryCode)
+		if err != nil {
+			return err
+		}
+		player.CountryCode = countryCode
+	}
+	if profile.DisplayName != nil {
+		name, err := s.checkDisplayName(ctx, *profile.DisplayName)
+		if err != nil {
+			return err
+		}
+		player.DisplayName = name
+	}
+	if profile.AvatarURL != nil {
+		if err := checkAvatarURL(*profile.AvatarURL); err != nil {
+			return err
+		}
+		player.AvatarURL = *profile.AvatarURL
+	}
+	if profile.Metadata != nil {
+		metadata, err := checkMetadata(profile.Metadata)
+		if err != nil {
+			return err
+		}
+		player.Metadata = metadata
+	}
+	return nil
+}
+
+// checkDisplayName returns name without surrounding spaces, or an error if
+// players may not use it. An empty name clears the player's.
+func (s *Service) checkDisplayName(ctx context.Context, name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", nil
+	}
+	if n := utf8.RuneCountInString(name); n < MinDisplayNameLength || n > MaxDisplayNameLength {
+		return "", fmt.Errorf("%w: display_name must be %d to %d characters", ErrInvalidArgument, MinDisplayNameLength, MaxDisplayNameLength)
+	}
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune(" _-.", r) {
+			return "", fmt.Errorf("%w: display_name may only contain letters, digits, spaces and _-.", ErrInvalidArgument)
+		}
+	}
+	for _, filter := range s.nameFilters {
+		if err := filter(ctx, name); err != nil {
+			logger(ctx).Info("display name rejected", "error", err)
+			return "", fmt.Errorf("%w: display_name %v", ErrInvalidArgument, err)
+		}
+	}
+	return name, nil
+}
+
+// checkAvatarURL accepts an absolute http or https URL, or "" for none.
+func checkAvatarURL(raw string) error {
+	if raw == "" {
+		return nil
+	}
+	if len(raw) > MaxAvatarURLLength {
+		return fmt.Errorf("%w: avatar_url must be at most %d bytes", ErrInvalidArgument, MaxAvatarURLLength)
+	}
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: avatar_url must be an absolute http or https URL", ErrInvalidArgument)
+	}
+	return nil
+}
+
+// checkMetadata returns the metadata to store, nil for JSON null.
+func checkMetadata(raw json.RawMessage) (json.RawMessage, error) {
+	raw = bytes.TrimSpace(raw)
+	if bytes.Equal(raw, []byte("null")) {
+		return nil, nil
+	}
+	if len(raw) > MaxMetadataSize {
+		return nil, fmt.Errorf("%w: metadata must be at most %d bytes", ErrInvalidArgument, MaxMetadataSize)
+	}
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &object); err != nil {
+		return nil, fmt.Errorf("%w: metadata must be a JSON object", ErrInvalidArgument)
+	}
+	return raw, nil
+}
+
+// saveError translates repository errors from saving a player.
+func saveError(err error) error {
+	if errors.Is(err, repository.ErrDisplayNameTaken) {
+		return fmt.Errorf("%w: display_name already taken", ErrConflict)
+	}
+	return err
+}
+
+// leaderboardEntry is how a leaderboard shows pc: the player's ID and score,
+// with their display name and avatar if they have them.
+func leaderboardEntry(pc model.PlayerCompetition) map[string]interface{} {
+	entry := map[string]interface{}{
+		"player_id": pc.PlayerID,
+		"score":     pc.Score,
+	}
+	if pc.DisplayName != "" {
+		entry["display_name"] = pc.DisplayName
+	}
+	if pc.AvatarURL != "" {
+		entry["avatar_url"] = pc.AvatarURL
+	}
+	return entry
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"leaderboard-service/internal/model"
+	"leaderboard-service/internal/repository"
+	"strings"
+	"testing"
+)
+
+func strPtr(s string) *string { return &s }
+
+func intPtr(n int) *int { return &n }
+
+func TestService_CreatePlayer_Profile(t *testing.T) {
+	var saved *model.Player
+	repo := &mockRepo{
+		CreatePlayerFunc: func(ctx context.Context, player *model.Player) error {
+			if strings.EqualFold(player.DisplayName, "Taken") {
+				return repository.ErrDisplayNameTaken
+			}
+			saved = player
+			player.Version = 1
+			return nil
+		},
+	}
+	svc := NewService(repo, validConfig())
+	svc.UseNameFilters(BlockedWords("Darn"))
+	ctx := context.Background()
+
+	player, err := svc.CreatePlayer(ctx, "p1", PlayerProfile{
+		Level:       intPtr(2),
+		DisplayName: strPtr("  Ada Lovelace "),
+		AvatarURL:   strPtr("https://example.com/ada.png"),
+		Metadata:    json.RawMessage(`{"clan": "analysts"}`),
+	})
+	if err != nil {
+		t.Fatalf("CreatePlayer failed: %v", err)
+	}
+	if saved.DisplayName != "Ada Lovelace" || saved.AvatarURL != "https://example.com/ada.png" || string(saved.Metadata) != `{"clan": "analysts"}` {
+		t.Errorf("unexpected player saved: %+v", saved)
+	}
+	if player.Version != 1 {
+		t.Errorf("expected the saved player back, got %+v", player)
+	}
+
+	invalid := []PlayerProfile{
+		{DisplayName: strPtr("Al")},
+		{DisplayName: strPtr(strings.Repeat("a", MaxDisplayNameLength+1))},
+		{DisplayName: strPtr("<script>")},
+		{DisplayName: strPtr("Oh DARN it")},
+		{AvatarURL: strPtr("javascript:alert(1)")},
+		{AvatarURL: strPtr("/avatars/1.png")},
+		{Metadata: json.RawMessage(`[1, 2]`)},
+		{Metadata: json.RawMessage(`{"big": "` + strings.Repeat("x", MaxMetadataSize) + `"}`)},
+	}
+	for _, profile := range invalid {
+		saved = nil
+		if _, err := svc.CreatePlayer(ctx, "p2", profile); !errors.Is(err, ErrInvalidArgument) || saved != nil {
+			t.Errorf("%+v: expected ErrInvalidArgument, got %v", profile, err)
+		}
+	}
+	if _, err := svc.CreatePlayer(ctx, "p2", PlayerProfile{DisplayName: strPtr("taken")}); !errors.Is(err, ErrConflict) {
+		t.Errorf("expected ErrConflict for a taken name, got %v", err)
+	}
+}
+
+func TestService_UpdatePlayer_Profile(t *testing.T) {
+	current := model.Player{PlayerID: "p1", Level: 1, CountryCode: "FR", DisplayName: "Ada", AvatarURL: "https://example.com/a.png", Metadata: json.RawMessage(`{"a":1}`), Version: 3}
+	var saved *model.Player
+	repo := &mockRepo{
+		GetPlayerByIDFunc: func(ctx context.Context, playerID string) (*model.Player, error) {
+			if playerID != "p1" {
+				return nil, sql.ErrNoRows
+			}
+			p := current
+			return &p, nil
+		},
+		UpdatePlayerFunc: func(ctx context.Context, player *model.Player) error {
+			if player.Version != current.Version {
+				return sql.ErrNoRows
+			}
+			saved = player
+			player.Version++
+			return nil
+		},
+	}
+	svc := NewService(repo, validConfig())
+	ctx := context.Background()
+
+	player, err := svc.UpdatePlayer(ctx, "p1", PlayerProfile{Level: intPtr(2), AvatarURL: strPtr(""), Metadata: json.RawMessage("null"), Version: 3})
+	if err != nil {
+		t.Fatalf("UpdatePlayer failed: %v", err)
+	}
+	if saved.Level != 2 || saved.CountryCode != "FR" || saved.DisplayName != "Ada" || saved.AvatarURL != "" || saved.Metadata != nil {
+		t.Errorf("expected the avatar and metadata cleared and the country and name kept, got %+v", saved)
+	}
+	if _, err := svc.UpdatePlayer(ctx, "p1", PlayerProfile{CountryCode: strPtr("gb")}); err != nil || saved.Level != 1 || saved.CountryCode != "GB" {
+		t.Errorf("expected the country changed and the level kept, got %+v, %v", saved, err)
+	}
+	if player.Version != 4 {
+		t.Errorf("expected version 4, got %d", player.Version)
+	}
+
+	if _, err := svc.UpdatePlayer(ctx, "p1", PlayerProfile{Version: 2}); !errors.Is(err, ErrPreconditionFailed) {
+		t.Errorf("expected ErrPreconditionFailed for a stale version, got %v", err)
+	}
+	if _, err := svc.UpdatePlayer(ctx, "ghost", PlayerProfile{}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	// The player changes between the read and the write.
+	repo.UpdatePlayerFunc = func(ctx context.Context, player *model.Player) error {
+		return sql.ErrNoRows
+	}
+	if _, err := svc.UpdatePlayer(ctx, "p1", PlayerProfile{}); !errors.Is(err, ErrPreconditionFailed) {
+		t.Errorf("expected ErrPreconditionFailed for a concurrent change, got %v", err)
+	}
+}
+
+func TestService_GetLeaderboard_DisplayInfo(t *testing.T) {
+	repo := &mockRepo{
+		GetLeaderboardByCompetitionIDFunc: func(ctx context.Context, competitionID string, viewer model.Viewer) ([]model.PlayerCompetition, error) {
+			return []model.PlayerCompetition{
+				{PlayerID: "p1", Score: 20, DisplayName: "Ada", AvatarURL: "https://example.com/a.png"},
+				{PlayerID: "p2", Score: 10},
+			}, nil
+		},
+	}
+	board, err := NewService(repo, validConfig()).GetLeaderboard(context.Background(), "c1")
+	if err != nil {
+		t.Fatalf("GetLeaderboard failed: %v", err)
+	}
+	entries := board.(map[string]interface{})["leaderboard"].([]map[string]interface{})
+	if entries[0]["display_name"] != "Ada" || entries[0]["avatar_url"] != "https://example.com/a.png" {
+		t.Errorf("expected display info on the first entry, got %v", entries[0])
+	}
+	if _, ok := entries[1]["display_name"]; ok {
+		t.Errorf("expected no display name on the second entry, got %v", entries[1])
+	}
+}
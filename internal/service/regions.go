@@ -0,0 +1,227 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"leaderboard-service/internal/iso3166"
+	"leaderboard-service/internal/model"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+const (
+	AuditActionRegionUpdate = "region.update"
+	AuditActionRegionDelete = "region.delete"
+)
+
+const (
+	// DefaultTopPlayers is how many players GetTopPlayers returns unless
+	// asked for another number, up to MaxTopPlayers.
+	DefaultTopPlayers = 100
+	MaxTopPlayers     = 1000
+)
+
+var regionCodePattern = regexp.MustCompile(`^[A-Z][A-Z0-9]{1,7}$`)
+
+// RegionDefinition is what an admin sets for a region.
+type RegionDefinition struct {
+	Name string
+	// Countries are ISO 3166-1 alpha-2 codes; case and order do not matter.
+	Countries []string
+	// Reason is recorded in the audit log.
+	Reason string
+}
+
+// GeoRank is a player's place in a competition among the players of a
+// country or region.
+type GeoRank struct {
+	Rank        int    `json:"rank"`
+	PlayerID    string `json:"player_id"`
+	DisplayName string `json:"display_name,omitempty"`
+	AvatarURL   string `json:"avatar_url,omitempty"`
+	CountryCode string `json:"country_code"`
+	Score       int    `json:"score"`
+}
+
+// GeoLeaderboard is a competition's leaderboard narrowed to one country or
+// region.
+type GeoLeaderboard struct {
+	LeaderboardID string    `json:"leaderboard_id"`
+	CountryCode   string    `json:"country_code,omitempty"`
+	RegionCode    string    `json:"region_code,omitempty"`
+	Leaderboard   []GeoRank `json:"leaderboard"`
+}
+
+// TopPlayers ranks players by their stats over all completed competitions,
+// everywhere or within one country or region.
+type TopPlayers struct {
+	CountryCode string      `json:"country_code,omitempty"`
+	RegionCode  string      `json:"region_code,omitempty"`
+	Leaderboard []StatsRank `json:"leaderboard"`
+}
+
+// normalizeCountry returns code in stored form, or ErrInvalidArgument if it
+// is not an ISO 3166-1 alpha-2 code. An empty code means no country.
+func normalizeCountry(code string) (string, error) {
+	code = iso3166.Normalize(code)
+	if code != "" && !iso3166.Valid(code) {
+		return "", fmt.Errorf("%w: country_code %q is not an ISO 3166-1 alpha-2 code", ErrInvalidArgument, code)
+	}
+	return code, nil
+}
+
+func regionTarget(regionCode string) string {
+	return "region/" + regionCode
+}
+
+// ListRegions returns every region with its countries, by code.
+func (s *Service) ListRegions(ctx context.Context) ([]model.Region, error) {
+	return s.repo.ListRegions(ctx)
+}
+
+// findRegion returns a region by code, in any case, or ErrNotFound.
+func (s *Service) findRegion(ctx context.Context, regionCode string) (*model.Region, error) {
+	region, err := s.repo.GetRegion(ctx, strings.ToUpper(strings.TrimSpace(regionCode)))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: region not found", ErrNotFound)
+	}
+	if err != nil {
+		logger(ctx).Error("error fetching region", "region_code", regionCode, "error", err)
+		return nil, err
+	}
+	return region, nil
+}
+
+// PutRegion creates a region or replaces its name and countries. Regional
+// leaderboards follow the change at once.
+func (s *Service) PutRegion(ctx context.Context, regionCode string, def RegionDefinition) (*model.Region, error) {
+	actor, err := requireActor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	regionCode = strings.ToUpper(strings.TrimSpace(regionCode))
+	if !regionCodePattern.MatchString(regionCode) {
+		return nil, fmt.Errorf("%w: region code must be 2 to 8 letters or digits, starting with a letter", ErrInvalidArgument)
+	}
+	region := &model.Region{RegionCode: regionCode, Name: strings.TrimSpace(def.Name), Countries: []string{}}
+	if region.Name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidArgument)
+	}
+	for _, c := range def.Countries {
+		code, err := normalizeCountry(c)
+		if err != nil {
+			return nil, err
+		}
+		if code != "" && !slices.Contains(region.Countries, code) {
+			region.Countries = append(region.Countries, code)
+		}
+	}
+	if len(region.Countries) == 0 {
+		return nil, fmt.Errorf("%w: a region needs at least one country", ErrInvalidArgument)
+	}
+	slices.Sort(region.Countries)
+
+	var before interface{}
+	if existing, err := s.findRegion(ctx, regionCode); err == nil {
+		before = existing
+	} else if !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+	entry := newAuditEntry(actor, AuditActionRegionUpdate, regionTarget(regionCode), def.Reason, before, region)
+	if err := s.repo.PutRegion(ctx, region, entry); err != nil {
+		return nil, err
+	}
+	logger(ctx).Info("region saved", "region_code", regionCode, "actor", actor, "audit_id", entry.ID, "countries", len(region.Countries))
+	return region, nil
+}
+
+// DeleteRegion deletes a region. Players keep their countries.
+func (s *Service) DeleteRegion(ctx context.Context, regionCode, reason string) error {
+	actor, err := requireActor(ctx)
+	if err != nil {
+		return err
+	}
+	before, err := s.findRegion(ctx, regionCode)
+	if err != nil {
+		return err
+	}
+	entry := newAuditEntry(actor, AuditActionRegionDelete, regionTarget(before.RegionCode), reason, before, nil)
+	ok, err := s.repo.DeleteRegion(ctx, before.RegionCode, entry)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("%w: region not found", ErrNotFound)
+	}
+	logger(ctx).Info("region deleted", "region_code", before.RegionCode, "actor", actor, "audit_id", entry.ID)
+	return nil
+}
+
+// geoCountries validates filter and returns it in stored form with the
+// countries it matches; nil countries match everyone.
+func (s *Service) geoCountries(ctx context.Context, filter model.GeoFilter) (model.GeoFilter, []string, error) {
+	if filter.CountryCode != "" && filter.RegionCode != "" {
+		return filter, nil, fmt.Errorf("%w: filter by country_code or region, not both", ErrInvalidArgument)
+	}
+	if filter.RegionCode != "" {
+		region, err := s.findRegion(ctx, filter.RegionCode)
+		if err != nil {
+			return filter, nil, err
+		}
+		return model.GeoFilter{RegionCode: region.RegionCode}, region.Countries, nil
+	}
+	code, err := normalizeCountry(filter.CountryCode)
+	if err != nil || code == "" {
+		return filter, nil, err
+	}
+	return model.GeoFilter{CountryCode: code}, []string{code}, nil
+}
+
+// GetGeoLeaderboard ranks the players of a competition from filter's
+// country or region among themselves, by the country they played for.
+func (s *Service) GetGeoLeaderboard(ctx context.Context, leaderboardID string, filter model.GeoFilter) (*GeoLeaderboard, error) {
+	filter, countries, err := s.geoCountries(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	pcs, err := s.repo.GetLeaderboardByCompetitionID(ctx, leaderboardID, model.Viewer{})
+	if err != nil || len(pcs) == 0 {
+		return nil, fmt.Errorf("%w: leaderboard not found", ErrNotFound)
+	}
+	board := &GeoLeaderboard{LeaderboardID: leaderboardID, CountryCode: filter.CountryCode, RegionCode: filter.RegionCode, Leaderboard: []GeoRank{}}
+	for _, pc := range pcs {
+		if countries == nil || slices.Contains(countries, pc.CountryCode) {
+			board.Leaderboard = append(board.Leaderboard, GeoRank{Rank: len(board.Leaderboard) + 1, PlayerID: pc.PlayerID,
+				DisplayName: pc.DisplayName, AvatarURL: pc.AvatarURL, CountryCode: pc.CountryCode, Score: pc.Score})
+		}
+	}
+	return board, nil
+}
+
+// GetTopPlayers ranks players by their stats over all completed
+// competitions, everywhere or within filter's country or region. A limit of
+// 0 means DefaultTopPlayers.
+func (s *Service) GetTopPlayers(ctx context.Context, filter model.GeoFilter, limit int) (*TopPlayers, error) {
+	if limit == 0 {
+		limit = DefaultTopPlayers
+	}
+	if limit < 1 || limit > MaxTopPlayers {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidArgument, MaxTopPlayers)
+	}
+	filter, _, err := s.geoCountries(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := s.repo.ListTopPlayers(ctx, filter, limit)
+	if err != nil {
+		return nil, err
+	}
+	top := &TopPlayers{CountryCode: filter.CountryCode, RegionCode: filter.RegionCode, Leaderboard: make([]StatsRank, len(stats))}
+	for i, st := range stats {
+		top.Leaderboard[i] = StatsRank{Rank: i + 1, PlayerStats: st}
+	}
+	return top, nil
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"leaderboard-service/internal/auth"
+	"leaderboard-service/internal/model"
+	"testing"
+)
+
+func TestNormalizeCountry(t *testing.T) {
+	cases := map[string]string{"de": "DE", " us ": "US", "": ""}
+	for in, want := range cases {
+		if got, err := normalizeCountry(in); err != nil || got != want {
+			t.Errorf("normalizeCountry(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"XX", "USA", "U"} {
+		if _, err := normalizeCountry(in); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("normalizeCountry(%q): expected ErrInvalidArgument, got %v", in, err)
+		}
+	}
+}
+
+func TestService_CreatePlayer_InvalidCountry(t *testing.T) {
+	var saved *model.Player
+	repo := &mockRepo{
+		CreatePlayerFunc: func(ctx context.Context, player *model.Player) error {
+			saved = player
+			return nil
+		},
+	}
+	svc := NewService(repo, validConfig())
+	if _, err := svc.CreatePlayer(context.Background(), "p1", PlayerProfile{Level: intPtr(1), CountryCode: strPtr("XX")}); !errors.Is(err, ErrInvalidArgument) || saved != nil {
+		t.Errorf("expected ErrInvalidArgument before saving, got %v", err)
+	}
+	if _, err := svc.CreatePlayer(context.Background(), "p1", PlayerProfile{Level: intPtr(1), CountryCode: strPtr("fr")}); err != nil || saved.CountryCode != "FR" {
+		t.Errorf("expected the country to be stored upper-case, got %+v (%v)", saved, err)
+	}
+}
+
+func TestService_PutRegion(t *testing.T) {
+	var saved *model.Region
+	var audit *model.AuditEntry
+	repo := &mockRepo{
+		GetRegionFunc: func(ctx context.Context, regionCode string) (*model.Region, error) {
+			return nil, sql.ErrNoRows
+		},
+		PutRegionFunc: func(ctx context.Context, region *model.Region, entry *model.AuditEntry) error {
+			saved, audit = region, entry
+			return nil
+		},
+	}
+	svc := NewService(repo, validConfig())
+	ctx := auth.WithActor(context.Background(), "alice")
+
+	cases := map[string]struct {
+		code string
+		def  RegionDefinition
+	}{
+		"bad code":      {"E", RegionDefinition{Name: "Europe", Countries: []string{"DE"}}},
+		"no name":       {"EU", RegionDefinition{Countries: []string{"DE"}}},
+		"no countries":  {"EU", RegionDefinition{Name: "Europe"}},
+		"invalid code":  {"EU", RegionDefinition{Name: "Europe", Countries: []string{"DE", "XX"}}},
+		"blank country": {"EU", RegionDefinition{Name: "Europe", Countries: []string{" "}}},
+	}
+	for name, tc := range cases {
+		if _, err := svc.PutRegion(ctx, tc.code, tc.def); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("%s: expected ErrInvalidArgument, got %v", name, err)
+		}
+	}
+	if _, err := svc.PutRegion(context.Background(), "EU", RegionDefinition{Name: "Europe", Countries: []string{"DE"}}); err == nil {
+		t.Errorf("expected an error without an actor")
+	}
+
+	region, err := svc.PutRegion(ctx, "dach", RegionDefinition{Name: "DACH", Countries: []string{"de", "CH", "AT", "DE"}, Reason: "launch"})
+	if err != nil {
+		t.Fatalf("PutRegion failed: %v", err)
+	}
+	if region != saved || region.RegionCode != "DACH" || fmt.Sprint(region.Countries) != "[AT CH DE]" {
+		t.Errorf("unexpected region: %+v", region)
+	}
+	if audit.Action != AuditActionRegionUpdate || audit.Actor != "alice" || audit.Target != "region/DACH" || audit.Reason != "launch" {
+		t.Errorf("unexpected audit entry: %+v", audit)
+	}
+}
+
+func TestService_GetGeoLeaderboard(t *testing.T) {
+	repo := &mockRepo{
+		GetRegionFunc: func(ctx context.Context, regionCode string) (*model.Region, error) {
+			if regionCode != "DACH" {
+				return nil, sql.ErrNoRows
+			}
+			return &model.Region{RegionCode: "DACH", Countries: []string{"AT", "CH", "DE"}}, nil
+		},
+		GetLeaderboardByCompetitionIDFunc: func(ctx context.Context, competitionID string, viewer model.Viewer) ([]model.PlayerCompetition, error) {
+			return []model.PlayerCompetition{
+				{PlayerID: "p1", CountryCode: "US", Score: 50}, {PlayerID: "p2", CountryCode: "DE", Score: 40},
+				{PlayerID: "p3", CountryCode: "AT", Score: 30}, {PlayerID: "p4", Score: 20},
+			}, nil
+		},
+	}
+	svc := NewService(repo, validConfig())
+
+	board, err := svc.GetGeoLeaderboard(context.Background(), "c1", model.GeoFilter{RegionCode: "dach"})
+	if err != nil {
+		t.Fatalf("GetGeoLeaderboard failed: %v", err)
+	}
+	want := []GeoRank{{1, "p2", "", "", "DE", 40}, {2, "p3", "", "", "AT", 30}}
+	if board.RegionCode != "DACH" || fmt.Sprint(board.Leaderboard) != fmt.Sprint(want) {
+		t.Errorf("unexpected regional leaderboard: %+v", board)
+	}
+
+	board, err = svc.GetGeoLeaderboard(context.Background(), "c1", model.GeoFilter{CountryCode: "us"})
+	if err != nil || board.CountryCode != "US" || len(board.Leaderboard) != 1 || board.Leaderboard[0].PlayerID != "p1" {
+		t.Errorf("unexpected country leaderboard: %+v (%v)", board, err)
+	}
+
+	errCases := map[string]struct {
+		filter model.GeoFilter
+		want   error
+	}{
+		"both filters":   {model.GeoFilter{CountryCode: "DE", RegionCode: "DACH"}, ErrInvalidArgument},
+		"invalid code":   {model.GeoFilter{CountryCode: "XX"}, ErrInvalidArgument},
+		"unknown region": {model.GeoFilter{RegionCode: "MARS"}, ErrNotFound},
+	}
+	for name, tc := range errCases {
+		if _, err := svc.GetGeoLeaderboard(context.Background(), "c1", tc.filter); !errors.Is(err, tc.want) {
+			t.Errorf("%s: expected %v, got %v", name, tc.want, err)
+		}
+	}
+}
+
+func TestService_GetTopPlayers(t *testing.T) {
+	var gotFilter model.GeoFilter
+	var gotLimit int
+	repo := &mockRepo{
+		ListTopPlayersFunc: func(ctx context.Context, filter model.GeoFilter, limit int) ([]model.PlayerStats, error) {
+			gotFilter, gotLimit = filter, limit
+			return []model.PlayerStats{{PlayerID: "p2", CountryCode: "DE", TotalScore: 90}, {PlayerID: "p1", CountryCode: "DE", TotalScore: 40}}, nil
+		},
+	}
+	svc := NewService(repo, validConfig())
+
+	top, err := svc.GetTopPlayers(context.Background(), model.GeoFilter{CountryCode: "de"}, 0)
+	if err != nil {
+		t.Fatalf("GetTopPlayers failed: %v", err)
+	}
+	if gotFilter.CountryCode != "DE" || gotLimit != DefaultTopPlayers {
+		t.Errorf("unexpected query: %+v limit %d", gotFilter, gotLimit)
+	}
+	if top.CountryCode != "DE" || len(top.Leaderboard) != 2 || top.Leaderboard[1].Rank != 2 || top.Leaderboard[1].PlayerID != "p1" {
+		t.Errorf("unexpected top players: %+v", top)
+	}
+
+	for _, limit := range []int{-1, MaxTopPlayers + 1} {
+		if _, err := svc.GetTopPlayers(context.Background(), model.GeoFilter{}, limit); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("limit %d: expected ErrInvalidArgument, got %v", limit, err)
+		}
+	}
+}
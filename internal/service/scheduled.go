@@ -0,0 +1,223 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"leaderboard-service/internal/logging"
+	"leaderboard-service/internal/model"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditActionCompetitionSchedule is the audit action recorded when an admin
+// schedules a competition.
+const AuditActionCompetitionSchedule = "competition.schedule"
+
+// CompetitionSchedule describes a competition with fixed times: players can
+// register from RegistrationOpensAt, it starts at StartsAt with the
+// registered players and ends at EndsAt.
+type CompetitionSchedule struct {
+	Name string
+	// RegistrationOpensAt defaults to now.
+	RegistrationOpensAt time.Time
+	StartsAt            time.Time
+	EndsAt              time.Time
+	// MaxPlayers caps registrations; 0 means no limit.
+	MaxPlayers int
+	// Reason is recorded in the audit log.
+	Reason string
+}
+
+func (c CompetitionSchedule) validate(now time.Time) error {
+	switch {
+	case strings.TrimSpace(c.Name) == "":
+		return fmt.Errorf("%w: name is required", ErrInvalidArgument)
+	case !c.StartsAt.After(now):
+		return fmt.Errorf("%w: starts_at must be in the future", ErrInvalidArgument)
+	case !c.EndsAt.After(c.StartsAt):
+		return fmt.Errorf("%w: ends_at must be after starts_at", ErrInvalidArgument)
+	case c.RegistrationOpensAt.After(c.StartsAt):
+		return fmt.Errorf("%w: registration_opens_at must not be after starts_at", ErrInvalidArgument)
+	case c.MaxPlayers < 0:
+		return fmt.Errorf("%w: max_players must not be negative", ErrInvalidArgument)
+	}
+	return nil
+}
+
+// ScheduleCompetition creates a scheduled competition. The matchmaking
+// worker opens its registration and starts and completes it on time.
+func (s *Service) ScheduleCompetition(ctx context.Context, schedule CompetitionSchedule) (*model.Competition, error) {
+	actor, err := requireActor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if err := schedule.validate(now); err != nil {
+		return nil, err
+	}
+	opensAt := schedule.RegistrationOpensAt
+	if opensAt.IsZero() {
+		opensAt = now
+	}
+	comp := &model.Competition{
+		CompetitionID:       uuid.New(),
+		Name:                strings.TrimSpace(schedule.Name),
+		Kind:                model.CompetitionKindScheduled,
+		StartedAt:           schedule.StartsAt,
+		EndsAt:              schedule.EndsAt,
+		Status:              model.CompetitionScheduled,
+		RegistrationOpensAt: &opensAt,
+		MaxPlayers:          schedule.MaxPlayers,
+	}
+	if !opensAt.After(now) {
+		comp.Status = model.CompetitionOpen
+	}
+	entry := newAuditEntry(actor, AuditActionCompetitionSchedule, competitionTarget(comp.CompetitionID), schedule.Reason, nil, comp)
+	if err := s.repo.CreateScheduledCompetition(ctx, comp, entry); err != nil {
+		return nil, err
+	}
+	logger(ctx).Info("competition scheduled", "competition_id", comp.CompetitionID, "actor", actor, "audit_id", entry.ID,
+		"registration_opens_at", opensAt, "starts_at", comp.StartedAt, "ends_at", comp.EndsAt)
+	return comp, nil
+}
+
+// RegisterForCompetition signs playerID up for a scheduled competition whose
+// registration is open, or enters them into a running recurring competition.
+// The player must meet the competition's eligibility rules and must not have
+// been removed from or disqualified in it, nor be playing another competition
+// when entering a running one.
+func (s *Service) RegisterForCompetition(ctx context.Context, competitionID, playerID string) error {
+	player, err := s.repo.GetPlayerByID(ctx, playerID)
+	if err != nil {
+		logger(ctx).Info("player not found", "player_id", playerID)
+		return errors.New("player not found")
+	}
+	if err := s.checkSanctions(ctx, playerID); err != nil {
+		return err
+	}
+	comp, err := s.joinableCompetition(ctx, competitionID)
+	if err != nil {
+		return err
+	}
+	running := comp.Kind == model.CompetitionKindRecurring && comp.Status == model.CompetitionActive
+	if comp.Status != model.CompetitionOpen && !running {
+		logger(ctx).Info("registration not open", "competition_id", competitionID, "status", comp.Status)
+		return errors.New("registration is not open")
+	}
+	if !comp.Eligibility.Allows(player) {
+		logger(ctx).Info("player not eligible", "competition_id", competitionID, "player_id", playerID)
+		return errors.New("player not eligible")
+	}
+	pc, err := s.repo.GetCompetitionPlayer(ctx, comp.CompetitionID, playerID)
+	if err == nil && (pc.Status == model.StatusRegistered || (running && pc.Status == model.StatusActive)) {
+		return errors.New("player already registered")
+	}
+	if err == nil && (pc.Status == model.StatusRemoved || pc.Status == model.StatusDisqualified) {
+		logger(ctx).Info("excluded player cannot re-enter", "competition_id", competitionID, "player_id", playerID, "status", pc.Status)
+		return errors.New("player excluded")
+	}
+	if running {
+		// Scores go to the player's one running competition, so they may
+		// not enter a second.
+		if active, err := s.repo.GetActivePlayerCompetition(ctx, playerID); err == nil {
+			logger(ctx).Info("player already in active competition", "player_id", playerID, "competition_id", active.CompetitionID)
+			return errors.New("player already in active competition")
+		}
+	}
+	ok, err := s.repo.RegisterCompetitionPlayer(ctx, comp.CompetitionID, player)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		// Registration closed, or the last place was taken, since the
+		// checks above.
+		logger(ctx).Info("competition full", "competition_id", competitionID, "player_id", playerID)
+		return errors.New("competition is full")
+	}
+	logger(ctx).Info("player registered for competition", "competition_id", competitionID, "player_id", playerID)
+	if running {
+		s.publishMatched(comp, []string{playerID})
+	}
+	return nil
+}
+
+// UnregisterFromCompetition withdraws playerID's registration for a scheduled
+// competition that has not started.
+func (s *Service) UnregisterFromCompetition(ctx context.Context, competitionID, playerID string) error {
+	if _, err := s.repo.GetPlayerByID(ctx, playerID); err != nil {
+		logger(ctx).Info("player not found", "player_id", playerID)
+		return errors.New("player not found")
+	}
+	comp, err := s.joinableCompetition(ctx, competitionID)
+	if err != nil {
+		return err
+	}
+	ok, err := s.repo.CancelCompetitionRegistration(ctx, comp.CompetitionID, playerID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		logger(ctx).Info("player not registered", "competition_id", competitionID, "player_id", playerID)
+		return errors.New("player not registered")
+	}
+	logger(ctx).Info("player unregistered from competition", "competition_id", competitionID, "player_id", playerID)
+	return nil
+}
+
+// joinableCompetition loads a scheduled or recurring competition, the kinds
+// players enter by ID.
+func (s *Service) joinableCompetition(ctx context.Context, competitionID string) (*model.Competition, error) {
+	if _, err := uuid.Parse(competitionID); err != nil {
+		return nil, errors.New("leaderboard not found")
+	}
+	comp, err := s.repo.GetCompetitionByID(ctx, competitionID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && (comp.Kind == model.CompetitionKindMatchmaking || comp.Kind == model.CompetitionKindTournament)) {
+		logger(ctx).Info("joinable competition not found", "competition_id", competitionID)
+		return nil, errors.New("leaderboard not found")
+	}
+	if err != nil {
+		logger(ctx).Error("error fetching competition", "competition_id", competitionID, "error", err)
+		return nil, err
+	}
+	return comp, nil
+}
+
+// advanceScheduled opens registration for and starts scheduled competitions
+// whose time has come, telling each registered player their competition has
+// started.
+func (s *Service) advanceScheduled(ctx context.Context) error {
+	opened, err := s.repo.OpenScheduledCompetitions(ctx)
+	if err != nil {
+		workerLogger(ctx).Error("error opening scheduled competitions", "error", err)
+		return err
+	}
+	for _, id := range opened {
+		workerLogger(ctx).Info("registration opened", "competition_id", id)
+	}
+
+	started, err := s.repo.StartScheduledCompetitions(ctx)
+	if err != nil {
+		workerLogger(ctx).Error("error starting scheduled competitions", "error", err)
+		return err
+	}
+	for i := range started {
+		comp := &started[i]
+		ctx := logging.With(ctx, "competition_id", comp.CompetitionID)
+		pcs, err := s.repo.GetLeaderboardByCompetitionID(ctx, comp.CompetitionID.String(), model.Viewer{All: true})
+		if err != nil {
+			workerLogger(ctx).Error("error fetching scheduled competition players", "error", err)
+			continue
+		}
+		playerIDs := make([]string, len(pcs))
+		for i, pc := range pcs {
+			playerIDs[i] = pc.PlayerID
+		}
+		workerLogger(ctx).Info("started scheduled competition", "name", comp.Name, "players", playerIDs)
+		s.publishMatched(comp, playerIDs)
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"leaderboard-service/internal/auth"
+	"leaderboard-service/internal/model"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestService_ScheduleCompetition(t *testing.T) {
+	var created *model.Competition
+	var audited *model.AuditEntry
+	repo := &mockRepo{CreateScheduledCompetitionFunc: func(ctx context.Context, comp *model.Competition, audit *model.AuditEntry) error {
+		created, audited = comp, audit
+		return nil
+	}}
+	svc := NewService(repo, validConfig())
+	ctx := auth.WithActor(context.Background(), "alice")
+	start := time.Now().Add(24 * time.Hour)
+
+	comp, err := svc.ScheduleCompetition(ctx, CompetitionSchedule{Name: " Weekend Cup ", StartsAt: start, EndsAt: start.Add(48 * time.Hour), MaxPlayers: 100})
+	if err != nil {
+		t.Fatalf("ScheduleCompetition failed: %v", err)
+	}
+	if comp != created || comp.Name != "Weekend Cup" || comp.Kind != model.CompetitionKindScheduled || comp.Status != model.CompetitionOpen {
+		t.Errorf("expected an open scheduled competition, got %+v", comp)
+	}
+	if audited.Action != AuditActionCompetitionSchedule || audited.Before != nil || audited.After == nil {
+		t.Errorf("unexpected audit entry: %+v", audited)
+	}
+
+	opens := start.Add(-time.Hour)
+	comp, err = svc.ScheduleCompetition(ctx, CompetitionSchedule{Name: "Later", RegistrationOpensAt: opens, StartsAt: start, EndsAt: start.Add(time.Hour)})
+	if err != nil || comp.Status != model.CompetitionScheduled || !comp.RegistrationOpensAt.Equal(opens) {
+		t.Errorf("expected registration to open later, got %+v, %v", comp, err)
+	}
+
+	for name, schedule := range map[string]CompetitionSchedule{
+		"no name":         {StartsAt: start, EndsAt: start.Add(time.Hour)},
+		"past start":      {Name: "x", StartsAt: time.Now().Add(-time.Minute), EndsAt: start},
+		"ends first":      {Name: "x", StartsAt: start, EndsAt: start},
+		"opens too late":  {Name: "x", RegistrationOpensAt: start.Add(time.Minute), StartsAt: start, EndsAt: start.Add(time.Hour)},
+		"negative places": {Name: "x", StartsAt: start, EndsAt: start.Add(time.Hour), MaxPlayers: -1},
+	} {
+		if _, err := svc.ScheduleCompetition(ctx, schedule); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("%s: expected ErrInvalidArgument, got %v", name, err)
+		}
+	}
+}
+
+func TestService_RegisterForCompetition(t *testing.T) {
+	comp := &model.Competition{CompetitionID: uuid.New(), Kind: model.CompetitionKindScheduled, Status: model.CompetitionOpen}
+	repo := activeCompetitionRepo(comp)
+	repo.GetPlayerByIDFunc = func(ctx context.Context, playerID string) (*model.Player, error) {
+		if playerID == "ghost" {
+			return nil, errors.New("no rows")
+		}
+		return &model.Player{PlayerID: playerID, Level: 3}, nil
+	}
+	repo.GetCompetitionPlayerFunc = func(ctx context.Context, competitionID uuid.UUID, playerID string) (*model.PlayerCompetition, error) {
+		switch playerID {
+		case "p-registered":
+			return &model.PlayerCompetition{PlayerID: playerID, Status: model.StatusRegistered}, nil
+		case "p-disqualified":
+			return &model.PlayerCompetition{PlayerID: playerID, Status: model.StatusDisqualified}, nil
+		}
+		return nil, errors.New("no rows")
+	}
+	repo.RegisterCompetitionPlayerFunc = func(ctx context.Context, competitionID uuid.UUID, player *model.Player) (bool, error) {
+		return player.PlayerID != "p-late", nil
+	}
+	svc := NewService(repo, validConfig())
+	id := comp.CompetitionID.String()
+
+	cases := map[string]string{
+		"p1":             "",
+		"ghost":          "player not found",
+		"p-registered":   "player already registered",
+		"p-disqualified": "player excluded",
+		"p-late":         "competition is full",
+	}
+	for playerID, want := range cases {
+		err := svc.RegisterForCompetition(context.Background(), id, playerID)
+		if (want == "" && err != nil) || (want != "" && (err == nil || err.Error() != want)) {
+			t.Errorf("%s: expected %q, got %v", playerID, want, err)
+		}
+	}
+	if err := svc.RegisterForCompetition(context.Background(), uuid.NewString(), "p1"); err == nil || err.Error() != "leaderboard not found" {
+		t.Errorf("expected leaderboard not found, got %v", err)
+	}
+	comp.Status = model.CompetitionActive
+	if err := svc.RegisterForCompetition(context.Background(), id, "p1"); err == nil || err.Error() != "registration is not open" {
+		t.Errorf("expected registration is not open, got %v", err)
+	}
+}
+
+func TestService_RunMatchmaking_StartsScheduledCompetitions(t *testing.T) {
+	comp := model.Competition{CompetitionID: uuid.New(), Kind: model.CompetitionKindScheduled, Name: "Weekend Cup", Status: model.CompetitionActive}
+	repo := &mockRepo{
+		StartScheduledCompetitionsFunc: func(ctx context.Context) ([]model.Competition, error) {
+			return []model.Competition{comp}, nil
+		},
+		GetLeaderboardByCompetitionIDFunc: func(ctx context.Context, competitionID string, viewer model.Viewer) ([]model.PlayerCompetition, error) {
+			return []model.PlayerCompetition{{PlayerID: "p1"}, {PlayerID: "p2"}}, nil
+		},
+		GetWaitingPlayersFunc: func(ctx context.Context, limit int) ([]model.PlayerCompetition, error) {
+			return nil, nil
+		},
+	}
+	svc := NewService(repo, validConfig())
+	sub := svc.Hub().Subscribe(playerTopic("p2"), 0)
+	defer sub.Close()
+
+	if err := svc.runMatchmaking(context.Background()); err != nil {
+		t.Fatalf("runMatchmaking failed: %v", err)
+	}
+	select {
+	case ev := <-sub.C:
+		data := ev.Data.(map[string]interface{})
+		if ev.Type != EventMatched || data["leaderboard_id"] != comp.CompetitionID.String() {
+			t.Errorf("unexpected event: %+v", ev)
+		}
+	default:
+		t.Error("expected a matched event for p2")
+	}
+}
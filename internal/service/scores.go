@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"leaderboard-service/internal/model"
+	"strings"
+)
+
+// AuditActionScoreAdjust is the audit action recorded for admin score
+// corrections.
+const AuditActionScoreAdjust = "competition.adjust_score"
+
+// maxAdjustAttempts bounds how often AdjustScore retries when the score
+// changes under it, e.g. because the player is still submitting.
+const maxAdjustAttempts = 3
+
+// ScoreAdjustment corrects a player's score in a competition. Exactly one of
+// Score, which sets it, and Delta, which adds to it, must be given.
+type ScoreAdjustment struct {
+	Score *int
+	Delta *int
+	// Reason is required and recorded in the audit log.
+	Reason string
+}
+
+// ScoreChange describes an applied score adjustment. Ranks are positions
+// in the competition's leaderboard after the change.
+type ScoreChange struct {
+	LeaderboardID string `json:"leaderboard_id"`
+	PlayerID      string `json:"player_id"`
+	PreviousScore int    `json:"previous_score"`
+	Score         int    `json:"score"`
+	PreviousRank  int    `json:"previous_rank"`
+	Rank          int    `json:"rank"`
+	AuditID       int64  `json:"audit_id,omitempty"`
+}
+
+func (a ScoreAdjustment) validate() error {
+	switch {
+	case (a.Score == nil) == (a.Delta == nil):
+		return fmt.Errorf("%w: exactly one of score and delta is required", ErrInvalidArgument)
+	case strings.TrimSpace(a.Reason) == "":
+		return fmt.Errorf("%w: a reason is required for score adjustments", ErrInvalidArgument)
+	}
+	return nil
+}
+
+func (a ScoreAdjustment) apply(score int) int {
+	if a.Score != nil {
+		return *a.Score
+	}
+	return score + *a.Delta
+}
+
+// AdjustScore sets or adjusts playerID's score in a competition, active or
+// completed. The change and its audit entry are written together; players
+// following the competition get a score event with the new ranks and the
+// player is notified of the correction.
+func (s *Service) AdjustScore(ctx context.Context, competitionID, playerID string, adjustment ScoreAdjustment) (*ScoreChange, error) {
+	actor, err := requireActor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := adjustment.validate(); err != nil {
+		return nil, err
+	}
+	comp, err := s.loadCompetition(ctx, competitionID)
+	if err != nil {
+		return nil, err
+	}
+
+	type scoreState struct {
+		Score int `json:"score"`
+	}
+	for attempt := 1; attempt <= maxAdjustAttempts; attempt++ {
+		pc, err := s.repo.GetCompetitionPlayer(ctx, comp.CompetitionID, playerID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: player not in competition", ErrNotFound)
+		}
+		if err != nil {
+			logger(ctx).Error("error fetching competition player", "competition_id", competitionID, "player_id", playerID, "error", err)
+			return nil, err
+		}
+		if pc.Status != model.StatusActive && pc.Status != model.StatusCompleted {
+			return nil, fmt.Errorf("%w: player entry is %s", ErrConflict, pc.Status)
+		}
+		change := &ScoreChange{LeaderboardID: competitionID, PlayerID: playerID, PreviousScore: pc.Score, Score: adjustment.apply(pc.Score)}
+		if change.Score == change.PreviousScore {
+			logger(ctx).Debug("score unchanged", "competition_id", competitionID, "player_id", playerID, "actor", actor)
+			return s.rankScoreChange(ctx, change), nil
+		}
+
+		entry := newAuditEntry(actor, AuditActionScoreAdjust, competitionTarget(comp.CompetitionID)+"/player/"+playerID,
+			adjustment.Reason, scoreState{change.PreviousScore}, scoreState{change.Score})
+		ok, err := s.repo.SetCompetitionScore(ctx, comp.CompetitionID, playerID, change.PreviousScore, change.Score, entry)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			logger(ctx).Debug("score changed during adjustment, retrying", "competition_id", competitionID, "player_id", playerID, "attempt", attempt)
+			continue
+		}
+		change.AuditID = entry.ID
+		logger(ctx).Info("score adjusted", "competition_id", competitionID, "player_id", playerID, "actor", actor,
+			"audit_id", entry.ID, "from", change.PreviousScore, "to", change.Score)
+		s.rankScoreChange(ctx, change)
+		s.publishScoreChange(ctx, comp, change, adjustment.Reason)
+		return change, nil
+	}
+	return nil, fmt.Errorf("%w: score kept changing, retry", ErrConflict)
+}
+
+// rankScoreChange fills in the ranks of change from the current leaderboard.
+func (s *Service) rankScoreChange(ctx context.Context, change *ScoreChange) *ScoreChange {
+	pcs, err := s.repo.GetLeaderboardByCompetitionID(ctx, change.LeaderboardID, model.Viewer{PlayerID: change.PlayerID})
+	if err != nil {
+		logger(ctx).Error("error fetching leaderboard for ranks", "competition_id", change.LeaderboardID, "error", err)
+		return change
+	}
+	change.Rank = rankOf(pcs, change.PlayerID, change.Score)
+	change.PreviousRank = rankOf(pcs, change.PlayerID, change.PreviousScore)
+	return change
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"leaderboard-service/internal/auth"
+	"leaderboard-service/internal/model"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestService_AdjustScore(t *testing.T) {
+	comp := &model.Competition{CompetitionID: uuid.New(), Status: model.CompetitionActive}
+	id := comp.CompetitionID.String()
+	repo := activeCompetitionRepo(comp)
+	// p1 submits a score between the first read and the first write.
+	scores := []int{50, 80}
+	repo.GetCompetitionPlayerFunc = func(ctx context.Context, competitionID uuid.UUID, playerID string) (*model.PlayerCompetition, error) {
+		score := scores[0]
+		if len(scores) > 1 {
+			scores = scores[1:]
+		}
+		return &model.PlayerCompetition{PlayerID: playerID, CompetitionID: &competitionID, Status: model.StatusActive, Score: score}, nil
+	}
+	var audited *model.AuditEntry
+	repo.SetCompetitionScoreFunc = func(ctx context.Context, competitionID uuid.UUID, playerID string, from, to int, audit *model.AuditEntry) (bool, error) {
+		if from != 80 {
+			return false, nil
+		}
+		audit.ID = 42
+		audited = audit
+		return true, nil
+	}
+	repo.GetLeaderboardByCompetitionIDFunc = func(ctx context.Context, competitionID string, viewer model.Viewer) ([]model.PlayerCompetition, error) {
+		return []model.PlayerCompetition{{PlayerID: "p1", Score: 70}, {PlayerID: "p2", Score: 75}}, nil
+	}
+	svc := NewService(repo, validConfig())
+	compSub := svc.Hub().Subscribe(competitionTopic(id), 0)
+	defer compSub.Close()
+	playerSub := svc.Hub().Subscribe(playerTopic("p1"), 0)
+	defer playerSub.Close()
+
+	delta := -10
+	change, err := svc.AdjustScore(auth.WithActor(context.Background(), "alice"), id, "p1", ScoreAdjustment{Delta: &delta, Reason: "duplicate submission"})
+	if err != nil {
+		t.Fatalf("AdjustScore failed: %v", err)
+	}
+	want := ScoreChange{LeaderboardID: id, PlayerID: "p1", PreviousScore: 80, Score: 70, PreviousRank: 1, Rank: 2, AuditID: 42}
+	if *change != want {
+		t.Errorf("expected %+v, got %+v", want, *change)
+	}
+	if audited.Action != AuditActionScoreAdjust || audited.Reason != "duplicate submission" ||
+		string(audited.Before) != `{"score":80}` || string(audited.After) != `{"score":70}` {
+		t.Errorf("unexpected audit entry: %+v", audited)
+	}
+	for _, tc := range []struct {
+		sub  *Subscription
+		typ  string
+		rank int
+	}{{compSub, EventScore, 2}, {playerSub, EventScoreAdjusted, 2}} {
+		select {
+		case ev := <-tc.sub.C:
+			if data := ev.Data.(map[string]interface{}); ev.Type != tc.typ || data["rank"] != tc.rank || data["score"] != 70 {
+				t.Errorf("unexpected %s event: %+v", tc.typ, ev)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("expected a %s event", tc.typ)
+		}
+	}
+}
+
+func TestService_AdjustScore_ShadowBanned(t *testing.T) {
+	comp := &model.Competition{CompetitionID: uuid.New(), Status: model.CompetitionActive}
+	id := comp.CompetitionID.String()
+	repo := activeCompetitionRepo(comp)
+	repo.GetCompetitionPlayerFunc = func(ctx context.Context, competitionID uuid.UUID, playerID string) (*model.PlayerCompetition, error) {
+		return &model.PlayerCompetition{PlayerID: playerID, CompetitionID: &competitionID, Status: model.StatusActive, Score: 50}, nil
+	}
+	repo.SetCompetitionScoreFunc = func(ctx context.Context, competitionID uuid.UUID, playerID string, from, to int, audit *model.AuditEntry) (bool, error) {
+		return true, nil
+	}
+	// p1 is shadow-banned: only they see themselves on the leaderboard.
+	repo.GetLeaderboardByCompetitionIDFunc = func(ctx context.Context, competitionID string, viewer model.Viewer) ([]model.PlayerCompetition, error) {
+		if viewer.All || viewer.PlayerID == "p1" {
+			return []model.PlayerCompetition{{PlayerID: "p1", Score: 60}, {PlayerID: "p2", Score: 55}}, nil
+		}
+		return []model.PlayerCompetition{{PlayerID: "p2", Score: 55}}, nil
+	}
+	svc := NewService(repo, validConfig())
+	compSub := svc.Hub().Subscribe(competitionTopic(id), 0)
+	defer compSub.Close()
+	playerSub := svc.Hub().Subscribe(playerTopic("p1"), 0)
+	defer playerSub.Close()
+
+	delta := 10
+	if _, err := svc.AdjustScore(auth.WithActor(context.Background(), "alice"), id, "p1", ScoreAdjustment{Delta: &delta, Reason: "bonus"}); err != nil {
+		t.Fatalf("AdjustScore failed: %v", err)
+	}
+	select {
+	case ev := <-playerSub.C:
+		if data := ev.Data.(map[string]interface{}); ev.Type != EventScoreAdjusted || data["rank"] != 1 {
+			t.Errorf("unexpected player event: %+v", ev)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected a score_adjusted event")
+	}
+	if len(compSub.C) != 0 {
+		t.Errorf("expected no public event for a shadow-banned player, got %+v", <-compSub.C)
+	}
+}
+
+func TestService_AdjustScore_Rejected(t *testing.T) {
+	comp := &model.Competition{CompetitionID: uuid.New(), Status: model.CompetitionCompleted}
+	repo := activeCompetitionRepo(comp)
+	repo.GetCompetitionPlayerFunc = func(ctx context.Context, competitionID uuid.UUID, playerID string) (*model.PlayerCompetition, error) {
+		return &model.PlayerCompetition{PlayerID: playerID, Status: model.StatusDisqualified}, nil
+	}
+	repo.SetCompetitionScoreFunc = func(ctx context.Context, competitionID uuid.UUID, playerID string, from, to int, audit *model.AuditEntry) (bool, error) {
+		t.Error("expected no score change")
+		return false, nil
+	}
+	svc := NewService(repo, validConfig())
+	ctx := auth.WithActor(context.Background(), "alice")
+	id := comp.CompetitionID.String()
+	score, delta := 10, 5
+	cases := []struct {
+		name       string
+		adjustment ScoreAdjustment
+		want       error
+	}{
+		{"no reason", ScoreAdjustment{Score: &score}, ErrInvalidArgument},
+		{"score and delta", ScoreAdjustment{Score: &score, Delta: &delta, Reason: "x"}, ErrInvalidArgument},
+		{"neither", ScoreAdjustment{Reason: "x"}, ErrInvalidArgument},
+		{"excluded entry", ScoreAdjustment{Score: &score, Reason: "x"}, ErrConflict},
+	}
+	for _, tc := range cases {
+		if _, err := svc.AdjustScore(ctx, id, "p1", tc.adjustment); !errors.Is(err, tc.want) {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.want, err)
+		}
+	}
+}
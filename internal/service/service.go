@@ -2,11 +2,15 @@ package service
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
+	"leaderboard-service/internal/logging"
 	"leaderboard-service/internal/model"
 	"leaderboard-service/internal/repository"
-	"log"
+	"log/slog"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
@@ -15,93 +19,275 @@ import (
 type Config struct {
 	MatchmakingInterval time.Duration
 	CompetitionDuration time.Duration
+	// MinGroupSize is the fewest waiting players that start a competition;
+	// NewService raises values below 2 to 2.
+	MinGroupSize int
+	// MaxGroupSize caps how many of the longest-waiting players each pass
+	// considers, and so the size of a competition; 0 means no limit.
+	MaxGroupSize int
+	// PromotePercent and RelegatePercent are the shares of a matchmaking
+	// competition's players, from the top and the bottom of its final
+	// leaderboard, who move up or down a tier when it completes.
+	PromotePercent  int
+	RelegatePercent int
+	// PartyLevel is how a party's level is rated from its members' for
+	// level-based matching; NewService defaults it to MAX.
+	PartyLevel model.PartyLevel
 }
 
 type Service struct {
-	repo   repository.RepositoryInterface
-	config Config
+	repo repository.RepositoryInterface
+	hub  *Hub
+	tick TickFunc
+	// nameFilters vet new display names; see UseNameFilters.
+	nameFilters []NameFilter
+
+	// config is replaced as a whole by UpdateConfig, which configMu
+	// serializes; configChanged wakes the worker to pick up a new interval.
+	config        atomic.Pointer[Config]
+	configMu      sync.Mutex
+	configChanged chan struct{}
 }
 
+// TickFunc runs one matchmaking pass.
+type TickFunc func(ctx context.Context) error
+
+// TickMiddleware wraps every matchmaking pass, e.g. to instrument it.
+type TickMiddleware func(next TickFunc) TickFunc
+
 type ServiceInterface interface {
 	Join(ctx context.Context, playerID string) (string, error)
+	Leave(ctx context.Context, playerID string) error
 	SubmitScore(ctx context.Context, playerID string, score int) error
 	GetPlayerLeaderboard(ctx context.Context, playerID string) (interface{}, error)
 	GetLeaderboard(ctx context.Context, leaderboardID string) (interface{}, error)
-	CreatePlayer(ctx context.Context, playerID string, level int, countryCode string) error
+	CreatePlayer(ctx context.Context, playerID string, profile PlayerProfile) (*model.Player, error)
 	GetPlayer(ctx context.Context, playerID string) (*model.Player, error)
-	UpdatePlayer(ctx context.Context, playerID string, level int, countryCode string) error
+	UpdatePlayer(ctx context.Context, playerID string, profile PlayerProfile) (*model.Player, error)
+	SubscribeLeaderboard(ctx context.Context, leaderboardID string, lastEventID uint64) (*Subscription, error)
+	SubscribePlayer(ctx context.Context, playerID string) (*Subscription, error)
+
+	GetConfig(ctx context.Context) (Config, error)
+	UpdateConfig(ctx context.Context, update ConfigUpdate) (Config, error)
+	ListAuditEntries(ctx context.Context, filter model.AuditFilter) ([]model.AuditEntry, error)
+
+	ListCompetitions(ctx context.Context, filter model.CompetitionFilter) ([]model.Competition, error)
+	CancelCompetition(ctx context.Context, competitionID, reason string) (*model.Competition, error)
+	CompleteCompetition(ctx context.Context, competitionID, reason string) (*model.Competition, error)
+	SetCompetitionEndsAt(ctx context.Context, competitionID string, endsAt time.Time, reason string) (*model.Competition, error)
+	ExcludePlayer(ctx context.Context, competitionID, playerID string, status model.PlayerStatus, reason string) error
+	AdjustScore(ctx context.Context, competitionID, playerID string, adjustment ScoreAdjustment) (*ScoreChange, error)
+	ScheduleCompetition(ctx context.Context, schedule CompetitionSchedule) (*model.Competition, error)
+	RegisterForCompetition(ctx context.Context, competitionID, playerID string) error
+	UnregisterFromCompetition(ctx context.Context, competitionID, playerID string) error
+
+	ListTemplates(ctx context.Context) ([]model.CompetitionTemplate, error)
+	GetTemplate(ctx context.Context, templateID string) (*model.CompetitionTemplate, error)
+	CreateTemplate(ctx context.Context, def TemplateDefinition) (*model.CompetitionTemplate, error)
+	UpdateTemplate(ctx context.Context, templateID string, def TemplateDefinition) (*model.CompetitionTemplate, error)
+	DeleteTemplate(ctx context.Context, templateID, reason string) error
+	PreviewTemplate(ctx context.Context, templateID string, count int) ([]Occurrence, error)
+
+	GetLeagueHistory(ctx context.Context, playerID string) ([]model.TierMovement, error)
+	GetPlayerHistory(ctx context.Context, playerID string, filter model.PlayerHistoryFilter) (*PlayerHistory, error)
+	DeletePlayer(ctx context.Context, playerID string) error
+	ExportPlayerData(ctx context.Context, playerID string) (*PlayerExport, error)
+	GetModeration(ctx context.Context, playerID string) (*model.Moderation, error)
+	ApplySanction(ctx context.Context, playerID string, sanction model.Sanction, until time.Time, reason string) (*model.Moderation, error)
+	LiftSanction(ctx context.Context, playerID string, sanction model.Sanction, reason string) (*model.Moderation, error)
+
+	CreateTournament(ctx context.Context, def TournamentDefinition) (*model.Tournament, error)
+	GetTournament(ctx context.Context, tournamentID string) (*Bracket, error)
+
+	CreateParty(ctx context.Context, playerID string) (*model.Party, error)
+	GetParty(ctx context.Context, partyID string) (*model.Party, error)
+	InviteToParty(ctx context.Context, partyID, playerID, inviteeID string) error
+	AcceptPartyInvite(ctx context.Context, partyID, playerID string) (*model.Party, error)
+	LeaveParty(ctx context.Context, partyID, playerID string) error
+	QueueParty(ctx context.Context, partyID, playerID string) error
+	ListFriends(ctx context.Context, playerID string) ([]string, error)
+	AddFriend(ctx context.Context, playerID, friendID string) error
+	ImportFriends(ctx context.Context, playerID string, friendIDs []string) (*FriendImport, error)
+	RemoveFriend(ctx context.Context, playerID, friendID string) error
+	GetFriendsLeaderboard(ctx context.Context, leaderboardID, playerID string) (*FriendsLeaderboard, error)
+	GetFriendsStats(ctx context.Context, playerID string) (*FriendsStats, error)
+	ListRegions(ctx context.Context) ([]model.Region, error)
+	PutRegion(ctx context.Context, regionCode string, def RegionDefinition) (*model.Region, error)
+	DeleteRegion(ctx context.Context, regionCode, reason string) error
+	GetGeoLeaderboard(ctx context.Context, leaderboardID string, filter model.GeoFilter) (*GeoLeaderboard, error)
+	GetTopPlayers(ctx context.Context, filter model.GeoFilter, limit int) (*TopPlayers, error)
 }
 
 func NewService(repo repository.RepositoryInterface, config Config) *Service {
-	return &Service{repo: repo, config: config}
+	if config.MinGroupSize < 2 {
+		config.MinGroupSize = 2
+	}
+	if config.PartyLevel == "" {
+		config.PartyLevel = model.PartyLevelMax
+	}
+	s := &Service{repo: repo, hub: NewHub(), configChanged: make(chan struct{}, 1)}
+	s.config.Store(&config)
+	s.tick = s.runMatchmaking
+	return s
+}
+
+// logger returns the request-scoped logger for service messages.
+func logger(ctx context.Context) *slog.Logger {
+	return logging.FromContext(ctx).With("component", "service")
+}
+
+// workerLogger returns the logger for the matchmaking worker; during a tick
+// it carries the tick ID and, once chosen, the competition ID.
+func workerLogger(ctx context.Context) *slog.Logger {
+	return logging.FromContext(ctx).With("component", "matchmaking")
+}
+
+// UseTickMiddleware wraps the matchmaking pass run by the worker. Middleware
+// added later runs outermost. It must be called before the worker starts.
+func (s *Service) UseTickMiddleware(mw ...TickMiddleware) {
+	for _, m := range mw {
+		s.tick = m(s.tick)
+	}
 }
 
-func (s *Service) StartMatchmakingWorker(ctx context.Context) {
+// StartMatchmakingWorker runs matchmaking every MatchmakingInterval until ctx
+// is cancelled. A tick that is in progress when ctx is cancelled runs to
+// completion; the returned channel is closed once the worker has exited.
+// When UpdateConfig changes the interval the ticker is reset to it.
+func (s *Service) StartMatchmakingWorker(ctx context.Context) <-chan struct{} {
+	done := make(chan struct{})
 	go func() {
-		ticker := time.NewTicker(s.config.MatchmakingInterval)
+		defer close(done)
+		interval := s.currentConfig().MatchmakingInterval
+		ticker := time.NewTicker(interval)
 		defer ticker.Stop()
-		log.Println("[MatchmakingWorker] Started")
+		var tickID uint64
+		workerLogger(ctx).Info("worker started", "interval", interval)
 		for {
 			select {
 			case <-ctx.Done():
-				log.Println("[MatchmakingWorker] Stopped")
+				workerLogger(ctx).Info("worker stopped")
 				return
+			case <-s.configChanged:
+				if next := s.currentConfig().MatchmakingInterval; next != interval {
+					workerLogger(ctx).Info("matchmaking interval changed", "from", interval, "to", next)
+					interval = next
+					ticker.Reset(interval)
+				}
 			case <-ticker.C:
-				s.runMatchmaking(ctx)
+				tickID++
+				s.tick(logging.With(context.WithoutCancel(ctx), "tick_id", tickID))
 			}
 		}
 	}()
+	return done
 }
 
-func (s *Service) runMatchmaking(ctx context.Context) {
+// runMatchmaking advances scheduled and recurring competitions, completes
+// finished ones, settling their leagues, moves tournaments on to their next
+// round and starts at most one new matchmaking competition. It returns the
+// first error that stopped or disrupted the pass.
+func (s *Service) runMatchmaking(ctx context.Context) error {
+	config := s.currentConfig()
+
+	// 0. Open registration for and start scheduled competitions, and start
+	// the due occurrences of recurring ones
+	passErr := s.advanceScheduled(ctx)
+	if err := s.advanceTemplates(ctx); err != nil && passErr == nil {
+		passErr = err
+	}
+
 	// 1. Mark finished competitions as COMPLETED
-	if err := s.repo.CompleteFinishedCompetitions(ctx); err != nil {
-		log.Printf("[MatchmakingWorker] Error completing finished competitions: %v", err)
+	completed, err := s.repo.CompleteFinishedCompetitions(ctx)
+	if err != nil {
+		workerLogger(ctx).Error("error completing finished competitions", "error", err)
+		if passErr == nil {
+			passErr = err
+		}
+	}
+	for _, compID := range completed {
+		s.publishFinished(logging.With(ctx, "competition_id", compID), compID.String(), model.CompetitionCompleted)
+	}
+	// Promote and relegate the players of completed league competitions,
+	// picking up any a previous pass failed to settle
+	if err := s.settleLeagues(ctx); err != nil && passErr == nil {
+		passErr = err
+	}
+	// Start the next round of tournaments whose round is over
+	if err := s.advanceTournaments(ctx); err != nil && passErr == nil {
+		passErr = err
 	}
 
 	// Check for existing active competition
 	activeComp, err := s.repo.GetActiveCompetition(ctx)
 	if err == nil && activeComp != nil {
-		log.Printf("[MatchmakingWorker] Active competition %s already exists, skipping creation", activeComp.CompetitionID.String())
-		return
+		workerLogger(ctx).Debug("active competition already exists, skipping creation", "competition_id", activeComp.CompetitionID)
+		return passErr
 	}
 
-	// 2. Fetch all waiting players
-	waitingPlayers, err := s.repo.GetWaitingPlayers(ctx)
+	// 2. Fetch the head of each tier's queue
+	waitingPlayers, err := s.repo.GetWaitingPlayers(ctx, config.MaxGroupSize)
 	if err != nil {
-		log.Printf("[MatchmakingWorker] Error fetching waiting players: %v", err)
-		return
+		workerLogger(ctx).Error("error fetching waiting players", "error", err)
+		return err
 	}
-	if len(waitingPlayers) < 2 {
-		log.Println("[MatchmakingWorker] Not enough players waiting")
-		return
+	minSize := config.MinGroupSize
+	if len(waitingPlayers) < minSize {
+		workerLogger(ctx).Debug("not enough players waiting", "waiting", len(waitingPlayers))
+		return passErr
 	}
 
-	// 3. Try to find the best group to match
-	var bestGroup []model.PlayerCompetition
+	// 3. A party is matched as a unit, never split, and groups are filled
+	// with whole units up to the maximum group size. Players only compete
+	// within their league tier: take the first tier, in waiting order, with
+	// enough players
+	units := queueUnits(waitingPlayers, config.PartyLevel)
+	tierGroups := make(map[model.Tier][]queueUnit)
+	var tiers []model.Tier
+	for _, u := range units {
+		if _, ok := tierGroups[u.tier]; !ok {
+			tiers = append(tiers, u.tier)
+		}
+		tierGroups[u.tier] = append(tierGroups[u.tier], u)
+	}
+	var tier model.Tier
+	var candidates []queueUnit
+	for _, t := range tiers {
+		if playerCount(fitUnits(tierGroups[t], config.MaxGroupSize)) >= minSize {
+			tier, candidates = t, tierGroups[t]
+			break
+		}
+	}
+	if candidates == nil {
+		workerLogger(ctx).Debug("not enough players waiting in any tier", "waiting", len(waitingPlayers), "tiers", len(tiers))
+		return passErr
+	}
+
+	// 4. Try to find the best group to match within the tier
+	var bestGroup []queueUnit
 	var matchType string
 
-	// 3a. Level-based matching
-	levelGroups := make(map[int][]model.PlayerCompetition)
-	for _, p := range waitingPlayers {
-		levelGroups[p.Level] = append(levelGroups[p.Level], p)
+	// 4a. Level-based matching
+	levelGroups := make(map[int][]queueUnit)
+	for _, u := range candidates {
+		levelGroups[u.level] = append(levelGroups[u.level], u)
 	}
 	for level, group := range levelGroups {
-		if len(group) >= 2 {
+		if group = fitUnits(group, config.MaxGroupSize); playerCount(group) >= minSize {
 			bestGroup = group
 			matchType = fmt.Sprintf("level %d", level)
 			break
 		}
 	}
 
-	// 3b. Country-based matching (if no level group found)
+	// 4b. Country-based matching (if no level group found)
 	if len(bestGroup) == 0 {
-		countryGroups := make(map[string][]model.PlayerCompetition)
-		for _, p := range waitingPlayers {
-			countryGroups[p.CountryCode] = append(countryGroups[p.CountryCode], p)
+		countryGroups := make(map[string][]queueUnit)
+		for _, u := range candidates {
+			countryGroups[u.countryCode] = append(countryGroups[u.countryCode], u)
 		}
 		for country, group := range countryGroups {
-			if len(group) >= 2 {
+			if group = fitUnits(group, config.MaxGroupSize); playerCount(group) >= minSize {
 				bestGroup = group
 				matchType = fmt.Sprintf("country %s", country)
 				break
@@ -109,61 +295,76 @@ func (s *Service) runMatchmaking(ctx context.Context) {
 		}
 	}
 
-	// 3c. Fallback: all waiting players
+	// 4c. Fallback: all waiting players in the tier
 	if len(bestGroup) == 0 {
-		bestGroup = waitingPlayers
-		matchType = "fallback (all waiting players)"
+		bestGroup = fitUnits(candidates, config.MaxGroupSize)
+		matchType = "fallback (all waiting players in tier)"
 	}
 
-	// 4. Create the competition for the best group
+	// 5. Create the competition for the best group
 
 	compID := uuid.New()
+	ctx = logging.With(ctx, "competition_id", compID)
 	now := time.Now()
-	endsAt := now.Add(s.config.CompetitionDuration)
+	endsAt := now.Add(config.CompetitionDuration)
 	comp := &model.Competition{
 		CompetitionID: compID,
+		Kind:          model.CompetitionKindMatchmaking,
 		StartedAt:     now,
 		EndsAt:        endsAt,
-		Level:         bestGroup[0].Level,
-		CountryCode:   bestGroup[0].CountryCode,
+		Level:         bestGroup[0].level,
+		CountryCode:   bestGroup[0].countryCode,
 		Status:        model.CompetitionActive,
+		Tier:          tier,
 	}
 	if err := s.repo.CreateCompetition(ctx, comp); err != nil {
-		log.Printf("[MatchmakingWorker] Error creating competition: %v", err)
-		return
+		workerLogger(ctx).Error("error creating competition", "error", err)
+		return err
 	}
-	playerIDs := make([]string, len(bestGroup))
-	for i, p := range bestGroup {
-		playerIDs[i] = p.PlayerID
+	var playerIDs []string
+	for _, u := range bestGroup {
+		for _, p := range u.entries {
+			playerIDs = append(playerIDs, p.PlayerID)
+		}
 	}
 	if err := s.repo.UpdatePlayerCompetitionsToActive(ctx, playerIDs, compID, endsAt); err != nil {
-		log.Printf("[MatchmakingWorker] Error updating player competitions: %v", err)
-		return
+		workerLogger(ctx).Error("error updating player competitions", "error", err)
+		return err
 	}
-	log.Printf("[MatchmakingWorker] Started competition %s (%s) with players: %v", compID.String(), matchType, playerIDs)
+	workerLogger(ctx).Info("started competition", "match_type", matchType, "tier", tier, "players", playerIDs)
+	s.publishMatched(comp, playerIDs)
+	return passErr
 }
 
 func (s *Service) Join(ctx context.Context, playerID string) (string, error) {
-	log.Printf("[Service] Player %s attempting to join matchmaking", playerID)
+	logger(ctx).Debug("player attempting to join matchmaking", "player_id", playerID)
 	player, err := s.repo.GetPlayerByID(ctx, playerID)
 	if err != nil {
-		log.Printf("[Service] Player %s not found", playerID)
+		logger(ctx).Info("player not found", "player_id", playerID)
 		return "", errors.New("player not found")
 	}
+	if err := s.checkSanctions(ctx, playerID); err != nil {
+		return "", err
+	}
 	_, err = s.repo.GetActivePlayerCompetition(ctx, playerID)
 	if err == nil {
-		log.Printf("[Service] Player %s already in active competition", playerID)
+		logger(ctx).Info("player already in active competition", "player_id", playerID)
 		return "", errors.New("player already in active competition")
 	}
 	inQueue, err := s.repo.IsPlayerInWaitingQueue(ctx, playerID)
 	if err != nil {
-		log.Printf("[Service] Error checking waiting queue for player %s: %v", playerID, err)
+		logger(ctx).Error("error checking waiting queue", "player_id", playerID, "error", err)
 		return "", err
 	}
 	if inQueue {
-		log.Printf("[Service] Player %s is already in the waiting queue", playerID)
+		logger(ctx).Info("player already in waiting queue", "player_id", playerID)
 		return "", errors.New("player already in waiting queue")
 	}
+	// A party member queues with the party, through its leader
+	if _, err := s.repo.GetPlayerParty(ctx, playerID); err == nil {
+		logger(ctx).Info("player in a party", "player_id", playerID)
+		return "", errors.New("player in a party")
+	}
 	pc := &model.PlayerCompetition{
 		PlayerID:      playerID,
 		CompetitionID: nil,
@@ -173,40 +374,57 @@ func (s *Service) Join(ctx context.Context, playerID string) (string, error) {
 		UpdatedAt:     time.Now(),
 		Level:         player.Level,
 		CountryCode:   player.CountryCode,
+		Tier:          player.Tier,
 	}
 	err = s.repo.CreatePlayerCompetition(ctx, pc)
 	if err != nil {
-		log.Printf("[Service] Error adding player %s to matchmaking queue: %v", playerID, err)
+		logger(ctx).Error("error adding player to matchmaking queue", "player_id", playerID, "error", err)
 		return "", err
 	}
-	log.Printf("[Service] Player %s added to matchmaking queue", playerID)
+	logger(ctx).Info("player added to matchmaking queue", "player_id", playerID)
 	return "", nil
 }
 
+func (s *Service) Leave(ctx context.Context, playerID string) error {
+	logger(ctx).Debug("player attempting to leave matchmaking", "player_id", playerID)
+	if _, err := s.repo.GetPlayerByID(ctx, playerID); err != nil {
+		logger(ctx).Info("player not found", "player_id", playerID)
+		return errors.New("player not found")
+	}
+	removed, err := s.repo.CancelWaitingPlayerCompetition(ctx, playerID)
+	if err != nil {
+		logger(ctx).Error("error removing player from matchmaking queue", "player_id", playerID, "error", err)
+		return err
+	}
+	if !removed {
+		logger(ctx).Info("player not in waiting queue", "player_id", playerID)
+		return errors.New("player not in waiting queue")
+	}
+	logger(ctx).Info("player removed from matchmaking queue", "player_id", playerID)
+	return nil
+}
+
 func (s *Service) GetPlayerLeaderboard(ctx context.Context, playerID string) (interface{}, error) {
-	log.Printf("[Service] Fetching leaderboard for player %s", playerID)
+	logger(ctx).Debug("fetching leaderboard for player", "player_id", playerID)
 	pc, err := s.repo.GetLatestPlayerCompetition(ctx, playerID)
 	if err != nil {
-		log.Printf("[Service] No competition found for player %s", playerID)
+		logger(ctx).Debug("no competition found for player", "player_id", playerID)
 		return map[string]interface{}{}, nil
 	}
 	if pc.CompetitionID == nil {
-		log.Printf("[Service] No competition ID for player %s", playerID)
+		logger(ctx).Debug("player has no competition yet", "player_id", playerID)
 		return map[string]interface{}{}, nil
 	}
-	leaderboard, err := s.repo.GetLeaderboardByCompetitionID(ctx, pc.CompetitionID.String())
+	leaderboard, err := s.repo.GetLeaderboardByCompetitionID(ctx, pc.CompetitionID.String(), model.Viewer{PlayerID: playerID})
 	if err != nil {
-		log.Printf("[Service] Error fetching leaderboard for competition %v: %v", pc.CompetitionID, err)
+		logger(ctx).Error("error fetching leaderboard", "competition_id", pc.CompetitionID, "error", err)
 		return nil, err
 	}
 	entries := make([]map[string]interface{}, 0, len(leaderboard))
 	for _, entry := range leaderboard {
-		entries = append(entries, map[string]interface{}{
-			"player_id": entry.PlayerID,
-			"score":     entry.Score,
-		})
+		entries = append(entries, leaderboardEntry(entry))
 	}
-	log.Printf("[Service] Returning leaderboard for competition %v", pc.CompetitionID)
+	logger(ctx).Debug("returning leaderboard", "player_id", playerID, "competition_id", pc.CompetitionID)
 	return map[string]interface{}{
 		"leaderboard_id": pc.CompetitionID.String(),
 		"ends_at":        pc.UpdatedAt.Unix(),
@@ -215,18 +433,15 @@ func (s *Service) GetPlayerLeaderboard(ctx context.Context, playerID string) (in
 }
 
 func (s *Service) GetLeaderboard(ctx context.Context, leaderboardID string) (interface{}, error) {
-	log.Printf("[Service] Fetching leaderboard for competition %s", leaderboardID)
-	pcs, err := s.repo.GetLeaderboardByCompetitionID(ctx, leaderboardID)
+	logger(ctx).Debug("fetching leaderboard", "competition_id", leaderboardID)
+	pcs, err := s.repo.GetLeaderboardByCompetitionID(ctx, leaderboardID, model.Viewer{})
 	if err != nil || len(pcs) == 0 {
-		log.Printf("[Service] No leaderboard found for competition %s", leaderboardID)
+		logger(ctx).Info("no leaderboard found", "competition_id", leaderboardID)
 		return nil, errors.New("leaderboard not found")
 	}
 	entries := make([]map[string]interface{}, 0, len(pcs))
 	for _, entry := range pcs {
-		entries = append(entries, map[string]interface{}{
-			"player_id": entry.PlayerID,
-			"score":     entry.Score,
-		})
+		entries = append(entries, leaderboardEntry(entry))
 	}
 	return map[string]interface{}{
 		"leaderboard_id": leaderboardID,
@@ -235,65 +450,103 @@ func (s *Service) GetLeaderboard(ctx context.Context, leaderboardID string) (int
 }
 
 func (s *Service) SubmitScore(ctx context.Context, playerID string, score int) error {
-	log.Printf("[Service] Submitting score for player %s", playerID)
+	logger(ctx).Debug("submitting score", "player_id", playerID, "score", score)
 	// Check if player exists
 	_, err := s.repo.GetPlayerByID(ctx, playerID)
 	if err != nil {
-		log.Printf("[Service] Player %s not found when submitting score", playerID)
+		logger(ctx).Info("player not found when submitting score", "player_id", playerID)
 		return errors.New("player not found")
 	}
+	if err := s.checkSanctions(ctx, playerID); err != nil {
+		return err
+	}
 	pc, err := s.repo.GetActivePlayerCompetition(ctx, playerID)
 	if err != nil {
-		log.Printf("[Service] Player %s not in active competition", playerID)
+		logger(ctx).Info("player not in active competition", "player_id", playerID)
 		return errors.New("player not in active competition")
 	}
 	err = s.repo.AddScoreToPlayer(ctx, playerID, score)
 	if err != nil {
-		log.Printf("[Service] Error adding score for player %s: %v", playerID, err)
+		logger(ctx).Error("error adding score", "player_id", playerID, "error", err)
 		return err
 	}
-	log.Printf("[Service] Score %d added to player %s in competition %v", score, playerID, pc.CompetitionID)
+	logger(ctx).Info("score added", "player_id", playerID, "score", score, "competition_id", pc.CompetitionID)
+	if pc.CompetitionID != nil {
+		if delta := s.scoreDelta(ctx, pc, score); delta != 0 {
+			s.publishScore(ctx, pc.CompetitionID.String(), playerID, delta)
+		}
+	}
 	return nil
 }
 
-func (s *Service) CreatePlayer(ctx context.Context, playerID string, level int, countryCode string) error {
-	player := &model.Player{
-		PlayerID:    playerID,
-		Level:       level,
-		CountryCode: countryCode,
+// scoreDelta is how much submitting score changed pc's score: all of it,
+// unless the competition only keeps the best submission.
+func (s *Service) scoreDelta(ctx context.Context, pc *model.PlayerCompetition, score int) int {
+	comp, err := s.repo.GetCompetitionByID(ctx, pc.CompetitionID.String())
+	if err != nil || comp.ScoringMode != model.ScoringBest {
+		return score
+	}
+	return max(score-pc.Score, 0)
+}
+
+// CreatePlayer creates a player in the lowest tier with profile, whose
+// version is ignored.
+func (s *Service) CreatePlayer(ctx context.Context, playerID string, profile PlayerProfile) (*model.Player, error) {
+	if err := checkPlayerID(playerID); err != nil {
+		return nil, err
+	}
+	player := &model.Player{PlayerID: playerID, Tier: model.Tiers[0]}
+	if err := s.applyProfile(ctx, player, profile); err != nil {
+		return nil, err
 	}
 	err := s.repo.CreatePlayer(ctx, player)
 	if err != nil {
-		log.Printf("[Service] Error creating player %s: %v", playerID, err)
-		return err
+		logger(ctx).Error("error creating player", "player_id", playerID, "error", err)
+		return nil, saveError(err)
 	}
-	log.Printf("[Service] Successfully created player %s", playerID)
-	return nil
+	logger(ctx).Info("created player", "player_id", playerID)
+	return player, nil
 }
 
 func (s *Service) GetPlayer(ctx context.Context, playerID string) (*model.Player, error) {
 	player, err := s.repo.GetPlayerByID(ctx, playerID)
 	if err != nil {
-		log.Printf("[Service] Error fetching player %s: %v", playerID, err)
+		logger(ctx).Info("error fetching player", "player_id", playerID, "error", err)
 		return nil, err
 	}
-	log.Printf("[Service] Successfully fetched player %s", playerID)
+	logger(ctx).Debug("fetched player", "player_id", playerID)
 	return player, nil
 }
 
-func (s *Service) UpdatePlayer(ctx context.Context, playerID string, level int, countryCode string) error {
+// UpdatePlayer sets profile on a player and returns the player with its new
+// version. The update is made against the version read here, or
+// profile.Version if given, and fails with ErrPreconditionFailed if the
+// player changed in between.
+func (s *Service) UpdatePlayer(ctx context.Context, playerID string, profile PlayerProfile) (*model.Player, error) {
 	player, err := s.repo.GetPlayerByID(ctx, playerID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: player not found", ErrNotFound)
+	}
 	if err != nil {
-		log.Printf("[Service] Error fetching player %s for update: %v", playerID, err)
-		return err
+		logger(ctx).Info("error fetching player for update", "player_id", playerID, "error", err)
+		return nil, err
+	}
+	if profile.Version != 0 && profile.Version != player.Version {
+		logger(ctx).Info("player update against stale version", "player_id", playerID, "version", profile.Version, "current_version", player.Version)
+		return nil, fmt.Errorf("%w: player is at version %d", ErrPreconditionFailed, player.Version)
+	}
+	if err := s.applyProfile(ctx, player, profile); err != nil {
+		return nil, err
 	}
-	player.Level = level
-	player.CountryCode = countryCode
 	err = s.repo.UpdatePlayer(ctx, player)
+	if errors.Is(err, sql.ErrNoRows) {
+		logger(ctx).Info("player changed during update", "player_id", playerID, "version", player.Version)
+		return nil, fmt.Errorf("%w: player changed since version %d", ErrPreconditionFailed, player.Version)
+	}
 	if err != nil {
-		log.Printf("[Service] Error updating player %s: %v", playerID, err)
-		return err
+		logger(ctx).Error("error updating player", "player_id", playerID, "error", err)
+		return nil, saveError(err)
 	}
-	log.Printf("[Service] Successfully updated player %s", playerID)
-	return nil
+	logger(ctx).Info("updated player", "player_id", playerID, "version", player.Version)
+	return player, nil
 }
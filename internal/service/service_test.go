@@ -1,11 +1,16 @@
 package service
 
 import (
+	"bytes"
 	"context"
+	"database/sql"
 	"errors"
+	"log/slog"
+	"strings"
 	"testing"
 	"time"
 
+	"leaderboard-service/internal/logging"
 	"leaderboard-service/internal/model"
 	"leaderboard-service/internal/repository"
 
@@ -14,15 +19,238 @@ import (
 
 type mockRepo struct {
 	repository.RepositoryInterface
-	GetPlayerByIDFunc                 func(ctx context.Context, playerID string) (*model.Player, error)
-	GetActivePlayerCompetitionFunc    func(ctx context.Context, playerID string) (*model.PlayerCompetition, error)
-	IsPlayerInWaitingQueueFunc        func(ctx context.Context, playerID string) (bool, error)
-	CreatePlayerCompetitionFunc       func(ctx context.Context, pc *model.PlayerCompetition) error
-	AddScoreToPlayerFunc              func(ctx context.Context, playerID string, score int) error
-	GetLeaderboardByCompetitionIDFunc func(ctx context.Context, competitionID string) ([]model.PlayerCompetition, error)
-	GetLatestPlayerCompetitionFunc    func(ctx context.Context, playerID string) (*model.PlayerCompetition, error)
-	CreatePlayerFunc                  func(ctx context.Context, player *model.Player) error
-	UpdatePlayerFunc                  func(ctx context.Context, player *model.Player) error
+	GetPlayerByIDFunc                    func(ctx context.Context, playerID string) (*model.Player, error)
+	GetActivePlayerCompetitionFunc       func(ctx context.Context, playerID string) (*model.PlayerCompetition, error)
+	IsPlayerInWaitingQueueFunc           func(ctx context.Context, playerID string) (bool, error)
+	CreatePlayerCompetitionFunc          func(ctx context.Context, pc *model.PlayerCompetition) error
+	AddScoreToPlayerFunc                 func(ctx context.Context, playerID string, score int) error
+	GetLeaderboardByCompetitionIDFunc    func(ctx context.Context, competitionID string, viewer model.Viewer) ([]model.PlayerCompetition, error)
+	GetLatestPlayerCompetitionFunc       func(ctx context.Context, playerID string) (*model.PlayerCompetition, error)
+	CreatePlayerFunc                     func(ctx context.Context, player *model.Player) error
+	UpdatePlayerFunc                     func(ctx context.Context, player *model.Player) error
+	GetCompetitionByIDFunc               func(ctx context.Context, competitionID string) (*model.Competition, error)
+	CompleteFinishedCompetitionsFunc     func(ctx context.Context) ([]uuid.UUID, error)
+	GetActiveCompetitionFunc             func(ctx context.Context) (*model.Competition, error)
+	GetWaitingPlayersFunc                func(ctx context.Context, limit int) ([]model.PlayerCompetition, error)
+	CreateCompetitionFunc                func(ctx context.Context, comp *model.Competition) error
+	CancelWaitingPlayerCompetitionFunc   func(ctx context.Context, playerID string) (bool, error)
+	UpdatePlayerCompetitionsToActiveFunc func(ctx context.Context, playerIDs []string, competitionID uuid.UUID, endsAt time.Time) error
+	CreateAuditEntryFunc                 func(ctx context.Context, entry *model.AuditEntry) error
+	ListAuditEntriesFunc                 func(ctx context.Context, filter model.AuditFilter) ([]model.AuditEntry, error)
+	GetPlayerModerationFunc              func(ctx context.Context, playerID string) (*model.Moderation, error)
+	SetPlayerSanctionFunc                func(ctx context.Context, playerID string, sanction model.Sanction, value *time.Time, audit *model.AuditEntry) (bool, error)
+	GetCompetitionPlayerFunc             func(ctx context.Context, competitionID uuid.UUID, playerID string) (*model.PlayerCompetition, error)
+	CancelCompetitionFunc                func(ctx context.Context, competitionID uuid.UUID, audit *model.AuditEntry) (bool, error)
+	SetCompetitionEndsAtFunc             func(ctx context.Context, competitionID uuid.UUID, endsAt time.Time, audit *model.AuditEntry) (bool, error)
+	ExcludeCompetitionPlayerFunc         func(ctx context.Context, competitionID uuid.UUID, playerID string, status model.PlayerStatus, audit *model.AuditEntry) (bool, error)
+	SetCompetitionScoreFunc              func(ctx context.Context, competitionID uuid.UUID, playerID string, from, to int, audit *model.AuditEntry) (bool, error)
+	CreateScheduledCompetitionFunc       func(ctx context.Context, comp *model.Competition, audit *model.AuditEntry) error
+	StartScheduledCompetitionsFunc       func(ctx context.Context) ([]model.Competition, error)
+	RegisterCompetitionPlayerFunc        func(ctx context.Context, competitionID uuid.UUID, player *model.Player) (bool, error)
+	GetCompetitionTemplateFunc           func(ctx context.Context, templateID uuid.UUID) (*model.CompetitionTemplate, error)
+	CreateCompetitionTemplateFunc        func(ctx context.Context, tpl *model.CompetitionTemplate, audit *model.AuditEntry) error
+	UpdateCompetitionTemplateFunc        func(ctx context.Context, tpl *model.CompetitionTemplate, lastUpdated time.Time, audit *model.AuditEntry) (bool, error)
+	DueCompetitionTemplatesFunc          func(ctx context.Context) ([]model.CompetitionTemplate, error)
+	StartTemplateOccurrenceFunc          func(ctx context.Context, templateID uuid.UUID, due time.Time, next *time.Time, comp *model.Competition) ([]uuid.UUID, bool, error)
+	UnsettledLeagueCompetitionsFunc      func(ctx context.Context) ([]model.Competition, error)
+	ApplyTierMovementsFunc               func(ctx context.Context, competitionID uuid.UUID, moves []model.TierMovement) ([]model.TierMovement, error)
+	ListTierHistoryFunc                  func(ctx context.Context, playerID string, limit int) ([]model.TierMovement, error)
+	CreateTournamentFunc                 func(ctx context.Context, t *model.Tournament, matches []model.TournamentMatch, comps []model.Competition, audit *model.AuditEntry) error
+	GetTournamentFunc                    func(ctx context.Context, tournamentID uuid.UUID) (*model.Tournament, error)
+	ActiveTournamentsFunc                func(ctx context.Context) ([]model.Tournament, error)
+	ListTournamentMatchesFunc            func(ctx context.Context, tournamentID uuid.UUID) ([]model.TournamentMatch, error)
+	StartTournamentRoundFunc             func(ctx context.Context, tournamentID uuid.UUID, round int, matches []model.TournamentMatch, comps []model.Competition) (bool, error)
+	CompleteTournamentFunc               func(ctx context.Context, tournamentID uuid.UUID, round int, winners []string) (bool, error)
+	CreatePartyFunc                      func(ctx context.Context, party *model.Party) (bool, error)
+	GetPartyFunc                         func(ctx context.Context, partyID uuid.UUID) (*model.Party, error)
+	GetPlayerPartyFunc                   func(ctx context.Context, playerID string) (*model.Party, error)
+	InvitePartyPlayerFunc                func(ctx context.Context, partyID uuid.UUID, playerID string) (bool, error)
+	AcceptPartyInviteFunc                func(ctx context.Context, partyID uuid.UUID, playerID string, maxSize int) (bool, error)
+	LeavePartyFunc                       func(ctx context.Context, partyID uuid.UUID, playerID string) (bool, error)
+	QueuePartyFunc                       func(ctx context.Context, partyID uuid.UUID, entries []model.PlayerCompetition) (bool, error)
+	AddFriendsFunc                       func(ctx context.Context, playerID string, friendIDs []string) ([]string, error)
+	RemoveFriendFunc                     func(ctx context.Context, playerID, friendID string) (bool, error)
+	ListFriendsFunc                      func(ctx context.Context, playerID string) ([]string, error)
+	GetPlayerStatsFunc                   func(ctx context.Context, playerIDs []string, viewer model.Viewer) ([]model.PlayerStats, error)
+	ListRegionsFunc                      func(ctx context.Context) ([]model.Region, error)
+	GetRegionFunc                        func(ctx context.Context, regionCode string) (*model.Region, error)
+	PutRegionFunc                        func(ctx context.Context, region *model.Region, audit *model.AuditEntry) error
+	DeleteRegionFunc                     func(ctx context.Context, regionCode string, audit *model.AuditEntry) (bool, error)
+	ListTopPlayersFunc                   func(ctx context.Context, filter model.GeoFilter, limit int) ([]model.PlayerStats, error)
+	ListPlayerHistoryFunc                func(ctx context.Context, playerID string, filter model.PlayerHistoryFilter) ([]model.CompetitionResult, error)
+	GetPlayerHistorySummaryFunc          func(ctx context.Context, playerID string, filter model.PlayerHistoryFilter) (*model.PlayerHistorySummary, error)
+	AnonymizePlayerFunc                  func(ctx context.Context, playerID, alias string) (bool, error)
+	PurgeAnonymizedPlayersFunc           func(ctx context.Context, cutoff time.Time) (int, error)
+	ListPlayerEntriesFunc                func(ctx context.Context, playerID string) ([]model.PlayerCompetition, error)
+}
+
+func (m *mockRepo) CreateScheduledCompetition(ctx context.Context, comp *model.Competition, audit *model.AuditEntry) error {
+	return m.CreateScheduledCompetitionFunc(ctx, comp, audit)
+}
+func (m *mockRepo) OpenScheduledCompetitions(ctx context.Context) ([]uuid.UUID, error) {
+	return nil, nil
+}
+func (m *mockRepo) StartScheduledCompetitions(ctx context.Context) ([]model.Competition, error) {
+	if m.StartScheduledCompetitionsFunc != nil {
+		return m.StartScheduledCompetitionsFunc(ctx)
+	}
+	return nil, nil
+}
+func (m *mockRepo) RegisterCompetitionPlayer(ctx context.Context, competitionID uuid.UUID, player *model.Player) (bool, error) {
+	return m.RegisterCompetitionPlayerFunc(ctx, competitionID, player)
+}
+func (m *mockRepo) GetCompetitionTemplate(ctx context.Context, templateID uuid.UUID) (*model.CompetitionTemplate, error) {
+	return m.GetCompetitionTemplateFunc(ctx, templateID)
+}
+func (m *mockRepo) CreateCompetitionTemplate(ctx context.Context, tpl *model.CompetitionTemplate, audit *model.AuditEntry) error {
+	return m.CreateCompetitionTemplateFunc(ctx, tpl, audit)
+}
+func (m *mockRepo) UpdateCompetitionTemplate(ctx context.Context, tpl *model.CompetitionTemplate, lastUpdated time.Time, audit *model.AuditEntry) (bool, error) {
+	return m.UpdateCompetitionTemplateFunc(ctx, tpl, lastUpdated, audit)
+}
+func (m *mockRepo) DueCompetitionTemplates(ctx context.Context) ([]model.CompetitionTemplate, error) {
+	if m.DueCompetitionTemplatesFunc != nil {
+		return m.DueCompetitionTemplatesFunc(ctx)
+	}
+	return nil, nil
+}
+func (m *mockRepo) StartTemplateOccurrence(ctx context.Context, templateID uuid.UUID, due time.Time, next *time.Time, comp *model.Competition) ([]uuid.UUID, bool, error) {
+	return m.StartTemplateOccurrenceFunc(ctx, templateID, due, next, comp)
+}
+func (m *mockRepo) UnsettledLeagueCompetitions(ctx context.Context) ([]model.Competition, error) {
+	if m.UnsettledLeagueCompetitionsFunc != nil {
+		return m.UnsettledLeagueCompetitionsFunc(ctx)
+	}
+	return nil, nil
+}
+func (m *mockRepo) ApplyTierMovements(ctx context.Context, competitionID uuid.UUID, moves []model.TierMovement) ([]model.TierMovement, error) {
+	return m.ApplyTierMovementsFunc(ctx, competitionID, moves)
+}
+func (m *mockRepo) ListAuditEntries(ctx context.Context, filter model.AuditFilter) ([]model.AuditEntry, error) {
+	return m.ListAuditEntriesFunc(ctx, filter)
+}
+func (m *mockRepo) GetPlayerModeration(ctx context.Context, playerID string) (*model.Moderation, error) {
+	if m.GetPlayerModerationFunc != nil {
+		return m.GetPlayerModerationFunc(ctx, playerID)
+	}
+	return &model.Moderation{PlayerID: playerID}, nil
+}
+func (m *mockRepo) SetPlayerSanction(ctx context.Context, playerID string, sanction model.Sanction, value *time.Time, audit *model.AuditEntry) (bool, error) {
+	return m.SetPlayerSanctionFunc(ctx, playerID, sanction, value, audit)
+}
+func (m *mockRepo) ListTierHistory(ctx context.Context, playerID string, limit int) ([]model.TierMovement, error) {
+	return m.ListTierHistoryFunc(ctx, playerID, limit)
+}
+func (m *mockRepo) CreateTournament(ctx context.Context, t *model.Tournament, matches []model.TournamentMatch, comps []model.Competition, audit *model.AuditEntry) error {
+	return m.CreateTournamentFunc(ctx, t, matches, comps, audit)
+}
+func (m *mockRepo) GetTournament(ctx context.Context, tournamentID uuid.UUID) (*model.Tournament, error) {
+	return m.GetTournamentFunc(ctx, tournamentID)
+}
+func (m *mockRepo) ActiveTournaments(ctx context.Context) ([]model.Tournament, error) {
+	if m.ActiveTournamentsFunc != nil {
+		return m.ActiveTournamentsFunc(ctx)
+	}
+	return nil, nil
+}
+func (m *mockRepo) ListTournamentMatches(ctx context.Context, tournamentID uuid.UUID) ([]model.TournamentMatch, error) {
+	return m.ListTournamentMatchesFunc(ctx, tournamentID)
+}
+func (m *mockRepo) StartTournamentRound(ctx context.Context, tournamentID uuid.UUID, round int, matches []model.TournamentMatch, comps []model.Competition) (bool, error) {
+	return m.StartTournamentRoundFunc(ctx, tournamentID, round, matches, comps)
+}
+func (m *mockRepo) CompleteTournament(ctx context.Context, tournamentID uuid.UUID, round int, winners []string) (bool, error) {
+	return m.CompleteTournamentFunc(ctx, tournamentID, round, winners)
+}
+func (m *mockRepo) CreateParty(ctx context.Context, party *model.Party) (bool, error) {
+	return m.CreatePartyFunc(ctx, party)
+}
+func (m *mockRepo) GetParty(ctx context.Context, partyID uuid.UUID) (*model.Party, error) {
+	return m.GetPartyFunc(ctx, partyID)
+}
+func (m *mockRepo) GetPlayerParty(ctx context.Context, playerID string) (*model.Party, error) {
+	if m.GetPlayerPartyFunc != nil {
+		return m.GetPlayerPartyFunc(ctx, playerID)
+	}
+	return nil, sql.ErrNoRows
+}
+func (m *mockRepo) InvitePartyPlayer(ctx context.Context, partyID uuid.UUID, playerID string) (bool, error) {
+	return m.InvitePartyPlayerFunc(ctx, partyID, playerID)
+}
+func (m *mockRepo) AcceptPartyInvite(ctx context.Context, partyID uuid.UUID, playerID string, maxSize int) (bool, error) {
+	return m.AcceptPartyInviteFunc(ctx, partyID, playerID, maxSize)
+}
+func (m *mockRepo) LeaveParty(ctx context.Context, partyID uuid.UUID, playerID string) (bool, error) {
+	return m.LeavePartyFunc(ctx, partyID, playerID)
+}
+func (m *mockRepo) QueueParty(ctx context.Context, partyID uuid.UUID, entries []model.PlayerCompetition) (bool, error) {
+	return m.QueuePartyFunc(ctx, partyID, entries)
+}
+func (m *mockRepo) AddFriends(ctx context.Context, playerID string, friendIDs []string) ([]string, error) {
+	return m.AddFriendsFunc(ctx, playerID, friendIDs)
+}
+func (m *mockRepo) RemoveFriend(ctx context.Context, playerID, friendID string) (bool, error) {
+	return m.RemoveFriendFunc(ctx, playerID, friendID)
+}
+func (m *mockRepo) ListFriends(ctx context.Context, playerID string) ([]string, error) {
+	return m.ListFriendsFunc(ctx, playerID)
+}
+func (m *mockRepo) GetPlayerStats(ctx context.Context, playerIDs []string, viewer model.Viewer) ([]model.PlayerStats, error) {
+	return m.GetPlayerStatsFunc(ctx, playerIDs, viewer)
+}
+func (m *mockRepo) ListRegions(ctx context.Context) ([]model.Region, error) {
+	return m.ListRegionsFunc(ctx)
+}
+func (m *mockRepo) GetRegion(ctx context.Context, regionCode string) (*model.Region, error) {
+	return m.GetRegionFunc(ctx, regionCode)
+}
+func (m *mockRepo) PutRegion(ctx context.Context, region *model.Region, audit *model.AuditEntry) error {
+	return m.PutRegionFunc(ctx, region, audit)
+}
+func (m *mockRepo) DeleteRegion(ctx context.Context, regionCode string, audit *model.AuditEntry) (bool, error) {
+	return m.DeleteRegionFunc(ctx, regionCode, audit)
+}
+func (m *mockRepo) ListTopPlayers(ctx context.Context, filter model.GeoFilter, limit int) ([]model.PlayerStats, error) {
+	return m.ListTopPlayersFunc(ctx, filter, limit)
+}
+func (m *mockRepo) ListPlayerHistory(ctx context.Context, playerID string, filter model.PlayerHistoryFilter) ([]model.CompetitionResult, error) {
+	return m.ListPlayerHistoryFunc(ctx, playerID, filter)
+}
+func (m *mockRepo) GetPlayerHistorySummary(ctx context.Context, playerID string, filter model.PlayerHistoryFilter) (*model.PlayerHistorySummary, error) {
+	return m.GetPlayerHistorySummaryFunc(ctx, playerID, filter)
+}
+func (m *mockRepo) AnonymizePlayer(ctx context.Context, playerID, alias string) (bool, error) {
+	return m.AnonymizePlayerFunc(ctx, playerID, alias)
+}
+func (m *mockRepo) PurgeAnonymizedPlayers(ctx context.Context, cutoff time.Time) (int, error) {
+	return m.PurgeAnonymizedPlayersFunc(ctx, cutoff)
+}
+func (m *mockRepo) ListPlayerEntries(ctx context.Context, playerID string) ([]model.PlayerCompetition, error) {
+	return m.ListPlayerEntriesFunc(ctx, playerID)
+}
+
+func (m *mockRepo) SetCompetitionScore(ctx context.Context, competitionID uuid.UUID, playerID string, from, to int, audit *model.AuditEntry) (bool, error) {
+	return m.SetCompetitionScoreFunc(ctx, competitionID, playerID, from, to, audit)
+}
+
+func (m *mockRepo) GetCompetitionPlayer(ctx context.Context, competitionID uuid.UUID, playerID string) (*model.PlayerCompetition, error) {
+	return m.GetCompetitionPlayerFunc(ctx, competitionID, playerID)
+}
+func (m *mockRepo) CancelCompetition(ctx context.Context, competitionID uuid.UUID, audit *model.AuditEntry) (bool, error) {
+	return m.CancelCompetitionFunc(ctx, competitionID, audit)
+}
+func (m *mockRepo) SetCompetitionEndsAt(ctx context.Context, competitionID uuid.UUID, endsAt time.Time, audit *model.AuditEntry) (bool, error) {
+	return m.SetCompetitionEndsAtFunc(ctx, competitionID, endsAt, audit)
+}
+func (m *mockRepo) ExcludeCompetitionPlayer(ctx context.Context, competitionID uuid.UUID, playerID string, status model.PlayerStatus, audit *model.AuditEntry) (bool, error) {
+	return m.ExcludeCompetitionPlayerFunc(ctx, competitionID, playerID, status, audit)
+}
+
+func (m *mockRepo) CreateAuditEntry(ctx context.Context, entry *model.AuditEntry) error {
+	if m.CreateAuditEntryFunc != nil {
+		return m.CreateAuditEntryFunc(ctx, entry)
+	}
+	return nil
 }
 
 func (m *mockRepo) GetPlayerByID(ctx context.Context, playerID string) (*model.Player, error) {
@@ -43,9 +271,9 @@ func (m *mockRepo) AddScoreToPlayer(ctx context.Context, playerID string, score
 	}
 	return nil
 }
-func (m *mockRepo) GetLeaderboardByCompetitionID(ctx context.Context, competitionID string) ([]model.PlayerCompetition, error) {
+func (m *mockRepo) GetLeaderboardByCompetitionID(ctx context.Context, competitionID string, viewer model.Viewer) ([]model.PlayerCompetition, error) {
 	if m.GetLeaderboardByCompetitionIDFunc != nil {
-		return m.GetLeaderboardByCompetitionIDFunc(ctx, competitionID)
+		return m.GetLeaderboardByCompetitionIDFunc(ctx, competitionID, viewer)
 	}
 	return nil, nil
 }
@@ -67,6 +295,48 @@ func (m *mockRepo) UpdatePlayer(ctx context.Context, player *model.Player) error
 	}
 	return nil
 }
+func (m *mockRepo) GetCompetitionByID(ctx context.Context, competitionID string) (*model.Competition, error) {
+	if m.GetCompetitionByIDFunc != nil {
+		return m.GetCompetitionByIDFunc(ctx, competitionID)
+	}
+	return nil, errors.New("not found")
+}
+func (m *mockRepo) CompleteFinishedCompetitions(ctx context.Context) ([]uuid.UUID, error) {
+	if m.CompleteFinishedCompetitionsFunc != nil {
+		return m.CompleteFinishedCompetitionsFunc(ctx)
+	}
+	return nil, nil
+}
+func (m *mockRepo) GetActiveCompetition(ctx context.Context) (*model.Competition, error) {
+	if m.GetActiveCompetitionFunc != nil {
+		return m.GetActiveCompetitionFunc(ctx)
+	}
+	return nil, errors.New("not found")
+}
+func (m *mockRepo) GetWaitingPlayers(ctx context.Context, limit int) ([]model.PlayerCompetition, error) {
+	if m.GetWaitingPlayersFunc != nil {
+		return m.GetWaitingPlayersFunc(ctx, limit)
+	}
+	return nil, nil
+}
+func (m *mockRepo) CreateCompetition(ctx context.Context, comp *model.Competition) error {
+	if m.CreateCompetitionFunc != nil {
+		return m.CreateCompetitionFunc(ctx, comp)
+	}
+	return nil
+}
+func (m *mockRepo) UpdatePlayerCompetitionsToActive(ctx context.Context, playerIDs []string, competitionID uuid.UUID, endsAt time.Time) error {
+	if m.UpdatePlayerCompetitionsToActiveFunc != nil {
+		return m.UpdatePlayerCompetitionsToActiveFunc(ctx, playerIDs, competitionID, endsAt)
+	}
+	return nil
+}
+func (m *mockRepo) CancelWaitingPlayerCompetition(ctx context.Context, playerID string) (bool, error) {
+	if m.CancelWaitingPlayerCompetitionFunc != nil {
+		return m.CancelWaitingPlayerCompetitionFunc(ctx, playerID)
+	}
+	return false, nil
+}
 
 func TestService_Join_PlayerNotFound(t *testing.T) {
 	repo := &mockRepo{
@@ -240,7 +510,7 @@ func TestService_GetPlayerLeaderboard_LeaderboardFetchError(t *testing.T) {
 			id := uuid.New()
 			return &model.PlayerCompetition{CompetitionID: &id}, nil
 		},
-		GetLeaderboardByCompetitionIDFunc: func(ctx context.Context, competitionID string) ([]model.PlayerCompetition, error) {
+		GetLeaderboardByCompetitionIDFunc: func(ctx context.Context, competitionID string, viewer model.Viewer) ([]model.PlayerCompetition, error) {
 			return nil, errors.New("db error")
 		},
 	}
@@ -258,7 +528,7 @@ func TestService_GetPlayerLeaderboard_Success(t *testing.T) {
 		GetLatestPlayerCompetitionFunc: func(ctx context.Context, playerID string) (*model.PlayerCompetition, error) {
 			return &model.PlayerCompetition{CompetitionID: &compID, UpdatedAt: time.Unix(123, 0)}, nil
 		},
-		GetLeaderboardByCompetitionIDFunc: func(ctx context.Context, competitionID string) ([]model.PlayerCompetition, error) {
+		GetLeaderboardByCompetitionIDFunc: func(ctx context.Context, competitionID string, viewer model.Viewer) ([]model.PlayerCompetition, error) {
 			return entries, nil
 		},
 	}
@@ -280,7 +550,7 @@ func TestService_CreatePlayer_Error(t *testing.T) {
 		},
 	}
 	svc := NewService(repo, Config{})
-	err := svc.CreatePlayer(context.Background(), "p1", 1, "US")
+	_, err := svc.CreatePlayer(context.Background(), "p1", PlayerProfile{Level: intPtr(1), CountryCode: strPtr("US")})
 	if err == nil {
 		t.Errorf("expected error, got nil")
 	}
@@ -298,7 +568,7 @@ func TestService_CreatePlayer_Success(t *testing.T) {
 		},
 	}
 	svc := NewService(repo, Config{})
-	err := svc.CreatePlayer(context.Background(), "p2", 2, "GB")
+	_, err := svc.CreatePlayer(context.Background(), "p2", PlayerProfile{Level: intPtr(2), CountryCode: strPtr("GB")})
 	if err != nil {
 		t.Errorf("expected no error, got %v", err)
 	}
@@ -343,7 +613,7 @@ func TestService_UpdatePlayer_ErrorFetching(t *testing.T) {
 		},
 	}
 	svc := NewService(repo, Config{})
-	err := svc.UpdatePlayer(context.Background(), "p1", 2, "GB")
+	_, err := svc.UpdatePlayer(context.Background(), "p1", PlayerProfile{Level: intPtr(2), CountryCode: strPtr("GB")})
 	if err == nil {
 		t.Errorf("expected error, got nil")
 	}
@@ -359,7 +629,7 @@ func TestService_UpdatePlayer_ErrorUpdating(t *testing.T) {
 		},
 	}
 	svc := NewService(repo, Config{})
-	err := svc.UpdatePlayer(context.Background(), "p1", 2, "GB")
+	_, err := svc.UpdatePlayer(context.Background(), "p1", PlayerProfile{Level: intPtr(2), CountryCode: strPtr("GB")})
 	if err == nil {
 		t.Errorf("expected error, got nil")
 	}
@@ -380,7 +650,7 @@ func TestService_UpdatePlayer_Success(t *testing.T) {
 		},
 	}
 	svc := NewService(repo, Config{})
-	err := svc.UpdatePlayer(context.Background(), "p1", 2, "GB")
+	_, err := svc.UpdatePlayer(context.Background(), "p1", PlayerProfile{Level: intPtr(2), CountryCode: strPtr("GB")})
 	if err != nil {
 		t.Errorf("expected no error, got %v", err)
 	}
@@ -388,3 +658,318 @@ func TestService_UpdatePlayer_Success(t *testing.T) {
 		t.Errorf("expected UpdatePlayer to be called")
 	}
 }
+
+func TestService_SubmitScore_PublishesRankUpdate(t *testing.T) {
+	compID := uuid.New()
+	repo := &mockRepo{
+		GetPlayerByIDFunc: func(ctx context.Context, playerID string) (*model.Player, error) {
+			return &model.Player{PlayerID: playerID}, nil
+		},
+		GetActivePlayerCompetitionFunc: func(ctx context.Context, playerID string) (*model.PlayerCompetition, error) {
+			return &model.PlayerCompetition{PlayerID: playerID, CompetitionID: &compID}, nil
+		},
+		GetLeaderboardByCompetitionIDFunc: func(ctx context.Context, competitionID string, viewer model.Viewer) ([]model.PlayerCompetition, error) {
+			return []model.PlayerCompetition{{PlayerID: "p2", Score: 30}, {PlayerID: "p1", Score: 20}, {PlayerID: "p3", Score: 15}}, nil
+		},
+	}
+	svc := NewService(repo, Config{})
+	sub := svc.Hub().Subscribe(competitionTopic(compID.String()), 0)
+	defer sub.Close()
+
+	if err := svc.SubmitScore(context.Background(), "p1", 10); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	ev := <-sub.C
+	data := ev.Data.(map[string]interface{})
+	if ev.Type != EventScore || data["rank"] != 2 || data["previous_rank"] != 3 || data["score"] != 20 {
+		t.Errorf("unexpected score event: %+v", ev)
+	}
+}
+
+func TestService_SubmitScore_SkipsLeaderboardWithoutSubscribers(t *testing.T) {
+	compID := uuid.New()
+	reads := 0
+	repo := &mockRepo{
+		GetPlayerByIDFunc: func(ctx context.Context, playerID string) (*model.Player, error) {
+			return &model.Player{PlayerID: playerID}, nil
+		},
+		GetActivePlayerCompetitionFunc: func(ctx context.Context, playerID string) (*model.PlayerCompetition, error) {
+			return &model.PlayerCompetition{PlayerID: playerID, CompetitionID: &compID}, nil
+		},
+		GetLeaderboardByCompetitionIDFunc: func(ctx context.Context, competitionID string, viewer model.Viewer) ([]model.PlayerCompetition, error) {
+			reads++
+			return []model.PlayerCompetition{{PlayerID: "p1", Score: 10}}, nil
+		},
+	}
+	svc := NewService(repo, Config{})
+	if err := svc.SubmitScore(context.Background(), "p1", 10); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if reads != 0 {
+		t.Errorf("expected no leaderboard read without subscribers, got %d", reads)
+	}
+}
+
+func TestService_SubscribeLeaderboard_NotFound(t *testing.T) {
+	svc := NewService(&mockRepo{}, Config{})
+	_, err := svc.SubscribeLeaderboard(context.Background(), "missing", 0)
+	if err == nil || err.Error() != "leaderboard not found" {
+		t.Errorf("expected leaderboard not found error, got %v", err)
+	}
+}
+
+func TestService_SubscribeLeaderboard_CompletedCompetition(t *testing.T) {
+	compID := uuid.New()
+	repo := &mockRepo{
+		GetCompetitionByIDFunc: func(ctx context.Context, competitionID string) (*model.Competition, error) {
+			return &model.Competition{CompetitionID: compID, Status: model.CompetitionCompleted}, nil
+		},
+		GetLeaderboardByCompetitionIDFunc: func(ctx context.Context, competitionID string, viewer model.Viewer) ([]model.PlayerCompetition, error) {
+			return []model.PlayerCompetition{{PlayerID: "p1", Score: 10}}, nil
+		},
+	}
+	svc := NewService(repo, Config{})
+	sub, err := svc.SubscribeLeaderboard(context.Background(), compID.String(), 0)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	ev, ok := <-sub.C
+	if !ok || ev.Type != EventCompleted {
+		t.Errorf("expected completed event, got %+v", ev)
+	}
+	if _, ok := <-sub.C; ok {
+		t.Errorf("expected subscription to be closed after completed event")
+	}
+}
+
+func TestService_SubscribeLeaderboard_FinishedWhileSubscribing(t *testing.T) {
+	compID := uuid.New()
+	reads := 0
+	repo := &mockRepo{
+		GetCompetitionByIDFunc: func(ctx context.Context, competitionID string) (*model.Competition, error) {
+			reads++
+			if reads == 1 {
+				return &model.Competition{CompetitionID: compID, Status: model.CompetitionActive}, nil
+			}
+			return &model.Competition{CompetitionID: compID, Status: model.CompetitionCompleted}, nil
+		},
+		GetLeaderboardByCompetitionIDFunc: func(ctx context.Context, competitionID string, viewer model.Viewer) ([]model.PlayerCompetition, error) {
+			return []model.PlayerCompetition{{PlayerID: "p1", Score: 10}}, nil
+		},
+	}
+	svc := NewService(repo, Config{})
+	// The competition completes between the status read and the subscription.
+	svc.Hub().CloseTopic(competitionTopic(compID.String()))
+
+	sub, err := svc.SubscribeLeaderboard(context.Background(), compID.String(), 0)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	ev, ok := <-sub.C
+	if !ok || ev.Type != EventCompleted {
+		t.Fatalf("expected completed event, got %+v", ev)
+	}
+	if data := ev.Data.(map[string]interface{}); data["status"] != model.CompetitionCompleted {
+		t.Errorf("expected completed status, got %v", data["status"])
+	}
+	if _, ok := <-sub.C; ok {
+		t.Errorf("expected subscription to be closed after completed event")
+	}
+}
+
+func TestService_RunMatchmaking_NotifiesMatchedPlayers(t *testing.T) {
+	repo := &mockRepo{
+		GetWaitingPlayersFunc: func(ctx context.Context, limit int) ([]model.PlayerCompetition, error) {
+			return []model.PlayerCompetition{{PlayerID: "p1", Level: 1}, {PlayerID: "p2", Level: 1}}, nil
+		},
+	}
+	svc := NewService(repo, Config{CompetitionDuration: time.Minute})
+	sub := svc.Hub().Subscribe(playerTopic("p1"), 0)
+	defer sub.Close()
+
+	svc.runMatchmaking(context.Background())
+	select {
+	case ev := <-sub.C:
+		if ev.Type != EventMatched {
+			t.Errorf("expected matched event, got %+v", ev)
+		}
+	default:
+		t.Errorf("expected matched event for p1")
+	}
+}
+
+func TestService_RunMatchmaking_RespectsGroupSizes(t *testing.T) {
+	var gotLimit int
+	created := false
+	repo := &mockRepo{
+		GetWaitingPlayersFunc: func(ctx context.Context, limit int) ([]model.PlayerCompetition, error) {
+			gotLimit = limit
+			return []model.PlayerCompetition{{PlayerID: "p1", Level: 1}, {PlayerID: "p2", Level: 1}}, nil
+		},
+		CreateCompetitionFunc: func(ctx context.Context, comp *model.Competition) error {
+			created = true
+			return nil
+		},
+	}
+	svc := NewService(repo, Config{CompetitionDuration: time.Minute, MinGroupSize: 3, MaxGroupSize: 6})
+
+	if err := svc.runMatchmaking(context.Background()); err != nil {
+		t.Fatalf("runMatchmaking failed: %v", err)
+	}
+	if gotLimit != 6 {
+		t.Errorf("expected waiting players to be fetched up to MaxGroupSize, got limit %d", gotLimit)
+	}
+	if created {
+		t.Error("expected no competition with fewer than MinGroupSize players waiting")
+	}
+}
+
+func TestService_RunMatchmaking_NoNotificationOnUpdateFailure(t *testing.T) {
+	repo := &mockRepo{
+		GetWaitingPlayersFunc: func(ctx context.Context, limit int) ([]model.PlayerCompetition, error) {
+			return []model.PlayerCompetition{{PlayerID: "p1", Level: 1}, {PlayerID: "p2", Level: 1}}, nil
+		},
+		UpdatePlayerCompetitionsToActiveFunc: func(ctx context.Context, playerIDs []string, competitionID uuid.UUID, endsAt time.Time) error {
+			return errors.New("db error")
+		},
+	}
+	svc := NewService(repo, Config{CompetitionDuration: time.Minute})
+	sub := svc.Hub().Subscribe(playerTopic("p1"), 0)
+	defer sub.Close()
+
+	svc.runMatchmaking(context.Background())
+	select {
+	case ev := <-sub.C:
+		t.Errorf("expected no event, got %+v", ev)
+	default:
+	}
+}
+
+func TestService_SubscribePlayer_StartsWithActiveCompetition(t *testing.T) {
+	compID := uuid.New()
+	repo := &mockRepo{
+		GetPlayerByIDFunc: func(ctx context.Context, playerID string) (*model.Player, error) {
+			return &model.Player{PlayerID: playerID}, nil
+		},
+		GetActivePlayerCompetitionFunc: func(ctx context.Context, playerID string) (*model.PlayerCompetition, error) {
+			return &model.PlayerCompetition{PlayerID: playerID, CompetitionID: &compID}, nil
+		},
+		GetCompetitionByIDFunc: func(ctx context.Context, competitionID string) (*model.Competition, error) {
+			return &model.Competition{CompetitionID: compID, Status: model.CompetitionActive}, nil
+		},
+	}
+	svc := NewService(repo, Config{})
+	sub, err := svc.SubscribePlayer(context.Background(), "p1")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer sub.Close()
+	ev := <-sub.C
+	if ev.Type != EventMatched || ev.Data.(map[string]interface{})["leaderboard_id"] != compID.String() {
+		t.Errorf("unexpected initial event: %+v", ev)
+	}
+}
+
+func TestService_Leave_NotInQueue(t *testing.T) {
+	repo := &mockRepo{
+		GetPlayerByIDFunc: func(ctx context.Context, playerID string) (*model.Player, error) {
+			return &model.Player{PlayerID: playerID}, nil
+		},
+	}
+	svc := NewService(repo, Config{})
+	err := svc.Leave(context.Background(), "p1")
+	if err == nil || err.Error() != "player not in waiting queue" {
+		t.Errorf("expected player not in waiting queue error, got %v", err)
+	}
+}
+
+func TestService_Leave_Success(t *testing.T) {
+	repo := &mockRepo{
+		GetPlayerByIDFunc: func(ctx context.Context, playerID string) (*model.Player, error) {
+			return &model.Player{PlayerID: playerID}, nil
+		},
+		CancelWaitingPlayerCompetitionFunc: func(ctx context.Context, playerID string) (bool, error) {
+			return true, nil
+		},
+	}
+	svc := NewService(repo, Config{})
+	if err := svc.Leave(context.Background(), "p1"); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
+
+func TestService_StartMatchmakingWorker_StopsOnCancel(t *testing.T) {
+	svc := NewService(&mockRepo{}, Config{MatchmakingInterval: time.Millisecond})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := svc.StartMatchmakingWorker(ctx)
+	time.Sleep(5 * time.Millisecond)
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Errorf("expected worker to exit after cancel")
+	}
+}
+
+func TestService_UseTickMiddleware_WrapsWorkerTick(t *testing.T) {
+	dbErr := errors.New("db error")
+	svc := NewService(&mockRepo{
+		CompleteFinishedCompetitionsFunc: func(ctx context.Context) ([]uuid.UUID, error) {
+			return nil, dbErr
+		},
+	}, Config{MatchmakingInterval: time.Millisecond})
+	results := make(chan error, 1)
+	svc.UseTickMiddleware(func(next TickFunc) TickFunc {
+		return func(ctx context.Context) error {
+			err := next(ctx)
+			select {
+			case results <- err:
+			default:
+			}
+			return err
+		}
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := svc.StartMatchmakingWorker(ctx)
+	defer func() { cancel(); <-done }()
+
+	select {
+	case err := <-results:
+		if err != dbErr {
+			t.Errorf("expected tick error %v, got %v", dbErr, err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("tick middleware was not called")
+	}
+}
+
+func TestService_RunMatchmaking_TagsRepositoryCallsWithCompetitionID(t *testing.T) {
+	var tagged string
+	repo := &mockRepo{
+		GetWaitingPlayersFunc: func(ctx context.Context, limit int) ([]model.PlayerCompetition, error) {
+			return []model.PlayerCompetition{{PlayerID: "p1", Level: 1}, {PlayerID: "p2", Level: 1}}, nil
+		},
+		UpdatePlayerCompetitionsToActiveFunc: func(ctx context.Context, playerIDs []string, competitionID uuid.UUID, endsAt time.Time) error {
+			logging.FromContext(ctx).Info("probe")
+			tagged = competitionID.String()
+			return nil
+		},
+	}
+	var buf bytes.Buffer
+	ctx := logging.NewContext(context.Background(), slog.New(slog.NewTextHandler(&buf, nil)))
+	ctx = logging.With(ctx, "tick_id", 3)
+	svc := NewService(repo, Config{CompetitionDuration: time.Minute})
+
+	if err := svc.runMatchmaking(ctx); err != nil {
+		t.Fatalf("runMatchmaking failed: %v", err)
+	}
+	var probe string
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if strings.Contains(line, "msg=probe") {
+			probe = line
+		}
+	}
+	if !strings.Contains(probe, "tick_id=3") || !strings.Contains(probe, "competition_id="+tagged) {
+		t.Errorf("expected repository log line tagged with tick and competition, got %q", probe)
+	}
+}
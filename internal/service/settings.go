@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"leaderboard-service/internal/model"
+	"time"
+)
+
+// AuditActionSettingsUpdate is the audit action recorded for config changes.
+const AuditActionSettingsUpdate = "settings.update"
+
+// configJSON is the wire form of Config, with durations as Go duration
+// strings.
+type configJSON struct {
+	MatchmakingInterval string `json:"matchmaking_interval"`
+	CompetitionDuration string `json:"competition_duration"`
+	MinGroupSize        int    `json:"min_group_size"`
+	MaxGroupSize        int    `json:"max_group_size"`
+	PromotePercent      int    `json:"promote_percent"`
+	RelegatePercent     int    `json:"relegate_percent"`
+	PartyLevel          string `json:"party_level"`
+}
+
+func (c Config) MarshalJSON() ([]byte, error) {
+	return json.Marshal(configJSON{
+		MatchmakingInterval: c.MatchmakingInterval.String(),
+		CompetitionDuration: c.CompetitionDuration.String(),
+		MinGroupSize:        c.MinGroupSize,
+		MaxGroupSize:        c.MaxGroupSize,
+		PromotePercent:      c.PromotePercent,
+		RelegatePercent:     c.RelegatePercent,
+		PartyLevel:          string(c.PartyLevel),
+	})
+}
+
+// Validate reports the first value that the worker cannot run with.
+func (c Config) Validate() error {
+	switch {
+	case c.MatchmakingInterval <= 0:
+		return fmt.Errorf("%w: matchmaking_interval must be positive", ErrInvalidArgument)
+	case c.CompetitionDuration <= 0:
+		return fmt.Errorf("%w: competition_duration must be positive", ErrInvalidArgument)
+	case c.MinGroupSize < 2:
+		return fmt.Errorf("%w: min_group_size must be at least 2", ErrInvalidArgument)
+	case c.MaxGroupSize != 0 && c.MaxGroupSize < c.MinGroupSize:
+		return fmt.Errorf("%w: max_group_size must be 0 or at least min_group_size", ErrInvalidArgument)
+	case c.PromotePercent < 0 || c.RelegatePercent < 0 || c.PromotePercent+c.RelegatePercent > 100:
+		return fmt.Errorf("%w: promote_percent and relegate_percent must not be negative or add up to more than 100", ErrInvalidArgument)
+	case c.PartyLevel != model.PartyLevelMax && c.PartyLevel != model.PartyLevelAverage:
+		return fmt.Errorf("%w: party_level must be MAX or AVERAGE", ErrInvalidArgument)
+	}
+	return nil
+}
+
+// ConfigUpdate changes the fields that are set and leaves the rest as they
+// are.
+type ConfigUpdate struct {
+	MatchmakingInterval *time.Duration
+	CompetitionDuration *time.Duration
+	MinGroupSize        *int
+	MaxGroupSize        *int
+	PromotePercent      *int
+	RelegatePercent     *int
+	PartyLevel          *model.PartyLevel
+	// Reason is recorded in the audit log.
+	Reason string
+}
+
+func (u ConfigUpdate) apply(c Config) Config {
+	if u.MatchmakingInterval != nil {
+		c.MatchmakingInterval = *u.MatchmakingInterval
+	}
+	if u.CompetitionDuration != nil {
+		c.CompetitionDuration = *u.CompetitionDuration
+	}
+	if u.MinGroupSize != nil {
+		c.MinGroupSize = *u.MinGroupSize
+	}
+	if u.MaxGroupSize != nil {
+		c.MaxGroupSize = *u.MaxGroupSize
+	}
+	if u.PromotePercent != nil {
+		c.PromotePercent = *u.PromotePercent
+	}
+	if u.RelegatePercent != nil {
+		c.RelegatePercent = *u.RelegatePercent
+	}
+	if u.PartyLevel != nil {
+		c.PartyLevel = *u.PartyLevel
+	}
+	return c
+}
+
+// currentConfig returns the settings in effect right now.
+func (s *Service) currentConfig() Config {
+	return *s.config.Load()
+}
+
+// GetConfig returns the matchmaking and competition settings in effect.
+func (s *Service) GetConfig(ctx context.Context) (Config, error) {
+	return s.currentConfig(), nil
+}
+
+// UpdateConfig changes the settings at runtime. The change is recorded in
+// the audit log under the context's actor before it takes effect; the
+// worker applies it from its next tick, resetting its ticker if the
+// interval changed.
+func (s *Service) UpdateConfig(ctx context.Context, update ConfigUpdate) (Config, error) {
+	actor, err := requireActor(ctx)
+	if err != nil {
+		return Config{}, err
+	}
+
+	s.configMu.Lock()
+	defer s.configMu.Unlock()
+	before := s.currentConfig()
+	after := update.apply(before)
+	if err := after.Validate(); err != nil {
+		return before, err
+	}
+	if after == before {
+		logger(ctx).Debug("settings unchanged", "actor", actor)
+		return after, nil
+	}
+
+	entry := newAuditEntry(actor, AuditActionSettingsUpdate, "matchmaking", update.Reason, before, after)
+	if err := s.repo.CreateAuditEntry(ctx, entry); err != nil {
+		logger(ctx).Error("error recording settings change", "actor", actor, "error", err)
+		return before, err
+	}
+
+	s.config.Store(&after)
+	select {
+	case s.configChanged <- struct{}{}:
+	default:
+	}
+	logger(ctx).Info("settings changed", "actor", actor, "audit_id", entry.ID,
+		"before", string(entry.Before), "after", string(entry.After))
+	return after, nil
+}
+
+// ListAuditEntries returns audit log entries matching filter, newest first.
+func (s *Service) ListAuditEntries(ctx context.Context, filter model.AuditFilter) ([]model.AuditEntry, error) {
+	entries, err := s.repo.ListAuditEntries(ctx, filter)
+	if err != nil {
+		logger(ctx).Error("error listing audit entries", "error", err)
+		return nil, err
+	}
+	return entries, nil
+}
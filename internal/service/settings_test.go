@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"leaderboard-service/internal/auth"
+	"leaderboard-service/internal/model"
+	"testing"
+	"time"
+)
+
+func validConfig() Config {
+	return Config{MatchmakingInterval: time.Minute, CompetitionDuration: time.Hour, MinGroupSize: 2, MaxGroupSize: 10, PartyLevel: model.PartyLevelMax}
+}
+
+func TestService_UpdateConfig_RecordsAudit(t *testing.T) {
+	var audited *model.AuditEntry
+	repo := &mockRepo{CreateAuditEntryFunc: func(ctx context.Context, entry *model.AuditEntry) error {
+		audited = entry
+		return nil
+	}}
+	svc := NewService(repo, validConfig())
+	interval := 5 * time.Second
+	ctx := auth.WithActor(context.Background(), "alice")
+
+	got, err := svc.UpdateConfig(ctx, ConfigUpdate{MatchmakingInterval: &interval, Reason: "peak hours"})
+	if err != nil {
+		t.Fatalf("UpdateConfig failed: %v", err)
+	}
+	if got.MatchmakingInterval != interval || got.CompetitionDuration != time.Hour {
+		t.Errorf("unexpected config: %+v", got)
+	}
+	if current, _ := svc.GetConfig(ctx); current != got {
+		t.Errorf("expected new config to take effect, got %+v", current)
+	}
+	if audited == nil || audited.Actor != "alice" || audited.Action != AuditActionSettingsUpdate || audited.Reason != "peak hours" {
+		t.Fatalf("unexpected audit entry: %+v", audited)
+	}
+	var before, after map[string]interface{}
+	json.Unmarshal(audited.Before, &before)
+	json.Unmarshal(audited.After, &after)
+	if before["matchmaking_interval"] != "1m0s" || after["matchmaking_interval"] != "5s" {
+		t.Errorf("unexpected before/after: %s -> %s", audited.Before, audited.After)
+	}
+}
+
+func TestService_UpdateConfig_Rejected(t *testing.T) {
+	zero, small, half, median := time.Duration(0), 1, 60, model.PartyLevel("MEDIAN")
+	cases := map[string]struct {
+		ctx    context.Context
+		update ConfigUpdate
+	}{
+		"no actor":        {context.Background(), ConfigUpdate{}},
+		"zero interval":   {auth.WithActor(context.Background(), "alice"), ConfigUpdate{MatchmakingInterval: &zero}},
+		"group too small": {auth.WithActor(context.Background(), "alice"), ConfigUpdate{MinGroupSize: &small}},
+		"over 100%":       {auth.WithActor(context.Background(), "alice"), ConfigUpdate{PromotePercent: &half, RelegatePercent: &half}},
+		"party level":     {auth.WithActor(context.Background(), "alice"), ConfigUpdate{PartyLevel: &median}},
+	}
+	for name, tc := range cases {
+		repo := &mockRepo{CreateAuditEntryFunc: func(ctx context.Context, entry *model.AuditEntry) error {
+			t.Errorf("%s: expected no audit entry", name)
+			return nil
+		}}
+		svc := NewService(repo, validConfig())
+		if _, err := svc.UpdateConfig(tc.ctx, tc.update); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("%s: expected ErrInvalidArgument, got %v", name, err)
+		}
+		if svc.currentConfig() != validConfig() {
+			t.Errorf("%s: expected config to be unchanged", name)
+		}
+	}
+}
+
+func TestService_UpdateConfig_AuditFailureKeepsConfig(t *testing.T) {
+	repo := &mockRepo{CreateAuditEntryFunc: func(ctx context.Context, entry *model.AuditEntry) error {
+		return errors.New("db error")
+	}}
+	svc := NewService(repo, validConfig())
+	d := 2 * time.Hour
+	if _, err := svc.UpdateConfig(auth.WithActor(context.Background(), "alice"), ConfigUpdate{CompetitionDuration: &d}); err == nil {
+		t.Fatal("expected error")
+	}
+	if svc.currentConfig().CompetitionDuration != time.Hour {
+		t.Error("expected config to be unchanged when the audit entry cannot be written")
+	}
+}
+
+func TestService_Worker_ResetsTickerOnIntervalChange(t *testing.T) {
+	svc := NewService(&mockRepo{}, Config{MatchmakingInterval: time.Hour, CompetitionDuration: time.Minute})
+	ticked := make(chan struct{}, 1)
+	svc.UseTickMiddleware(func(next TickFunc) TickFunc {
+		return func(ctx context.Context) error {
+			select {
+			case ticked <- struct{}{}:
+			default:
+			}
+			return nil
+		}
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := svc.StartMatchmakingWorker(ctx)
+	defer func() { cancel(); <-done }()
+
+	interval := 10 * time.Millisecond
+	if _, err := svc.UpdateConfig(auth.WithActor(context.Background(), "alice"), ConfigUpdate{MatchmakingInterval: &interval}); err != nil {
+		t.Fatalf("UpdateConfig failed: %v", err)
+	}
+	select {
+	case <-ticked:
+	case <-time.After(time.Second):
+		t.Fatal("expected the worker to tick at the new interval")
+	}
+}
@@ -0,0 +1,194 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"leaderboard-service/internal/model"
+)
+
+// Leaderboard stream event types.
+const (
+	EventSnapshot  = "snapshot"
+	EventScore     = "score"
+	EventCompleted = "completed"
+	EventMatched   = "matched"
+	// EventScoreAdjusted tells a player that an admin corrected their score.
+	EventScoreAdjusted = "score_adjusted"
+)
+
+// Hub returns the service's event hub.
+func (s *Service) Hub() *Hub {
+	return s.hub
+}
+
+// SubscribeLeaderboard subscribes to live updates for a competition. Events
+// after lastEventID are replayed when still retained. For competitions that
+// are no longer active the subscription yields a single completed event.
+func (s *Service) SubscribeLeaderboard(ctx context.Context, leaderboardID string, lastEventID uint64) (*Subscription, error) {
+	comp, err := s.repo.GetCompetitionByID(ctx, leaderboardID)
+	if err != nil {
+		logger(ctx).Info("no competition found for stream", "competition_id", leaderboardID, "error", err)
+		return nil, errors.New("leaderboard not found")
+	}
+	if comp.Status == model.CompetitionActive {
+		if sub, ok := s.hub.subscribe(competitionTopic(leaderboardID), lastEventID); ok {
+			logger(ctx).Info("new stream subscriber", "competition_id", leaderboardID, "last_event_id", lastEventID)
+			return sub, nil
+		}
+		// The competition finished since it was read, and its completed
+		// event has been published already; its final status is stored
+		// before its topic is closed.
+		comp, err = s.repo.GetCompetitionByID(ctx, leaderboardID)
+		if err != nil {
+			logger(ctx).Error("error re-reading finished competition", "competition_id", leaderboardID, "error", err)
+			return nil, err
+		}
+	}
+	pcs, err := s.repo.GetLeaderboardByCompetitionID(ctx, leaderboardID, model.Viewer{})
+	if err != nil {
+		logger(ctx).Error("error fetching final leaderboard", "competition_id", leaderboardID, "error", err)
+		return nil, err
+	}
+	return closedSubscription(Event{Type: EventCompleted, Data: completedEvent(leaderboardID, comp.Status, pcs)}), nil
+}
+
+// SubscribePlayer subscribes to notifications addressed to a single player,
+// such as being matched into a competition. If the player is already in an
+// active competition the subscription starts with a matched event for it.
+func (s *Service) SubscribePlayer(ctx context.Context, playerID string) (*Subscription, error) {
+	if _, err := s.repo.GetPlayerByID(ctx, playerID); err != nil {
+		logger(ctx).Info("player not found for subscription", "player_id", playerID)
+		return nil, errors.New("player not found")
+	}
+	var initial []Event
+	if pc, err := s.repo.GetActivePlayerCompetition(ctx, playerID); err == nil && pc.CompetitionID != nil {
+		if comp, err := s.repo.GetCompetitionByID(ctx, pc.CompetitionID.String()); err == nil {
+			initial = append(initial, Event{Type: EventMatched, Data: matchedEvent(comp, nil)})
+		}
+	}
+	logger(ctx).Info("new player subscriber", "player_id", playerID)
+	sub, _ := s.hub.subscribe(playerTopic(playerID), 0, initial...)
+	return sub, nil
+}
+
+// publishMatched tells each player that they have been placed in comp.
+func (s *Service) publishMatched(comp *model.Competition, playerIDs []string) {
+	data := matchedEvent(comp, playerIDs)
+	for _, playerID := range playerIDs {
+		s.hub.Notify(playerTopic(playerID), EventMatched, data)
+	}
+}
+
+func matchedEvent(comp *model.Competition, playerIDs []string) map[string]interface{} {
+	data := map[string]interface{}{
+		"leaderboard_id": comp.CompetitionID.String(),
+		"started_at":     comp.StartedAt.Unix(),
+		"ends_at":        comp.EndsAt.Unix(),
+	}
+	if playerIDs != nil {
+		data["players"] = playerIDs
+	}
+	return data
+}
+
+// publishScore notifies stream subscribers of a score change for playerID.
+// The leaderboard is only read to rank the player if someone is following
+// the competition.
+func (s *Service) publishScore(ctx context.Context, competitionID, playerID string, delta int) {
+	if s.hub.skipIfIdle(competitionTopic(competitionID)) {
+		return
+	}
+	pcs, err := s.repo.GetLeaderboardByCompetitionID(ctx, competitionID, model.Viewer{})
+	if err != nil {
+		logger(ctx).Error("error fetching leaderboard for stream update", "competition_id", competitionID, "error", err)
+		return
+	}
+	var current *model.PlayerCompetition
+	for i := range pcs {
+		if pcs[i].PlayerID == playerID {
+			current = &pcs[i]
+			break
+		}
+	}
+	// Shadow-banned players are not on the public leaderboard.
+	if current == nil {
+		return
+	}
+	s.hub.Publish(competitionTopic(competitionID), EventScore, map[string]interface{}{
+		"leaderboard_id": competitionID,
+		"player_id":      playerID,
+		"score":          current.Score,
+		"delta":          delta,
+		"rank":           rankOf(pcs, playerID, current.Score),
+		"previous_rank":  rankOf(pcs, playerID, current.Score-delta),
+	})
+}
+
+// publishScoreChange announces an admin score correction: followers of an
+// active competition get a score event with the new ranks, unless the player
+// is not on the public leaderboard, and the player is notified whether or
+// not the competition is still running.
+func (s *Service) publishScoreChange(ctx context.Context, comp *model.Competition, change *ScoreChange, reason string) {
+	if comp.Status == model.CompetitionActive {
+		s.publishScore(ctx, change.LeaderboardID, change.PlayerID, change.Score-change.PreviousScore)
+	}
+	s.hub.Notify(playerTopic(change.PlayerID), EventScoreAdjusted, map[string]interface{}{
+		"leaderboard_id": change.LeaderboardID,
+		"score":          change.Score,
+		"previous_score": change.PreviousScore,
+		"rank":           change.Rank,
+		"reason":         reason,
+	})
+}
+
+// publishFinished sends the final standings of a completed or cancelled
+// competition and closes its stream topic.
+func (s *Service) publishFinished(ctx context.Context, competitionID string, status model.CompetitionStatus) {
+	pcs, err := s.repo.GetLeaderboardByCompetitionID(ctx, competitionID, model.Viewer{})
+	if err != nil {
+		logger(ctx).Error("error fetching final leaderboard", "competition_id", competitionID, "error", err)
+	}
+	topic := competitionTopic(competitionID)
+	s.hub.Publish(topic, EventCompleted, completedEvent(competitionID, status, pcs))
+	s.hub.CloseTopic(topic)
+}
+
+// publishSnapshot sends the whole current leaderboard after a change that
+// can move more than one player, such as an admin exclusion.
+func (s *Service) publishSnapshot(ctx context.Context, competitionID string) {
+	snapshot, err := s.GetLeaderboard(ctx, competitionID)
+	if err != nil {
+		logger(ctx).Debug("no leaderboard to publish", "competition_id", competitionID, "error", err)
+		return
+	}
+	s.hub.Publish(competitionTopic(competitionID), EventSnapshot, snapshot)
+}
+
+func completedEvent(competitionID string, status model.CompetitionStatus, pcs []model.PlayerCompetition) map[string]interface{} {
+	entries := make([]map[string]interface{}, 0, len(pcs))
+	for i, entry := range pcs {
+		e := leaderboardEntry(entry)
+		e["rank"] = i + 1
+		entries = append(entries, e)
+	}
+	return map[string]interface{}{
+		"leaderboard_id": competitionID,
+		"status":         status,
+		"leaderboard":    entries,
+	}
+}
+
+// rankOf returns the 1-based rank playerID would hold with the given score,
+// using the same ordering as the leaderboard query (score desc, player_id asc).
+func rankOf(pcs []model.PlayerCompetition, playerID string, score int) int {
+	rank := 1
+	for _, pc := range pcs {
+		if pc.PlayerID == playerID {
+			continue
+		}
+		if pc.Score > score || (pc.Score == score && pc.PlayerID < playerID) {
+			rank++
+		}
+	}
+	return rank
+}
@@ -0,0 +1,316 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"leaderboard-service/internal/iso3166"
+	"leaderboard-service/internal/logging"
+	"leaderboard-service/internal/model"
+	"leaderboard-service/internal/recurrence"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Audit actions recorded for competition template changes.
+const (
+	AuditActionTemplateCreate = "template.create"
+	AuditActionTemplateUpdate = "template.update"
+	AuditActionTemplateDelete = "template.delete"
+)
+
+// MaxPreviewOccurrences caps how many occurrences PreviewTemplate returns.
+const MaxPreviewOccurrences = 100
+
+// TemplateDefinition is the admin-editable part of a competition template.
+type TemplateDefinition struct {
+	Name string
+	// Schedule is a cron expression, evaluated in UTC, or
+	// "@every <duration>"; see package recurrence.
+	Schedule    string
+	Duration    time.Duration
+	ScoringMode model.ScoringMode
+	Eligibility model.Eligibility
+	Rewards     []model.Reward
+	MaxPlayers  int
+	Enabled     bool
+	// Reason is recorded in the audit log.
+	Reason string
+}
+
+// Occurrence is one competition a template will start.
+type Occurrence struct {
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+}
+
+func (d TemplateDefinition) validate() (recurrence.Schedule, error) {
+	switch {
+	case strings.TrimSpace(d.Name) == "":
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidArgument)
+	case d.Duration < time.Minute || d.Duration%time.Second != 0:
+		return nil, fmt.Errorf("%w: duration must be at least 1m and a whole number of seconds", ErrInvalidArgument)
+	case d.ScoringMode != model.ScoringSum && d.ScoringMode != model.ScoringBest:
+		return nil, fmt.Errorf("%w: scoring_mode must be SUM or BEST", ErrInvalidArgument)
+	case d.Eligibility.MinLevel < 0 || d.Eligibility.MaxLevel < 0:
+		return nil, fmt.Errorf("%w: levels must not be negative", ErrInvalidArgument)
+	case d.Eligibility.MaxLevel > 0 && d.Eligibility.MaxLevel < d.Eligibility.MinLevel:
+		return nil, fmt.Errorf("%w: max_level must be 0 or at least min_level", ErrInvalidArgument)
+	case d.MaxPlayers < 0:
+		return nil, fmt.Errorf("%w: max_players must not be negative", ErrInvalidArgument)
+	}
+	if err := validateRewards(d.Rewards); err != nil {
+		return nil, err
+	}
+	for _, c := range d.Eligibility.Countries {
+		if !iso3166.Valid(c) {
+			return nil, fmt.Errorf("%w: eligibility country %q is not an upper-case ISO 3166-1 alpha-2 code", ErrInvalidArgument, c)
+		}
+	}
+	schedule, err := recurrence.Parse(d.Schedule)
+	if err != nil {
+		return nil, fmt.Errorf("%w: schedule: %v", ErrInvalidArgument, err)
+	}
+	return schedule, nil
+}
+
+// validateRewards checks that reward rank ranges are well formed and do not
+// overlap.
+func validateRewards(rewards []model.Reward) error {
+	sorted := append([]model.Reward(nil), rewards...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].FromRank < sorted[j].FromRank })
+	for i, r := range sorted {
+		switch {
+		case r.FromRank < 1 || r.ToRank < r.FromRank:
+			return fmt.Errorf("%w: reward ranks must satisfy 1 <= from_rank <= to_rank", ErrInvalidArgument)
+		case strings.TrimSpace(r.Reward) == "":
+			return fmt.Errorf("%w: every reward needs a description", ErrInvalidArgument)
+		case i > 0 && r.FromRank <= sorted[i-1].ToRank:
+			return fmt.Errorf("%w: reward rank ranges overlap", ErrInvalidArgument)
+		}
+	}
+	return nil
+}
+
+// apply sets tpl's definition from d and schedules its next occurrence
+// after now; a disabled template has none.
+func (d TemplateDefinition) apply(tpl *model.CompetitionTemplate, schedule recurrence.Schedule, now time.Time) {
+	tpl.Name = strings.TrimSpace(d.Name)
+	tpl.Schedule = strings.TrimSpace(d.Schedule)
+	tpl.Duration = d.Duration
+	tpl.ScoringMode = d.ScoringMode
+	tpl.Eligibility = d.Eligibility
+	tpl.Rewards = d.Rewards
+	tpl.MaxPlayers = d.MaxPlayers
+	tpl.Enabled = d.Enabled
+	tpl.NextRunAt = nil
+	if next := schedule.Next(now); d.Enabled && !next.IsZero() {
+		tpl.NextRunAt = &next
+	}
+}
+
+func templateTarget(templateID uuid.UUID) string {
+	return "template/" + templateID.String()
+}
+
+// ListTemplates returns all competition templates by name.
+func (s *Service) ListTemplates(ctx context.Context) ([]model.CompetitionTemplate, error) {
+	tpls, err := s.repo.ListCompetitionTemplates(ctx)
+	if err != nil {
+		logger(ctx).Error("error listing competition templates", "error", err)
+		return nil, err
+	}
+	return tpls, nil
+}
+
+func (s *Service) GetTemplate(ctx context.Context, templateID string) (*model.CompetitionTemplate, error) {
+	id, err := uuid.Parse(templateID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: template not found", ErrNotFound)
+	}
+	tpl, err := s.repo.GetCompetitionTemplate(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: template not found", ErrNotFound)
+	}
+	if err != nil {
+		logger(ctx).Error("error fetching competition template", "template_id", templateID, "error", err)
+		return nil, err
+	}
+	return tpl, nil
+}
+
+// CreateTemplate stores a new competition template. If enabled, the worker
+// starts its first competition at the next occurrence of its schedule.
+func (s *Service) CreateTemplate(ctx context.Context, def TemplateDefinition) (*model.CompetitionTemplate, error) {
+	actor, err := requireActor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	schedule, err := def.validate()
+	if err != nil {
+		return nil, err
+	}
+	tpl := &model.CompetitionTemplate{TemplateID: uuid.New()}
+	def.apply(tpl, schedule, time.Now())
+	entry := newAuditEntry(actor, AuditActionTemplateCreate, templateTarget(tpl.TemplateID), def.Reason, nil, tpl)
+	if err := s.repo.CreateCompetitionTemplate(ctx, tpl, entry); err != nil {
+		return nil, err
+	}
+	logger(ctx).Info("competition template created", "template_id", tpl.TemplateID, "actor", actor, "audit_id", entry.ID,
+		"schedule", tpl.Schedule, "next_run_at", tpl.NextRunAt)
+	return tpl, nil
+}
+
+// UpdateTemplate replaces a template's definition and reschedules its next
+// occurrence. Competitions already started from it keep their rules.
+func (s *Service) UpdateTemplate(ctx context.Context, templateID string, def TemplateDefinition) (*model.CompetitionTemplate, error) {
+	actor, err := requireActor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	schedule, err := def.validate()
+	if err != nil {
+		return nil, err
+	}
+	before, err := s.GetTemplate(ctx, templateID)
+	if err != nil {
+		return nil, err
+	}
+	after := *before
+	def.apply(&after, schedule, time.Now())
+	entry := newAuditEntry(actor, AuditActionTemplateUpdate, templateTarget(before.TemplateID), def.Reason, before, after)
+	ok, err := s.repo.UpdateCompetitionTemplate(ctx, &after, before.UpdatedAt, entry)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: template changed concurrently, retry", ErrConflict)
+	}
+	logger(ctx).Info("competition template updated", "template_id", templateID, "actor", actor, "audit_id", entry.ID,
+		"schedule", after.Schedule, "next_run_at", after.NextRunAt)
+	return &after, nil
+}
+
+// DeleteTemplate deletes a template so that it starts no more competitions.
+// A competition it started keeps running until it ends.
+func (s *Service) DeleteTemplate(ctx context.Context, templateID, reason string) error {
+	actor, err := requireActor(ctx)
+	if err != nil {
+		return err
+	}
+	before, err := s.GetTemplate(ctx, templateID)
+	if err != nil {
+		return err
+	}
+	entry := newAuditEntry(actor, AuditActionTemplateDelete, templateTarget(before.TemplateID), reason, before, nil)
+	ok, err := s.repo.DeleteCompetitionTemplate(ctx, before.TemplateID, entry)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("%w: template not found", ErrNotFound)
+	}
+	logger(ctx).Info("competition template deleted", "template_id", templateID, "actor", actor, "audit_id", entry.ID)
+	return nil
+}
+
+// PreviewTemplate returns the next count competitions a template will
+// start, beginning with its next occurrence. A disabled template has none.
+func (s *Service) PreviewTemplate(ctx context.Context, templateID string, count int) ([]Occurrence, error) {
+	if count < 1 || count > MaxPreviewOccurrences {
+		return nil, fmt.Errorf("%w: count must be between 1 and %d", ErrInvalidArgument, MaxPreviewOccurrences)
+	}
+	tpl, err := s.GetTemplate(ctx, templateID)
+	if err != nil {
+		return nil, err
+	}
+	occurrences := []Occurrence{}
+	if tpl.NextRunAt == nil {
+		return occurrences, nil
+	}
+	schedule, err := recurrence.Parse(tpl.Schedule)
+	if err != nil {
+		return nil, err
+	}
+	for start := *tpl.NextRunAt; !start.IsZero() && len(occurrences) < count; start = schedule.Next(start) {
+		occurrences = append(occurrences, Occurrence{StartsAt: start, EndsAt: start.Add(tpl.Duration)})
+	}
+	return occurrences, nil
+}
+
+// advanceTemplates starts a competition for each template whose next
+// occurrence has come, completing the previous one. After downtime only the
+// latest missed occurrence is started, and only if it has not already ended.
+func (s *Service) advanceTemplates(ctx context.Context) error {
+	due, err := s.repo.DueCompetitionTemplates(ctx)
+	if err != nil {
+		workerLogger(ctx).Error("error fetching due competition templates", "error", err)
+		return err
+	}
+	var passErr error
+	for i := range due {
+		if err := s.startOccurrence(logging.With(ctx, "template_id", due[i].TemplateID), &due[i]); err != nil && passErr == nil {
+			passErr = err
+		}
+	}
+	return passErr
+}
+
+func (s *Service) startOccurrence(ctx context.Context, tpl *model.CompetitionTemplate) error {
+	schedule, err := recurrence.Parse(tpl.Schedule)
+	if err != nil {
+		workerLogger(ctx).Error("invalid template schedule", "schedule", tpl.Schedule, "error", err)
+		return err
+	}
+	now := time.Now()
+	start := *tpl.NextRunAt
+	next := schedule.Next(start)
+	for !next.IsZero() && !next.After(now) {
+		start, next = next, schedule.Next(next)
+	}
+	var nextRunAt *time.Time
+	if !next.IsZero() {
+		nextRunAt = &next
+	}
+
+	var comp *model.Competition
+	if endsAt := start.Add(tpl.Duration); endsAt.After(now) {
+		templateID := tpl.TemplateID
+		comp = &model.Competition{
+			CompetitionID: uuid.New(),
+			Name:          tpl.Name,
+			Kind:          model.CompetitionKindRecurring,
+			StartedAt:     start,
+			EndsAt:        endsAt,
+			Status:        model.CompetitionActive,
+			MaxPlayers:    tpl.MaxPlayers,
+			TemplateID:    &templateID,
+			ScoringMode:   tpl.ScoringMode,
+			Eligibility:   tpl.Eligibility,
+			Rewards:       tpl.Rewards,
+		}
+	}
+	closed, ok, err := s.repo.StartTemplateOccurrence(ctx, tpl.TemplateID, *tpl.NextRunAt, nextRunAt, comp)
+	if err != nil {
+		workerLogger(ctx).Error("error starting template occurrence", "error", err)
+		return err
+	}
+	if !ok {
+		workerLogger(ctx).Debug("template occurrence already started")
+		return nil
+	}
+	for _, id := range closed {
+		s.publishFinished(logging.With(ctx, "competition_id", id), id.String(), model.CompetitionCompleted)
+	}
+	if comp == nil {
+		workerLogger(ctx).Warn("skipped template occurrence that has already ended", "starts_at", start, "next_run_at", nextRunAt)
+		return nil
+	}
+	workerLogger(ctx).Info("started recurring competition", "competition_id", comp.CompetitionID, "name", comp.Name,
+		"ends_at", comp.EndsAt, "closed", closed, "next_run_at", nextRunAt)
+	return nil
+}
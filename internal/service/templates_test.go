@@ -0,0 +1,255 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"leaderboard-service/internal/auth"
+	"leaderboard-service/internal/model"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func validTemplate() TemplateDefinition {
+	return TemplateDefinition{
+		Name:        "Daily Sprint",
+		Schedule:    "@daily",
+		Duration:    24 * time.Hour,
+		ScoringMode: model.ScoringBest,
+		Eligibility: model.Eligibility{MinLevel: 5, Countries: []string{"DE", "FR"}},
+		Rewards:     []model.Reward{{FromRank: 1, ToRank: 1, Reward: "1000 coins"}, {FromRank: 2, ToRank: 10, Reward: "100 coins"}},
+		Enabled:     true,
+	}
+}
+
+func TestService_CreateTemplate(t *testing.T) {
+	var created *model.CompetitionTemplate
+	var audited *model.AuditEntry
+	repo := &mockRepo{CreateCompetitionTemplateFunc: func(ctx context.Context, tpl *model.CompetitionTemplate, audit *model.AuditEntry) error {
+		created, audited = tpl, audit
+		return nil
+	}}
+	svc := NewService(repo, validConfig())
+	ctx := auth.WithActor(context.Background(), "alice")
+
+	tpl, err := svc.CreateTemplate(ctx, validTemplate())
+	if err != nil {
+		t.Fatalf("CreateTemplate failed: %v", err)
+	}
+	if tpl != created || tpl.NextRunAt == nil || !tpl.NextRunAt.After(time.Now()) || tpl.NextRunAt.Hour() != 0 {
+		t.Errorf("expected the next midnight as first run, got %+v", tpl)
+	}
+	if audited.Action != AuditActionTemplateCreate || audited.Target != "template/"+tpl.TemplateID.String() {
+		t.Errorf("unexpected audit entry: %+v", audited)
+	}
+
+	disabled := validTemplate()
+	disabled.Enabled = false
+	if tpl, err := svc.CreateTemplate(ctx, disabled); err != nil || tpl.NextRunAt != nil {
+		t.Errorf("expected a disabled template to have no next run, got %+v, %v", tpl, err)
+	}
+
+	for name, mutate := range map[string]func(*TemplateDefinition){
+		"no name":         func(d *TemplateDefinition) { d.Name = " " },
+		"bad schedule":    func(d *TemplateDefinition) { d.Schedule = "every day" },
+		"short duration":  func(d *TemplateDefinition) { d.Duration = time.Second },
+		"scoring mode":    func(d *TemplateDefinition) { d.ScoringMode = "MAX" },
+		"level range":     func(d *TemplateDefinition) { d.Eligibility.MaxLevel = 2 },
+		"overlap":         func(d *TemplateDefinition) { d.Rewards[1].FromRank = 1 },
+		"reversed ranks":  func(d *TemplateDefinition) { d.Rewards[1].ToRank = 1 },
+		"empty reward":    func(d *TemplateDefinition) { d.Rewards[0].Reward = "" },
+		"negative places": func(d *TemplateDefinition) { d.MaxPlayers = -1 },
+	} {
+		def := validTemplate()
+		mutate(&def)
+		if _, err := svc.CreateTemplate(ctx, def); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("%s: expected ErrInvalidArgument, got %v", name, err)
+		}
+	}
+}
+
+func TestService_UpdateTemplate_Conflict(t *testing.T) {
+	existing := &model.CompetitionTemplate{TemplateID: uuid.New(), Name: "Old", Schedule: "@weekly", Duration: time.Hour, UpdatedAt: time.Now()}
+	repo := &mockRepo{
+		GetCompetitionTemplateFunc: func(ctx context.Context, templateID uuid.UUID) (*model.CompetitionTemplate, error) {
+			if templateID != existing.TemplateID {
+				return nil, sql.ErrNoRows
+			}
+			tpl := *existing
+			return &tpl, nil
+		},
+		UpdateCompetitionTemplateFunc: func(ctx context.Context, tpl *model.CompetitionTemplate, lastUpdated time.Time, audit *model.AuditEntry) (bool, error) {
+			if !lastUpdated.Equal(existing.UpdatedAt) {
+				t.Errorf("expected the update to be guarded by %v, got %v", existing.UpdatedAt, lastUpdated)
+			}
+			return false, nil
+		},
+	}
+	svc := NewService(repo, validConfig())
+	ctx := auth.WithActor(context.Background(), "alice")
+
+	if _, err := svc.UpdateTemplate(ctx, existing.TemplateID.String(), validTemplate()); !errors.Is(err, ErrConflict) {
+		t.Errorf("expected ErrConflict, got %v", err)
+	}
+	if _, err := svc.UpdateTemplate(ctx, uuid.NewString(), validTemplate()); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestService_PreviewTemplate(t *testing.T) {
+	next := time.Date(2030, 1, 4, 18, 0, 0, 0, time.UTC)
+	tpl := &model.CompetitionTemplate{TemplateID: uuid.New(), Schedule: "0 18 * * 5", Duration: 2 * time.Hour, Enabled: true, NextRunAt: &next}
+	repo := &mockRepo{GetCompetitionTemplateFunc: func(ctx context.Context, templateID uuid.UUID) (*model.CompetitionTemplate, error) {
+		return tpl, nil
+	}}
+	svc := NewService(repo, validConfig())
+
+	occurrences, err := svc.PreviewTemplate(context.Background(), tpl.TemplateID.String(), 3)
+	if err != nil {
+		t.Fatalf("PreviewTemplate failed: %v", err)
+	}
+	if len(occurrences) != 3 || !occurrences[0].StartsAt.Equal(next) || !occurrences[2].StartsAt.Equal(next.AddDate(0, 0, 14)) ||
+		!occurrences[2].EndsAt.Equal(next.AddDate(0, 0, 14).Add(2*time.Hour)) {
+		t.Errorf("unexpected occurrences: %+v", occurrences)
+	}
+	if _, err := svc.PreviewTemplate(context.Background(), tpl.TemplateID.String(), 0); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("expected ErrInvalidArgument for count 0, got %v", err)
+	}
+}
+
+func TestService_RunMatchmaking_StartsTemplateOccurrence(t *testing.T) {
+	// The worker was down for three days: only the latest occurrence runs.
+	due := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -3)
+	tpl := model.CompetitionTemplate{TemplateID: uuid.New(), Name: "Daily Sprint", Schedule: "@daily", Duration: 24 * time.Hour,
+		ScoringMode: model.ScoringBest, Eligibility: model.Eligibility{MinLevel: 5}, Enabled: true, NextRunAt: &due}
+	previous := uuid.New()
+	var gotDue time.Time
+	var gotNext *time.Time
+	var started *model.Competition
+	repo := &mockRepo{
+		DueCompetitionTemplatesFunc: func(ctx context.Context) ([]model.CompetitionTemplate, error) {
+			return []model.CompetitionTemplate{tpl}, nil
+		},
+		StartTemplateOccurrenceFunc: func(ctx context.Context, templateID uuid.UUID, due time.Time, next *time.Time, comp *model.Competition) ([]uuid.UUID, bool, error) {
+			gotDue, gotNext, started = due, next, comp
+			return []uuid.UUID{previous}, true, nil
+		},
+		GetLeaderboardByCompetitionIDFunc: func(ctx context.Context, competitionID string, viewer model.Viewer) ([]model.PlayerCompetition, error) {
+			return nil, nil
+		},
+		GetWaitingPlayersFunc: func(ctx context.Context, limit int) ([]model.PlayerCompetition, error) {
+			return nil, nil
+		},
+	}
+	svc := NewService(repo, validConfig())
+	sub := svc.Hub().Subscribe(competitionTopic(previous.String()), 0)
+	defer sub.Close()
+
+	if err := svc.runMatchmaking(context.Background()); err != nil {
+		t.Fatalf("runMatchmaking failed: %v", err)
+	}
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	if !gotDue.Equal(due) || gotNext == nil || !gotNext.Equal(today.AddDate(0, 0, 1)) {
+		t.Errorf("expected to move the next run from %v to tomorrow, got %v -> %v", due, gotDue, gotNext)
+	}
+	if started == nil || !started.StartedAt.Equal(today) || started.Kind != model.CompetitionKindRecurring ||
+		*started.TemplateID != tpl.TemplateID || started.ScoringMode != model.ScoringBest || started.Eligibility.MinLevel != 5 {
+		t.Errorf("unexpected competition: %+v", started)
+	}
+	select {
+	case ev := <-sub.C:
+		if ev.Type != EventCompleted {
+			t.Errorf("expected the previous competition to complete, got %+v", ev)
+		}
+	default:
+		t.Error("expected a completed event for the previous competition")
+	}
+}
+
+func TestService_RegisterForCompetition_Recurring(t *testing.T) {
+	comp := &model.Competition{CompetitionID: uuid.New(), Kind: model.CompetitionKindRecurring, Status: model.CompetitionActive,
+		Eligibility: model.Eligibility{MinLevel: 5, Countries: []string{"DE"}}}
+	repo := activeCompetitionRepo(comp)
+	repo.GetPlayerByIDFunc = func(ctx context.Context, playerID string) (*model.Player, error) {
+		if playerID == "novice" {
+			return &model.Player{PlayerID: playerID, Level: 1, CountryCode: "DE"}, nil
+		}
+		return &model.Player{PlayerID: playerID, Level: 7, CountryCode: "DE"}, nil
+	}
+	repo.GetCompetitionPlayerFunc = func(ctx context.Context, competitionID uuid.UUID, playerID string) (*model.PlayerCompetition, error) {
+		return nil, sql.ErrNoRows
+	}
+	other := uuid.New()
+	repo.GetActivePlayerCompetitionFunc = func(ctx context.Context, playerID string) (*model.PlayerCompetition, error) {
+		if playerID == "busy" {
+			return &model.PlayerCompetition{PlayerID: playerID, CompetitionID: &other, Status: model.StatusActive}, nil
+		}
+		return nil, sql.ErrNoRows
+	}
+	repo.RegisterCompetitionPlayerFunc = func(ctx context.Context, competitionID uuid.UUID, player *model.Player) (bool, error) {
+		return true, nil
+	}
+	svc := NewService(repo, validConfig())
+	sub := svc.Hub().Subscribe(playerTopic("p1"), 0)
+	defer sub.Close()
+
+	if err := svc.RegisterForCompetition(context.Background(), comp.CompetitionID.String(), "novice"); err == nil || err.Error() != "player not eligible" {
+		t.Errorf("expected player not eligible, got %v", err)
+	}
+	if err := svc.RegisterForCompetition(context.Background(), comp.CompetitionID.String(), "busy"); err == nil || err.Error() != "player already in active competition" {
+		t.Errorf("expected player already in active competition, got %v", err)
+	}
+	if err := svc.RegisterForCompetition(context.Background(), comp.CompetitionID.String(), "p1"); err != nil {
+		t.Fatalf("RegisterForCompetition failed: %v", err)
+	}
+	select {
+	case ev := <-sub.C:
+		if ev.Type != EventMatched {
+			t.Errorf("expected a matched event, got %+v", ev)
+		}
+	default:
+		t.Error("expected a matched event for a running competition")
+	}
+}
+
+func TestService_SubmitScore_BestScoring(t *testing.T) {
+	compID := uuid.New()
+	repo := &mockRepo{
+		GetPlayerByIDFunc: func(ctx context.Context, playerID string) (*model.Player, error) {
+			return &model.Player{PlayerID: playerID}, nil
+		},
+		GetActivePlayerCompetitionFunc: func(ctx context.Context, playerID string) (*model.PlayerCompetition, error) {
+			return &model.PlayerCompetition{PlayerID: playerID, CompetitionID: &compID, Score: 50}, nil
+		},
+		AddScoreToPlayerFunc: func(ctx context.Context, playerID string, score int) error {
+			return nil
+		},
+		GetCompetitionByIDFunc: func(ctx context.Context, competitionID string) (*model.Competition, error) {
+			return &model.Competition{CompetitionID: compID, ScoringMode: model.ScoringBest}, nil
+		},
+		GetLeaderboardByCompetitionIDFunc: func(ctx context.Context, competitionID string, viewer model.Viewer) ([]model.PlayerCompetition, error) {
+			return []model.PlayerCompetition{{PlayerID: "p1", Score: 80}}, nil
+		},
+	}
+	svc := NewService(repo, validConfig())
+	sub := svc.Hub().Subscribe(competitionTopic(compID.String()), 0)
+	defer sub.Close()
+
+	if err := svc.SubmitScore(context.Background(), "p1", 30); err != nil {
+		t.Fatalf("SubmitScore failed: %v", err)
+	}
+	select {
+	case ev := <-sub.C:
+		t.Errorf("expected no event for a score below the best, got %+v", ev)
+	default:
+	}
+	if err := svc.SubmitScore(context.Background(), "p1", 80); err != nil {
+		t.Fatalf("SubmitScore failed: %v", err)
+	}
+	ev := <-sub.C
+	if data := ev.Data.(map[string]interface{}); data["delta"] != 30 || data["score"] != 80 {
+		t.Errorf("unexpected score event: %+v", ev)
+	}
+}
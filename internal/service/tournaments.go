@@ -0,0 +1,692 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"leaderboard-service/internal/logging"
+	"leaderboard-service/internal/model"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditActionTournamentCreate is the audit action recorded for new
+// tournaments.
+const AuditActionTournamentCreate = "tournament.create"
+
+// MaxTournamentPlayers caps the players of one tournament.
+const MaxTournamentPlayers = 1024
+
+// Round-robin match points.
+const (
+	pointsWin  = 3
+	pointsDraw = 1
+)
+
+// TournamentDefinition describes a tournament to create.
+type TournamentDefinition struct {
+	Name    string
+	Format  model.TournamentFormat
+	Seeding model.Seeding
+	// PlayerIDs lists the players; with manual seeding, best seed first.
+	PlayerIDs []string
+	// GroupSize is the most players in a single-elimination match or a
+	// round-robin group.
+	GroupSize int
+	// Advance is how many players of each single-elimination match go
+	// through; at most half of GroupSize, so that every round has fewer
+	// matches than the one before. Must be 0 for round robin.
+	Advance       int
+	RoundDuration time.Duration
+	// Reason is recorded in the audit log.
+	Reason string
+}
+
+func (d TournamentDefinition) validate() error {
+	switch {
+	case strings.TrimSpace(d.Name) == "":
+		return fmt.Errorf("%w: name is required", ErrInvalidArgument)
+	case d.Format != model.TournamentSingleElimination && d.Format != model.TournamentRoundRobin:
+		return fmt.Errorf("%w: format must be SINGLE_ELIMINATION or ROUND_ROBIN", ErrInvalidArgument)
+	case d.Seeding != model.SeedByLevel && d.Seeding != model.SeedByTier && d.Seeding != model.SeedManual:
+		return fmt.Errorf("%w: seeding must be LEVEL, TIER or MANUAL", ErrInvalidArgument)
+	case len(d.PlayerIDs) < 2 || len(d.PlayerIDs) > MaxTournamentPlayers:
+		return fmt.Errorf("%w: a tournament needs between 2 and %d players", ErrInvalidArgument, MaxTournamentPlayers)
+	case d.GroupSize < 2:
+		return fmt.Errorf("%w: group_size must be at least 2", ErrInvalidArgument)
+	case d.Format == model.TournamentSingleElimination && (d.Advance < 1 || d.Advance > d.GroupSize/2):
+		return fmt.Errorf("%w: advance must be between 1 and half of group_size", ErrInvalidArgument)
+	case d.Format == model.TournamentRoundRobin && d.Advance != 0:
+		return fmt.Errorf("%w: advance only applies to single elimination", ErrInvalidArgument)
+	case d.RoundDuration < time.Minute || d.RoundDuration%time.Second != 0:
+		return fmt.Errorf("%w: round_duration must be at least 1m and a whole number of seconds", ErrInvalidArgument)
+	}
+	seen := make(map[string]bool, len(d.PlayerIDs))
+	for _, id := range d.PlayerIDs {
+		if id == "" || seen[id] {
+			return fmt.Errorf("%w: player_ids must be distinct and not empty", ErrInvalidArgument)
+		}
+		seen[id] = true
+	}
+	return nil
+}
+
+// Bracket is the state of a tournament: every round with its matches,
+// including those still to be played, and for round robin the group
+// standings.
+type Bracket struct {
+	Tournament model.Tournament `json:"tournament"`
+	Rounds     []BracketRound   `json:"rounds"`
+	Groups     []GroupTable     `json:"groups,omitempty"`
+}
+
+// Round states in a Bracket.
+const (
+	RoundPending   = "PENDING"
+	RoundActive    = "ACTIVE"
+	RoundCompleted = "COMPLETED"
+)
+
+type BracketRound struct {
+	Round   int            `json:"round"`
+	Status  string         `json:"status"`
+	Matches []BracketMatch `json:"matches"`
+}
+
+// BracketMatch is one match of a Bracket. A match that has not started yet
+// has no competition; in single elimination its players are not known yet.
+// Neither has a bye, whose players go through without playing.
+type BracketMatch struct {
+	Slot          int                     `json:"slot"`
+	Bye           bool                    `json:"bye,omitempty"`
+	Group         *int                    `json:"group,omitempty"`
+	CompetitionID *uuid.UUID              `json:"competition_id,omitempty"`
+	Status        model.CompetitionStatus `json:"status,omitempty"`
+	EndsAt        *time.Time              `json:"ends_at,omitempty"`
+	// NextSlot is the single-elimination match of the next round that this
+	// match's advancing players go through to.
+	NextSlot *int     `json:"next_slot,omitempty"`
+	Players  []string `json:"players"`
+	// Standings is the match leaderboard once it has started.
+	Standings []MatchStanding `json:"standings,omitempty"`
+}
+
+type MatchStanding struct {
+	PlayerID string `json:"player_id"`
+	Score    int    `json:"score"`
+	Rank     int    `json:"rank"`
+	// Advanced marks the players going through to the next round.
+	Advanced bool `json:"advanced,omitempty"`
+}
+
+// GroupTable ranks a round-robin group by points, then total score.
+type GroupTable struct {
+	Group     int             `json:"group"`
+	Standings []GroupStanding `json:"standings"`
+}
+
+type GroupStanding struct {
+	PlayerID string `json:"player_id"`
+	Played   int    `json:"played"`
+	Won      int    `json:"won"`
+	Drawn    int    `json:"drawn"`
+	Lost     int    `json:"lost"`
+	Points   int    `json:"points"`
+	Score    int    `json:"score"`
+}
+
+func tournamentTarget(tournamentID uuid.UUID) string {
+	return "tournament/" + tournamentID.String()
+}
+
+// seedPlayers orders players, best seed first. Ties keep the given order.
+func seedPlayers(players []*model.Player, seeding model.Seeding) []string {
+	sorted := append([]*model.Player(nil), players...)
+	switch seeding {
+	case model.SeedByLevel:
+		sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Level > sorted[j].Level })
+	case model.SeedByTier:
+		sort.SliceStable(sorted, func(i, j int) bool {
+			if ri, rj := tierRank(sorted[i].Tier), tierRank(sorted[j].Tier); ri != rj {
+				return ri > rj
+			}
+			return sorted[i].Level > sorted[j].Level
+		})
+	}
+	seeds := make([]string, len(sorted))
+	for i, p := range sorted {
+		seeds[i] = p.PlayerID
+	}
+	return seeds
+}
+
+// snakeGroups deals seeds into n groups back and forth, so that the top
+// seeds are spread out and every group is about as strong as the others.
+func snakeGroups(seeds []string, n int) [][]string {
+	groups := make([][]string, n)
+	for i, id := range seeds {
+		g := i % n
+		if (i/n)%2 == 1 {
+			g = n - 1 - g
+		}
+		groups[g] = append(groups[g], id)
+	}
+	return groups
+}
+
+func ceilDiv(a, b int) int {
+	return (a + b - 1) / b
+}
+
+// feedFactor is how many single-elimination matches feed one match of the
+// next round.
+func feedFactor(t *model.Tournament) int {
+	return t.GroupSize / t.Advance
+}
+
+// eliminationSlots returns the number of matches in each round of a
+// single-elimination tournament, the last being the final.
+func eliminationSlots(t *model.Tournament) []int {
+	slots := []int{ceilDiv(len(t.Seeds), t.GroupSize)}
+	for m := slots[0]; m > 1; {
+		m = ceilDiv(m, feedFactor(t))
+		slots = append(slots, m)
+	}
+	return slots
+}
+
+// tournamentGroups returns the round-robin groups of a tournament.
+func tournamentGroups(t *model.Tournament) [][]string {
+	return snakeGroups(t.Seeds, ceilDiv(len(t.Seeds), t.GroupSize))
+}
+
+// roundRobinRounds is the number of rounds in which everyone in the
+// largest group plays everyone else once.
+func roundRobinRounds(groups [][]string) int {
+	rounds := 0
+	for _, g := range groups {
+		rounds = max(rounds, len(g)+len(g)%2-1)
+	}
+	return rounds
+}
+
+// roundRobinPairings returns the head-to-head matches of a group in round
+// (from 1), by the circle method: the first player stays put and the others
+// rotate. With an odd number of players one of them sits each round out.
+func roundRobinPairings(group []string, round int) [][]string {
+	players := append([]string(nil), group...)
+	if len(players)%2 == 1 {
+		players = append(players, "")
+	}
+	n := len(players)
+	if round < 1 || round > n-1 {
+		return nil
+	}
+	circle := make([]string, n)
+	circle[0] = players[0]
+	for i := 1; i < n; i++ {
+		circle[i] = players[1+(i-1+round-1)%(n-1)]
+	}
+	var pairs [][]string
+	for i := 0; i < n/2; i++ {
+		a, b := circle[i], circle[n-1-i]
+		if a != "" && b != "" {
+			pairs = append(pairs, []string{a, b})
+		}
+	}
+	return pairs
+}
+
+// roundPlayers returns the players of each match of round, by slot, and for
+// round robin the group of each match. The single-elimination matches of
+// rounds after the first take the advancing players of the previous round,
+// from the standings of its matches, keyed by slot.
+func roundPlayers(t *model.Tournament, round int, standings map[int][]model.PlayerCompetition) (players [][]string, groups []int) {
+	if t.Format == model.TournamentRoundRobin {
+		for g, group := range tournamentGroups(t) {
+			for _, pair := range roundRobinPairings(group, round) {
+				players = append(players, pair)
+				groups = append(groups, g)
+			}
+		}
+		return players, groups
+	}
+	slots := eliminationSlots(t)
+	if round == 1 {
+		return snakeGroups(t.Seeds, slots[0]), make([]int, slots[0])
+	}
+	players = make([][]string, slots[round-1])
+	f := feedFactor(t)
+	for rank := 0; rank < t.Advance; rank++ {
+		for slot := 0; slot < slots[round-2]; slot++ {
+			if lb := standings[slot]; rank < len(lb) {
+				players[slot/f] = append(players[slot/f], lb[rank].PlayerID)
+			}
+		}
+	}
+	return players, make([]int, len(players))
+}
+
+// isBye reports whether a single-elimination match of round with players
+// would knock nobody out: its players go through without playing, and the
+// lone player of a final wins it. A match without players is a bye too,
+// e.g. after the matches feeding it were cancelled.
+func isBye(t *model.Tournament, round int, players []string) bool {
+	if t.Format != model.TournamentSingleElimination {
+		return false
+	}
+	through := t.Advance
+	if round == t.Rounds {
+		through = 1
+	}
+	return len(players) <= through
+}
+
+// eliminationStandings returns the standings of the single-elimination
+// matches of round, by slot, from the leaderboards of the matches played,
+// keyed by round and slot. Byes are ranked by seed; a match that was
+// cancelled, or has not finished, has no standings.
+func eliminationStandings(t *model.Tournament, round int, played map[int]map[int][]model.PlayerCompetition) map[int][]model.PlayerCompetition {
+	var standings map[int][]model.PlayerCompetition
+	for r := 1; r <= round; r++ {
+		players, _ := roundPlayers(t, r, standings)
+		standings = make(map[int][]model.PlayerCompetition, len(players))
+		for slot, ps := range players {
+			if !isBye(t, r, ps) {
+				if lb, ok := played[r][slot]; ok {
+					standings[slot] = lb
+				}
+				continue
+			}
+			for _, id := range ps {
+				standings[slot] = append(standings[slot], model.PlayerCompetition{PlayerID: id})
+			}
+		}
+	}
+	return standings
+}
+
+// playedStandings keys the leaderboards of matches, by competition, by
+// round and slot.
+func playedStandings(matches []model.TournamentMatch, standings map[uuid.UUID][]model.PlayerCompetition) map[int]map[int][]model.PlayerCompetition {
+	played := make(map[int]map[int][]model.PlayerCompetition)
+	for _, m := range matches {
+		lb, ok := standings[m.CompetitionID]
+		if !ok {
+			continue
+		}
+		if played[m.Round] == nil {
+			played[m.Round] = make(map[int][]model.PlayerCompetition)
+		}
+		played[m.Round][m.Slot] = lb
+	}
+	return played
+}
+
+// newRound builds the matches of round and their competitions, leaving out
+// byes, from the standings of the previous round.
+func newRound(t *model.Tournament, round int, standings map[int][]model.PlayerCompetition, now time.Time) ([]model.TournamentMatch, []model.Competition) {
+	players, groups := roundPlayers(t, round, standings)
+	var matches []model.TournamentMatch
+	var comps []model.Competition
+	for slot := range players {
+		if isBye(t, round, players[slot]) {
+			continue
+		}
+		comp := model.Competition{
+			CompetitionID: uuid.New(),
+			Name:          fmt.Sprintf("%s: round %d, match %d", t.Name, round, slot+1),
+			Kind:          model.CompetitionKindTournament,
+			StartedAt:     now,
+			EndsAt:        now.Add(t.RoundDuration),
+			Status:        model.CompetitionActive,
+			ScoringMode:   model.ScoringSum,
+		}
+		comps = append(comps, comp)
+		matches = append(matches, model.TournamentMatch{
+			TournamentID:  t.TournamentID,
+			Round:         round,
+			Slot:          slot,
+			Group:         groups[slot],
+			CompetitionID: comp.CompetitionID,
+			Players:       players[slot],
+			Status:        model.CompetitionActive,
+			EndsAt:        comp.EndsAt,
+		})
+	}
+	return matches, comps
+}
+
+// CreateTournament seeds the players and starts the first round. Players
+// entered into a match leave the matchmaking queue and get a matched
+// message, like players of any other competition.
+func (s *Service) CreateTournament(ctx context.Context, def TournamentDefinition) (*model.Tournament, error) {
+	actor, err := requireActor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := def.validate(); err != nil {
+		return nil, err
+	}
+	players := make([]*model.Player, len(def.PlayerIDs))
+	for i, id := range def.PlayerIDs {
+		p, err := s.repo.GetPlayerByID(ctx, id)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: player %s not found", ErrInvalidArgument, id)
+		}
+		if err != nil {
+			logger(ctx).Error("error fetching tournament player", "player_id", id, "error", err)
+			return nil, err
+		}
+		players[i] = p
+	}
+
+	t := &model.Tournament{
+		TournamentID:  uuid.New(),
+		Name:          strings.TrimSpace(def.Name),
+		Format:        def.Format,
+		Seeding:       def.Seeding,
+		Seeds:         seedPlayers(players, def.Seeding),
+		GroupSize:     def.GroupSize,
+		Advance:       def.Advance,
+		RoundDuration: def.RoundDuration,
+		Round:         1,
+		Status:        model.TournamentActive,
+	}
+	if t.Format == model.TournamentRoundRobin {
+		t.Rounds = roundRobinRounds(tournamentGroups(t))
+	} else {
+		t.Rounds = len(eliminationSlots(t))
+	}
+	matches, comps := newRound(t, 1, nil, time.Now())
+	entry := newAuditEntry(actor, AuditActionTournamentCreate, tournamentTarget(t.TournamentID), def.Reason, nil, t)
+	if err := s.repo.CreateTournament(ctx, t, matches, comps, entry); err != nil {
+		return nil, err
+	}
+	for i := range comps {
+		s.publishMatched(&comps[i], matches[i].Players)
+	}
+	logger(ctx).Info("tournament created", "tournament_id", t.TournamentID, "actor", actor, "audit_id", entry.ID,
+		"format", t.Format, "players", len(t.Seeds), "rounds", t.Rounds)
+	return t, nil
+}
+
+// GetTournament returns a tournament's bracket.
+func (s *Service) GetTournament(ctx context.Context, tournamentID string) (*Bracket, error) {
+	id, err := uuid.Parse(tournamentID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: tournament not found", ErrNotFound)
+	}
+	t, err := s.repo.GetTournament(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: tournament not found", ErrNotFound)
+	}
+	if err != nil {
+		logger(ctx).Error("error fetching tournament", "tournament_id", tournamentID, "error", err)
+		return nil, err
+	}
+	matches, err := s.repo.ListTournamentMatches(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	standings := make(map[uuid.UUID][]model.PlayerCompetition, len(matches))
+	for _, m := range matches {
+		lb, err := s.repo.GetLeaderboardByCompetitionID(ctx, m.CompetitionID.String(), model.Viewer{All: true})
+		if err != nil {
+			logger(ctx).Error("error fetching match leaderboard", "competition_id", m.CompetitionID, "error", err)
+			return nil, err
+		}
+		standings[m.CompetitionID] = lb
+	}
+	return buildBracket(t, matches, standings), nil
+}
+
+func buildBracket(t *model.Tournament, matches []model.TournamentMatch, standings map[uuid.UUID][]model.PlayerCompetition) *Bracket {
+	b := &Bracket{Tournament: *t, Rounds: make([]BracketRound, t.Rounds)}
+	var slots []int
+	if t.Format == model.TournamentSingleElimination {
+		slots = eliminationSlots(t)
+	}
+	for i := range b.Rounds {
+		round := i + 1
+		br := BracketRound{Round: round, Status: RoundPending, Matches: []BracketMatch{}}
+		switch {
+		case round < t.Round || t.Status == model.TournamentCompleted:
+			br.Status = RoundCompleted
+		case round == t.Round:
+			br.Status = RoundActive
+		}
+		// Lay out the matches still to be played, then fill in those
+		// that have started.
+		if br.Status == RoundPending {
+			if t.Format == model.TournamentRoundRobin {
+				players, groups := roundPlayers(t, round, nil)
+				for slot := range players {
+					br.Matches = append(br.Matches, BracketMatch{Slot: slot, Group: &groups[slot], Players: players[slot]})
+				}
+			} else {
+				for slot := 0; slot < slots[i]; slot++ {
+					br.Matches = append(br.Matches, BracketMatch{Slot: slot, Players: []string{}})
+				}
+			}
+		}
+		b.Rounds[i] = br
+	}
+	for _, m := range matches {
+		if m.Round < 1 || m.Round > len(b.Rounds) {
+			continue
+		}
+		bm := BracketMatch{
+			Slot:          m.Slot,
+			CompetitionID: &m.CompetitionID,
+			Status:        m.Status,
+			EndsAt:        &m.EndsAt,
+			Players:       m.Players,
+		}
+		finished := m.Status == model.CompetitionCompleted
+		for i, pc := range standings[m.CompetitionID] {
+			st := MatchStanding{PlayerID: pc.PlayerID, Score: pc.Score, Rank: i + 1}
+			if t.Format == model.TournamentSingleElimination && finished && m.Round < t.Rounds {
+				st.Advanced = i < t.Advance
+			}
+			bm.Standings = append(bm.Standings, st)
+		}
+		if t.Format == model.TournamentRoundRobin {
+			group := m.Group
+			bm.Group = &group
+		}
+		rounds := &b.Rounds[m.Round-1]
+		rounds.Matches = append(rounds.Matches, bm)
+	}
+	if t.Format == model.TournamentSingleElimination {
+		played := playedStandings(matches, standings)
+		var previous map[int][]model.PlayerCompetition
+		for round := 1; round <= min(t.Round, t.Rounds); round++ {
+			players, _ := roundPlayers(t, round, previous)
+			for slot, ps := range players {
+				if len(ps) > 0 && isBye(t, round, ps) {
+					rounds := &b.Rounds[round-1]
+					rounds.Matches = append(rounds.Matches, BracketMatch{Slot: slot, Bye: true, Players: ps})
+				}
+			}
+			previous = eliminationStandings(t, round, played)
+		}
+	}
+	for i := range b.Rounds {
+		sort.SliceStable(b.Rounds[i].Matches, func(x, y int) bool { return b.Rounds[i].Matches[x].Slot < b.Rounds[i].Matches[y].Slot })
+		for j := range b.Rounds[i].Matches {
+			bm := &b.Rounds[i].Matches[j]
+			if t.Format == model.TournamentSingleElimination && i+1 < t.Rounds {
+				next := bm.Slot / feedFactor(t)
+				bm.NextSlot = &next
+			}
+		}
+	}
+	if t.Format == model.TournamentRoundRobin {
+		b.Groups = groupTables(t, matches, standings)
+	}
+	return b
+}
+
+// groupTables scores every finished round-robin match: the higher score
+// wins, equal scores draw, and a player left alone after the other was
+// taken out of the match wins.
+func groupTables(t *model.Tournament, matches []model.TournamentMatch, standings map[uuid.UUID][]model.PlayerCompetition) []GroupTable {
+	groups := tournamentGroups(t)
+	rows := make(map[string]*GroupStanding, len(t.Seeds))
+	seed := make(map[string]int, len(t.Seeds))
+	for i, id := range t.Seeds {
+		rows[id] = &GroupStanding{PlayerID: id}
+		seed[id] = i
+	}
+	for _, m := range matches {
+		lb := standings[m.CompetitionID]
+		if m.Status != model.CompetitionCompleted || len(lb) == 0 {
+			continue
+		}
+		for _, pc := range lb {
+			if row := rows[pc.PlayerID]; row != nil {
+				row.Played++
+				row.Score += pc.Score
+			}
+		}
+		first := rows[lb[0].PlayerID]
+		if first == nil {
+			continue
+		}
+		if len(lb) > 1 && lb[1].Score == lb[0].Score {
+			for _, pc := range lb[:2] {
+				if row := rows[pc.PlayerID]; row != nil {
+					row.Drawn++
+					row.Points += pointsDraw
+				}
+			}
+			continue
+		}
+		first.Won++
+		first.Points += pointsWin
+		for _, pc := range lb[1:] {
+			if row := rows[pc.PlayerID]; row != nil {
+				row.Lost++
+			}
+		}
+	}
+	tables := make([]GroupTable, len(groups))
+	for g, group := range groups {
+		table := GroupTable{Group: g, Standings: make([]GroupStanding, len(group))}
+		for i, id := range group {
+			table.Standings[i] = *rows[id]
+		}
+		sort.SliceStable(table.Standings, func(i, j int) bool {
+			a, b := table.Standings[i], table.Standings[j]
+			if a.Points != b.Points {
+				return a.Points > b.Points
+			}
+			if a.Score != b.Score {
+				return a.Score > b.Score
+			}
+			return seed[a.PlayerID] < seed[b.PlayerID]
+		})
+		tables[g] = table
+	}
+	return tables
+}
+
+// advanceTournaments starts the next round of every tournament whose
+// current round's matches are all over, or completes the tournament after
+// its last round.
+func (s *Service) advanceTournaments(ctx context.Context) error {
+	tournaments, err := s.repo.ActiveTournaments(ctx)
+	if err != nil {
+		workerLogger(ctx).Error("error fetching active tournaments", "error", err)
+		return err
+	}
+	var passErr error
+	for i := range tournaments {
+		ctx := logging.With(ctx, "tournament_id", tournaments[i].TournamentID)
+		if err := s.advanceTournament(ctx, &tournaments[i]); err != nil {
+			workerLogger(ctx).Error("error advancing tournament", "error", err)
+			if passErr == nil {
+				passErr = err
+			}
+		}
+	}
+	return passErr
+}
+
+func (s *Service) advanceTournament(ctx context.Context, t *model.Tournament) error {
+	matches, err := s.repo.ListTournamentMatches(ctx, t.TournamentID)
+	if err != nil {
+		return err
+	}
+	for _, m := range matches {
+		if m.Round == t.Round && m.Status != model.CompetitionCompleted && m.Status != model.CompetitionCancelled {
+			return nil
+		}
+	}
+	byCompetition := make(map[uuid.UUID][]model.PlayerCompetition, len(matches))
+	for _, m := range matches {
+		if m.Status == model.CompetitionCancelled {
+			continue
+		}
+		// Brackets name their players anyway, so shadow-banned players
+		// advance like everyone else.
+		lb, err := s.repo.GetLeaderboardByCompetitionID(ctx, m.CompetitionID.String(), model.Viewer{All: true})
+		if err != nil {
+			return err
+		}
+		byCompetition[m.CompetitionID] = lb
+	}
+
+	if t.Round >= t.Rounds {
+		winners := tournamentWinners(t, matches, byCompetition)
+		ok, err := s.repo.CompleteTournament(ctx, t.TournamentID, t.Round, winners)
+		if err != nil || !ok {
+			return err
+		}
+		workerLogger(ctx).Info("tournament completed", "winners", winners)
+		return nil
+	}
+
+	round := t.Round + 1
+	var standings map[int][]model.PlayerCompetition
+	if t.Format == model.TournamentSingleElimination {
+		standings = eliminationStandings(t, t.Round, playedStandings(matches, byCompetition))
+	}
+	next, comps := newRound(t, round, standings, time.Now())
+	ok, err := s.repo.StartTournamentRound(ctx, t.TournamentID, round, next, comps)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		workerLogger(ctx).Debug("tournament round already started", "round", round)
+		return nil
+	}
+	for i := range comps {
+		s.publishMatched(&comps[i], next[i].Players)
+	}
+	workerLogger(ctx).Info("started tournament round", "round", round, "matches", len(next))
+	return nil
+}
+
+// tournamentWinners returns the winner of a single-elimination final, or
+// the leader of each round-robin group.
+func tournamentWinners(t *model.Tournament, matches []model.TournamentMatch, standings map[uuid.UUID][]model.PlayerCompetition) []string {
+	var winners []string
+	if t.Format == model.TournamentRoundRobin {
+		for _, table := range groupTables(t, matches, standings) {
+			if len(table.Standings) > 0 {
+				winners = append(winners, table.Standings[0].PlayerID)
+			}
+		}
+		return winners
+	}
+	if lb := eliminationStandings(t, t.Rounds, playedStandings(matches, standings))[0]; len(lb) > 0 {
+		winners = append(winners, lb[0].PlayerID)
+	}
+	return winners
+}
@@ -0,0 +1,358 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"leaderboard-service/internal/auth"
+	"leaderboard-service/internal/model"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func validTournament() TournamentDefinition {
+	return TournamentDefinition{Name: "Cup", Format: model.TournamentSingleElimination, Seeding: model.SeedByLevel,
+		PlayerIDs: []string{"p1", "p2", "p3", "p4"}, GroupSize: 2, Advance: 1, RoundDuration: time.Hour}
+}
+
+func TestTournamentDefinition_Validate(t *testing.T) {
+	cases := map[string]func(d *TournamentDefinition){
+		"no name":           func(d *TournamentDefinition) { d.Name = " " },
+		"unknown format":    func(d *TournamentDefinition) { d.Format = "SWISS" },
+		"unknown seeding":   func(d *TournamentDefinition) { d.Seeding = "RANDOM" },
+		"one player":        func(d *TournamentDefinition) { d.PlayerIDs = d.PlayerIDs[:1] },
+		"duplicate player":  func(d *TournamentDefinition) { d.PlayerIDs[1] = "p1" },
+		"advance too large": func(d *TournamentDefinition) { d.Advance = 2 },
+		"no advance":        func(d *TournamentDefinition) { d.Advance = 0 },
+		"round robin advance": func(d *TournamentDefinition) {
+			d.Format = model.TournamentRoundRobin
+		},
+		"short round": func(d *TournamentDefinition) { d.RoundDuration = time.Second },
+	}
+	for name, mutate := range cases {
+		def := validTournament()
+		mutate(&def)
+		if err := def.validate(); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("%s: expected ErrInvalidArgument, got %v", name, err)
+		}
+	}
+	if err := validTournament().validate(); err != nil {
+		t.Errorf("expected a valid definition, got %v", err)
+	}
+}
+
+func TestTournamentLayout(t *testing.T) {
+	seeds := []string{"s1", "s2", "s3", "s4", "s5", "s6", "s7", "s8"}
+	if got := fmt.Sprint(snakeGroups(seeds, 3)); got != "[[s1 s6 s7] [s2 s5 s8] [s3 s4]]" {
+		t.Errorf("unexpected snake groups: %s", got)
+	}
+
+	// 20 players in matches of 4 with the top 2 going through: 5 matches,
+	// then 3, 2 and the final.
+	players := make([]string, 20)
+	for i := range players {
+		players[i] = fmt.Sprintf("p%d", i+1)
+	}
+	se := &model.Tournament{Seeds: players, GroupSize: 4, Advance: 2}
+	if got := fmt.Sprint(eliminationSlots(se)); got != "[5 3 2 1]" {
+		t.Errorf("unexpected elimination slots: %s", got)
+	}
+
+	// Everyone meets everyone else exactly once, sitting out once with an
+	// odd number of players.
+	group := []string{"a", "b", "c", "d", "e"}
+	if rounds := roundRobinRounds([][]string{group, {"x", "y"}}); rounds != 5 {
+		t.Fatalf("expected 5 rounds, got %d", rounds)
+	}
+	met := map[string]int{}
+	for round := 1; round <= 5; round++ {
+		played := map[string]bool{}
+		for _, pair := range roundRobinPairings(group, round) {
+			if played[pair[0]] || played[pair[1]] {
+				t.Errorf("round %d: a player plays twice: %v", round, pair)
+			}
+			played[pair[0]], played[pair[1]] = true, true
+			met[pair[0]+pair[1]]++
+			met[pair[1]+pair[0]]++
+		}
+	}
+	for _, a := range group {
+		for _, b := range group {
+			if a != b && met[a+b] != 1 {
+				t.Errorf("%s and %s met %d times", a, b, met[a+b])
+			}
+		}
+	}
+}
+
+func TestService_CreateTournament(t *testing.T) {
+	levels := map[string]int{"p1": 1, "p2": 4, "p3": 3, "p4": 2}
+	var stored *model.Tournament
+	var matches []model.TournamentMatch
+	var comps []model.Competition
+	var audit *model.AuditEntry
+	repo := &mockRepo{
+		GetPlayerByIDFunc: func(ctx context.Context, playerID string) (*model.Player, error) {
+			level, ok := levels[playerID]
+			if !ok {
+				return nil, sql.ErrNoRows
+			}
+			return &model.Player{PlayerID: playerID, Level: level}, nil
+		},
+		CreateTournamentFunc: func(ctx context.Context, tournament *model.Tournament, m []model.TournamentMatch, c []model.Competition, entry *model.AuditEntry) error {
+			stored, matches, comps, audit = tournament, m, c, entry
+			return nil
+		},
+	}
+	svc := NewService(repo, validConfig())
+	ctx := auth.WithActor(context.Background(), "alice")
+
+	if _, err := svc.CreateTournament(context.Background(), validTournament()); err == nil {
+		t.Error("expected an error without an actor")
+	}
+	ghost := validTournament()
+	ghost.PlayerIDs[3] = "ghost"
+	if _, err := svc.CreateTournament(ctx, ghost); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("expected ErrInvalidArgument for an unknown player, got %v", err)
+	}
+
+	got, err := svc.CreateTournament(ctx, validTournament())
+	if err != nil {
+		t.Fatalf("CreateTournament failed: %v", err)
+	}
+	if got != stored || got.Rounds != 2 || got.Round != 1 || fmt.Sprint(got.Seeds) != "[p2 p3 p4 p1]" {
+		t.Errorf("unexpected tournament: %+v", got)
+	}
+	// The best seeds meet as late as possible.
+	if len(matches) != 2 || fmt.Sprint(matches[0].Players) != "[p2 p1]" || fmt.Sprint(matches[1].Players) != "[p3 p4]" {
+		t.Errorf("unexpected first round: %+v", matches)
+	}
+	if len(comps) != 2 || comps[0].Kind != model.CompetitionKindTournament || comps[0].CompetitionID != matches[0].CompetitionID ||
+		comps[0].EndsAt.Sub(comps[0].StartedAt) != time.Hour {
+		t.Errorf("unexpected competitions: %+v", comps)
+	}
+	if audit == nil || audit.Actor != "alice" || audit.Action != AuditActionTournamentCreate {
+		t.Errorf("unexpected audit entry: %+v", audit)
+	}
+}
+
+func TestService_RunMatchmaking_AdvancesTournament(t *testing.T) {
+	tournament := model.Tournament{TournamentID: uuid.New(), Name: "Cup", Format: model.TournamentSingleElimination,
+		Seeds: []string{"p1", "p2", "p3", "p4", "p5", "p6", "p7", "p8"}, GroupSize: 4, Advance: 2, RoundDuration: time.Hour,
+		Round: 1, Rounds: 2, Status: model.TournamentActive}
+	first, second := uuid.New(), uuid.New()
+	matches := []model.TournamentMatch{
+		{TournamentID: tournament.TournamentID, Round: 1, Slot: 0, CompetitionID: first, Status: model.CompetitionCompleted},
+		{TournamentID: tournament.TournamentID, Round: 1, Slot: 1, CompetitionID: second, Status: model.CompetitionCompleted},
+	}
+	leaderboards := map[string][]model.PlayerCompetition{
+		first.String():  {{PlayerID: "p5", Score: 9}, {PlayerID: "p1", Score: 7}, {PlayerID: "p4", Score: 3}, {PlayerID: "p8"}},
+		second.String(): {{PlayerID: "p2", Score: 8}, {PlayerID: "p6", Score: 6}, {PlayerID: "p3", Score: 2}, {PlayerID: "p7"}},
+	}
+	var started []model.TournamentMatch
+	var winners []string
+	repo := &mockRepo{
+		ActiveTournamentsFunc: func(ctx context.Context) ([]model.Tournament, error) {
+			return []model.Tournament{tournament}, nil
+		},
+		ListTournamentMatchesFunc: func(ctx context.Context, tournamentID uuid.UUID) ([]model.TournamentMatch, error) {
+			return matches, nil
+		},
+		GetLeaderboardByCompetitionIDFunc: func(ctx context.Context, competitionID string, viewer model.Viewer) ([]model.PlayerCompetition, error) {
+			return leaderboards[competitionID], nil
+		},
+		StartTournamentRoundFunc: func(ctx context.Context, tournamentID uuid.UUID, round int, m []model.TournamentMatch, c []model.Competition) (bool, error) {
+			if round != 2 {
+				t.Errorf("expected round 2 to start, got %d", round)
+			}
+			started = m
+			return true, nil
+		},
+		CompleteTournamentFunc: func(ctx context.Context, tournamentID uuid.UUID, round int, w []string) (bool, error) {
+			winners = w
+			return true, nil
+		},
+		GetActiveCompetitionFunc: func(ctx context.Context) (*model.Competition, error) {
+			return &model.Competition{}, nil
+		},
+	}
+	svc := NewService(repo, validConfig())
+
+	if err := svc.runMatchmaking(context.Background()); err != nil {
+		t.Fatalf("runMatchmaking failed: %v", err)
+	}
+	if len(started) != 1 || fmt.Sprint(started[0].Players) != "[p5 p2 p1 p6]" {
+		t.Fatalf("expected the top two of each match in the final, got %+v", started)
+	}
+
+	// The final is over: the tournament completes with its winner.
+	final := uuid.New()
+	tournament.Round = 2
+	matches = append(matches, model.TournamentMatch{TournamentID: tournament.TournamentID, Round: 2, CompetitionID: final,
+		Players: started[0].Players, Status: model.CompetitionCompleted})
+	leaderboards[final.String()] = []model.PlayerCompetition{{PlayerID: "p6", Score: 12}, {PlayerID: "p5", Score: 10}}
+	if err := svc.runMatchmaking(context.Background()); err != nil {
+		t.Fatalf("runMatchmaking failed: %v", err)
+	}
+	if fmt.Sprint(winners) != "[p6]" {
+		t.Errorf("expected p6 to win, got %v", winners)
+	}
+}
+
+func TestTournament_OddEntrantsGetByes(t *testing.T) {
+	tournament := &model.Tournament{TournamentID: uuid.New(), Name: "Cup", Format: model.TournamentSingleElimination,
+		Seeds: []string{"s1", "s2", "s3"}, GroupSize: 2, Advance: 1, RoundDuration: time.Hour, Round: 1, Rounds: 2,
+		Status: model.TournamentActive}
+
+	// The top seed sits the first round out.
+	matches, comps := newRound(tournament, 1, nil, time.Now())
+	if len(matches) != 1 || len(comps) != 1 || matches[0].Slot != 1 || fmt.Sprint(matches[0].Players) != "[s2 s3]" {
+		t.Fatalf("expected one match for s2 and s3, got %+v", matches)
+	}
+	matches[0].Status = model.CompetitionCompleted
+	played := map[uuid.UUID][]model.PlayerCompetition{matches[0].CompetitionID: {{PlayerID: "s3", Score: 4}, {PlayerID: "s2"}}}
+	b := buildBracket(tournament, matches, played)
+	if m := b.Rounds[0].Matches; len(m) != 2 || !m[0].Bye || fmt.Sprint(m[0].Players) != "[s1]" || m[1].CompetitionID == nil {
+		t.Errorf("expected a bye for s1 before the match, got %+v", m)
+	}
+
+	final, _ := newRound(tournament, 2, eliminationStandings(tournament, 1, playedStandings(matches, played)), time.Now())
+	if len(final) != 1 || fmt.Sprint(final[0].Players) != "[s1 s3]" {
+		t.Errorf("expected s1 to meet s3 in the final, got %+v", final)
+	}
+}
+
+func TestService_RunMatchmaking_TournamentCancelledFeeders(t *testing.T) {
+	tournament := model.Tournament{TournamentID: uuid.New(), Name: "Cup", Format: model.TournamentSingleElimination,
+		Seeds: []string{"p1", "p2", "p3", "p4", "p5", "p6", "p7", "p8"}, GroupSize: 2, Advance: 1, RoundDuration: time.Hour,
+		Round: 1, Rounds: 3, Status: model.TournamentActive}
+	// Round 1: 4 matches, of which the two feeding the first semi-final
+	// and one feeding the second were cancelled.
+	var matches []model.TournamentMatch
+	leaderboards := map[string][]model.PlayerCompetition{}
+	for slot, players := range [][]string{{"p1", "p8"}, {"p2", "p7"}, {"p3", "p6"}, {"p4", "p5"}} {
+		m := model.TournamentMatch{TournamentID: tournament.TournamentID, Round: 1, Slot: slot, CompetitionID: uuid.New(),
+			Players: players, Status: model.CompetitionCancelled}
+		if slot == 3 {
+			m.Status = model.CompetitionCompleted
+			leaderboards[m.CompetitionID.String()] = []model.PlayerCompetition{{PlayerID: "p5", Score: 3}, {PlayerID: "p4"}}
+		}
+		matches = append(matches, m)
+	}
+	var started []model.TournamentMatch
+	var winners []string
+	repo := &mockRepo{
+		ActiveTournamentsFunc: func(ctx context.Context) ([]model.Tournament, error) {
+			return []model.Tournament{tournament}, nil
+		},
+		ListTournamentMatchesFunc: func(ctx context.Context, tournamentID uuid.UUID) ([]model.TournamentMatch, error) {
+			return matches, nil
+		},
+		GetLeaderboardByCompetitionIDFunc: func(ctx context.Context, competitionID string, viewer model.Viewer) ([]model.PlayerCompetition, error) {
+			return leaderboards[competitionID], nil
+		},
+		StartTournamentRoundFunc: func(ctx context.Context, tournamentID uuid.UUID, round int, m []model.TournamentMatch, c []model.Competition) (bool, error) {
+			if len(m) != len(c) {
+				t.Errorf("round %d: %d matches but %d competitions", round, len(m), len(c))
+			}
+			started = m
+			tournament.Round = round
+			return true, nil
+		},
+		CompleteTournamentFunc: func(ctx context.Context, tournamentID uuid.UUID, round int, w []string) (bool, error) {
+			winners = w
+			return true, nil
+		},
+		GetActiveCompetitionFunc: func(ctx context.Context) (*model.Competition, error) {
+			return &model.Competition{}, nil
+		},
+	}
+	svc := NewService(repo, validConfig())
+
+	// The first semi-final has nobody to play and the second only p5, who
+	// goes through without playing.
+	if err := svc.runMatchmaking(context.Background()); err != nil {
+		t.Fatalf("runMatchmaking failed: %v", err)
+	}
+	if tournament.Round != 2 || len(started) != 0 {
+		t.Fatalf("expected round 2 to start without matches, got round %d with %+v", tournament.Round, started)
+	}
+	// p5 is alone in the final too, and wins it.
+	if err := svc.runMatchmaking(context.Background()); err != nil {
+		t.Fatalf("runMatchmaking failed: %v", err)
+	}
+	if tournament.Round != 3 || len(started) != 0 {
+		t.Fatalf("expected the final to start without matches, got round %d with %+v", tournament.Round, started)
+	}
+	if err := svc.runMatchmaking(context.Background()); err != nil {
+		t.Fatalf("runMatchmaking failed: %v", err)
+	}
+	if fmt.Sprint(winners) != "[p5]" {
+		t.Errorf("expected p5 to win, got %v", winners)
+	}
+}
+
+func TestService_RunMatchmaking_WaitsForTournamentRound(t *testing.T) {
+	repo := &mockRepo{
+		ActiveTournamentsFunc: func(ctx context.Context) ([]model.Tournament, error) {
+			return []model.Tournament{{TournamentID: uuid.New(), Format: model.TournamentRoundRobin, Round: 1, Rounds: 3}}, nil
+		},
+		ListTournamentMatchesFunc: func(ctx context.Context, tournamentID uuid.UUID) ([]model.TournamentMatch, error) {
+			return []model.TournamentMatch{
+				{Round: 1, Slot: 0, Status: model.CompetitionCompleted},
+				{Round: 1, Slot: 1, Status: model.CompetitionActive},
+			}, nil
+		},
+		GetActiveCompetitionFunc: func(ctx context.Context) (*model.Competition, error) {
+			return &model.Competition{}, nil
+		},
+	}
+	if err := NewService(repo, validConfig()).runMatchmaking(context.Background()); err != nil {
+		t.Fatalf("runMatchmaking failed: %v", err)
+	}
+}
+
+func TestService_GetTournament_RoundRobin(t *testing.T) {
+	tournament := &model.Tournament{TournamentID: uuid.New(), Format: model.TournamentRoundRobin, Seeds: []string{"a", "b", "c"},
+		GroupSize: 3, Round: 1, Rounds: 3, Status: model.TournamentActive}
+	played := uuid.New()
+	repo := &mockRepo{
+		GetTournamentFunc: func(ctx context.Context, tournamentID uuid.UUID) (*model.Tournament, error) {
+			if tournamentID != tournament.TournamentID {
+				return nil, sql.ErrNoRows
+			}
+			return tournament, nil
+		},
+		ListTournamentMatchesFunc: func(ctx context.Context, tournamentID uuid.UUID) ([]model.TournamentMatch, error) {
+			return []model.TournamentMatch{{Round: 1, CompetitionID: played, Players: []string{"a", "c"}, Status: model.CompetitionCompleted}}, nil
+		},
+		GetLeaderboardByCompetitionIDFunc: func(ctx context.Context, competitionID string, viewer model.Viewer) ([]model.PlayerCompetition, error) {
+			return []model.PlayerCompetition{{PlayerID: "c", Score: 5}, {PlayerID: "a", Score: 2}}, nil
+		},
+	}
+	svc := NewService(repo, validConfig())
+
+	if _, err := svc.GetTournament(context.Background(), uuid.NewString()); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	b, err := svc.GetTournament(context.Background(), tournament.TournamentID.String())
+	if err != nil {
+		t.Fatalf("GetTournament failed: %v", err)
+	}
+	if len(b.Rounds) != 3 || b.Rounds[0].Status != RoundActive || b.Rounds[1].Status != RoundPending {
+		t.Fatalf("unexpected rounds: %+v", b.Rounds)
+	}
+	if m := b.Rounds[1].Matches; len(m) != 1 || m[0].CompetitionID != nil || len(m[0].Players) != 2 {
+		t.Errorf("expected the pending pairing of round 2, got %+v", m)
+	}
+	if len(b.Groups) != 1 {
+		t.Fatalf("expected one group, got %+v", b.Groups)
+	}
+	leader := b.Groups[0].Standings[0]
+	if leader.PlayerID != "c" || leader.Won != 1 || leader.Points != pointsWin || b.Groups[0].Standings[1].Lost != 1 {
+		t.Errorf("unexpected group table: %+v", b.Groups[0].Standings)
+	}
+}
@@ -0,0 +1,661 @@
+package tracing
+
+import (
+	"context"
+	"leaderboard-service/internal/model"
+	"leaderboard-service/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracedRepository starts a client span for every repository call, tagged
+// with the SQL operation and table it runs.
+type tracedRepository struct {
+	next repository.RepositoryInterface
+}
+
+// NewRepository wraps next so that every call is traced.
+func NewRepository(next repository.RepositoryInterface) repository.RepositoryInterface {
+	return &tracedRepository{next: next}
+}
+
+func startQuery(ctx context.Context, method, operation, table string) (context.Context, trace.Span) {
+	return tracer().Start(ctx, "repository."+method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", "postgresql"),
+			attribute.String("db.operation.name", operation),
+			attribute.String("db.collection.name", table),
+		),
+	)
+}
+
+func (r *tracedRepository) CreatePlayer(ctx context.Context, player *model.Player) error {
+	ctx, span := startQuery(ctx, "CreatePlayer", "INSERT", "players")
+	defer span.End()
+	err := r.next.CreatePlayer(ctx, player)
+	finish(span, err)
+	return err
+}
+
+func (r *tracedRepository) GetPlayerByID(ctx context.Context, playerID string) (*model.Player, error) {
+	ctx, span := startQuery(ctx, "GetPlayerByID", "SELECT", "players")
+	defer span.End()
+	player, err := r.next.GetPlayerByID(ctx, playerID)
+	finish(span, err)
+	return player, err
+}
+
+func (r *tracedRepository) UpdatePlayer(ctx context.Context, player *model.Player) error {
+	ctx, span := startQuery(ctx, "UpdatePlayer", "UPDATE", "players")
+	defer span.End()
+	err := r.next.UpdatePlayer(ctx, player)
+	finish(span, err)
+	return err
+}
+
+func (r *tracedRepository) CreateCompetition(ctx context.Context, comp *model.Competition) error {
+	ctx, span := startQuery(ctx, "CreateCompetition", "INSERT", "competitions")
+	defer span.End()
+	err := r.next.CreateCompetition(ctx, comp)
+	finish(span, err)
+	return err
+}
+
+func (r *tracedRepository) GetCompetitionByID(ctx context.Context, competitionID string) (*model.Competition, error) {
+	ctx, span := startQuery(ctx, "GetCompetitionByID", "SELECT", "competitions")
+	defer span.End()
+	comp, err := r.next.GetCompetitionByID(ctx, competitionID)
+	finish(span, err)
+	return comp, err
+}
+
+func (r *tracedRepository) UpdateCompetition(ctx context.Context, comp *model.Competition) error {
+	ctx, span := startQuery(ctx, "UpdateCompetition", "UPDATE", "competitions")
+	defer span.End()
+	err := r.next.UpdateCompetition(ctx, comp)
+	finish(span, err)
+	return err
+}
+
+func (r *tracedRepository) GetActiveCompetition(ctx context.Context) (*model.Competition, error) {
+	ctx, span := startQuery(ctx, "GetActiveCompetition", "SELECT", "competitions")
+	defer span.End()
+	comp, err := r.next.GetActiveCompetition(ctx)
+	finish(span, err)
+	return comp, err
+}
+
+func (r *tracedRepository) CreatePlayerCompetition(ctx context.Context, pc *model.PlayerCompetition) error {
+	ctx, span := startQuery(ctx, "CreatePlayerCompetition", "INSERT", "player_competitions")
+	defer span.End()
+	err := r.next.CreatePlayerCompetition(ctx, pc)
+	finish(span, err)
+	return err
+}
+
+func (r *tracedRepository) GetPlayerCompetitionByID(ctx context.Context, id int) (*model.PlayerCompetition, error) {
+	ctx, span := startQuery(ctx, "GetPlayerCompetitionByID", "SELECT", "player_competitions")
+	defer span.End()
+	pc, err := r.next.GetPlayerCompetitionByID(ctx, id)
+	finish(span, err)
+	return pc, err
+}
+
+func (r *tracedRepository) UpdatePlayerCompetition(ctx context.Context, pc *model.PlayerCompetition) error {
+	ctx, span := startQuery(ctx, "UpdatePlayerCompetition", "UPDATE", "player_competitions")
+	defer span.End()
+	err := r.next.UpdatePlayerCompetition(ctx, pc)
+	finish(span, err)
+	return err
+}
+
+func (r *tracedRepository) GetLatestPlayerCompetition(ctx context.Context, playerID string) (*model.PlayerCompetition, error) {
+	ctx, span := startQuery(ctx, "GetLatestPlayerCompetition", "SELECT", "player_competitions")
+	defer span.End()
+	pc, err := r.next.GetLatestPlayerCompetition(ctx, playerID)
+	finish(span, err)
+	return pc, err
+}
+
+func (r *tracedRepository) GetLeaderboardByCompetitionID(ctx context.Context, competitionID string, viewer model.Viewer) ([]model.PlayerCompetition, error) {
+	ctx, span := startQuery(ctx, "GetLeaderboardByCompetitionID", "SELECT", "player_competitions")
+	defer span.End()
+	pcs, err := r.next.GetLeaderboardByCompetitionID(ctx, competitionID, viewer)
+	finish(span, err)
+	return pcs, err
+}
+
+func (r *tracedRepository) GetActivePlayerCompetition(ctx context.Context, playerID string) (*model.PlayerCompetition, error) {
+	ctx, span := startQuery(ctx, "GetActivePlayerCompetition", "SELECT", "player_competitions")
+	defer span.End()
+	pc, err := r.next.GetActivePlayerCompetition(ctx, playerID)
+	finish(span, err)
+	return pc, err
+}
+
+func (r *tracedRepository) GetWaitingPlayers(ctx context.Context, limit int) ([]model.PlayerCompetition, error) {
+	ctx, span := startQuery(ctx, "GetWaitingPlayers", "SELECT", "player_competitions")
+	defer span.End()
+	pcs, err := r.next.GetWaitingPlayers(ctx, limit)
+	finish(span, err)
+	return pcs, err
+}
+
+func (r *tracedRepository) UpdatePlayerCompetitionsToActive(ctx context.Context, playerIDs []string, competitionID uuid.UUID, endsAt time.Time) error {
+	ctx, span := startQuery(ctx, "UpdatePlayerCompetitionsToActive", "UPDATE", "player_competitions")
+	defer span.End()
+	span.SetAttributes(attribute.String("competition.id", competitionID.String()), attribute.Int("players", len(playerIDs)))
+	err := r.next.UpdatePlayerCompetitionsToActive(ctx, playerIDs, competitionID, endsAt)
+	finish(span, err)
+	return err
+}
+
+func (r *tracedRepository) AddScoreToPlayer(ctx context.Context, playerID string, score int) error {
+	ctx, span := startQuery(ctx, "AddScoreToPlayer", "UPDATE", "player_competitions")
+	defer span.End()
+	err := r.next.AddScoreToPlayer(ctx, playerID, score)
+	finish(span, err)
+	return err
+}
+
+func (r *tracedRepository) CompleteFinishedCompetitions(ctx context.Context) ([]uuid.UUID, error) {
+	ctx, span := startQuery(ctx, "CompleteFinishedCompetitions", "UPDATE", "competitions")
+	defer span.End()
+	ids, err := r.next.CompleteFinishedCompetitions(ctx)
+	span.SetAttributes(attribute.Int("competitions.completed", len(ids)))
+	finish(span, err)
+	return ids, err
+}
+
+func (r *tracedRepository) IsPlayerInWaitingQueue(ctx context.Context, playerID string) (bool, error) {
+	ctx, span := startQuery(ctx, "IsPlayerInWaitingQueue", "SELECT", "player_competitions")
+	defer span.End()
+	inQueue, err := r.next.IsPlayerInWaitingQueue(ctx, playerID)
+	finish(span, err)
+	return inQueue, err
+}
+
+func (r *tracedRepository) CancelWaitingPlayerCompetition(ctx context.Context, playerID string) (bool, error) {
+	ctx, span := startQuery(ctx, "CancelWaitingPlayerCompetition", "UPDATE", "player_competitions")
+	defer span.End()
+	removed, err := r.next.CancelWaitingPlayerCompetition(ctx, playerID)
+	finish(span, err)
+	return removed, err
+}
+
+func (r *tracedRepository) CountWaitingPlayersByLevel(ctx context.Context) (map[int]int, error) {
+	ctx, span := startQuery(ctx, "CountWaitingPlayersByLevel", "SELECT", "player_competitions")
+	defer span.End()
+	counts, err := r.next.CountWaitingPlayersByLevel(ctx)
+	finish(span, err)
+	return counts, err
+}
+
+func (r *tracedRepository) CountActiveCompetitions(ctx context.Context) (int, error) {
+	ctx, span := startQuery(ctx, "CountActiveCompetitions", "SELECT", "competitions")
+	defer span.End()
+	count, err := r.next.CountActiveCompetitions(ctx)
+	finish(span, err)
+	return count, err
+}
+
+func (r *tracedRepository) CreateAuditEntry(ctx context.Context, entry *model.AuditEntry) error {
+	ctx, span := startQuery(ctx, "CreateAuditEntry", "INSERT", "audit_log")
+	defer span.End()
+	err := r.next.CreateAuditEntry(ctx, entry)
+	finish(span, err)
+	return err
+}
+
+func (r *tracedRepository) ListAuditEntries(ctx context.Context, filter model.AuditFilter) ([]model.AuditEntry, error) {
+	ctx, span := startQuery(ctx, "ListAuditEntries", "SELECT", "audit_log")
+	defer span.End()
+	entries, err := r.next.ListAuditEntries(ctx, filter)
+	finish(span, err)
+	return entries, err
+}
+
+func (r *tracedRepository) ListCompetitions(ctx context.Context, filter model.CompetitionFilter) ([]model.Competition, error) {
+	ctx, span := startQuery(ctx, "ListCompetitions", "SELECT", "competitions")
+	defer span.End()
+	comps, err := r.next.ListCompetitions(ctx, filter)
+	finish(span, err)
+	return comps, err
+}
+
+func (r *tracedRepository) GetCompetitionPlayer(ctx context.Context, competitionID uuid.UUID, playerID string) (*model.PlayerCompetition, error) {
+	ctx, span := startQuery(ctx, "GetCompetitionPlayer", "SELECT", "player_competitions")
+	defer span.End()
+	pc, err := r.next.GetCompetitionPlayer(ctx, competitionID, playerID)
+	finish(span, err)
+	return pc, err
+}
+
+func (r *tracedRepository) CancelCompetition(ctx context.Context, competitionID uuid.UUID, audit *model.AuditEntry) (bool, error) {
+	ctx, span := startQuery(ctx, "CancelCompetition", "UPDATE", "competitions")
+	defer span.End()
+	ok, err := r.next.CancelCompetition(ctx, competitionID, audit)
+	finish(span, err)
+	return ok, err
+}
+
+func (r *tracedRepository) CompleteCompetition(ctx context.Context, competitionID uuid.UUID, audit *model.AuditEntry) (bool, error) {
+	ctx, span := startQuery(ctx, "CompleteCompetition", "UPDATE", "competitions")
+	defer span.End()
+	ok, err := r.next.CompleteCompetition(ctx, competitionID, audit)
+	finish(span, err)
+	return ok, err
+}
+
+func (r *tracedRepository) SetCompetitionEndsAt(ctx context.Context, competitionID uuid.UUID, endsAt time.Time, audit *model.AuditEntry) (bool, error) {
+	ctx, span := startQuery(ctx, "SetCompetitionEndsAt", "UPDATE", "competitions")
+	defer span.End()
+	ok, err := r.next.SetCompetitionEndsAt(ctx, competitionID, endsAt, audit)
+	finish(span, err)
+	return ok, err
+}
+
+func (r *tracedRepository) ExcludeCompetitionPlayer(ctx context.Context, competitionID uuid.UUID, playerID string, status model.PlayerStatus, audit *model.AuditEntry) (bool, error) {
+	ctx, span := startQuery(ctx, "ExcludeCompetitionPlayer", "UPDATE", "player_competitions")
+	defer span.End()
+	ok, err := r.next.ExcludeCompetitionPlayer(ctx, competitionID, playerID, status, audit)
+	finish(span, err)
+	return ok, err
+}
+
+func (r *tracedRepository) SetCompetitionScore(ctx context.Context, competitionID uuid.UUID, playerID string, from, to int, audit *model.AuditEntry) (bool, error) {
+	ctx, span := startQuery(ctx, "SetCompetitionScore", "UPDATE", "player_competitions")
+	defer span.End()
+	ok, err := r.next.SetCompetitionScore(ctx, competitionID, playerID, from, to, audit)
+	finish(span, err)
+	return ok, err
+}
+
+func (r *tracedRepository) CreateScheduledCompetition(ctx context.Context, comp *model.Competition, audit *model.AuditEntry) error {
+	ctx, span := startQuery(ctx, "CreateScheduledCompetition", "INSERT", "competitions")
+	defer span.End()
+	err := r.next.CreateScheduledCompetition(ctx, comp, audit)
+	finish(span, err)
+	return err
+}
+
+func (r *tracedRepository) OpenScheduledCompetitions(ctx context.Context) ([]uuid.UUID, error) {
+	ctx, span := startQuery(ctx, "OpenScheduledCompetitions", "UPDATE", "competitions")
+	defer span.End()
+	opened, err := r.next.OpenScheduledCompetitions(ctx)
+	finish(span, err)
+	return opened, err
+}
+
+func (r *tracedRepository) StartScheduledCompetitions(ctx context.Context) ([]model.Competition, error) {
+	ctx, span := startQuery(ctx, "StartScheduledCompetitions", "UPDATE", "competitions")
+	defer span.End()
+	started, err := r.next.StartScheduledCompetitions(ctx)
+	finish(span, err)
+	return started, err
+}
+
+func (r *tracedRepository) RegisterCompetitionPlayer(ctx context.Context, competitionID uuid.UUID, player *model.Player) (bool, error) {
+	ctx, span := startQuery(ctx, "RegisterCompetitionPlayer", "INSERT", "player_competitions")
+	defer span.End()
+	ok, err := r.next.RegisterCompetitionPlayer(ctx, competitionID, player)
+	finish(span, err)
+	return ok, err
+}
+
+func (r *tracedRepository) CancelCompetitionRegistration(ctx context.Context, competitionID uuid.UUID, playerID string) (bool, error) {
+	ctx, span := startQuery(ctx, "CancelCompetitionRegistration", "UPDATE", "player_competitions")
+	defer span.End()
+	ok, err := r.next.CancelCompetitionRegistration(ctx, competitionID, playerID)
+	finish(span, err)
+	return ok, err
+}
+
+func (r *tracedRepository) ListCompetitionTemplates(ctx context.Context) ([]model.CompetitionTemplate, error) {
+	ctx, span := startQuery(ctx, "ListCompetitionTemplates", "SELECT", "competition_templates")
+	defer span.End()
+	res, err := r.next.ListCompetitionTemplates(ctx)
+	finish(span, err)
+	return res, err
+}
+
+func (r *tracedRepository) GetCompetitionTemplate(ctx context.Context, templateID uuid.UUID) (*model.CompetitionTemplate, error) {
+	ctx, span := startQuery(ctx, "GetCompetitionTemplate", "SELECT", "competition_templates")
+	defer span.End()
+	span.SetAttributes(attribute.String("template.id", templateID.String()))
+	res, err := r.next.GetCompetitionTemplate(ctx, templateID)
+	finish(span, err)
+	return res, err
+}
+
+func (r *tracedRepository) CreateCompetitionTemplate(ctx context.Context, tpl *model.CompetitionTemplate, audit *model.AuditEntry) error {
+	ctx, span := startQuery(ctx, "CreateCompetitionTemplate", "INSERT", "competition_templates")
+	defer span.End()
+	span.SetAttributes(attribute.String("template.id", tpl.TemplateID.String()))
+	err := r.next.CreateCompetitionTemplate(ctx, tpl, audit)
+	finish(span, err)
+	return err
+}
+
+func (r *tracedRepository) UpdateCompetitionTemplate(ctx context.Context, tpl *model.CompetitionTemplate, lastUpdated time.Time, audit *model.AuditEntry) (bool, error) {
+	ctx, span := startQuery(ctx, "UpdateCompetitionTemplate", "UPDATE", "competition_templates")
+	defer span.End()
+	span.SetAttributes(attribute.String("template.id", tpl.TemplateID.String()))
+	ok, err := r.next.UpdateCompetitionTemplate(ctx, tpl, lastUpdated, audit)
+	finish(span, err)
+	return ok, err
+}
+
+func (r *tracedRepository) DeleteCompetitionTemplate(ctx context.Context, templateID uuid.UUID, audit *model.AuditEntry) (bool, error) {
+	ctx, span := startQuery(ctx, "DeleteCompetitionTemplate", "DELETE", "competition_templates")
+	defer span.End()
+	span.SetAttributes(attribute.String("template.id", templateID.String()))
+	ok, err := r.next.DeleteCompetitionTemplate(ctx, templateID, audit)
+	finish(span, err)
+	return ok, err
+}
+
+func (r *tracedRepository) DueCompetitionTemplates(ctx context.Context) ([]model.CompetitionTemplate, error) {
+	ctx, span := startQuery(ctx, "DueCompetitionTemplates", "SELECT", "competition_templates")
+	defer span.End()
+	res, err := r.next.DueCompetitionTemplates(ctx)
+	finish(span, err)
+	return res, err
+}
+
+func (r *tracedRepository) StartTemplateOccurrence(ctx context.Context, templateID uuid.UUID, due time.Time, next *time.Time, comp *model.Competition) ([]uuid.UUID, bool, error) {
+	ctx, span := startQuery(ctx, "StartTemplateOccurrence", "UPDATE", "competition_templates")
+	defer span.End()
+	span.SetAttributes(attribute.String("template.id", templateID.String()))
+	closed, ok, err := r.next.StartTemplateOccurrence(ctx, templateID, due, next, comp)
+	finish(span, err)
+	return closed, ok, err
+}
+
+func (r *tracedRepository) UnsettledLeagueCompetitions(ctx context.Context) ([]model.Competition, error) {
+	ctx, span := startQuery(ctx, "UnsettledLeagueCompetitions", "SELECT", "competitions")
+	defer span.End()
+	comps, err := r.next.UnsettledLeagueCompetitions(ctx)
+	finish(span, err)
+	return comps, err
+}
+
+func (r *tracedRepository) ApplyTierMovements(ctx context.Context, competitionID uuid.UUID, moves []model.TierMovement) ([]model.TierMovement, error) {
+	ctx, span := startQuery(ctx, "ApplyTierMovements", "INSERT", "player_tier_history")
+	defer span.End()
+	span.SetAttributes(attribute.String("competition.id", competitionID.String()))
+	res, err := r.next.ApplyTierMovements(ctx, competitionID, moves)
+	finish(span, err)
+	return res, err
+}
+
+func (r *tracedRepository) ListTierHistory(ctx context.Context, playerID string, limit int) ([]model.TierMovement, error) {
+	ctx, span := startQuery(ctx, "ListTierHistory", "SELECT", "player_tier_history")
+	defer span.End()
+	span.SetAttributes(attribute.String("player.id", playerID))
+	res, err := r.next.ListTierHistory(ctx, playerID, limit)
+	finish(span, err)
+	return res, err
+}
+
+func (r *tracedRepository) CreateTournament(ctx context.Context, t *model.Tournament, matches []model.TournamentMatch, comps []model.Competition, audit *model.AuditEntry) error {
+	ctx, span := startQuery(ctx, "CreateTournament", "INSERT", "tournaments")
+	defer span.End()
+	span.SetAttributes(attribute.String("tournament.id", t.TournamentID.String()))
+	err := r.next.CreateTournament(ctx, t, matches, comps, audit)
+	finish(span, err)
+	return err
+}
+
+func (r *tracedRepository) GetTournament(ctx context.Context, tournamentID uuid.UUID) (*model.Tournament, error) {
+	ctx, span := startQuery(ctx, "GetTournament", "SELECT", "tournaments")
+	defer span.End()
+	span.SetAttributes(attribute.String("tournament.id", tournamentID.String()))
+	res, err := r.next.GetTournament(ctx, tournamentID)
+	finish(span, err)
+	return res, err
+}
+
+func (r *tracedRepository) ActiveTournaments(ctx context.Context) ([]model.Tournament, error) {
+	ctx, span := startQuery(ctx, "ActiveTournaments", "SELECT", "tournaments")
+	defer span.End()
+	res, err := r.next.ActiveTournaments(ctx)
+	finish(span, err)
+	return res, err
+}
+
+func (r *tracedRepository) ListTournamentMatches(ctx context.Context, tournamentID uuid.UUID) ([]model.TournamentMatch, error) {
+	ctx, span := startQuery(ctx, "ListTournamentMatches", "SELECT", "tournament_matches")
+	defer span.End()
+	span.SetAttributes(attribute.String("tournament.id", tournamentID.String()))
+	res, err := r.next.ListTournamentMatches(ctx, tournamentID)
+	finish(span, err)
+	return res, err
+}
+
+func (r *tracedRepository) StartTournamentRound(ctx context.Context, tournamentID uuid.UUID, round int, matches []model.TournamentMatch, comps []model.Competition) (bool, error) {
+	ctx, span := startQuery(ctx, "StartTournamentRound", "UPDATE", "tournaments")
+	defer span.End()
+	span.SetAttributes(attribute.String("tournament.id", tournamentID.String()))
+	ok, err := r.next.StartTournamentRound(ctx, tournamentID, round, matches, comps)
+	finish(span, err)
+	return ok, err
+}
+
+func (r *tracedRepository) CompleteTournament(ctx context.Context, tournamentID uuid.UUID, round int, winners []string) (bool, error) {
+	ctx, span := startQuery(ctx, "CompleteTournament", "UPDATE", "tournaments")
+	defer span.End()
+	span.SetAttributes(attribute.String("tournament.id", tournamentID.String()))
+	ok, err := r.next.CompleteTournament(ctx, tournamentID, round, winners)
+	finish(span, err)
+	return ok, err
+}
+
+func (r *tracedRepository) CreateParty(ctx context.Context, party *model.Party) (bool, error) {
+	ctx, span := startQuery(ctx, "CreateParty", "INSERT", "parties")
+	defer span.End()
+	span.SetAttributes(attribute.String("party.id", party.PartyID.String()))
+	ok, err := r.next.CreateParty(ctx, party)
+	finish(span, err)
+	return ok, err
+}
+
+func (r *tracedRepository) GetParty(ctx context.Context, partyID uuid.UUID) (*model.Party, error) {
+	ctx, span := startQuery(ctx, "GetParty", "SELECT", "parties")
+	defer span.End()
+	span.SetAttributes(attribute.String("party.id", partyID.String()))
+	res, err := r.next.GetParty(ctx, partyID)
+	finish(span, err)
+	return res, err
+}
+
+func (r *tracedRepository) GetPlayerParty(ctx context.Context, playerID string) (*model.Party, error) {
+	ctx, span := startQuery(ctx, "GetPlayerParty", "SELECT", "parties")
+	defer span.End()
+	span.SetAttributes(attribute.String("player.id", playerID))
+	res, err := r.next.GetPlayerParty(ctx, playerID)
+	finish(span, err)
+	return res, err
+}
+
+func (r *tracedRepository) InvitePartyPlayer(ctx context.Context, partyID uuid.UUID, playerID string) (bool, error) {
+	ctx, span := startQuery(ctx, "InvitePartyPlayer", "INSERT", "party_invites")
+	defer span.End()
+	span.SetAttributes(attribute.String("party.id", partyID.String()))
+	ok, err := r.next.InvitePartyPlayer(ctx, partyID, playerID)
+	finish(span, err)
+	return ok, err
+}
+
+func (r *tracedRepository) AcceptPartyInvite(ctx context.Context, partyID uuid.UUID, playerID string, maxSize int) (bool, error) {
+	ctx, span := startQuery(ctx, "AcceptPartyInvite", "INSERT", "party_members")
+	defer span.End()
+	span.SetAttributes(attribute.String("party.id", partyID.String()))
+	ok, err := r.next.AcceptPartyInvite(ctx, partyID, playerID, maxSize)
+	finish(span, err)
+	return ok, err
+}
+
+func (r *tracedRepository) LeaveParty(ctx context.Context, partyID uuid.UUID, playerID string) (bool, error) {
+	ctx, span := startQuery(ctx, "LeaveParty", "DELETE", "party_members")
+	defer span.End()
+	span.SetAttributes(attribute.String("party.id", partyID.String()))
+	ok, err := r.next.LeaveParty(ctx, partyID, playerID)
+	finish(span, err)
+	return ok, err
+}
+
+func (r *tracedRepository) QueueParty(ctx context.Context, partyID uuid.UUID, entries []model.PlayerCompetition) (bool, error) {
+	ctx, span := startQuery(ctx, "QueueParty", "INSERT", "player_competitions")
+	defer span.End()
+	span.SetAttributes(attribute.String("party.id", partyID.String()))
+	ok, err := r.next.QueueParty(ctx, partyID, entries)
+	finish(span, err)
+	return ok, err
+}
+
+func (r *tracedRepository) AddFriends(ctx context.Context, playerID string, friendIDs []string) ([]string, error) {
+	ctx, span := startQuery(ctx, "AddFriends", "INSERT", "player_friends")
+	defer span.End()
+	span.SetAttributes(attribute.String("player.id", playerID), attribute.Int("friends", len(friendIDs)))
+	added, err := r.next.AddFriends(ctx, playerID, friendIDs)
+	finish(span, err)
+	return added, err
+}
+
+func (r *tracedRepository) RemoveFriend(ctx context.Context, playerID, friendID string) (bool, error) {
+	ctx, span := startQuery(ctx, "RemoveFriend", "DELETE", "player_friends")
+	defer span.End()
+	span.SetAttributes(attribute.String("player.id", playerID))
+	ok, err := r.next.RemoveFriend(ctx, playerID, friendID)
+	finish(span, err)
+	return ok, err
+}
+
+func (r *tracedRepository) ListFriends(ctx context.Context, playerID string) ([]string, error) {
+	ctx, span := startQuery(ctx, "ListFriends", "SELECT", "player_friends")
+	defer span.End()
+	span.SetAttributes(attribute.String("player.id", playerID))
+	friends, err := r.next.ListFriends(ctx, playerID)
+	finish(span, err)
+	return friends, err
+}
+
+func (r *tracedRepository) GetPlayerStats(ctx context.Context, playerIDs []string, viewer model.Viewer) ([]model.PlayerStats, error) {
+	ctx, span := startQuery(ctx, "GetPlayerStats", "SELECT", "player_competitions")
+	defer span.End()
+	span.SetAttributes(attribute.Int("players", len(playerIDs)))
+	stats, err := r.next.GetPlayerStats(ctx, playerIDs, viewer)
+	finish(span, err)
+	return stats, err
+}
+
+func (r *tracedRepository) ListRegions(ctx context.Context) ([]model.Region, error) {
+	ctx, span := startQuery(ctx, "ListRegions", "SELECT", "regions")
+	defer span.End()
+	regions, err := r.next.ListRegions(ctx)
+	finish(span, err)
+	return regions, err
+}
+
+func (r *tracedRepository) GetRegion(ctx context.Context, regionCode string) (*model.Region, error) {
+	ctx, span := startQuery(ctx, "GetRegion", "SELECT", "regions")
+	defer span.End()
+	span.SetAttributes(attribute.String("region.code", regionCode))
+	region, err := r.next.GetRegion(ctx, regionCode)
+	finish(span, err)
+	return region, err
+}
+
+func (r *tracedRepository) PutRegion(ctx context.Context, region *model.Region, audit *model.AuditEntry) error {
+	ctx, span := startQuery(ctx, "PutRegion", "INSERT", "regions")
+	defer span.End()
+	span.SetAttributes(attribute.String("region.code", region.RegionCode))
+	err := r.next.PutRegion(ctx, region, audit)
+	finish(span, err)
+	return err
+}
+
+func (r *tracedRepository) DeleteRegion(ctx context.Context, regionCode string, audit *model.AuditEntry) (bool, error) {
+	ctx, span := startQuery(ctx, "DeleteRegion", "DELETE", "regions")
+	defer span.End()
+	span.SetAttributes(attribute.String("region.code", regionCode))
+	ok, err := r.next.DeleteRegion(ctx, regionCode, audit)
+	finish(span, err)
+	return ok, err
+}
+
+func (r *tracedRepository) ListTopPlayers(ctx context.Context, filter model.GeoFilter, limit int) ([]model.PlayerStats, error) {
+	ctx, span := startQuery(ctx, "ListTopPlayers", "SELECT", "player_competitions")
+	defer span.End()
+	span.SetAttributes(attribute.String("country.code", filter.CountryCode), attribute.String("region.code", filter.RegionCode))
+	stats, err := r.next.ListTopPlayers(ctx, filter, limit)
+	finish(span, err)
+	return stats, err
+}
+
+func (r *tracedRepository) ListPlayerHistory(ctx context.Context, playerID string, filter model.PlayerHistoryFilter) ([]model.CompetitionResult, error) {
+	ctx, span := startQuery(ctx, "ListPlayerHistory", "SELECT", "player_competitions")
+	defer span.End()
+	span.SetAttributes(attribute.String("player.id", playerID))
+	results, err := r.next.ListPlayerHistory(ctx, playerID, filter)
+	finish(span, err)
+	return results, err
+}
+
+func (r *tracedRepository) GetPlayerHistorySummary(ctx context.Context, playerID string, filter model.PlayerHistoryFilter) (*model.PlayerHistorySummary, error) {
+	ctx, span := startQuery(ctx, "GetPlayerHistorySummary", "SELECT", "player_competitions")
+	defer span.End()
+	span.SetAttributes(attribute.String("player.id", playerID))
+	sum, err := r.next.GetPlayerHistorySummary(ctx, playerID, filter)
+	finish(span, err)
+	return sum, err
+}
+
+func (r *tracedRepository) AnonymizePlayer(ctx context.Context, playerID, alias string) (bool, error) {
+	ctx, span := startQuery(ctx, "AnonymizePlayer", "UPDATE", "players")
+	defer span.End()
+	span.SetAttributes(attribute.String("player.id", playerID))
+	ok, err := r.next.AnonymizePlayer(ctx, playerID, alias)
+	finish(span, err)
+	return ok, err
+}
+
+func (r *tracedRepository) PurgeAnonymizedPlayers(ctx context.Context, cutoff time.Time) (int, error) {
+	ctx, span := startQuery(ctx, "PurgeAnonymizedPlayers", "DELETE", "players")
+	defer span.End()
+	n, err := r.next.PurgeAnonymizedPlayers(ctx, cutoff)
+	finish(span, err)
+	return n, err
+}
+
+func (r *tracedRepository) ListPlayerEntries(ctx context.Context, playerID string) ([]model.PlayerCompetition, error) {
+	ctx, span := startQuery(ctx, "ListPlayerEntries", "SELECT", "player_competitions")
+	defer span.End()
+	span.SetAttributes(attribute.String("player.id", playerID))
+	entries, err := r.next.ListPlayerEntries(ctx, playerID)
+	finish(span, err)
+	return entries, err
+}
+
+func (r *tracedRepository) GetPlayerModeration(ctx context.Context, playerID string) (*model.Moderation, error) {
+	ctx, span := startQuery(ctx, "GetPlayerModeration", "SELECT", "players")
+	defer span.End()
+	span.SetAttributes(attribute.String("player.id", playerID))
+	m, err := r.next.GetPlayerModeration(ctx, playerID)
+	finish(span, err)
+	return m, err
+}
+
+func (r *tracedRepository) SetPlayerSanction(ctx context.Context, playerID string, sanction model.Sanction, value *time.Time, audit *model.AuditEntry) (bool, error) {
+	ctx, span := startQuery(ctx, "SetPlayerSanction", "UPDATE", "players")
+	defer span.End()
+	span.SetAttributes(attribute.String("player.id", playerID))
+	ok, err := r.next.SetPlayerSanction(ctx, playerID, sanction, value, audit)
+	finish(span, err)
+	return ok, err
+}
@@ -0,0 +1,459 @@
+package tracing
+
+import (
+	"context"
+	"leaderboard-service/internal/model"
+	"leaderboard-service/internal/service"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracedService starts a span for every ServiceInterface call.
+type tracedService struct {
+	next service.ServiceInterface
+}
+
+// NewService wraps next so that every call is traced.
+func NewService(next service.ServiceInterface) service.ServiceInterface {
+	return &tracedService{next: next}
+}
+
+func startService(ctx context.Context, method string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracer().Start(ctx, "service."+method, trace.WithAttributes(attrs...))
+}
+
+func (s *tracedService) Join(ctx context.Context, playerID string) (string, error) {
+	ctx, span := startService(ctx, "Join", attribute.String("player.id", playerID))
+	defer span.End()
+	id, err := s.next.Join(ctx, playerID)
+	finish(span, err)
+	return id, err
+}
+
+func (s *tracedService) Leave(ctx context.Context, playerID string) error {
+	ctx, span := startService(ctx, "Leave", attribute.String("player.id", playerID))
+	defer span.End()
+	err := s.next.Leave(ctx, playerID)
+	finish(span, err)
+	return err
+}
+
+func (s *tracedService) SubmitScore(ctx context.Context, playerID string, score int) error {
+	ctx, span := startService(ctx, "SubmitScore", attribute.String("player.id", playerID), attribute.Int("score", score))
+	defer span.End()
+	err := s.next.SubmitScore(ctx, playerID, score)
+	finish(span, err)
+	return err
+}
+
+func (s *tracedService) GetPlayerLeaderboard(ctx context.Context, playerID string) (interface{}, error) {
+	ctx, span := startService(ctx, "GetPlayerLeaderboard", attribute.String("player.id", playerID))
+	defer span.End()
+	resp, err := s.next.GetPlayerLeaderboard(ctx, playerID)
+	finish(span, err)
+	return resp, err
+}
+
+func (s *tracedService) GetLeaderboard(ctx context.Context, leaderboardID string) (interface{}, error) {
+	ctx, span := startService(ctx, "GetLeaderboard", attribute.String("competition.id", leaderboardID))
+	defer span.End()
+	resp, err := s.next.GetLeaderboard(ctx, leaderboardID)
+	finish(span, err)
+	return resp, err
+}
+
+func (s *tracedService) CreatePlayer(ctx context.Context, playerID string, profile service.PlayerProfile) (*model.Player, error) {
+	ctx, span := startService(ctx, "CreatePlayer", attribute.String("player.id", playerID))
+	defer span.End()
+	player, err := s.next.CreatePlayer(ctx, playerID, profile)
+	finish(span, err)
+	return player, err
+}
+
+func (s *tracedService) GetPlayer(ctx context.Context, playerID string) (*model.Player, error) {
+	ctx, span := startService(ctx, "GetPlayer", attribute.String("player.id", playerID))
+	defer span.End()
+	player, err := s.next.GetPlayer(ctx, playerID)
+	finish(span, err)
+	return player, err
+}
+
+func (s *tracedService) UpdatePlayer(ctx context.Context, playerID string, profile service.PlayerProfile) (*model.Player, error) {
+	ctx, span := startService(ctx, "UpdatePlayer", attribute.String("player.id", playerID))
+	defer span.End()
+	player, err := s.next.UpdatePlayer(ctx, playerID, profile)
+	finish(span, err)
+	return player, err
+}
+
+// SubscribeLeaderboard traces only the subscription setup, not the lifetime
+// of the stream.
+func (s *tracedService) SubscribeLeaderboard(ctx context.Context, leaderboardID string, lastEventID uint64) (*service.Subscription, error) {
+	ctx, span := startService(ctx, "SubscribeLeaderboard", attribute.String("competition.id", leaderboardID))
+	defer span.End()
+	sub, err := s.next.SubscribeLeaderboard(ctx, leaderboardID, lastEventID)
+	finish(span, err)
+	return sub, err
+}
+
+func (s *tracedService) SubscribePlayer(ctx context.Context, playerID string) (*service.Subscription, error) {
+	ctx, span := startService(ctx, "SubscribePlayer", attribute.String("player.id", playerID))
+	defer span.End()
+	sub, err := s.next.SubscribePlayer(ctx, playerID)
+	finish(span, err)
+	return sub, err
+}
+
+func (s *tracedService) GetConfig(ctx context.Context) (service.Config, error) {
+	ctx, span := startService(ctx, "GetConfig")
+	defer span.End()
+	config, err := s.next.GetConfig(ctx)
+	finish(span, err)
+	return config, err
+}
+
+func (s *tracedService) UpdateConfig(ctx context.Context, update service.ConfigUpdate) (service.Config, error) {
+	ctx, span := startService(ctx, "UpdateConfig")
+	defer span.End()
+	config, err := s.next.UpdateConfig(ctx, update)
+	finish(span, err)
+	return config, err
+}
+
+func (s *tracedService) ListAuditEntries(ctx context.Context, filter model.AuditFilter) ([]model.AuditEntry, error) {
+	ctx, span := startService(ctx, "ListAuditEntries")
+	defer span.End()
+	entries, err := s.next.ListAuditEntries(ctx, filter)
+	finish(span, err)
+	return entries, err
+}
+
+func (s *tracedService) ListCompetitions(ctx context.Context, filter model.CompetitionFilter) ([]model.Competition, error) {
+	ctx, span := startService(ctx, "ListCompetitions")
+	defer span.End()
+	comps, err := s.next.ListCompetitions(ctx, filter)
+	finish(span, err)
+	return comps, err
+}
+
+func (s *tracedService) CancelCompetition(ctx context.Context, competitionID, reason string) (*model.Competition, error) {
+	ctx, span := startService(ctx, "CancelCompetition", attribute.String("competition.id", competitionID))
+	defer span.End()
+	comp, err := s.next.CancelCompetition(ctx, competitionID, reason)
+	finish(span, err)
+	return comp, err
+}
+
+func (s *tracedService) CompleteCompetition(ctx context.Context, competitionID, reason string) (*model.Competition, error) {
+	ctx, span := startService(ctx, "CompleteCompetition", attribute.String("competition.id", competitionID))
+	defer span.End()
+	comp, err := s.next.CompleteCompetition(ctx, competitionID, reason)
+	finish(span, err)
+	return comp, err
+}
+
+func (s *tracedService) SetCompetitionEndsAt(ctx context.Context, competitionID string, endsAt time.Time, reason string) (*model.Competition, error) {
+	ctx, span := startService(ctx, "SetCompetitionEndsAt", attribute.String("competition.id", competitionID))
+	defer span.End()
+	comp, err := s.next.SetCompetitionEndsAt(ctx, competitionID, endsAt, reason)
+	finish(span, err)
+	return comp, err
+}
+
+func (s *tracedService) ExcludePlayer(ctx context.Context, competitionID, playerID string, status model.PlayerStatus, reason string) error {
+	ctx, span := startService(ctx, "ExcludePlayer", attribute.String("competition.id", competitionID), attribute.String("player.id", playerID))
+	defer span.End()
+	err := s.next.ExcludePlayer(ctx, competitionID, playerID, status, reason)
+	finish(span, err)
+	return err
+}
+
+func (s *tracedService) AdjustScore(ctx context.Context, competitionID, playerID string, adjustment service.ScoreAdjustment) (*service.ScoreChange, error) {
+	ctx, span := startService(ctx, "AdjustScore", attribute.String("competition.id", competitionID), attribute.String("player.id", playerID))
+	defer span.End()
+	change, err := s.next.AdjustScore(ctx, competitionID, playerID, adjustment)
+	finish(span, err)
+	return change, err
+}
+
+func (s *tracedService) ScheduleCompetition(ctx context.Context, schedule service.CompetitionSchedule) (*model.Competition, error) {
+	ctx, span := startService(ctx, "ScheduleCompetition")
+	defer span.End()
+	comp, err := s.next.ScheduleCompetition(ctx, schedule)
+	finish(span, err)
+	return comp, err
+}
+
+func (s *tracedService) RegisterForCompetition(ctx context.Context, competitionID, playerID string) error {
+	ctx, span := startService(ctx, "RegisterForCompetition", attribute.String("competition.id", competitionID), attribute.String("player.id", playerID))
+	defer span.End()
+	err := s.next.RegisterForCompetition(ctx, competitionID, playerID)
+	finish(span, err)
+	return err
+}
+
+func (s *tracedService) UnregisterFromCompetition(ctx context.Context, competitionID, playerID string) error {
+	ctx, span := startService(ctx, "UnregisterFromCompetition", attribute.String("competition.id", competitionID), attribute.String("player.id", playerID))
+	defer span.End()
+	err := s.next.UnregisterFromCompetition(ctx, competitionID, playerID)
+	finish(span, err)
+	return err
+}
+
+func (s *tracedService) ListTemplates(ctx context.Context) ([]model.CompetitionTemplate, error) {
+	ctx, span := startService(ctx, "ListTemplates")
+	defer span.End()
+	res, err := s.next.ListTemplates(ctx)
+	finish(span, err)
+	return res, err
+}
+
+func (s *tracedService) GetTemplate(ctx context.Context, templateID string) (*model.CompetitionTemplate, error) {
+	ctx, span := startService(ctx, "GetTemplate", attribute.String("template.id", templateID))
+	defer span.End()
+	res, err := s.next.GetTemplate(ctx, templateID)
+	finish(span, err)
+	return res, err
+}
+
+func (s *tracedService) CreateTemplate(ctx context.Context, def service.TemplateDefinition) (*model.CompetitionTemplate, error) {
+	ctx, span := startService(ctx, "CreateTemplate")
+	defer span.End()
+	res, err := s.next.CreateTemplate(ctx, def)
+	finish(span, err)
+	return res, err
+}
+
+func (s *tracedService) UpdateTemplate(ctx context.Context, templateID string, def service.TemplateDefinition) (*model.CompetitionTemplate, error) {
+	ctx, span := startService(ctx, "UpdateTemplate", attribute.String("template.id", templateID))
+	defer span.End()
+	res, err := s.next.UpdateTemplate(ctx, templateID, def)
+	finish(span, err)
+	return res, err
+}
+
+func (s *tracedService) DeleteTemplate(ctx context.Context, templateID, reason string) error {
+	ctx, span := startService(ctx, "DeleteTemplate", attribute.String("template.id", templateID))
+	defer span.End()
+	err := s.next.DeleteTemplate(ctx, templateID, reason)
+	finish(span, err)
+	return err
+}
+
+func (s *tracedService) PreviewTemplate(ctx context.Context, templateID string, count int) ([]service.Occurrence, error) {
+	ctx, span := startService(ctx, "PreviewTemplate", attribute.String("template.id", templateID))
+	defer span.End()
+	res, err := s.next.PreviewTemplate(ctx, templateID, count)
+	finish(span, err)
+	return res, err
+}
+
+func (s *tracedService) GetLeagueHistory(ctx context.Context, playerID string) ([]model.TierMovement, error) {
+	ctx, span := startService(ctx, "GetLeagueHistory", attribute.String("player.id", playerID))
+	defer span.End()
+	res, err := s.next.GetLeagueHistory(ctx, playerID)
+	finish(span, err)
+	return res, err
+}
+
+func (s *tracedService) CreateTournament(ctx context.Context, def service.TournamentDefinition) (*model.Tournament, error) {
+	ctx, span := startService(ctx, "CreateTournament")
+	defer span.End()
+	res, err := s.next.CreateTournament(ctx, def)
+	finish(span, err)
+	return res, err
+}
+
+func (s *tracedService) GetTournament(ctx context.Context, tournamentID string) (*service.Bracket, error) {
+	ctx, span := startService(ctx, "GetTournament", attribute.String("tournament.id", tournamentID))
+	defer span.End()
+	res, err := s.next.GetTournament(ctx, tournamentID)
+	finish(span, err)
+	return res, err
+}
+
+func (s *tracedService) CreateParty(ctx context.Context, playerID string) (*model.Party, error) {
+	ctx, span := startService(ctx, "CreateParty", attribute.String("player.id", playerID))
+	defer span.End()
+	res, err := s.next.CreateParty(ctx, playerID)
+	finish(span, err)
+	return res, err
+}
+
+func (s *tracedService) GetParty(ctx context.Context, partyID string) (*model.Party, error) {
+	ctx, span := startService(ctx, "GetParty", attribute.String("party.id", partyID))
+	defer span.End()
+	res, err := s.next.GetParty(ctx, partyID)
+	finish(span, err)
+	return res, err
+}
+
+func (s *tracedService) InviteToParty(ctx context.Context, partyID, playerID, inviteeID string) error {
+	ctx, span := startService(ctx, "InviteToParty", attribute.String("party.id", partyID), attribute.String("player.id", playerID))
+	defer span.End()
+	err := s.next.InviteToParty(ctx, partyID, playerID, inviteeID)
+	finish(span, err)
+	return err
+}
+
+func (s *tracedService) AcceptPartyInvite(ctx context.Context, partyID, playerID string) (*model.Party, error) {
+	ctx, span := startService(ctx, "AcceptPartyInvite", attribute.String("party.id", partyID), attribute.String("player.id", playerID))
+	defer span.End()
+	res, err := s.next.AcceptPartyInvite(ctx, partyID, playerID)
+	finish(span, err)
+	return res, err
+}
+
+func (s *tracedService) LeaveParty(ctx context.Context, partyID, playerID string) error {
+	ctx, span := startService(ctx, "LeaveParty", attribute.String("party.id", partyID), attribute.String("player.id", playerID))
+	defer span.End()
+	err := s.next.LeaveParty(ctx, partyID, playerID)
+	finish(span, err)
+	return err
+}
+
+func (s *tracedService) QueueParty(ctx context.Context, partyID, playerID string) error {
+	ctx, span := startService(ctx, "QueueParty", attribute.String("party.id", partyID), attribute.String("player.id", playerID))
+	defer span.End()
+	err := s.next.QueueParty(ctx, partyID, playerID)
+	finish(span, err)
+	return err
+}
+
+func (s *tracedService) ListFriends(ctx context.Context, playerID string) ([]string, error) {
+	ctx, span := startService(ctx, "ListFriends", attribute.String("player.id", playerID))
+	defer span.End()
+	friends, err := s.next.ListFriends(ctx, playerID)
+	finish(span, err)
+	return friends, err
+}
+
+func (s *tracedService) AddFriend(ctx context.Context, playerID, friendID string) error {
+	ctx, span := startService(ctx, "AddFriend", attribute.String("player.id", playerID), attribute.String("friend.id", friendID))
+	defer span.End()
+	err := s.next.AddFriend(ctx, playerID, friendID)
+	finish(span, err)
+	return err
+}
+
+func (s *tracedService) ImportFriends(ctx context.Context, playerID string, friendIDs []string) (*service.FriendImport, error) {
+	ctx, span := startService(ctx, "ImportFriends", attribute.String("player.id", playerID), attribute.Int("friends", len(friendIDs)))
+	defer span.End()
+	res, err := s.next.ImportFriends(ctx, playerID, friendIDs)
+	finish(span, err)
+	return res, err
+}
+
+func (s *tracedService) RemoveFriend(ctx context.Context, playerID, friendID string) error {
+	ctx, span := startService(ctx, "RemoveFriend", attribute.String("player.id", playerID), attribute.String("friend.id", friendID))
+	defer span.End()
+	err := s.next.RemoveFriend(ctx, playerID, friendID)
+	finish(span, err)
+	return err
+}
+
+func (s *tracedService) GetFriendsLeaderboard(ctx context.Context, leaderboardID, playerID string) (*service.FriendsLeaderboard, error) {
+	ctx, span := startService(ctx, "GetFriendsLeaderboard", attribute.String("competition.id", leaderboardID), attribute.String("player.id", playerID))
+	defer span.End()
+	res, err := s.next.GetFriendsLeaderboard(ctx, leaderboardID, playerID)
+	finish(span, err)
+	return res, err
+}
+
+func (s *tracedService) GetFriendsStats(ctx context.Context, playerID string) (*service.FriendsStats, error) {
+	ctx, span := startService(ctx, "GetFriendsStats", attribute.String("player.id", playerID))
+	defer span.End()
+	res, err := s.next.GetFriendsStats(ctx, playerID)
+	finish(span, err)
+	return res, err
+}
+
+func (s *tracedService) ListRegions(ctx context.Context) ([]model.Region, error) {
+	ctx, span := startService(ctx, "ListRegions")
+	defer span.End()
+	regions, err := s.next.ListRegions(ctx)
+	finish(span, err)
+	return regions, err
+}
+
+func (s *tracedService) PutRegion(ctx context.Context, regionCode string, def service.RegionDefinition) (*model.Region, error) {
+	ctx, span := startService(ctx, "PutRegion", attribute.String("region.code", regionCode))
+	defer span.End()
+	region, err := s.next.PutRegion(ctx, regionCode, def)
+	finish(span, err)
+	return region, err
+}
+
+func (s *tracedService) DeleteRegion(ctx context.Context, regionCode, reason string) error {
+	ctx, span := startService(ctx, "DeleteRegion", attribute.String("region.code", regionCode))
+	defer span.End()
+	err := s.next.DeleteRegion(ctx, regionCode, reason)
+	finish(span, err)
+	return err
+}
+
+func (s *tracedService) GetGeoLeaderboard(ctx context.Context, leaderboardID string, filter model.GeoFilter) (*service.GeoLeaderboard, error) {
+	ctx, span := startService(ctx, "GetGeoLeaderboard", attribute.String("competition.id", leaderboardID), attribute.String("country.code", filter.CountryCode), attribute.String("region.code", filter.RegionCode))
+	defer span.End()
+	res, err := s.next.GetGeoLeaderboard(ctx, leaderboardID, filter)
+	finish(span, err)
+	return res, err
+}
+
+func (s *tracedService) GetTopPlayers(ctx context.Context, filter model.GeoFilter, limit int) (*service.TopPlayers, error) {
+	ctx, span := startService(ctx, "GetTopPlayers", attribute.String("country.code", filter.CountryCode), attribute.String("region.code", filter.RegionCode))
+	defer span.End()
+	res, err := s.next.GetTopPlayers(ctx, filter, limit)
+	finish(span, err)
+	return res, err
+}
+
+func (s *tracedService) GetPlayerHistory(ctx context.Context, playerID string, filter model.PlayerHistoryFilter) (*service.PlayerHistory, error) {
+	ctx, span := startService(ctx, "GetPlayerHistory", attribute.String("player.id", playerID))
+	defer span.End()
+	res, err := s.next.GetPlayerHistory(ctx, playerID, filter)
+	finish(span, err)
+	return res, err
+}
+
+func (s *tracedService) DeletePlayer(ctx context.Context, playerID string) error {
+	ctx, span := startService(ctx, "DeletePlayer", attribute.String("player.id", playerID))
+	defer span.End()
+	err := s.next.DeletePlayer(ctx, playerID)
+	finish(span, err)
+	return err
+}
+
+func (s *tracedService) ExportPlayerData(ctx context.Context, playerID string) (*service.PlayerExport, error) {
+	ctx, span := startService(ctx, "ExportPlayerData", attribute.String("player.id", playerID))
+	defer span.End()
+	res, err := s.next.ExportPlayerData(ctx, playerID)
+	finish(span, err)
+	return res, err
+}
+
+func (s *tracedService) GetModeration(ctx context.Context, playerID string) (*model.Moderation, error) {
+	ctx, span := startService(ctx, "GetModeration", attribute.String("player.id", playerID))
+	defer span.End()
+	m, err := s.next.GetModeration(ctx, playerID)
+	finish(span, err)
+	return m, err
+}
+
+func (s *tracedService) ApplySanction(ctx context.Context, playerID string, sanction model.Sanction, until time.Time, reason string) (*model.Moderation, error) {
+	ctx, span := startService(ctx, "ApplySanction", attribute.String("player.id", playerID))
+	defer span.End()
+	m, err := s.next.ApplySanction(ctx, playerID, sanction, until, reason)
+	finish(span, err)
+	return m, err
+}
+
+func (s *tracedService) LiftSanction(ctx context.Context, playerID string, sanction model.Sanction, reason string) (*model.Moderation, error) {
+	ctx, span := startService(ctx, "LiftSanction", attribute.String("player.id", playerID))
+	defer span.End()
+	m, err := s.next.LiftSanction(ctx, playerID, sanction, reason)
+	finish(span, err)
+	return m, err
+}
@@ -0,0 +1,118 @@
+// Package tracing sets up OpenTelemetry and provides tracing decorators for
+// the service and repository layers and the matchmaking worker. HTTP and gRPC
+// spans come from the otelmux and otelgrpc instrumentation.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"leaderboard-service/internal/logging"
+	"leaderboard-service/internal/service"
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ServiceName is reported as service.name unless OTEL_SERVICE_NAME is set.
+const ServiceName = "leaderboard-service"
+
+const instrumentationName = "leaderboard-service/internal/tracing"
+
+// tracer looks up the global provider on every call, so spans follow whichever
+// provider Setup (or a test) installed last.
+func tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// Setup installs the global tracer provider and the W3C trace context
+// propagator. exporter is "otlp" (configured by the standard
+// OTEL_EXPORTER_OTLP_* variables), "stdout" or "none". The returned function
+// flushes and stops the provider.
+func Setup(ctx context.Context, exporter string) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{}, propagation.Baggage{},
+	))
+
+	var spanExporter sdktrace.SpanExporter
+	var err error
+	switch strings.ToLower(exporter) {
+	case "", "none":
+		return func(context.Context) error { return nil }, nil
+	case "otlp":
+		spanExporter, err = otlptracegrpc.New(ctx)
+	case "stdout", "console":
+		spanExporter, err = stdouttrace.New(stdouttrace.WithPrettyPrint())
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q", exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("creating %s trace exporter: %w", exporter, err)
+	}
+
+	res, err := resource.Merge(
+		resource.Default(),
+		resource.NewSchemaless(semconv.ServiceName(ServiceName)),
+	)
+	if err != nil {
+		return nil, err
+	}
+	// Let OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES override the default.
+	if env, err := resource.New(ctx, resource.WithFromEnv()); err == nil {
+		if merged, err := resource.Merge(res, env); err == nil {
+			res = merged
+		}
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(spanExporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(tp)
+	return tp.Shutdown, nil
+}
+
+// TickMiddleware records every matchmaking pass as a root span.
+func TickMiddleware() service.TickMiddleware {
+	return func(next service.TickFunc) service.TickFunc {
+		return func(ctx context.Context) error {
+			ctx, span := tracer().Start(ctx, "matchmaking.tick", trace.WithNewRoot())
+			defer span.End()
+			err := next(withTraceID(ctx))
+			finish(span, err)
+			return err
+		}
+	}
+}
+
+// LogMiddleware adds the current trace ID to the request logger. It must run
+// after the middleware that starts the request span.
+func LogMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(withTraceID(r.Context())))
+	})
+}
+
+func withTraceID(ctx context.Context) context.Context {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return ctx
+	}
+	return logging.With(ctx, "trace_id", sc.TraceID().String())
+}
+
+// finish marks span as failed when err is set.
+func finish(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
@@ -0,0 +1,159 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"leaderboard-service/internal/model"
+	"leaderboard-service/internal/repository"
+	"leaderboard-service/internal/service"
+
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gorilla/mux/otelmux"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+type mockService struct {
+	service.ServiceInterface
+	GetPlayerFunc func(ctx context.Context, playerID string) (*model.Player, error)
+}
+
+func (m *mockService) GetPlayer(ctx context.Context, playerID string) (*model.Player, error) {
+	return m.GetPlayerFunc(ctx, playerID)
+}
+
+type mockRepo struct {
+	repository.RepositoryInterface
+	err error
+}
+
+func (m *mockRepo) GetPlayerByID(ctx context.Context, playerID string) (*model.Player, error) {
+	return &model.Player{PlayerID: playerID}, m.err
+}
+
+// recordSpans installs an in-memory tracer provider for the test.
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	rec := tracetest.NewSpanRecorder()
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+	return rec
+}
+
+func attr(span sdktrace.ReadOnlySpan, key string) attribute.Value {
+	for _, kv := range span.Attributes() {
+		if string(kv.Key) == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestRepository_SpanCarriesSQLOperation(t *testing.T) {
+	rec := recordSpans(t)
+	repo := NewRepository(&mockRepo{err: errors.New("sql: no rows in result set")})
+
+	repo.GetPlayerByID(context.Background(), "p1")
+
+	spans := rec.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+	span := spans[0]
+	if span.Name() != "repository.GetPlayerByID" {
+		t.Errorf("unexpected span name %q", span.Name())
+	}
+	if got := attr(span, "db.operation.name").AsString(); got != "SELECT" {
+		t.Errorf("db.operation.name = %q, want SELECT", got)
+	}
+	if span.Status().Code != codes.Error {
+		t.Errorf("expected error status, got %v", span.Status())
+	}
+}
+
+func TestService_RepositorySpansAreChildren(t *testing.T) {
+	rec := recordSpans(t)
+	repo := NewRepository(&mockRepo{})
+	svc := NewService(&mockService{
+		GetPlayerFunc: func(ctx context.Context, playerID string) (*model.Player, error) {
+			return repo.GetPlayerByID(ctx, playerID)
+		},
+	})
+
+	if _, err := svc.GetPlayer(context.Background(), "p1"); err != nil {
+		t.Fatalf("GetPlayer failed: %v", err)
+	}
+
+	spans := rec.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+	child, parent := spans[0], spans[1]
+	if parent.Name() != "service.GetPlayer" || child.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Errorf("expected repository span under service span, got %q under %v", child.Name(), child.Parent().SpanID())
+	}
+}
+
+func TestTickMiddleware_StartsRootSpan(t *testing.T) {
+	rec := recordSpans(t)
+	tick := TickMiddleware()(func(ctx context.Context) error {
+		return errors.New("db error")
+	})
+
+	tick(context.Background())
+
+	spans := rec.Ended()
+	if len(spans) != 1 || spans[0].Name() != "matchmaking.tick" {
+		t.Fatalf("expected one matchmaking.tick span, got %v", spans)
+	}
+	if spans[0].Status().Code != codes.Error {
+		t.Errorf("expected error status, got %v", spans[0].Status())
+	}
+}
+
+func TestHTTP_AcceptsInboundTraceparent(t *testing.T) {
+	rec := recordSpans(t)
+	svc := NewService(&mockService{
+		GetPlayerFunc: func(ctx context.Context, playerID string) (*model.Player, error) {
+			return &model.Player{PlayerID: playerID}, nil
+		},
+	})
+	r := mux.NewRouter()
+	r.Use(otelmux.Middleware(ServiceName), LogMiddleware)
+	r.HandleFunc("/v1/player/{player_id}", func(w http.ResponseWriter, r *http.Request) {
+		svc.GetPlayer(r.Context(), mux.Vars(r)["player_id"])
+	})
+
+	req := httptest.NewRequest("GET", "/v1/player/p1", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := rec.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+	for _, span := range spans {
+		if got := span.SpanContext().TraceID().String(); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+			t.Errorf("span %q has trace ID %s, want the inbound one", span.Name(), got)
+		}
+	}
+	if spans[1].Name() != "/v1/player/{player_id}" {
+		t.Errorf("expected HTTP span named after the route template, got %q", spans[1].Name())
+	}
+}
+
+func TestSetup_RejectsUnknownExporter(t *testing.T) {
+	if _, err := Setup(context.Background(), "zipkin"); err == nil {
+		t.Error("expected error for unknown exporter")
+	}
+}